@@ -205,7 +205,11 @@ func getReposForPrivateOrg(releaseRepoPath string, allowlist map[string][]string
 		return nil
 	}
 
-	if err := config.OperateOnCIOperatorConfigDir(filepath.Join(releaseRepoPath, config.CiopConfigInRepoPath), callback); err != nil {
+	clusterProfiles, err := config.LoadClusterProfiles(releaseRepoPath)
+	if err != nil {
+		return ret, err
+	}
+	if err := config.OperateOnCIOperatorConfigDir(filepath.Join(releaseRepoPath, config.CiopConfigInRepoPath), clusterProfiles, callback); err != nil {
 		return ret, fmt.Errorf("error while operating in ci-operator configuration files: %w", err)
 	}
 
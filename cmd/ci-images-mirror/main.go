@@ -391,7 +391,7 @@ func getPromotedTags(ciOperatorConfigPath string) sets.Set[string] {
 	if err != nil {
 		logrus.WithError(err).Fatal("failed to determine absolute CI Operator configuration path")
 	}
-	err = config.OperateOnCIOperatorConfigDir(abs, func(cfg *api.ReleaseBuildConfiguration, metadata *config.Info) error {
+	err = config.OperateOnCIOperatorConfigDir(abs, nil, func(cfg *api.ReleaseBuildConfiguration, metadata *config.Info) error {
 		for _, isTagRef := range release.PromotedTags(cfg) {
 			promotedTags.Insert(isTagRef.ISTagName())
 		}
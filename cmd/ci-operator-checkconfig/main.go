@@ -68,7 +68,7 @@ func (o *options) parse() error {
 	}
 	o.clusterClaimOwners = claimOwners
 
-	ciOPConfigAgent, err := agents.NewConfigAgent(o.ConfigDir, nil, agents.WithOrg(o.Org), agents.WithRepo(o.Repo))
+	ciOPConfigAgent, err := agents.NewConfigAgent(o.ConfigDir, nil, agents.WithOrg(o.Org), agents.WithRepo(o.Repo), agents.WithClusterProfiles(func() api.ClusterProfilesMap { return profiles }))
 	if err != nil {
 		return fmt.Errorf("failed to create CI Op config agent: %w", err)
 	}
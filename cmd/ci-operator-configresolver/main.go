@@ -284,13 +284,6 @@ func main() {
 		interrupts.Run(watcher)
 	}
 
-	configErrCh := make(chan error)
-	configAgent, err := agents.NewConfigAgent(o.configPath, configErrCh, agents.WithConfigMetrics(configresolverMetrics.ErrorRate), configAgentOption)
-	if err != nil {
-		logrus.Fatalf("Failed to get config agent: %v", err)
-	}
-	go func() { logrus.Fatal(<-configErrCh) }()
-
 	registryErrCh := make(chan error)
 	registryAgent, err := agents.NewRegistryAgent(o.registryPath, registryErrCh, agents.WithRegistryMetrics(configresolverMetrics.ErrorRate), agents.WithRegistryFlat(o.flatRegistry), registryAgentOption)
 	if err != nil {
@@ -298,6 +291,13 @@ func main() {
 	}
 	go func() { logrus.Fatal(<-registryErrCh) }()
 
+	configErrCh := make(chan error)
+	configAgent, err := agents.NewConfigAgent(o.configPath, configErrCh, agents.WithConfigMetrics(configresolverMetrics.ErrorRate), agents.WithClusterProfiles(registryAgent.GetClusterProfiles), configAgentOption)
+	if err != nil {
+		logrus.Fatalf("Failed to get config agent: %v", err)
+	}
+	go func() { logrus.Fatal(<-configErrCh) }()
+
 	inClusterConfig, err := util.LoadClusterConfig()
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to load in-cluster config")
@@ -91,11 +91,12 @@ func (o *options) process() error {
 		return fmt.Errorf("failed to complete config options: %w", err)
 	}
 	if o.registryPath != "" {
-		refs, chains, workflows, _, _, _, observers, err := load.Registry(o.registryPath, load.RegistryFlag(0))
+		refs, chains, workflows, profiles, _, _, observers, err := load.Registry(o.registryPath, load.RegistryFlag(0))
 		if err != nil {
 			return fmt.Errorf("failed to load registry: %w", err)
 		}
 		o.resolver = registry.NewResolver(refs, chains, workflows, observers)
+		o.ClusterProfiles = profiles
 	}
 	return nil
 }
@@ -116,7 +116,7 @@ channel in the CoreOS Slack.`))
 	if err != nil {
 		logrus.WithError(err).Fatalf("failed to determine absolute filepath of %s", o.ciOperatorConfigDir)
 	}
-	err = config.OperateOnCIOperatorConfigDir(abs, func(cfg *cioperatorapi.ReleaseBuildConfiguration, metadata *config.Info) error {
+	err = config.OperateOnCIOperatorConfigDir(abs, nil, func(cfg *cioperatorapi.ReleaseBuildConfiguration, metadata *config.Info) error {
 		if err := process(cfg, metadata); err != nil {
 			errs = append(errs, err)
 		}
@@ -88,6 +88,7 @@ import (
 	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/secrets"
 	"github.com/openshift/ci-tools/pkg/steps"
+	"github.com/openshift/ci-tools/pkg/steps/release"
 	"github.com/openshift/ci-tools/pkg/util"
 	"github.com/openshift/ci-tools/pkg/util/gzip"
 	"github.com/openshift/ci-tools/pkg/validation"
@@ -174,6 +175,12 @@ After a successful build the --promote will tag each built image (in "images")
 to the image stream(s) identified by the "promotion" config. You may add
 additional images to promote and their target names via the "additional_images"
 map.
+
+To iterate on tests without rebuilding the images each run, --skip-image-builds
+together with --prebuilt-images-namespace tags the source and the images of the
+pipeline from the pipeline image stream of the given namespace, e.g. the one of a
+previous run, instead of building them. The run fails if an image it requires is
+missing from that namespace.
 `
 
 const (
@@ -270,6 +277,10 @@ func main() {
 		logrus.Error("Some steps failed:")
 		logrus.Error(message.String())
 		opt.Report(defaulted...)
+		if opt.informingTargetsOnly() {
+			logrus.Warn("All targets are informing tests, reporting success despite the failures.")
+			return
+		}
 		os.Exit(1)
 	}
 	opt.Report()
@@ -363,8 +374,9 @@ type options struct {
 	help       bool
 	printGraph bool
 
-	writeParams string
-	artifactDir string
+	writeParams      string
+	artifactDir      string
+	imageSummaryPath string
 
 	gitRef                 string
 	namespace              string
@@ -397,6 +409,9 @@ type options struct {
 
 	resolverAddress string
 	resolverClient  server.ResolverClient
+	// clusterProfileAliases holds the cluster profiles of the tests that are not built in,
+	// as looked up from the config resolver
+	clusterProfileAliases api.ClusterProfilesMap
 
 	registryPath string
 	org          string
@@ -429,11 +444,17 @@ type options struct {
 	multiStageParamOverrides stringSlice
 	dependencyOverrides      stringSlice
 
+	podLabels      stringSlice
+	podAnnotations stringSlice
+
 	targetAdditionalSuffix string
 	manifestToolDockerCfg  string
 	localRegistryDNS       string
 
 	restrictNetworkAccess bool
+
+	skipImageBuilds         bool
+	prebuiltImagesNamespace string
 }
 
 func bindOptions(flag *flag.FlagSet) *options {
@@ -478,6 +499,7 @@ func bindOptions(flag *flag.FlagSet) *options {
 	// output control
 	flag.StringVar(&opt.artifactDir, "artifact-dir", "", "DEPRECATED. Does nothing, set $ARTIFACTS instead.")
 	flag.StringVar(&opt.writeParams, "write-params", "", "If set write an env-compatible file with the output of the job.")
+	flag.StringVar(&opt.imageSummaryPath, "image-summary-path", "", "If set write a JSON summary of the images built by the job, their pull specs, digests and promotion targets to this path.")
 
 	// experimental flags
 	flag.StringVar(&opt.gitRef, "git-ref", "", "Populate the job spec from this local Git reference. If JOB_SPEC is set, the refs field will be overwritten.")
@@ -503,6 +525,11 @@ func bindOptions(flag *flag.FlagSet) *options {
 	flag.Var(&opt.multiStageParamOverrides, "multi-stage-param", "A repeatable option where one or more environment parameters can be passed down to the multi-stage steps. This parameter should be in the format NAME=VAL. e.g --multi-stage-param PARAM1=VAL1 --multi-stage-param PARAM2=VAL2.")
 	flag.Var(&opt.dependencyOverrides, "dependency-override-param", "A repeatable option used to override dependencies with external pull specs. This parameter should be in the format ENVVARNAME=PULLSPEC, e.g. --dependency-override-param=OO_INDEX=registry.mydomain.com:5000/pushed/myimage. This would override the value for the OO_INDEX environment variable for any tests/steps that currently have that dependency configured.")
 
+	flag.Var(&opt.podLabels, "pod-labels", "A repeatable option used to add a label to every pod created for the job. This parameter should be in the format KEY=VALUE. Labels that ci-operator sets itself can not be overridden.")
+	flag.Var(&opt.podAnnotations, "pod-annotations", "A repeatable option used to add an annotation to every pod created for the job. This parameter should be in the format KEY=VALUE. Annotations that ci-operator sets itself can not be overridden.")
+
+	flag.BoolVar(&opt.skipImageBuilds, "skip-image-builds", false, "Do not build the source and the images of the pipeline but use the ones already built in the pipeline imagestream of the namespace set by --prebuilt-images-namespace.")
+	flag.StringVar(&opt.prebuiltImagesNamespace, "prebuilt-images-namespace", "", "Namespace holding the pipeline imagestream with the images used instead of building them. Requires --skip-image-builds.")
 	flag.StringVar(&opt.targetAdditionalSuffix, "target-additional-suffix", "", "Inject an additional suffix onto the targeted test's 'as' name. Used for adding an aggregate index")
 
 	flag.StringVar(&opt.manifestToolDockerCfg, "manifest-tool-dockercfg", "/secrets/manifest-tool/.dockerconfigjson", "The dockercfg file path to be used to push the manifest listed image after build. This is being used by the manifest-tool binary.")
@@ -543,6 +570,17 @@ func (o *options) Complete() error {
 	info := o.getResolverInfo(jobSpec)
 	o.resolverClient = server.NewResolverClient(o.resolverAddress)
 
+	if o.skipImageBuilds {
+		if o.prebuiltImagesNamespace == "" {
+			return errors.New("--prebuilt-images-namespace is required when --skip-image-builds is set")
+		}
+		if o.promote {
+			return errors.New("cannot set --skip-image-builds and --promote at the same time")
+		}
+	} else if o.prebuiltImagesNamespace != "" {
+		return errors.New("--prebuilt-images-namespace requires --skip-image-builds")
+	}
+
 	if o.unresolvedConfigPath != "" && o.configSpecPath != "" {
 		return errors.New("cannot set --config and --unresolved-config at the same time")
 	}
@@ -584,8 +622,9 @@ func (o *options) Complete() error {
 	}
 	o.configSpec = config
 	o.jobSpec.Metadata = config.Metadata
+	o.clusterProfileAliases = o.lookUpClusterProfileAliases()
 	mergedConfig := o.injectTest != ""
-	if err := validation.IsValidResolvedConfiguration(o.configSpec, mergedConfig); err != nil {
+	if err := validation.IsValidResolvedConfiguration(o.configSpec, mergedConfig, o.clusterProfileAliases); err != nil {
 		return results.ForReason("validating_config").ForError(err)
 	}
 	o.graphConfig = defaults.FromConfigStatic(o.configSpec)
@@ -729,6 +768,10 @@ func (o *options) Complete() error {
 
 	handleTargetAdditionalSuffix(o)
 
+	if err := setPodMetadata(o); err != nil {
+		return err
+	}
+
 	return overrideTestStepDependencyParams(o)
 }
 
@@ -751,6 +794,21 @@ func parseKeyValParams(input []string, paramType string) (map[string]string, err
 	return params, nil
 }
 
+// informingTargetsOnly determines whether all targets are informing tests, whose
+// failures are recorded but do not fail the job
+func (o *options) informingTargetsOnly() bool {
+	if o.configSpec == nil || len(o.targets.values) == 0 {
+		return false
+	}
+	informing := sets.New[string]()
+	for _, test := range o.configSpec.Tests {
+		if test.Informing {
+			informing.Insert(test.As)
+		}
+	}
+	return informing.HasAll(o.targets.values...)
+}
+
 func handleTargetAdditionalSuffix(o *options) {
 	if o.targetAdditionalSuffix == "" {
 		return
@@ -772,6 +830,27 @@ func handleTargetAdditionalSuffix(o *options) {
 	}
 }
 
+func setPodMetadata(o *options) error {
+	labels, err := parseKeyValParams(o.podLabels.values, "pod-labels")
+	if err != nil {
+		return err
+	}
+	annotations, err := parseKeyValParams(o.podAnnotations.values, "pod-annotations")
+	if err != nil {
+		return err
+	}
+	if err := labeledclient.ValidatePodMetadata(labels, annotations); err != nil {
+		return fmt.Errorf("invalid --pod-labels or --pod-annotations: %w", err)
+	}
+	if len(labels) > 0 {
+		o.jobSpec.PodLabels = labels
+	}
+	if len(annotations) > 0 {
+		o.jobSpec.PodAnnotations = annotations
+	}
+	return nil
+}
+
 func overrideMultiStageParams(o *options) error {
 	// see if there are any passed-in multi-stage parameters.
 	if len(o.multiStageParamOverrides.values) == 0 {
@@ -908,10 +987,14 @@ func (o *options) Run() []error {
 	}
 
 	injectedTest := o.injectTest != ""
+	var prebuiltImagesNamespace string
+	if o.skipImageBuilds {
+		prebuiltImagesNamespace = o.prebuiltImagesNamespace
+	}
 	// load the graph from the configuration
 	buildSteps, promotionSteps, err := defaults.FromConfig(ctx, o.configSpec, &o.graphConfig, o.jobSpec, o.templates, o.writeParams, o.promote, o.clusterConfig,
 		o.podPendingTimeout, leaseClient, o.targets.values, o.cloneAuthConfig, o.pullSecret, o.pushSecret, o.censor, o.hiveKubeconfig,
-		o.consoleHost, o.nodeName, nodeArchitectures, o.targetAdditionalSuffix, o.manifestToolDockerCfg, o.localRegistryDNS, streams, injectedTest)
+		o.consoleHost, o.nodeName, nodeArchitectures, o.targetAdditionalSuffix, o.manifestToolDockerCfg, o.localRegistryDNS, streams, injectedTest, prebuiltImagesNamespace, o.clusterProfileAliases)
 	if err != nil {
 		return []error{results.ForReason("defaulting_config").WithError(err).Errorf("failed to generate steps from config: %v", err)}
 	}
@@ -993,6 +1076,11 @@ func (o *options) Run() []error {
 		if err := o.writeMetadataJSON(); err != nil {
 			logrus.WithError(err).Warn("Unable to update metadata.json for build")
 		}
+		if o.imageSummaryPath != "" {
+			if err := o.writeImageSummary(ctx); err != nil {
+				logrus.WithError(err).Warn("Unable to write the image summary.")
+			}
+		}
 		if len(errs) > 0 {
 			eventRecorder.Event(runtimeObject, coreapi.EventTypeWarning, "CiJobFailed", eventJobDescription(o.jobSpec, o.namespace))
 			var wrapped []error
@@ -1609,6 +1697,68 @@ func (o *options) writeMetadataJSON() error {
 	return nil
 }
 
+// imageSummary describes the images built by a job in a stable format for downstream tooling.
+type imageSummary struct {
+	Images []builtImage `json:"images"`
+}
+
+type builtImage struct {
+	// To is the name of the image in the pipeline ImageStream
+	To api.PipelineImageStreamTagReference `json:"to"`
+	// PullSpec is the resolved pull spec of the built image
+	PullSpec string `json:"pull_spec"`
+	// Digest is the digest of the built image
+	Digest string `json:"digest"`
+	// PromotedTo lists the tags the image is promoted to, if the job promotes
+	PromotedTo []string `json:"promoted_to,omitempty"`
+}
+
+// writeImageSummary records the images built into the pipeline ImageStream during the run.
+// Promotion targets are determined from the configuration, so they are listed even when
+// promotion did not happen.
+func (o *options) writeImageSummary(ctx context.Context) error {
+	client, err := ctrlruntimeclient.New(o.clusterConfig, ctrlruntimeclient.Options{})
+	if err != nil {
+		return fmt.Errorf("failed to construct client: %w", err)
+	}
+	pipeline := &imageapi.ImageStream{}
+	if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: o.namespace, Name: api.PipelineImageStream}, pipeline); err != nil {
+		return fmt.Errorf("failed to get pipeline imagestream: %w", err)
+	}
+	data, err := json.MarshalIndent(summarizeImages(o.configSpec, pipeline), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal image summary: %w", err)
+	}
+	if err := os.WriteFile(o.imageSummaryPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write image summary: %w", err)
+	}
+	return nil
+}
+
+func summarizeImages(config *api.ReleaseBuildConfiguration, pipeline *imageapi.ImageStream) imageSummary {
+	promotedTags, _ := release.PromotedTagsWithRequiredImages(config)
+	summary := imageSummary{Images: []builtImage{}}
+	for _, image := range config.Images {
+		pullSpec, exists, _ := util.ResolvePullSpec(pipeline, string(image.To), true)
+		if !exists {
+			continue
+		}
+		built := builtImage{To: image.To, PullSpec: pullSpec}
+		if i := strings.LastIndex(pullSpec, "@"); i != -1 {
+			built.Digest = pullSpec[i+1:]
+		}
+		for _, tag := range promotedTags[string(image.To)] {
+			built.PromotedTo = append(built.PromotedTo, tag.ISTagName())
+		}
+		sort.Strings(built.PromotedTo)
+		summary.Images = append(summary.Images, built)
+	}
+	sort.Slice(summary.Images, func(i, j int) bool {
+		return summary.Images[i].To < summary.Images[j].To
+	})
+	return summary
+}
+
 func (o *options) findCustomMetadataFile(artifactDir string) (customProwMetadataFile string, err error) {
 	// Try to find the custom prow metadata file. We assume that there's only one. If there's more than one,
 	// we'll just use the first one that we find.
@@ -2393,6 +2543,32 @@ func getClusterProfileSecret(cp clusterProfileForTarget, client ctrlruntimeclien
 	return newSecret, nil
 }
 
+// lookUpClusterProfileAliases looks up the cluster profiles of the tests that are not built in
+// from the config resolver, so that the aliases among them behave like their base profiles.
+// Profiles that can not be looked up are left to the validation of the config.
+func (o *options) lookUpClusterProfileAliases() api.ClusterProfilesMap {
+	if o.resolverAddress == "" {
+		return nil
+	}
+	aliases := api.ClusterProfilesMap{}
+	for _, test := range o.configSpec.Tests {
+		profile := api.ClusterProfile(test.GetClusterProfileName())
+		if profile == "" || api.IsBuiltInClusterProfile(profile) {
+			continue
+		}
+		if _, seen := aliases[profile]; seen {
+			continue
+		}
+		details, err := o.resolverClient.ClusterProfile(profile.Name())
+		if err != nil {
+			logrus.WithError(err).Warnf("Failed to look up cluster profile %s.", profile)
+			continue
+		}
+		aliases[profile] = *details
+	}
+	return aliases
+}
+
 type clusterProfileForTarget struct {
 	target      string
 	profileName string
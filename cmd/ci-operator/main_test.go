@@ -1728,3 +1728,108 @@ func TestGetClusterProfileNamesFromTargets(t *testing.T) {
 		})
 	}
 }
+
+func TestInformingTargetsOnly(t *testing.T) {
+	configSpec := &api.ReleaseBuildConfiguration{
+		Tests: []api.TestStepConfiguration{
+			{As: "informing-1", Informing: true},
+			{As: "informing-2", Informing: true},
+			{As: "gating"},
+			{As: "optional", Optional: true},
+		},
+	}
+	testCases := []struct {
+		name     string
+		targets  []string
+		expected bool
+	}{
+		{
+			name:     "single informing target",
+			targets:  []string{"informing-1"},
+			expected: true,
+		},
+		{
+			name:     "multiple informing targets",
+			targets:  []string{"informing-1", "informing-2"},
+			expected: true,
+		},
+		{
+			name:    "informing and gating target",
+			targets: []string{"informing-1", "gating"},
+		},
+		{
+			name:    "optional target is not informing",
+			targets: []string{"optional"},
+		},
+		{
+			name:    "image target",
+			targets: []string{"[images]"},
+		},
+		{
+			name: "no targets",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &options{targets: stringSlice{values: tc.targets}, configSpec: configSpec}
+			if actual := o.informingTargetsOnly(); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSummarizeImages(t *testing.T) {
+	pipeline := &imagev1.ImageStream{
+		Status: imagev1.ImageStreamStatus{
+			PublicDockerImageRepository: "registry.ci.openshift.org/ci-op-1234/pipeline",
+			Tags: []imagev1.NamedTagEventList{
+				{Tag: "src", Items: []imagev1.TagEvent{{Image: "sha256:src"}}},
+				{Tag: "foo", Items: []imagev1.TagEvent{{Image: "sha256:foo"}}},
+				{Tag: "bar", Items: []imagev1.TagEvent{{Image: "sha256:bar"}}},
+				{Tag: "unbuilt"},
+			},
+		},
+	}
+	images := []api.ProjectDirectoryImageBuildStepConfiguration{{To: "foo"}, {To: "bar"}, {To: "unbuilt"}, {To: "missing"}}
+
+	testCases := []struct {
+		name     string
+		config   *api.ReleaseBuildConfiguration
+		expected imageSummary
+	}{
+		{
+			name:   "no promotion",
+			config: &api.ReleaseBuildConfiguration{Images: images},
+			expected: imageSummary{Images: []builtImage{
+				{To: "bar", PullSpec: "registry.ci.openshift.org/ci-op-1234/pipeline@sha256:bar", Digest: "sha256:bar"},
+				{To: "foo", PullSpec: "registry.ci.openshift.org/ci-op-1234/pipeline@sha256:foo", Digest: "sha256:foo"},
+			}},
+		},
+		{
+			name: "promotion targets are listed",
+			config: &api.ReleaseBuildConfiguration{
+				Images: images,
+				PromotionConfiguration: &api.PromotionConfiguration{
+					Targets: []api.PromotionTarget{{Namespace: "ocp", Name: "4.16", ExcludedImages: []string{"bar"}}},
+				},
+			},
+			expected: imageSummary{Images: []builtImage{
+				{To: "bar", PullSpec: "registry.ci.openshift.org/ci-op-1234/pipeline@sha256:bar", Digest: "sha256:bar"},
+				{To: "foo", PullSpec: "registry.ci.openshift.org/ci-op-1234/pipeline@sha256:foo", Digest: "sha256:foo", PromotedTo: []string{"ocp/4.16:foo"}},
+			}},
+		},
+		{
+			name:     "nothing built",
+			config:   &api.ReleaseBuildConfiguration{},
+			expected: imageSummary{Images: []builtImage{}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, summarizeImages(tc.config, pipeline)); diff != "" {
+				t.Errorf("unexpected summary (-want, +got): %s", diff)
+			}
+		})
+	}
+}
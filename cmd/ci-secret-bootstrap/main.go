@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
@@ -39,6 +40,7 @@ import (
 	"github.com/openshift/ci-tools/pkg/kubernetes/pkg/credentialprovider"
 	"github.com/openshift/ci-tools/pkg/prowconfigutils"
 	"github.com/openshift/ci-tools/pkg/secrets"
+	"github.com/openshift/ci-tools/pkg/vaultclient"
 )
 
 type options struct {
@@ -46,16 +48,23 @@ type options struct {
 
 	dryRun             bool
 	force              bool
+	prune              bool
+	since              time.Duration
 	validateItemsUsage bool
 	confirm            bool
 
+	reportUnconsumedSecrets bool
+
 	kubernetesOptions   flagutil.KubernetesOptions
 	configPath          string
 	generatorConfigPath string
 	cluster             string
+	clusterGroup        string
 	secretNamesRaw      flagutil.Strings
 	logLevel            string
 	impersonateUser     string
+	reportFormat        string
+	dryRunOutputDir     string
 
 	secretsGetters  map[string]Getter
 	config          secretbootstrap.Config
@@ -63,6 +72,16 @@ type options struct {
 
 	allowUnused flagutil.Strings
 
+	knownSecretsPath string
+	allowNewSecrets  bool
+	// knownSecrets holds the cluster/namespace/name of every secret the tool may manage,
+	// it is nil when --known-secrets-file is not set
+	knownSecrets sets.Set[string]
+
+	sopsFile string
+
+	confirmThresholdPercent float64
+
 	validateOnly bool
 }
 
@@ -79,16 +98,26 @@ func parseOptions(censor *secrets.DynamicCensor) (options, error) {
 	fs.BoolVar(&o.validateOnly, "validate-only", false, "If set, the tool exists after validating its config file.")
 	fs.Var(&o.allowUnused, "bw-allow-unused", "One or more items that will be ignored when the --validate-items-usage is specified")
 	fs.BoolVar(&o.validateItemsUsage, "validate-bitwarden-items-usage", false, fmt.Sprintf("If set, the tool only validates if all fields that exist in Vault and were last modified before %d days ago are being used in the given config.", allowUnusedDays))
+	fs.BoolVar(&o.reportUnconsumedSecrets, "report-unconsumed-secrets", false, "If set, the tool only lists the secrets from the config that exist on the cluster passed via --cluster but are neither referenced by a pod nor by a service account in their namespace, one cluster/namespace/name per line.")
 	fs.BoolVar(&o.dryRun, "dry-run", true, "Whether to actually create the secrets with oc command")
 	fs.BoolVar(&o.confirm, "confirm", true, "Whether to mutate the actual secrets in the targeted clusters")
 	o.kubernetesOptions.AddFlags(fs)
 	fs.StringVar(&o.configPath, "config", "", "Path to the config file to use for this tool.")
 	fs.StringVar(&o.generatorConfigPath, "generator-config", "", "Path to the secret-generator config file.")
 	fs.StringVar(&o.cluster, "cluster", "", "If set, only provision secrets for this cluster")
+	fs.StringVar(&o.clusterGroup, "cluster-group", "", "If set, only provision secrets for the clusters in this cluster group from the config. Mutually exclusive with --cluster.")
 	fs.Var(&o.secretNamesRaw, "secret-names", "If set, only provision secrets with the given name. user_secrets_target_clusters in the configuration is ignored. Can be passed multiple times.")
 	fs.BoolVar(&o.force, "force", false, "If true, update the secrets even if existing one differs from Bitwarden items instead of existing with error. Default false.")
+	fs.DurationVar(&o.since, "since", 0, "If set, only reconcile secrets with at least one source item changed within this duration. Secrets missing from or lacking keys on their target clusters are always reconciled. Ignored with --force.")
+	fs.BoolVar(&o.prune, "prune", false, "If true, remove stale keys from existing secrets owned by ci-secret-bootstrap even without --force. Default false.")
 	fs.StringVar(&o.logLevel, "log-level", "info", fmt.Sprintf("Log level is one of %v.", logrus.AllLevels))
 	fs.StringVar(&o.impersonateUser, "as", "", "Username to impersonate")
+	fs.StringVar(&o.knownSecretsPath, "known-secrets-file", "", "If set, path to a file listing the cluster/namespace/name of every secret the tool may manage, one per line. Targets not in the list are an error.")
+	fs.BoolVar(&o.allowNewSecrets, "allow-new-secrets", false, "If set, targets not listed in --known-secrets-file are only logged.")
+	fs.StringVar(&o.sopsFile, "sops-file", "", "If set, path to a SOPS-encrypted snapshot of the Vault items that is used instead of Vault, for runs without access to it. The snapshot maps item names to their fields and values and is decrypted with the sops binary.")
+	fs.Float64Var(&o.confirmThresholdPercent, "confirm-threshold-percent", 0, "If set, abort before mutating anything when more than this percentage of the secrets managed on any single cluster would be created or updated, unless --force is set.")
+	fs.StringVar(&o.reportFormat, "report-format", reportFormatYAML, fmt.Sprintf("Output format in dry-run mode. One of %q (write the full secrets to temporary files) or %q (print the changes to the live secrets to stdout).", reportFormatYAML, reportFormatJSON))
+	fs.StringVar(&o.dryRunOutputDir, "dry-run-output-dir", "", fmt.Sprintf("If set, the secrets are written to <cluster>.yaml files in this directory in dry-run mode, together with a %s indexing them, instead of to temporary files.", dryRunManifestFile))
 	o.secrets.Bind(fs, os.Getenv, censor)
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		return options{}, err
@@ -103,13 +132,56 @@ func (o *options) validateOptions() error {
 		errs = append(errs, fmt.Errorf("invalid log level specified: %w", err))
 	}
 	logrus.SetLevel(level)
-	errs = append(errs, o.secrets.Validate())
+	if o.sopsFile == "" {
+		errs = append(errs, o.secrets.Validate())
+	}
 	if o.configPath == "" {
 		errs = append(errs, errors.New("--config is required"))
 	}
+	if o.cluster != "" && o.clusterGroup != "" {
+		errs = append(errs, errors.New("--cluster and --cluster-group are mutually exclusive"))
+	}
+	if o.since < 0 {
+		errs = append(errs, errors.New("--since must not be negative"))
+	}
+	// the snapshot does not know when the items were changed
+	if o.since != 0 && o.sopsFile != "" {
+		errs = append(errs, errors.New("--since and --sops-file are mutually exclusive"))
+	}
 	if len(o.allowUnused.Strings()) > 0 && !o.validateItemsUsage {
 		errs = append(errs, errors.New("--bw-allow-unused must be specified with --validate-items-usage"))
 	}
+	if o.reportUnconsumedSecrets {
+		if o.cluster == "" {
+			errs = append(errs, errors.New("--report-unconsumed-secrets requires --cluster"))
+		}
+		if o.validateOnly {
+			errs = append(errs, errors.New("--report-unconsumed-secrets and --validate-only are mutually exclusive"))
+		}
+	}
+	if o.confirmThresholdPercent < 0 || o.confirmThresholdPercent > 100 {
+		errs = append(errs, errors.New("--confirm-threshold-percent must be between 0 and 100"))
+	}
+	if o.allowNewSecrets && o.knownSecretsPath == "" {
+		errs = append(errs, errors.New("--allow-new-secrets must be specified with --known-secrets-file"))
+	}
+	switch o.reportFormat {
+	case "", reportFormatYAML:
+	case reportFormatJSON:
+		if !o.dryRun {
+			errs = append(errs, fmt.Errorf("--report-format=%s requires --dry-run", reportFormatJSON))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("--report-format must be one of %q or %q", reportFormatYAML, reportFormatJSON))
+	}
+	if o.dryRunOutputDir != "" {
+		if !o.dryRun {
+			errs = append(errs, errors.New("--dry-run-output-dir requires --dry-run"))
+		}
+		if o.reportFormat == reportFormatJSON {
+			errs = append(errs, fmt.Errorf("--dry-run-output-dir and --report-format=%s are mutually exclusive", reportFormatJSON))
+		}
+	}
 	errs = append(errs, o.kubernetesOptions.Validate(o.dryRun))
 	return utilerrors.NewAggregate(errs)
 }
@@ -130,6 +202,13 @@ func (o *options) completeOptions(censor *secrets.DynamicCensor, kubeConfigs map
 		logrus.WithField("secretNames", sets.List(secretNames)).WithField("o.config.Secrets", o.config.Secrets).Info("pruned irrelevant configuration")
 	}
 
+	if o.knownSecretsPath != "" {
+		var err error
+		if o.knownSecrets, err = loadKnownSecrets(o.knownSecretsPath); err != nil {
+			return err
+		}
+	}
+
 	if o.generatorConfigPath != "" {
 		var err error
 		o.generatorConfig, err = secretgenerator.LoadConfigFromPath(o.generatorConfigPath)
@@ -147,6 +226,15 @@ func (o *options) completeOptions(censor *secrets.DynamicCensor, kubeConfigs map
 
 	}
 
+	var groupClusters sets.Set[string]
+	if o.clusterGroup != "" {
+		clusters, ok := o.config.ClusterGroups[o.clusterGroup]
+		if !ok {
+			return fmt.Errorf("cluster group %q passed via --cluster-group does not exist in the config", o.clusterGroup)
+		}
+		groupClusters = sets.New[string](clusters...)
+	}
+
 	o.secretsGetters = map[string]Getter{}
 	var filteredSecrets []secretbootstrap.SecretConfig
 	for i, secretConfig := range o.config.Secrets {
@@ -161,6 +249,10 @@ func (o *options) completeOptions(censor *secrets.DynamicCensor, kubeConfigs map
 				logrus.WithFields(logrus.Fields{"target-cluster": o.cluster, "secret-cluster": secretContext.Cluster}).Debug("Skipping provisioning of secrets for a cluster that does not match the one configured via --cluster")
 				continue
 			}
+			if groupClusters != nil && !groupClusters.Has(secretContext.Cluster) {
+				logrus.WithFields(logrus.Fields{"target-cluster-group": o.clusterGroup, "secret-cluster": secretContext.Cluster}).Debug("Skipping provisioning of secrets for a cluster that is not in the group configured via --cluster-group")
+				continue
+			}
 			to = append(to, secretContext)
 
 			if !o.validateOnly {
@@ -192,7 +284,8 @@ func pruneIrrelevantConfiguration(c *secretbootstrap.Config, secretNames sets.Se
 	var secretConfigs []secretbootstrap.SecretConfig
 	for _, secretConfig := range c.Secrets {
 		for _, secretContext := range secretConfig.To {
-			if secretNames.Has(secretContext.Name) {
+			// invalid name templates are reported when validating the config
+			if name, err := secretContext.RenderName(); err == nil && secretNames.Has(name) {
 				secretConfigs = append(secretConfigs, secretConfig)
 				break
 			}
@@ -202,11 +295,33 @@ func pruneIrrelevantConfiguration(c *secretbootstrap.Config, secretNames sets.Se
 	c.UserSecretsTargetClusters = nil
 }
 
+// loadKnownSecrets reads the cluster/namespace/name of the secrets the tool may manage.
+// Empty lines and lines starting with # are ignored.
+func loadKnownSecrets(path string) (sets.Set[string], error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known secrets file: %w", err)
+	}
+	knownSecrets := sets.New[string]()
+	for i, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if parts := strings.Split(line, "/"); len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("%s:%d: %q is not of the form cluster/namespace/name", path, i+1, line)
+		}
+		knownSecrets.Insert(line)
+	}
+	return knownSecrets, nil
+}
+
 func (o *options) validateCompletedOptions() error {
 	if err := o.config.Validate(); err != nil {
 		return fmt.Errorf("failed to validate the config: %w", err)
 	}
-	toMap := map[string]map[string]string{}
+	// toMap holds the first config[i].to[j] for every target secret by cluster, namespace and name
+	toMap := map[string]map[string]map[string]string{}
 	for i, secretConfig := range o.config.Secrets {
 		if len(secretConfig.From) == 0 {
 			return fmt.Errorf("config[%d].from is empty", i)
@@ -241,9 +356,12 @@ func (o *options) validateCompletedOptions() error {
 					}
 				}
 			} else if itemContext.Item != "" {
-				if itemContext.Field == "" {
+				if itemContext.Field == "" && itemContext.DockerConfigJSONField == "" && len(itemContext.AllFieldsExcept) == 0 {
 					return fmt.Errorf("config[%d].from[%s]: field must be set", i, key)
 				}
+				if itemContext.Field != "" && itemContext.DockerConfigJSONField != "" {
+					return fmt.Errorf("config[%d].from[%s]: field and dockerconfigJSON_field are mutually exclusive", i, key)
+				}
 			}
 		}
 		for j, secretContext := range secretConfig.To {
@@ -256,14 +374,32 @@ func (o *options) validateCompletedOptions() error {
 			if secretContext.Name == "" {
 				return fmt.Errorf("config[%d].to[%d].name: empty value is not allowed", i, j)
 			}
+			name, err := secretContext.RenderName()
+			if err != nil {
+				return fmt.Errorf("config[%d].to[%d].name: %w", i, j, err)
+			}
+			secretContext.Name = name
+
+			if o.knownSecrets != nil {
+				if known := fmt.Sprintf("%s/%s/%s", secretContext.Cluster, secretContext.Namespace, secretContext.Name); !o.knownSecrets.Has(known) {
+					if !o.allowNewSecrets {
+						return fmt.Errorf("config[%d].to[%d]: secret %s is not listed in the known secrets file, add %s to it if the secret is expected", i, j, secretContext, known)
+					}
+					logrus.WithField("secret", known).Warn("Secret is not listed in the known secrets file")
+				}
+			}
 
 			if toMap[secretContext.Cluster] == nil {
-				toMap[secretContext.Cluster] = map[string]string{secretContext.Namespace: secretContext.Name}
-			} else if toMap[secretContext.Cluster][secretContext.Namespace] != secretContext.Name {
-				toMap[secretContext.Cluster][secretContext.Namespace] = secretContext.Name
-			} else {
-				return fmt.Errorf("config[%d].to[%d]: secret %s listed more than once in the config", i, j, secretContext)
+				toMap[secretContext.Cluster] = map[string]map[string]string{}
+			}
+			if toMap[secretContext.Cluster][secretContext.Namespace] == nil {
+				toMap[secretContext.Cluster][secretContext.Namespace] = map[string]string{}
 			}
+			// secrets are constructed per config, so a secret populated by more than one config would keep the keys of only one of them
+			if first, ok := toMap[secretContext.Cluster][secretContext.Namespace][secretContext.Name]; ok {
+				return fmt.Errorf("config[%d].to[%d]: secret %s listed more than once in the config, first in %s", i, j, secretContext, first)
+			}
+			toMap[secretContext.Cluster][secretContext.Namespace][secretContext.Name] = fmt.Sprintf("config[%d].to[%d]", i, j)
 		}
 	}
 	return nil
@@ -304,13 +440,26 @@ func constructDockerConfigJSON(client secrets.ReadOnlyClient, dockerConfigJSONDa
 	return b, nil
 }
 
+// dockerConfigJSONFromField reads a complete dockerconfigJSON from a single field of an item
+func dockerConfigJSONFromField(client secrets.ReadOnlyClient, item, field string) ([]byte, error) {
+	value, err := client.GetFieldOnItem(item, field)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get dockerconfigJSON field '%s' from item %s: %w", field, item, err)
+	}
+	if err := json.Unmarshal(value, &credentialprovider.DockerConfigJSON{}); err != nil {
+		return nil, fmt.Errorf("the dockerconfigJSON in field '%s' of item %s doesn't parse: %w", field, item, err)
+	}
+	return value, nil
+}
+
 func constructSecrets(config secretbootstrap.Config, client secrets.ReadOnlyClient, prowDisabledClusters sets.Set[string]) (map[string][]*coreapi.Secret, error) {
 	secretsByClusterAndName := map[string]map[types.NamespacedName]coreapi.Secret{}
+	conflictPolicies := map[string]map[types.NamespacedName]secretbootstrap.ConflictPolicy{}
 	secretsMapLock := &sync.Mutex{}
 
 	var potentialErrors int
 	for _, item := range config.Secrets {
-		potentialErrors = potentialErrors + len(item.From)
+		potentialErrors = potentialErrors + len(item.From) + len(item.To)
 	}
 	errChan := make(chan error, potentialErrors)
 
@@ -344,6 +493,8 @@ func constructSecrets(config secretbootstrap.Config, client secrets.ReadOnlyClie
 					var err error
 					if itemContext.Field != "" {
 						value, err = client.GetFieldOnItem(itemContext.Item, itemContext.Field)
+					} else if itemContext.DockerConfigJSONField != "" {
+						value, err = dockerConfigJSONFromField(client, itemContext.Item, itemContext.DockerConfigJSONField)
 					} else if len(itemContext.DockerConfigJSONData) > 0 {
 						value, err = constructDockerConfigJSON(client, itemContext.DockerConfigJSONData)
 					}
@@ -383,13 +534,18 @@ func constructSecrets(config secretbootstrap.Config, client secrets.ReadOnlyClie
 				return
 			}
 
-			for _, secretContext := range cfg.To {
+			for j, secretContext := range cfg.To {
 				if secretContext.Type == "" {
 					secretContext.Type = coreapi.SecretTypeOpaque
 				}
+				name, err := secretContext.RenderName()
+				if err != nil {
+					errChan <- fmt.Errorf("config.%d.to.%d: %w", idx, j, err)
+					continue
+				}
 				secret := coreapi.Secret{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      secretContext.Name,
+						Name:      name,
 						Namespace: secretContext.Namespace,
 						Labels:    map[string]string{api.DPTPRequesterLabel: "ci-secret-bootstrap"},
 					},
@@ -404,6 +560,12 @@ func constructSecrets(config secretbootstrap.Config, client secrets.ReadOnlyClie
 					secretsByClusterAndName[secretContext.Cluster] = map[types.NamespacedName]coreapi.Secret{}
 				}
 				secretsByClusterAndName[secretContext.Cluster][types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}] = secret
+				if secretContext.OnConflict != "" {
+					if _, ok := conflictPolicies[secretContext.Cluster]; !ok {
+						conflictPolicies[secretContext.Cluster] = map[types.NamespacedName]secretbootstrap.ConflictPolicy{}
+					}
+					conflictPolicies[secretContext.Cluster][types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}] = secretContext.OnConflict
+				}
 				secretsMapLock.Unlock()
 			}
 
@@ -419,7 +581,7 @@ func constructSecrets(config secretbootstrap.Config, client secrets.ReadOnlyClie
 	var err error
 	statBefore := generateSecretStats(secretsByClusterAndName)
 	logrus.WithField("count", statBefore.count).WithField("median", statBefore.median).Info("Secret stats before fetching user secrets")
-	secretsByClusterAndName, err = fetchUserSecrets(secretsByClusterAndName, client, config.UserSecretsTargetClusters)
+	secretsByClusterAndName, err = fetchUserSecrets(secretsByClusterAndName, conflictPolicies, client, config.UserSecretsTargetClusters)
 	if err != nil {
 		errs = append(errs, err)
 	}
@@ -443,7 +605,10 @@ func constructSecrets(config secretbootstrap.Config, client secrets.ReadOnlyClie
 	return result, utilerrors.NewAggregate(errs)
 }
 
-func fetchUserSecrets(secretsMap map[string]map[types.NamespacedName]coreapi.Secret, secretStoreClient secrets.ReadOnlyClient, targetClusters []string) (map[string]map[types.NamespacedName]coreapi.Secret, error) {
+// fetchUserSecrets merges the user secrets synced from the secret store into the secrets
+// constructed from the config. Keys present in both are resolved according to the
+// conflict policy of the config target, erroring when none is set.
+func fetchUserSecrets(secretsMap map[string]map[types.NamespacedName]coreapi.Secret, conflictPolicies map[string]map[types.NamespacedName]secretbootstrap.ConflictPolicy, secretStoreClient secrets.ReadOnlyClient, targetClusters []string) (map[string]map[types.NamespacedName]coreapi.Secret, error) {
 	if len(targetClusters) == 0 {
 		logrus.Warn("No target clusters for user secrets configured, skipping...")
 		return secretsMap, nil
@@ -487,8 +652,17 @@ func fetchUserSecrets(secretsMap map[string]map[types.NamespacedName]coreapi.Sec
 					continue
 				}
 				if _, alreadyExists := entry.Data[vaultKey]; alreadyExists {
-					errs = append(errs, fmt.Errorf("key %s in secret %s in cluster %s is targeted by ci-secret-bootstrap config and by vault item in path %s", vaultKey, secretName.String(), cluster, secretKeys[vaultapi.VaultSourceKey]))
-					continue
+					policy := conflictPolicies[cluster][secretName]
+					switch policy {
+					case secretbootstrap.ConflictPolicyConfigWins:
+						logger.WithField("key", vaultKey).WithField("on_conflict", policy).Debug("Key is also provided by ci-secret-bootstrap config, keeping the config value.")
+						continue
+					case secretbootstrap.ConflictPolicyUserWins:
+						logger.WithField("key", vaultKey).WithField("on_conflict", policy).Debug("Key is also provided by ci-secret-bootstrap config, overriding it with the Vault data.")
+					default:
+						errs = append(errs, fmt.Errorf("key %s in secret %s in cluster %s is targeted by ci-secret-bootstrap config and by vault item in path %s", vaultKey, secretName.String(), cluster, secretKeys[vaultapi.VaultSourceKey]))
+						continue
+					}
 				}
 				entry.Data[vaultKey] = []byte(vaultValue)
 				logger.WithField("key", vaultKey).Debug("Populating key from Vault data.")
@@ -503,9 +677,11 @@ func fetchUserSecrets(secretsMap map[string]map[types.NamespacedName]coreapi.Sec
 type Getter interface {
 	coreclientset.SecretsGetter
 	coreclientset.NamespacesGetter
+	coreclientset.PodsGetter
+	coreclientset.ServiceAccountsGetter
 }
 
-func updateSecrets(getters map[string]Getter, secretsMap map[string][]*coreapi.Secret, force bool, confirm bool, osdGlobalPullSecretGroup, prowDisabledClusters sets.Set[string]) error {
+func updateSecrets(getters map[string]Getter, secretsMap map[string][]*coreapi.Secret, force, prune, confirm bool, osdGlobalPullSecretGroup, prowDisabledClusters sets.Set[string]) error {
 	var errs []error
 
 	var dryRunOptions []string
@@ -591,23 +767,29 @@ func updateSecrets(getters map[string]Getter, secretsMap map[string][]*coreapi.S
 					shouldCreate = true
 				}
 
+				staleKeys := sets.New[string]()
 				if len(secret.Data) > 0 {
 					for k := range existingSecret.Data {
 						if _, exists := secret.Data[k]; exists {
 							continue
 						}
+						staleKeys.Insert(k)
 						logger.WithFields(logrus.Fields{"cluster": cluster, "key": k, "namespace": existingSecret.Namespace, "secret": existingSecret.Name}).Warning("Stale key in secret will be deleted")
 					}
 				}
 
 				if !shouldCreate {
 					differentData := !equality.Semantic.DeepEqual(secret.Data, existingSecret.Data)
-					if !force && differentData {
+					pruneOnly := prune && staleKeys.Len() > 0 && isOwnedByBootstrap(existingSecret) && onlyStaleKeysDiffer(secret, existingSecret, staleKeys)
+					if pruneOnly {
+						logger.WithField("keys", sets.List(staleKeys)).Info("Pruning stale keys from secret")
+					}
+					if !force && differentData && !pruneOnly {
 						logger.Errorf("actual secret data differs the expected")
 						errs = append(errs, fmt.Errorf("secret %s:%s/%s needs updating in place, use --force to do so", cluster, secret.Namespace, secret.Name))
 						continue
 					}
-					if existingSecret.Labels == nil || existingSecret.Labels[api.DPTPRequesterLabel] != "ci-secret-bootstrap" || differentData {
+					if !isOwnedByBootstrap(existingSecret) || differentData {
 						if _, err := secretClient.Update(context.TODO(), secret, metav1.UpdateOptions{DryRun: dryRunOptions}); err != nil {
 							errs = append(errs, fmt.Errorf("error updating secret %s:%s/%s: %w", cluster, secret.Namespace, secret.Name, err))
 							continue
@@ -631,6 +813,22 @@ func updateSecrets(getters map[string]Getter, secretsMap map[string][]*coreapi.S
 	return utilerrors.NewAggregate(errs)
 }
 
+// isOwnedByBootstrap returns true if the secret carries the requester label set by ci-secret-bootstrap.
+func isOwnedByBootstrap(secret *coreapi.Secret) bool {
+	return secret.Labels != nil && secret.Labels[api.DPTPRequesterLabel] == "ci-secret-bootstrap"
+}
+
+// onlyStaleKeysDiffer returns true if the existing secret matches the desired one once the stale keys are removed.
+func onlyStaleKeysDiffer(secret, existingSecret *coreapi.Secret, staleKeys sets.Set[string]) bool {
+	pruned := make(map[string][]byte, len(existingSecret.Data))
+	for k, v := range existingSecret.Data {
+		if !staleKeys.Has(k) {
+			pruned[k] = v
+		}
+	}
+	return equality.Semantic.DeepEqual(secret.Data, pruned)
+}
+
 // mutateGlobalPullSecret mutates the original secret based on the refreshed value stored in another secret.
 func mutateGlobalPullSecret(original, secret *coreapi.Secret) (bool, error) {
 	dockerConfig, err := dockerConfigJSON(secret)
@@ -709,6 +907,106 @@ func writeSecrets(secretsMap map[string][]*coreapi.Secret) error {
 	return nil
 }
 
+// dryRunManifestFile is the name of the file that indexes the files written to --dry-run-output-dir
+const dryRunManifestFile = "manifest.json"
+
+type dryRunManifestEntry struct {
+	Cluster string `json:"cluster"`
+	File    string `json:"file"`
+	// Secrets holds the namespace/name of all secrets in the file
+	Secrets []string `json:"secrets"`
+}
+
+// writeSecretsToDir writes the secrets of every cluster to <cluster>.yaml in dir, sorted
+// by namespace and name, and a manifest indexing the files, so the output is deterministic
+// and can be compared against known files. Files listed in the manifest of a previous run
+// that are not written again are removed. As the files contain the secrets in clear text,
+// they are only readable by the current user.
+func writeSecretsToDir(secretsMap map[string][]*coreapi.Secret, dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	previous, err := readDryRunManifest(dir)
+	if err != nil {
+		return err
+	}
+
+	var manifest []dryRunManifestEntry
+	written := sets.New[string]()
+	for _, cluster := range sets.List(sets.KeySet(secretsMap)) {
+		secrets := make([]*coreapi.Secret, len(secretsMap[cluster]))
+		copy(secrets, secretsMap[cluster])
+		sort.Slice(secrets, func(i, j int) bool {
+			if secrets[i].Namespace != secrets[j].Namespace {
+				return secrets[i].Namespace < secrets[j].Namespace
+			}
+			return secrets[i].Name < secrets[j].Name
+		})
+
+		entry := dryRunManifestEntry{Cluster: cluster, File: cluster + ".yaml", Secrets: []string{}}
+		for _, secret := range secrets {
+			entry.Secrets = append(entry.Secrets, secret.Namespace+"/"+secret.Name)
+		}
+		var buf bytes.Buffer
+		if err := writeSecretsToFile(secrets, &buf); err != nil {
+			return fmt.Errorf("error while serializing secrets for cluster %s: %w", cluster, err)
+		}
+		path := filepath.Join(dir, entry.File)
+		logrus.Infof("Writing secrets from cluster %s to %s", cluster, path)
+		if err := writePrivateFile(path, buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to write secrets for cluster %s to file %s: %w", cluster, path, err)
+		}
+		manifest = append(manifest, entry)
+		written.Insert(entry.File)
+	}
+
+	for _, entry := range previous {
+		if written.Has(entry.File) || filepath.Base(entry.File) != entry.File {
+			continue
+		}
+		path := filepath.Join(dir, entry.File)
+		logrus.Infof("Removing stale secrets file %s of cluster %s", path, entry.Cluster)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove stale file %s: %w", path, err)
+		}
+	}
+
+	raw, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the manifest: %w", err)
+	}
+	if err := writePrivateFile(filepath.Join(dir, dryRunManifestFile), append(raw, '\n')); err != nil {
+		return fmt.Errorf("failed to write the manifest: %w", err)
+	}
+	return nil
+}
+
+// readDryRunManifest reads the manifest of a previous run from dir, if any
+func readDryRunManifest(dir string) ([]dryRunManifestEntry, error) {
+	path := filepath.Join(dir, dryRunManifestFile)
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the manifest %s: %w", path, err)
+	}
+	var manifest []dryRunManifestEntry
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the manifest %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// writePrivateFile writes data to path, making sure that an existing file that was
+// created with broader permissions is restricted as well
+func writePrivateFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
+}
+
 func writeSecretsToFile(secrets []*coreapi.Secret, w io.Writer) error {
 	serializerOptions := kubejson.SerializerOptions{Yaml: true, Pretty: true, Strict: true}
 	serializer := kubejson.NewSerializerWithOptions(kubejson.DefaultMetaFactory, scheme.Scheme, scheme.Scheme, serializerOptions)
@@ -752,7 +1050,7 @@ func constructConfigItemsByName(config secretbootstrap.Config) map[string]*compa
 						fields: sets.New[string](),
 					}
 				}
-				item.fields = insertIfNotEmpty(item.fields, itemContext.Field)
+				item.fields = insertIfNotEmpty(item.fields, itemContext.Field, itemContext.DockerConfigJSONField)
 				cfgComparableItemsByName[itemContext.Item] = item
 			}
 
@@ -856,6 +1154,313 @@ func getUnusedItems(config secretbootstrap.Config, client secrets.ReadOnlyClient
 	return utilerrors.NewAggregate(errs)
 }
 
+// filterUnchangedSecrets drops the secrets whose source items were all last
+// changed before the given time. Secrets are kept when any of their items is
+// unknown to the secret store or when any target secret is missing from its
+// cluster or lacks one of the configured keys, so newly-added config entries
+// are always reconciled.
+func filterUnchangedSecrets(config secretbootstrap.Config, client secrets.ReadOnlyClient, getters map[string]Getter, since time.Time) (secretbootstrap.Config, error) {
+	allSecretStoreItems, err := client.GetInUseInformationForAllItems(config.VaultDPTPPrefix)
+	if err != nil {
+		return config, fmt.Errorf("failed to get in-use information from secret store: %w", err)
+	}
+
+	var filtered []secretbootstrap.SecretConfig
+	for _, cfg := range config.Secrets {
+		items := sets.KeySet(constructConfigItemsByName(secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{cfg}}))
+		changed := false
+		for _, itemName := range sets.List(items) {
+			item, ok := allSecretStoreItems[itemName]
+			if !ok || item.LastChanged().After(since) {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			missing, err := targetSecretsMissing(cfg, getters)
+			if err != nil {
+				return config, err
+			}
+			changed = missing
+		}
+		if !changed {
+			logrus.WithField("items", strings.Join(sets.List(items), ",")).Debugf("Skipping secret whose items have not changed since %s", since)
+			continue
+		}
+		filtered = append(filtered, cfg)
+	}
+
+	config.Secrets = filtered
+	return config, nil
+}
+
+// targetSecretsMissing determines whether any of the target secrets does not
+// exist yet or is missing one of the configured keys.
+func targetSecretsMissing(cfg secretbootstrap.SecretConfig, getters map[string]Getter) (bool, error) {
+	for _, secretContext := range cfg.To {
+		getter, ok := getters[secretContext.Cluster]
+		if !ok {
+			return false, fmt.Errorf("failed to get client getter for cluster %s", secretContext.Cluster)
+		}
+		name, err := secretContext.RenderName()
+		if err != nil {
+			return false, err
+		}
+		existing, err := getter.Secrets(secretContext.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("error reading secret %s:%s/%s: %w", secretContext.Cluster, secretContext.Namespace, name, err)
+		}
+		for key := range cfg.From {
+			if _, ok := existing.Data[key]; !ok {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// skippedTargets returns the target secrets by cluster of the config entries
+// that filterUnchangedSecrets dropped, unless a kept entry targets them too.
+// User secrets must not be merged into these targets, as they would be rebuilt
+// without the keys from the config.
+func skippedTargets(config, filtered secretbootstrap.Config) (map[string]sets.Set[types.NamespacedName], error) {
+	targets := func(configs []secretbootstrap.SecretConfig) (map[string]sets.Set[types.NamespacedName], error) {
+		byCluster := map[string]sets.Set[types.NamespacedName]{}
+		for _, cfg := range configs {
+			for _, secretContext := range cfg.To {
+				name, err := secretContext.RenderName()
+				if err != nil {
+					return nil, err
+				}
+				if _, ok := byCluster[secretContext.Cluster]; !ok {
+					byCluster[secretContext.Cluster] = sets.New[types.NamespacedName]()
+				}
+				byCluster[secretContext.Cluster].Insert(types.NamespacedName{Namespace: secretContext.Namespace, Name: name})
+			}
+		}
+		return byCluster, nil
+	}
+
+	all, err := targets(config.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	kept, err := targets(filtered.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	skipped := map[string]sets.Set[types.NamespacedName]{}
+	for cluster, names := range all {
+		if names = names.Difference(kept[cluster]); names.Len() > 0 {
+			skipped[cluster] = names
+		}
+	}
+	return skipped, nil
+}
+
+// withoutTargets drops the given target secrets from the constructed secrets.
+func withoutTargets(secretsMap map[string][]*coreapi.Secret, targets map[string]sets.Set[types.NamespacedName]) map[string][]*coreapi.Secret {
+	for cluster, names := range targets {
+		var kept []*coreapi.Secret
+		for _, secret := range secretsMap[cluster] {
+			if names.Has(types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}) {
+				logrus.WithFields(logrus.Fields{"cluster": cluster, "namespace": secret.Namespace, "name": secret.Name}).Debug("Skipping secret whose config items have not changed")
+				continue
+			}
+			kept = append(kept, secret)
+		}
+		if len(kept) == 0 {
+			delete(secretsMap, cluster)
+			continue
+		}
+		secretsMap[cluster] = kept
+	}
+	return secretsMap
+}
+
+// unconsumedSecrets returns the cluster/namespace/name of the target secrets that exist but are
+// neither referenced by a pod, e.g. as a volume, environment or image pull secret, nor by a service
+// account in their namespace. Those are likely not needed anymore. Secrets that only get mounted
+// by pods that are not running at the moment, e.g. those of Prow jobs, are reported as well.
+// Namespaces that cannot be inspected are skipped and reported in the error, together with the
+// secrets found in all other namespaces.
+func unconsumedSecrets(config secretbootstrap.Config, getters map[string]Getter) ([]string, error) {
+	referencedByNamespace := map[string]sets.Set[string]{}
+	failedNamespaces := sets.New[string]()
+	unconsumed := sets.New[string]()
+	var errs []error
+	for _, cfg := range config.Secrets {
+		for _, secretContext := range cfg.To {
+			getter, ok := getters[secretContext.Cluster]
+			if !ok {
+				errs = append(errs, fmt.Errorf("failed to get client getter for cluster %s", secretContext.Cluster))
+				continue
+			}
+			name, err := secretContext.RenderName()
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if _, err := getter.Secrets(secretContext.Namespace).Get(context.TODO(), name, metav1.GetOptions{}); err != nil {
+				if !kerrors.IsNotFound(err) {
+					errs = append(errs, fmt.Errorf("error reading secret %s:%s/%s: %w", secretContext.Cluster, secretContext.Namespace, name, err))
+				}
+				continue
+			}
+
+			namespaceKey := secretContext.Cluster + "/" + secretContext.Namespace
+			if failedNamespaces.Has(namespaceKey) {
+				continue
+			}
+			referenced, ok := referencedByNamespace[namespaceKey]
+			if !ok {
+				referenced, err = secretsReferencedInNamespace(getter, secretContext.Namespace)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("failed to determine the secrets referenced in namespace %s on cluster %s: %w", secretContext.Namespace, secretContext.Cluster, err))
+					failedNamespaces.Insert(namespaceKey)
+					continue
+				}
+				referencedByNamespace[namespaceKey] = referenced
+			}
+			if !referenced.Has(name) {
+				unconsumed.Insert(namespaceKey + "/" + name)
+			}
+		}
+	}
+	return sets.List(unconsumed), utilerrors.NewAggregate(errs)
+}
+
+// secretsReferencedInNamespace returns the names of all secrets the pods and service accounts of the namespace refer to
+func secretsReferencedInNamespace(getter Getter, namespace string) (sets.Set[string], error) {
+	referenced := sets.New[string]()
+	pods, err := getter.Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		for _, pullSecret := range pod.Spec.ImagePullSecrets {
+			referenced.Insert(pullSecret.Name)
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.Secret != nil {
+				referenced.Insert(volume.Secret.SecretName)
+			}
+			if volume.Projected != nil {
+				for _, source := range volume.Projected.Sources {
+					if source.Secret != nil {
+						referenced.Insert(source.Secret.Name)
+					}
+				}
+			}
+		}
+		containers := append(append([]coreapi.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, container := range containers {
+			for _, envFrom := range container.EnvFrom {
+				if envFrom.SecretRef != nil {
+					referenced.Insert(envFrom.SecretRef.Name)
+				}
+			}
+			for _, env := range container.Env {
+				if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+					referenced.Insert(env.ValueFrom.SecretKeyRef.Name)
+				}
+			}
+		}
+	}
+
+	serviceAccounts, err := getter.ServiceAccounts(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+	for _, serviceAccount := range serviceAccounts.Items {
+		for _, pullSecret := range serviceAccount.ImagePullSecrets {
+			referenced.Insert(pullSecret.Name)
+		}
+		for _, secret := range serviceAccount.Secrets {
+			referenced.Insert(secret.Name)
+		}
+	}
+	return referenced, nil
+}
+
+// expandAllFieldsExcept replaces the entries that import all fields of an item except some with
+// one entry per imported field. The fields are determined from the item in the secret store and
+// the excluded ones have to exist. Secrets whose entries cannot be expanded are dropped.
+func expandAllFieldsExcept(config secretbootstrap.Config, client secrets.ReadOnlyClient) (secretbootstrap.Config, error) {
+	// only the referenced items are looked up, and every one of them only once
+	fieldsByItem := map[string]sets.Set[string]{}
+	fieldsOnItem := func(item string) (sets.Set[string], error) {
+		if fields, ok := fieldsByItem[item]; ok {
+			return fields, nil
+		}
+		fields, err := client.GetFieldsOnItem(item)
+		if err != nil {
+			return nil, err
+		}
+		fieldsByItem[item] = fields
+		return fields, nil
+	}
+
+	var errs []error
+	var expanded []secretbootstrap.SecretConfig
+	for idx, cfg := range config.Secrets {
+		from := make(map[string]secretbootstrap.ItemContext, len(cfg.From))
+		var toExpand []string
+		for key, itemContext := range cfg.From {
+			if len(itemContext.AllFieldsExcept) > 0 {
+				toExpand = append(toExpand, key)
+				continue
+			}
+			from[key] = itemContext
+		}
+		sort.Strings(toExpand)
+
+		var cfgErrs []error
+		for _, key := range toExpand {
+			itemContext := cfg.From[key]
+			fields, err := fieldsOnItem(itemContext.Item)
+			if vaultclient.IsNotFound(err) {
+				cfgErrs = append(cfgErrs, fmt.Errorf("config.%d.\"%s\": item %s doesn't exist", idx, key, itemContext.Item))
+				continue
+			} else if err != nil {
+				cfgErrs = append(cfgErrs, fmt.Errorf("config.%d.\"%s\": failed to get the fields of item %s: %w", idx, key, itemContext.Item, err))
+				continue
+			}
+			for _, excluded := range itemContext.AllFieldsExcept {
+				if !fields.Has(excluded) {
+					cfgErrs = append(cfgErrs, fmt.Errorf("config.%d.\"%s\": excluded field %s in item %s doesn't exist", idx, key, excluded, itemContext.Item))
+				}
+			}
+			for _, field := range sets.List(fields.Difference(sets.New[string](itemContext.AllFieldsExcept...))) {
+				// the keys that configure the sync of a user secret are not part of its data
+				if strings.HasPrefix(field, vaultapi.SecretSyncKeyPrefix) {
+					continue
+				}
+				if _, exists := from[field]; exists {
+					cfgErrs = append(cfgErrs, fmt.Errorf("config.%d.\"%s\": field %s in item %s is imported into a key that is already set", idx, key, field, itemContext.Item))
+					continue
+				}
+				from[field] = secretbootstrap.ItemContext{Item: itemContext.Item, Field: field, Base64Decode: itemContext.Base64Decode}
+			}
+		}
+		cfg.From = from
+		if len(toExpand) > 0 {
+			cfgErrs = append(cfgErrs, cfg.ValidateRequiredKeys(idx)...)
+		}
+		if len(cfgErrs) > 0 {
+			errs = append(errs, cfgErrs...)
+			continue
+		}
+		expanded = append(expanded, cfg)
+	}
+
+	config.Secrets = expanded
+	return config, utilerrors.NewAggregate(errs)
+}
+
 func (o *options) validateItems(client secrets.ReadOnlyClient) error {
 	var errs []error
 
@@ -897,12 +1502,15 @@ func (o *options) validateItems(client secrets.ReadOnlyClient) error {
 					}
 				}
 
-				if item.Field != "" {
-					if _, err := client.GetFieldOnItem(item.Item, item.Field); err != nil {
-						if o.generatorConfig.IsFieldGenerated(stripDPTPPrefixFromItem(item.Item, &o.config), item.Field) {
-							logger.WithField("field", item.Field).Warn("Field doesn't exist but it will be generated")
+				for _, field := range append([]string{item.Field, item.DockerConfigJSONField}, item.AllFieldsExcept...) {
+					if field == "" {
+						continue
+					}
+					if _, err := client.GetFieldOnItem(item.Item, field); err != nil {
+						if o.generatorConfig.IsFieldGenerated(stripDPTPPrefixFromItem(item.Item, &o.config), field) {
+							logger.WithField("field", field).Warn("Field doesn't exist but it will be generated")
 						} else {
-							errs = append(errs, fmt.Errorf("field %s in item %s doesn't exist", item.Field, item.Item))
+							errs = append(errs, fmt.Errorf("field %s in item %s doesn't exist", field, item.Item))
 						}
 					}
 				}
@@ -946,7 +1554,12 @@ func main() {
 	if err := o.completeOptions(&censor, kubeconfigs, disabledClusters); err != nil {
 		logrus.WithError(err).Error("Failed to complete options.")
 	}
-	client, err := o.secrets.NewReadOnlyClient(&censor)
+	var client secrets.ReadOnlyClient
+	if o.sopsFile != "" {
+		client, err = secrets.NewSOPSClient(o.sopsFile, &censor)
+	} else {
+		client, err = o.secrets.NewReadOnlyClient(&censor)
+	}
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to create client.")
 	}
@@ -970,19 +1583,57 @@ func reconcileSecrets(o options, client secrets.ReadOnlyClient, prowDisabledClus
 			return append(errs, fmt.Errorf("failed to validate items: %w", err))
 		}
 
+		if _, err := expandAllFieldsExcept(o.config, client); err != nil {
+			return append(errs, fmt.Errorf("failed to validate the fields imported with all_fields_except: %w", err))
+		}
+
 		logrus.Infof("the config file %s has been validated", o.configPath)
 		return nil
 	}
 
+	// errors returned by expandAllFieldsExcept only affect the secrets that could not be expanded
+	config, err := expandAllFieldsExcept(o.config, client)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if o.reportUnconsumedSecrets {
+		// the secrets are printed even on errors, which only affect the secrets that could not be inspected
+		unconsumed, err := unconsumedSecrets(config, o.secretsGetters)
+		for _, secret := range unconsumed {
+			fmt.Println(secret)
+		}
+		logrus.Infof("Found %d secrets that are not referenced by any pod or service account in their namespace", len(unconsumed))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to determine unconsumed secrets: %w", err))
+		}
+		return errs
+	}
+
+	toReconcile := config
+	var skipped map[string]sets.Set[types.NamespacedName]
+	if o.since > 0 && !o.force {
+		filtered, err := filterUnchangedSecrets(config, client, o.secretsGetters, time.Now().Add(-o.since))
+		if err != nil {
+			return append(errs, fmt.Errorf("failed to filter unchanged secrets: %w", err))
+		}
+		if skipped, err = skippedTargets(config, filtered); err != nil {
+			return append(errs, fmt.Errorf("failed to determine skipped secrets: %w", err))
+		}
+		toReconcile = filtered
+	}
+
 	// errors returned by constructSecrets will be handled once the rest of the secrets have been uploaded
-	secretsMap, err := constructSecrets(o.config, client, prowDisabledClusters)
+	secretsMap, err := constructSecrets(toReconcile, client, prowDisabledClusters)
 	if err != nil {
 		errs = append(errs, err)
 	}
+	// user secrets would otherwise recreate skipped targets without the keys from the config
+	secretsMap = withoutTargets(secretsMap, skipped)
 
 	if o.validateItemsUsage {
 		unusedGracePeriod := time.Now().AddDate(0, 0, -allowUnusedDays)
-		err := getUnusedItems(o.config, client, o.allowUnused.StringSet(), unusedGracePeriod)
+		err := getUnusedItems(config, client, o.allowUnused.StringSet(), unusedGracePeriod)
 		if err != nil {
 			errs = append(errs, err)
 		}
@@ -990,11 +1641,28 @@ func reconcileSecrets(o options, client secrets.ReadOnlyClient, prowDisabledClus
 
 	if o.dryRun {
 		logrus.Infof("Running in dry-run mode")
-		if err := writeSecrets(secretsMap); err != nil {
+		if o.reportFormat == reportFormatJSON {
+			report, err := diffSecrets(o.secretsGetters, secretsMap)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to compare secrets on dry run: %w", err))
+			}
+			if err := writeSecretsReport(report, os.Stdout); err != nil {
+				errs = append(errs, fmt.Errorf("failed to write the report on dry run: %w", err))
+			}
+		} else if o.dryRunOutputDir != "" {
+			if err := writeSecretsToDir(secretsMap, o.dryRunOutputDir); err != nil {
+				errs = append(errs, fmt.Errorf("failed to write secrets on dry run: %w", err))
+			}
+		} else if err := writeSecrets(secretsMap); err != nil {
 			errs = append(errs, fmt.Errorf("failed to write secrets on dry run: %w", err))
 		}
 	} else {
-		if err := updateSecrets(o.secretsGetters, secretsMap, o.force, o.confirm, sets.New[string](o.config.OSDGlobalPullSecretGroup()...), prowDisabledClusters); err != nil {
+		if o.confirmThresholdPercent > 0 && !o.force {
+			if err := checkSecretsAgainstConfirmThreshold(o.secretsGetters, secretsMap, o.confirmThresholdPercent); err != nil {
+				return append(errs, err)
+			}
+		}
+		if err := updateSecrets(o.secretsGetters, secretsMap, o.force, o.prune, o.confirm, sets.New[string](o.config.OSDGlobalPullSecretGroup()...), prowDisabledClusters); err != nil {
 			errs = append(errs, fmt.Errorf("failed to update secrets: %w", err))
 		}
 		logrus.Info("Updated secrets.")
@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -24,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
 
 	"github.com/openshift/ci-tools/pkg/api/secretbootstrap"
 	"github.com/openshift/ci-tools/pkg/api/secretgenerator"
@@ -50,6 +52,89 @@ func TestValidateOptions(t *testing.T) {
 			},
 			expected: fmt.Errorf("--config is required"),
 		},
+		{
+			name: "cluster and cluster group are mutually exclusive",
+			given: options{
+				logLevel:     "info",
+				configPath:   "/tmp/config",
+				cluster:      "build01",
+				clusterGroup: "build_farm",
+				secrets: secrets.CLIOptions{
+					VaultAddr:      "https://vault.test",
+					VaultPrefix:    "prefix",
+					VaultTokenFile: "/tmp/vault-token",
+				},
+			},
+			expected: fmt.Errorf("--cluster and --cluster-group are mutually exclusive"),
+		},
+		{
+			name: "reporting unconsumed secrets requires a cluster",
+			given: options{
+				logLevel:                "info",
+				configPath:              "/tmp/config",
+				reportUnconsumedSecrets: true,
+				secrets: secrets.CLIOptions{
+					VaultAddr:      "https://vault.test",
+					VaultPrefix:    "prefix",
+					VaultTokenFile: "/tmp/vault-token",
+				},
+			},
+			expected: fmt.Errorf("--report-unconsumed-secrets requires --cluster"),
+		},
+		{
+			name: "negative since",
+			given: options{
+				logLevel:   "info",
+				configPath: "/tmp/config",
+				since:      -time.Hour,
+				secrets: secrets.CLIOptions{
+					VaultAddr:      "https://vault.test",
+					VaultPrefix:    "prefix",
+					VaultTokenFile: "/tmp/vault-token",
+				},
+			},
+			expected: fmt.Errorf("--since must not be negative"),
+		},
+		{
+			name: "confirm threshold above 100 percent",
+			given: options{
+				logLevel:                "info",
+				configPath:              "/tmp/config",
+				confirmThresholdPercent: 150,
+				secrets: secrets.CLIOptions{
+					VaultAddr:      "https://vault.test",
+					VaultPrefix:    "prefix",
+					VaultTokenFile: "/tmp/vault-token",
+				},
+			},
+			expected: fmt.Errorf("--confirm-threshold-percent must be between 0 and 100"),
+		},
+		{
+			name: "sops file replaces the vault options",
+			given: options{
+				logLevel:   "info",
+				configPath: "/tmp/config",
+				sopsFile:   "/tmp/snapshot.enc.yaml",
+			},
+		},
+		{
+			name: "sops file and since are mutually exclusive",
+			given: options{
+				logLevel:   "info",
+				configPath: "/tmp/config",
+				sopsFile:   "/tmp/snapshot.enc.yaml",
+				since:      24 * time.Hour,
+			},
+			expected: fmt.Errorf("--since and --sops-file are mutually exclusive"),
+		},
+		{
+			name: "vault options are required without a sops file",
+			given: options{
+				logLevel:   "info",
+				configPath: "/tmp/config",
+			},
+			expected: fmt.Errorf("--vault-addr, one of --vault-token, the VAULT_TOKEN env var or --vault-role and --vault-prefix must be specified together"),
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -163,6 +248,25 @@ secret_configs:
     namespace: ns
     name: name
 `
+
+	configWithMultipleGroups = `
+cluster_groups:
+  group-a:
+  - default
+  group-b:
+  - build01
+secret_configs:
+- from:
+    key-name-1:
+      item: item-name-1
+      field: field-name-1
+  to:
+  - cluster_groups:
+    - group-a
+    - group-b
+    namespace: ns
+    name: name
+`
 )
 
 var (
@@ -299,6 +403,7 @@ func TestCompleteOptions(t *testing.T) {
 	configPath := filepath.Join(dir, "configPath")
 	configWithTypoPath := filepath.Join(dir, "configWithTypoPath")
 	configWithGroupsPath := filepath.Join(dir, "configWithGroups")
+	configWithMultipleGroupsPath := filepath.Join(dir, "configWithMultipleGroups")
 	configWithNonPasswordAttributePath := filepath.Join(dir, "configContentWithNonPasswordAttribute")
 
 	fileMap := map[string][]byte{
@@ -306,6 +411,7 @@ func TestCompleteOptions(t *testing.T) {
 		configPath:                         []byte(configContent),
 		configWithTypoPath:                 []byte(configContentWithTypo),
 		configWithGroupsPath:               []byte(configWithGroups),
+		configWithMultipleGroupsPath:       []byte(configWithMultipleGroups),
 		configWithNonPasswordAttributePath: []byte(configContentWithNonPasswordAttribute),
 	}
 
@@ -381,6 +487,49 @@ func TestCompleteOptions(t *testing.T) {
 			},
 			expectedClusters: []string{"default"},
 		},
+		{
+			name: "multiple groups are resolved",
+			given: options{
+				logLevel:   "info",
+				configPath: configWithMultipleGroupsPath,
+			},
+			expectedConfig: secretbootstrap.Config{
+				ClusterGroups: map[string][]string{"group-a": {"default"}, "group-b": {"build01"}},
+				Secrets: []secretbootstrap.SecretConfig{{
+					From: map[string]secretbootstrap.ItemContext{"key-name-1": {Item: "item-name-1", Field: "field-name-1"}},
+					To: []secretbootstrap.SecretContext{
+						{ClusterGroups: []string{"group-a", "group-b"}, Cluster: "default", Namespace: "ns", Name: "name"},
+						{ClusterGroups: []string{"group-a", "group-b"}, Cluster: "build01", Namespace: "ns", Name: "name"},
+					},
+				}},
+			},
+			expectedClusters: []string{"build01", "default"},
+		},
+		{
+			name: "only clusters in the configured cluster group are used",
+			given: options{
+				logLevel:     "info",
+				configPath:   configWithMultipleGroupsPath,
+				clusterGroup: "group-b",
+			},
+			expectedConfig: secretbootstrap.Config{
+				ClusterGroups: map[string][]string{"group-a": {"default"}, "group-b": {"build01"}},
+				Secrets: []secretbootstrap.SecretConfig{{
+					From: map[string]secretbootstrap.ItemContext{"key-name-1": {Item: "item-name-1", Field: "field-name-1"}},
+					To:   []secretbootstrap.SecretContext{{ClusterGroups: []string{"group-a", "group-b"}, Cluster: "build01", Namespace: "ns", Name: "name"}},
+				}},
+			},
+			expectedClusters: []string{"build01"},
+		},
+		{
+			name: "unknown cluster group",
+			given: options{
+				logLevel:     "info",
+				configPath:   configWithMultipleGroupsPath,
+				clusterGroup: "group-c",
+			},
+			expectedError: fmt.Errorf("cluster group \"group-c\" passed via --cluster-group does not exist in the config"),
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -437,6 +586,33 @@ func TestValidateCompletedOptions(t *testing.T) {
 			},
 			expected: fmt.Errorf("config[0].to is empty"),
 		},
+		{
+			name: "field and dockerconfigJSON_field are mutually exclusive",
+			given: options{
+				logLevel: "info",
+				config: secretbootstrap.Config{
+					Secrets: []secretbootstrap.SecretConfig{
+						{
+							From: map[string]secretbootstrap.ItemContext{
+								".dockerconfigjson": {
+									Item:                  "item-name-1",
+									Field:                 "field-name-1",
+									DockerConfigJSONField: "field-name-2",
+								},
+							},
+							To: []secretbootstrap.SecretContext{
+								{
+									Cluster:   "default",
+									Namespace: "namespace-1",
+									Name:      "prod-secret-1",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: fmt.Errorf("config[0].from[.dockerconfigjson]: field and dockerconfigJSON_field are mutually exclusive"),
+		},
 		{
 			name: "empty from",
 			given: options{
@@ -638,7 +814,7 @@ func TestValidateCompletedOptions(t *testing.T) {
 				"default": configDefault,
 				"build01": configBuild01,
 			},
-			expected: errors.New("config[0].to[2]: secret namespace-1/prod-secret-1 in cluster default listed more than once in the config"),
+			expected: errors.New("config[0].to[2]: secret namespace-1/prod-secret-1 in cluster default listed more than once in the config, first in config[0].to[0]"),
 		},
 		{
 			name: "conflicting secrets in different TOs",
@@ -693,10 +869,10 @@ func TestValidateCompletedOptions(t *testing.T) {
 				"default": configDefault,
 				"build01": configBuild01,
 			},
-			expected: errors.New("config[1].to[0]: secret namespace-1/prod-secret-1 in cluster default listed more than once in the config"),
+			expected: errors.New("config[1].to[0]: secret namespace-1/prod-secret-1 in cluster default listed more than once in the config, first in config[0].to[1]"),
 		},
 		{
-			name: "happy dockerconfigJSON configuration",
+			name: "conflicting keys in the same secret from different configs",
 			given: options{
 				logLevel: "info",
 				config: secretbootstrap.Config{
@@ -709,13 +885,6 @@ func TestValidateCompletedOptions(t *testing.T) {
 											Item:        "item-1",
 											RegistryURL: "test.com",
 											AuthField:   "auth",
-											EmailField:  "email",
-										},
-										{
-											Item:        "item-2",
-											RegistryURL: "test.com",
-											AuthField:   "auth",
-											EmailField:  "email",
 										},
 									},
 								},
@@ -723,18 +892,51 @@ func TestValidateCompletedOptions(t *testing.T) {
 							To: []secretbootstrap.SecretContext{
 								{
 									Cluster:   "default",
-									Name:      "docker-config-json-secret",
 									Namespace: "namespace-1",
-									Type:      "kubernetes.io/dockerconfigjson",
+									Name:      "prod-secret-1",
+								},
+							},
+						},
+						{
+							From: map[string]secretbootstrap.ItemContext{
+								"key-name-1": {
+									Item:  "item-name-1",
+									Field: "field-name-1",
+								},
+							},
+							To: []secretbootstrap.SecretContext{
+								{
+									Cluster:   "default",
+									Namespace: "namespace-1",
+									Name:      "prod-secret-2",
+								},
+							},
+						},
+						{
+							From: map[string]secretbootstrap.ItemContext{
+								".dockerconfigjson": {
+									Item:  "item-name-1",
+									Field: "field-name-1",
+								},
+							},
+							To: []secretbootstrap.SecretContext{
+								{
+									Cluster:   "default",
+									Namespace: "namespace-1",
+									Name:      "prod-secret-1",
 								},
 							},
 						},
 					},
 				},
 			},
+			kubeConfigs: map[string]rest.Config{
+				"default": configDefault,
+			},
+			expected: errors.New("config[2].to[0]: secret namespace-1/prod-secret-1 in cluster default listed more than once in the config, first in config[0].to[0]"),
 		},
 		{
-			name: "sad dockerconfigJSON configuration",
+			name: "different keys in the same secret from different configs",
 			given: options{
 				logLevel: "info",
 				config: secretbootstrap.Config{
@@ -742,103 +944,343 @@ func TestValidateCompletedOptions(t *testing.T) {
 						{
 							From: map[string]secretbootstrap.ItemContext{
 								"key-name-1": {
-									DockerConfigJSONData: []secretbootstrap.DockerConfigJSONData{
-										{
-											Item:        "item-1",
-											RegistryURL: "test.com",
-										},
-										{
-											Item:        "item-2",
-											RegistryURL: "test.com",
-											AuthField:   "auth",
-											EmailField:  "email",
-										},
-									},
+									Item:  "item-name-1",
+									Field: "field-name-1",
+								},
+							},
+							To: []secretbootstrap.SecretContext{
+								{
+									Cluster:   "default",
+									Namespace: "namespace-1",
+									Name:      "prod-secret-1",
+								},
+							},
+						},
+						{
+							From: map[string]secretbootstrap.ItemContext{
+								"key-name-1": {
+									Item:  "item-name-1",
+									Field: "field-name-1",
+								},
+							},
+							To: []secretbootstrap.SecretContext{
+								{
+									Cluster:   "default",
+									Namespace: "namespace-1",
+									Name:      "prod-secret-2",
+								},
+							},
+						},
+						{
+							From: map[string]secretbootstrap.ItemContext{
+								"key-name-2": {
+									Item:  "item-name-2",
+									Field: "field-name-2",
 								},
 							},
 							To: []secretbootstrap.SecretContext{
 								{
 									Cluster:   "default",
-									Name:      "docker-config-json-secret",
 									Namespace: "namespace-1",
+									Name:      "prod-secret-1",
 								},
 							},
 						},
 					},
 				},
 			},
-			expected: fmt.Errorf("config[0].from[key-name-1]: auth_field is missing"),
+			kubeConfigs: map[string]rest.Config{
+				"default": configDefault,
+			},
+			expected: errors.New("config[2].to[0]: secret namespace-1/prod-secret-1 in cluster default listed more than once in the config, first in config[0].to[0]"),
 		},
 		{
-			name: "sad dockerconfigJSON configuration: cannot determine registry URL",
+			name: "all fields except some of an item",
 			given: options{
 				logLevel: "info",
 				config: secretbootstrap.Config{
 					Secrets: []secretbootstrap.SecretConfig{
 						{
 							From: map[string]secretbootstrap.ItemContext{
-								"key-name-1": {
-									DockerConfigJSONData: []secretbootstrap.DockerConfigJSONData{
-										{
-											Item:       "bitwarden-item2",
-											AuthField:  "auth",
-											EmailField: "email",
-										},
-									},
+								"all": {
+									Item:            "item-name-1",
+									AllFieldsExcept: []string{"unwanted"},
 								},
 							},
 							To: []secretbootstrap.SecretContext{
 								{
 									Cluster:   "default",
-									Name:      "docker-config-json-secret",
 									Namespace: "namespace-1",
+									Name:      "prod-secret-1",
 								},
 							},
 						},
 					},
 				},
 			},
-			expected: fmt.Errorf("config[0].from[key-name-1]: registry_url must be set"),
 		},
-	}
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			actual := tc.given.validateCompletedOptions()
-			equalError(t, tc.expected, actual)
-		})
-	}
-}
-
-func TestConstructSecrets(t *testing.T) {
-	testCases := []struct {
-		name             string
-		config           secretbootstrap.Config
-		items            map[string]vaultclient.KVData
-		disabledClusters sets.Set[string]
-		expected         map[string][]*coreapi.Secret
-		expectedError    string
-	}{
 		{
-			name:   "basic case",
-			config: defaultConfig,
-			items: map[string]vaultclient.KVData{
-				"item-name-1": {
-					Data: map[string]string{
-						"field-name-1": "value1",
-						"field-name-2": "value2",
-						"field-name-3": "value3",
-						"field-name-4": "value4",
-					},
-				},
-				"item-name-2": {
-					Data: map[string]string{
-						"field-name-1": "value1",
-						"field-name-2": "value2",
-						"field-name-3": "value3",
-						"field-name-4": "value4",
-					},
-				},
-				"item-name-3": {
+			name: "item without field",
+			given: options{
+				logLevel: "info",
+				config: secretbootstrap.Config{
+					Secrets: []secretbootstrap.SecretConfig{
+						{
+							From: map[string]secretbootstrap.ItemContext{
+								"key-name-1": {
+									Item: "item-name-1",
+								},
+							},
+							To: []secretbootstrap.SecretContext{
+								{
+									Cluster:   "default",
+									Namespace: "namespace-1",
+									Name:      "prod-secret-1",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: errors.New("config[0].from[key-name-1]: field must be set"),
+		},
+		{
+			name: "happy dockerconfigJSON configuration",
+			given: options{
+				logLevel: "info",
+				config: secretbootstrap.Config{
+					Secrets: []secretbootstrap.SecretConfig{
+						{
+							From: map[string]secretbootstrap.ItemContext{
+								".dockerconfigjson": {
+									DockerConfigJSONData: []secretbootstrap.DockerConfigJSONData{
+										{
+											Item:        "item-1",
+											RegistryURL: "test.com",
+											AuthField:   "auth",
+											EmailField:  "email",
+										},
+										{
+											Item:        "item-2",
+											RegistryURL: "test.com",
+											AuthField:   "auth",
+											EmailField:  "email",
+										},
+									},
+								},
+							},
+							To: []secretbootstrap.SecretContext{
+								{
+									Cluster:   "default",
+									Name:      "docker-config-json-secret",
+									Namespace: "namespace-1",
+									Type:      "kubernetes.io/dockerconfigjson",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "sad dockerconfigJSON configuration",
+			given: options{
+				logLevel: "info",
+				config: secretbootstrap.Config{
+					Secrets: []secretbootstrap.SecretConfig{
+						{
+							From: map[string]secretbootstrap.ItemContext{
+								"key-name-1": {
+									DockerConfigJSONData: []secretbootstrap.DockerConfigJSONData{
+										{
+											Item:        "item-1",
+											RegistryURL: "test.com",
+										},
+										{
+											Item:        "item-2",
+											RegistryURL: "test.com",
+											AuthField:   "auth",
+											EmailField:  "email",
+										},
+									},
+								},
+							},
+							To: []secretbootstrap.SecretContext{
+								{
+									Cluster:   "default",
+									Name:      "docker-config-json-secret",
+									Namespace: "namespace-1",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: fmt.Errorf("config[0].from[key-name-1]: auth_field is missing"),
+		},
+		{
+			name: "sad dockerconfigJSON configuration: cannot determine registry URL",
+			given: options{
+				logLevel: "info",
+				config: secretbootstrap.Config{
+					Secrets: []secretbootstrap.SecretConfig{
+						{
+							From: map[string]secretbootstrap.ItemContext{
+								"key-name-1": {
+									DockerConfigJSONData: []secretbootstrap.DockerConfigJSONData{
+										{
+											Item:       "bitwarden-item2",
+											AuthField:  "auth",
+											EmailField: "email",
+										},
+									},
+								},
+							},
+							To: []secretbootstrap.SecretContext{
+								{
+									Cluster:   "default",
+									Name:      "docker-config-json-secret",
+									Namespace: "namespace-1",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: fmt.Errorf("config[0].from[key-name-1]: registry_url must be set"),
+		},
+		{
+			name: "templated names are rendered before checking for duplicates",
+			given: options{
+				logLevel: "info",
+				config: secretbootstrap.Config{
+					Secrets: []secretbootstrap.SecretConfig{
+						{
+							From: map[string]secretbootstrap.ItemContext{
+								"key-name-1": {Item: "item-name-1", Field: "field-name-1"},
+							},
+							To: []secretbootstrap.SecretContext{
+								{Cluster: "default", Namespace: "namespace-1", Name: "secret-{{.Cluster}}"},
+								{Cluster: "build01", Namespace: "namespace-1", Name: "secret-{{.Cluster}}"},
+							},
+						},
+						{
+							From: map[string]secretbootstrap.ItemContext{
+								"key-name-2": {Item: "item-name-1", Field: "field-name-2"},
+							},
+							To: []secretbootstrap.SecretContext{
+								{Cluster: "build01", Namespace: "namespace-1", Name: "secret-build01"},
+							},
+						},
+					},
+				},
+			},
+			expected: fmt.Errorf("config[1].to[0]: secret namespace-1/secret-build01 in cluster build01 listed more than once in the config, first in config[0].to[1]"),
+		},
+		{
+			name: "all secrets are known",
+			given: options{
+				logLevel:     "info",
+				config:       defaultConfig,
+				knownSecrets: sets.New[string]("default/namespace-1/prod-secret-1", "build01/namespace-2/prod-secret-2", "default/ci/ci-pull-credentials"),
+			},
+		},
+		{
+			name: "unknown secret",
+			given: options{
+				logLevel:     "info",
+				config:       defaultConfig,
+				knownSecrets: sets.New[string]("default/namespace-1/prod-secret-1", "default/ci/ci-pull-credentials"),
+			},
+			expected: fmt.Errorf("config[0].to[1]: secret namespace-2/prod-secret-2 in cluster build01 is not listed in the known secrets file, add build01/namespace-2/prod-secret-2 to it if the secret is expected"),
+		},
+		{
+			name: "unknown secret is allowed",
+			given: options{
+				logLevel:        "info",
+				config:          defaultConfig,
+				knownSecrets:    sets.New[string]("default/namespace-1/prod-secret-1", "default/ci/ci-pull-credentials"),
+				allowNewSecrets: true,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := tc.given.validateCompletedOptions()
+			equalError(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestLoadKnownSecrets(t *testing.T) {
+	testCases := []struct {
+		name          string
+		content       string
+		expected      sets.Set[string]
+		expectedError string
+	}{
+		{
+			name:     "comments and empty lines are ignored",
+			content:  "# build farm\ndefault/ci/secret-1\n\n  build01/ci/secret-2  \n",
+			expected: sets.New[string]("default/ci/secret-1", "build01/ci/secret-2"),
+		},
+		{
+			name:          "malformed line",
+			content:       "default/ci/secret-1\ndefault/secret-2\n",
+			expectedError: `known-secrets:2: "default/secret-2" is not of the form cluster/namespace/name`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "known-secrets")
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			actual, err := loadKnownSecrets(path)
+			var actualError string
+			if err != nil {
+				actualError = strings.TrimPrefix(err.Error(), filepath.Dir(path)+"/")
+			}
+			if diff := cmp.Diff(tc.expectedError, actualError); diff != "" {
+				t.Errorf("unexpected error (-want, +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected known secrets (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestConstructSecrets(t *testing.T) {
+	testCases := []struct {
+		name             string
+		config           secretbootstrap.Config
+		items            map[string]vaultclient.KVData
+		disabledClusters sets.Set[string]
+		expected         map[string][]*coreapi.Secret
+		expectedError    string
+	}{
+		{
+			name:   "basic case",
+			config: defaultConfig,
+			items: map[string]vaultclient.KVData{
+				"item-name-1": {
+					Data: map[string]string{
+						"field-name-1": "value1",
+						"field-name-2": "value2",
+						"field-name-3": "value3",
+						"field-name-4": "value4",
+					},
+				},
+				"item-name-2": {
+					Data: map[string]string{
+						"field-name-1": "value1",
+						"field-name-2": "value2",
+						"field-name-3": "value3",
+						"field-name-4": "value4",
+					},
+				},
+				"item-name-3": {
 					Data: map[string]string{
 						"field-name-1": "value1",
 					},
@@ -1267,6 +1709,90 @@ Code: 404. Errors:
 				}},
 			},
 		},
+		{
+			name: "Usersecret collides with dptp key, config wins",
+			items: map[string]vaultclient.KVData{
+				"my/vault/secret": {
+					Data: map[string]string{
+						"secretsync/target-namespace": "some-namespace",
+						"secretsync/target-name":      "some-name",
+						"dptp-key":                    "user-value",
+						"user-key":                    "user-value",
+					},
+				},
+				"dptp-item": {
+					Data: map[string]string{
+						"dptp-key": "dptp-secret",
+					},
+				},
+			},
+			config: secretbootstrap.Config{
+				UserSecretsTargetClusters: []string{"a"},
+				Secrets: []secretbootstrap.SecretConfig{{
+					From: map[string]secretbootstrap.ItemContext{"dptp-key": {Item: "dptp-item", Field: "dptp-key"}},
+					To: []secretbootstrap.SecretContext{
+						{Cluster: "a", Namespace: "some-namespace", Name: "some-name", OnConflict: secretbootstrap.ConflictPolicyConfigWins},
+					},
+				}},
+			},
+			expected: map[string][]*coreapi.Secret{
+				"a": {{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "some-name", Labels: map[string]string{"dptp.openshift.io/requester": "ci-secret-bootstrap"}},
+					Type:       coreapi.SecretTypeOpaque,
+					Data: map[string][]byte{
+						"dptp-key":                     []byte("dptp-secret"),
+						"user-key":                     []byte("user-value"),
+						"secretsync-vault-source-path": []byte("prefix/my/vault/secret"),
+					},
+				}},
+			},
+		},
+		{
+			name: "Usersecret collides with dptp key, user wins",
+			items: map[string]vaultclient.KVData{
+				"my/vault/secret": {
+					Data: map[string]string{
+						"secretsync/target-namespace": "some-namespace",
+						"secretsync/target-name":      "some-name",
+						"dptp-key":                    "user-value",
+					},
+				},
+				"dptp-item": {
+					Data: map[string]string{
+						"dptp-key": "dptp-secret",
+					},
+				},
+			},
+			config: secretbootstrap.Config{
+				UserSecretsTargetClusters: []string{"a", "b"},
+				Secrets: []secretbootstrap.SecretConfig{{
+					From: map[string]secretbootstrap.ItemContext{"dptp-key": {Item: "dptp-item", Field: "dptp-key"}},
+					To: []secretbootstrap.SecretContext{
+						{Cluster: "a", Namespace: "some-namespace", Name: "some-name", OnConflict: secretbootstrap.ConflictPolicyUserWins},
+						{Cluster: "b", Namespace: "some-namespace", Name: "some-name", OnConflict: secretbootstrap.ConflictPolicyError},
+					},
+				}},
+			},
+			expectedError: `key dptp-key in secret some-namespace/some-name in cluster b is targeted by ci-secret-bootstrap config and by vault item in path prefix/my/vault/secret`,
+			expected: map[string][]*coreapi.Secret{
+				"a": {{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "some-name", Labels: map[string]string{"dptp.openshift.io/requester": "ci-secret-bootstrap"}},
+					Type:       coreapi.SecretTypeOpaque,
+					Data: map[string][]byte{
+						"dptp-key":                     []byte("user-value"),
+						"secretsync-vault-source-path": []byte("prefix/my/vault/secret"),
+					},
+				}},
+				"b": {{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "some-name", Labels: map[string]string{"dptp.openshift.io/requester": "ci-secret-bootstrap"}},
+					Type:       coreapi.SecretTypeOpaque,
+					Data: map[string][]byte{
+						"dptp-key":                     []byte("dptp-secret"),
+						"secretsync-vault-source-path": []byte("prefix/my/vault/secret"),
+					},
+				}},
+			},
+		},
 		{
 			name: "dptp secret isn't of opaque type, error",
 			items: map[string]vaultclient.KVData{
@@ -1331,6 +1857,33 @@ Code: 404. Errors:
 * no data at path prefix/fake-item`,
 			expected: map[string][]*coreapi.Secret{},
 		},
+		{
+			name: "secret names are rendered against the target cluster",
+			items: map[string]vaultclient.KVData{
+				"item": {Data: map[string]string{"field": "value"}},
+			},
+			config: secretbootstrap.Config{
+				Secrets: []secretbootstrap.SecretConfig{{
+					From: map[string]secretbootstrap.ItemContext{"key": {Item: "item", Field: "field"}},
+					To: []secretbootstrap.SecretContext{
+						{Cluster: "a", Namespace: "some-namespace", Name: "some-name-{{.Cluster}}"},
+						{Cluster: "b", Namespace: "some-namespace", Name: "some-name-{{.Cluster}}"},
+					},
+				}},
+			},
+			expected: map[string][]*coreapi.Secret{
+				"a": {{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "some-name-a", Labels: map[string]string{"dptp.openshift.io/requester": "ci-secret-bootstrap"}},
+					Type:       coreapi.SecretTypeOpaque,
+					Data:       map[string][]byte{"key": []byte("value")},
+				}},
+				"b": {{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "some-name-b", Labels: map[string]string{"dptp.openshift.io/requester": "ci-secret-bootstrap"}},
+					Type:       coreapi.SecretTypeOpaque,
+					Data:       map[string][]byte{"key": []byte("value")},
+				}},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -1367,6 +1920,7 @@ func TestUpdateSecrets(t *testing.T) {
 		existSecretsOnBuild01    []runtime.Object
 		secretsMap               map[string][]*coreapi.Secret
 		force                    bool
+		prune                    bool
 		expected                 error
 		expectedSecretsOnDefault []coreapi.Secret
 		expectedSecretsOnBuild01 []coreapi.Secret
@@ -1607,90 +2161,221 @@ func TestUpdateSecrets(t *testing.T) {
   }
 }`),
 						},
-						Type: coreapi.SecretTypeOpaque,
+						Type: coreapi.SecretTypeOpaque,
+					},
+				},
+			},
+			force: true,
+			expectedSecretsOnDefault: []coreapi.Secret{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "prod-secret-2",
+						Namespace: "namespace-2",
+					},
+					Data: map[string][]byte{
+						"key-name-1": []byte(`{
+  "auths": {
+    "quay.io": {
+      "auth": "aaa",
+      "email": ""
+    }
+  }
+}`),
+					},
+					Type: coreapi.SecretTypeOpaque,
+				},
+			},
+		},
+		{
+			name: "change secret type without force",
+			existSecretsOnDefault: []runtime.Object{
+				&coreapi.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "prod-secret-2",
+						Namespace: "namespace-2",
+					},
+					Data: map[string][]byte{
+						"key-name-1": []byte(`{
+  "auths": {
+    "quay.io": {
+      "auth": "aaa",
+      "email": ""
+    }
+  }
+}`),
+					},
+					Type: coreapi.SecretTypeDockerConfigJson,
+				},
+			},
+			secretsMap: map[string][]*coreapi.Secret{
+				"default": {
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "prod-secret-2",
+							Namespace: "namespace-2",
+						},
+						Data: map[string][]byte{
+							"key-name-1": []byte(`{
+  "auths": {
+    "quay.io": {
+      "auth": "aaa",
+      "email": ""
+    }
+  }
+}`),
+						},
+					},
+				},
+			},
+			expected: fmt.Errorf("cannot change secret type from \"kubernetes.io/dockerconfigjson\" to \"\" (immutable field): default:namespace-2/prod-secret-2"),
+			expectedSecretsOnDefault: []coreapi.Secret{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "prod-secret-2",
+						Namespace: "namespace-2",
+					},
+					Data: map[string][]byte{
+						"key-name-1": []byte(`{
+  "auths": {
+    "quay.io": {
+      "auth": "aaa",
+      "email": ""
+    }
+  }
+}`),
+					},
+					Type: coreapi.SecretTypeDockerConfigJson,
+				},
+			},
+		},
+		{
+			name: "stale keys are pruned without force",
+			existSecretsOnDefault: []runtime.Object{
+				&coreapi.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "prod-secret-1",
+						Namespace: "namespace-1",
+						Labels:    map[string]string{"dptp.openshift.io/requester": "ci-secret-bootstrap"},
+					},
+					Data: map[string][]byte{
+						"key-name-1": []byte("abc"),
+						"stale-key":  []byte("stale"),
+					},
+				},
+			},
+			secretsMap: map[string][]*coreapi.Secret{
+				"default": {
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "prod-secret-1",
+							Namespace: "namespace-1",
+							Labels:    map[string]string{"dptp.openshift.io/requester": "ci-secret-bootstrap"},
+						},
+						Data: map[string][]byte{
+							"key-name-1": []byte("abc"),
+						},
+					},
+				},
+			},
+			prune: true,
+			expectedSecretsOnDefault: []coreapi.Secret{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "prod-secret-1",
+						Namespace: "namespace-1",
+						Labels:    map[string]string{"dptp.openshift.io/requester": "ci-secret-bootstrap"},
+					},
+					Data: map[string][]byte{
+						"key-name-1": []byte("abc"),
+					},
+				},
+			},
+		},
+		{
+			name: "stale keys are not pruned from secrets without the requester label",
+			existSecretsOnDefault: []runtime.Object{
+				&coreapi.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "prod-secret-1",
+						Namespace: "namespace-1",
+					},
+					Data: map[string][]byte{
+						"key-name-1": []byte("abc"),
+						"stale-key":  []byte("stale"),
+					},
+				},
+			},
+			secretsMap: map[string][]*coreapi.Secret{
+				"default": {
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "prod-secret-1",
+							Namespace: "namespace-1",
+							Labels:    map[string]string{"dptp.openshift.io/requester": "ci-secret-bootstrap"},
+						},
+						Data: map[string][]byte{
+							"key-name-1": []byte("abc"),
+						},
 					},
 				},
 			},
-			force: true,
+			prune:    true,
+			expected: fmt.Errorf("secret default:namespace-1/prod-secret-1 needs updating in place, use --force to do so"),
 			expectedSecretsOnDefault: []coreapi.Secret{
 				{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      "prod-secret-2",
-						Namespace: "namespace-2",
+						Name:      "prod-secret-1",
+						Namespace: "namespace-1",
 					},
 					Data: map[string][]byte{
-						"key-name-1": []byte(`{
-  "auths": {
-    "quay.io": {
-      "auth": "aaa",
-      "email": ""
-    }
-  }
-}`),
+						"key-name-1": []byte("abc"),
+						"stale-key":  []byte("stale"),
 					},
-					Type: coreapi.SecretTypeOpaque,
 				},
 			},
 		},
 		{
-			name: "change secret type without force",
+			name: "prune does not update changed values without force",
 			existSecretsOnDefault: []runtime.Object{
 				&coreapi.Secret{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      "prod-secret-2",
-						Namespace: "namespace-2",
+						Name:      "prod-secret-1",
+						Namespace: "namespace-1",
+						Labels:    map[string]string{"dptp.openshift.io/requester": "ci-secret-bootstrap"},
 					},
 					Data: map[string][]byte{
-						"key-name-1": []byte(`{
-  "auths": {
-    "quay.io": {
-      "auth": "aaa",
-      "email": ""
-    }
-  }
-}`),
+						"key-name-1": []byte("abc"),
+						"stale-key":  []byte("stale"),
 					},
-					Type: coreapi.SecretTypeDockerConfigJson,
 				},
 			},
 			secretsMap: map[string][]*coreapi.Secret{
 				"default": {
 					{
 						ObjectMeta: metav1.ObjectMeta{
-							Name:      "prod-secret-2",
-							Namespace: "namespace-2",
+							Name:      "prod-secret-1",
+							Namespace: "namespace-1",
+							Labels:    map[string]string{"dptp.openshift.io/requester": "ci-secret-bootstrap"},
 						},
 						Data: map[string][]byte{
-							"key-name-1": []byte(`{
-  "auths": {
-    "quay.io": {
-      "auth": "aaa",
-      "email": ""
-    }
-  }
-}`),
+							"key-name-1": []byte("value1"),
 						},
 					},
 				},
 			},
-			expected: fmt.Errorf("cannot change secret type from \"kubernetes.io/dockerconfigjson\" to \"\" (immutable field): default:namespace-2/prod-secret-2"),
+			prune:    true,
+			expected: fmt.Errorf("secret default:namespace-1/prod-secret-1 needs updating in place, use --force to do so"),
 			expectedSecretsOnDefault: []coreapi.Secret{
 				{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      "prod-secret-2",
-						Namespace: "namespace-2",
+						Name:      "prod-secret-1",
+						Namespace: "namespace-1",
+						Labels:    map[string]string{"dptp.openshift.io/requester": "ci-secret-bootstrap"},
 					},
 					Data: map[string][]byte{
-						"key-name-1": []byte(`{
-  "auths": {
-    "quay.io": {
-      "auth": "aaa",
-      "email": ""
-    }
-  }
-}`),
+						"key-name-1": []byte("abc"),
+						"stale-key":  []byte("stale"),
 					},
-					Type: coreapi.SecretTypeDockerConfigJson,
 				},
 			},
 		},
@@ -1720,7 +2405,7 @@ func TestUpdateSecrets(t *testing.T) {
 				"build01": fkcBuild01.CoreV1(),
 			}
 
-			actual := updateSecrets(clients, tc.secretsMap, tc.force, true, nil, nil)
+			actual := updateSecrets(clients, tc.secretsMap, tc.force, tc.prune, true, nil, nil)
 			equalError(t, tc.expected, actual)
 
 			actualSecretsOnDefault, err := fkcDefault.CoreV1().Secrets("").List(context.TODO(), metav1.ListOptions{})
@@ -1823,6 +2508,90 @@ metadata:
 	}
 }
 
+func TestWriteSecretsToDir(t *testing.T) {
+	secret := func(namespace, name string) *coreapi.Secret {
+		return &coreapi.Secret{
+			TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Data:       map[string][]byte{"key": []byte("value")},
+		}
+	}
+	secretsMap := map[string][]*coreapi.Secret{
+		"build02": {secret("ns-2", "b"), secret("ns-1", "b"), secret("ns-1", "a")},
+		"build01": {secret("ns", "a")},
+	}
+
+	dir := filepath.Join(t.TempDir(), "output")
+	if err := writeSecretsToDir(secretsMap, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, dryRunManifestFile))
+	if err != nil {
+		t.Fatalf("failed to read manifest: %v", err)
+	}
+	var manifest []dryRunManifestEntry
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		t.Fatalf("failed to unmarshal manifest: %v", err)
+	}
+	expectedManifest := []dryRunManifestEntry{
+		{Cluster: "build01", File: "build01.yaml", Secrets: []string{"ns/a"}},
+		{Cluster: "build02", File: "build02.yaml", Secrets: []string{"ns-1/a", "ns-1/b", "ns-2/b"}},
+	}
+	if diff := cmp.Diff(expectedManifest, manifest); diff != "" {
+		t.Errorf("manifest differs from expected:\n%s", diff)
+	}
+
+	for _, entry := range manifest {
+		actual, err := os.ReadFile(filepath.Join(dir, entry.File))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", entry.File, err)
+		}
+		secrets := make([]*coreapi.Secret, 0, len(entry.Secrets))
+		for _, s := range entry.Secrets {
+			namespace, name, _ := strings.Cut(s, "/")
+			secrets = append(secrets, secret(namespace, name))
+		}
+		expected := &bytes.Buffer{}
+		if err := writeSecretsToFile(secrets, expected); err != nil {
+			t.Fatalf("failed to serialize secrets: %v", err)
+		}
+		if diff := cmp.Diff(expected.String(), string(actual)); diff != "" {
+			t.Errorf("%s differs from expected:\n%s", entry.File, diff)
+		}
+	}
+
+	for _, name := range []string{"", dryRunManifestFile, "build01.yaml", "build02.yaml"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to stat %q: %v", name, err)
+		}
+		expected := os.FileMode(0600)
+		if info.IsDir() {
+			expected = 0700
+		}
+		if perm := info.Mode().Perm(); perm != expected {
+			t.Errorf("%q: expected permissions %v, got %v", name, expected, perm)
+		}
+	}
+
+	unrelated := filepath.Join(dir, "unrelated.yaml")
+	if err := os.WriteFile(unrelated, []byte("{}"), 0600); err != nil {
+		t.Fatalf("failed to write unrelated file: %v", err)
+	}
+	if err := writeSecretsToDir(map[string][]*coreapi.Secret{"build01": {secret("ns", "a")}}, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "build02.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected the stale build02.yaml to be removed, got: %v", err)
+	}
+	for _, path := range []string{filepath.Join(dir, "build01.yaml"), unrelated} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s to be kept, got: %v", path, err)
+		}
+	}
+}
+
 func equalError(t *testing.T, expected, actual error) {
 	t.Helper()
 	if expected != nil && actual == nil || expected == nil && actual != nil {
@@ -1965,6 +2734,53 @@ func TestConstructDockerConfigJSON(t *testing.T) {
 	}
 }
 
+func TestDockerConfigJSONFromField(t *testing.T) {
+	testCases := []struct {
+		id            string
+		items         map[string]vaultclient.KVData
+		expectedJSON  []byte
+		expectedError string
+	}{
+		{
+			id: "complete dockerconfigJSON is passed through",
+			items: map[string]vaultclient.KVData{
+				"item-name-1": {Data: map[string]string{".dockerconfigjson": `{"auths":{"quay.io":{"auth":"dXNlcjpwYXNzd29yZA=="}}}`}},
+			},
+			expectedJSON: []byte(`{"auths":{"quay.io":{"auth":"dXNlcjpwYXNzd29yZA=="}}}`),
+		},
+		{
+			id: "invalid dockerconfigJSON",
+			items: map[string]vaultclient.KVData{
+				"item-name-1": {Data: map[string]string{".dockerconfigjson": `{"auths":{"quay.io":{"auth":"123456789"}}}`}},
+			},
+			expectedError: "the dockerconfigJSON in field '.dockerconfigjson' of item item-name-1 doesn't parse: illegal base64 data at input byte 8",
+		},
+		{
+			id: "field is missing",
+			items: map[string]vaultclient.KVData{
+				"item-name-1": {Data: map[string]string{"auth": "dXNlcjpwYXNzd29yZA=="}},
+			},
+			expectedError: `couldn't get dockerconfigJSON field '.dockerconfigjson' from item item-name-1: item at path "prefix/item-name-1" has no key ".dockerconfigjson"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.id, func(t *testing.T) {
+			actual, err := dockerConfigJSONFromField(vaultClientFromTestItems(tc.items), "item-name-1", ".dockerconfigjson")
+			var actualError string
+			if err != nil {
+				actualError = err.Error()
+			}
+			if diff := cmp.Diff(tc.expectedError, actualError); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedJSON, actual); diff != "" {
+				t.Errorf("unexpected dockerconfigJSON: %s", diff)
+			}
+		})
+	}
+}
+
 func TestGetUnusedItems(t *testing.T) {
 	threshold := time.Now()
 	dayAfter := threshold.AddDate(0, 0, 1)
@@ -2147,6 +2963,136 @@ func TestGetUnusedItems(t *testing.T) {
 	}
 }
 
+func TestFilterUnchangedSecrets(t *testing.T) {
+	since := time.Now()
+	dayAfter := since.AddDate(0, 0, 1)
+	dayBefore := since.AddDate(0, 0, -1)
+
+	existing := &coreapi.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "namespace"},
+		Data:       map[string][]byte{"key-1": []byte("value")},
+	}
+	secretConfig := func(item string, keys ...string) secretbootstrap.SecretConfig {
+		from := map[string]secretbootstrap.ItemContext{}
+		for _, key := range keys {
+			from[key] = secretbootstrap.ItemContext{Item: item, Field: "field"}
+		}
+		return secretbootstrap.SecretConfig{
+			From: from,
+			To:   []secretbootstrap.SecretContext{{Cluster: "default", Namespace: "namespace", Name: "secret"}},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		items    map[string]vaultclient.KVData
+		existing []runtime.Object
+		config   secretbootstrap.Config
+		expected []secretbootstrap.SecretConfig
+	}{
+		{
+			name: "secret with items changed within the window is kept",
+			items: map[string]vaultclient.KVData{
+				"item": {Metadata: vaultclient.KVMetadata{CreatedTime: dayAfter}, Data: map[string]string{"field": "value"}},
+			},
+			existing: []runtime.Object{existing},
+			config:   secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{secretConfig("item", "key-1")}},
+			expected: []secretbootstrap.SecretConfig{secretConfig("item", "key-1")},
+		},
+		{
+			name: "secret with items unchanged within the window is dropped",
+			items: map[string]vaultclient.KVData{
+				"item": {Metadata: vaultclient.KVMetadata{CreatedTime: dayBefore}, Data: map[string]string{"field": "value"}},
+			},
+			existing: []runtime.Object{existing},
+			config:   secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{secretConfig("item", "key-1")}},
+		},
+		{
+			name: "secret with unknown items is kept",
+			items: map[string]vaultclient.KVData{
+				"item": {Metadata: vaultclient.KVMetadata{CreatedTime: dayBefore}, Data: map[string]string{"field": "value"}},
+			},
+			existing: []runtime.Object{existing},
+			config:   secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{secretConfig("other", "key-1")}},
+			expected: []secretbootstrap.SecretConfig{secretConfig("other", "key-1")},
+		},
+		{
+			name: "secret missing on the cluster is kept",
+			items: map[string]vaultclient.KVData{
+				"item": {Metadata: vaultclient.KVMetadata{CreatedTime: dayBefore}, Data: map[string]string{"field": "value"}},
+			},
+			config:   secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{secretConfig("item", "key-1")}},
+			expected: []secretbootstrap.SecretConfig{secretConfig("item", "key-1")},
+		},
+		{
+			name: "secret missing a configured key on the cluster is kept",
+			items: map[string]vaultclient.KVData{
+				"item": {Metadata: vaultclient.KVMetadata{CreatedTime: dayBefore}, Data: map[string]string{"field": "value"}},
+			},
+			existing: []runtime.Object{existing},
+			config:   secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{secretConfig("item", "key-1", "key-2")}},
+			expected: []secretbootstrap.SecretConfig{secretConfig("item", "key-1", "key-2")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			getters := map[string]Getter{"default": fake.NewSimpleClientset(tc.existing...).CoreV1()}
+			actual, err := filterUnchangedSecrets(tc.config, vaultClientFromTestItems(tc.items), getters, since)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actual.Secrets); diff != "" {
+				t.Errorf("unexpected secrets (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReconcileSecretsSinceWithUserSecrets(t *testing.T) {
+	existing := &coreapi.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "namespace", Labels: map[string]string{"dptp.openshift.io/requester": "ci-secret-bootstrap"}},
+		Data: map[string][]byte{
+			"key-1":                        []byte("value"),
+			"user-key":                     []byte("user-value"),
+			"secretsync-vault-source-path": []byte("prefix/user-item"),
+		},
+		Type: coreapi.SecretTypeOpaque,
+	}
+	client := vaultClientFromTestItems(map[string]vaultclient.KVData{
+		"item": {Metadata: vaultclient.KVMetadata{CreatedTime: time.Now().AddDate(0, 0, -1)}, Data: map[string]string{"field": "value"}},
+		"user-item": {Metadata: vaultclient.KVMetadata{CreatedTime: time.Now().AddDate(0, 0, -1)}, Data: map[string]string{
+			"user-key":                    "user-value",
+			"secretsync/target-namespace": "namespace",
+			"secretsync/target-name":      "secret",
+		}},
+	})
+	getter := fake.NewSimpleClientset(existing.DeepCopy()).CoreV1()
+	o := options{
+		since:          time.Hour,
+		prune:          true,
+		secretsGetters: map[string]Getter{"default": getter},
+		config: secretbootstrap.Config{
+			UserSecretsTargetClusters: []string{"default"},
+			Secrets: []secretbootstrap.SecretConfig{{
+				From: map[string]secretbootstrap.ItemContext{"key-1": {Item: "item", Field: "field"}},
+				To:   []secretbootstrap.SecretContext{{Cluster: "default", Namespace: "namespace", Name: "secret"}},
+			}},
+		},
+	}
+
+	if errs := reconcileSecrets(o, client, nil); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", utilerrors.NewAggregate(errs))
+	}
+	actual, err := getter.Secrets("namespace").Get(context.Background(), "secret", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get secret: %v", err)
+	}
+	if diff := cmp.Diff(existing.Data, actual.Data); diff != "" {
+		t.Errorf("unexpected secret data (-want +got):\n%s", diff)
+	}
+}
+
 func vaultClientFromTestItems(items map[string]vaultclient.KVData) secrets.Client {
 	const prefix = "prefix"
 	data := make(map[string]*vaultclient.KVData, len(items))
@@ -2166,6 +3112,257 @@ func vaultClientFromTestItems(items map[string]vaultclient.KVData) secrets.Clien
 	return secrets.NewVaultClient(&fakeVaultClient{items: data}, prefix, &censor)
 }
 
+func TestUnconsumedSecrets(t *testing.T) {
+	secret := func(namespace, name string) *coreapi.Secret {
+		return &coreapi.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+	config := secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{{
+		From: map[string]secretbootstrap.ItemContext{"key": {Item: "item", Field: "field"}},
+		To: []secretbootstrap.SecretContext{
+			{Cluster: "default", Namespace: "ns", Name: "volume"},
+			{Cluster: "default", Namespace: "ns", Name: "projected"},
+			{Cluster: "default", Namespace: "ns", Name: "env"},
+			{Cluster: "default", Namespace: "ns", Name: "env-from"},
+			{Cluster: "default", Namespace: "ns", Name: "pod-pull-secret"},
+			{Cluster: "default", Namespace: "ns", Name: "sa-pull-secret"},
+			{Cluster: "default", Namespace: "ns", Name: "sa-secret"},
+			{Cluster: "default", Namespace: "ns", Name: "unconsumed"},
+			{Cluster: "default", Namespace: "ns", Name: "not-created"},
+			{Cluster: "default", Namespace: "other", Name: "volume"},
+		},
+	}}}
+	existing := []runtime.Object{
+		secret("ns", "volume"),
+		secret("ns", "projected"),
+		secret("ns", "env"),
+		secret("ns", "env-from"),
+		secret("ns", "pod-pull-secret"),
+		secret("ns", "sa-pull-secret"),
+		secret("ns", "sa-secret"),
+		secret("ns", "unconsumed"),
+		secret("other", "volume"),
+		&coreapi.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod"},
+			Spec: coreapi.PodSpec{
+				ImagePullSecrets: []coreapi.LocalObjectReference{{Name: "pod-pull-secret"}},
+				Volumes: []coreapi.Volume{
+					{Name: "volume", VolumeSource: coreapi.VolumeSource{Secret: &coreapi.SecretVolumeSource{SecretName: "volume"}}},
+					{Name: "projected", VolumeSource: coreapi.VolumeSource{Projected: &coreapi.ProjectedVolumeSource{Sources: []coreapi.VolumeProjection{
+						{Secret: &coreapi.SecretProjection{LocalObjectReference: coreapi.LocalObjectReference{Name: "projected"}}},
+					}}}},
+				},
+				InitContainers: []coreapi.Container{{
+					EnvFrom: []coreapi.EnvFromSource{{SecretRef: &coreapi.SecretEnvSource{LocalObjectReference: coreapi.LocalObjectReference{Name: "env-from"}}}},
+				}},
+				Containers: []coreapi.Container{{
+					Env: []coreapi.EnvVar{{Name: "ENV", ValueFrom: &coreapi.EnvVarSource{SecretKeyRef: &coreapi.SecretKeySelector{LocalObjectReference: coreapi.LocalObjectReference{Name: "env"}, Key: "key"}}}},
+				}},
+			},
+		},
+		&coreapi.ServiceAccount{
+			ObjectMeta:       metav1.ObjectMeta{Namespace: "ns", Name: "sa"},
+			ImagePullSecrets: []coreapi.LocalObjectReference{{Name: "sa-pull-secret"}},
+			Secrets:          []coreapi.ObjectReference{{Name: "sa-secret"}},
+		},
+	}
+
+	actual, err := unconsumedSecrets(config, map[string]Getter{"default": fake.NewSimpleClientset(existing...).CoreV1()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"default/ns/unconsumed", "default/other/volume"}, actual); diff != "" {
+		t.Errorf("unconsumed secrets differ from expected: %s", diff)
+	}
+
+	client := fake.NewSimpleClientset(existing...)
+	client.PrependReactor("list", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "ns" {
+			return true, nil, errors.New("forbidden")
+		}
+		return false, nil, nil
+	})
+	actual, err = unconsumedSecrets(config, map[string]Getter{"default": client.CoreV1()})
+	expectedErr := errors.New("failed to determine the secrets referenced in namespace ns on cluster default: failed to list pods: forbidden")
+	equalError(t, expectedErr, err)
+	if diff := cmp.Diff([]string{"default/other/volume"}, actual); diff != "" {
+		t.Errorf("unconsumed secrets differ from expected when a namespace cannot be inspected: %s", diff)
+	}
+}
+
+func TestExpandAllFieldsExcept(t *testing.T) {
+	items := map[string]vaultclient.KVData{
+		"item": {
+			Data: map[string]string{
+				"wanted":      "value",
+				"also-wanted": "value",
+				"unwanted":    "value",
+				"ignored":     "value",
+			},
+		},
+		"tls-item": {
+			Data: map[string]string{
+				"tls.crt": "value",
+				"tls.key": "value",
+				"ca.crt":  "value",
+			},
+		},
+		"user-item": {
+			Data: map[string]string{
+				"wanted":                      "value",
+				"unwanted":                    "value",
+				"secretsync/target-namespace": "ns",
+				"secretsync/target-name":      "name",
+				"secretsync/target-clusters":  "a",
+			},
+		},
+	}
+	to := []secretbootstrap.SecretContext{{Cluster: "a", Namespace: "ns", Name: "name"}}
+	testCases := []struct {
+		name          string
+		config        secretbootstrap.Config
+		expected      secretbootstrap.Config
+		expectedError string
+	}{
+		{
+			name: "nothing to expand",
+			config: secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{{
+				From: map[string]secretbootstrap.ItemContext{"key": {Item: "item", Field: "wanted"}},
+				To:   to,
+			}}},
+			expected: secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{{
+				From: map[string]secretbootstrap.ItemContext{"key": {Item: "item", Field: "wanted"}},
+				To:   to,
+			}}},
+		},
+		{
+			name: "all fields but the excluded ones are imported",
+			config: secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{{
+				From: map[string]secretbootstrap.ItemContext{
+					"all":   {Item: "item", AllFieldsExcept: []string{"unwanted", "ignored"}, Base64Decode: true},
+					"other": {Item: "other-item", Field: "field"},
+				},
+				To: to,
+			}}},
+			expected: secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{{
+				From: map[string]secretbootstrap.ItemContext{
+					"wanted":      {Item: "item", Field: "wanted", Base64Decode: true},
+					"also-wanted": {Item: "item", Field: "also-wanted", Base64Decode: true},
+					"other":       {Item: "other-item", Field: "field"},
+				},
+				To: to,
+			}}},
+		},
+		{
+			name: "keys configuring the sync of a user secret are not imported",
+			config: secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{{
+				From: map[string]secretbootstrap.ItemContext{"all": {Item: "user-item", AllFieldsExcept: []string{"unwanted"}}},
+				To:   to,
+			}}},
+			expected: secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{{
+				From: map[string]secretbootstrap.ItemContext{"wanted": {Item: "user-item", Field: "wanted"}},
+				To:   to,
+			}}},
+		},
+		{
+			name: "items are looked up regardless of the DPTP prefix",
+			config: secretbootstrap.Config{
+				Secrets: []secretbootstrap.SecretConfig{{
+					From: map[string]secretbootstrap.ItemContext{"all": {Item: "item", AllFieldsExcept: []string{"unwanted", "ignored"}}},
+					To:   to,
+				}},
+				VaultDPTPPrefix: "dptp",
+			},
+			expected: secretbootstrap.Config{
+				Secrets: []secretbootstrap.SecretConfig{{
+					From: map[string]secretbootstrap.ItemContext{
+						"wanted":      {Item: "item", Field: "wanted"},
+						"also-wanted": {Item: "item", Field: "also-wanted"},
+					},
+					To: to,
+				}},
+				VaultDPTPPrefix: "dptp",
+			},
+		},
+		{
+			name: "excluded field does not exist",
+			config: secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{{
+				From: map[string]secretbootstrap.ItemContext{"all": {Item: "item", AllFieldsExcept: []string{"typo"}}},
+				To:   to,
+			}}},
+			expected:      secretbootstrap.Config{},
+			expectedError: `config.0."all": excluded field typo in item item doesn't exist`,
+		},
+		{
+			name: "item does not exist",
+			config: secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{{
+				From: map[string]secretbootstrap.ItemContext{"all": {Item: "missing", AllFieldsExcept: []string{"unwanted"}}},
+				To:   to,
+			}}},
+			expected:      secretbootstrap.Config{},
+			expectedError: `config.0."all": item missing doesn't exist`,
+		},
+		{
+			name: "imported field collides with an explicit key",
+			config: secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{{
+				From: map[string]secretbootstrap.ItemContext{
+					"all":    {Item: "item", AllFieldsExcept: []string{"unwanted", "ignored", "also-wanted"}},
+					"wanted": {Item: "other-item", Field: "field"},
+				},
+				To: to,
+			}}},
+			expected:      secretbootstrap.Config{},
+			expectedError: `config.0."all": field wanted in item item is imported into a key that is already set`,
+		},
+		{
+			name: "expanded fields provide the keys required by the secret type",
+			config: secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{{
+				From: map[string]secretbootstrap.ItemContext{"all": {Item: "tls-item", AllFieldsExcept: []string{"ca.crt"}}},
+				To:   []secretbootstrap.SecretContext{{Cluster: "a", Namespace: "ns", Name: "name", Type: coreapi.SecretTypeTLS}},
+			}}},
+			expected: secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{{
+				From: map[string]secretbootstrap.ItemContext{
+					"tls.crt": {Item: "tls-item", Field: "tls.crt"},
+					"tls.key": {Item: "tls-item", Field: "tls.key"},
+				},
+				To: []secretbootstrap.SecretContext{{Cluster: "a", Namespace: "ns", Name: "name", Type: coreapi.SecretTypeTLS}},
+			}}},
+		},
+		{
+			name: "expanded fields lack a key required by the secret type",
+			config: secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{{
+				From: map[string]secretbootstrap.ItemContext{"all": {Item: "tls-item", AllFieldsExcept: []string{"tls.key"}}},
+				To:   []secretbootstrap.SecretContext{{Cluster: "a", Namespace: "ns", Name: "name", Type: coreapi.SecretTypeTLS}},
+			}}},
+			expected:      secretbootstrap.Config{},
+			expectedError: `secret[0] in secretConfig[0] with kubernetes.io/tls type have no key named tls.key`,
+		},
+		{
+			name: "expanded fields lack the key of a dockerconfigjson secret",
+			config: secretbootstrap.Config{Secrets: []secretbootstrap.SecretConfig{{
+				From: map[string]secretbootstrap.ItemContext{"all": {Item: "item", AllFieldsExcept: []string{"unwanted"}}},
+				To:   []secretbootstrap.SecretContext{{Cluster: "a", Namespace: "ns", Name: "name", Type: coreapi.SecretTypeDockerConfigJson}},
+			}}},
+			expected:      secretbootstrap.Config{},
+			expectedError: `secret[0] in secretConfig[0] with kubernetes.io/dockerconfigjson type have no key named .dockerconfigjson`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := expandAllFieldsExcept(tc.config, vaultClientFromTestItems(items))
+			var actualError string
+			if err != nil {
+				actualError = err.Error()
+			}
+			if diff := cmp.Diff(tc.expectedError, actualError); diff != "" {
+				t.Errorf("unexpected error (-want, +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, actual, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("unexpected config (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
 func TestValidateItems(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	coreapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+const (
+	reportFormatYAML = "yaml"
+	reportFormatJSON = "json"
+)
+
+type secretAction string
+
+const (
+	secretActionCreate secretAction = "create"
+	secretActionUpdate secretAction = "update"
+	secretActionNoop   secretAction = "noop"
+)
+
+// secretDiff describes what would happen to a single secret on a cluster.
+// It only ever contains key names, never the values.
+type secretDiff struct {
+	Namespace   string       `json:"namespace"`
+	Name        string       `json:"name"`
+	Action      secretAction `json:"action"`
+	ChangedKeys []string     `json:"changedKeys,omitempty"`
+	RemovedKeys []string     `json:"removedKeys,omitempty"`
+}
+
+// diffSecrets compares the constructed secrets against the ones that live in the clusters
+// and returns the list of differences, keyed by cluster.
+func diffSecrets(getters map[string]Getter, secretsMap map[string][]*coreapi.Secret) (map[string][]secretDiff, error) {
+	var errs []error
+	report := map[string][]secretDiff{}
+	for cluster, secrets := range secretsMap {
+		clientGetter, ok := getters[cluster]
+		if !ok {
+			errs = append(errs, fmt.Errorf("failed to get client getter for cluster %s", cluster))
+			continue
+		}
+		diffs := []secretDiff{}
+		for _, secret := range secrets {
+			existingSecret, err := clientGetter.Secrets(secret.Namespace).Get(context.TODO(), secret.Name, metav1.GetOptions{})
+			if err != nil && !kerrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("error reading secret %s:%s/%s: %w", cluster, secret.Namespace, secret.Name, err))
+				continue
+			}
+			if kerrors.IsNotFound(err) {
+				existingSecret = nil
+			}
+			diffs = append(diffs, diffSecret(secret, existingSecret))
+		}
+		sort.Slice(diffs, func(i, j int) bool {
+			if diffs[i].Namespace != diffs[j].Namespace {
+				return diffs[i].Namespace < diffs[j].Namespace
+			}
+			return diffs[i].Name < diffs[j].Name
+		})
+		report[cluster] = diffs
+	}
+	return report, utilerrors.NewAggregate(errs)
+}
+
+func diffSecret(secret, existingSecret *coreapi.Secret) secretDiff {
+	diff := secretDiff{Namespace: secret.Namespace, Name: secret.Name}
+	if existingSecret == nil {
+		diff.Action = secretActionCreate
+		diff.ChangedKeys = sets.List(sets.KeySet(secret.Data))
+		return diff
+	}
+
+	changed, removed := sets.New[string](), sets.New[string]()
+	for k, v := range secret.Data {
+		if existing, ok := existingSecret.Data[k]; !ok || !equality.Semantic.DeepEqual(v, existing) {
+			changed.Insert(k)
+		}
+	}
+	for k := range existingSecret.Data {
+		if _, ok := secret.Data[k]; !ok {
+			removed.Insert(k)
+		}
+	}
+	if changed.Len() > 0 {
+		diff.ChangedKeys = sets.List(changed)
+	}
+	if removed.Len() > 0 {
+		diff.RemovedKeys = sets.List(removed)
+	}
+
+	diff.Action = secretActionNoop
+	if changed.Len() > 0 || removed.Len() > 0 || secret.Type != existingSecret.Type {
+		diff.Action = secretActionUpdate
+	}
+	return diff
+}
+
+// countManagedSecrets returns the number of secrets created by ci-secret-bootstrap that exist on each of the clusters
+func countManagedSecrets(getters map[string]Getter, clusters []string) (map[string]int, error) {
+	selector := fmt.Sprintf("%s=ci-secret-bootstrap", api.DPTPRequesterLabel)
+	counts := map[string]int{}
+	for _, cluster := range clusters {
+		getter, ok := getters[cluster]
+		if !ok {
+			return nil, fmt.Errorf("failed to get client getter for cluster %s", cluster)
+		}
+		secrets, err := getter.Secrets(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list secrets on cluster %s: %w", cluster, err)
+		}
+		counts[cluster] = len(secrets.Items)
+	}
+	return counts, nil
+}
+
+// checkConfirmThreshold returns an error for every cluster on which more than thresholdPercent
+// of the managed secrets would be created or updated. The percentage is relative to all secrets
+// managed on the cluster, as the report may only hold some of them, e.g. when --since is used.
+func checkConfirmThreshold(report map[string][]secretDiff, managedSecrets map[string]int, thresholdPercent float64) error {
+	var errs []error
+	for _, cluster := range sets.List(sets.KeySet(report)) {
+		diffs := report[cluster]
+		if len(diffs) == 0 {
+			continue
+		}
+		var mutated, created int
+		for _, diff := range diffs {
+			if diff.Action != secretActionNoop {
+				mutated++
+			}
+			if diff.Action == secretActionCreate {
+				created++
+			}
+		}
+		// secrets created before they were labeled are not counted as managed
+		total := max(managedSecrets[cluster]+created, len(diffs))
+		if percent := float64(mutated) / float64(total) * 100; percent > thresholdPercent {
+			errs = append(errs, fmt.Errorf("%d of %d secrets (%.1f%%) on cluster %s would be created or updated, above the threshold of %.1f%%", mutated, total, percent, cluster, thresholdPercent))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// checkSecretsAgainstConfirmThreshold compares the constructed secrets against the live ones and
+// returns an error if too many of the managed secrets of any cluster would be created or updated.
+func checkSecretsAgainstConfirmThreshold(getters map[string]Getter, secretsMap map[string][]*coreapi.Secret, thresholdPercent float64) error {
+	report, err := diffSecrets(getters, secretsMap)
+	if err != nil {
+		return fmt.Errorf("failed to compare secrets against the confirm threshold: %w", err)
+	}
+	managedSecrets, err := countManagedSecrets(getters, sets.List(sets.KeySet(report)))
+	if err != nil {
+		return fmt.Errorf("failed to count the managed secrets for the confirm threshold: %w", err)
+	}
+	if err := checkConfirmThreshold(report, managedSecrets, thresholdPercent); err != nil {
+		return fmt.Errorf("refusing to update secrets, use --force to do so anyway: %w", err)
+	}
+	return nil
+}
+
+func writeSecretsReport(report map[string][]secretDiff, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
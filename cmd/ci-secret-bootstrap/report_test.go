@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestDiffSecrets(t *testing.T) {
+	testCases := []struct {
+		name          string
+		existing      []runtime.Object
+		secretsMap    map[string][]*coreapi.Secret
+		expected      map[string][]secretDiff
+		expectedError error
+	}{
+		{
+			name: "secret is created, updated, or left alone",
+			existing: []runtime.Object{
+				&coreapi.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "updated", Namespace: "ns"},
+					Data: map[string][]byte{
+						"same":    []byte("value"),
+						"changed": []byte("old"),
+						"removed": []byte("value"),
+					},
+					Type: coreapi.SecretTypeOpaque,
+				},
+				&coreapi.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "untouched", Namespace: "ns"},
+					Data:       map[string][]byte{"key": []byte("value")},
+					Type:       coreapi.SecretTypeOpaque,
+				},
+			},
+			secretsMap: map[string][]*coreapi.Secret{
+				"default": {
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "untouched", Namespace: "ns"},
+						Data:       map[string][]byte{"key": []byte("value")},
+						Type:       coreapi.SecretTypeOpaque,
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "updated", Namespace: "ns"},
+						Data: map[string][]byte{
+							"same":    []byte("value"),
+							"changed": []byte("new"),
+							"added":   []byte("value"),
+						},
+						Type: coreapi.SecretTypeOpaque,
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "created", Namespace: "ns"},
+						Data:       map[string][]byte{"b": []byte("value"), "a": []byte("value")},
+						Type:       coreapi.SecretTypeOpaque,
+					},
+				},
+			},
+			expected: map[string][]secretDiff{
+				"default": {
+					{Namespace: "ns", Name: "created", Action: secretActionCreate, ChangedKeys: []string{"a", "b"}},
+					{Namespace: "ns", Name: "untouched", Action: secretActionNoop},
+					{Namespace: "ns", Name: "updated", Action: secretActionUpdate, ChangedKeys: []string{"added", "changed"}, RemovedKeys: []string{"removed"}},
+				},
+			},
+		},
+		{
+			name: "type change is an update",
+			existing: []runtime.Object{
+				&coreapi.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+					Data:       map[string][]byte{"key": []byte("value")},
+					Type:       coreapi.SecretTypeDockerConfigJson,
+				},
+			},
+			secretsMap: map[string][]*coreapi.Secret{
+				"default": {
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+						Data:       map[string][]byte{"key": []byte("value")},
+						Type:       coreapi.SecretTypeOpaque,
+					},
+				},
+			},
+			expected: map[string][]secretDiff{
+				"default": {{Namespace: "ns", Name: "secret", Action: secretActionUpdate}},
+			},
+		},
+		{
+			name: "unknown cluster is an error",
+			secretsMap: map[string][]*coreapi.Secret{
+				"forgotten-one": {{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"}}},
+			},
+			expected:      map[string][]secretDiff{},
+			expectedError: errors.New("failed to get client getter for cluster forgotten-one"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clients := map[string]Getter{"default": fake.NewSimpleClientset(tc.existing...).CoreV1()}
+			actual, err := diffSecrets(clients, tc.secretsMap)
+			equalError(t, tc.expectedError, err)
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("report differs from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckConfirmThreshold(t *testing.T) {
+	report := map[string][]secretDiff{
+		"build01": {
+			{Namespace: "ns", Name: "created", Action: secretActionCreate},
+			{Namespace: "ns", Name: "updated", Action: secretActionUpdate},
+			{Namespace: "ns", Name: "untouched", Action: secretActionNoop},
+			{Namespace: "ns", Name: "untouched-too", Action: secretActionNoop},
+		},
+		"build02": {
+			{Namespace: "ns", Name: "untouched", Action: secretActionNoop},
+		},
+		"default": {},
+	}
+	managedSecrets := map[string]int{"build01": 3, "build02": 1}
+	testCases := []struct {
+		name             string
+		report           map[string][]secretDiff
+		managedSecrets   map[string]int
+		thresholdPercent float64
+		expected         error
+	}{
+		{
+			name:             "below the threshold",
+			report:           report,
+			managedSecrets:   managedSecrets,
+			thresholdPercent: 75,
+		},
+		{
+			name:             "exactly the threshold",
+			report:           report,
+			managedSecrets:   managedSecrets,
+			thresholdPercent: 50,
+		},
+		{
+			name:             "above the threshold",
+			report:           report,
+			managedSecrets:   managedSecrets,
+			thresholdPercent: 25,
+			expected:         errors.New("2 of 4 secrets (50.0%) on cluster build01 would be created or updated, above the threshold of 25.0%"),
+		},
+		{
+			name: "report only holds the secrets whose items changed since a given time",
+			report: map[string][]secretDiff{
+				"build01": {{Namespace: "ns", Name: "updated", Action: secretActionUpdate}},
+			},
+			managedSecrets:   map[string]int{"build01": 10},
+			thresholdPercent: 25,
+		},
+		{
+			name: "changed secrets are above the threshold of all managed secrets",
+			report: map[string][]secretDiff{
+				"build01": {
+					{Namespace: "ns", Name: "updated", Action: secretActionUpdate},
+					{Namespace: "ns", Name: "created", Action: secretActionCreate},
+					{Namespace: "ns", Name: "created-too", Action: secretActionCreate},
+				},
+			},
+			managedSecrets:   map[string]int{"build01": 8},
+			thresholdPercent: 25,
+			expected:         errors.New("3 of 10 secrets (30.0%) on cluster build01 would be created or updated, above the threshold of 25.0%"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			equalError(t, tc.expected, checkConfirmThreshold(tc.report, tc.managedSecrets, tc.thresholdPercent))
+		})
+	}
+}
+
+func TestCountManagedSecrets(t *testing.T) {
+	managed := map[string]string{api.DPTPRequesterLabel: "ci-secret-bootstrap"}
+	clients := map[string]Getter{
+		"build01": fake.NewSimpleClientset(
+			&coreapi.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "managed", Labels: managed}},
+			&coreapi.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "managed", Labels: managed}},
+			&coreapi.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "unmanaged"}},
+		).CoreV1(),
+		"build02": fake.NewSimpleClientset().CoreV1(),
+	}
+	actual, err := countManagedSecrets(clients, []string{"build01", "build02"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]int{"build01": 2, "build02": 0}, actual); diff != "" {
+		t.Errorf("counts differ from expected:\n%s", diff)
+	}
+
+	_, err = countManagedSecrets(clients, []string{"forgotten-one"})
+	equalError(t, errors.New("failed to get client getter for cluster forgotten-one"), err)
+}
+
+func TestCheckSecretsAgainstConfirmThreshold(t *testing.T) {
+	managed := map[string]string{api.DPTPRequesterLabel: "ci-secret-bootstrap"}
+	var existing []runtime.Object
+	for i := 0; i < 10; i++ {
+		existing = append(existing, &coreapi.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: fmt.Sprintf("secret-%d", i), Labels: managed},
+			Data:       map[string][]byte{"key": []byte("value")},
+		})
+	}
+	changed := func(names ...string) map[string][]*coreapi.Secret {
+		var secrets []*coreapi.Secret
+		for _, name := range names {
+			secrets = append(secrets, &coreapi.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, Labels: managed},
+				Data:       map[string][]byte{"key": []byte("changed")},
+			})
+		}
+		return map[string][]*coreapi.Secret{"build01": secrets}
+	}
+	testCases := []struct {
+		name       string
+		secretsMap map[string][]*coreapi.Secret
+		expected   error
+	}{
+		{
+			// with --since, only the secrets whose items changed are constructed
+			name:       "few changed secrets out of all managed ones",
+			secretsMap: changed("secret-0", "secret-1"),
+		},
+		{
+			name:       "too many changed secrets out of all managed ones",
+			secretsMap: changed("secret-0", "secret-1", "secret-2"),
+			expected:   errors.New("refusing to update secrets, use --force to do so anyway: 3 of 10 secrets (30.0%) on cluster build01 would be created or updated, above the threshold of 25.0%"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			getters := map[string]Getter{"build01": fake.NewSimpleClientset(existing...).CoreV1()}
+			equalError(t, tc.expected, checkSecretsAgainstConfirmThreshold(getters, tc.secretsMap, 25))
+		})
+	}
+}
+
+func TestWriteSecretsReport(t *testing.T) {
+	report := map[string][]secretDiff{
+		"build01": {{Namespace: "ns", Name: "secret", Action: secretActionUpdate, ChangedKeys: []string{"a"}, RemovedKeys: []string{"b"}}},
+		"default": {{Namespace: "ns", Name: "secret", Action: secretActionNoop}},
+	}
+	expected := `{
+  "build01": [
+    {
+      "namespace": "ns",
+      "name": "secret",
+      "action": "update",
+      "changedKeys": [
+        "a"
+      ],
+      "removedKeys": [
+        "b"
+      ]
+    }
+  ],
+  "default": [
+    {
+      "namespace": "ns",
+      "name": "secret",
+      "action": "noop"
+    }
+  ]
+}
+`
+	w := &bytes.Buffer{}
+	if err := writeSecretsReport(report, w); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected, w.String()); diff != "" {
+		t.Errorf("output differs from expected:\n%s", diff)
+	}
+}
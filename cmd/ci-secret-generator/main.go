@@ -131,6 +131,9 @@ func (o *options) validateConfig() error {
 				return cmdEmptyErr(i, fieldIndex, "fields")
 			}
 		}
+		if _, err := item.FieldsInGenerationOrder(); err != nil {
+			return fmt.Errorf("config[%d]: %w", i, err)
+		}
 		var hasCluster bool
 		for paramName, params := range item.Params {
 			if len(params) == 0 {
@@ -147,8 +150,11 @@ func (o *options) validateConfig() error {
 	return nil
 }
 
-func executeCommand(command string) ([]byte, error) {
+func executeCommand(command string, env []string) ([]byte, error) {
 	cmd := exec.Command("bash", "-o", "errexit", "-o", "nounset", "-o", "pipefail", "-c", command)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	var outBuf, errBuf bytes.Buffer
 	cmd.Stdout = &outBuf
 	cmd.Stderr = &errBuf
@@ -197,7 +203,15 @@ func updateSecrets(config secretgenerator.Config, client secrets.Client, disable
 	var errs []error
 	for _, item := range config {
 		logger := logrus.WithField("item", item.ItemName)
-		for _, field := range item.Fields {
+		fields, err := item.FieldsInGenerationOrder()
+		if err != nil {
+			logger.WithError(err).Error("failed to order fields")
+			errs = append(errs, err)
+			continue
+		}
+		generated := map[string][]byte{}
+	fields:
+		for _, field := range fields {
 			logger = logger.WithFields(logrus.Fields{
 				"field":   field.Name,
 				"command": field.Cmd,
@@ -207,8 +221,19 @@ func updateSecrets(config secretgenerator.Config, client secrets.Client, disable
 				logger.Info("ignored field for disabled cluster")
 				continue
 			}
+			var env []string
+			for _, input := range field.Inputs {
+				value, ok := generated[input]
+				if !ok {
+					msg := fmt.Sprintf("input field %s was not generated", input)
+					logger.Error(msg)
+					errs = append(errs, errors.New(msg))
+					continue fields
+				}
+				env = append(env, fmt.Sprintf("%s=%s", secretgenerator.InputEnvName(input), value))
+			}
 			logger.Info("processing field")
-			out, err := executeCommand(field.Cmd)
+			out, err := executeCommand(field.Cmd, env)
 			if err != nil {
 				msg := "failed to generate field"
 				logger.WithError(err).Error(msg)
@@ -221,6 +246,7 @@ func updateSecrets(config secretgenerator.Config, client secrets.Client, disable
 				errs = append(errs, errors.New(msg))
 				continue
 			}
+			generated[field.Name] = out
 		}
 
 		// Adding the notes not empty check here since we dont want to overwrite any notes that might already be present
@@ -330,6 +356,12 @@ func validateContexts(contexts []secretbootstrap.ItemContext, config secretboots
 				if reflect.DeepEqual(needle, haystack) {
 					found = true
 				}
+				if haystack.DockerConfigJSONField != "" {
+					ctx := secretbootstrap.ItemContext{Item: haystack.Item, Field: haystack.DockerConfigJSONField}
+					if reflect.DeepEqual(needle, ctx) {
+						found = true
+					}
+				}
 				for _, dc := range haystack.DockerConfigJSONData {
 					ctx := secretbootstrap.ItemContext{
 						Item:  strings.TrimPrefix(dc.Item, config.VaultDPTPPrefix+"/"),
@@ -329,6 +329,7 @@ func TestExecuteCommand(t *testing.T) {
 	testCases := []struct {
 		name          string
 		cmd           string
+		env           []string
 		expected      []byte
 		expectedError error
 	}{
@@ -337,6 +338,12 @@ func TestExecuteCommand(t *testing.T) {
 			cmd:      "echo basic case",
 			expected: []byte("basic case\n"),
 		},
+		{
+			name:     "input fields are exposed in the environment",
+			cmd:      `echo "${FIELD_password}"`,
+			env:      []string{"FIELD_password=secret"},
+			expected: []byte("secret\n"),
+		},
 		{
 			name: "error on no output",
 			cmd:  "true",
@@ -383,7 +390,7 @@ error output:
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			actual, actualError := executeCommand(tc.cmd)
+			actual, actualError := executeCommand(tc.cmd, tc.env)
 			if diff := cmp.Diff(tc.expected, actual); diff != "" {
 				t.Errorf("%s: mismatch (-expected +actual), diff: %s", tc.name, diff)
 			}
@@ -404,6 +411,10 @@ func TestValidateConfig(t *testing.T) {
 			name:     "no cluster param",
 			expected: fmt.Errorf(`failed to find params['cluster'] in the 0 item with name "Item1"`),
 		},
+		{
+			name:     "input cycle",
+			expected: fmt.Errorf(`config[0]: item Item1: fields form a cycle: a -> b -> a`),
+		},
 		{
 			name: "valid",
 			expectedConfig: secretgenerator.Config{
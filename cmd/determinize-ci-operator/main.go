@@ -14,6 +14,7 @@ import (
 
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/load"
 )
 
 const (
@@ -31,6 +32,7 @@ type options struct {
 	templateMigrationAllowedBranches        flagutil.Strings
 	templateMigrationAllowedOrgs            flagutil.Strings
 	templateMigrationAllowedClusterProfiles flagutil.Strings
+	clusterProfilesConfig                   string
 }
 
 func (o options) validate() error {
@@ -53,6 +55,7 @@ func gatherOptions() options {
 	flag.Var(&o.templateMigrationAllowedBranches, "template-migration-allowed-branch", "Allowed branches to automigrate templates on. Can be passed multiple times. All branches are allowed if unset.")
 	flag.Var(&o.templateMigrationAllowedOrgs, "template-migration-allowed-org", "Allowed orgs to automigrate templates on. Can be passed multiple times. All orgs are allowed if unset.")
 	flag.Var(&o.templateMigrationAllowedClusterProfiles, "template-migration-allowed-cluster-profile", "Allowed cluster profiles to automigrate templates on. Can be passed multiple times. All cluster profiles are allowed if unset.")
+	flag.StringVar(&o.clusterProfilesConfig, "cluster-profiles-config", "", "Path to the cluster profiles config, whose aliases are accepted in addition to the built-in cluster profiles.")
 	flag.Parse()
 
 	return o
@@ -67,6 +70,13 @@ func main() {
 	if err := o.ConfirmableOptions.Complete(); err != nil {
 		logrus.Fatalf("Couldn't complete the config options: %v", err)
 	}
+	if o.clusterProfilesConfig != "" {
+		profiles, err := load.ClusterProfilesConfig(o.clusterProfilesConfig)
+		if err != nil {
+			logrus.Fatalf("Couldn't load the cluster profiles config: %v", err)
+		}
+		o.ClusterProfiles = profiles
+	}
 
 	var migratedCount int
 	var toCommit []config.DataWithInfo
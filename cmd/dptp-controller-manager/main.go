@@ -5,11 +5,13 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/bombsimon/logrusr/v3"
@@ -24,18 +26,21 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	prowconfig "sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/config/secret"
 	"sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 	"sigs.k8s.io/prow/pkg/interrupts"
 	"sigs.k8s.io/prow/pkg/kube"
 	"sigs.k8s.io/prow/pkg/logrusutil"
+	"sigs.k8s.io/prow/pkg/pjutil"
 	"sigs.k8s.io/prow/pkg/pjutil/pprof"
 
 	imagev1 "github.com/openshift/api/image/v1"
 
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/controller/orphanednamespacecleaner"
 	"github.com/openshift/ci-tools/pkg/controller/promotionreconciler"
 	serviceaccountsecretrefresher "github.com/openshift/ci-tools/pkg/controller/serviceaccount_secret_refresher"
 	testimagesdistributor "github.com/openshift/ci-tools/pkg/controller/test-images-distributor"
@@ -54,6 +59,7 @@ var allControllers = sets.New[string](
 	testimagesdistributor.ControllerName,
 	serviceaccountsecretrefresher.ControllerName,
 	testimagestreamimportcleaner.ControllerName,
+	orphanednamespacecleaner.ControllerName,
 )
 
 type options struct {
@@ -65,6 +71,7 @@ type options struct {
 	leaderElectionSuffix                 string
 	enabledControllers                   flagutil.Strings
 	enabledControllersSet                sets.Set[string]
+	isolated                             bool
 	registryClusterName                  string
 	dryRun                               bool
 	blockProfileRate                     time.Duration
@@ -72,6 +79,9 @@ type options struct {
 	serviceAccountSecretRefresherOptions serviceAccountSecretRefresherOptions
 	imagePusherOptions                   imagePusherOptions
 	promotionReconcilerOptions           promotionReconcilerOptions
+	testImageStreamImportCleanerOptions  testImageStreamImportCleanerOptions
+	orphanNamespaceTTL                   time.Duration
+	kubeconfigChangeGracePeriod          time.Duration
 	*flagutil.GitHubOptions
 	releaseRepoGitSyncPath string
 }
@@ -91,6 +101,10 @@ type testImagesDistributorOptions struct {
 	forbiddenRegistries                sets.Set[string]
 	ignoreClusterNamesRaw              flagutil.Strings
 	ignoreClusterNames                 sets.Set[string]
+	ignoreImageStreamTagsRaw           flagutil.Strings
+	ignoreImageStreamTags              []*regexp.Regexp
+	maxConcurrentSyncs                 int64
+	syncQPS                            float64
 }
 
 type promotionReconcilerOptions struct {
@@ -98,6 +112,10 @@ type promotionReconcilerOptions struct {
 	ignoreImageStreams    []*regexp.Regexp
 	sinceRaw              string
 	since                 time.Duration
+	sinceOverridesRaw     flagutil.Strings
+	sinceOverrides        []promotionreconciler.SinceOverride
+	// secondaryRegistryClusterNames are the clusters whose registries promoted tags are mirrored to
+	secondaryRegistryClusterNames flagutil.Strings
 }
 
 type imagePusherOptions struct {
@@ -105,9 +123,15 @@ type imagePusherOptions struct {
 	imageStreams    sets.Set[string]
 }
 
+type testImageStreamImportCleanerOptions struct {
+	namespaces       flagutil.Strings
+	ignoreNamespaces flagutil.Strings
+}
+
 type serviceAccountSecretRefresherOptions struct {
 	enabledNamespaces     flagutil.Strings
 	removeOldSecrets      bool
+	maxSecretAge          time.Duration
 	ignoreServiceAccounts flagutil.Strings
 }
 
@@ -124,19 +148,30 @@ func newOpts() (*options, error) {
 	fs.StringVar(&opts.stepConfigPath, "step-config-path", "", "Path to the registries step configuration")
 	fs.StringVar(&opts.leaderElectionSuffix, "leader-election-suffix", "", "Suffix for the leader election lock. Useful for local testing. If set, --dry-run must be set as well")
 	fs.Var(&opts.enabledControllers, "enable-controller", fmt.Sprintf("Enabled controllers. Available controllers are: %v. Can be specified multiple times. Defaults to %v", sets.List(allControllers), opts.enabledControllers.Strings()))
+	fs.BoolVar(&opts.isolated, "isolated", false, "Run exactly one controller passed via --enable-controller in its own process. The leader election lock is specific to the controller and only the clients the controller needs are constructed.")
 	fs.Var(&opts.testImagesDistributorOptions.additionalImageStreamTagsRaw, "testImagesDistributorOptions.additional-image-stream-tag", "An imagestreamtag that will be distributed even if no test explicitly references it. It must be in namespace/name:tag format (e.G `ci/clonerefs:latest`). Can be passed multiple times.")
 	fs.Var(&opts.testImagesDistributorOptions.additionalImageStreamsRaw, "testImagesDistributorOptions.additional-image-stream", "An imagestream that will be distributed even if no test explicitly references it. It must be in namespace/name format (e.G `ci/clonerefs`). Can be passed multiple times.")
 	fs.Var(&opts.testImagesDistributorOptions.additionalImageStreamNamespacesRaw, "testImagesDistributorOptions.additional-image-stream-namespace", "A namespace in which imagestreams will be distributed even if no test explicitly references them (e.G `ci`). Can be passed multiple times.")
 	fs.Var(&opts.testImagesDistributorOptions.forbiddenRegistriesRaw, "testImagesDistributorOptions.forbidden-registry", "The hostname of an image registry from which there is no synchronization of its images. Can be passed multiple times.")
 	fs.Var(&opts.testImagesDistributorOptions.ignoreClusterNamesRaw, "testImagesDistributorOptions.ignore-cluster-name", "The cluster name to which there is no synchronization of test images. Can be passed multiple times.")
+	fs.Var(&opts.testImagesDistributorOptions.ignoreImageStreamTagsRaw, "testImagesDistributorOptions.ignore-image-stream-tag", "A regex matched against imagestreamtags in namespace/name:tag format (e.G `.*:.*-source`). Matching imagestreamtags are never distributed, even if a test references them. Can be passed multiple times.")
+	fs.Int64Var(&opts.testImagesDistributorOptions.maxConcurrentSyncs, "testImagesDistributorOptions.max-concurrent-syncs", 0, "The maximum number of image imports in flight, each with its own worker. Zero keeps a single worker.")
+	fs.Float64Var(&opts.testImagesDistributorOptions.syncQPS, "testImagesDistributorOptions.sync-qps", 0, "The maximum number of image imports per second. Zero means unlimited.")
 	fs.DurationVar(&opts.blockProfileRate, "block-profile-rate", time.Duration(0), "The block profile rate. Set to non-zero to enable.")
 	fs.StringVar(&opts.registryClusterName, "registry-cluster-name", "app.ci", "the cluster name on which the CI central registry is running")
 	fs.Var(&opts.serviceAccountSecretRefresherOptions.enabledNamespaces, "serviceAccountRefresherOptions.enabled-namespace", "A namespace for which the serviceaccount_secret_refresher should be enabled. Can be passed multiple times.")
-	fs.BoolVar(&opts.serviceAccountSecretRefresherOptions.removeOldSecrets, "serviceAccountRefresherOptions.remove-old-secrets", false, "whether the serviceaccountsecretrefresher should delete secrets older than 30 days")
+	fs.BoolVar(&opts.serviceAccountSecretRefresherOptions.removeOldSecrets, "serviceAccountRefresherOptions.remove-old-secrets", false, "whether the serviceaccountsecretrefresher should delete secrets that are older than twice the max-secret-age")
+	fs.DurationVar(&opts.serviceAccountSecretRefresherOptions.maxSecretAge, "serviceAccountRefresherOptions.max-secret-age", 30*24*time.Hour, "The age after which the serviceaccountsecretrefresher rotates a pull secret. Defaults to 720h, i.e., 30 days")
 	fs.Var(&opts.serviceAccountSecretRefresherOptions.ignoreServiceAccounts, "serviceAccountRefresherOptions.ignore-service-account", "The service account to ignore. It must be in namespace/name format (e.G `ci/sync-rover-groups-updater`). Can be passed multiple times.")
 	fs.Var(&opts.imagePusherOptions.imageStreamsRaw, "imagePusherOptions.image-stream", "An imagestream that will be synced. It must be in namespace/name format (e.G `ci/clonerefs`). Can be passed multiple times.")
 	fs.Var(&opts.promotionReconcilerOptions.ignoreImageStreamsRaw, "promotionReconcilerOptions.ignore-image-stream", "The image stream to ignore. It is an regular expression (e.G ^openshift-priv/.+). Can be passed multiple times.")
 	fs.StringVar(&opts.promotionReconcilerOptions.sinceRaw, "promotionReconcilerOptions.since", "360h", "The image stream tags to reconcile if it is younger than a relative duration like 5s, 2m, or 3h. Defaults to 360h, i.e., 15 days")
+	fs.Var(&opts.promotionReconcilerOptions.sinceOverridesRaw, "promotionReconcilerOptions.since-override", "Overrides --promotionReconcilerOptions.since for the image streams matching a regular expression, in regex=duration notation (e.G ^ocp/4\\.1[0-9]:.+=720h). The first matching override is used. Can be passed multiple times.")
+	fs.Var(&opts.testImageStreamImportCleanerOptions.namespaces, "testImageStreamImportCleanerOptions.namespace", fmt.Sprintf("A namespace in which the %s controller cleans up imports. If unset, all namespaces are cleaned up. Can be passed multiple times.", testimagestreamimportcleaner.ControllerName))
+	fs.Var(&opts.testImageStreamImportCleanerOptions.ignoreNamespaces, "testImageStreamImportCleanerOptions.ignore-namespace", fmt.Sprintf("A namespace in which the %s controller never cleans up imports. Can be passed multiple times.", testimagestreamimportcleaner.ControllerName))
+	fs.DurationVar(&opts.orphanNamespaceTTL, "orphan-namespace-ttl", 24*time.Hour, fmt.Sprintf("The time after the completion of its ProwJob after which the %s controller deletes a ci-operator namespace", orphanednamespacecleaner.ControllerName))
+	fs.Var(&opts.promotionReconcilerOptions.secondaryRegistryClusterNames, "promotionReconcilerOptions.secondary-registry-cluster-name", "The name of a cluster with a secondary registry to which promoted tags are mirrored. Can be passed multiple times.")
+	fs.DurationVar(&opts.kubeconfigChangeGracePeriod, "kubeconfig-change-grace-period", 0, "How long to let in-flight reconciles finish before exiting when the kubeconfig changes. No new reconciles are started during this time. Zero means exiting immediately.")
 	fs.BoolVar(&opts.dryRun, "dry-run", true, "Whether to run the controller-manager with dry-run")
 	fs.StringVar(&opts.releaseRepoGitSyncPath, "release-repo-git-sync-path", "", "Path to release repository dir")
 	if err := fs.Parse(os.Args[1:]); err != nil {
@@ -181,6 +216,10 @@ func newOpts() (*options, error) {
 		}
 	}
 
+	if opts.isolated && opts.enabledControllersSet.Len() != 1 {
+		errs = append(errs, fmt.Errorf("--isolated requires exactly one --enable-controller, got %d", opts.enabledControllersSet.Len()))
+	}
+
 	isTags, isTagErrors := completeImageStreamTags("testImagesDistributorOptions.additional-image-stream-tag", opts.testImagesDistributorOptions.additionalImageStreamTagsRaw)
 	errs = append(errs, isTagErrors...)
 	opts.testImagesDistributorOptions.additionalImageStreamTags = isTags
@@ -193,6 +232,15 @@ func newOpts() (*options, error) {
 	opts.testImagesDistributorOptions.forbiddenRegistries = completeSet(opts.testImagesDistributorOptions.forbiddenRegistriesRaw)
 	opts.testImagesDistributorOptions.ignoreClusterNames = completeSet(opts.testImagesDistributorOptions.ignoreClusterNamesRaw)
 
+	for _, raw := range opts.testImagesDistributorOptions.ignoreImageStreamTagsRaw.Strings() {
+		re, err := regexp.Compile(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("--testImagesDistributorOptions.ignore-image-stream-tag: failed to compile regex from %q: %w", raw, err))
+			continue
+		}
+		opts.testImagesDistributorOptions.ignoreImageStreamTags = append(opts.testImagesDistributorOptions.ignoreImageStreamTags, re)
+	}
+
 	imagePusherImageStreams, isErrors := completeImageStream("uniRegistrySyncerOptions.image-stream", opts.imagePusherOptions.imageStreamsRaw)
 	errs = append(errs, isErrors...)
 	opts.imagePusherOptions.imageStreams = imagePusherImageStreams
@@ -214,15 +262,46 @@ func newOpts() (*options, error) {
 			opts.promotionReconcilerOptions.since = since
 		}
 	}
+	for _, raw := range opts.promotionReconcilerOptions.sinceOverridesRaw.Strings() {
+		override, err := parseSinceOverride(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("--promotionReconcilerOptions.since-override is invalid: %w", err))
+			continue
+		}
+		opts.promotionReconcilerOptions.sinceOverrides = append(opts.promotionReconcilerOptions.sinceOverrides, override)
+	}
 
 	if opts.enabledControllersSet.Has(testimagesdistributor.ControllerName) && opts.stepConfigPath == "" {
 		errs = append(errs, fmt.Errorf("--step-config-path is required when the %s controller is enabled", testimagesdistributor.ControllerName))
 	}
+	if opts.testImagesDistributorOptions.maxConcurrentSyncs < 0 {
+		errs = append(errs, errors.New("--testImagesDistributorOptions.max-concurrent-syncs must not be negative"))
+	}
+	if opts.testImagesDistributorOptions.syncQPS < 0 {
+		errs = append(errs, errors.New("--testImagesDistributorOptions.sync-qps must not be negative"))
+	}
 
 	if opts.enabledControllersSet.Has(serviceaccountsecretrefresher.ControllerName) {
 		if len(opts.serviceAccountSecretRefresherOptions.enabledNamespaces.Strings()) == 0 {
 			errs = append(errs, fmt.Errorf("--serviceAccountRefresherOptions.enabled-namespace must be set at least once when enabling the %s controller, otherwise it won't do anything", serviceaccountsecretrefresher.ControllerName))
 		}
+		if opts.serviceAccountSecretRefresherOptions.maxSecretAge <= 0 {
+			errs = append(errs, errors.New("--serviceAccountRefresherOptions.max-secret-age must be positive"))
+		}
+	}
+
+	if opts.enabledControllersSet.Has(orphanednamespacecleaner.ControllerName) && opts.orphanNamespaceTTL <= 0 {
+		errs = append(errs, fmt.Errorf("--orphan-namespace-ttl must be positive when the %s controller is enabled", orphanednamespacecleaner.ControllerName))
+	}
+
+	if both := sets.New[string](opts.testImageStreamImportCleanerOptions.namespaces.Strings()...).Intersection(sets.New[string](opts.testImageStreamImportCleanerOptions.ignoreNamespaces.Strings()...)); both.Len() > 0 {
+		errs = append(errs, fmt.Errorf("--testImageStreamImportCleanerOptions.namespace and --testImageStreamImportCleanerOptions.ignore-namespace must not both contain %s", strings.Join(sets.List(both), ", ")))
+	}
+
+	for _, cluster := range opts.promotionReconcilerOptions.secondaryRegistryClusterNames.Strings() {
+		if cluster == opts.registryClusterName {
+			errs = append(errs, fmt.Errorf("--promotionReconcilerOptions.secondary-registry-cluster-name must not be the registry cluster %s", cluster))
+		}
 	}
 
 	if err := opts.GitHubOptions.Validate(opts.dryRun); err != nil {
@@ -234,6 +313,33 @@ func newOpts() (*options, error) {
 	return opts, utilerrors.NewAggregate(errs)
 }
 
+// leaderElectionID returns the name of the leader election lock. Isolated processes each
+// run a single controller, so they get a lock of their own.
+func (o *options) leaderElectionID() string {
+	if o.isolated {
+		controller := strings.ReplaceAll(sets.List(o.enabledControllersSet)[0], "_", "-")
+		return fmt.Sprintf("dptp-controller-manager-%s%s", controller, o.leaderElectionSuffix)
+	}
+	return fmt.Sprintf("dptp-controller-manager%s", o.leaderElectionSuffix)
+}
+
+// needsAnyOf determines whether some of the controllers that need a client or an agent are enabled.
+// Everything is constructed unless the process is isolated, to keep the behavior of shared processes.
+func (o *options) needsAnyOf(controllers ...string) bool {
+	return !o.isolated || o.enabledControllersSet.HasAny(controllers...)
+}
+
+// requiredClusters returns the clusters managers have to be constructed for. The promotionreconciler
+// only acts on the registry clusters, all other controllers need every cluster.
+func (o *options) requiredClusters(all sets.Set[string]) sets.Set[string] {
+	if !o.isolated || !o.enabledControllersSet.Has(promotionreconciler.ControllerName) {
+		return all
+	}
+	required := sets.New[string](appCIContextName, o.registryClusterName)
+	required.Insert(o.promotionReconcilerOptions.secondaryRegistryClusterNames.Strings()...)
+	return all.Intersection(required)
+}
+
 func completeImageStreamTags(name string, raw flagutil.Strings) (sets.Set[string], []error) {
 	isTags := sets.Set[string]{}
 	var errs []error
@@ -280,6 +386,53 @@ func completeSet(raw flagutil.Strings) sets.Set[string] {
 	return result
 }
 
+// parseSinceOverride parses a regex=duration pair. The regex may contain
+// equal signs itself, so the pair is split on the last one.
+func parseSinceOverride(raw string) (promotionreconciler.SinceOverride, error) {
+	idx := strings.LastIndex(raw, "=")
+	if idx < 1 {
+		return promotionreconciler.SinceOverride{}, fmt.Errorf("%q is not in regex=duration notation", raw)
+	}
+	re, err := regexp.Compile(raw[:idx])
+	if err != nil {
+		return promotionreconciler.SinceOverride{}, fmt.Errorf("failed to compile regex from %q: %w", raw[:idx], err)
+	}
+	since, err := time.ParseDuration(raw[idx+1:])
+	if err != nil {
+		return promotionreconciler.SinceOverride{}, fmt.Errorf("failed to parse duration from %q: %w", raw, err)
+	}
+	return promotionreconciler.SinceOverride{ImageStreams: re, Since: since}, nil
+}
+
+// cachesSyncedCheck returns a readiness check that only passes once the caches of all
+// given clusters have synced, so controllers never act on empty caches.
+func cachesSyncedCheck(ctx context.Context, caches map[string]cache.Cache) pjutil.ReadinessCheck {
+	var synced atomic.Bool
+	go func() {
+		for cluster, clusterCache := range caches {
+			if !clusterCache.WaitForCacheSync(ctx) {
+				logrus.WithField("cluster", cluster).Warn("Context ended before the cache synced")
+				return
+			}
+			logrus.WithField("cluster", cluster).Info("Cache synced")
+		}
+		synced.Store(true)
+	}()
+	return synced.Load
+}
+
+// readinessHandler responds with 200 while the check passes and with 503 otherwise
+func readinessHandler(check pjutil.ReadinessCheck) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if !check() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, "ReadinessCheck failed")
+			return
+		}
+		fmt.Fprint(w, "OK")
+	})
+}
+
 func main() {
 	logrusutil.ComponentInit()
 	controllerruntime.SetLogger(logrusr.New(logrus.StandardLogger()))
@@ -301,8 +454,15 @@ func main() {
 	ctx := controllerruntime.SetupSignalHandler()
 	ctx, cancel := context.WithCancel(ctx)
 
+	drainer := &controllerutil.Drainer{}
 	kubeconfigChangedCallBack := func() {
 		logrus.Info("Kubeconfig changed, exiting to get restarted by Kubelet and pick up the changes")
+		if opts.kubeconfigChangeGracePeriod > 0 {
+			logrus.WithField("grace_period", opts.kubeconfigChangeGracePeriod.String()).Info("Waiting for in-flight reconciles to finish")
+			if !drainer.Drain(opts.kubeconfigChangeGracePeriod) {
+				logrus.Warn("Not all in-flight reconciles finished within the grace period")
+			}
+		}
 		cancel()
 	}
 
@@ -351,15 +511,21 @@ func main() {
 		}
 		interrupts.Run(watcher)
 	}
-	configErrCh := make(chan error)
-	ciOPConfigAgent, err := agents.NewConfigAgent(opts.ciOperatorconfigPath, configErrCh, configAgentOption)
-	if err != nil {
-		logrus.WithError(err).Fatal("Failed to construct ci-operator config agent")
+	var ciOPConfigAgent agents.ConfigAgent
+	if opts.needsAnyOf(promotionreconciler.ControllerName, testimagesdistributor.ControllerName) {
+		configErrCh := make(chan error)
+		ciOPConfigAgent, err = agents.NewConfigAgent(opts.ciOperatorconfigPath, configErrCh, configAgentOption)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to construct ci-operator config agent")
+		}
+		go func() { logrus.Fatal(<-configErrCh) }()
 	}
-	go func() { logrus.Fatal(<-configErrCh) }()
-	configAgent, err := opts.prowconfig.ConfigAgent()
-	if err != nil {
-		logrus.WithError(err).Fatal("Failed to start config agent")
+	var configAgent *prowconfig.Agent
+	if opts.needsAnyOf(promotionreconciler.ControllerName, orphanednamespacecleaner.ControllerName) {
+		configAgent, err = opts.prowconfig.ConfigAgent()
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to start config agent")
+		}
 	}
 
 	allManagers := map[string]controllerruntime.Manager{}
@@ -367,8 +533,13 @@ func main() {
 	var registryMgr controllerruntime.Manager
 
 	var errs []error
+	requiredClusters := opts.requiredClusters(sets.KeySet(kubeconfigs))
 	for cluster, cfg := range kubeconfigs {
 		cluster, cfg := cluster, cfg
+		if !requiredClusters.Has(cluster) {
+			logrus.WithField("cluster", cluster).Info("Not creating a manager for a cluster that the enabled controller does not use")
+			continue
+		}
 		if _, alreadyExists := allManagers[cluster]; alreadyExists {
 			logrus.Fatalf("attempted duplicate creation of manager for cluster %s", cluster)
 		}
@@ -382,7 +553,7 @@ func main() {
 			options.LeaderElection = true
 			options.LeaderElectionReleaseOnCancel = true
 			options.LeaderElectionNamespace = opts.leaderElectionNamespace
-			options.LeaderElectionID = fmt.Sprintf("dptp-controller-manager%s", opts.leaderElectionSuffix)
+			options.LeaderElectionID = opts.leaderElectionID()
 		} else {
 			options.Metrics = server.Options{
 				BindAddress: "0",
@@ -424,6 +595,20 @@ func main() {
 		logrus.WithError(err).Fatal("Failed to add prowv1 to scheme")
 	}
 	pprof.Serve(flagutil.DefaultPProfPort)
+	// The registry cluster and all build clusters are in allManagers, so this covers every cache the controllers use
+	caches := map[string]cache.Cache{}
+	for cluster, clusterMgr := range allManagers {
+		caches[cluster] = clusterMgr.GetCache()
+	}
+	// The health endpoints are served by the metrics server of the app.ci manager rather than a server of their own
+	for path, handler := range map[string]http.Handler{
+		"/healthz":       readinessHandler(func() bool { return true }),
+		"/healthz/ready": readinessHandler(cachesSyncedCheck(ctx, caches)),
+	} {
+		if err := mgr.AddMetricsServerExtraHandler(path, handler); err != nil {
+			logrus.WithError(err).Fatalf("Failed to serve %s", path)
+		}
+	}
 
 	for cluster, buildClusterMgr := range allManagers {
 		if cluster == appCIContextName {
@@ -449,21 +634,34 @@ func main() {
 		if err != nil {
 			logrus.WithError(err).Fatal("Failed to get gitHubClient")
 		}
+		secondaryRegistryManagers := map[string]controllerruntime.Manager{}
+		for _, cluster := range opts.promotionReconcilerOptions.secondaryRegistryClusterNames.Strings() {
+			secondaryMgr, ok := allManagers[cluster]
+			if !ok {
+				logrus.Fatalf("--kubeconfig must include a context named `%s`", cluster)
+			}
+			secondaryRegistryManagers[cluster] = secondaryMgr
+		}
 		promotionreconcilerOptions := promotionreconciler.Options{
-			DryRun:                opts.dryRun,
-			CIOperatorConfigAgent: ciOPConfigAgent,
-			ConfigGetter:          configAgent.Config,
-			GitHubClient:          gitHubClient,
-			RegistryManager:       registryMgr,
-			IgnoredImageStreams:   opts.promotionReconcilerOptions.ignoreImageStreams,
-			Since:                 opts.promotionReconcilerOptions.since,
+			DryRun:                    opts.dryRun,
+			CIOperatorConfigAgent:     ciOPConfigAgent,
+			ConfigGetter:              configAgent.Config,
+			GitHubClient:              gitHubClient,
+			RegistryManager:           registryMgr,
+			RegistryClusterName:       opts.registryClusterName,
+			SecondaryRegistryManagers: secondaryRegistryManagers,
+			IgnoredImageStreams:       opts.promotionReconcilerOptions.ignoreImageStreams,
+			Since:                     opts.promotionReconcilerOptions.since,
+			SinceOverrides:            opts.promotionReconcilerOptions.sinceOverrides,
+			Drainer:                   drainer,
 		}
 		if err := promotionreconciler.AddToManager(mgr, promotionreconcilerOptions); err != nil {
 			logrus.WithError(err).Fatal("Failed to add imagestreamtagreconciler")
 		}
 	}
 
-	if opts.enabledControllersSet.Has(testimagesdistributor.ControllerName) {
+	mirrorsToSecondaryRegistries := opts.enabledControllersSet.Has(promotionreconciler.ControllerName) && len(opts.promotionReconcilerOptions.secondaryRegistryClusterNames.Strings()) > 0
+	if opts.enabledControllersSet.Has(testimagesdistributor.ControllerName) || mirrorsToSecondaryRegistries {
 		if err := controllerutil.RegisterMetrics(); err != nil {
 			logrus.WithError(err).Fatal("failed to register metrics")
 		}
@@ -498,8 +696,12 @@ func main() {
 			opts.testImagesDistributorOptions.additionalImageStreamTags,
 			opts.testImagesDistributorOptions.additionalImageStreams,
 			opts.testImagesDistributorOptions.additionalImageStreamNamespaces,
+			opts.testImagesDistributorOptions.ignoreImageStreamTags,
 			opts.testImagesDistributorOptions.forbiddenRegistries,
 			opts.testImagesDistributorOptions.ignoreClusterNames,
+			opts.testImagesDistributorOptions.maxConcurrentSyncs,
+			opts.testImagesDistributorOptions.syncQPS,
+			drainer,
 		); err != nil {
 			logrus.WithError(err).Fatal("failed to add testimagesdistributor")
 		}
@@ -507,18 +709,30 @@ func main() {
 
 	if opts.enabledControllersSet.Has(serviceaccountsecretrefresher.ControllerName) {
 		for clusterName, clusterMgr := range allManagers {
-			if err := serviceaccountsecretrefresher.AddToManager(clusterName, clusterMgr, opts.serviceAccountSecretRefresherOptions.enabledNamespaces.StringSet(), opts.serviceAccountSecretRefresherOptions.ignoreServiceAccounts.StringSet(), opts.serviceAccountSecretRefresherOptions.removeOldSecrets); err != nil {
+			if err := serviceaccountsecretrefresher.AddToManager(clusterName, clusterMgr, opts.serviceAccountSecretRefresherOptions.enabledNamespaces.StringSet(), opts.serviceAccountSecretRefresherOptions.ignoreServiceAccounts.StringSet(), opts.serviceAccountSecretRefresherOptions.removeOldSecrets, opts.serviceAccountSecretRefresherOptions.maxSecretAge, drainer); err != nil {
 				logrus.WithError(err).Fatalf("Failed to add the %s controller to the %s cluster", serviceaccountsecretrefresher.ControllerName, clusterName)
 			}
 		}
 	}
 
 	if opts.enabledControllersSet.Has(testimagestreamimportcleaner.ControllerName) {
-		if err := testimagestreamimportcleaner.AddToManager(mgr, allManagers); err != nil {
+		if err := testimagestreamimportcleaner.AddToManager(mgr, allManagers, sets.New[string](opts.testImageStreamImportCleanerOptions.namespaces.Strings()...), sets.New[string](opts.testImageStreamImportCleanerOptions.ignoreNamespaces.Strings()...), drainer); err != nil {
 			logrus.WithError(err).Fatal("Failed to construct the testimagestreamimportcleaner controller")
 		}
 	}
 
+	if opts.enabledControllersSet.Has(orphanednamespacecleaner.ControllerName) {
+		buildClusterManagers := map[string]controllerruntime.Manager{}
+		for cluster, clusterMgr := range allManagers {
+			if cluster != appCIContextName {
+				buildClusterManagers[cluster] = clusterMgr
+			}
+		}
+		if err := orphanednamespacecleaner.AddToManager(mgr, buildClusterManagers, configAgent.Config().ProwJobNamespace, opts.orphanNamespaceTTL, drainer); err != nil {
+			logrus.WithError(err).Fatalf("Failed to construct the %s controller", orphanednamespacecleaner.ControllerName)
+		}
+	}
+
 	if err := mgr.Start(ctx); err != nil {
 		logrus.WithError(err).Fatal("Manager ended with error")
 	}
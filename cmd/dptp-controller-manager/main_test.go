@@ -1,14 +1,22 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/prow/pkg/flagutil"
 
+	"github.com/openshift/ci-tools/pkg/controller/promotionreconciler"
+	serviceaccountsecretrefresher "github.com/openshift/ci-tools/pkg/controller/serviceaccount_secret_refresher"
 	"github.com/openshift/ci-tools/pkg/testhelper"
 )
 
@@ -119,3 +127,199 @@ func TestCompleteSet(t *testing.T) {
 		})
 	}
 }
+
+type fakeCache struct {
+	cache.Cache
+	synced chan struct{}
+}
+
+func (c *fakeCache) WaitForCacheSync(ctx context.Context) bool {
+	select {
+	case <-c.synced:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func TestCachesSyncedCheck(t *testing.T) {
+	t.Run("ready once all caches synced", func(t *testing.T) {
+		appCI, build01 := &fakeCache{synced: make(chan struct{})}, &fakeCache{synced: make(chan struct{})}
+		check := cachesSyncedCheck(context.Background(), map[string]cache.Cache{"app.ci": appCI, "build01": build01})
+		if check() {
+			t.Fatal("expected not to be ready before any cache synced")
+		}
+		close(appCI.synced)
+		if err := wait.PollUntilContextTimeout(context.Background(), time.Millisecond, 100*time.Millisecond, true, func(context.Context) (bool, error) {
+			return check(), nil
+		}); err == nil {
+			t.Fatal("expected not to be ready while the build01 cache didn't sync")
+		}
+		close(build01.synced)
+		if err := wait.PollUntilContextTimeout(context.Background(), time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+			return check(), nil
+		}); err != nil {
+			t.Fatalf("expected to be ready after all caches synced: %v", err)
+		}
+	})
+
+	t.Run("never ready when the context ends first", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		check := cachesSyncedCheck(ctx, map[string]cache.Cache{"app.ci": &fakeCache{synced: make(chan struct{})}})
+		if err := wait.PollUntilContextTimeout(context.Background(), time.Millisecond, 100*time.Millisecond, true, func(context.Context) (bool, error) {
+			return check(), nil
+		}); err == nil {
+			t.Fatal("expected not to be ready")
+		}
+	})
+}
+
+func TestParseSinceOverride(t *testing.T) {
+	testCases := []struct {
+		name          string
+		raw           string
+		expectedRegex string
+		expectedSince time.Duration
+		expectedErr   error
+	}{
+		{
+			name:          "valid override",
+			raw:           `^ocp/4\.1[0-9]:.+=720h`,
+			expectedRegex: `^ocp/4\.1[0-9]:.+`,
+			expectedSince: 720 * time.Hour,
+		},
+		{
+			name:          "regex containing an equal sign",
+			raw:           `^ci/a=b:.+=1h`,
+			expectedRegex: `^ci/a=b:.+`,
+			expectedSince: time.Hour,
+		},
+		{
+			name:        "no equal sign",
+			raw:         `^ocp/.+`,
+			expectedErr: fmt.Errorf(`"^ocp/.+" is not in regex=duration notation`),
+		},
+		{
+			name:        "invalid duration",
+			raw:         `^ocp/.+=forever`,
+			expectedErr: fmt.Errorf(`failed to parse duration from "^ocp/.+=forever": time: invalid duration "forever"`),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := parseSinceOverride(tc.raw)
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("error differs from expected:\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if actual.ImageStreams.String() != tc.expectedRegex || actual.Since != tc.expectedSince {
+				t.Errorf("expected %s=%s, got %s=%s", tc.expectedRegex, tc.expectedSince, actual.ImageStreams, actual.Since)
+			}
+		})
+	}
+}
+
+func TestLeaderElectionID(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     options
+		expected string
+	}{
+		{
+			name:     "shared process",
+			opts:     options{enabledControllersSet: sets.New[string](promotionreconciler.ControllerName, serviceaccountsecretrefresher.ControllerName)},
+			expected: "dptp-controller-manager",
+		},
+		{
+			name:     "shared process with suffix",
+			opts:     options{enabledControllersSet: sets.New[string](promotionreconciler.ControllerName), leaderElectionSuffix: "-dev"},
+			expected: "dptp-controller-manager-dev",
+		},
+		{
+			name:     "isolated process",
+			opts:     options{enabledControllersSet: sets.New[string](serviceaccountsecretrefresher.ControllerName), isolated: true},
+			expected: "dptp-controller-manager-serviceaccount-secret-refresher",
+		},
+		{
+			name:     "isolated process with suffix",
+			opts:     options{enabledControllersSet: sets.New[string](promotionreconciler.ControllerName), isolated: true, leaderElectionSuffix: "-dev"},
+			expected: "dptp-controller-manager-promotionreconciler-dev",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.opts.leaderElectionID(); actual != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRequiredClusters(t *testing.T) {
+	all := sets.New[string]("app.ci", "build01", "build02", "build03")
+	testCases := []struct {
+		name     string
+		opts     options
+		expected sets.Set[string]
+	}{
+		{
+			name:     "shared process needs all clusters",
+			opts:     options{enabledControllersSet: sets.New[string](promotionreconciler.ControllerName), registryClusterName: "app.ci"},
+			expected: all,
+		},
+		{
+			name:     "isolated controller other than the promotionreconciler needs all clusters",
+			opts:     options{enabledControllersSet: sets.New[string](serviceaccountsecretrefresher.ControllerName), registryClusterName: "app.ci", isolated: true},
+			expected: all,
+		},
+		{
+			name:     "isolated promotionreconciler only needs the registry clusters",
+			opts:     options{enabledControllersSet: sets.New[string](promotionreconciler.ControllerName), registryClusterName: "app.ci", isolated: true},
+			expected: sets.New[string]("app.ci"),
+		},
+		{
+			name: "isolated promotionreconciler needs the secondary registry clusters",
+			opts: options{
+				enabledControllersSet:      sets.New[string](promotionreconciler.ControllerName),
+				registryClusterName:        "app.ci",
+				isolated:                   true,
+				promotionReconcilerOptions: promotionReconcilerOptions{secondaryRegistryClusterNames: flagutil.NewStrings("build02")},
+			},
+			expected: sets.New[string]("app.ci", "build02"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, tc.opts.requiredClusters(all)); diff != "" {
+				t.Errorf("unexpected clusters (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestReadinessHandler(t *testing.T) {
+	testCases := []struct {
+		name         string
+		ready        bool
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "ready", ready: true, expectedCode: http.StatusOK, expectedBody: "OK"},
+		{name: "not ready", expectedCode: http.StatusServiceUnavailable, expectedBody: "ReadinessCheck failed"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			readinessHandler(func() bool { return tc.ready }).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz/ready", nil))
+			if recorder.Code != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, recorder.Code)
+			}
+			if diff := cmp.Diff(tc.expectedBody, recorder.Body.String()); diff != "" {
+				t.Errorf("body differs from expected: %s", diff)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ type presubmitTests struct {
 	alwaysRequired                []string
 	conditionallyRequired         []string
 	pipelineConditionallyRequired []config.Presubmit
+	optional                      []config.Presubmit
 }
 
 type ConfigDataProvider struct {
@@ -99,6 +100,11 @@ func (c *ConfigDataProvider) gatherData() {
 				}
 				continue
 			}
+			if p.Optional {
+				pre := updatedPresubmits[orgRepo]
+				pre.optional = append(pre.optional, p)
+				updatedPresubmits[orgRepo] = pre
+			}
 		}
 	}
 	c.m.Lock()
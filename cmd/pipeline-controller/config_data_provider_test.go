@@ -115,7 +115,11 @@ func TestConfigDataProviderGatherData(t *testing.T) {
 				pipelineConditionallyRequired: []config.Presubmit{
 					composePipelineCondRequiredPresubmit("ps4", false, map[string]string{"pipeline_run_if_changed": ".*"}),
 					composePipelineCondRequiredPresubmit("ps5", true, map[string]string{"pipeline_run_if_changed": ".*"}),
-				}},
+				},
+				optional: []config.Presubmit{
+					composePipelineCondRequiredPresubmit("ps6", true, map[string]string{}),
+				},
+			},
 		},
 		{
 			name: "Org policy and repo require manual trigger",
@@ -131,7 +135,7 @@ func TestConfigDataProviderGatherData(t *testing.T) {
 					ProwConfig: decorateWithRepoPolicy(decorateWithOrgPolicy(composeBPConfig())),
 				}
 			},
-			expected: presubmitTests{protected: []string{"ps1"}, alwaysRequired: []string{"ps2"}},
+			expected: presubmitTests{protected: []string{"ps1"}, alwaysRequired: []string{"ps2"}, optional: []config.Presubmit{{JobBase: config.JobBase{Name: "ps3"}, Optional: true}}},
 		},
 		{
 			name: "No manual trigger required",
@@ -19,16 +19,21 @@ type enabledConfig struct {
 	} `yaml:"orgs"`
 }
 
+// regularConfig is the name of the config with the enabled orgs and repos
+const regularConfig = "regular"
+
 // watcher struct encapsulates the file watcher and configuration
 type watcher struct {
+	name     string
 	filePath string
 	config   enabledConfig
 	mutex    sync.Mutex
 	logger   *logrus.Entry
 }
 
-func newWatcher(filePath string, logger *logrus.Entry) *watcher {
+func newWatcher(name, filePath string, logger *logrus.Entry) *watcher {
 	watcher := &watcher{
+		name:     name,
 		filePath: filePath,
 		logger:   logger,
 	}
@@ -83,6 +88,15 @@ func (w *watcher) reloadConfig() error {
 		return err
 	}
 
+	var repos, orgs int
+	for _, org := range w.config.Orgs {
+		if len(org.Repos) == 0 {
+			orgs++
+		}
+		repos += len(org.Repos)
+	}
+	configuredReposGauge.WithLabelValues(w.name, scopeRepo).Set(float64(repos))
+	configuredReposGauge.WithLabelValues(w.name, scopeOrg).Set(float64(orgs))
 	return nil
 }
 
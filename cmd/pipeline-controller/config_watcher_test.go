@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestReloadConfig(t *testing.T) {
+	watcherConfig := filepath.Join(t.TempDir(), "config.yaml")
+	config := `orgs:
+- org: org
+  repos:
+  - repo
+  - other
+- org: whole-org
+- org: another-whole-org
+`
+	if err := os.WriteFile(watcherConfig, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	w := newWatcher("reload-test", watcherConfig, logrus.NewEntry(logrus.New()))
+	if err := w.reloadConfig(); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	expected := map[string]sets.String{
+		"org":               sets.NewString("repo", "other"),
+		"whole-org":         sets.NewString(),
+		"another-whole-org": sets.NewString(),
+	}
+	if diff := cmp.Diff(expected, w.getConfig()); diff != "" {
+		t.Errorf("config differs from expected:\n%s", diff)
+	}
+	if diff := cmp.Diff(2.0, testutil.ToFloat64(configuredReposGauge.WithLabelValues("reload-test", scopeRepo))); diff != "" {
+		t.Errorf("configured repos differ from expected:\n%s", diff)
+	}
+	if diff := cmp.Diff(2.0, testutil.ToFloat64(configuredReposGauge.WithLabelValues("reload-test", scopeOrg))); diff != "" {
+		t.Errorf("configured orgs differ from expected:\n%s", diff)
+	}
+}
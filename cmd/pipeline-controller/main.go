@@ -5,6 +5,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/bombsimon/logrusr/v3"
@@ -14,17 +16,24 @@ import (
 	ctrlruntimelog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/config/secret"
 	prowflagutil "sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/githubeventserver"
 	"sigs.k8s.io/prow/pkg/interrupts"
+	"sigs.k8s.io/prow/pkg/labels"
 	"sigs.k8s.io/prow/pkg/logrusutil"
+	"sigs.k8s.io/prow/pkg/metrics"
 )
 
 const pullRequestInfoComment = "**Pipeline controller notification**\n This repository is configured to use the [pipeline controller](https://docs.ci.openshift.org/docs/how-tos/creating-a-pipeline/). Second-stage tests will be triggered only if the required tests of the first stage are successful. The pipeline controller will automatically detect which contexts are required, or not needed and will utilize a set of `/test` and `/override` Prow commands to trigger the second stage."
 
+const RepoNotConfiguredMessage = "This repository is not configured to use the [pipeline controller](https://docs.ci.openshift.org/docs/how-tos/creating-a-pipeline/)."
+
+var pipelineOptionalRe = regexp.MustCompile(`(?m)^/pipeline optional(?:[ \t]+(\S+))?[ \t]*$`)
+
 type options struct {
 	client                   prowflagutil.KubernetesOptions
 	github                   prowflagutil.GitHubOptions
@@ -80,7 +89,7 @@ func parseOptions() options {
 }
 
 type clientWrapper struct {
-	ghc                github.Client
+	ghc                minimalGhClient
 	configDataProvider *ConfigDataProvider
 	watcher            *watcher
 }
@@ -110,8 +119,87 @@ func (cw *clientWrapper) handlePullRequestCreation(l *logrus.Entry, event github
 		})
 		if err := cw.ghc.CreateComment(org, repo, number, pullRequestInfoComment); err != nil {
 			logger.WithError(err).Error("failed to create comment")
+			return
 		}
+		infoCommentsCounter.WithLabelValues(org, repo).Inc()
+	}
+}
+
+func (cw *clientWrapper) handleIssueComment(l *logrus.Entry, event github.IssueCommentEvent) {
+	if event.Action != github.IssueCommentActionCreated || !event.Issue.IsPullRequest() {
+		return
+	}
+	match := pipelineOptionalRe.FindStringSubmatch(event.Comment.Body)
+	if match == nil {
+		return
+	}
+	org := event.Repo.Owner.Login
+	repo := event.Repo.Name
+	number := event.Issue.Number
+	logger := l.WithFields(logrus.Fields{
+		"org":  org,
+		"repo": repo,
+		"pr":   number,
+	})
+
+	comment, err := cw.optionalJobsComment(event, match[1])
+	if err != nil {
+		logger.WithError(err).Error("failed to handle the /pipeline optional command")
+		return
+	}
+	if err := cw.ghc.CreateComment(org, repo, number, comment); err != nil {
+		logger.WithError(err).Error("failed to create comment")
+		return
+	}
+	commandResponsesCounter.WithLabelValues(org, repo, "optional").Inc()
+}
+
+// optionalJobsComment lists the optional jobs that can run on the pull request or, if a job name
+// is given, responds with the command that triggers that job
+func (cw *clientWrapper) optionalJobsComment(event github.IssueCommentEvent, name string) (string, error) {
+	org := event.Repo.Owner.Login
+	repo := event.Repo.Name
+	repos, ok := cw.watcher.getConfig()[org]
+	if !ok || !(repos.Len() == 0 || repos.Has(repo)) {
+		return RepoNotConfiguredMessage, nil
+	}
+
+	pr, err := cw.ghc.GetPullRequest(org, repo, event.Issue.Number)
+	if err != nil {
+		return "", fmt.Errorf("error getting pull request: %w", err)
+	}
+	var optional []config.Presubmit
+	for _, presubmit := range cw.configDataProvider.GetPresubmits(org + "/" + repo).optional {
+		if presubmit.CouldRun(pr.Base.Ref) {
+			optional = append(optional, presubmit)
+		}
+	}
+
+	if name == "" {
+		if len(optional) == 0 {
+			return fmt.Sprintf("There are no optional jobs for the `%s` branch.", pr.Base.Ref), nil
+		}
+		comment := fmt.Sprintf("Optional jobs for the `%s` branch, use `/pipeline optional <name>` to trigger one of them:\n", pr.Base.Ref)
+		for _, presubmit := range optional {
+			comment += fmt.Sprintf("\n- `%s`", presubmit.Name)
+		}
+		return comment, nil
+	}
+
+	for _, presubmit := range optional {
+		if presubmit.Name != name && presubmit.RerunCommand != "/test "+name {
+			continue
+		}
+		if github.HasLabel(labels.NeedsOkToTest, event.Issue.Labels) {
+			return fmt.Sprintf("@%s: optional jobs can only be triggered once the pull request is `/ok-to-test`.", event.Comment.User.Login), nil
+		}
+		return fmt.Sprintf("Triggering `%s` as requested by @%s:\n%s", presubmit.Name, event.Comment.User.Login, presubmit.RerunCommand), nil
+	}
+	names := make([]string, 0, len(optional))
+	for _, presubmit := range optional {
+		names = append(names, presubmit.Name)
 	}
+	return fmt.Sprintf("@%s: `%s` is not an optional job for the `%s` branch. Available optional jobs: %s", event.Comment.User.Login, name, pr.Base.Ref, strings.Join(names, ", ")), nil
 }
 
 func main() {
@@ -163,7 +251,7 @@ func main() {
 		logger.WithError(err).Fatal("error getting GitHub client")
 	}
 
-	watcher := newWatcher(o.configFile, logger)
+	watcher := newWatcher(regularConfig, o.configFile, logger)
 	go watcher.watch()
 
 	configDataProvider := NewConfigDataProvider(cfg)
@@ -188,12 +276,14 @@ func main() {
 	logger.Debug("starting event server")
 	eventServer := githubeventserver.New(o.githubEventServerOptions, webhookTokenGenerator, logger)
 	eventServer.RegisterHandlePullRequestEvent(cw.handlePullRequestCreation)
+	eventServer.RegisterHandleIssueCommentEvent(cw.handleIssueComment)
 
 	interrupts.OnInterrupt(func() {
 		eventServer.GracefulShutdown()
 	})
 
 	interrupts.ListenAndServe(eventServer, time.Second*30)
+	metrics.ExposeMetrics("pipeline-controller", config.PushGateway{}, prowflagutil.DefaultMetricsPort)
 	interrupts.Run(func(ctx context.Context) {
 		if err := mgr.Start(ctx); err != nil {
 			logger.WithError(err).Fatal("controller manager exited with error")
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/labels"
+)
+
+type commentingGhClient struct {
+	fakeGhClient
+	baseRef  string
+	comments []string
+}
+
+func (c *commentingGhClient) GetPullRequest(org, repo string, number int) (*github.PullRequest, error) {
+	return &github.PullRequest{State: github.PullRequestStateOpen, Base: github.PullRequestBranch{Ref: c.baseRef}}, nil
+}
+
+func (c *commentingGhClient) CreateComment(owner, repo string, number int, comment string) error {
+	c.comments = append(c.comments, comment)
+	return nil
+}
+
+func TestHandleIssueComment(t *testing.T) {
+	watcherConfig := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(watcherConfig, []byte("orgs:\n- org: org\n  repos:\n  - repo\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	w := newWatcher(regularConfig, watcherConfig, logrus.NewEntry(logrus.New()))
+	if err := w.reloadConfig(); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	optional := []config.Presubmit{
+		{JobBase: config.JobBase{Name: "pull-ci-org-repo-master-e2e-optional"}, Brancher: config.Brancher{Branches: []string{"master"}}, RerunCommand: "/test e2e-optional", Optional: true},
+		{JobBase: config.JobBase{Name: "pull-ci-org-repo-release-1.0-e2e-optional"}, Brancher: config.Brancher{Branches: []string{"release-1.0"}}, RerunCommand: "/test e2e-optional", Optional: true},
+	}
+	if err := config.SetPresubmitRegexes(optional); err != nil {
+		t.Fatalf("failed to set presubmit regexes: %v", err)
+	}
+	configDataProvider := &ConfigDataProvider{
+		updatedPresubmits: map[string]presubmitTests{
+			"org/repo": {protected: []string{"pull-ci-org-repo-master-e2e"}, optional: optional},
+		},
+		m: sync.Mutex{},
+	}
+	event := func(repo, body string, issueLabels ...string) github.IssueCommentEvent {
+		e := github.IssueCommentEvent{
+			Action:  github.IssueCommentActionCreated,
+			Repo:    github.Repo{Owner: github.User{Login: "org"}, Name: repo},
+			Issue:   github.Issue{Number: 123, PullRequest: &struct{}{}},
+			Comment: github.IssueComment{Body: body, User: github.User{Login: "author"}},
+		}
+		for _, label := range issueLabels {
+			e.Issue.Labels = append(e.Issue.Labels, github.Label{Name: label})
+		}
+		return e
+	}
+
+	testCases := []struct {
+		name     string
+		event    github.IssueCommentEvent
+		expected []string
+	}{
+		{
+			name:  "unrelated comment is ignored",
+			event: event("repo", "/test e2e"),
+		},
+		{
+			name:     "repo is not configured",
+			event:    event("other", "/pipeline optional"),
+			expected: []string{RepoNotConfiguredMessage},
+		},
+		{
+			name:     "optional jobs for the base branch are listed",
+			event:    event("repo", "/pipeline optional"),
+			expected: []string{"Optional jobs for the `master` branch, use `/pipeline optional <name>` to trigger one of them:\n\n- `pull-ci-org-repo-master-e2e-optional`"},
+		},
+		{
+			name:     "optional job is triggered by its name",
+			event:    event("repo", "/pipeline optional pull-ci-org-repo-master-e2e-optional"),
+			expected: []string{"Triggering `pull-ci-org-repo-master-e2e-optional` as requested by @author:\n/test e2e-optional"},
+		},
+		{
+			name:     "optional job is triggered by its short name",
+			event:    event("repo", "/pipeline optional e2e-optional"),
+			expected: []string{"Triggering `pull-ci-org-repo-master-e2e-optional` as requested by @author:\n/test e2e-optional"},
+		},
+		{
+			name:     "job that is not optional is not triggered",
+			event:    event("repo", "/pipeline optional pull-ci-org-repo-master-e2e"),
+			expected: []string{"@author: `pull-ci-org-repo-master-e2e` is not an optional job for the `master` branch. Available optional jobs: pull-ci-org-repo-master-e2e-optional"},
+		},
+		{
+			name:     "untrusted pull request is not triggered",
+			event:    event("repo", "/pipeline optional e2e-optional", labels.NeedsOkToTest),
+			expected: []string{"@author: optional jobs can only be triggered once the pull request is `/ok-to-test`."},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ghc := &commentingGhClient{fakeGhClient: fakeGhClient{closed: sets.NewInt()}, baseRef: "master"}
+			cw := &clientWrapper{ghc: ghc, configDataProvider: configDataProvider, watcher: w}
+			responses := commandResponsesCounter.WithLabelValues("org", tc.event.Repo.Name, "optional")
+			before := testutil.ToFloat64(responses)
+			cw.handleIssueComment(logrus.WithField("test", tc.name), tc.event)
+			if diff := cmp.Diff(tc.expected, ghc.comments); diff != "" {
+				t.Errorf("comments differ from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(float64(len(tc.expected)), testutil.ToFloat64(responses)-before); diff != "" {
+				t.Errorf("counted command responses differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandlePullRequestCreation(t *testing.T) {
+	watcherConfig := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(watcherConfig, []byte("orgs:\n- org: org\n  repos:\n  - repo\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	w := newWatcher(regularConfig, watcherConfig, logrus.NewEntry(logrus.New()))
+	if err := w.reloadConfig(); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	configDataProvider := &ConfigDataProvider{
+		updatedPresubmits: map[string]presubmitTests{
+			"org/repo":  {protected: []string{"pull-ci-org-repo-master-e2e"}},
+			"org/other": {protected: []string{"pull-ci-org-other-master-e2e"}},
+		},
+		m: sync.Mutex{},
+	}
+
+	testCases := []struct {
+		name     string
+		repo     string
+		expected []string
+	}{
+		{
+			name:     "configured repo is notified",
+			repo:     "repo",
+			expected: []string{pullRequestInfoComment},
+		},
+		{
+			name: "repo that is not configured is not notified",
+			repo: "other",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ghc := &commentingGhClient{fakeGhClient: fakeGhClient{closed: sets.NewInt()}}
+			cw := &clientWrapper{ghc: ghc, configDataProvider: configDataProvider, watcher: w}
+			infoComments := infoCommentsCounter.WithLabelValues("org", tc.repo)
+			before := testutil.ToFloat64(infoComments)
+			cw.handlePullRequestCreation(logrus.WithField("test", tc.name), github.PullRequestEvent{
+				Action: github.PullRequestActionOpened,
+				Repo:   github.Repo{Owner: github.User{Login: "org"}, Name: tc.repo},
+				Number: 123,
+			})
+			if diff := cmp.Diff(tc.expected, ghc.comments); diff != "" {
+				t.Errorf("comments differ from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(float64(len(tc.expected)), testutil.ToFloat64(infoComments)-before); diff != "" {
+				t.Errorf("counted info comments differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	infoCommentsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pipeline_controller_info_comments_total",
+			Help: "Number of pipeline controller notifications posted on newly opened pull requests.",
+		},
+		[]string{"org", "repo"},
+	)
+
+	commandResponsesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pipeline_controller_command_responses_total",
+			Help: "Number of responses posted to /pipeline commands.",
+		},
+		[]string{"org", "repo", "command"},
+	)
+
+	testCommentsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pipeline_controller_test_comments_total",
+			Help: "Number of comments posted to trigger the second stage tests.",
+		},
+		[]string{"org", "repo"},
+	)
+
+	configuredReposGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "pipeline_controller_configured_repos",
+			Help: "Number of entries in the config, by whether they enable a single repo or an org as a whole.",
+		},
+		[]string{"config", "scope"},
+	)
+)
+
+const (
+	// scopeRepo labels the repos listed in the config
+	scopeRepo = "repo"
+	// scopeOrg labels the orgs that are enabled as a whole, without listing any repo
+	scopeOrg = "org"
+)
+
+func init() {
+	prometheus.MustRegister(infoCommentsCounter, commandResponsesCounter, testCommentsCounter, configuredReposGauge)
+}
@@ -23,6 +23,12 @@ import (
 
 const (
 	retention = 24 * time.Hour
+
+	// maxPullRequestChanges is the number of changed files after which GitHub truncates
+	// the list of changes of a pull request, even when all pages are requested. The
+	// vendored client does not expose the number of changed files of a pull request,
+	// so a list of this length has to be assumed to be truncated.
+	maxPullRequestChanges = 3000
 )
 
 type minimalGhClient interface {
@@ -196,6 +202,7 @@ func (r *reconciler) reconcile(ctx context.Context, req reconcile.Request) error
 		r.ids.Delete(composeKey(pj.Spec.Refs))
 		return err
 	}
+	testCommentsCounter.WithLabelValues(pj.Spec.Refs.Org, pj.Spec.Refs.Repo).Inc()
 	return nil
 }
 
@@ -204,7 +211,7 @@ func (r *reconciler) acquireConditionalContexts(pj *v1.ProwJob, pipelineConditio
 	var overrideCommands string
 	var testCommands string
 	if len(pipelineConditionallyRequired) != 0 {
-		cfp := config.NewGitHubDeferredChangedFilesProvider(r.ghc, pj.Spec.Refs.Org, pj.Spec.Refs.Repo, pj.Spec.Refs.Pulls[0].Number)
+		cfp, truncated := r.changedFilesProvider(pj.Spec.Refs)
 		for _, presubmit := range pipelineConditionallyRequired {
 			if !strings.Contains(presubmit.Name, repoBaseRef) {
 				continue
@@ -221,6 +228,12 @@ func (r *reconciler) acquireConditionalContexts(pj *v1.ProwJob, pipelineConditio
 					r.ids.Delete(composeKey(pj.Spec.Refs))
 					return "", "", err
 				}
+				if !shouldRun && *truncated {
+					// A matching file may be missing from the truncated list, so rather schedule the test than override it
+					r.logger.WithField("pr", composePRIdentifier(pj.Spec.Refs)).WithField("job", presubmit.Name).
+						Warnf("The list of changed files is truncated at %d entries, scheduling the test", maxPullRequestChanges)
+					shouldRun = true
+				}
 				if shouldRun {
 					testCommands += "\n" + presubmit.RerunCommand
 					continue
@@ -234,6 +247,27 @@ func (r *reconciler) acquireConditionalContexts(pj *v1.ProwJob, pipelineConditio
 	return testCommands, overrideCommands, nil
 }
 
+// changedFilesProvider lazily gets the files changed in the pull request. Once the provider was called,
+// the returned bool tells whether GitHub truncated the list, in which case it can't be trusted to be complete.
+func (r *reconciler) changedFilesProvider(refs *v1.Refs) (config.ChangedFilesProvider, *bool) {
+	var truncated bool
+	var changedFiles []string
+	return func() ([]string, error) {
+		if changedFiles == nil {
+			changes, err := r.ghc.GetPullRequestChanges(refs.Org, refs.Repo, refs.Pulls[0].Number)
+			if err != nil {
+				return nil, fmt.Errorf("error getting pull request changes: %w", err)
+			}
+			changedFiles = []string{}
+			for _, change := range changes {
+				changedFiles = append(changedFiles, change.Filename)
+			}
+			truncated = len(changes) >= maxPullRequestChanges
+		}
+		return changedFiles, nil
+	}, &truncated
+}
+
 func (r *reconciler) reportSuccessOnPR(ctx context.Context, pj *v1.ProwJob, presubmits presubmitTests) (bool, error) {
 	if pj == nil || pj.Spec.Refs == nil || len(pj.Spec.Refs.Pulls) != 1 {
 		return false, nil
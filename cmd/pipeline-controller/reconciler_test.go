@@ -3,22 +3,33 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/kube"
 )
 
 type fakeGhClient struct {
-	closed sets.Int
+	closed  sets.Int
+	changes []github.PullRequestChange
 }
 
 func (c fakeGhClient) GetPullRequest(org, repo string, number int) (*github.PullRequest, error) {
@@ -34,7 +45,7 @@ func (c fakeGhClient) CreateComment(owner, repo string, number int, comment stri
 }
 
 func (c fakeGhClient) GetPullRequestChanges(org string, repo string, number int) ([]github.PullRequestChange, error) {
-	return []github.PullRequestChange{}, nil
+	return c.changes, nil
 }
 
 type FakeReader struct {
@@ -316,3 +327,122 @@ func Test_reconciler_reportSuccessOnPR(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileCountsTestComments(t *testing.T) {
+	watcherConfig := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(watcherConfig, []byte("orgs:\n- org: org\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	w := newWatcher(regularConfig, watcherConfig, logrus.NewEntry(logrus.New()))
+	if err := w.reloadConfig(); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	pj := composePresubmit("org-repo-master-ps1", v1.SuccessState, "sha")
+	pj.Name, pj.Namespace = "ps1", "ci"
+	pj.Spec.Refs.Org = "org"
+	scheme := runtime.NewScheme()
+	if err := v1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to register prowjobs: %v", err)
+	}
+	ghc := &commentingGhClient{fakeGhClient: fakeGhClient{closed: sets.NewInt()}}
+	r := &reconciler{
+		pjclientset: fakectrlruntimeclient.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&pj).Build(),
+		lister: FakeReader{pjs: v1.ProwJobList{Items: []v1.ProwJob{
+			composePresubmit("org-repo-master-ps2", v1.SuccessState, "sha"),
+		}}},
+		configDataProvider: &ConfigDataProvider{
+			updatedPresubmits: map[string]presubmitTests{
+				"org/repo": {protected: []string{"org-repo-master-ps1"}, alwaysRequired: []string{"org-repo-master-ps2"}},
+			},
+			m: sync.Mutex{},
+		},
+		ghc:            ghc,
+		ids:            sync.Map{},
+		logger:         logrus.WithField("test", t.Name()),
+		watcher:        w,
+		closedPRsCache: closedPRsCache{prs: map[string]pullRequest{}, m: sync.Mutex{}, ghc: ghc, clearTime: time.Now()},
+	}
+
+	testComments := testCommentsCounter.WithLabelValues("org", "repo")
+	before := testutil.ToFloat64(testComments)
+	for i := 0; i < 2; i++ {
+		if err := r.reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ci", Name: "ps1"}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if diff := cmp.Diff([]string{"/test remaining-required"}, ghc.comments); diff != "" {
+		t.Errorf("comments differ from expected:\n%s", diff)
+	}
+	if diff := cmp.Diff(1.0, testutil.ToFloat64(testComments)-before); diff != "" {
+		t.Errorf("counted test comments differ from expected:\n%s", diff)
+	}
+}
+
+func Test_reconciler_acquireConditionalContexts(t *testing.T) {
+	pj := composePresubmit("org-repo-master-ps1", v1.SuccessState, "sha")
+	presubmits := []config.Presubmit{
+		{
+			JobBase:      config.JobBase{Name: "pull-ci-org-repo-master-docs", Annotations: map[string]string{"pipeline_run_if_changed": "^docs/"}},
+			Reporter:     config.Reporter{Context: "ci/prow/docs"},
+			RerunCommand: "/test docs",
+		},
+	}
+	changes := func(n int, filename string) []github.PullRequestChange {
+		var changes []github.PullRequestChange
+		for i := 0; i < n; i++ {
+			changes = append(changes, github.PullRequestChange{Filename: fmt.Sprintf("%s/%d", filename, i)})
+		}
+		return changes
+	}
+
+	tests := []struct {
+		name              string
+		changes           []github.PullRequestChange
+		expectedTests     string
+		expectedOverrides string
+	}{
+		{
+			name:          "matching change schedules the test",
+			changes:       changes(2, "docs"),
+			expectedTests: "\n/test docs",
+		},
+		{
+			name:              "no matching change overrides the context",
+			changes:           changes(2, "pkg"),
+			expectedOverrides: " ci/prow/docs",
+		},
+		{
+			name:              "more changes than fit a single page are trusted",
+			changes:           changes(300, "pkg"),
+			expectedOverrides: " ci/prow/docs",
+		},
+		{
+			name:              "just below the truncation limit, the list is trusted",
+			changes:           changes(maxPullRequestChanges-1, "pkg"),
+			expectedOverrides: " ci/prow/docs",
+		},
+		{
+			name:          "truncated list of changes schedules the test conservatively",
+			changes:       changes(maxPullRequestChanges, "pkg"),
+			expectedTests: "\n/test docs",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &reconciler{
+				ghc:    fakeGhClient{closed: sets.NewInt(), changes: tc.changes},
+				logger: logrus.WithField("test", tc.name),
+			}
+			testCommands, overrides, err := r.acquireConditionalContexts(&pj, presubmits)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedTests, testCommands); diff != "" {
+				t.Errorf("test commands differ from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedOverrides, overrides); diff != "" {
+				t.Errorf("override commands differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
@@ -13,10 +13,11 @@ import (
 	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
 )
 
-func newReloader(name string, cache Cache) *cacheReloader {
+func newReloader(name string, cache Cache, maxAge time.Duration) *cacheReloader {
 	reloader := &cacheReloader{
-		name:  name,
-		cache: cache,
+		name:   name,
+		cache:  cache,
+		maxAge: maxAge,
 		logger: logrus.WithFields(logrus.Fields{
 			"component": "pod-scaler reloader",
 			"metric":    name,
@@ -30,6 +31,7 @@ func newReloader(name string, cache Cache) *cacheReloader {
 type cacheReloader struct {
 	name   string
 	cache  Cache
+	maxAge time.Duration
 	logger *logrus.Entry
 
 	lock        *sync.RWMutex
@@ -71,6 +73,8 @@ func (c *cacheReloader) reload() {
 		logger.WithError(err).Warn("Failed to read cached data, won't reload this tick.")
 		return
 	}
+	// the producer prunes the data it writes back, but it may not have run since data expired
+	data.Prune(c.maxAge)
 	c.lock.Lock()
 	if len(c.subscribers) > 0 {
 		c.lastUpdated = lastUpdated
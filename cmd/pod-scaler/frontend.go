@@ -201,14 +201,15 @@ var (
 	static embed.FS
 )
 
-func serveUI(port, healthPort int, dataDir string, loaders map[string][]*cacheReloader) {
+func serveUI(port, healthPort int, dataDir string, loaders map[string][]*cacheReloader, quantiles requestQuantiles) {
 	logger := logrus.WithField("component", "pod-scaler frontend")
 	server := &frontendServer{
-		logger:   logger,
-		lock:     sync.RWMutex{},
-		mappings: endpoints(),
-		indices:  map[string][]*IndexNode{},
-		dataDir:  dataDir,
+		logger:    logger,
+		lock:      sync.RWMutex{},
+		mappings:  endpoints(),
+		indices:   map[string][]*IndexNode{},
+		dataDir:   dataDir,
+		quantiles: quantiles,
 	}
 	health := pjutil.NewHealthOnPort(healthPort)
 	digestAll(loaders, map[string]digester{
@@ -323,6 +324,9 @@ type frontendServer struct {
 
 	// dataDir is where we hold sharded data by metadata identifier
 	dataDir string
+
+	// quantiles are the quantiles recommended as requests, displayed as the cutoff
+	quantiles requestQuantiles
 }
 
 // dataForDisplay caches precomputed values for displaying data
@@ -463,15 +467,15 @@ func (s *frontendServer) setDatum(meta podscaler.FullMetadata, resource corev1.R
 
 func (s *frontendServer) digestCPU(data *podscaler.CachedQuery) {
 	s.logger.Debugf("Digesting new CPU consumption metrics.")
-	s.digestData(data, corev1.ResourceCPU, cpuRequestQuantile)
+	s.digestData(data, corev1.ResourceCPU, s.quantiles.cpu, cpuRequestQuantile)
 }
 
 func (s *frontendServer) digestMemory(data *podscaler.CachedQuery) {
 	s.logger.Debugf("Digesting new Memory consumption metrics.")
-	s.digestData(data, corev1.ResourceMemory, memRequestQuantile)
+	s.digestData(data, corev1.ResourceMemory, s.quantiles.memory, memRequestQuantile)
 }
 
-func (s *frontendServer) digestData(data *podscaler.CachedQuery, metric corev1.ResourceName, quantile float64) {
+func (s *frontendServer) digestData(data *podscaler.CachedQuery, metric corev1.ResourceName, quantile, defaultQuantile float64) {
 	s.logger.Debugf("Digesting %d identifiers.", len(data.DataByMetaData))
 	for meta, fingerprintTimes := range data.DataByMetaData {
 		s.lock.Lock()
@@ -497,7 +501,7 @@ func (s *frontendServer) digestData(data *podscaler.CachedQuery, metric corev1.R
 			members = append(members, data.Data[fingerprint].Histogram())
 		}
 		if err := s.setDatum(meta, metric, dataForDisplay{
-			Cutoff:     overall.ValueAtQuantile(quantile),
+			Cutoff:     overall.ValueAtQuantile(quantileFor(overall, quantile, defaultQuantile)),
 			LowerBound: overall.ValueAtQuantile(.001),
 			Merged:     overall,
 			Histograms: members,
@@ -6,6 +6,11 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/openhistogram/circonusllhist"
+	"github.com/prometheus/common/model"
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/openshift/ci-tools/pkg/api"
 	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
@@ -100,3 +105,33 @@ type fakeWriter struct{}
 func (w *fakeWriter) Header() http.Header        { return nil }
 func (w *fakeWriter) Write([]byte) (int, error)  { return 0, nil }
 func (w *fakeWriter) WriteHeader(statusCode int) {}
+
+func TestDigestDataCutoff(t *testing.T) {
+	meta := podscaler.FullMetadata{Target: "unit", Container: "test"}
+	histogram := circonusllhist.New()
+	for i := 1; i <= 1000; i++ {
+		if err := histogram.RecordValue(float64(i * 1024 * 1024)); err != nil {
+			t.Fatalf("failed to record value: %v", err)
+		}
+	}
+	data := &podscaler.CachedQuery{
+		Data:           map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{1: circonusllhist.NewHistogramWithoutLookups(histogram)},
+		DataByMetaData: map[podscaler.FullMetadata][]podscaler.FingerprintTime{meta: {{Fingerprint: 1}}},
+	}
+	server := &frontendServer{
+		logger:    logrus.WithField("test", t.Name()),
+		mappings:  endpoints(),
+		indices:   map[string][]*IndexNode{},
+		dataDir:   t.TempDir(),
+		quantiles: requestQuantiles{memory: 0.99},
+	}
+	server.digestMemory(data)
+
+	datum, ok, err := server.getDatum(meta)
+	if err != nil || !ok {
+		t.Fatalf("failed to get datum: %t, %v", ok, err)
+	}
+	if expected, actual := histogram.ValueAtQuantile(0.99), datum[corev1.ResourceMemory].Cutoff; actual != expected {
+		t.Errorf("expected the cutoff at the configured quantile to be %v, got %v", expected, actual)
+	}
+}
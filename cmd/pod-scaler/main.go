@@ -29,6 +29,7 @@ import (
 	buildclientset "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
 	routeclientset "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 
+	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
 	"github.com/openshift/ci-tools/pkg/prowconfigutils"
 	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/util"
@@ -47,6 +48,7 @@ type options struct {
 	cacheDir           string
 	cacheBucket        string
 	gcsCredentialsFile string
+	dataMaxAge         time.Duration
 
 	resultsOptions results.Options
 }
@@ -67,6 +69,8 @@ type consumerOptions struct {
 	cpuCap                int64
 	memoryCap             string
 	cpuPriorityScheduling int64
+	cpuRequestQuantile    float64
+	memoryRequestQuantile float64
 }
 
 func bindOptions(fs *flag.FlagSet) *options {
@@ -86,9 +90,12 @@ func bindOptions(fs *flag.FlagSet) *options {
 	fs.StringVar(&o.dataDir, "data-dir", "", "Local directory to cache UI data into.")
 	fs.StringVar(&o.cacheBucket, "cache-bucket", "", "GCS bucket name holding cached Prometheus data.")
 	fs.StringVar(&o.gcsCredentialsFile, "gcs-credentials-file", "", "File where GCS credentials are stored.")
+	fs.DurationVar(&o.dataMaxAge, "data-max-age", podscaler.DefaultDataMaxAge, "Data that was added longer than this ago is pruned when it is loaded or written back to the cache.")
 	fs.Int64Var(&o.cpuCap, "cpu-cap", 10, "The maximum CPU request value, ex: 10")
 	fs.StringVar(&o.memoryCap, "memory-cap", "20Gi", "The maximum memory request value, ex: '20Gi'")
 	fs.Int64Var(&o.cpuPriorityScheduling, "cpu-priority-scheduling", 8, "Pods with CPU requests at, or above, this value will be admitted with priority scheduling")
+	fs.Float64Var(&o.cpuRequestQuantile, "cpu-request-quantile", cpuRequestQuantile, fmt.Sprintf("The quantile of CPU usage data to recommend as the CPU request, ex: 0.99. Workloads with fewer than %d samples use the default of %v.", minSamplesForQuantile, cpuRequestQuantile))
+	fs.Float64Var(&o.memoryRequestQuantile, "memory-request-quantile", memRequestQuantile, fmt.Sprintf("The quantile of memory usage data to recommend as the memory request, ex: 0.99. Workloads with fewer than %d samples use the default of %v.", minSamplesForQuantile, memRequestQuantile))
 	o.resultsOptions.Bind(fs)
 	return &o
 }
@@ -109,6 +116,9 @@ func (o *options) validate() error {
 		if o.dataDir == "" {
 			return errors.New("--data-dir is required")
 		}
+		if err := o.validateRequestQuantiles(); err != nil {
+			return err
+		}
 	case "consumer.admission":
 		if o.port == 0 {
 			return errors.New("--port is required")
@@ -122,6 +132,9 @@ func (o *options) validate() error {
 		if memoryCap := resource.MustParse(o.memoryCap); memoryCap.Sign() <= 0 {
 			return errors.New("--memory-cap must be greater than 0")
 		}
+		if err := o.validateRequestQuantiles(); err != nil {
+			return err
+		}
 		if err := o.resultsOptions.Validate(); err != nil {
 			return err
 		}
@@ -137,6 +150,9 @@ func (o *options) validate() error {
 			return errors.New("--gcs-credentials-file is required")
 		}
 	}
+	if o.dataMaxAge <= 0 {
+		return errors.New("--data-max-age must be greater than 0")
+	}
 	if level, err := logrus.ParseLevel(o.loglevel); err != nil {
 		return fmt.Errorf("--loglevel invalid: %w", err)
 	} else {
@@ -149,6 +165,16 @@ func (o *options) validate() error {
 	return o.instrumentationOptions.Validate(false)
 }
 
+func (o *options) validateRequestQuantiles() error {
+	if o.cpuRequestQuantile <= 0 || o.cpuRequestQuantile > 1 {
+		return errors.New("--cpu-request-quantile must be greater than 0 and at most 1")
+	}
+	if o.memoryRequestQuantile <= 0 || o.memoryRequestQuantile > 1 {
+		return errors.New("--memory-request-quantile must be greater than 0 and at most 1")
+	}
+	return nil
+}
+
 func main() {
 	flagSet := flag.NewFlagSet("", flag.ExitOnError)
 	opts := bindOptions(flagSet)
@@ -241,12 +267,12 @@ func mainProduce(opts *options, cache Cache) {
 		logger.Debugf("Loaded Prometheus client.")
 	}
 
-	produce(clients, cache, opts.ignoreLatest, opts.once)
+	produce(clients, cache, opts.ignoreLatest, opts.dataMaxAge, opts.once)
 
 }
 
 func mainUI(opts *options, cache Cache) {
-	go serveUI(opts.uiPort, opts.instrumentationOptions.HealthPort, opts.dataDir, loaders(cache))
+	go serveUI(opts.uiPort, opts.instrumentationOptions.HealthPort, opts.dataDir, loaders(cache, opts.dataMaxAge), requestQuantiles{cpu: opts.cpuRequestQuantile, memory: opts.memoryRequestQuantile})
 }
 
 func mainAdmission(opts *options, cache Cache) {
@@ -265,14 +291,14 @@ func mainAdmission(opts *options, cache Cache) {
 		logrus.WithError(err).Fatal("Failed to create pod-scaler reporter.")
 	}
 
-	go admit(opts.port, opts.instrumentationOptions.HealthPort, opts.certDir, client, loaders(cache), opts.mutateResourceLimits, opts.cpuCap, opts.memoryCap, opts.cpuPriorityScheduling, reporter)
+	go admit(opts.port, opts.instrumentationOptions.HealthPort, opts.certDir, client, loaders(cache, opts.dataMaxAge), opts.mutateResourceLimits, opts.cpuCap, opts.memoryCap, opts.cpuPriorityScheduling, requestQuantiles{cpu: opts.cpuRequestQuantile, memory: opts.memoryRequestQuantile}, reporter)
 }
 
-func loaders(cache Cache) map[string][]*cacheReloader {
+func loaders(cache Cache, maxAge time.Duration) map[string][]*cacheReloader {
 	l := map[string][]*cacheReloader{}
 	for _, prefix := range []string{ProwjobsCachePrefix, PodsCachePrefix, StepsCachePrefix} {
-		l[MetricNameCPUUsage] = append(l[MetricNameCPUUsage], newReloader(prefix+"/"+MetricNameCPUUsage, cache))
-		l[MetricNameMemoryWorkingSet] = append(l[MetricNameMemoryWorkingSet], newReloader(prefix+"/"+MetricNameMemoryWorkingSet, cache))
+		l[MetricNameCPUUsage] = append(l[MetricNameCPUUsage], newReloader(prefix+"/"+MetricNameCPUUsage, cache, maxAge))
+		l[MetricNameMemoryWorkingSet] = append(l[MetricNameMemoryWorkingSet], newReloader(prefix+"/"+MetricNameMemoryWorkingSet, cache, maxAge))
 	}
 	return l
 }
@@ -70,7 +70,7 @@ func queriesByMetric() map[string]string {
 	return queries
 }
 
-func produce(clients map[string]prometheusapi.API, dataCache Cache, ignoreLatest time.Duration, once bool) {
+func produce(clients map[string]prometheusapi.API, dataCache Cache, ignoreLatest, maxAge time.Duration, once bool) {
 	var execute func(func())
 	if once {
 		execute = func(f func()) {
@@ -138,7 +138,7 @@ func produce(clients map[string]prometheusapi.API, dataCache Cache, ignoreLatest
 				}()
 			}
 			wg.Wait()
-			if err := storeCache(dataCache, name, cache, logger); err != nil {
+			if err := storeCache(dataCache, name, cache, maxAge, logger); err != nil {
 				logger.WithError(err).Error("Failed to write cached data.")
 			}
 		}
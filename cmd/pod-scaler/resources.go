@@ -13,12 +13,13 @@ import (
 	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
 )
 
-func newResourceServer(loaders map[string][]*cacheReloader, health *pjutil.Health) *resourceServer {
+func newResourceServer(loaders map[string][]*cacheReloader, health *pjutil.Health, quantiles requestQuantiles) *resourceServer {
 	logger := logrus.WithField("component", "pod-scaler request server")
 	server := &resourceServer{
 		logger:     logger,
 		lock:       sync.RWMutex{},
 		byMetaData: map[podscaler.FullMetadata]corev1.ResourceRequirements{},
+		quantiles:  quantiles,
 	}
 	digestAll(loaders, map[string]digester{
 		MetricNameCPUUsage:         server.digestCPU,
@@ -34,11 +35,23 @@ type resourceServer struct {
 	// byMetaData caches resource requirements calculated for the full assortment of
 	// metadata labels.
 	byMetaData map[podscaler.FullMetadata]corev1.ResourceRequirements
+	quantiles  requestQuantiles
+}
+
+// requestQuantiles holds the quantiles of usage data that are recommended as requests
+type requestQuantiles struct {
+	cpu    float64
+	memory float64
 }
 
 const (
-	// cpuRequestQuantile is the quantile of CPU core usage data to use as the CPU request
+	// cpuRequestQuantile is the default quantile of CPU core usage data to use as the CPU request
 	cpuRequestQuantile = 0.8
+
+	// minSamplesForQuantile is the number of samples below which the configured quantile is not used.
+	// A high quantile of only a few samples is the largest outlier, while a low one under-provisions
+	// the workload, so the default quantile is used for such sparse data instead.
+	minSamplesForQuantile = 100
 )
 
 func formatCPU() toQuantity {
@@ -49,11 +62,11 @@ func formatCPU() toQuantity {
 
 func (s *resourceServer) digestCPU(data *podscaler.CachedQuery) {
 	s.logger.Debugf("Digesting new CPU consumption metrics.")
-	s.digestData(data, cpuRequestQuantile, corev1.ResourceCPU, formatCPU())
+	s.digestData(data, s.quantiles.cpu, cpuRequestQuantile, corev1.ResourceCPU, formatCPU())
 }
 
 const (
-	// memRequestQuantile is the quantile of memory usage data to use as the memory request
+	// memRequestQuantile is the default quantile of memory usage data to use as the memory request
 	memRequestQuantile = 0.8
 )
 
@@ -65,12 +78,12 @@ func formatMemory() toQuantity {
 
 func (s *resourceServer) digestMemory(data *podscaler.CachedQuery) {
 	s.logger.Debugf("Digesting new memory consumption metrics.")
-	s.digestData(data, memRequestQuantile, corev1.ResourceMemory, formatMemory())
+	s.digestData(data, s.quantiles.memory, memRequestQuantile, corev1.ResourceMemory, formatMemory())
 }
 
 type toQuantity func(valueAtQuantile float64) (quantity *resource.Quantity)
 
-func (s *resourceServer) digestData(data *podscaler.CachedQuery, quantile float64, request corev1.ResourceName, quantity toQuantity) {
+func (s *resourceServer) digestData(data *podscaler.CachedQuery, quantile, defaultQuantile float64, request corev1.ResourceName, quantity toQuantity) {
 	logger := s.logger.WithField("resource", request)
 	logger.Debugf("Digesting %d identifiers.", len(data.DataByMetaData))
 	for meta, fingerprintTimes := range data.DataByMetaData {
@@ -81,7 +94,7 @@ func (s *resourceServer) digestData(data *podscaler.CachedQuery, quantile float6
 			overall.Merge(data.Data[fingerprintTime.Fingerprint].Histogram())
 		}
 		metaLogger.Trace("merged all fingerprints")
-		valueAtQuantile := overall.ValueAtQuantile(quantile)
+		valueAtQuantile := overall.ValueAtQuantile(quantileFor(overall, quantile, defaultQuantile))
 		metaLogger.Trace("locking for value update")
 		s.lock.Lock()
 		if _, exists := s.byMetaData[meta]; !exists {
@@ -98,6 +111,15 @@ func (s *resourceServer) digestData(data *podscaler.CachedQuery, quantile float6
 	logger.Debug("Finished digesting new data.")
 }
 
+// quantileFor returns the quantile to use for the histogram, falling back to the default
+// one when there are too few samples to trust the configured quantile
+func quantileFor(histogram *circonusllhist.Histogram, quantile, defaultQuantile float64) float64 {
+	if quantile == 0 || histogram.Count() < minSamplesForQuantile {
+		return defaultQuantile
+	}
+	return quantile
+}
+
 func (s *resourceServer) recommendedRequestFor(meta podscaler.FullMetadata) (corev1.ResourceRequirements, bool) {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/openhistogram/circonusllhist"
+	"github.com/prometheus/common/model"
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+
+	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
+)
+
+func TestDigestDataQuantile(t *testing.T) {
+	meta := podscaler.FullMetadata{Target: "unit", Container: "test"}
+	histogramWith := func(samples int) *circonusllhist.Histogram {
+		histogram := circonusllhist.New()
+		for i := 1; i <= samples; i++ {
+			if err := histogram.RecordValue(float64(i * 1024 * 1024)); err != nil {
+				t.Fatalf("failed to record value: %v", err)
+			}
+		}
+		return histogram
+	}
+
+	testCases := []struct {
+		name             string
+		samples          int
+		quantile         float64
+		expectedQuantile float64
+	}{
+		{
+			name:             "default quantile",
+			samples:          1000,
+			quantile:         memRequestQuantile,
+			expectedQuantile: memRequestQuantile,
+		},
+		{
+			name:             "configured quantile is used with enough samples",
+			samples:          1000,
+			quantile:         0.99,
+			expectedQuantile: 0.99,
+		},
+		{
+			name:             "configured quantile is used with just enough samples",
+			samples:          minSamplesForQuantile,
+			quantile:         0.99,
+			expectedQuantile: 0.99,
+		},
+		{
+			name:             "too few samples fall back to the default quantile",
+			samples:          minSamplesForQuantile - 1,
+			quantile:         0.99,
+			expectedQuantile: memRequestQuantile,
+		},
+		{
+			name:             "unset quantile falls back to the default quantile",
+			samples:          1000,
+			expectedQuantile: memRequestQuantile,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			histogram := histogramWith(tc.samples)
+			data := &podscaler.CachedQuery{
+				Data:           map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{1: circonusllhist.NewHistogramWithoutLookups(histogram)},
+				DataByMetaData: map[podscaler.FullMetadata][]podscaler.FingerprintTime{meta: {{Fingerprint: 1}}},
+			}
+			server := &resourceServer{
+				logger:     logrus.WithField("test", tc.name),
+				lock:       sync.RWMutex{},
+				byMetaData: map[podscaler.FullMetadata]corev1.ResourceRequirements{},
+				quantiles:  requestQuantiles{memory: tc.quantile},
+			}
+			server.digestMemory(data)
+
+			recommendation, ok := server.recommendedRequestFor(meta)
+			if !ok {
+				t.Fatal("expected a recommendation")
+			}
+			expected := formatMemory()(histogram.ValueAtQuantile(tc.expectedQuantile))
+			if actual := recommendation.Requests[corev1.ResourceMemory]; actual.Cmp(*expected) != 0 {
+				t.Errorf("expected memory request %s, got %s", expected.String(), actual.String())
+			}
+		})
+	}
+}
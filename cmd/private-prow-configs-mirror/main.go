@@ -164,7 +164,7 @@ func getOrgReposWithOfficialImages(configDir string, whitelist map[string][]stri
 		return nil
 	}
 
-	if err := config.OperateOnCIOperatorConfigDir(configDir, callback); err != nil {
+	if err := config.OperateOnCIOperatorConfigDir(configDir, nil, callback); err != nil {
 		return ret, fmt.Errorf("error while operating in ci-operator configuration files: %w", err)
 	}
 
@@ -415,9 +415,12 @@ func getCommonPlugins(privateRepoPlugins map[string][]string) sets.Set[string] {
 
 func getAllConfigs(releaseRepoPath string) (*config.ReleaseRepoConfig, error) {
 	c := &config.ReleaseRepoConfig{}
-	var err error
+	clusterProfiles, err := config.LoadClusterProfiles(releaseRepoPath)
+	if err != nil {
+		return nil, err
+	}
 	ciopConfigPath := filepath.Join(releaseRepoPath, config.CiopConfigInRepoPath)
-	c.CiOperator, err = config.LoadDataByFilename(ciopConfigPath)
+	c.CiOperator, err = config.LoadDataByFilename(ciopConfigPath, clusterProfiles)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load ci-operator configuration from release repo: %w", err)
 	}
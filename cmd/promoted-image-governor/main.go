@@ -434,7 +434,7 @@ func main() {
 	}
 	var promotedTags []api.ImageStreamTagReference
 	var ignoredCommitTags []*regexp.Regexp
-	if err := config.OperateOnCIOperatorConfigDir(abs, func(cfg *api.ReleaseBuildConfiguration, metadata *config.Info) error {
+	if err := config.OperateOnCIOperatorConfigDir(abs, nil, func(cfg *api.ReleaseBuildConfiguration, metadata *config.Info) error {
 		for _, isTagRef := range release.PromotedTags(cfg) {
 			logrus.WithField("metadata", metadata).WithField("tag", isTagRef.ISTagName()).Debug("Appending promoted tag ...")
 			promotedTags = append(promotedTags, isTagRef)
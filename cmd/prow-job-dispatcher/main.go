@@ -16,6 +16,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/robfig/cron/v3"
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
@@ -52,12 +53,19 @@ type options struct {
 	clusterConfigPath string
 	jobsStoragePath   string
 
+	historyStoragePath string
+	historyMaxEntries  int
+
 	prometheusDaysBefore int
+	weightByDuration     bool
+	volumeCachePath      string
+	volumeCacheTTL       time.Duration
 
-	createPR    bool
-	githubLogin string
-	targetDir   string
-	assign      string
+	createPR     bool
+	validateOnly bool
+	githubLogin  string
+	targetDir    string
+	assign       string
 
 	enableClusters  flagutil.Strings
 	disableClusters flagutil.Strings
@@ -69,6 +77,12 @@ type options struct {
 
 	slackTokenPath string
 	opsChannelId   string
+
+	volumeShareAlertMax      float64
+	volumeShareAlertMin      float64
+	volumeShareAlertInterval time.Duration
+
+	specialClusterVolumeThreshold float64
 }
 
 type slackClient interface {
@@ -83,9 +97,15 @@ func gatherOptions() options {
 	fs.StringVar(&o.configPath, "config-path", "", "Path to the config file (core-services/sanitize-prow-jobs/_config.yaml in openshift/release)")
 	fs.StringVar(&o.clusterConfigPath, "cluster-config-path", "core-services/sanitize-prow-jobs/_clusters.yaml", "Path to the config file (core-services/sanitize-prow-jobs/_clusters.yaml in openshift/release)")
 	fs.StringVar(&o.jobsStoragePath, "jobs-storage-path", "", "Path to the file holding only job assignments in Gob format")
+	fs.StringVar(&o.historyStoragePath, "history-storage-path", "", "Path to the file holding the history of assignment changes in Gob format. Defaults to the --jobs-storage-path with a .history suffix.")
+	fs.IntVar(&o.historyMaxEntries, "history-max-entries", 100, "Number of dispatches whose assignment changes are kept in the history. Older ones are dropped.")
 	fs.IntVar(&o.prometheusDaysBefore, "prometheus-days-before", 1, "Number [1,15] of days before. Time 00-00-00 of that day will be used as time to query Prometheus. E.g., 1 means 00-00-00 of yesterday.")
+	fs.BoolVar(&o.weightByDuration, "weight-by-duration", false, "Weight the job volumes by the average job durations from Prometheus. Jobs without duration data are weighted by their count only.")
+	fs.StringVar(&o.volumeCachePath, "volume-cache-path", "", "Path to the file caching the last job volumes fetched from Prometheus in Gob format. The cached volumes are used when Prometheus is unreachable.")
+	fs.DurationVar(&o.volumeCacheTTL, "volume-cache-ttl", 72*time.Hour, "How long the cached job volumes may be used for when Prometheus is unreachable. The time is counted from the day they were queried for to the day that would be queried, so the cache expires at a day boundary.")
 
 	fs.BoolVar(&o.createPR, "create-pr", false, "Create a pull request to the change made with this tool.")
+	fs.BoolVar(&o.validateOnly, "validate-only", false, "Dispatch the jobs in memory, print the number of jobs per cluster and exit non-zero if some jobs cannot be assigned. Nothing is written and Prometheus, Slack and GitHub are not contacted.")
 	fs.StringVar(&o.githubLogin, "github-login", githubLogin, "The GitHub username to use.")
 	fs.StringVar(&o.targetDir, "target-dir", "", "The directory containing the target repo.")
 	fs.StringVar(&o.assign, "assign", "ghost", "The github username or group name to assign the created pull request to.")
@@ -95,6 +115,10 @@ func gatherOptions() options {
 	fs.StringVar(&o.defaultCluster, "default-cluster", "", "If passed, changes the default cluster to the specified value.")
 	fs.StringVar(&o.slackTokenPath, "slack-token-path", "", "Path to the file containing the Slack token to use.")
 	fs.StringVar(&o.opsChannelId, "ops-channel-id", "CHY2E1BL4", "Channel ID for #ops-testplatform")
+	fs.Float64Var(&o.volumeShareAlertMax, "volume-share-alert-max", 0, "If set, warn in the ops channel when a build farm cluster receives more than this fraction of the job volume after a dispatch, e.g. 0.4.")
+	fs.Float64Var(&o.volumeShareAlertMin, "volume-share-alert-min", 0, "If set, warn in the ops channel when a build farm cluster receives less than this fraction of the job volume after a dispatch, e.g. 0.05.")
+	fs.DurationVar(&o.volumeShareAlertInterval, "volume-share-alert-interval", 24*time.Hour, "Minimum time between two volume share warnings about the same cluster.")
+	fs.Float64Var(&o.specialClusterVolumeThreshold, "special-cluster-volume-threshold", 0, "If set, warn when the job volume dispatched to a cluster outside of the build farm exceeds this value.")
 
 	o.GitAuthorOptions.AddFlags(fs)
 	o.PrometheusOptions.AddFlags(fs)
@@ -119,15 +143,43 @@ func (o *options) validate() error {
 		return fmt.Errorf("--prometheus-days-before must be between 1 and 15")
 	}
 
+	if o.historyMaxEntries < 1 {
+		return fmt.Errorf("--history-max-entries must be positive")
+	}
+
+	if o.volumeCacheTTL < 0 {
+		return fmt.Errorf("--volume-cache-ttl must not be negative")
+	}
+
+	if o.volumeShareAlertMax < 0 || o.volumeShareAlertMax > 1 {
+		return fmt.Errorf("--volume-share-alert-max must be between 0 and 1")
+	}
+	if o.volumeShareAlertMin < 0 || o.volumeShareAlertMin > 1 {
+		return fmt.Errorf("--volume-share-alert-min must be between 0 and 1")
+	}
+	if o.volumeShareAlertMax > 0 && o.volumeShareAlertMin >= o.volumeShareAlertMax {
+		return fmt.Errorf("--volume-share-alert-min must be lower than --volume-share-alert-max")
+	}
+	if o.volumeShareAlertInterval < 0 {
+		return fmt.Errorf("--volume-share-alert-interval must not be negative")
+	}
+	if o.specialClusterVolumeThreshold < 0 {
+		return fmt.Errorf("--special-cluster-volume-threshold must not be negative")
+	}
+
 	if o.clusterConfigPath == "" {
 		logrus.Fatal("mandatory argument --cluster-config-path wasn't set")
 	}
 
-	if o.jobsStoragePath == "" {
+	if o.validateOnly && o.createPR {
+		return fmt.Errorf("--validate-only and --create-pr are mutually exclusive")
+	}
+
+	if o.jobsStoragePath == "" && !o.validateOnly {
 		logrus.Fatal("mandatory argument --jobs-storage-path wasn't set")
 	}
 
-	if o.slackTokenPath == "" {
+	if o.slackTokenPath == "" && !o.validateOnly {
 		logrus.Fatal("mandatory argument --slack-token-path wasn't set")
 	}
 
@@ -196,6 +248,13 @@ type clusterVolume struct {
 	blocked            sets.Set[string]
 	volumeDistribution map[string]float64
 	clusterMap         dispatcher.ClusterMap
+	// drained clusters keep the jobs assigned to them in existing but get no new ones
+	drained  sets.Set[string]
+	existing map[string]string
+	// explanations records why each job landed on its cluster
+	explanations map[string]dispatcher.Explanation
+	// specialClusterVolumeThreshold is the volume above which a cluster outside of the build farm is reported, zero disables it
+	specialClusterVolumeThreshold float64
 }
 
 // findClusterForJobConfig finds a cluster running on a preferred cloud provider for the jobs in a Prow job config.
@@ -206,6 +265,8 @@ func (cv *clusterVolume) findClusterForJobConfig(cloudProvider string, jc *prowc
 		cloudProvider = ""
 	}
 	var cluster string
+	var reason dispatcher.AssignmentReason
+	var candidates []string
 	var totalVolume float64
 	for _, volume := range jobVolumes {
 		totalVolume += volume
@@ -213,31 +274,46 @@ func (cv *clusterVolume) findClusterForJobConfig(cloudProvider string, jc *prowc
 
 	mostUsedCluster := dispatcher.FindMostUsedCluster(jc)
 	// TODO: 75% as we still have manual assignments and these are affecting even distribution, re-evaluate when manual assignments are gone
-	if determinedCloudProvider := config.IsInBuildFarm(api.Cluster(mostUsedCluster)); determinedCloudProvider != "" &&
+	if determinedCloudProvider := config.IsInBuildFarm(api.Cluster(mostUsedCluster)); determinedCloudProvider != "" && !cv.drained.Has(mostUsedCluster) &&
 		cv.clusterVolumeMap[string(determinedCloudProvider)][mostUsedCluster] < cv.volumeDistribution[mostUsedCluster]*0.75 {
 		cluster = mostUsedCluster
+		reason = dispatcher.ReasonMostUsedCluster
+		candidates = []string{mostUsedCluster}
 	} else {
-		min := float64(-1)
+		reason = dispatcher.ReasonVolumeMin
+		weightedVolumes := map[string]float64{}
 		for _, cp := range sets.List(cv.cloudProviders) {
-			m := cv.clusterVolumeMap[cp]
-			for c, v := range m {
-				if cv.clusterMap[c].Capacity != 100 {
+			if cloudProvider != "" && cloudProvider != cp {
+				continue
+			}
+			for c, v := range cv.clusterVolumeMap[cp] {
+				capacity := cv.clusterMap[c].Capacity
+				if capacity <= 0 || capacity > 100 || cv.drained.Has(c) {
 					continue
 				}
-				if cloudProvider == "" || cloudProvider == cp {
-					if min < 0 || min > v {
-						min = v
-						cluster = c
-					}
-				}
+				candidates = append(candidates, c)
+				weightedVolumes[c] = cv.weightedVolume(c, v)
 			}
 		}
+		// the candidates are sorted so that ties are broken by the cluster name rather than the map iteration order
+		sort.Strings(candidates)
+		min := float64(-1)
+		for _, c := range candidates {
+			if weighted := weightedVolumes[c]; min < 0 || min > weighted {
+				min = weighted
+				cluster = c
+			}
+		}
+		// the file is assigned as a whole, so the cap applies to the volume of all of its jobs
+		if capped := cv.capToShare(cluster, candidates, cv.blocked, jobConfigVolume(jc, jobVolumes), config); capped != cluster {
+			cluster, reason = capped, dispatcher.ReasonVolumeCap
+		}
 	}
 
 	var errs []error
 	for k := range jc.PresubmitsStatic {
 		for _, job := range jc.PresubmitsStatic[k] {
-			if err := cv.addToVolume(cluster, job.JobBase, path, config, jobVolumes); err != nil {
+			if err := cv.addToVolume(cluster, reason, candidates, job.JobBase, path, config, jobVolumes); err != nil {
 				errs = append(errs, err)
 			}
 		}
@@ -245,13 +321,13 @@ func (cv *clusterVolume) findClusterForJobConfig(cloudProvider string, jc *prowc
 
 	for k := range jc.PostsubmitsStatic {
 		for _, job := range jc.PostsubmitsStatic[k] {
-			if err := cv.addToVolume(cluster, job.JobBase, path, config, jobVolumes); err != nil {
+			if err := cv.addToVolume(cluster, reason, candidates, job.JobBase, path, config, jobVolumes); err != nil {
 				errs = append(errs, err)
 			}
 		}
 	}
 	for _, job := range jc.Periodics {
-		if err := cv.addToVolume(cluster, job.JobBase, path, config, jobVolumes); err != nil {
+		if err := cv.addToVolume(cluster, reason, candidates, job.JobBase, path, config, jobVolumes); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -268,7 +344,7 @@ func findClusterAssigmentsForMissingJobs(jc *prowconfig.JobConfig, path string,
 			return fmt.Errorf("failed to determine cluster for the job %s in path %q: %w", jobBase.Name, path, err)
 		}
 
-		c := dispatcher.DetermineTargetCluster(cluster, string(determinedCluster), string(config.Default), canBeRelocated, blocked)
+		c := dispatcher.DetermineTargetCluster(cluster, string(determinedCluster), string(config.Default), canBeRelocated, dispatcher.BlockedClustersForJob(jobBase, blocked, cm))
 		pjs[jobBase.Name] = c
 		logrus.WithField("job", jobBase.Name).WithField("cluster", c).Info("found cluster for missing job")
 		return nil
@@ -305,15 +381,90 @@ func findClusterAssigmentsForMissingJobs(jc *prowconfig.JobConfig, path string,
 	return utilerrors.NewAggregate(errs)
 }
 
-func (cv *clusterVolume) addToVolume(cluster string, jobBase prowconfig.JobBase, path string, config *dispatcher.Config, jobVolumes map[string]float64) error {
+// weightedVolume returns the volume of the cluster weighted by its capacity: a cluster running
+// at reduced capacity looks proportionally more loaded
+func (cv *clusterVolume) weightedVolume(cluster string, volume float64) float64 {
+	return volume * 100 / float64(cv.clusterMap[cluster].Capacity)
+}
+
+// jobConfigVolume returns the volume of all jobs defined in a Prow job config
+func jobConfigVolume(jc *prowconfig.JobConfig, jobVolumes map[string]float64) float64 {
+	var volume float64
+	for k := range jc.PresubmitsStatic {
+		for _, job := range jc.PresubmitsStatic[k] {
+			volume += jobVolumes[job.Name]
+		}
+	}
+	for k := range jc.PostsubmitsStatic {
+		for _, job := range jc.PostsubmitsStatic[k] {
+			volume += jobVolumes[job.Name]
+		}
+	}
+	for _, job := range jc.Periodics {
+		volume += jobVolumes[job.Name]
+	}
+	return volume
+}
+
+// capToShare returns the cluster a file chosen by volume should be assigned to so that the cluster does not
+// exceed its share of the volume with the volume of the file. Otherwise, the least loaded candidate of the
+// same cloud provider that stays below its share is chosen instead. The cluster is kept if there is no such candidate.
+func (cv *clusterVolume) capToShare(cluster string, candidates []string, blocked sets.Set[string], volume float64, config *dispatcher.Config) string {
+	cloudProvider := string(config.IsInBuildFarm(api.Cluster(cluster)))
+	if share := cv.volumeDistribution[cluster]; cloudProvider == "" || share <= 0 || cv.clusterVolumeMap[cloudProvider][cluster]+volume <= share {
+		return cluster
+	}
+	capped := cluster
+	min := float64(-1)
+	for _, c := range candidates {
+		if c == cluster || blocked.Has(c) || string(config.IsInBuildFarm(api.Cluster(c))) != cloudProvider {
+			continue
+		}
+		v := cv.clusterVolumeMap[cloudProvider][c]
+		if v+volume > cv.volumeDistribution[c] {
+			continue
+		}
+		if weighted := cv.weightedVolume(c, v); min < 0 || min > weighted {
+			min = weighted
+			capped = c
+		}
+	}
+	return capped
+}
+
+// addToVolume assigns the job to a cluster and adds its volume to the cluster. The reason and
+// candidates describe how the cluster was chosen for the whole job config and are recorded as
+// the explanation of the assignment unless the job itself determines its cluster.
+func (cv *clusterVolume) addToVolume(cluster string, reason dispatcher.AssignmentReason, candidates []string, jobBase prowconfig.JobBase, path string, config *dispatcher.Config, jobVolumes map[string]float64) error {
 	determinedCluster, canBeRelocated, err := config.DetermineClusterForJob(jobBase, path, cv.clusterMap)
 
 	if err != nil {
 		return fmt.Errorf("failed to determine cluster for the job %s in path %q: %w", jobBase.Name, path, err)
 	}
 
-	c := dispatcher.DetermineTargetCluster(cluster, string(determinedCluster), string(config.Default), canBeRelocated, cv.blocked)
+	var c string
+	if existing, ok := cv.existing[jobBase.Name]; ok && cv.drained.Has(existing) {
+		c = existing
+		reason, candidates = dispatcher.ReasonDrained, []string{existing}
+	} else {
+		blocked := dispatcher.BlockedClustersForJob(jobBase, cv.blocked, cv.clusterMap).Union(cv.drained)
+		c = dispatcher.DetermineTargetCluster(cluster, string(determinedCluster), string(config.Default), canBeRelocated, blocked)
+		switch {
+		case c == string(determinedCluster) && (cluster == "" || !canBeRelocated):
+			reason, candidates = dispatcher.ReasonPinned, []string{c}
+		case c != cluster:
+			reason = dispatcher.ReasonBlocked
+		}
+	}
 	cv.pjs[jobBase.Name] = c
+	if cv.explanations != nil {
+		cv.explanations[jobBase.Name] = dispatcher.Explanation{
+			Cluster:      c,
+			Reason:       reason,
+			Capabilities: dispatcher.RequiredCapabilities(jobBase),
+			Candidates:   candidates,
+		}
+	}
 	if determinedCloudProvider := config.IsInBuildFarm(api.Cluster(c)); determinedCloudProvider != "" {
 		cv.clusterVolumeMap[string(determinedCloudProvider)][c] = cv.clusterVolumeMap[string(determinedCloudProvider)][c] + jobVolumes[jobBase.Name]
 		return nil
@@ -322,6 +473,61 @@ func (cv *clusterVolume) addToVolume(cluster string, jobBase prowconfig.JobBase,
 	return nil
 }
 
+// retainJobConfig keeps the jobs defined in a Prow job config that is excluded from the dispatch on their
+// current cluster, without accounting for their volume. Jobs without an existing assignment get the cluster
+// determined by the config. It returns the build farm cluster the file is currently assigned to, if any.
+func (cv *clusterVolume) retainJobConfig(jc *prowconfig.JobConfig, path string, config *dispatcher.Config) (string, error) {
+	var errs []error
+	retain := func(jobBase prowconfig.JobBase) {
+		c, ok := cv.existing[jobBase.Name]
+		if !ok {
+			determinedCluster, _, err := config.DetermineClusterForJob(jobBase, path, cv.clusterMap)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to determine cluster for the job %s in path %q: %w", jobBase.Name, path, err))
+				return
+			}
+			c = string(determinedCluster)
+		}
+		cv.pjs[jobBase.Name] = c
+		if cv.explanations != nil {
+			cv.explanations[jobBase.Name] = dispatcher.Explanation{
+				Cluster:      c,
+				Reason:       dispatcher.ReasonExcluded,
+				Capabilities: dispatcher.RequiredCapabilities(jobBase),
+				Candidates:   []string{c},
+			}
+		}
+	}
+	for k := range jc.PresubmitsStatic {
+		for _, job := range jc.PresubmitsStatic[k] {
+			retain(job.JobBase)
+		}
+	}
+	for k := range jc.PostsubmitsStatic {
+		for _, job := range jc.PostsubmitsStatic[k] {
+			retain(job.JobBase)
+		}
+	}
+	for _, job := range jc.Periodics {
+		retain(job.JobBase)
+	}
+	return string(config.BuildFarmClusterForFile(filepath.Base(path))), utilerrors.NewAggregate(errs)
+}
+
+// specialClustersOverThreshold returns the clusters outside of the build farm whose volume exceeds the threshold
+func (cv *clusterVolume) specialClustersOverThreshold() []string {
+	if cv.specialClusterVolumeThreshold <= 0 {
+		return nil
+	}
+	var clusters []string
+	for _, cluster := range sets.List(sets.KeySet(cv.specialClusters)) {
+		if cv.specialClusters[cluster] > cv.specialClusterVolumeThreshold {
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters
+}
+
 // dispatchJobConfig dispatches the jobs defined in a Prow jon config
 func (cv *clusterVolume) dispatchJobConfig(jc *prowconfig.JobConfig, path string, config *dispatcher.Config, jobVolumes map[string]float64) (string, error) {
 	cloudProvidersForE2ETests := getCloudProvidersForE2ETests(jc)
@@ -354,9 +560,14 @@ type fileSizeInfo struct {
 //   - When all the e2e tests are targeting the same cloud provider, we run the test pod on the that cloud provider too.
 //   - When the e2e tests are targeting different cloud providers, or there is no e2e tests at all, we can run the tests
 //     on any cluster in the build farm. Those jobs are used to load balance the workload of clusters in the build farm.
-func dispatchJobs(prowJobConfigDir string, config *dispatcher.Config, jobVolumes map[string]float64, blocked sets.Set[string], volumeDistribution map[string]float64, cm dispatcher.ClusterMap) (map[string]string, error) {
+//
+// Jobs that are currently assigned to a drained cluster according to existing stay there, no other job is assigned to it.
+// Files matching an exclude pattern of the config are not dispatched: their jobs keep the assignment from existing and
+// do not count towards the volume of the clusters.
+// Along with the assignments, it returns an explanation of each of them.
+func dispatchJobs(prowJobConfigDir string, config *dispatcher.Config, jobVolumes map[string]float64, blocked, drained sets.Set[string], existing map[string]string, volumeDistribution map[string]float64, cm dispatcher.ClusterMap, specialClusterVolumeThreshold float64) (map[string]string, map[string]dispatcher.Explanation, error) {
 	if config == nil {
-		return nil, fmt.Errorf("config is nil")
+		return nil, nil, fmt.Errorf("config is nil")
 	}
 
 	// cv stores the volume for each cluster in the build farm
@@ -365,9 +576,15 @@ func dispatchJobs(prowJobConfigDir string, config *dispatcher.Config, jobVolumes
 		cloudProviders:     sets.New[string](),
 		pjs:                map[string]string{},
 		blocked:            blocked,
+		drained:            drained,
+		existing:           existing,
+		explanations:       map[string]dispatcher.Explanation{},
 		specialClusters:    map[string]float64{},
 		volumeDistribution: volumeDistribution,
-		clusterMap:         cm}
+		clusterMap:         cm,
+
+		specialClusterVolumeThreshold: specialClusterVolumeThreshold,
+	}
 	for cloudProvider, v := range config.BuildFarm {
 		for cluster := range v {
 			cloudProviderString := string(cloudProvider)
@@ -383,13 +600,23 @@ func dispatchJobs(prowJobConfigDir string, config *dispatcher.Config, jobVolumes
 
 	// no clusters in the build farm
 	if len(cv.clusterVolumeMap) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	results := map[string][]string{}
 	var errs []error
 
 	dispatch := func(jobConfig *prowconfig.JobConfig, path string, info fs.DirEntry) {
+		if config.MatchingExcludePattern(path) {
+			cluster, err := cv.retainJobConfig(jobConfig, path, config)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to retain job config %q: %w", path, err))
+			}
+			if cluster != "" && !config.MatchingPathRegEx(path) {
+				results[cluster] = append(results[cluster], info.Name())
+			}
+			return
+		}
 		cluster, err := cv.dispatchJobConfig(jobConfig, path, config, jobVolumes)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("failed to dispatch job config %q: %w", path, err))
@@ -402,7 +629,7 @@ func dispatchJobs(prowJobConfigDir string, config *dispatcher.Config, jobVolumes
 	}
 	fileList, err := composeFileInfoList(prowJobConfigDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to dispatch all Prow jobs: %w", err)
+		return nil, nil, fmt.Errorf("failed to dispatch all Prow jobs: %w", err)
 	}
 
 	sort.Slice(fileList, func(i, j int) bool { return fileList[i].size > fileList[j].size })
@@ -419,13 +646,18 @@ func dispatchJobs(prowJobConfigDir string, config *dispatcher.Config, jobVolumes
 	for cluster, volume := range cv.specialClusters {
 		logrus.WithField("cluster", cluster).WithField("volume", volume).Info("dispatched the volume on the cluster")
 	}
+	for _, cluster := range cv.specialClustersOverThreshold() {
+		logrus.WithField("cluster", cluster).WithField("volume", cv.specialClusters[cluster]).WithField("threshold", cv.specialClusterVolumeThreshold).
+			Warn("the volume dispatched on the cluster outside of the build farm exceeds the threshold, consider moving its jobs into the build farm")
+	}
+	cv.recordMetrics()
 	for cloudProvider, jobGroups := range config.BuildFarm {
 		for cluster := range jobGroups {
 			config.BuildFarm[cloudProvider][cluster] = &dispatcher.BuildFarmConfig{FilenamesRaw: results[string(cluster)]}
 		}
 	}
 
-	return cv.pjs, utilerrors.NewAggregate(errs)
+	return cv.pjs, cv.explanations, utilerrors.NewAggregate(errs)
 }
 
 func dispatchMissingJobs(prowJobConfigDir string, config *dispatcher.Config, blocked sets.Set[string], pjs map[string]string, cm dispatcher.ClusterMap) error {
@@ -607,7 +839,7 @@ func createPR(o options, config *dispatcher.Config, pjs map[string]string, cm di
 		logrus.WithError(err).WithField("configPath", o.configPath).Fatal("failed to save config file")
 	}
 
-	if err := sanitizer.DeterminizeJobs(filepath.Join(targetDirWithRelease, "/ci-operator/jobs"), config, pjs, make(sets.Set[string]), cm); err != nil {
+	if err := sanitizer.DeterminizeJobs(filepath.Join(targetDirWithRelease, "/ci-operator/jobs"), config, pjs, make(sets.Set[string]), nil, cm); err != nil {
 		logrus.WithError(err).Fatal("failed to determinize")
 	}
 
@@ -644,6 +876,21 @@ func main() {
 		logrus.WithError(err).Fatal("Failed to complete options.")
 	}
 
+	if o.validateOnly {
+		config, err := dispatcher.LoadConfig(o.configPath)
+		if err != nil {
+			logrus.WithError(err).Fatalf("failed to load config from %q", o.configPath)
+		}
+		cm, blocked, drained, err := dispatcher.LoadClusterConfig(o.clusterConfigPath)
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to load cluster config")
+		}
+		if err := validateDispatch(o.prowJobConfigDir, config, cm, blocked, drained, os.Stdout); err != nil {
+			logrus.WithError(err).Fatal("validation failed")
+		}
+		return
+	}
+
 	if o.createPR {
 		if err := o.PRCreationOptions.Finalize(); err != nil {
 			logrus.WithError(err).Fatal("Failed to finalize PR creation options")
@@ -662,7 +909,7 @@ func main() {
 		}
 	}
 
-	promVolumes, err := newPrometheusVolumes(o.PrometheusOptions, o.prometheusDaysBefore)
+	promVolumes, err := newPrometheusVolumes(o.PrometheusOptions, o.prometheusDaysBefore, o.weightByDuration, o.volumeCachePath, o.volumeCacheTTL)
 	if err != nil {
 		logrus.WithError(err).Fatal("failed to create prometheus volumes")
 	}
@@ -682,11 +929,22 @@ func main() {
 	var dispatchWrapper func(forceDispatch bool)
 	var dispatchDeltaWrapper func()
 	prowjobs := dispatcher.NewProwjobs(o.jobsStoragePath)
+	historyStoragePath := o.historyStoragePath
+	if historyStoragePath == "" {
+		historyStoragePath = o.jobsStoragePath + ".history"
+	}
+	history := dispatcher.NewHistory(historyStoragePath, o.historyMaxEntries)
+	recordHistory := func(previous, current map[string]string) {
+		if err := history.Record(time.Now(), previous, current); err != nil {
+			logrus.WithError(err).Error("failed to write the assignment history")
+		}
+	}
 	c := cron.New()
 
 	{
 		var mu sync.Mutex
 		slackClient := slack.New(string(secret.GetSecret(o.slackTokenPath)))
+		volumeAlerter := newVolumeShareAlerter(o.volumeShareAlertMax, o.volumeShareAlertMin, o.volumeShareAlertInterval)
 
 		dispatchDeltaWrapper = func() {
 			mu.Lock()
@@ -696,19 +954,22 @@ func main() {
 				logrus.WithError(err).Errorf("failed to load config from %q", o.configPath)
 				return
 			}
-			cm, blocked, err := dispatcher.LoadClusterConfig(o.clusterConfigPath)
+			cm, blocked, drained, err := dispatcher.LoadClusterConfig(o.clusterConfigPath)
 			if err != nil {
 				logrus.WithError(err).Error("failed to load cluster config")
 				return
 			}
 
+			previous := prowjobs.GetDataCopy()
 			pjs := prowjobs.GetDataCopy()
 
-			if err := dispatchMissingJobs(o.prowJobConfigDir, config, blocked, pjs, cm); err != nil {
+			// missing jobs are new assignments, so they must not land on drained clusters either
+			if err := dispatchMissingJobs(o.prowJobConfigDir, config, blocked.Union(drained), pjs, cm); err != nil {
 				logrus.WithError(err).Error("failed to dispatch")
 				return
 			}
 			prowjobs.Regenerate(pjs)
+			recordHistory(previous, pjs)
 		}
 
 		dispatchWrapper = func(forceDispatch bool) {
@@ -721,11 +982,12 @@ func main() {
 				return
 			}
 
-			configClusterMap, blocked, err := dispatcher.LoadClusterConfig(o.clusterConfigPath)
+			configClusterMap, blocked, drained, err := dispatcher.LoadClusterConfig(o.clusterConfigPath)
 			if err != nil {
 				logrus.WithError(err).Error("failed to load cluster config")
 				return
 			}
+			recordBlockedClusters(blocked)
 			clustersFromConfig := clustersMapToSet(configClusterMap)
 
 			enabled, disabled := getDiffClusters(getEnabledClusters(config), clustersFromConfig)
@@ -752,16 +1014,26 @@ func main() {
 					}
 					return api.Cloud(info.Provider), nil
 				})
-			pjs, err := dispatchJobs(o.prowJobConfigDir, config, jobVolumes, blocked, promVolumes.calculateVolumeDistribution(configClusterMap), configClusterMap)
+			previous := prowjobs.GetDataCopy()
+			pjs, explanations, err := dispatchJobs(o.prowJobConfigDir, config, jobVolumes, blocked, drained, prowjobs.GetDataCopy(), promVolumes.calculateVolumeDistribution(configClusterMap), configClusterMap, o.specialClusterVolumeThreshold)
 			if err != nil {
 				logrus.WithError(err).Error("failed to dispatch")
 				return
 			}
 			prowjobs.Regenerate(pjs)
+			prowjobs.SetExplanations(explanations)
 
 			if err := dispatcher.WriteGob(o.jobsStoragePath, pjs); err != nil {
 				logrus.WithError(err).Errorf("continuing on cache memory, error writing Gob file")
 			}
+			recordHistory(previous, pjs)
+
+			if volumeAlerter.enabled() {
+				shares := volumeShares(pjs, jobVolumes, getEnabledClusters(config), blocked.Union(drained))
+				if err := volumeAlerter.alert(slackClient, o.opsChannelId, shares, time.Now()); err != nil {
+					logrus.WithError(err).Error("Failed to post message in ops channel")
+				}
+			}
 
 			if o.createPR {
 				createPR(o, config, pjs, configClusterMap)
@@ -793,7 +1065,7 @@ func main() {
 		deltaTicker := time.NewTicker(5 * time.Minute)
 		defer deltaTicker.Stop()
 
-		prevConfigClusterMap, prevBlocked, err := dispatcher.LoadClusterConfig(config)
+		prevConfigClusterMap, prevBlocked, prevDrained, err := dispatcher.LoadClusterConfig(config)
 		if err != nil {
 			logrus.WithError(err).Fatal("failed to load initial cluster config")
 			return
@@ -804,18 +1076,19 @@ func main() {
 		for {
 			select {
 			case <-configTicker.C:
-				currentConfigClusterMap, currentBlocked, err := dispatcher.LoadClusterConfig(config)
+				currentConfigClusterMap, currentBlocked, currentDrained, err := dispatcher.LoadClusterConfig(config)
 				if err != nil {
 					logrus.WithError(err).Error("failed to load cluster config")
 					continue
 				}
 
-				if !reflect.DeepEqual(currentConfigClusterMap, prevConfigClusterMap) || !reflect.DeepEqual(currentBlocked, prevBlocked) {
-					logrus.WithField("prevConfigClusterMap", prevConfigClusterMap).WithField("prevBlocked", prevBlocked).
-						WithField("currentConfigClusterMap", currentConfigClusterMap).WithField("currentBlocked", currentBlocked).Info("new dispatch")
+				if !reflect.DeepEqual(currentConfigClusterMap, prevConfigClusterMap) || !reflect.DeepEqual(currentBlocked, prevBlocked) || !reflect.DeepEqual(currentDrained, prevDrained) {
+					logrus.WithField("prevConfigClusterMap", prevConfigClusterMap).WithField("prevBlocked", prevBlocked).WithField("prevDrained", prevDrained).
+						WithField("currentConfigClusterMap", currentConfigClusterMap).WithField("currentBlocked", currentBlocked).WithField("currentDrained", currentDrained).Info("new dispatch")
 					dispatchWrapper(dispatcher.HasCapacityOrCapabilitiesChanged(prevConfigClusterMap, currentConfigClusterMap))
 					prevConfigClusterMap = currentConfigClusterMap
 					prevBlocked = currentBlocked
+					prevDrained = currentDrained
 				}
 
 			case <-deltaTicker.C:
@@ -824,9 +1097,12 @@ func main() {
 		}
 	}(o.clusterConfigPath)
 
-	server := dispatcher.NewServer(prowjobs, dispatchWrapper)
+	server := dispatcher.NewServer(prowjobs, history, dispatchWrapper)
 	http.HandleFunc("/", server.RequestHandler)
 	http.HandleFunc("/event", server.EventHandler)
+	http.HandleFunc("/explain", server.ExplainHandler)
+	http.HandleFunc("/history", server.HistoryHandler)
+	http.Handle("/metrics", promhttp.Handler())
 	logrus.Fatal(http.ListenAndServe(":8080", nil))
 
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"path/filepath"
 	"reflect"
@@ -82,6 +83,9 @@ func TestDispatchJobs(t *testing.T) {
 		expectedBuildFarm map[api.Cloud]map[api.Cluster]*dispatcher.BuildFarmConfig
 		distribution      map[string]float64
 		clusterMap        dispatcher.ClusterMap
+		drained           sets.Set[string]
+		existing          map[string]string
+		expectedJobs      map[string]string
 	}{
 		{
 			name:     "nil config",
@@ -113,11 +117,92 @@ func TestDispatchJobs(t *testing.T) {
 				"gcp": {"build02": {FilenamesRaw: []string{"wildfly-operator-presubmits.yaml", "xyz-operator-presubmits.yaml"}}},
 			},
 		},
+		{
+			name:             "drained cluster keeps existing jobs but gets no new ones",
+			config:           &c,
+			prowJobConfigDir: filepath.Join("testdata", t.Name()),
+			jobVolumes: map[string]float64{
+				"pull-ci-openshift-cluster-api-provider-gcp-master-e2e-gcp":          24,
+				"pull-ci-openshift-ci-tools-master-breaking-changes":                 43,
+				"pull-ci-openshift-ci-tools-master-e2e":                              12,
+				"pull-ci-openshift-cluster-etcd-operator-master-unit":                6,
+				"pull-ci-openshift-cluster-api-provider-gcp-master-e2e-gcp-operator": 3,
+				"branch-ci-wildfly-wildfly-operator-master-images":                   2,
+				"branch-ci-xyz-xyz-operator-master-images":                           10,
+			},
+			distribution: map[string]float64{
+				"build01": 50,
+				"build02": 50,
+			},
+			clusterMap: dispatcher.ClusterMap{
+				"build01": dispatcher.ClusterInfo{Capacity: 100},
+				"build02": dispatcher.ClusterInfo{Capacity: 100},
+			},
+			drained: sets.New[string]("build02"),
+			existing: map[string]string{
+				"branch-ci-wildfly-wildfly-operator-master-images": "build02",
+				"branch-ci-xyz-xyz-operator-master-images":         "build01",
+			},
+			expectedBuildFarm: map[api.Cloud]map[api.Cluster]*dispatcher.BuildFarmConfig{
+				"aws": {"build01": {FilenamesRaw: []string{"cluster-etcd-operator-master-presubmits.yaml", "cluster-api-provider-gcp-presubmits.yaml", "ci-tools-presubmits.yaml", "wildfly-operator-presubmits.yaml", "xyz-operator-presubmits.yaml"}}},
+				"gcp": {"build02": {}},
+			},
+			expectedJobs: map[string]string{
+				"branch-ci-wildfly-wildfly-operator-master-images": "build02",
+				"branch-ci-xyz-xyz-operator-master-images":         "build01",
+			},
+		},
+		{
+			name: "excluded file keeps its current assignment",
+			config: &dispatcher.Config{
+				Default: "api.ci",
+				BuildFarm: map[api.Cloud]map[api.Cluster]*dispatcher.BuildFarmConfig{
+					api.CloudAWS: {api.ClusterBuild01: {Filenames: sets.New[string]("xyz-operator-presubmits.yaml")}},
+					api.CloudGCP: {api.ClusterBuild02: {}},
+				},
+				ExcludePatterns:   []string{".*xyz-operator-presubmits.yaml$"},
+				ExcludePatternREs: []*regexp.Regexp{regexp.MustCompile(".*xyz-operator-presubmits.yaml$")},
+			},
+			prowJobConfigDir: filepath.Join("testdata", t.Name()),
+			jobVolumes: map[string]float64{
+				"pull-ci-openshift-cluster-api-provider-gcp-master-e2e-gcp":          24,
+				"pull-ci-openshift-ci-tools-master-breaking-changes":                 43,
+				"pull-ci-openshift-ci-tools-master-e2e":                              12,
+				"pull-ci-openshift-cluster-etcd-operator-master-unit":                6,
+				"pull-ci-openshift-cluster-api-provider-gcp-master-e2e-gcp-operator": 3,
+				"branch-ci-wildfly-wildfly-operator-master-images":                   2,
+				"branch-ci-xyz-xyz-operator-master-images":                           1000,
+			},
+			distribution: map[string]float64{
+				"build01": 50,
+				"build02": 50,
+			},
+			clusterMap: dispatcher.ClusterMap{
+				"build01": dispatcher.ClusterInfo{Capacity: 100},
+				"build02": dispatcher.ClusterInfo{Capacity: 100},
+			},
+			existing: map[string]string{
+				"branch-ci-xyz-xyz-operator-master-images": "build01",
+			},
+			expectedBuildFarm: map[api.Cloud]map[api.Cluster]*dispatcher.BuildFarmConfig{
+				// the volume of the excluded file does not count towards build01
+				"aws": {"build01": {FilenamesRaw: []string{"cluster-etcd-operator-master-presubmits.yaml", "cluster-api-provider-gcp-presubmits.yaml", "ci-tools-presubmits.yaml", "xyz-operator-presubmits.yaml"}}},
+				"gcp": {"build02": {FilenamesRaw: []string{"wildfly-operator-presubmits.yaml"}}},
+			},
+			expectedJobs: map[string]string{
+				"branch-ci-xyz-xyz-operator-master-images": "build01",
+			},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			_, actual := dispatchJobs(tc.prowJobConfigDir, tc.config, tc.jobVolumes, sets.New[string](), tc.distribution, tc.clusterMap)
+			pjs, _, actual := dispatchJobs(tc.prowJobConfigDir, tc.config, tc.jobVolumes, sets.New[string](), tc.drained, tc.existing, tc.distribution, tc.clusterMap, 0)
 			equalError(t, tc.expected, actual)
+			for job, cluster := range tc.expectedJobs {
+				if pjs[job] != cluster {
+					t.Errorf("expected job %s to be dispatched to %s, got %s", job, cluster, pjs[job])
+				}
+			}
 			if tc.config != nil && !reflect.DeepEqual(tc.expectedBuildFarm, tc.config.BuildFarm) {
 				t.Errorf("%s: actual differs from expected:\n%s", t.Name(), cmp.Diff(tc.expectedBuildFarm, tc.config.BuildFarm))
 			}
@@ -243,6 +328,78 @@ func TestDispatchJobConfig(t *testing.T) {
 			},
 			expected: "build02",
 		},
+		{
+			name: "cluster with reduced capacity is still considered",
+			cv: &clusterVolume{
+				clusterVolumeMap: map[string]map[string]float64{"aws": {"build01": 5}, "gcp": {"build02": 15}},
+				cloudProviders:   sets.New[string]("aws", "gcp"),
+				pjs:              map[string]string{},
+				volumeDistribution: map[string]float64{
+					"build01": 14,
+					"build02": 28,
+				},
+				clusterMap: dispatcher.ClusterMap{
+					"build01": dispatcher.ClusterInfo{Capacity: 50},
+					"build02": dispatcher.ClusterInfo{Capacity: 100},
+				},
+			},
+			config: &c,
+			jc: &prowconfig.JobConfig{
+				PresubmitsStatic: map[string][]prowconfig.Presubmit{
+					"repo": {{JobBase: prowconfig.JobBase{Name: "job"}}},
+				},
+			},
+			path:     "repo-presubmits.yaml",
+			expected: "build01",
+		},
+		{
+			name: "cluster with reduced capacity gets a proportional share",
+			cv: &clusterVolume{
+				clusterVolumeMap: map[string]map[string]float64{"aws": {"build01": 10}, "gcp": {"build02": 15}},
+				cloudProviders:   sets.New[string]("aws", "gcp"),
+				pjs:              map[string]string{},
+				volumeDistribution: map[string]float64{
+					"build01": 14,
+					"build02": 28,
+				},
+				clusterMap: dispatcher.ClusterMap{
+					"build01": dispatcher.ClusterInfo{Capacity: 50},
+					"build02": dispatcher.ClusterInfo{Capacity: 100},
+				},
+			},
+			config: &c,
+			jc: &prowconfig.JobConfig{
+				PresubmitsStatic: map[string][]prowconfig.Presubmit{
+					"repo": {{JobBase: prowconfig.JobBase{Name: "job"}}},
+				},
+			},
+			path:     "repo-presubmits.yaml",
+			expected: "build02",
+		},
+		{
+			name: "cluster without capacity is skipped",
+			cv: &clusterVolume{
+				clusterVolumeMap: map[string]map[string]float64{"aws": {"build01": 0}, "gcp": {"build02": 15}},
+				cloudProviders:   sets.New[string]("aws", "gcp"),
+				pjs:              map[string]string{},
+				volumeDistribution: map[string]float64{
+					"build01": 0,
+					"build02": 42,
+				},
+				clusterMap: dispatcher.ClusterMap{
+					"build01": dispatcher.ClusterInfo{Capacity: 0},
+					"build02": dispatcher.ClusterInfo{Capacity: 100},
+				},
+			},
+			config: &c,
+			jc: &prowconfig.JobConfig{
+				PresubmitsStatic: map[string][]prowconfig.Presubmit{
+					"repo": {{JobBase: prowconfig.JobBase{Name: "job"}}},
+				},
+			},
+			path:     "repo-presubmits.yaml",
+			expected: "build02",
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -257,6 +414,213 @@ func TestDispatchJobConfig(t *testing.T) {
 	}
 }
 
+func TestDispatchJobConfigBreaksTiesByClusterName(t *testing.T) {
+	clusterVolumeMap := func() map[string]map[string]float64 {
+		return map[string]map[string]float64{"aws": {"build05": 10, "build03": 10, "build09": 10}, "gcp": {"build02": 10, "build04": 10}}
+	}
+	jc := &prowconfig.JobConfig{
+		PresubmitsStatic: map[string][]prowconfig.Presubmit{
+			"repo": {{JobBase: prowconfig.JobBase{Name: "job"}}},
+		},
+	}
+	clusterMap := dispatcher.ClusterMap{}
+	for _, clusters := range clusterVolumeMap() {
+		for cluster := range clusters {
+			clusterMap[cluster] = dispatcher.ClusterInfo{Capacity: 100}
+		}
+	}
+	// map iteration order is randomized, so repeated runs would pick different clusters without a stable tie-break
+	for i := 0; i < 20; i++ {
+		cv := &clusterVolume{
+			clusterVolumeMap: clusterVolumeMap(),
+			cloudProviders:   sets.New[string]("aws", "gcp"),
+			pjs:              map[string]string{},
+			clusterMap:       clusterMap,
+		}
+		actual, err := cv.dispatchJobConfig(jc, "repo-presubmits.yaml", &c, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff("build02", actual); diff != "" {
+			t.Fatalf("run %d: actual does not match expected, diff: %s", i, diff)
+		}
+		if diff := cmp.Diff(map[string]string{"job": "build02"}, cv.pjs); diff != "" {
+			t.Fatalf("run %d: dispatched jobs do not match expected, diff: %s", i, diff)
+		}
+	}
+}
+
+func TestAddToVolume(t *testing.T) {
+	testCases := []struct {
+		name                string
+		cluster             string
+		jobBase             prowconfig.JobBase
+		blocked             sets.Set[string]
+		expectedCluster     string
+		expectedVolumes     map[string]map[string]float64
+		expectedExplanation dispatcher.Explanation
+	}{
+		{
+			name:            "job goes to the chosen cluster",
+			cluster:         "build01",
+			jobBase:         prowconfig.JobBase{Agent: "kubernetes", Name: "job"},
+			expectedCluster: "build01",
+			expectedVolumes: map[string]map[string]float64{"aws": {"build01": 10}, "gcp": {"build02": 0}},
+			expectedExplanation: dispatcher.Explanation{
+				Cluster:    "build01",
+				Reason:     dispatcher.ReasonVolumeMin,
+				Candidates: []string{"build01", "build02"},
+			},
+		},
+		{
+			name:            "pinned job goes to the pinned cluster and counts toward its volume",
+			cluster:         "build01",
+			jobBase:         prowconfig.JobBase{Agent: "kubernetes", Name: "job", Labels: map[string]string{api.PinClusterLabel: "build02"}},
+			expectedCluster: "build02",
+			expectedVolumes: map[string]map[string]float64{"aws": {"build01": 0}, "gcp": {"build02": 10}},
+			expectedExplanation: dispatcher.Explanation{
+				Cluster:    "build02",
+				Reason:     dispatcher.ReasonPinned,
+				Candidates: []string{"build02"},
+			},
+		},
+		{
+			name:            "job pinned to a blocked cluster goes to the chosen cluster",
+			cluster:         "build01",
+			jobBase:         prowconfig.JobBase{Agent: "kubernetes", Name: "job", Labels: map[string]string{api.PinClusterLabel: "build02"}},
+			blocked:         sets.New[string]("build02"),
+			expectedCluster: "build01",
+			expectedVolumes: map[string]map[string]float64{"aws": {"build01": 10}, "gcp": {"build02": 0}},
+			expectedExplanation: dispatcher.Explanation{
+				Cluster:    "build01",
+				Reason:     dispatcher.ReasonVolumeMin,
+				Candidates: []string{"build01", "build02"},
+			},
+		},
+		{
+			name:            "job whose chosen cluster is blocked goes to the default cluster",
+			cluster:         "build01",
+			jobBase:         prowconfig.JobBase{Agent: "kubernetes", Name: "job", Labels: map[string]string{"capability/arm64": "arm64"}},
+			blocked:         sets.New[string]("build01"),
+			expectedCluster: "api.ci",
+			expectedVolumes: map[string]map[string]float64{"aws": {"build01": 0}, "gcp": {"build02": 0}},
+			expectedExplanation: dispatcher.Explanation{
+				Cluster:      "api.ci",
+				Reason:       dispatcher.ReasonBlocked,
+				Capabilities: []string{"arm64"},
+				Candidates:   []string{"build01", "build02"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cv := &clusterVolume{
+				clusterVolumeMap: map[string]map[string]float64{"aws": {"build01": 0}, "gcp": {"build02": 0}},
+				specialClusters:  map[string]float64{},
+				pjs:              map[string]string{},
+				blocked:          tc.blocked,
+				explanations:     map[string]dispatcher.Explanation{},
+				clusterMap:       dispatcher.ClusterMap{"build01": {Capabilities: []string{"arm64"}}},
+			}
+			if err := cv.addToVolume(tc.cluster, dispatcher.ReasonVolumeMin, []string{"build01", "build02"}, tc.jobBase, "org/repo/org-repo-master-presubmits.yaml", &c, map[string]float64{"job": 10}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedCluster, cv.pjs[tc.jobBase.Name]); diff != "" {
+				t.Errorf("cluster differs from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedVolumes, cv.clusterVolumeMap); diff != "" {
+				t.Errorf("volumes differ from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedExplanation, cv.explanations[tc.jobBase.Name]); diff != "" {
+				t.Errorf("explanation differs from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDispatchJobConfigCapsClusterShare(t *testing.T) {
+	config := dispatcher.Config{
+		Default: "api.ci",
+		BuildFarm: map[api.Cloud]map[api.Cluster]*dispatcher.BuildFarmConfig{
+			api.CloudAWS: {api.ClusterBuild01: {}, api.ClusterBuild03: {}},
+			api.CloudGCP: {api.ClusterBuild02: {}},
+		},
+	}
+	awsJob := func(name string) prowconfig.Presubmit {
+		return prowconfig.Presubmit{JobBase: prowconfig.JobBase{Name: name,
+			Spec: &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Env: []corev1.EnvVar{{Name: "CLUSTER_TYPE", Value: "aws"}}},
+				},
+			}}}
+	}
+	jc := &prowconfig.JobConfig{
+		PresubmitsStatic: map[string][]prowconfig.Presubmit{
+			"repo": {awsJob("job1"), awsJob("job2"), awsJob("job3")},
+		},
+	}
+	testCases := []struct {
+		name                 string
+		volumeDistribution   map[string]float64
+		expectedCluster      string
+		expected             map[string]string
+		expectedExplanations map[string]dispatcher.Explanation
+	}{
+		{
+			name:               "file goes to the next least loaded cluster of the cloud provider when it would exceed the share of the chosen one",
+			volumeDistribution: map[string]float64{"build01": 15, "build02": 100, "build03": 30},
+			expectedCluster:    "build03",
+			expected:           map[string]string{"job1": "build03", "job2": "build03", "job3": "build03"},
+			expectedExplanations: map[string]dispatcher.Explanation{
+				"job1": {Cluster: "build03", Reason: dispatcher.ReasonVolumeCap, Candidates: []string{"build01", "build03"}},
+				"job2": {Cluster: "build03", Reason: dispatcher.ReasonVolumeCap, Candidates: []string{"build01", "build03"}},
+				"job3": {Cluster: "build03", Reason: dispatcher.ReasonVolumeCap, Candidates: []string{"build01", "build03"}},
+			},
+		},
+		{
+			name:               "share is exceeded when no cluster of the cloud provider is below its share",
+			volumeDistribution: map[string]float64{"build01": 15, "build02": 100, "build03": 5},
+			expectedCluster:    "build01",
+			expected:           map[string]string{"job1": "build01", "job2": "build01", "job3": "build01"},
+			expectedExplanations: map[string]dispatcher.Explanation{
+				"job1": {Cluster: "build01", Reason: dispatcher.ReasonVolumeMin, Candidates: []string{"build01", "build03"}},
+				"job2": {Cluster: "build01", Reason: dispatcher.ReasonVolumeMin, Candidates: []string{"build01", "build03"}},
+				"job3": {Cluster: "build01", Reason: dispatcher.ReasonVolumeMin, Candidates: []string{"build01", "build03"}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cv := &clusterVolume{
+				clusterVolumeMap:   map[string]map[string]float64{"aws": {"build01": 0, "build03": 0}, "gcp": {"build02": 0}},
+				specialClusters:    map[string]float64{},
+				cloudProviders:     sets.New[string]("aws", "gcp"),
+				pjs:                map[string]string{},
+				explanations:       map[string]dispatcher.Explanation{},
+				volumeDistribution: tc.volumeDistribution,
+				clusterMap: dispatcher.ClusterMap{
+					"build01": {Capacity: 100},
+					"build02": {Capacity: 100},
+					"build03": {Capacity: 100},
+				},
+			}
+			cluster, err := cv.dispatchJobConfig(jc, "repo-presubmits.yaml", &config, map[string]float64{"job1": 10, "job2": 10, "job3": 10})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cluster != tc.expectedCluster {
+				t.Errorf("expected the file to be assigned to %s, got %s", tc.expectedCluster, cluster)
+			}
+			if diff := cmp.Diff(tc.expected, cv.pjs); diff != "" {
+				t.Errorf("dispatched jobs differ from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedExplanations, cv.explanations); diff != "" {
+				t.Errorf("explanations differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestGetCloudProvidersForE2ETests(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -470,6 +834,59 @@ func TestDispatchMissingJobs(t *testing.T) {
 	}
 }
 
+func TestValidateDispatch(t *testing.T) {
+	testCases := []struct {
+		name           string
+		blocked        sets.Set[string]
+		drained        sets.Set[string]
+		expectedOutput string
+		expectedErr    error
+	}{
+		{
+			name:           "valid",
+			blocked:        sets.New[string](),
+			expectedOutput: "build01: 3\n",
+		},
+		{
+			name:           "drained cluster",
+			blocked:        sets.New[string](),
+			drained:        sets.New[string]("build01"),
+			expectedOutput: "build02: 3\n",
+		},
+		{
+			name:           "pinned to blocked cluster",
+			blocked:        sets.New[string]("build03"),
+			expectedOutput: "build01: 1\n",
+			expectedErr:    fmt.Errorf(`job pull-ci-org-repo-master-unit in "testdata/TestValidateDispatch/pinned_to_blocked_cluster/org-repo-presubmits.yaml" is pinned to the blocked cluster build03`),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &dispatcher.Config{
+				Default: "api.ci",
+				BuildFarm: map[api.Cloud]map[api.Cluster]*dispatcher.BuildFarmConfig{
+					api.CloudAWS: {api.ClusterBuild01: {}},
+				},
+				BuildFarmCloud: map[api.Cloud][]string{
+					api.CloudAWS: {string(api.ClusterBuild01)},
+				},
+			}
+			cm := dispatcher.ClusterMap{
+				"build01": dispatcher.ClusterInfo{Provider: string(api.CloudAWS), Capacity: 100},
+				"build02": dispatcher.ClusterInfo{Provider: string(api.CloudGCP), Capacity: 100},
+			}
+			out := &bytes.Buffer{}
+			err := validateDispatch(filepath.Join("testdata", t.Name()), config, cm, tc.blocked, tc.drained, out)
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("error differs from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedOutput, out.String()); diff != "" {
+				t.Errorf("output differs from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
 type fakeSlackClient struct {
 }
 
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var (
+	clusterVolumeGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "prow_job_dispatcher_cluster_volume",
+			Help: "Job volume dispatched to a cluster in the build farm during the last dispatch.",
+		},
+		[]string{"cloud_provider", "cluster"},
+	)
+
+	specialClusterVolumeGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "prow_job_dispatcher_special_cluster_volume",
+			Help: "Job volume dispatched to a cluster outside of the build farm during the last dispatch.",
+		},
+		[]string{"cluster"},
+	)
+
+	specialClusterOverThresholdGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "prow_job_dispatcher_special_cluster_volume_over_threshold",
+			Help: "Whether the job volume dispatched to a cluster outside of the build farm during the last dispatch exceeded --special-cluster-volume-threshold.",
+		},
+		[]string{"cluster"},
+	)
+
+	blockedClustersGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "prow_job_dispatcher_blocked_clusters",
+			Help: "Number of clusters blocked in the cluster config.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(clusterVolumeGauge, specialClusterVolumeGauge, specialClusterOverThresholdGauge, blockedClustersGauge)
+}
+
+// recordMetrics replaces the exposed volumes with the ones from the given dispatch
+func (cv *clusterVolume) recordMetrics() {
+	clusterVolumeGauge.Reset()
+	for cloudProvider, m := range cv.clusterVolumeMap {
+		for cluster, volume := range m {
+			clusterVolumeGauge.WithLabelValues(cloudProvider, cluster).Set(volume)
+		}
+	}
+	specialClusterVolumeGauge.Reset()
+	for cluster, volume := range cv.specialClusters {
+		specialClusterVolumeGauge.WithLabelValues(cluster).Set(volume)
+	}
+	specialClusterOverThresholdGauge.Reset()
+	if cv.specialClusterVolumeThreshold > 0 {
+		overThreshold := sets.New[string](cv.specialClustersOverThreshold()...)
+		for cluster := range cv.specialClusters {
+			var value float64
+			if overThreshold.Has(cluster) {
+				value = 1
+			}
+			specialClusterOverThresholdGauge.WithLabelValues(cluster).Set(value)
+		}
+	}
+}
+
+func recordBlockedClusters(blocked sets.Set[string]) {
+	blockedClustersGauge.Set(float64(blocked.Len()))
+}
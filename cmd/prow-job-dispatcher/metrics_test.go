@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func gaugeValues(t *testing.T, collector prometheus.Collector) map[string]float64 {
+	t.Helper()
+	ch := make(chan prometheus.Metric, 10)
+	collector.Collect(ch)
+	close(ch)
+	values := map[string]float64{}
+	for metric := range ch {
+		m := &dto.Metric{}
+		if err := metric.Write(m); err != nil {
+			t.Fatalf("failed to write metric: %v", err)
+		}
+		var key string
+		for _, label := range m.GetLabel() {
+			key += label.GetName() + "=" + label.GetValue() + ","
+		}
+		values[key] = m.GetGauge().GetValue()
+	}
+	return values
+}
+
+func TestRecordMetrics(t *testing.T) {
+	clusterVolumeGauge.WithLabelValues("gcp", "removed").Set(1)
+	cv := &clusterVolume{
+		clusterVolumeMap: map[string]map[string]float64{
+			"aws": {"build01": 10, "build03": 20},
+			"gcp": {"build02": 30},
+		},
+		specialClusters:               map[string]float64{"vsphere02": 5, "vsphere03": 20},
+		specialClusterVolumeThreshold: 10,
+	}
+	cv.recordMetrics()
+	recordBlockedClusters(sets.New[string]("build04", "build05"))
+
+	if diff := cmp.Diff(map[string]float64{
+		"cloud_provider=aws,cluster=build01,": 10,
+		"cloud_provider=aws,cluster=build03,": 20,
+		"cloud_provider=gcp,cluster=build02,": 30,
+	}, gaugeValues(t, clusterVolumeGauge)); diff != "" {
+		t.Errorf("cluster volumes differ from expected:\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]float64{"cluster=vsphere02,": 5, "cluster=vsphere03,": 20}, gaugeValues(t, specialClusterVolumeGauge)); diff != "" {
+		t.Errorf("special cluster volumes differ from expected:\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]float64{"cluster=vsphere02,": 0, "cluster=vsphere03,": 1}, gaugeValues(t, specialClusterOverThresholdGauge)); diff != "" {
+		t.Errorf("special clusters over threshold differ from expected:\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]float64{"": 2}, gaugeValues(t, blockedClustersGauge)); diff != "" {
+		t.Errorf("blocked clusters differ from expected:\n%s", diff)
+	}
+}
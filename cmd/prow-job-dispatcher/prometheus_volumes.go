@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -19,10 +22,24 @@ type prometheusVolumes struct {
 	timestamp            time.Time
 	promClient           promapi.Client
 	prometheusDaysBefore int
+	weightByDuration     bool
+	cachePath            string
+	cacheTTL             time.Duration
 	m                    sync.Mutex
 }
 
-func newPrometheusVolumes(promOptions dispatcher.PrometheusOptions, prometheusDaysBefore int) (prometheusVolumes, error) {
+// jobVolumesCache is the on-disk copy of the last job volumes successfully fetched from Prometheus
+type jobVolumesCache struct {
+	// Day is the day the job volumes were queried for, the cache ages by it
+	Day time.Time
+	// DaysBefore and WeightByDuration are the query options the job volumes were fetched with,
+	// volumes fetched with other options must not be used
+	DaysBefore       int
+	WeightByDuration bool
+	JobVolumes       map[string]float64
+}
+
+func newPrometheusVolumes(promOptions dispatcher.PrometheusOptions, prometheusDaysBefore int, weightByDuration bool, cachePath string, cacheTTL time.Duration) (prometheusVolumes, error) {
 	promClient, err := promOptions.NewPrometheusClient(secret.GetSecret)
 	if err != nil {
 		return prometheusVolumes{}, err
@@ -31,6 +48,9 @@ func newPrometheusVolumes(promOptions dispatcher.PrometheusOptions, prometheusDa
 		promClient:           promClient,
 		jobVolumes:           map[string]float64{},
 		prometheusDaysBefore: prometheusDaysBefore,
+		weightByDuration:     weightByDuration,
+		cachePath:            cachePath,
+		cacheTTL:             cacheTTL,
 		m:                    sync.Mutex{},
 	}, nil
 }
@@ -42,19 +62,87 @@ func (pv *prometheusVolumes) GetJobVolumes() (map[string]float64, error) {
 		logrus.Info("Using cached job volumes")
 		return pv.jobVolumes, nil
 	}
+	ts := pv.queryDay(time.Now())
+	jv, err := pv.fetchJobVolumes(ts)
+	if err != nil {
+		cache, cacheErr := pv.readCache(ts)
+		if cacheErr != nil {
+			logrus.WithError(cacheErr).Debug("Could not use the job volume cache")
+			return nil, err
+		}
+		logrus.WithError(err).WithField("day", cache.Day.Format(time.DateOnly)).Warn("Failed to fetch job volumes from Prometheus, using the cached job volumes")
+		// the timestamp is left alone so that Prometheus is queried again on the next dispatch
+		pv.jobVolumes = cache.JobVolumes
+		return pv.jobVolumes, nil
+	}
+	pv.jobVolumes = jv
+	pv.timestamp = time.Now()
+	logrus.Info("Fetched new job volumes")
+	if err := pv.writeCache(ts); err != nil {
+		logrus.WithError(err).Warn("Failed to write the job volume cache")
+	}
+	return pv.jobVolumes, nil
+}
+
+// queryDay returns the day whose job volumes are queried from Prometheus at the given time
+func (pv *prometheusVolumes) queryDay(now time.Time) time.Time {
+	y, m, d := now.Add(-time.Duration(24*pv.prometheusDaysBefore) * time.Hour).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func (pv *prometheusVolumes) fetchJobVolumes(ts time.Time) (map[string]float64, error) {
 	v1api := prometheusapi.NewAPI(pv.promClient)
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	y, m, d := time.Now().Add(-time.Duration(24*pv.prometheusDaysBefore) * time.Hour).Date()
-	ts := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
 	jv, err := dispatcher.GetJobVolumesFromPrometheus(ctx, v1api, ts)
 	if err != nil {
 		return nil, err
 	}
-	pv.jobVolumes = jv
-	pv.timestamp = time.Now()
-	logrus.Info("Fetched new job volumes")
-	return pv.jobVolumes, nil
+	if pv.weightByDuration {
+		durations, err := dispatcher.GetJobDurationsFromPrometheus(ctx, v1api, ts)
+		if err != nil {
+			return nil, err
+		}
+		jv = dispatcher.WeightJobVolumesByDuration(jv, durations)
+	}
+	return jv, nil
+}
+
+func (pv *prometheusVolumes) writeCache(day time.Time) error {
+	if pv.cachePath == "" {
+		return nil
+	}
+	return dispatcher.WriteGob(pv.cachePath, jobVolumesCache{
+		Day:              day,
+		DaysBefore:       pv.prometheusDaysBefore,
+		WeightByDuration: pv.weightByDuration,
+		JobVolumes:       pv.jobVolumes,
+	})
+}
+
+// readCache returns the cached job volumes if they can be used in place of the ones for the given day
+func (pv *prometheusVolumes) readCache(day time.Time) (jobVolumesCache, error) {
+	var cache jobVolumesCache
+	if pv.cachePath == "" {
+		return cache, errors.New("no cache configured")
+	}
+	if err := dispatcher.ReadGob(pv.cachePath, &cache); err != nil {
+		return cache, fmt.Errorf("failed to read cache: %w", err)
+	}
+	if cache.DaysBefore != pv.prometheusDaysBefore || cache.WeightByDuration != pv.weightByDuration {
+		if err := os.Remove(pv.cachePath); err != nil {
+			logrus.WithError(err).Warn("Failed to remove the outdated job volume cache")
+		}
+		return cache, fmt.Errorf("cached job volumes were queried %d days before with weighting by duration %t, not %d days before with weighting by duration %t", cache.DaysBefore, cache.WeightByDuration, pv.prometheusDaysBefore, pv.weightByDuration)
+	}
+	// the age is counted in days queried rather than from the time of the fetch, so the cache expires at a day boundary
+	if age := day.Sub(cache.Day); age > pv.cacheTTL {
+		return cache, fmt.Errorf("cached job volumes for %s are %s older than the ones for %s, more than the TTL of %s", cache.Day.Format(time.DateOnly), age, day.Format(time.DateOnly), pv.cacheTTL)
+	}
+	if len(cache.JobVolumes) == 0 {
+		return cache, errors.New("cache holds no job volumes")
+	}
+	return cache, nil
 }
 
 func (pv *prometheusVolumes) getTotalVolume() float64 {
@@ -69,12 +157,17 @@ func (pv *prometheusVolumes) getTotalVolume() float64 {
 func (pv *prometheusVolumes) calculateVolumeDistribution(clusterMap dispatcher.ClusterMap) map[string]float64 {
 	totalCapacity := 0
 	for _, cluster := range clusterMap {
-		totalCapacity += cluster.Capacity
+		if cluster.Capacity > 0 {
+			totalCapacity += cluster.Capacity
+		}
 	}
 	totalVolume := pv.getTotalVolume()
 	volumeDistribution := make(map[string]float64)
 	for clusterName, cluster := range clusterMap {
-		volumeShare := (float64(cluster.Capacity) / float64(totalCapacity)) * totalVolume
+		var volumeShare float64
+		if cluster.Capacity > 0 {
+			volumeShare = (float64(cluster.Capacity) / float64(totalCapacity)) * totalVolume
+		}
 		volumeDistribution[clusterName] = volumeShare
 	}
 
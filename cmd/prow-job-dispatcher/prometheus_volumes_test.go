@@ -4,6 +4,8 @@ import (
 	"context"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
 	"sync"
 	"testing"
@@ -26,16 +28,20 @@ func (fc *FakeClient) Do(ctx context.Context, req *http.Request) (*http.Response
 }
 
 func TestPrometheusVolumesGetJobVolumes(t *testing.T) {
+	queryDay := (&prometheusVolumes{prometheusDaysBefore: 15}).queryDay(time.Now())
 	type fields struct {
 		jobVolumes map[string]float64
 		timestamp  time.Time
 		promClient promapi.Client
+		cache      *jobVolumesCache
 	}
 	tests := []struct {
 		name    string
 		fields  fields
 		want    map[string]float64
 		wantErr bool
+		// wantCacheRemoved is set when the cache on disk is expected to be invalidated
+		wantCacheRemoved bool
 	}{
 		{
 			name: "acquire volumes from cache",
@@ -57,6 +63,79 @@ func TestPrometheusVolumesGetJobVolumes(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "Prometheus fails, fall back to the cache on disk",
+			fields: fields{
+				timestamp:  time.Now().Add(-25 * time.Hour),
+				promClient: &FakeClient{},
+				cache: &jobVolumesCache{
+					Day:        queryDay.AddDate(0, 0, -2),
+					DaysBefore: 15,
+					JobVolumes: map[string]float64{"job1": 3.0},
+				},
+			},
+			want:    map[string]float64{"job1": 3.0},
+			wantErr: false,
+		},
+		{
+			name: "Prometheus fails, cache on disk was queried for another window",
+			fields: fields{
+				timestamp:  time.Now().Add(-25 * time.Hour),
+				promClient: &FakeClient{},
+				cache: &jobVolumesCache{
+					Day:        queryDay.AddDate(0, 0, -2),
+					DaysBefore: 7,
+					JobVolumes: map[string]float64{"job1": 3.0},
+				},
+			},
+			want:             nil,
+			wantErr:          true,
+			wantCacheRemoved: true,
+		},
+		{
+			name: "Prometheus fails, cache on disk was weighted by duration",
+			fields: fields{
+				timestamp:  time.Now().Add(-25 * time.Hour),
+				promClient: &FakeClient{},
+				cache: &jobVolumesCache{
+					Day:              queryDay.AddDate(0, 0, -2),
+					DaysBefore:       15,
+					WeightByDuration: true,
+					JobVolumes:       map[string]float64{"job1": 3.0},
+				},
+			},
+			want:             nil,
+			wantErr:          true,
+			wantCacheRemoved: true,
+		},
+		{
+			name: "Prometheus fails, cache on disk was queried for the last day within the TTL",
+			fields: fields{
+				timestamp:  time.Now().Add(-25 * time.Hour),
+				promClient: &FakeClient{},
+				cache: &jobVolumesCache{
+					Day:        queryDay.AddDate(0, 0, -3),
+					DaysBefore: 15,
+					JobVolumes: map[string]float64{"job1": 3.0},
+				},
+			},
+			want:    map[string]float64{"job1": 3.0},
+			wantErr: false,
+		},
+		{
+			name: "Prometheus fails, cache on disk is expired",
+			fields: fields{
+				timestamp:  time.Now().Add(-25 * time.Hour),
+				promClient: &FakeClient{},
+				cache: &jobVolumesCache{
+					Day:        queryDay.AddDate(0, 0, -4),
+					DaysBefore: 15,
+					JobVolumes: map[string]float64{"job1": 3.0},
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -65,8 +144,15 @@ func TestPrometheusVolumesGetJobVolumes(t *testing.T) {
 				timestamp:            tt.fields.timestamp,
 				promClient:           tt.fields.promClient,
 				prometheusDaysBefore: 15,
+				cacheTTL:             72 * time.Hour,
 				m:                    sync.Mutex{},
 			}
+			if tt.fields.cache != nil {
+				pv.cachePath = filepath.Join(t.TempDir(), "volumes.gob")
+				if err := dispatcher.WriteGob(pv.cachePath, tt.fields.cache); err != nil {
+					t.Fatalf("failed to write cache: %v", err)
+				}
+			}
 			got, err := pv.GetJobVolumes()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("prometheusVolumes.GetJobVolumes() error = %v, wantErr %v", err, tt.wantErr)
@@ -75,6 +161,12 @@ func TestPrometheusVolumesGetJobVolumes(t *testing.T) {
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("prometheusVolumes.GetJobVolumes() = %v, want %v", got, tt.want)
 			}
+			if tt.fields.cache != nil {
+				_, statErr := os.Stat(pv.cachePath)
+				if removed := os.IsNotExist(statErr); removed != tt.wantCacheRemoved {
+					t.Errorf("cache removed: %t, expected %t", removed, tt.wantCacheRemoved)
+				}
+			}
 		})
 	}
 }
@@ -144,6 +236,16 @@ func TestCalculateVolumeDistribution(t *testing.T) {
 				"clusterB": 1000,
 			},
 		},
+		{
+			name:       "no cluster with capacity",
+			jobVolumes: map[string]float64{"jobA": 1000},
+			clusterMap: dispatcher.ClusterMap{
+				"clusterA": {Provider: "AWS", Capacity: 0},
+			},
+			expected: map[string]float64{
+				"clusterA": 0,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
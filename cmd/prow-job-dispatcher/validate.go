@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	prowconfig "sigs.k8s.io/prow/pkg/config"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/dispatcher"
+)
+
+// validateDispatch runs both the full and the delta dispatch in memory and reports
+// every job that cannot be assigned to a usable cluster. Job volumes are not needed
+// to validate the assignments, so every job is given the same weight.
+// Drained clusters are handled like in a dispatch without existing assignments.
+// A summary of the assigned jobs per cluster is written to out.
+func validateDispatch(prowJobConfigDir string, config *dispatcher.Config, cm dispatcher.ClusterMap, blocked, drained sets.Set[string], out io.Writer) error {
+	enabled, disabled := getDiffClusters(getEnabledClusters(config), clustersMapToSet(cm))
+	if len(disabled) > 0 {
+		removeDisabledClusters(config, disabled)
+	}
+	addEnabledClusters(config, enabled, func(cluster string) (api.Cloud, error) {
+		return api.Cloud(cm[cluster].Provider), nil
+	})
+
+	var errs []error
+	pinnedToBlocked := func(jobConfig *prowconfig.JobConfig, path string, _ fs.DirEntry) {
+		check := func(jobBase prowconfig.JobBase) {
+			if cluster := dispatcher.PinnedCluster(jobBase); cluster != "" && blocked.Has(cluster) {
+				errs = append(errs, fmt.Errorf("job %s in %q is pinned to the blocked cluster %s", jobBase.Name, path, cluster))
+			}
+		}
+		for k := range jobConfig.PresubmitsStatic {
+			for _, job := range jobConfig.PresubmitsStatic[k] {
+				check(job.JobBase)
+			}
+		}
+		for k := range jobConfig.PostsubmitsStatic {
+			for _, job := range jobConfig.PostsubmitsStatic[k] {
+				check(job.JobBase)
+			}
+		}
+		for _, job := range jobConfig.Periodics {
+			check(job.JobBase)
+		}
+	}
+	fileList, err := composeFileInfoList(prowJobConfigDir)
+	if err != nil {
+		return fmt.Errorf("failed to list Prow job config files: %w", err)
+	}
+	if err := dispatchEveryFile(fileList, pinnedToBlocked); err != nil {
+		errs = append(errs, err)
+	}
+
+	pjs, _, err := dispatchJobs(prowJobConfigDir, config, map[string]float64{}, blocked, drained, nil, map[string]float64{}, cm, 0)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to dispatch jobs: %w", err))
+	}
+	missing := map[string]string{}
+	if err := dispatchMissingJobs(prowJobConfigDir, config, blocked.Union(drained), missing, cm); err != nil {
+		errs = append(errs, fmt.Errorf("failed to dispatch missing jobs: %w", err))
+	}
+
+	// both dispatches usually agree, report every problem only once
+	problems := sets.New[string]()
+	for _, assignments := range []map[string]string{pjs, missing} {
+		for _, job := range sets.List(sets.KeySet(assignments)) {
+			switch cluster := assignments[job]; {
+			case cluster == "":
+				problems.Insert(fmt.Sprintf("job %s could not be assigned to any cluster", job))
+			case blocked.Has(cluster):
+				problems.Insert(fmt.Sprintf("job %s is assigned to the blocked cluster %s", job, cluster))
+			}
+		}
+	}
+	for _, problem := range sets.List(problems) {
+		errs = append(errs, errors.New(problem))
+	}
+
+	jobsByCluster := map[string]int{}
+	for _, cluster := range pjs {
+		jobsByCluster[cluster]++
+	}
+	for _, cluster := range sets.List(sets.KeySet(jobsByCluster)) {
+		fmt.Fprintf(out, "%s: %d\n", cluster, jobsByCluster[cluster])
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
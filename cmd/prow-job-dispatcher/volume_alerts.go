@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// volumeShareAlerter warns about build farm clusters whose share of the dispatched
+// job volume is above maxShare or below minShare. A cluster is alerted on at most
+// once per interval, as the dispatch is re-run whenever the cluster config changes.
+type volumeShareAlerter struct {
+	maxShare float64
+	minShare float64
+	interval time.Duration
+
+	lastAlerted map[string]time.Time
+}
+
+func newVolumeShareAlerter(maxShare, minShare float64, interval time.Duration) *volumeShareAlerter {
+	return &volumeShareAlerter{
+		maxShare:    maxShare,
+		minShare:    minShare,
+		interval:    interval,
+		lastAlerted: map[string]time.Time{},
+	}
+}
+
+func (a *volumeShareAlerter) enabled() bool {
+	return a.maxShare > 0 || a.minShare > 0
+}
+
+// volumeShares determines the share of the total job volume dispatched to each of the clusters.
+// Excluded clusters, i.e. blocked and drained ones, are expected to get no new jobs: they have no
+// share and the volume of the jobs they still run does not count towards the total.
+func volumeShares(pjs map[string]string, jobVolumes map[string]float64, clusters, excluded sets.Set[string]) map[string]float64 {
+	shares := map[string]float64{}
+	for cluster := range clusters.Difference(excluded) {
+		shares[cluster] = 0
+	}
+	var total float64
+	for job, cluster := range pjs {
+		if excluded.Has(cluster) {
+			continue
+		}
+		total += jobVolumes[job]
+		if clusters.Has(cluster) {
+			shares[cluster] += jobVolumes[job]
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+	for cluster := range shares {
+		shares[cluster] /= total
+	}
+	return shares
+}
+
+// alerts returns a warning for every cluster whose share crosses a threshold and
+// that was not alerted on within the interval, keyed by the cluster
+func (a *volumeShareAlerter) alerts(shares map[string]float64, now time.Time) map[string]string {
+	alerts := map[string]string{}
+	for _, cluster := range sets.List(sets.KeySet(shares)) {
+		share := shares[cluster]
+		var alert string
+		switch {
+		case a.maxShare > 0 && share > a.maxShare:
+			alert = fmt.Sprintf("*%s* receives *%.1f%%* of the job volume, above the threshold of %.1f%%.", cluster, share*100, a.maxShare*100)
+		case a.minShare > 0 && share < a.minShare:
+			alert = fmt.Sprintf("*%s* receives *%.1f%%* of the job volume, below the threshold of %.1f%%.", cluster, share*100, a.minShare*100)
+		default:
+			continue
+		}
+		if last, alerted := a.lastAlerted[cluster]; alerted && now.Sub(last) < a.interval {
+			logrus.WithField("cluster", cluster).WithField("share", share).Debug("Not alerting on the volume share again within the interval")
+			continue
+		}
+		alerts[cluster] = alert
+	}
+	return alerts
+}
+
+func (a *volumeShareAlerter) alert(slackClient slackClient, channelId string, shares map[string]float64, now time.Time) error {
+	alerts := a.alerts(shares, now)
+	if len(alerts) == 0 {
+		return nil
+	}
+	var lines []string
+	for _, cluster := range sets.List(sets.KeySet(alerts)) {
+		lines = append(lines, alerts[cluster])
+	}
+	blockMessage := slack.MsgOptionBlocks(
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Unbalanced job volume*\n\n%s", strings.Join(lines, "\n")), false, false),
+			nil,
+			nil,
+		),
+	)
+	if _, _, err := slackClient.PostMessage(channelId, blockMessage); err != nil {
+		return fmt.Errorf("failed to post the volume share alert: %w", err)
+	}
+	for cluster := range alerts {
+		a.lastAlerted[cluster] = now
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestVolumeShares(t *testing.T) {
+	testCases := []struct {
+		name       string
+		pjs        map[string]string
+		jobVolumes map[string]float64
+		clusters   sets.Set[string]
+		excluded   sets.Set[string]
+		expected   map[string]float64
+	}{
+		{
+			name:       "shares of the build farm clusters",
+			pjs:        map[string]string{"a": "build01", "b": "build01", "c": "build02", "d": "special"},
+			jobVolumes: map[string]float64{"a": 2, "b": 3, "c": 4, "d": 1},
+			clusters:   sets.New[string]("build01", "build02", "build03"),
+			expected:   map[string]float64{"build01": 0.5, "build02": 0.4, "build03": 0},
+		},
+		{
+			name:       "blocked and drained clusters are excluded",
+			pjs:        map[string]string{"a": "build01", "b": "build02", "c": "build03", "d": "build04"},
+			jobVolumes: map[string]float64{"a": 3, "b": 1, "c": 4, "d": 2},
+			clusters:   sets.New[string]("build01", "build02", "build03", "build04"),
+			excluded:   sets.New[string]("build03", "build04"),
+			expected:   map[string]float64{"build01": 0.75, "build02": 0.25},
+		},
+		{
+			name:     "no volume",
+			pjs:      map[string]string{"a": "build01"},
+			clusters: sets.New[string]("build01"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, volumeShares(tc.pjs, tc.jobVolumes, tc.clusters, tc.excluded)); diff != "" {
+				t.Errorf("shares differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestVolumeShareAlerterAlerts(t *testing.T) {
+	now := time.Date(2024, time.March, 4, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name        string
+		maxShare    float64
+		minShare    float64
+		lastAlerted map[string]time.Time
+		shares      map[string]float64
+		expected    map[string]string
+	}{
+		{
+			name:     "no cluster crosses a threshold",
+			maxShare: 0.4,
+			minShare: 0.1,
+			shares:   map[string]float64{"build01": 0.35, "build02": 0.35, "build03": 0.3},
+			expected: map[string]string{},
+		},
+		{
+			name:     "clusters above and below the thresholds",
+			maxShare: 0.4,
+			minShare: 0.1,
+			shares:   map[string]float64{"build01": 0.55, "build02": 0.4, "build03": 0.05},
+			expected: map[string]string{
+				"build01": "*build01* receives *55.0%* of the job volume, above the threshold of 40.0%.",
+				"build03": "*build03* receives *5.0%* of the job volume, below the threshold of 10.0%.",
+			},
+		},
+		{
+			name:     "unset threshold is ignored",
+			maxShare: 0.4,
+			shares:   map[string]float64{"build01": 1, "build02": 0},
+			expected: map[string]string{
+				"build01": "*build01* receives *100.0%* of the job volume, above the threshold of 40.0%.",
+			},
+		},
+		{
+			name:        "cluster alerted within the interval is skipped",
+			maxShare:    0.4,
+			lastAlerted: map[string]time.Time{"build01": now.Add(-time.Hour), "build02": now.Add(-25 * time.Hour)},
+			shares:      map[string]float64{"build01": 0.5, "build02": 0.5},
+			expected: map[string]string{
+				"build02": "*build02* receives *50.0%* of the job volume, above the threshold of 40.0%.",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			alerter := newVolumeShareAlerter(tc.maxShare, tc.minShare, 24*time.Hour)
+			if tc.lastAlerted != nil {
+				alerter.lastAlerted = tc.lastAlerted
+			}
+			if diff := cmp.Diff(tc.expected, alerter.alerts(tc.shares, now)); diff != "" {
+				t.Errorf("alerts differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestVolumeShareAlerterAlert(t *testing.T) {
+	now := time.Date(2024, time.March, 4, 12, 0, 0, 0, time.UTC)
+	shares := map[string]float64{"build01": 0.5}
+
+	alerter := newVolumeShareAlerter(0.4, 0, 24*time.Hour)
+	if err := alerter.alert(&fakeSlackClient{}, "wrong-channelId", shares, now); err == nil {
+		t.Fatal("expected an error posting to the wrong channel")
+	}
+	if _, alerted := alerter.lastAlerted["build01"]; alerted {
+		t.Error("a failed alert must not be rate-limited")
+	}
+	if err := alerter.alert(&fakeSlackClient{}, "channelId", shares, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]time.Time{"build01": now}, alerter.lastAlerted); diff != "" {
+		t.Errorf("last alerted differs from expected:\n%s", diff)
+	}
+}
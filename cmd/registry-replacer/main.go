@@ -6,12 +6,15 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/semaphore"
 
@@ -42,6 +45,7 @@ type options struct {
 	githubUserName                               string
 	selfApprove                                  bool
 	ensureCorrectPromotionDockerfile             bool
+	verifyDockerfileExists                       bool
 	maxConcurrency                               int
 	ocpBuildDataRepoDir                          string
 	currentRelease                               ocpbuilddata.MajorMinor
@@ -51,17 +55,33 @@ type options struct {
 	applyReplacements                            bool
 	ensureCorrectPromotionDockerfileIngoredRepos *flagutil.Strings
 	registryPath                                 string
+	registryRegexesRaw                           flagutil.Strings
+	registryRegexes                              []*regexp.Regexp
+	dockerfileNames                              flagutil.Strings
+	multiArchImageStreams                        flagutil.Strings
+	printDiff                                    bool
+	skippedImages                                *flagutil.Strings
+	gitLabURL                                    string
+	gitLabRepos                                  flagutil.Strings
+	gitLabTokenPath                              string
+	orgTokenPathsRaw                             flagutil.Strings
+	orgTokenPaths                                map[string]string
+	onlyOrg                                      string
+	onlyRepo                                     string
+	reportPath                                   string
+	check                                        bool
 	flagutil.GitHubOptions
 }
 
 func gatherOptions() (*options, error) {
-	o := &options{ensureCorrectPromotionDockerfileIngoredRepos: &flagutil.Strings{}}
+	o := &options{ensureCorrectPromotionDockerfileIngoredRepos: &flagutil.Strings{}, skippedImages: &flagutil.Strings{}}
 	o.AddFlags(flag.CommandLine)
 	flag.StringVar(&o.configDir, "config-dir", "", "The directory with the ci-operator configs")
 	flag.BoolVar(&o.createPR, "create-pr", false, "If the tool should automatically create a PR. Requires --token-file")
 	flag.StringVar(&o.githubUserName, "github-user-name", "openshift-bot", "Name of the github user. Required when --create-pr is set. Does nothing otherwise")
 	flag.BoolVar(&o.selfApprove, "self-approve", false, "If the bot should self-approve its PR.")
 	flag.BoolVar(&o.ensureCorrectPromotionDockerfile, "ensure-correct-promotion-dockerfile", false, "If Dockerfiles used for promotion should get updated to match whats in the ocp-build-data repo")
+	flag.BoolVar(&o.verifyDockerfileExists, "verify-dockerfile-exists", false, "If set, only update a Dockerfile used for promotion to match the ocp-build-data repo if the new Dockerfile exists in the repo. Requires --ensure-correct-promotion-dockerfile")
 	flag.Var(o.ensureCorrectPromotionDockerfileIngoredRepos, "ensure-correct-promotion-dockerfile-ignored-repos", "Repos that are being ignored when ensuring the correct promotion dockerfile in org/repo notation. Can be passed multiple times.")
 	flag.IntVar(&o.maxConcurrency, "concurrency", 500, "Maximum number of concurrent in-flight goroutines to handle files.")
 	flag.StringVar(&o.ocpBuildDataRepoDir, "ocp-build-data-repo-dir", "../ocp-build-data", "The directory in which the ocp-build-data repository is")
@@ -71,6 +91,19 @@ func gatherOptions() (*options, error) {
 	flag.BoolVar(&o.applyReplacements, "apply-replacements", true, "If we should apply Dockerfile image replacements. You will probably always leave this as the default, and it's mostly used by tests that validate that base image pruning doesn't botch things. Note: If not applying replacements we will also skip unused replacement pruning.")
 	flag.BoolVar(&o.pruneOCPBuilderReplacements, "prune-ocp-builder-replacements", false, "If all replacements that target the ocp/builder imagestream should be removed")
 	flag.StringVar(&o.registryPath, "registry", "", "Path to the step registry directory")
+	flag.Var(&o.registryRegexesRaw, "registry-regex", fmt.Sprintf("Additional regular expression matching pull specs of registries whose references should be replaced, on top of %q. Can be passed multiple times.", registryRegex.String()))
+	flag.Var(&o.dockerfileNames, "dockerfile-name", "Additional file name, e.g. Containerfile, that is processed like the Dockerfile in the context directory of images without a dockerfile_path. Can be passed multiple times.")
+	flag.Var(&o.multiArchImageStreams, "multi-arch-imagestream", "Imagestream whose tags are manifest lists, in org/repo notation. References to its images in repo:tag@sha256 notation are replaced with the tag rather than left alone, as the digest may pin a single architecture. Tag-only references are replaced as usual. Can be passed multiple times.")
+	flag.Var(o.skippedImages, "skip-image", "Images that are left untouched, in org/repo:to notation where to is the name of the image in the config. Can be passed multiple times.")
+	flag.BoolVar(&o.printDiff, "print-diff", false, "If set, print a unified diff of the changes to stdout instead of writing the configs")
+	flag.StringVar(&o.gitLabURL, "gitlab-url", "", "Base URL of the GitLab instance hosting the repos passed via --gitlab-repo, e.g. https://gitlab.example.com")
+	flag.Var(&o.gitLabRepos, "gitlab-repo", "Repos hosted on the GitLab instance from --gitlab-url instead of GitHub, in org/repo notation. Can be passed multiple times.")
+	flag.StringVar(&o.gitLabTokenPath, "gitlab-token-path", "", "Path to the file containing the GitLab token used to fetch files from the repos passed via --gitlab-repo")
+	flag.Var(&o.orgTokenPathsRaw, "github-org-token-path", "A GitHub org and the path to the file containing the token used to fetch files from its repos instead of the default token, in org=path notation. Can be passed multiple times.")
+	flag.StringVar(&o.onlyOrg, "only-org", "", "If set, only process the configs of this org")
+	flag.StringVar(&o.onlyRepo, "only-repo", "", "If set, only process the configs of repos with this name")
+	flag.StringVar(&o.reportPath, "report-path", "", "If set, write a report of the pruned replacements and base images of every config to this path, as CSV if it ends in .csv and as JSON otherwise")
+	flag.BoolVar(&o.check, "check", false, "If set, do not write the configs but exit non-zero listing the ones that are out of date, e.g. to gate changes in CI")
 	flag.Parse()
 
 	var errs []error
@@ -78,7 +111,58 @@ func gatherOptions() (*options, error) {
 		errs = append(errs, errors.New("--config-dir is mandatory"))
 	}
 
+	o.registryRegexes = []*regexp.Regexp{registryRegex}
+	for _, raw := range o.registryRegexesRaw.Strings() {
+		re, err := regexp.Compile(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("--registry-regex %q is not a valid regular expression: %w", raw, err))
+			continue
+		}
+		o.registryRegexes = append(o.registryRegexes, re)
+	}
+
+	if len(o.gitLabRepos.Strings()) > 0 && o.gitLabURL == "" {
+		errs = append(errs, errors.New("--gitlab-url is required when --gitlab-repo is set"))
+	}
+	for _, repo := range o.gitLabRepos.Strings() {
+		if parts := strings.Split(repo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			errs = append(errs, fmt.Errorf("--gitlab-repo %q is not in org/repo notation", repo))
+		}
+	}
+
+	for _, imageStream := range o.multiArchImageStreams.Strings() {
+		if parts := strings.Split(imageStream, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			errs = append(errs, fmt.Errorf("--multi-arch-imagestream %q is not in org/repo notation", imageStream))
+		}
+	}
+
+	o.orgTokenPaths = map[string]string{}
+	for _, raw := range o.orgTokenPathsRaw.Strings() {
+		org, path, found := strings.Cut(raw, "=")
+		if !found || org == "" || path == "" {
+			errs = append(errs, fmt.Errorf("--github-org-token-path %q is not in org=path notation", raw))
+			continue
+		}
+		if _, duplicate := o.orgTokenPaths[org]; duplicate {
+			errs = append(errs, fmt.Errorf("--github-org-token-path was passed more than once for org %s", org))
+			continue
+		}
+		o.orgTokenPaths[org] = path
+	}
+
+	if o.check {
+		if o.createPR {
+			errs = append(errs, errors.New("--check and --create-pr are mutually exclusive"))
+		}
+		if o.printDiff {
+			errs = append(errs, errors.New("--check and --print-diff are mutually exclusive"))
+		}
+	}
+
 	if o.createPR {
+		if o.printDiff {
+			errs = append(errs, errors.New("--print-diff and --create-pr are mutually exclusive"))
+		}
 		if o.githubUserName == "" {
 			errs = append(errs, errors.New("--github-user-name was unset, it is required when --create-pr is set"))
 		}
@@ -93,6 +177,8 @@ func gatherOptions() (*options, error) {
 			errs = append(errs, errors.New("--current-release must be set when --ensure-correct-promotion-dockerfile is set"))
 		}
 		o.currentRelease.Major = "4"
+	} else if o.verifyDockerfileExists {
+		errs = append(errs, errors.New("--verify-dockerfile-exists requires --ensure-correct-promotion-dockerfile"))
 	}
 
 	return o, utilerrors.NewAggregate(errs)
@@ -120,43 +206,82 @@ func main() {
 		}
 	}
 
-	var promotionTargetToDockerfileMapping map[string]dockerfileLocation
+	var promotionDockerfiles ocpBuildDataDockerfiles
 	if opts.ensureCorrectPromotionDockerfile {
-		var err error
-		promotionTargetToDockerfileMapping, err = getPromotionTargetToDockerfileMapping(opts.ocpBuildDataRepoDir, opts.currentRelease)
+		promotionTargetToDockerfileMapping, err := getPromotionTargetToDockerfileMapping(opts.ocpBuildDataRepoDir, opts.currentRelease)
 		if err != nil {
 			logrus.WithError(err).Fatal("Failed to construct promotion target to dockerfile mapping")
 		}
+		promotionDockerfiles = newOCPBuildDataDockerfiles(promotionTargetToDockerfileMapping, opts.currentRelease)
 	}
 
-	var credentials *usernameToken
+	credentials := &githubCredentials{byOrg: map[string]*usernameToken{}}
 	if opts.TokenPath != "" {
-		credentials = &usernameToken{
+		credentials.defaultCredentials = &usernameToken{
 			username: opts.githubUserName,
 			token:    string(secret.GetSecret(opts.TokenPath)),
 		}
 	}
+	for org, path := range opts.orgTokenPaths {
+		if err := secret.Add(path); err != nil {
+			logrus.WithError(err).WithField("org", org).Fatal("Failed to load github token for org")
+		}
+		credentials.byOrg[org] = &usernameToken{
+			username: opts.githubUserName,
+			token:    string(secret.GetSecret(path)),
+		}
+	}
+
+	var gitLabCredentials *usernameToken
+	if opts.gitLabTokenPath != "" {
+		if err := secret.Add(opts.gitLabTokenPath); err != nil {
+			logrus.WithError(err).Fatal("Failed to load gitlab token")
+		}
+		gitLabCredentials = &usernameToken{token: string(secret.GetSecret(opts.gitLabTokenPath))}
+	}
+	fileGetterFactory := sourceFileGetterFactory(github.FileGetterFactory, github.GitLabFileGetterFactory, opts.gitLabURL, sets.New[string](opts.gitLabRepos.Strings()...), gitLabCredentials)
 
 	resolver, err := loadResolver(opts.registryPath)
 	if err != nil {
 		logrus.WithError(err).Fatal("failed to load resolver")
 	}
 
+	var diffOut io.Writer
+	if opts.printDiff {
+		diffOut = &lockedWriter{w: os.Stdout}
+	}
+
+	var report *pruneReport
+	if opts.reportPath != "" {
+		report = newPruneReport()
+	}
+
 	var errs []error
+	var outOfDate []string
 	errLock := &sync.Mutex{}
 	sem := semaphore.NewWeighted(int64(opts.maxConcurrency))
 	ctx := context.TODO()
 	if err := config.OperateOnCIOperatorConfigDir(
 		opts.configDir,
+		nil,
 		func(config *api.ReleaseBuildConfiguration, info *config.Info) error {
+			if !opts.selects(info) {
+				return nil
+			}
 			if err := sem.Acquire(ctx, 1); err != nil {
 				return fmt.Errorf("failed to acquire semaphore: %w", err)
 			}
 			go func(filename string) {
 				defer sem.Release(1)
 				if err := replacer(
-					github.FileGetterFactory,
+					fileGetterFactory,
 					func(data []byte) error {
+						if opts.check {
+							errLock.Lock()
+							outOfDate = append(outOfDate, filename)
+							errLock.Unlock()
+							return nil
+						}
 						return os.WriteFile(filename, data, 0644)
 					},
 					opts.pruneUnusedReplacements,
@@ -164,10 +289,16 @@ func main() {
 					opts.pruneUnusedBaseImages,
 					opts.applyReplacements,
 					opts.ensureCorrectPromotionDockerfile,
+					opts.verifyDockerfileExists,
 					sets.New[string](opts.ensureCorrectPromotionDockerfileIngoredRepos.Strings()...),
-					promotionTargetToDockerfileMapping,
-					opts.currentRelease,
+					promotionDockerfiles,
 					credentials,
+					opts.registryRegexes,
+					opts.dockerfileNames.Strings(),
+					sets.New[string](opts.multiArchImageStreams.Strings()...),
+					sets.New[string](opts.skippedImages.Strings()...),
+					diffOut,
+					report,
 					func(config api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error) {
 						return registry.ResolveConfig(resolver, config)
 					},
@@ -188,6 +319,18 @@ func main() {
 	if err := utilerrors.NewAggregate(errs); err != nil {
 		logrus.WithError(err).Fatal("Encountered errors")
 	}
+	if report != nil {
+		if err := report.write(opts.reportPath); err != nil {
+			logrus.WithError(err).Fatal("Failed to write the report")
+		}
+	}
+
+	if opts.check {
+		if err := checkUpToDate(outOfDate); err != nil {
+			logrus.WithError(err).Fatal("Check failed")
+		}
+		return
+	}
 
 	if !opts.createPR {
 		return
@@ -198,6 +341,20 @@ func main() {
 	}
 }
 
+// checkUpToDate returns an error listing the configs that would be changed by the replacer
+func checkUpToDate(outOfDate []string) error {
+	if len(outOfDate) == 0 {
+		return nil
+	}
+	sort.Strings(outOfDate)
+	return fmt.Errorf("%d configs are out of date, run registry-replacer to update them: %s", len(outOfDate), strings.Join(outOfDate, ", "))
+}
+
+// selects determines whether the config is processed, based on --only-org and --only-repo
+func (o *options) selects(info *config.Info) bool {
+	return (o.onlyOrg == "" || o.onlyOrg == info.Org) && (o.onlyRepo == "" || o.onlyRepo == info.Repo)
+}
+
 func loadResolver(path string) (registry.Resolver, error) {
 	if path == "" {
 		return nil, nil
@@ -214,6 +371,47 @@ type usernameToken struct {
 	token    string
 }
 
+// githubCredentials holds the credentials used to fetch files from GitHub. Orgs
+// that need a different token, e.g. of another app installation, can have their own.
+type githubCredentials struct {
+	defaultCredentials *usernameToken
+	byOrg              map[string]*usernameToken
+}
+
+// forOrg returns the credentials for the org, falling back to the default ones
+func (c *githubCredentials) forOrg(org string) *usernameToken {
+	if c == nil {
+		return nil
+	}
+	if credentials, ok := c.byOrg[org]; ok {
+		return credentials
+	}
+	return c.defaultCredentials
+}
+
+// sourceFileGetterFactory returns a file getter factory that fetches files of the given GitLab-hosted
+// repos from the GitLab instance at gitLabURL and files of all other repos from GitHub. The GitHub
+// credentials passed to the returned factory are not used for GitLab-hosted repos, those use the
+// gitLabCredentials instead.
+func sourceFileGetterFactory(
+	githubFileGetterFactory func(org, repo, branch string, opts ...github.Opt) github.FileGetter,
+	gitLabFileGetterFactory func(baseURL, org, repo, branch string, opts ...github.Opt) github.FileGetter,
+	gitLabURL string,
+	gitLabRepos sets.Set[string],
+	gitLabCredentials *usernameToken,
+) func(org, repo, branch string, opts ...github.Opt) github.FileGetter {
+	return func(org, repo, branch string, opts ...github.Opt) github.FileGetter {
+		if !gitLabRepos.Has(org + "/" + repo) {
+			return githubFileGetterFactory(org, repo, branch, opts...)
+		}
+		var gitLabOpts []github.Opt
+		if gitLabCredentials != nil {
+			gitLabOpts = append(gitLabOpts, github.WithAuthentication(gitLabCredentials.username, gitLabCredentials.token))
+		}
+		return gitLabFileGetterFactory(gitLabURL, org, repo, branch, gitLabOpts...)
+	}
+}
+
 // replacer ensures replace directives are in place. It fetches the files via http because using git
 // en masse easily kills a developer laptop whereas the http calls are cheap and can be parallelized without
 // bounds.
@@ -225,10 +423,16 @@ func replacer(
 	pruneUnusedBaseImagesEnabled bool,
 	applyReplacements bool,
 	ensureCorrectPromotionDockerfile bool,
+	verifyDockerfileExists bool,
 	ensureCorrectPromotionDockerfileIgnoredrepos sets.Set[string],
-	promotionTargetToDockerfileMapping map[string]dockerfileLocation,
-	majorMinor ocpbuilddata.MajorMinor,
-	credentials *usernameToken,
+	promotionDockerfiles ocpBuildDataDockerfiles,
+	credentials *githubCredentials,
+	registryRegexes []*regexp.Regexp,
+	dockerfileNames []string,
+	multiArchImageStreams sets.Set[string],
+	skippedImages sets.Set[string],
+	diffOut io.Writer,
+	report *pruneReport,
 	configResolver func(config api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error),
 ) func(*api.ReleaseBuildConfiguration, *config.Info) error {
 	return func(config *api.ReleaseBuildConfiguration, info *config.Info) error {
@@ -240,19 +444,27 @@ func replacer(
 		if err != nil {
 			return fmt.Errorf("failed to marshal config for comparison: %w", err)
 		}
-
-		// We have to do this first because the result of the following operations might
-		// change based on what we do here.
-		if ensureCorrectPromotionDockerfile {
-			updateDockerfilesToMatchOCPBuildData(config, promotionTargetToDockerfileMapping, majorMinor.String(), ensureCorrectPromotionDockerfileIgnoredrepos)
+		var original *api.ReleaseBuildConfiguration
+		if report != nil {
+			original = config.DeepCopy()
 		}
 
 		var getter github.FileGetter
-		if credentials == nil {
+		if credentials := credentials.forOrg(info.Org); credentials == nil {
 			getter = githubFileGetterFactory(info.Org, info.Repo, info.Branch)
 		} else {
 			getter = githubFileGetterFactory(info.Org, info.Repo, info.Branch, github.WithAuthentication(credentials.username, credentials.token))
 		}
+
+		// We have to do this first because the result of the following operations might
+		// change based on what we do here.
+		if ensureCorrectPromotionDockerfile {
+			var dockerfileGetter github.FileGetter
+			if verifyDockerfileExists {
+				dockerfileGetter = getter
+			}
+			updateDockerfilesToMatchOCPBuildData(config, promotionDockerfiles, ensureCorrectPromotionDockerfileIgnoredrepos, skippedImages, dockerfileGetter)
+		}
 		allReplacementCandidates := sets.Set[string]{}
 
 		if applyReplacements {
@@ -261,56 +473,59 @@ func replacer(
 			var hasNonEmptyDockerfile bool
 
 			for idx, image := range config.Images {
-				var dockerfile []byte
+				if isSkipped(config, image, skippedImages) {
+					logrus.WithField("image", skippedImageKey(config, image)).Info("Skipping image")
+					continue
+				}
+				var dockerfiles [][]byte
 				if image.DockerfileLiteral != nil {
-					dockerfile = []byte(*image.DockerfileLiteral)
+					dockerfiles = append(dockerfiles, []byte(*image.DockerfileLiteral))
 				} else {
-					dockerFilePath := "Dockerfile"
-					if image.DockerfilePath != "" {
-						dockerFilePath = image.DockerfilePath
-					}
-
-					var err error
-					dockerfile, err = getter(filepath.Join(image.ContextDir, dockerFilePath))
-					if err != nil {
-						return fmt.Errorf("failed to get dockerfile %s: %w", image.DockerfilePath, err)
+					for _, dockerfilePath := range dockerfilePaths(image, dockerfileNames) {
+						dockerfile, err := getter(dockerfilePath)
+						if err != nil {
+							return fmt.Errorf("failed to get dockerfile %s: %w", dockerfilePath, err)
+						}
+						dockerfiles = append(dockerfiles, dockerfile)
 					}
 				}
 
-				hasNonEmptyDockerfile = hasNonEmptyDockerfile || len(dockerfile) > 0
-
-				dockerfile, err = applyReplacementsToDockerfile(dockerfile, &image)
-				if err != nil {
-					return fmt.Errorf("failed to apply replacements to Dockerfile in %s/%s@%s: %w", info.Org, info.Repo, info.Branch, err)
-				}
+				for _, dockerfile := range dockerfiles {
+					hasNonEmptyDockerfile = hasNonEmptyDockerfile || len(dockerfile) > 0
 
-				foundTags, err := ensureReplacement(&config.Images[idx], dockerfile)
-				if err != nil {
-					return fmt.Errorf("failed to ensure replacements in %s/%s@%s: %w", info.Org, info.Repo, info.Branch, err)
-				}
-				for _, foundTag := range foundTags {
-					if config.BaseImages == nil {
-						config.BaseImages = map[string]api.ImageStreamTagReference{}
+					dockerfile, err = applyReplacementsToDockerfile(dockerfile, &image)
+					if err != nil {
+						return fmt.Errorf("failed to apply replacements to Dockerfile in %s/%s@%s: %w", info.Org, info.Repo, info.Branch, err)
 					}
-					if _, exists := config.BaseImages[foundTag.String()]; exists {
-						continue
+
+					foundTags, err := ensureReplacement(&config.Images[idx], dockerfile, registryRegexes, multiArchImageStreams)
+					if err != nil {
+						return fmt.Errorf("failed to ensure replacements in %s/%s@%s: %w", info.Org, info.Repo, info.Branch, err)
 					}
-					config.BaseImages[foundTag.String()] = api.ImageStreamTagReference{
-						Namespace: foundTag.org,
-						Name:      foundTag.repo,
-						Tag:       foundTag.tag,
+					for _, foundTag := range foundTags {
+						if config.BaseImages == nil {
+							config.BaseImages = map[string]api.ImageStreamTagReference{}
+						}
+						if _, exists := config.BaseImages[foundTag.String()]; exists {
+							continue
+						}
+						config.BaseImages[foundTag.String()] = api.ImageStreamTagReference{
+							Namespace: foundTag.org,
+							Name:      foundTag.repo,
+							Tag:       foundTag.tag,
+						}
 					}
-				}
 
-				replacementCandidates, err := extractReplacementCandidatesFromDockerfile(dockerfile)
-				if err != nil {
-					return fmt.Errorf("failed to extract source images from dockerfile in %s/%s@%s: %w", info.Org, info.Repo, info.Branch, err)
+					replacementCandidates, err := extractReplacementCandidatesFromDockerfile(dockerfile)
+					if err != nil {
+						return fmt.Errorf("failed to extract source images from dockerfile in %s/%s@%s: %w", info.Org, info.Repo, info.Branch, err)
+					}
+					allReplacementCandidates.Insert(replacementCandidates.UnsortedList()...)
 				}
-				allReplacementCandidates.Insert(replacementCandidates.UnsortedList()...)
 			}
 
 			if pruneUnusedReplacementsEnabled && hasNonEmptyDockerfile {
-				if err := pruneUnusedReplacements(config, allReplacementCandidates); err != nil {
+				if err := pruneUnusedReplacements(config, allReplacementCandidates, skippedImages); err != nil {
 					return fmt.Errorf("failed to prune unused replacements in %s/%s@%s: %w", info.Org, info.Repo, info.Branch, err)
 				}
 			} else if pruneUnusedReplacementsEnabled {
@@ -348,6 +563,12 @@ func replacer(
 			return nil
 		}
 
+		report.record(info.Filename, original, config)
+
+		if diffOut != nil {
+			return writeDiff(diffOut, info.Filename, originalConfig, newConfig)
+		}
+
 		if err := writer(newConfig); err != nil {
 			return fmt.Errorf("faild to write %s: %w", info.Filename, err)
 		}
@@ -356,21 +577,57 @@ func replacer(
 	}
 }
 
+// writeDiff writes a unified diff between the original and the updated config in a single write,
+// so that diffs of different configs do not get interleaved.
+func writeDiff(w io.Writer, filename string, original, updated []byte) error {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(original)),
+		B:        difflib.SplitLines(string(updated)),
+		FromFile: filename,
+		ToFile:   filename,
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to construct diff for %s: %w", filename, err)
+	}
+	if _, err := w.Write([]byte(diff)); err != nil {
+		return fmt.Errorf("failed to write diff for %s: %w", filename, err)
+	}
+	return nil
+}
+
+type lockedWriter struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.w.Write(p)
+}
+
 var registryRegex = regexp.MustCompile(`registry\.(|svc\.)ci\.openshift\.org/\S+`)
 
-type orgRepoTag struct{ org, repo, tag string }
+type orgRepoTag struct{ org, repo, tag, digest string }
 
 func (ort orgRepoTag) String() string {
 	return ort.org + "_" + ort.repo + "_" + ort.tag
 }
 
-func ensureReplacement(image *api.ProjectDirectoryImageBuildStepConfiguration, dockerfile []byte) ([]orgRepoTag, error) {
+func ensureReplacement(image *api.ProjectDirectoryImageBuildStepConfiguration, dockerfile []byte, registryRegexes []*regexp.Regexp, multiArchImageStreams sets.Set[string]) ([]orgRepoTag, error) {
 	var toReplace []string
-	for _, line := range bytes.Split(dockerfile, []byte("\n")) {
+	lines := bytes.Split(dockerfile, []byte("\n"))
+	argsInFrom := argsReferencedInFrom(lines)
+	for _, line := range lines {
 		if !bytes.Contains(line, []byte("FROM")) && !bytes.Contains(line, []byte("COPY")) && !bytes.Contains(line, []byte("copy")) {
+			// ARG defaults are included when they are referenced in FROM directives
+			if isArgLine(line) {
+				toReplace = append(toReplace, argDefaultRegistryReferences(line, argsInFrom, registryRegexes)...)
+			}
 			continue
 		}
-		match := registryRegex.Find(line)
+		match := findRegistryReference(line, registryRegexes)
 		if match == nil {
 			continue
 		}
@@ -384,6 +641,16 @@ func ensureReplacement(image *api.ProjectDirectoryImageBuildStepConfiguration, d
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse string %s as pullspec: %w", toReplace, err)
 		}
+		// The digest in a reference to a multi-arch imagestream usually pins the image of a single
+		// architecture, while the tag next to it references the manifest list
+		if orgRepoTag.tag != "" && multiArchImageStreams.Has(orgRepoTag.org+"/"+orgRepoTag.repo) {
+			orgRepoTag.digest = ""
+		}
+		// An ImageStreamTag can not pin a digest, so digest-pinned references are left alone
+		// rather than replaced with a tag that may point to a different image
+		if orgRepoTag.digest != "" {
+			continue
+		}
 
 		// Assume ppl know what they are doing
 		if hasReplacementFor(image, toReplace) {
@@ -403,6 +670,53 @@ func ensureReplacement(image *api.ProjectDirectoryImageBuildStepConfiguration, d
 	return result, nil
 }
 
+func isArgLine(line []byte) bool {
+	fields := bytes.Fields(line)
+	return len(fields) > 0 && bytes.EqualFold(fields[0], []byte("ARG"))
+}
+
+// argsReferencedInFrom returns the names of the ARGs referenced in FROM directives
+func argsReferencedInFrom(lines [][]byte) sets.Set[string] {
+	referenced := sets.New[string]()
+	for _, line := range lines {
+		fields := bytes.Fields(line)
+		if len(fields) < 2 || !bytes.EqualFold(fields[0], []byte("FROM")) {
+			continue
+		}
+		os.Expand(string(fields[1]), func(name string) string {
+			referenced.Insert(name)
+			return ""
+		})
+	}
+	return referenced
+}
+
+// argDefaultRegistryReferences returns the registry references in the defaults of the
+// ARGs declared in the line that are part of the given names
+func argDefaultRegistryReferences(line []byte, names sets.Set[string], registryRegexes []*regexp.Regexp) []string {
+	var references []string
+	for _, arg := range bytes.Fields(line)[1:] {
+		name, value, hasDefault := bytes.Cut(arg, []byte("="))
+		if !hasDefault || !names.Has(string(name)) {
+			continue
+		}
+		if match := findRegistryReference(value, registryRegexes); match != nil {
+			references = append(references, string(match))
+		}
+	}
+	return references
+}
+
+// findRegistryReference returns the first match of any of the given registry regexes in the line
+func findRegistryReference(line []byte, registryRegexes []*regexp.Regexp) []byte {
+	for _, re := range registryRegexes {
+		if match := re.Find(line); match != nil {
+			return match
+		}
+	}
+	return nil
+}
+
 func hasReplacementFor(image *api.ProjectDirectoryImageBuildStepConfiguration, target string) bool {
 	for _, input := range image.Inputs {
 		if sets.New[string](input.As...).Has(target) {
@@ -429,6 +743,13 @@ func orgRepoTagFromPullString(pullString string) (orgRepoTag, error) {
 	default:
 		return res, fmt.Errorf("pull stringe %q couldn't be parsed, expected to get between one and three elements after slashsplitting, got %d", pullString, n)
 	}
+	// A digest can not be referenced through an ImageStreamTag, so it is kept apart from the tag.
+	// Without a tag next to it, there is no tag to default to.
+	if repo, digest, found := strings.Cut(res.repo, "@"); found {
+		res.repo = repo
+		res.digest = digest
+		res.tag = ""
+	}
 	if repoTag := strings.Split(res.repo, ":"); len(repoTag) == 2 {
 		res.repo = repoTag[0]
 		res.tag = repoTag[1]
@@ -515,6 +836,23 @@ func (c *censor) Censor(data []byte) []byte {
 	return bytes.ReplaceAll(data, c.secret, []byte("<< REDACTED >>"))
 }
 
+// dockerfilePaths returns the paths of the files an image is built from. The dockerfile_path
+// of the image takes precedence, otherwise the Dockerfile and all files with one of the
+// additional names in the context directory are used.
+func dockerfilePaths(image api.ProjectDirectoryImageBuildStepConfiguration, dockerfileNames []string) []string {
+	if image.DockerfilePath != "" {
+		return []string{filepath.Join(image.ContextDir, image.DockerfilePath)}
+	}
+	paths := []string{filepath.Join(image.ContextDir, "Dockerfile")}
+	for _, name := range dockerfileNames {
+		if name == "Dockerfile" {
+			continue
+		}
+		paths = append(paths, filepath.Join(image.ContextDir, name))
+	}
+	return paths
+}
+
 // applyReplacementsToDockerfile duplicates what the build tools would do
 func applyReplacementsToDockerfile(in []byte, image *api.ProjectDirectoryImageBuildStepConfiguration) ([]byte, error) {
 	if image.From == "" {
@@ -543,17 +881,28 @@ func extractReplacementCandidatesFromDockerfile(dockerfile []byte) (sets.Set[str
 	// copied from https://github.com/openshift/builder/blob/1205194b1d67f2b68c163add5ae17e4b81962ec3/pkg/build/builder/common.go#L472-L497
 	// only difference: We collect the replacement source values rather than doing the replacements
 	names := make(map[string]string)
-	stages, err := imagebuilder.NewStages(node, imagebuilder.NewBuilder(make(map[string]string)))
+	builder := imagebuilder.NewBuilder(make(map[string]string))
+	stages, err := imagebuilder.NewStages(node, builder)
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct imagebuilder stages: %w", err)
 	}
+	// stageNames are the names of the stages declared so far, a FROM referencing them does not reference an image
+	stageNames := sets.New[string]()
 	for _, stage := range stages {
 		for _, child := range stage.Node.Children {
 			switch {
 			case child.Value == "from" && child.Next != nil:
 				image := child.Next
-				replacementCandidates.Insert(image.Value)
 				names[stage.Name] = image.Value
+				if image.Value == "scratch" || stageNames.Has(image.Value) {
+					logrus.WithField("from", image.Value).Debug("Ignoring FROM that does not reference an image")
+					break
+				}
+				replacementCandidates.Insert(image.Value)
+				// FROM can reference ARGs declared before the first FROM, resolve them through their defaults
+				if resolved := expandHeadingArgs(image.Value, builder.HeadingArgs); resolved != image.Value {
+					replacementCandidates.Insert(resolved)
+				}
 				if alias := image.Next; alias != nil && alias.Value == "AS" && alias.Next != nil {
 					replacementCandidates.Insert(alias.Next.Value)
 				}
@@ -567,19 +916,37 @@ func extractReplacementCandidatesFromDockerfile(dockerfile []byte) (sets.Set[str
 				}
 			}
 		}
+		stageNames.Insert(stage.Name)
 	}
 
 	return replacementCandidates, nil
 }
 
-func pruneUnusedReplacements(config *api.ReleaseBuildConfiguration, replacementCandidates sets.Set[string]) error {
-	return pruneReplacements(config, func(asDirective string, _ string) (bool, error) {
+// expandHeadingArgs expands references to ARGs using their default value. The value is
+// returned untouched if any of the referenced ARGs has no default.
+func expandHeadingArgs(value string, headingArgs map[string]string) string {
+	var missingDefault bool
+	expanded := os.Expand(value, func(name string) string {
+		arg, ok := headingArgs[name]
+		if !ok || arg == "" {
+			missingDefault = true
+		}
+		return arg
+	})
+	if missingDefault {
+		return value
+	}
+	return expanded
+}
+
+func pruneUnusedReplacements(config *api.ReleaseBuildConfiguration, replacementCandidates, skippedImages sets.Set[string]) error {
+	return pruneReplacements(config, skippedImages, func(asDirective string, _ string) (bool, error) {
 		return replacementCandidates.Has(asDirective), nil
 	})
 }
 
 func pruneOCPBuilderReplacements(config *api.ReleaseBuildConfiguration) error {
-	return pruneReplacements(config, func(asDirective string, imageKey string) (bool, error) {
+	return pruneReplacements(config, nil, func(asDirective string, imageKey string) (bool, error) {
 		orgRepoTag, err := orgRepoTagFromPullString(asDirective)
 		if err != nil {
 			return false, fmt.Errorf("failed to extract org and tag from pull spec %s: %w", asDirective, err)
@@ -616,13 +983,26 @@ func pruneOCPBuilderReplacements(config *api.ReleaseBuildConfiguration) error {
 	})
 }
 
+// skippedImageKey identifies an image in the org/repo:to notation used by --skip-image
+func skippedImageKey(config *api.ReleaseBuildConfiguration, image api.ProjectDirectoryImageBuildStepConfiguration) string {
+	return fmt.Sprintf("%s/%s:%s", config.Metadata.Org, config.Metadata.Repo, image.To)
+}
+
+func isSkipped(config *api.ReleaseBuildConfiguration, image api.ProjectDirectoryImageBuildStepConfiguration, skippedImages sets.Set[string]) bool {
+	return skippedImages.Has(skippedImageKey(config, image))
+}
+
 type asDirectiveFilter func(asDirectiveValue string, inputKey string) (keep bool, err error)
 
-func pruneReplacements(config *api.ReleaseBuildConfiguration, filter asDirectiveFilter) error {
+func pruneReplacements(config *api.ReleaseBuildConfiguration, skippedImages sets.Set[string], filter asDirectiveFilter) error {
 	var prunedImages []api.ProjectDirectoryImageBuildStepConfiguration
 	var errs []error
 
 	for _, image := range config.Images {
+		if isSkipped(config, image, skippedImages) {
+			prunedImages = append(prunedImages, image)
+			continue
+		}
 		for k, sourceImage := range image.Inputs {
 			var newAs []string
 			for _, sourceImage := range sourceImage.As {
@@ -676,11 +1056,17 @@ func pruneUnusedBaseImages(config *api.ReleaseBuildConfiguration, resolvedConfig
 			getImageBuildInputImages(config, step.ProjectDirectoryImageBuildStepConfiguration.Inputs, &usedBaseImages)
 		case step.RPMImageInjectionStepConfiguration != nil:
 			usedBaseImages.Insert(string(step.RPMImageInjectionStepConfiguration.From))
+			// the injection target is how base_rpm_images are referenced
+			if step.RPMImageInjectionStepConfiguration.To != "" {
+				usedBaseImages.Insert(string(step.RPMImageInjectionStepConfiguration.To))
+			}
+		case step.RPMServeStepConfiguration != nil:
+			usedBaseImages.Insert(string(step.RPMServeStepConfiguration.From))
 		case step.SourceStepConfiguration != nil:
 			usedBaseImages.Insert(string(step.SourceStepConfiguration.From))
 		case step.TestStepConfiguration != nil:
 			getTestStepImages(resolvedConfig, &usedBaseImages, step.TestStepConfiguration)
-		case step.ReleaseImagesTagStepConfiguration != nil || step.ResolvedReleaseImagesStepConfiguration != nil || step.RPMServeStepConfiguration != nil:
+		case step.ReleaseImagesTagStepConfiguration != nil || step.ResolvedReleaseImagesStepConfiguration != nil:
 			// no op
 		default:
 			return fmt.Errorf("unsupported step configuration provided when pruning base images")
@@ -813,11 +1199,32 @@ func getPromotionTargetToDockerfileMapping(ocpBuildDataDir string, majorMinor oc
 	return result, nil
 }
 
+// ocpBuildDataDockerfiles indexes the Dockerfile locations from ocp-build-data by the tag they
+// are promoted to in the ocp/<major.minor> ImageStream of the current release. It is computed
+// once and shared by all configs, so that looking up an image does not require to format and
+// compare full pull specs.
+type ocpBuildDataDockerfiles struct {
+	majorMinor string
+	byTag      map[string]dockerfileLocation
+}
+
+func newOCPBuildDataDockerfiles(promotionTargetToDockerfileMapping map[string]dockerfileLocation, majorMinor ocpbuilddata.MajorMinor) ocpBuildDataDockerfiles {
+	dockerfiles := ocpBuildDataDockerfiles{majorMinor: majorMinor.String(), byTag: map[string]dockerfileLocation{}}
+	prefix := fmt.Sprintf("registry.ci.openshift.org/ocp/%s:", dockerfiles.majorMinor)
+	for promotionTarget, location := range promotionTargetToDockerfileMapping {
+		if tag, ok := strings.CutPrefix(promotionTarget, prefix); ok {
+			dockerfiles.byTag[tag] = location
+		}
+	}
+	return dockerfiles
+}
+
 func updateDockerfilesToMatchOCPBuildData(
 	config *api.ReleaseBuildConfiguration,
-	promotionTargetToDockerfileMapping map[string]dockerfileLocation,
-	majorMinorVersion string,
+	dockerfiles ocpBuildDataDockerfiles,
 	ignoredRepos sets.Set[string],
+	skippedImages sets.Set[string],
+	getter github.FileGetter,
 ) {
 
 	// The tool only works for the current release
@@ -828,29 +1235,47 @@ func updateDockerfilesToMatchOCPBuildData(
 		return
 	}
 
-	// Configs indexed by tag
-	promotedTags := map[string]api.ImageStreamTagReference{}
-	for _, promotedTag := range release.PromotedTags(config) {
-		if promotedTag.Namespace != "ocp" || promotedTag.Name != majorMinorVersion {
-			continue
+	// Tags promoted to the current release. The promoted tags are not sorted like
+	// release.PromotedTags does, as this runs for every config and only lookups are needed.
+	promotedTags := sets.New[string]()
+	promotedTagsBySource, _ := release.PromotedTagsWithRequiredImages(config)
+	for _, tags := range promotedTagsBySource {
+		for _, promotedTag := range tags {
+			if promotedTag.Namespace == "ocp" && promotedTag.Name == dockerfiles.majorMinor {
+				promotedTags.Insert(promotedTag.Tag)
+			}
 		}
-		promotedTags[promotedTag.Tag] = promotedTag
 	}
 	if len(promotedTags) == 0 {
 		return
 	}
 
 	for idx, image := range config.Images {
-		promotionTarget, ok := promotedTags[string(image.To)]
-		if !ok {
+		if !promotedTags.Has(string(image.To)) || isSkipped(config, image, skippedImages) {
 			continue
 		}
-		stringifiedPromotionTarget := fmt.Sprintf("registry.ci.openshift.org/%s", promotionTarget.ISTagName())
-		dockerfilePath, ok := promotionTargetToDockerfileMapping[stringifiedPromotionTarget]
+		dockerfilePath, ok := dockerfiles.byTag[string(image.To)]
 		if !ok {
-			logrus.WithField("promotiontarget", stringifiedPromotionTarget).Info("Ignoring promotion target for which we have no ocp-build-data config")
+			logrus.WithField("promotiontarget", fmt.Sprintf("registry.ci.openshift.org/ocp/%s:%s", dockerfiles.majorMinor, image.To)).Info("Ignoring promotion target for which we have no ocp-build-data config")
 			continue
 		}
+		if image.ContextDir == dockerfilePath.contextDir && image.DockerfilePath == dockerfilePath.dockerfile {
+			continue
+		}
+		if getter != nil {
+			path := filepath.Join(dockerfilePath.contextDir, dockerfilePath.dockerfile)
+			logger := logrus.WithField("image", skippedImageKey(config, image)).WithField("dockerfile", path)
+			dockerfile, err := getter(path)
+			if err != nil {
+				logger.WithError(err).Warn("Failed to verify that the Dockerfile from ocp-build-data exists, not updating the image")
+				continue
+			}
+			// The getter returns no content and no error when the file does not exist
+			if len(dockerfile) == 0 {
+				logger.Info("Dockerfile from ocp-build-data does not exist in the repo, not updating the image")
+				continue
+			}
+		}
 		if image.ContextDir != dockerfilePath.contextDir {
 			config.Images[idx].ContextDir = dockerfilePath.contextDir
 		}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/sirupsen/logrus"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 	utilpointer "k8s.io/utils/pointer"
@@ -26,10 +29,14 @@ func TestReplacer(t *testing.T) {
 		pruneOCPBuilderReplacementsEnabled           bool
 		pruneUnusedBaseImagesEnabled                 bool
 		ensureCorrectPromotionDockerfile             bool
+		verifyDockerfileExists                       bool
 		ensureCorrectPromotionDockerfileIngoredRepos sets.Set[string]
 		promotionTargetToDockerfileMapping           map[string]dockerfileLocation
 		files                                        map[string][]byte
-		credentials                                  *usernameToken
+		credentials                                  *githubCredentials
+		skippedImages                                sets.Set[string]
+		dockerfileNames                              []string
+		multiArchImageStreams                        sets.Set[string]
 		expectWrite                                  bool
 		epectedOpts                                  github.Opts
 	}{
@@ -54,6 +61,83 @@ func TestReplacer(t *testing.T) {
 			},
 			expectWrite: true,
 		},
+		{
+			name: "Additional dockerfile name is processed",
+			config: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{}},
+			},
+			files:           map[string][]byte{"Containerfile": []byte("FROM registry.svc.ci.openshift.org/org/repo:tag")},
+			dockerfileNames: []string{"Containerfile"},
+			expectWrite:     true,
+		},
+		{
+			name: "Dockerfile path takes precedence over additional dockerfile names",
+			config: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{DockerfilePath: "Dockerfile.rhel"}}},
+			},
+			files:           map[string][]byte{"Containerfile": []byte("FROM registry.svc.ci.openshift.org/org/repo:tag")},
+			dockerfileNames: []string{"Containerfile"},
+		},
+		{
+			name: "Replaces ARG default used in FROM",
+			config: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{}},
+			},
+			files:       map[string][]byte{"Dockerfile": []byte("ARG BASE=registry.ci.openshift.org/org/repo:tag\nFROM ${BASE}")},
+			expectWrite: true,
+		},
+		{
+			name: "ARG default not used in FROM is not replaced",
+			config: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{}},
+			},
+			files:       map[string][]byte{"Dockerfile": []byte("ARG BASE=registry.ci.openshift.org/org/repo:tag\nARG TOOLS=registry.ci.openshift.org/org/tools:tag\nFROM ${BASE}\nRUN echo ${TOOLS}")},
+			expectWrite: true,
+		},
+		{
+			name: "Digest of multi-arch imagestream is replaced with the manifest list tag",
+			config: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{}},
+			},
+			files:                 map[string][]byte{"Dockerfile": []byte("FROM registry.ci.openshift.org/ocp/4.14:base@sha256:4ad3c9e0ef5b2c4de0a0a41f1b4ae8b3ae2f96d9e1cf2bb54d2d4e1b5a8a7c9d")},
+			multiArchImageStreams: sets.New[string]("ocp/4.14"),
+			expectWrite:           true,
+		},
+		{
+			name: "Tag of multi-arch imagestream is replaced as usual",
+			config: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{}},
+			},
+			files:                 map[string][]byte{"Dockerfile": []byte("FROM registry.ci.openshift.org/ocp/4.14:base")},
+			multiArchImageStreams: sets.New[string]("ocp/4.14"),
+			expectWrite:           true,
+		},
+		{
+			name: "Digest without tag of multi-arch imagestream is left alone",
+			config: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{}},
+			},
+			files:                 map[string][]byte{"Dockerfile": []byte("FROM registry.ci.openshift.org/ocp/4.14@sha256:4ad3c9e0ef5b2c4de0a0a41f1b4ae8b3ae2f96d9e1cf2bb54d2d4e1b5a8a7c9d")},
+			multiArchImageStreams: sets.New[string]("ocp/4.14"),
+		},
+		{
+			name: "Digest of single-arch imagestream is left alone",
+			config: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{}},
+			},
+			files:                 map[string][]byte{"Dockerfile": []byte("FROM registry.ci.openshift.org/ocp/4.14:base@sha256:4ad3c9e0ef5b2c4de0a0a41f1b4ae8b3ae2f96d9e1cf2bb54d2d4e1b5a8a7c9d")},
+			multiArchImageStreams: sets.New[string]("ocp/4.15"),
+		},
+		{
+			name: "Skipped image is left untouched",
+			config: &api.ReleaseBuildConfiguration{
+				Images:   []api.ProjectDirectoryImageBuildStepConfiguration{{To: "test-image"}},
+				Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"},
+			},
+			files:                          map[string][]byte{"Dockerfile": []byte("FROM registry.svc.ci.openshift.org/org/repo:tag")},
+			pruneUnusedReplacementsEnabled: true,
+			skippedImages:                  sets.New[string]("org/repo:test-image"),
+		},
 		{
 			name: "Existing base_image is not overwritten",
 			config: &api.ReleaseBuildConfiguration{
@@ -120,6 +204,22 @@ func TestReplacer(t *testing.T) {
 			files:       map[string][]byte{"dockerfile": []byte("COPY --from=registry.svc.ci.openshift.org/org/repo")},
 			expectWrite: true,
 		},
+		{
+			name: "Leaves digest-pinned Copy --from alone",
+			config: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{
+					ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+						DockerfilePath: "dockerfile",
+					},
+				}},
+			},
+			files: map[string][]byte{"dockerfile": []byte(`FROM registry.ci.openshift.org/ocp/builder:rhel-8-golang-1.20 AS builder
+RUN make
+FROM registry.ci.openshift.org/ocp/4.14:base
+COPY --from=builder /go/bin/tool /usr/bin/
+COPY --from=registry.ci.openshift.org/ocp/4.14:cli@sha256:4ad3c9e0ef5b2c4de0a0a41f1b4ae8b3ae2f96d9e1cf2bb54d2d4e1b5a8a7c9d /usr/bin/oc /usr/bin/oc`)},
+			expectWrite: true,
+		},
 		{
 			name: "Different registry, does nothing",
 			config: &api.ReleaseBuildConfiguration{
@@ -228,6 +328,44 @@ func TestReplacer(t *testing.T) {
 			promotionTargetToDockerfileMapping: map[string]dockerfileLocation{fmt.Sprintf("registry.ci.openshift.org/ocp/%s:promotionTarget", majorMinor.String()): {dockerfile: "Dockerfile.rhel"}},
 			expectWrite:                        true,
 		},
+		{
+			name: "Dockerfile gets fixed up when it exists",
+			config: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{
+					ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+						Inputs: map[string]api.ImageBuildInputs{
+							"root": {As: []string{"ocp/builder:something"}},
+						},
+					},
+					To: "promotionTarget",
+				}},
+				PromotionConfiguration: &api.PromotionConfiguration{Targets: []api.PromotionTarget{{Namespace: "ocp", Name: majorMinor.String()}}},
+				Metadata:               api.Metadata{Branch: "master"},
+			},
+			ensureCorrectPromotionDockerfile:   true,
+			verifyDockerfileExists:             true,
+			promotionTargetToDockerfileMapping: map[string]dockerfileLocation{fmt.Sprintf("registry.ci.openshift.org/ocp/%s:promotionTarget", majorMinor.String()): {dockerfile: "Dockerfile.rhel"}},
+			files:                              map[string][]byte{"Dockerfile.rhel": []byte("FROM ocp/builder:something")},
+			expectWrite:                        true,
+		},
+		{
+			name: "Dockerfile that does not exist is not fixed up",
+			config: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{
+					ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+						Inputs: map[string]api.ImageBuildInputs{
+							"root": {As: []string{"ocp/builder:something"}},
+						},
+					},
+					To: "promotionTarget",
+				}},
+				PromotionConfiguration: &api.PromotionConfiguration{Targets: []api.PromotionTarget{{Namespace: "ocp", Name: majorMinor.String()}}},
+				Metadata:               api.Metadata{Branch: "master"},
+			},
+			ensureCorrectPromotionDockerfile:   true,
+			verifyDockerfileExists:             true,
+			promotionTargetToDockerfileMapping: map[string]dockerfileLocation{fmt.Sprintf("registry.ci.openshift.org/ocp/%s:promotionTarget", majorMinor.String()): {dockerfile: "Dockerfile.rhel"}},
+		},
 		{
 			name: "Config for non-master branch is ignored",
 			config: &api.ReleaseBuildConfiguration{
@@ -338,7 +476,7 @@ func TestReplacer(t *testing.T) {
 			},
 			ensureCorrectPromotionDockerfile:   true,
 			promotionTargetToDockerfileMapping: map[string]dockerfileLocation{fmt.Sprintf("registry.svc.ci.openshift.org/ocp/%s:promotionTarget", majorMinor.String()): {contextDir: "some_dir", dockerfile: "Dockerfile.rhel"}},
-			credentials:                        &usernameToken{username: "some-user", token: "some-token"},
+			credentials:                        &githubCredentials{defaultCredentials: &usernameToken{username: "some-user", token: "some-token"}},
 			epectedOpts:                        github.Opts{BasicAuthUser: "some-user", BasicAuthPassword: "some-token"},
 		},
 		{
@@ -384,6 +522,51 @@ func TestReplacer(t *testing.T) {
 			pruneUnusedBaseImagesEnabled: true,
 			expectWrite:                  true,
 		},
+		{
+			name: "Unused base RPM images are pruned",
+			config: &api.ReleaseBuildConfiguration{
+				InputConfiguration: api.InputConfiguration{
+					BaseRPMImages: map[string]api.ImageStreamTagReference{
+						"rpm_injection_target_image": {
+							Name:      "rpm_injection_target_image",
+							Namespace: "namespace",
+							Tag:       "test-1.0",
+						},
+						"rpm_serve_image": {
+							Name:      "rpm_serve_image",
+							Namespace: "namespace",
+							Tag:       "test-1.0",
+						},
+						"unused_rpm_image": {
+							Name:      "unused_rpm_image",
+							Namespace: "namespace",
+							Tag:       "test-1.0",
+						},
+					},
+				},
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{
+					ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+						Inputs: map[string]api.ImageBuildInputs{
+							"image": {As: []string{"org/image"}},
+						},
+					},
+					To: "cool_image",
+				}},
+				RawSteps: []api.StepConfiguration{
+					{RPMImageInjectionStepConfiguration: &api.RPMImageInjectionStepConfiguration{
+						From: "rpm_injection_source_image",
+						To:   "rpm_injection_target_image",
+					}},
+					{RPMServeStepConfiguration: &api.RPMServeStepConfiguration{
+						From: "rpm_serve_image",
+					}},
+				},
+				Metadata: api.Metadata{Branch: "master"},
+			},
+			files:                        map[string][]byte{"Dockerfile": []byte("FROM registry.svc.ci.openshift.org/org/image as image")},
+			pruneUnusedBaseImagesEnabled: true,
+			expectWrite:                  true,
+		},
 		{
 			name: "Used base images not pruned",
 			config: &api.ReleaseBuildConfiguration{
@@ -516,9 +699,15 @@ func TestReplacer(t *testing.T) {
 				tc.pruneUnusedBaseImagesEnabled,
 				true,
 				tc.ensureCorrectPromotionDockerfile,
+				tc.verifyDockerfileExists,
 				tc.ensureCorrectPromotionDockerfileIngoredRepos,
-				tc.promotionTargetToDockerfileMapping,
-				majorMinor,
+				newOCPBuildDataDockerfiles(tc.promotionTargetToDockerfileMapping, majorMinor),
+				tc.credentials,
+				[]*regexp.Regexp{registryRegex},
+				tc.dockerfileNames,
+				tc.multiArchImageStreams,
+				tc.skippedImages,
+				nil,
 				nil,
 				func(config api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error) {
 					return *tc.config, nil
@@ -563,6 +752,100 @@ func fakeGithubFileGetterFactory(data map[string][]byte) (*github.Opts, func(str
 	}
 }
 
+func TestSourceFileGetterFactory(t *testing.T) {
+	githubOpts, githubFactory := fakeGithubFileGetterFactory(map[string][]byte{"Dockerfile": []byte("github")})
+	gitLabOpts := &github.Opts{}
+	var gitLabURL string
+	gitLabFactory := func(baseURL, _, _, _ string, opts ...github.Opt) github.FileGetter {
+		gitLabURL = baseURL
+		for _, opt := range opts {
+			opt(gitLabOpts)
+		}
+		return func(string) ([]byte, error) {
+			return []byte("gitlab"), nil
+		}
+	}
+	factory := sourceFileGetterFactory(githubFactory, gitLabFactory, "https://gitlab.example.com", sets.New[string]("org/mirrored"), &usernameToken{token: "gitlab-token"})
+
+	testCases := []struct {
+		name     string
+		repo     string
+		expected string
+	}{
+		{
+			name:     "repo not hosted on GitLab is fetched from GitHub",
+			repo:     "repo",
+			expected: "github",
+		},
+		{
+			name:     "repo hosted on GitLab is fetched from GitLab",
+			repo:     "mirrored",
+			expected: "gitlab",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := factory("org", tc.repo, "master", github.WithAuthentication("user", "github-token"))("Dockerfile")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, string(data)); diff != "" {
+				t.Errorf("unexpected file content: %s", diff)
+			}
+		})
+	}
+	if githubOpts.BasicAuthPassword != "github-token" {
+		t.Errorf("expected the GitHub token to be passed to GitHub, got %q", githubOpts.BasicAuthPassword)
+	}
+	if gitLabOpts.BasicAuthPassword != "gitlab-token" || gitLabURL != "https://gitlab.example.com" {
+		t.Errorf("expected the GitLab token and URL to be passed to GitLab, got %q and %q", gitLabOpts.BasicAuthPassword, gitLabURL)
+	}
+}
+
+func TestOptionsSelects(t *testing.T) {
+	info := &config.Info{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"}}
+	testCases := []struct {
+		name     string
+		opts     options
+		expected bool
+	}{
+		{
+			name:     "no filter",
+			expected: true,
+		},
+		{
+			name:     "matching org",
+			opts:     options{onlyOrg: "org"},
+			expected: true,
+		},
+		{
+			name: "other org",
+			opts: options{onlyOrg: "other"},
+		},
+		{
+			name:     "matching org and repo",
+			opts:     options{onlyOrg: "org", onlyRepo: "repo"},
+			expected: true,
+		},
+		{
+			name: "matching org and other repo",
+			opts: options{onlyOrg: "org", onlyRepo: "other"},
+		},
+		{
+			name:     "matching repo in any org",
+			opts:     options{onlyRepo: "repo"},
+			expected: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.opts.selects(info); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
 func TestExtractReplacementCandidatesFromDockerfile(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -595,6 +878,16 @@ COPY --from=builder /go/src/github.com/kubernetes-sigs/aws-ebs-csi-driver/bin/aw
 ENTRYPOINT ["/usr/bin/aws-ebs-csi-driver"]`,
 			expectedResult: sets.New[string]("registry.svc.ci.openshift.org/openshift/release:golang-1.13", "builder", "registry.svc.ci.openshift.org/openshift/origin-v4.0:base"),
 		},
+		{
+			name:           "ARG with default in FROM",
+			in:             "ARG BASE=registry.ci.openshift.org/ocp/builder:rhel-8\nFROM ${BASE}",
+			expectedResult: sets.New[string]("${BASE}", "registry.ci.openshift.org/ocp/builder:rhel-8"),
+		},
+		{
+			name:           "ARG without default in FROM",
+			in:             "ARG BASE\nFROM $BASE",
+			expectedResult: sets.New[string]("$BASE"),
+		},
 		{
 			name:           "Missing image alias name",
 			in:             "FROM centos:8 AS",
@@ -605,6 +898,21 @@ ENTRYPOINT ["/usr/bin/aws-ebs-csi-driver"]`,
 			in:             "FROM centos:8 as useless",
 			expectedResult: sets.New("centos:8"),
 		},
+		{
+			name:           "FROM scratch",
+			in:             "FROM registry.ci.openshift.org/ocp/builder:rhel-8 AS builder\nFROM scratch\nCOPY --from=builder /bin/tool /bin/tool",
+			expectedResult: sets.New[string]("registry.ci.openshift.org/ocp/builder:rhel-8", "builder"),
+		},
+		{
+			name: "FROM earlier stage",
+			in: `FROM registry.ci.openshift.org/ocp/builder:rhel-8 AS builder
+RUN make
+FROM builder AS tester
+RUN make test
+FROM registry.ci.openshift.org/ocp/4.16:base
+COPY --from=tester /bin/tool /bin/tool`,
+			expectedResult: sets.New[string]("registry.ci.openshift.org/ocp/builder:rhel-8", "builder", "registry.ci.openshift.org/ocp/4.16:base"),
+		},
 		{
 			name: "Unrelated directives",
 			in:   "RUN somestuff\n\n\n ENV var=val",
@@ -634,6 +942,7 @@ func TestPruneUnusedReplacements(t *testing.T) {
 		name            string
 		in              *api.ReleaseBuildConfiguration
 		allSourceImages sets.Set[string]
+		skippedImages   sets.Set[string]
 		expected        *api.ReleaseBuildConfiguration
 	}{
 		{
@@ -680,6 +989,33 @@ func TestPruneUnusedReplacements(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Replacements of skipped image are kept",
+			in: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{
+					ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+						Inputs: map[string]api.ImageBuildInputs{
+							"builder": {As: []string{"some-image", "superfluous"}},
+						},
+					},
+					To: "test-image",
+				}},
+				Metadata: api.Metadata{Org: "org", Repo: "repo"},
+			},
+			allSourceImages: sets.New[string]("some-image"),
+			skippedImages:   sets.New[string]("org/repo:test-image"),
+			expected: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{
+					ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+						Inputs: map[string]api.ImageBuildInputs{
+							"builder": {As: []string{"some-image", "superfluous"}},
+						},
+					},
+					To: "test-image",
+				}},
+				Metadata: api.Metadata{Org: "org", Repo: "repo"},
+			},
+		},
 		{
 			name: "One input is empty and gets removed",
 			in: &api.ReleaseBuildConfiguration{
@@ -806,7 +1142,7 @@ func TestPruneUnusedReplacements(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			if err := pruneUnusedReplacements(tc.in, tc.allSourceImages); err != nil {
+			if err := pruneUnusedReplacements(tc.in, tc.allSourceImages, tc.skippedImages); err != nil {
 				t.Fatalf("pruneUnusedReplacements failed: %v", err)
 			}
 			if diff := cmp.Diff(tc.in, tc.expected, cmpopts.EquateEmpty(), cmpopts.IgnoreUnexported(api.ProjectDirectoryImageBuildStepConfiguration{})); diff != "" {
@@ -954,3 +1290,278 @@ func TestRegistryRegex(t *testing.T) {
 		})
 	}
 }
+
+func TestFindRegistryReference(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		regexes  []*regexp.Regexp
+		expected string
+	}{
+		{
+			name:     "default regex",
+			line:     "FROM registry.ci.openshift.org/ocp/builder:rhel-8-base-openshift-4.7",
+			regexes:  []*regexp.Regexp{registryRegex},
+			expected: "registry.ci.openshift.org/ocp/builder:rhel-8-base-openshift-4.7",
+		},
+		{
+			name:     "additional regex",
+			line:     "FROM quay.io/openshift/ci:ocp_builder_rhel-8",
+			regexes:  []*regexp.Regexp{registryRegex, regexp.MustCompile(`quay\.io/openshift/\S+`)},
+			expected: "quay.io/openshift/ci:ocp_builder_rhel-8",
+		},
+		{
+			name:    "no match",
+			line:    "FROM quay.io/openshift/ci:ocp_builder_rhel-8",
+			regexes: []*regexp.Regexp{registryRegex},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := findRegistryReference([]byte(tc.line), tc.regexes)
+			if diff := cmp.Diff(tc.expected, string(actual)); diff != "" {
+				t.Errorf("actual does not match expected, diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestReplacerPrintsDiff(t *testing.T) {
+	cfg := &api.ReleaseBuildConfiguration{
+		Images: []api.ProjectDirectoryImageBuildStepConfiguration{{To: "image"}},
+	}
+	_, fileGetter := fakeGithubFileGetterFactory(map[string][]byte{"Dockerfile": []byte("FROM registry.ci.openshift.org/org/repo:tag")})
+	fakeWriter := &fakeWriter{}
+	diffOut := &bytes.Buffer{}
+	if err := replacer(
+		fileGetter,
+		fakeWriter.Write,
+		false,
+		false,
+		false,
+		true,
+		false,
+		false,
+		nil,
+		ocpBuildDataDockerfiles{},
+		nil,
+		[]*regexp.Regexp{registryRegex},
+		nil,
+		nil,
+		nil,
+		diffOut,
+		nil,
+		nil,
+	)(cfg, &config.Info{Filename: "org-repo-master.yaml"}); err != nil {
+		t.Fatalf("replacer failed: %v", err)
+	}
+	if fakeWriter.data != nil {
+		t.Errorf("expected no write, got data: %s", string(fakeWriter.data))
+	}
+	testhelper.CompareWithFixture(t, diffOut.Bytes())
+}
+
+func TestDockerfilePaths(t *testing.T) {
+	testCases := []struct {
+		name            string
+		image           api.ProjectDirectoryImageBuildStepConfiguration
+		dockerfileNames []string
+		expected        []string
+	}{
+		{
+			name:     "default",
+			image:    api.ProjectDirectoryImageBuildStepConfiguration{ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{ContextDir: "images/cli"}},
+			expected: []string{"images/cli/Dockerfile"},
+		},
+		{
+			name:            "additional names",
+			image:           api.ProjectDirectoryImageBuildStepConfiguration{ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{ContextDir: "images/cli"}},
+			dockerfileNames: []string{"Containerfile", "Dockerfile"},
+			expected:        []string{"images/cli/Dockerfile", "images/cli/Containerfile"},
+		},
+		{
+			name:            "dockerfile path takes precedence",
+			image:           api.ProjectDirectoryImageBuildStepConfiguration{ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{ContextDir: "images/cli", DockerfilePath: "Dockerfile.rhel"}},
+			dockerfileNames: []string{"Containerfile"},
+			expected:        []string{"images/cli/Dockerfile.rhel"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, dockerfilePaths(tc.image, tc.dockerfileNames)); diff != "" {
+				t.Errorf("unexpected paths (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestNewOCPBuildDataDockerfiles(t *testing.T) {
+	mapping := map[string]dockerfileLocation{
+		"registry.ci.openshift.org/ocp/4.6:cli":           {dockerfile: "images/cli/Dockerfile.rhel"},
+		"registry.ci.openshift.org/ocp/4.6:tests":         {contextDir: "tests", dockerfile: "Dockerfile.rhel"},
+		"registry.ci.openshift.org/ocp/4.7:cli":           {dockerfile: "images/cli/Dockerfile.rhel7"},
+		"registry.svc.ci.openshift.org/ocp/4.6:installer": {dockerfile: "Dockerfile"},
+	}
+	expected := ocpBuildDataDockerfiles{
+		majorMinor: "4.6",
+		byTag: map[string]dockerfileLocation{
+			"cli":   {dockerfile: "images/cli/Dockerfile.rhel"},
+			"tests": {contextDir: "tests", dockerfile: "Dockerfile.rhel"},
+		},
+	}
+	actual := newOCPBuildDataDockerfiles(mapping, ocpbuilddata.MajorMinor{Major: "4", Minor: "6"})
+	if diff := cmp.Diff(expected, actual, cmp.AllowUnexported(ocpBuildDataDockerfiles{}, dockerfileLocation{})); diff != "" {
+		t.Errorf("unexpected dockerfiles (-want, +got): %s", diff)
+	}
+}
+
+// BenchmarkUpdateDockerfilesToMatchOCPBuildData mimics a sweep over all configs of the
+// openshift/release repository.
+func BenchmarkUpdateDockerfilesToMatchOCPBuildData(b *testing.B) {
+	logrus.SetLevel(logrus.WarnLevel)
+	majorMinor := ocpbuilddata.MajorMinor{Major: "4", Minor: "6"}
+	mapping := map[string]dockerfileLocation{}
+	for i := 0; i < 1000; i++ {
+		mapping[fmt.Sprintf("registry.ci.openshift.org/ocp/%s:image-%d", majorMinor.String(), i)] = dockerfileLocation{dockerfile: "Dockerfile.rhel"}
+	}
+	var configs []*api.ReleaseBuildConfiguration
+	for i := 0; i < 2000; i++ {
+		cfg := &api.ReleaseBuildConfiguration{
+			Metadata:               api.Metadata{Org: "openshift", Repo: fmt.Sprintf("repo-%d", i), Branch: "master"},
+			PromotionConfiguration: &api.PromotionConfiguration{Targets: []api.PromotionTarget{{Namespace: "ocp", Name: majorMinor.String()}}},
+		}
+		for j := 0; j < 5; j++ {
+			cfg.Images = append(cfg.Images, api.ProjectDirectoryImageBuildStepConfiguration{To: api.PipelineImageStreamTagReference(fmt.Sprintf("image-%d", (i*5+j)%1500))})
+		}
+		configs = append(configs, cfg)
+	}
+	ignoredRepos, skippedImages := sets.New[string](), sets.New[string]()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		dockerfiles := newOCPBuildDataDockerfiles(mapping, majorMinor)
+		for _, cfg := range configs {
+			updateDockerfilesToMatchOCPBuildData(cfg, dockerfiles, ignoredRepos, skippedImages, nil)
+		}
+	}
+}
+
+func TestCheckUpToDate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		outOfDate []string
+		expected  string
+	}{
+		{
+			name: "all configs are up to date",
+		},
+		{
+			name:      "out of date configs are listed sorted",
+			outOfDate: []string{"org-repo-master.yaml", "another-repo-master.yaml"},
+			expected:  "2 configs are out of date, run registry-replacer to update them: another-repo-master.yaml, org-repo-master.yaml",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual string
+			if err := checkUpToDate(tc.outOfDate); err != nil {
+				actual = err.Error()
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
+
+func TestGithubCredentialsForOrg(t *testing.T) {
+	defaultCredentials := &usernameToken{username: "user", token: "default"}
+	orgCredentials := &usernameToken{username: "user", token: "org"}
+	credentials := &githubCredentials{defaultCredentials: defaultCredentials, byOrg: map[string]*usernameToken{"other-org": orgCredentials}}
+
+	testCases := []struct {
+		name        string
+		credentials *githubCredentials
+		org         string
+		expected    *usernameToken
+	}{
+		{
+			name:        "org with its own token",
+			credentials: credentials,
+			org:         "other-org",
+			expected:    orgCredentials,
+		},
+		{
+			name:        "org without its own token falls back to the default",
+			credentials: credentials,
+			org:         "openshift",
+			expected:    defaultCredentials,
+		},
+		{
+			name: "no credentials",
+			org:  "openshift",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.credentials.forOrg(tc.org); actual != tc.expected {
+				t.Errorf("expected credentials %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestOrgRepoTagFromPullString(t *testing.T) {
+	const digest = "sha256:4ad3c9e0ef5b2c4de0a0a41f1b4ae8b3ae2f96d9e1cf2bb54d2d4e1b5a8a7c9d"
+	testCases := []struct {
+		name           string
+		pullString     string
+		expected       orgRepoTag
+		expectedString string
+	}{
+		{
+			name:           "tag",
+			pullString:     "registry.ci.openshift.org/ocp/4.14:cli",
+			expected:       orgRepoTag{org: "ocp", repo: "4.14", tag: "cli"},
+			expectedString: "ocp_4.14_cli",
+		},
+		{
+			name:           "no tag defaults to latest",
+			pullString:     "registry.ci.openshift.org/ocp/4.14",
+			expected:       orgRepoTag{org: "ocp", repo: "4.14", tag: "latest"},
+			expectedString: "ocp_4.14_latest",
+		},
+		{
+			name:           "digest",
+			pullString:     "registry.ci.openshift.org/ocp/4.14@" + digest,
+			expected:       orgRepoTag{org: "ocp", repo: "4.14", digest: digest},
+			expectedString: "ocp_4.14_",
+		},
+		{
+			name:           "tag and digest",
+			pullString:     "registry.ci.openshift.org/ocp/4.14:cli@" + digest,
+			expected:       orgRepoTag{org: "ocp", repo: "4.14", tag: "cli", digest: digest},
+			expectedString: "ocp_4.14_cli",
+		},
+		{
+			name:           "registry port is not mistaken for a tag",
+			pullString:     "registry.ci.openshift.org:443/ocp/4.14@" + digest,
+			expected:       orgRepoTag{org: "ocp", repo: "4.14", digest: digest},
+			expectedString: "ocp_4.14_",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := orgRepoTagFromPullString(tc.pullString)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actual, cmp.AllowUnexported(orgRepoTag{})); diff != "" {
+				t.Errorf("result differs from expected: %s", diff)
+			}
+			if actual.String() != tc.expectedString {
+				t.Errorf("expected string %q, got %q", tc.expectedString, actual.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// prunedReplacement is an `as` entry of an image input that got pruned
+type prunedReplacement struct {
+	Image string `json:"image"`
+	Input string `json:"input"`
+	As    string `json:"as"`
+}
+
+// pruneReportEntry holds everything that got pruned from a single config
+type pruneReportEntry struct {
+	Config        string              `json:"config"`
+	Replacements  []prunedReplacement `json:"replacements,omitempty"`
+	BaseImages    []string            `json:"base_images,omitempty"`
+	BaseRPMImages []string            `json:"base_rpm_images,omitempty"`
+}
+
+func (e pruneReportEntry) empty() bool {
+	return len(e.Replacements) == 0 && len(e.BaseImages) == 0 && len(e.BaseRPMImages) == 0
+}
+
+// pruneReport accumulates what got pruned from the configs. The replacer runs
+// concurrently for many configs, so all access is guarded by the lock.
+type pruneReport struct {
+	lock    sync.Mutex
+	entries map[string]pruneReportEntry
+}
+
+func newPruneReport() *pruneReport {
+	return &pruneReport{entries: map[string]pruneReportEntry{}}
+}
+
+// record adds everything that is in the original config but not in the updated one to the report
+func (r *pruneReport) record(filename string, original, updated *api.ReleaseBuildConfiguration) {
+	if r == nil {
+		return
+	}
+	entry := prunedFrom(original, updated)
+	if entry.empty() {
+		return
+	}
+	entry.Config = filename
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.entries[filename] = entry
+}
+
+func prunedFrom(original, updated *api.ReleaseBuildConfiguration) pruneReportEntry {
+	remaining := sets.New[prunedReplacement]()
+	for _, image := range updated.Images {
+		for input, inputs := range image.Inputs {
+			for _, as := range inputs.As {
+				remaining.Insert(prunedReplacement{Image: string(image.To), Input: input, As: as})
+			}
+		}
+	}
+	var entry pruneReportEntry
+	for _, image := range original.Images {
+		for input, inputs := range image.Inputs {
+			for _, as := range inputs.As {
+				if replacement := (prunedReplacement{Image: string(image.To), Input: input, As: as}); !remaining.Has(replacement) {
+					entry.Replacements = append(entry.Replacements, replacement)
+				}
+			}
+		}
+	}
+	sort.Slice(entry.Replacements, func(i, j int) bool {
+		a, b := entry.Replacements[i], entry.Replacements[j]
+		if a.Image != b.Image {
+			return a.Image < b.Image
+		}
+		if a.Input != b.Input {
+			return a.Input < b.Input
+		}
+		return a.As < b.As
+	})
+	entry.BaseImages = sets.List(sets.KeySet(original.BaseImages).Difference(sets.KeySet(updated.BaseImages)))
+	entry.BaseRPMImages = sets.List(sets.KeySet(original.BaseRPMImages).Difference(sets.KeySet(updated.BaseRPMImages)))
+	return entry
+}
+
+// sortedEntries returns the entries of the report, sorted by config
+func (r *pruneReport) sortedEntries() []pruneReportEntry {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entries := []pruneReportEntry{}
+	for _, filename := range sets.List(sets.KeySet(r.entries)) {
+		entries = append(entries, r.entries[filename])
+	}
+	return entries
+}
+
+// write writes the report to path, as CSV if it has a .csv extension and as JSON otherwise
+func (r *pruneReport) write(path string) error {
+	var raw []byte
+	var err error
+	if filepath.Ext(path) == ".csv" {
+		raw, err = r.csv()
+	} else {
+		raw, err = json.MarshalIndent(r.sortedEntries(), "", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to serialize the report: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		return fmt.Errorf("failed to write the report to %s: %w", path, err)
+	}
+	return nil
+}
+
+// csv serializes the report with one row per pruned item
+func (r *pruneReport) csv() ([]byte, error) {
+	records := [][]string{{"config", "kind", "image", "input", "name"}}
+	for _, entry := range r.sortedEntries() {
+		for _, replacement := range entry.Replacements {
+			records = append(records, []string{entry.Config, "replacement", replacement.Image, replacement.Input, replacement.As})
+		}
+		for _, name := range entry.BaseImages {
+			records = append(records, []string{entry.Config, "base_image", "", "", name})
+		}
+		for _, name := range entry.BaseRPMImages {
+			records = append(records, []string{entry.Config, "base_rpm_image", "", "", name})
+		}
+	}
+	buf := &bytes.Buffer{}
+	if err := csv.NewWriter(buf).WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestPrunedFrom(t *testing.T) {
+	original := &api.ReleaseBuildConfiguration{
+		InputConfiguration: api.InputConfiguration{
+			BaseImages: map[string]api.ImageStreamTagReference{
+				"base":   {Namespace: "ocp", Name: "4.16", Tag: "base"},
+				"unused": {Namespace: "ocp", Name: "4.16", Tag: "unused"},
+			},
+			BaseRPMImages: map[string]api.ImageStreamTagReference{
+				"rpms": {Namespace: "ocp", Name: "4.16", Tag: "rpms"},
+			},
+		},
+		Images: []api.ProjectDirectoryImageBuildStepConfiguration{
+			{
+				To: "first",
+				ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+					Inputs: map[string]api.ImageBuildInputs{
+						"base":        {As: []string{"registry.ci.openshift.org/ocp/4.16:base", "registry.ci.openshift.org/ocp/4.15:base"}},
+						"ocp_builder": {As: []string{"registry.ci.openshift.org/ocp/builder:rhel-9-golang-1.21-openshift-4.16"}},
+					},
+				},
+			},
+			{
+				To: "second",
+				ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+					Inputs: map[string]api.ImageBuildInputs{
+						"base": {As: []string{"registry.ci.openshift.org/ocp/4.16:base"}},
+					},
+				},
+			},
+		},
+	}
+	updated := original.DeepCopy()
+	delete(updated.BaseImages, "unused")
+	updated.BaseRPMImages = nil
+	updated.Images[0].Inputs = map[string]api.ImageBuildInputs{
+		"base": {As: []string{"registry.ci.openshift.org/ocp/4.16:base"}},
+	}
+
+	expected := pruneReportEntry{
+		Replacements: []prunedReplacement{
+			{Image: "first", Input: "base", As: "registry.ci.openshift.org/ocp/4.15:base"},
+			{Image: "first", Input: "ocp_builder", As: "registry.ci.openshift.org/ocp/builder:rhel-9-golang-1.21-openshift-4.16"},
+		},
+		BaseImages:    []string{"unused"},
+		BaseRPMImages: []string{"rpms"},
+	}
+	if diff := cmp.Diff(expected, prunedFrom(original, updated)); diff != "" {
+		t.Errorf("unexpected pruned items: %s", diff)
+	}
+	if entry := prunedFrom(original, original); !entry.empty() {
+		t.Errorf("expected nothing to be pruned from an unchanged config, got %v", entry)
+	}
+}
+
+func TestPruneReportWrite(t *testing.T) {
+	report := newPruneReport()
+	original := &api.ReleaseBuildConfiguration{
+		InputConfiguration: api.InputConfiguration{
+			BaseImages: map[string]api.ImageStreamTagReference{"unused": {Namespace: "ocp", Name: "4.16", Tag: "unused"}},
+		},
+		Images: []api.ProjectDirectoryImageBuildStepConfiguration{{
+			To: "image",
+			ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+				Inputs: map[string]api.ImageBuildInputs{"base": {As: []string{"registry.ci.openshift.org/ocp/4.15:base"}}},
+			},
+		}},
+	}
+	pruned := &api.ReleaseBuildConfiguration{Images: []api.ProjectDirectoryImageBuildStepConfiguration{{To: "image"}}}
+	// recorded out of order, as the replacer runs concurrently
+	report.record("org-repo-master.yaml", original, pruned)
+	report.record("unchanged-org-repo-master.yaml", original, original)
+	report.record("another-repo-master.yaml", original, pruned)
+
+	testCases := []struct {
+		name     string
+		filename string
+		expected string
+	}{
+		{
+			name:     "JSON",
+			filename: "report.json",
+			expected: `[
+  {
+    "config": "another-repo-master.yaml",
+    "replacements": [
+      {
+        "image": "image",
+        "input": "base",
+        "as": "registry.ci.openshift.org/ocp/4.15:base"
+      }
+    ],
+    "base_images": [
+      "unused"
+    ]
+  },
+  {
+    "config": "org-repo-master.yaml",
+    "replacements": [
+      {
+        "image": "image",
+        "input": "base",
+        "as": "registry.ci.openshift.org/ocp/4.15:base"
+      }
+    ],
+    "base_images": [
+      "unused"
+    ]
+  }
+]`,
+		},
+		{
+			name:     "CSV",
+			filename: "report.csv",
+			expected: `config,kind,image,input,name
+another-repo-master.yaml,replacement,image,base,registry.ci.openshift.org/ocp/4.15:base
+another-repo-master.yaml,base_image,,,unused
+org-repo-master.yaml,replacement,image,base,registry.ci.openshift.org/ocp/4.15:base
+org-repo-master.yaml,base_image,,,unused
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.filename)
+			if err := report.write(path); err != nil {
+				t.Fatalf("failed to write report: %v", err)
+			}
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read report: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, string(raw)); diff != "" {
+				t.Errorf("unexpected report: %s", diff)
+			}
+		})
+	}
+}
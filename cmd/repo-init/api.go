@@ -18,7 +18,9 @@ import (
 
 	"github.com/ghodss/yaml"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/robfig/cron.v2"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	prowConfig "sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/flagutil"
 	"sigs.k8s.io/prow/pkg/interrupts"
@@ -116,6 +118,7 @@ func serveAPI(port, healthPort, numRepos int, ghOptions flagutil.GitHubOptions,
 			l("configs"),
 			l("config-validations"),
 			l("server-configs"),
+			l("validate"),
 		),
 	))
 
@@ -127,6 +130,7 @@ func serveAPI(port, healthPort, numRepos int, ghOptions flagutil.GitHubOptions,
 	mux.HandleFunc("/api/configs", handler(s.configHandler()).ServeHTTP)
 	mux.HandleFunc("/api/config-validations", handler(s.configValidationHandler()).ServeHTTP)
 	mux.HandleFunc("/api/server-configs", handler(s.serverConfigHandler()).ServeHTTP)
+	mux.HandleFunc("/api/validate", handler(s.initConfigValidationHandler()).ServeHTTP)
 	httpServer := &http.Server{Addr: ":" + strconv.Itoa(port), Handler: mux}
 	interrupts.ListenAndServe(httpServer, 5*time.Second)
 	s.logger.Debug("Ready to serve HTTP requests.")
@@ -291,6 +295,20 @@ func (s *server) configValidationHandler() http.HandlerFunc {
 	}
 }
 
+func (s *server) initConfigValidationHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.disableCORS(w)
+		switch r.Method {
+		case http.MethodPost:
+			s.validateInitConfig(w, r)
+		case http.MethodOptions:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}
+}
+
 func (s *server) configHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		s.disableCORS(w)
@@ -368,7 +386,13 @@ func (s server) loadConfigs(w http.ResponseWriter, r *http.Request) {
 	releaseRepo := availableRepo.path
 
 	//TODO(smg247): note, this generates an error inside that isn't really an error in this case. We need to make sure this is being filtered
-	configs, err := config.LoadByOrgRepo(getConfigPath(org, repo, releaseRepo))
+	clusterProfiles, err := config.LoadClusterProfiles(releaseRepo)
+	if err != nil {
+		s.logger.WithError(err).Error("Error while loading the cluster profiles")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	configs, err := config.LoadByOrgRepo(getConfigPath(org, repo, releaseRepo), clusterProfiles)
 
 	if err != nil {
 		s.logger.WithError(err).Error("Error while loading configs")
@@ -462,7 +486,7 @@ func (s server) validateConfig(w http.ResponseWriter, r *http.Request) {
 
 		switch validationType {
 		case All:
-			if err := validation.IsValidConfiguration(generated, configRequest.Config.Org, configRequest.Config.Repo); err != nil {
+			if err := validation.IsValidConfiguration(generated, configRequest.Config.Org, configRequest.Config.Repo, nil); err != nil {
 				validationErrors = append(validationErrors, err)
 			}
 		case BaseImages:
@@ -549,6 +573,123 @@ func (s server) validateConfig(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(marshalled)
 }
 
+// validateInitConfig runs the checks the interactive CLI performs on its input against the provided initConfig,
+// without assigning a copy of the release repo to the user or writing anything.
+func (s server) validateInitConfig(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithField("handler", "initConfigValidationHandler")
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		logger.WithError(err).Error("Error while reading request body")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var config initConfig
+	if err := json.Unmarshal(body, &config); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.WithError(err).Error("unable to unmarshal request")
+		_, _ = w.Write([]byte("Invalid validation request"))
+		return
+	}
+
+	// the existing configuration is looked up in an idle copy of the release repo, which is only read
+	response := validationResponse{Valid: true}
+	checked := false
+	inspect := func(releaseRepo string) {
+		response.ValidationErrors = initConfigErrors(config, releaseRepo)
+		checked = true
+	}
+	if s.rm != nil {
+		if err := s.rm.inspectAvailable(inspect); err != nil {
+			logger.WithError(err).Warn("Could not check for an existing configuration")
+		}
+	}
+	switch {
+	case !checked:
+		// the other checks still run, but the configuration can not be reported as valid
+		response.ValidationErrors = initConfigErrors(config, "")
+		response.Valid = false
+		response.Message = "could not check whether a configuration already exists for the repository, please try again later"
+		if len(response.ValidationErrors) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	case len(response.ValidationErrors) > 0:
+		response.Valid = false
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+	marshalled, err := json.Marshal(response)
+	if err != nil {
+		logger.WithError(err).Error("Failed to marshal validation errors")
+	}
+	_, _ = w.Write(marshalled)
+}
+
+// initConfigErrors returns a field-level error for every check that the interactive CLI would not let pass.
+// The existing configuration is only detected when releaseRepo is set.
+func initConfigErrors(config initConfig, releaseRepo string) []validationError {
+	var errs []validationError
+	if config.Org == "" {
+		errs = append(errs, validationError{Field: "org", Message: "an organization must be provided"})
+	}
+	if config.Repo == "" {
+		errs = append(errs, validationError{Field: "repo", Message: "a repository must be provided"})
+	}
+	if config.Org != "" && config.Repo != "" && releaseRepo != "" && configExists(config.Org, config.Repo, releaseRepo) {
+		errs = append(errs, validationError{Field: "repo", Message: fmt.Sprintf("configuration for %s/%s already exists", config.Org, config.Repo)})
+	}
+
+	names := sets.New[string]()
+	checkName := func(field, name string) {
+		if names.Has(name) {
+			errs = append(errs, validationError{Field: field, Message: fmt.Sprintf("a test named %q already exists, please choose a different name", name)})
+		}
+		names.Insert(name)
+	}
+	for i, test := range config.Tests {
+		checkName(fmt.Sprintf("tests[%d].as", i), test.As)
+	}
+	for i, test := range config.CustomE2E {
+		checkName(fmt.Sprintf("custom_e2e[%d].as", i), test.As)
+	}
+	for i, test := range config.Periodics {
+		checkName(fmt.Sprintf("periodics[%d].as", i), test.As)
+	}
+
+	needsCluster := len(config.CustomE2E) > 0
+	for i, test := range config.CustomE2E {
+		if clusterProfiles[test.Profile] == "" {
+			errs = append(errs, validationError{Field: fmt.Sprintf("custom_e2e[%d].profile", i), Message: fmt.Sprintf("cluster profile %q is not valid, please choose one from: %s", test.Profile, clusterProfileList)})
+		}
+	}
+	for i, test := range config.Periodics {
+		if test.Cron != "" {
+			if _, err := cron.Parse(test.Cron); err != nil {
+				errs = append(errs, validationError{Field: fmt.Sprintf("periodics[%d].cron", i), Message: fmt.Sprintf("cron expression %q is not valid: %v", test.Cron, err)})
+			}
+		}
+		if test.Profile == "" {
+			continue
+		}
+		needsCluster = true
+		if clusterProfiles[test.Profile] == "" {
+			errs = append(errs, validationError{Field: fmt.Sprintf("periodics[%d].profile", i), Message: fmt.Sprintf("cluster profile %q is not valid, please choose one from: %s", test.Profile, clusterProfileList)})
+		}
+	}
+
+	validFormatted := strings.Join(sets.List(validReleaseTypes), ", ")
+	switch {
+	case config.ReleaseType != "" && !validReleaseTypes.Has(config.ReleaseType):
+		errs = append(errs, validationError{Field: "release_type", Message: fmt.Sprintf("unexpected release type %q, please choose one from: [%s]", config.ReleaseType, validFormatted)})
+	case config.ReleaseType == "" && needsCluster && !config.Promotes:
+		errs = append(errs, validationError{Field: "release_type", Message: fmt.Sprintf("a release type is required for tests that run on a cluster, please choose one from: [%s]", validFormatted)})
+	}
+	return errs
+}
+
 func getConfigPath(org, repo, releaseRepo string) string {
 	pathElements := []string{releaseRepo, "ci-operator", "config", org}
 	if repo != "" {
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -96,10 +98,10 @@ func TestConfigValidation(t *testing.T) {
 						Branch:                "branch",
 						CanonicalGoRepository: "sometimes.com",
 						GoVersion:             "1",
-						OperatorBundle: &operatorBundle{
+						OperatorBundles: []operatorBundle{{
 							DockerfilePath: "Dockerfile.bundle",
 							Name:           "ci-index",
-						},
+						}},
 					},
 				},
 				Substitution: api.PullSpecSubstitution{
@@ -122,10 +124,10 @@ func TestConfigValidation(t *testing.T) {
 						Branch:                "branch",
 						CanonicalGoRepository: "sometimes.com",
 						GoVersion:             "1",
-						OperatorBundle: &operatorBundle{
+						OperatorBundles: []operatorBundle{{
 							DockerfilePath: "Dockerfile.bundle",
 							Name:           "ci-index",
-						},
+						}},
 					},
 				},
 				Substitution: api.PullSpecSubstitution{
@@ -175,6 +177,212 @@ func TestConfigValidation(t *testing.T) {
 
 }
 
+func TestInitConfigValidation(t *testing.T) {
+	releaseRepo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(releaseRepo, "ci-operator", "config", "org", "existing"), 0755); err != nil {
+		t.Fatalf("could not create existing config dir: %v", err)
+	}
+
+	testCases := []struct {
+		name   string
+		config initConfig
+
+		expectedStatus int
+		expected       *validationResponse
+	}{
+		{
+			name: "valid config",
+			config: initConfig{
+				Org:         "org",
+				Repo:        "repo",
+				CustomE2E:   []e2eTest{{As: "e2e", Command: "make e2e", Profile: "aws"}},
+				Periodics:   []periodicTest{{As: "nightly", Command: "make nightly", Cron: "@daily"}},
+				ReleaseType: "nightly",
+			},
+			expectedStatus: http.StatusOK,
+			expected:       &validationResponse{Valid: true},
+		},
+		{
+			name:           "missing org and repo",
+			config:         initConfig{},
+			expectedStatus: http.StatusBadRequest,
+			expected: &validationResponse{
+				ValidationErrors: []validationError{
+					{Field: "org", Message: "an organization must be provided"},
+					{Field: "repo", Message: "a repository must be provided"},
+				},
+			},
+		},
+		{
+			name:           "config already exists",
+			config:         initConfig{Org: "org", Repo: "existing"},
+			expectedStatus: http.StatusBadRequest,
+			expected: &validationResponse{
+				ValidationErrors: []validationError{
+					{Field: "repo", Message: "configuration for org/existing already exists"},
+				},
+			},
+		},
+		{
+			name: "invalid profiles and release type",
+			config: initConfig{
+				Org:         "org",
+				Repo:        "repo",
+				CustomE2E:   []e2eTest{{As: "e2e", Command: "make e2e", Profile: "aws"}, {As: "e2e-other", Command: "make e2e", Profile: "openstack"}},
+				Periodics:   []periodicTest{{As: "nightly", Command: "make nightly", Cron: "@daily", Profile: "vsphere"}},
+				ReleaseType: "stable",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expected: &validationResponse{
+				ValidationErrors: []validationError{
+					{Field: "custom_e2e[1].profile", Message: `cluster profile "openstack" is not valid, please choose one from: [aws azure gcp]`},
+					{Field: "periodics[0].profile", Message: `cluster profile "vsphere" is not valid, please choose one from: [aws azure gcp]`},
+					{Field: "release_type", Message: `unexpected release type "stable", please choose one from: [nightly, published]`},
+				},
+			},
+		},
+		{
+			name: "duplicate test names and invalid cron",
+			config: initConfig{
+				Org:       "org",
+				Repo:      "repo",
+				Tests:     []test{{As: "unit", Command: "make test"}},
+				CustomE2E: []e2eTest{{As: "unit", Command: "make e2e", Profile: "aws"}},
+				Periodics: []periodicTest{
+					{As: "nightly", Command: "make nightly", Cron: "not a cron"},
+					{As: "nightly", Command: "make nightly", Interval: "24h"},
+				},
+				ReleaseType: "nightly",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expected: &validationResponse{
+				ValidationErrors: []validationError{
+					{Field: "custom_e2e[0].as", Message: `a test named "unit" already exists, please choose a different name`},
+					{Field: "periodics[1].as", Message: `a test named "nightly" already exists, please choose a different name`},
+					{Field: "periodics[0].cron", Message: `cron expression "not a cron" is not valid: Expected 5 or 6 fields, found 3: not a cron`},
+				},
+			},
+		},
+		{
+			name: "missing release type for tests on a cluster",
+			config: initConfig{
+				Org:       "org",
+				Repo:      "repo",
+				Periodics: []periodicTest{{As: "nightly", Command: "make nightly", Cron: "@daily", Profile: "gcp"}},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expected: &validationResponse{
+				ValidationErrors: []validationError{
+					{Field: "release_type", Message: "a release type is required for tests that run on a cluster, please choose one from: [nightly, published]"},
+				},
+			},
+		},
+		{
+			name: "promoting repos do not need a release type",
+			config: initConfig{
+				Org:       "org",
+				Repo:      "repo",
+				Promotes:  true,
+				CustomE2E: []e2eTest{{As: "e2e", Command: "make e2e", Profile: "azure"}},
+			},
+			expectedStatus: http.StatusOK,
+			expected:       &validationResponse{Valid: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			marshalled, _ := json.Marshal(tc.config)
+			r, err := http.NewRequest(http.MethodPost, "wordup.com", bytes.NewBuffer(marshalled))
+			if err != nil {
+				t.Fatalf("could not make request: %v", err)
+			}
+
+			writer := &fakeWriter{}
+			var synced int
+			s := server{
+				logger: logrus.WithField("component", "repo-init-api"),
+				rm: &repoManager{
+					availableRepos: []*repo{{path: releaseRepo}},
+					sync: func(*repo) error {
+						synced++
+						return nil
+					},
+				},
+			}
+			s.initConfigValidationHandler()(writer, r)
+
+			if writer.status != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, writer.status)
+			}
+			actual := &validationResponse{}
+			_ = json.Unmarshal(writer.body, actual)
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("got invalid response: %v", diff)
+			}
+			if len(s.rm.inUseRepos) != 0 {
+				t.Errorf("expected no repos to be locked, got %v", s.rm.inUseRepos)
+			}
+			if synced != 0 {
+				t.Errorf("expected the repo not to be synced for the lookup, got %d syncs", synced)
+			}
+		})
+	}
+}
+
+func TestInitConfigValidationHandlerWithoutAvailableRepo(t *testing.T) {
+	testCases := []struct {
+		name           string
+		config         initConfig
+		expectedStatus int
+		expected       *validationResponse
+	}{
+		{
+			name:           "valid config can not be reported as valid",
+			config:         initConfig{Org: "org", Repo: "repo"},
+			expectedStatus: http.StatusServiceUnavailable,
+			expected: &validationResponse{
+				Message: "could not check whether a configuration already exists for the repository, please try again later",
+			},
+		},
+		{
+			name:           "other errors are still reported",
+			config:         initConfig{Org: "org"},
+			expectedStatus: http.StatusBadRequest,
+			expected: &validationResponse{
+				Message:          "could not check whether a configuration already exists for the repository, please try again later",
+				ValidationErrors: []validationError{{Field: "repo", Message: "a repository must be provided"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			marshalled, _ := json.Marshal(tc.config)
+			r, err := http.NewRequest(http.MethodPost, "wordup.com", bytes.NewBuffer(marshalled))
+			if err != nil {
+				t.Fatalf("could not make request: %v", err)
+			}
+
+			writer := &fakeWriter{}
+			s := server{
+				logger: logrus.WithField("component", "repo-init-api"),
+				rm:     &repoManager{inUseRepos: []*repo{{path: "/nonexistent", inUseBy: "someone"}}},
+			}
+			s.initConfigValidationHandler()(writer, r)
+
+			if writer.status != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, writer.status)
+			}
+			actual := &validationResponse{}
+			_ = json.Unmarshal(writer.body, actual)
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("got invalid response: %v", diff)
+			}
+		})
+	}
+}
+
 type fakeWriter struct {
 	status int
 	body   []byte
@@ -14,11 +14,13 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
+	"gopkg.in/robfig/cron.v2"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 	prowconfig "sigs.k8s.io/prow/pkg/config"
@@ -44,6 +46,8 @@ type options struct {
 
 	releaseRepo   string
 	config        string
+	configFile    string
+	force         bool
 	disableCors   bool
 	GitHubOptions flagutil.GitHubOptions
 }
@@ -74,6 +78,9 @@ func (o *options) Validate() error {
 		if o.releaseRepo == "" {
 			return errors.New("--release-repo is required")
 		}
+		if o.config != "" && o.configFile != "" {
+			return errors.New("--config and --config-file are mutually exclusive")
+		}
 	default:
 		return errors.New("--mode must be either \"server\", \"ui\", or \"cli\"")
 	}
@@ -93,8 +100,13 @@ func (o *options) Validate() error {
 const (
 	logStyleJson = "json"
 	logStyleText = "text"
+
+	// defaultGoVersion is used when the Go version cannot be determined from openshift/origin
+	defaultGoVersion = "1.13"
 )
 
+var golangTagRegex = regexp.MustCompile(`golang-(\d+\.\d+(\.\d+)?)`)
+
 var (
 	// Valid cluster profile options and their associated workflows.
 	// This is supposed to be a simple, non-exhaustive list of profiles that are
@@ -111,6 +123,8 @@ var (
 		sort.Slice(ret, func(i, j int) bool { return ret[i] < ret[j] })
 		return
 	}()
+	// Valid types of OpenShift release that end-to-end tests can run on top of.
+	validReleaseTypes = sets.New[string]("nightly", "published")
 )
 
 func gatherOptions() options {
@@ -120,6 +134,8 @@ func gatherOptions() options {
 	fs.StringVar(&o.mode, "mode", "cli", "Whether to run the repo initializer as an interactive cli, a standalone server, or in ui mode.")
 	fs.StringVar(&o.releaseRepo, "release-repo", "", "Path to the root of the openshift/release repository.")
 	fs.StringVar(&o.config, "config", "", "JSON configuration to use instead of the interactive mode.")
+	fs.StringVar(&o.configFile, "config-file", "", "Path to a YAML or JSON configuration file to use instead of the interactive mode.")
+	fs.BoolVar(&o.force, "force", false, "Update the Prow configuration even if it already references the repository.")
 	fs.StringVar(&o.loglevel, "loglevel", "debug", "Logging level.")
 	fs.StringVar(&o.logStyle, "log-style", "json", "Logging style: json or text.")
 	fs.IntVar(&o.port, "port", 0, "Port to run on if in server mode.")
@@ -150,9 +166,27 @@ type initConfig struct {
 	TestBuildCommands     string                                            `json:"test_build_commands"`
 	Tests                 []test                                            `json:"tests"`
 	CustomE2E             []e2eTest                                         `json:"custom_e2e"`
+	Periodics             []periodicTest                                    `json:"periodics"`
 	ReleaseType           string                                            `json:"release_type"`
 	ReleaseVersion        string                                            `json:"release_version"`
-	OperatorBundle        *operatorBundle                                   `json:"operator_bundle"`
+	OperatorBundles       []operatorBundle                                  `json:"operator_bundles"`
+}
+
+// UnmarshalJSON folds the deprecated singular operator_bundle into the operator bundles
+func (c *initConfig) UnmarshalJSON(data []byte) error {
+	type plainConfig initConfig
+	var raw struct {
+		plainConfig
+		OperatorBundle *operatorBundle `json:"operator_bundle"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = initConfig(raw.plainConfig)
+	if raw.OperatorBundle != nil {
+		c.OperatorBundles = append([]operatorBundle{*raw.OperatorBundle}, c.OperatorBundles...)
+	}
+	return nil
 }
 
 type test struct {
@@ -172,6 +206,17 @@ type e2eTest struct {
 	Dependencies api.TestDependencies      `dependencies:"dependencies"`
 }
 
+// periodicTest runs on a schedule instead of on pull requests. When a cluster
+// profile or workflow is set, it runs against an ephemeral cluster like an e2e test.
+type periodicTest struct {
+	As       string             `json:"as"`
+	Cron     string             `json:"cron"`
+	Interval string             `json:"interval"`
+	Command  string             `json:"command"`
+	Profile  api.ClusterProfile `json:"profile"`
+	Workflow string             `json:"workflow"`
+}
+
 type operatorBundle struct {
 	Name             string                     `json:"name"`
 	DockerfilePath   string                     `json:"dockerfile_path"`
@@ -214,6 +259,19 @@ func mainUI(o options) {
 	interrupts.WaitForGracefulShutdown()
 }
 
+// loadConfigFile reads the configuration from a YAML or JSON file
+func loadConfigFile(path string) (initConfig, error) {
+	var config initConfig
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("could not read configuration file: %w", err)
+	}
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		return config, fmt.Errorf("could not unmarshal configuration file %s: %w", path, err)
+	}
+	return config, nil
+}
+
 func mainCli(o options) {
 	go func() {
 		interrupts.WaitForGracefulShutdown()
@@ -228,6 +286,12 @@ In order to generate a new set of configurations, some information will be neces
 		if err := json.Unmarshal([]byte(o.config), &config); err != nil {
 			errorExit(fmt.Sprintf("could not unmarshal provided configuration: %v", err))
 		}
+	} else if o.configFile != "" {
+		fmt.Printf("Loading configuration from %s ...\n", o.configFile)
+		var err error
+		if config, err = loadConfigFile(o.configFile); err != nil {
+			errorExit(err.Error())
+		}
 	} else {
 		fmt.Println(`
 Let's start with general information about the repository...`)
@@ -251,7 +315,13 @@ Now, let's determine how the repository builds output artifacts...`)
 
 		fmt.Println(`
 Now, let's configure how the repository is compiled...`)
-		config.GoVersion = fetchOrDefaultWithPrompt("What version of Go does the repository build with?", "1.13")
+		goVersion := defaultGoVersion
+		if originConfig, err := loadOriginConfig(o.releaseRepo); err != nil {
+			logrus.WithError(err).Warn("Could not determine the Go version used by openshift/origin.")
+		} else if originGoVersion := goVersionFromBuildRoot(originConfig); originGoVersion != "" {
+			goVersion = originGoVersion
+		}
+		config.GoVersion = fetchOrDefaultWithPrompt("What version of Go does the repository build with?", goVersion)
 		config.CanonicalGoRepository = fetchOrDefaultWithPrompt("[OPTIONAL] Enter the Go import path for the repository if it uses a vanity URL (e.g. \"k8s.io/my-repo\"):", "")
 		config.BuildCommands = fetchOrDefaultWithPrompt("[OPTIONAL] What commands are used to build binaries in the repository? (e.g. \"go install ./cmd/...\")", "")
 		config.TestBuildCommands = fetchOrDefaultWithPrompt("[OPTIONAL] What commands are used to build test binaries? (e.g. \"go install -race ./cmd/...\" or \"go test -c ./test/...\")", "")
@@ -356,12 +426,72 @@ A test named %s already exists. Please choose a different name.\n`, test.As)
 		}
 
 		config.CustomE2E = e2eTests
-		if len(config.CustomE2E) > 0 && !config.Promotes {
-			valid := sets.New[string]("nightly", "published")
-			validFormatted := strings.Join(sets.List(valid), ", ")
+
+		var periodics []periodicTest
+		for {
+			more := ""
+			detail := `
+Next, we will configure periodic tests. A periodic test
+runs on a schedule, e.g. nightly, instead of on every pull
+request. It may optionally run against an ephemeral cluster.
+
+`
+			if len(periodics) > 0 {
+				more = "more "
+				detail = ""
+			}
+			if !fetchBoolWithPrompt(fmt.Sprintf("%sAre there any %speriodic tests to configure? ", detail, more)) {
+				break
+			}
+			var test periodicTest
+			test.As = fetchWithPrompt("What is the name of this test (e.g. \"nightly\")? ")
+			for {
+				if names.Has(test.As) {
+					fmt.Printf(`
+A test named %s already exists. Please choose a different name.\n`, test.As)
+					test.As = fetchWithPrompt("What is the name of this test (e.g. \"nightly\")? ")
+				} else {
+					names.Insert(test.As)
+					break
+				}
+			}
+
+			test.Cron = fetchOrDefaultWithPrompt("When should the test run? Enter a cron expression:", "@daily")
+			for {
+				if _, err := cron.Parse(test.Cron); err != nil {
+					fmt.Printf("Cron expression %q is not valid: %v.\n", test.Cron, err)
+					test.Cron = fetchOrDefaultWithPrompt("When should the test run? Enter a cron expression:", "@daily")
+				} else {
+					break
+				}
+			}
+
+			test.Profile = api.ClusterProfile(fetchOrDefaultWithPrompt("[OPTIONAL] Which cloud provider does the test require a cluster on, if any? ", ""))
+			for {
+				if test.Profile != "" && clusterProfiles[test.Profile] == "" {
+					fmt.Printf("Cluster profile %s is not valid. Please choose one from: %s.\n", test.Profile, clusterProfileList)
+					test.Profile = api.ClusterProfile(fetchOrDefaultWithPrompt("[OPTIONAL] Which cloud provider does the test require a cluster on, if any? ", ""))
+				} else {
+					break
+				}
+			}
+			test.Command = fetchWithPrompt("What commands in the repository run the test (e.g. \"make test-nightly\")? ")
+
+			periodics = append(periodics, test)
+		}
+		config.Periodics = periodics
+
+		needsCluster := len(config.CustomE2E) > 0
+		for _, test := range config.Periodics {
+			if test.Profile != "" {
+				needsCluster = true
+			}
+		}
+		if needsCluster && !config.Promotes {
+			validFormatted := strings.Join(sets.List(validReleaseTypes), ", ")
 			releaseType := fetchWithPrompt(fmt.Sprintf("What type of OpenShift release do the end-to-end tests run on top of? [%s]", validFormatted))
 			for {
-				if !valid.Has(releaseType) {
+				if !validReleaseTypes.Has(releaseType) {
 					fmt.Printf(`
 Unexpected release type %q. Please choose one from: [%v].\n`, releaseType, validFormatted)
 					releaseType = fetchWithPrompt(fmt.Sprintf("What type of OpenShift release do the end-to-end tests run on top of? [%s]", validFormatted))
@@ -386,6 +516,20 @@ create this run without using the interactive interface:
 %s --config=%q
 `, strings.Join(os.Args, " "), string(marshalled))
 
+	if !o.force {
+		existing, err := existingProwConfigForRepo(config, o.releaseRepo)
+		if err != nil {
+			errorExit(fmt.Sprintf("could not check existing Prow configuration: %v", err))
+		}
+		if len(existing) > 0 {
+			errorExit(fmt.Sprintf(`the Prow configuration already references %s/%s:
+
+%s
+
+Re-run with --force to update the configuration anyway.`, config.Org, config.Repo, strings.Join(existing, "\n")))
+		}
+	}
+
 	if err := updateProwConfig(config, o.releaseRepo); err != nil {
 		errorExit(fmt.Sprintf("could not update Prow configuration: %v", err))
 	}
@@ -489,6 +633,44 @@ type TideRepoProwConfig struct {
 	Queries prowconfig.TideQueries `json:"queries,omitempty"`
 }
 
+// existingProwConfigForRepo describes the tide queries and plugin configuration that
+// already reference the repository, ignoring the casing of the org and repo.
+func existingProwConfigForRepo(config initConfig, releaseRepo string) ([]string, error) {
+	prowConfig, err := ciopconfig.LoadProwConfig(releaseRepo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Prow config: %w", err)
+	}
+	pluginConfig, err := loadPluginConfig(releaseRepo)
+	if err != nil {
+		return nil, err
+	}
+	return findExistingProwConfig(config, prowConfig.Tide.Queries, pluginConfig), nil
+}
+
+func findExistingProwConfig(config initConfig, queries prowconfig.TideQueries, pluginConfig *plugins.Configuration) []string {
+	orgRepo := prowconfig.OrgRepo{Org: config.Org, Repo: config.Repo}
+	var existing []string
+	for _, query := range queries.QueryMap().ForRepo(orgRepo) {
+		existing = append(existing, fmt.Sprintf("tide query: %s", query.Query()))
+	}
+	if len(existing) == 0 {
+		// a query for the same repo with a different casing would not be matched above
+		for _, query := range queries {
+			for _, repo := range query.Repos {
+				if strings.EqualFold(repo, orgRepo.String()) {
+					existing = append(existing, fmt.Sprintf("tide query: %s", query.Query()))
+				}
+			}
+		}
+	}
+	for _, key := range sets.List(sets.KeySet(pluginConfig.Plugins)) {
+		if strings.EqualFold(key, orgRepo.String()) {
+			existing = append(existing, fmt.Sprintf("plugins for %s: %s", key, strings.Join(pluginConfig.Plugins[key].Plugins, ", ")))
+		}
+	}
+	return existing
+}
+
 func updateProwConfig(config initConfig, releaseRepo string) (ret error) {
 	prowConfig, err := ciopconfig.LoadProwConfig(releaseRepo)
 	if err != nil {
@@ -551,16 +733,13 @@ func updatePluginConfig(config initConfig, releaseRepo string) error {
 	fmt.Println(`
 Updating Prow plugin configuration ...`)
 	configPath := path.Join(releaseRepo, ciopconfig.PluginConfigInRepoPath)
-	supplementalPluginConfigDir := path.Join(releaseRepo, filepath.Dir(ciopconfig.PluginConfigInRepoPath))
-	agent := plugins.ConfigAgent{}
-	if err := agent.Load(configPath, []string{supplementalPluginConfigDir}, "_pluginconfig.yaml", false, true); err != nil {
-		return fmt.Errorf("could not load Prow plugin configuration: %w", err)
+	pluginConfig, err := loadPluginConfig(releaseRepo)
+	if err != nil {
+		return err
 	}
-
-	pluginConfig := agent.Config()
 	editPluginConfig(pluginConfig, config)
 
-	pluginConfig, err := prowconfigsharding.WriteShardedPluginConfig(pluginConfig, afero.NewBasePathFs(afero.NewOsFs(), filepath.Join(releaseRepo, "core-services/prow/02_config")))
+	pluginConfig, err = prowconfigsharding.WriteShardedPluginConfig(pluginConfig, afero.NewBasePathFs(afero.NewOsFs(), filepath.Join(releaseRepo, "core-services/prow/02_config")))
 	if err != nil {
 		return fmt.Errorf("failed to write plugin config shards: %w", err)
 	}
@@ -573,6 +752,16 @@ Updating Prow plugin configuration ...`)
 	return os.WriteFile(configPath, data, 0644)
 }
 
+func loadPluginConfig(releaseRepo string) (*plugins.Configuration, error) {
+	configPath := path.Join(releaseRepo, ciopconfig.PluginConfigInRepoPath)
+	supplementalPluginConfigDir := path.Join(releaseRepo, filepath.Dir(ciopconfig.PluginConfigInRepoPath))
+	agent := plugins.ConfigAgent{}
+	if err := agent.Load(configPath, []string{supplementalPluginConfigDir}, "_pluginconfig.yaml", false, true); err != nil {
+		return nil, fmt.Errorf("could not load Prow plugin configuration: %w", err)
+	}
+	return agent.Config(), nil
+}
+
 func editPluginConfig(pluginConfig *plugins.Configuration, config initConfig) {
 	orgRepo := fmt.Sprintf("%s/%s", config.Org, config.Repo)
 	_, orgRegistered := pluginConfig.Plugins[config.Org]
@@ -627,25 +816,69 @@ Ensure that webhooks are set up for Prow to watch GitHub state.`)
 
 func createCIOperatorConfig(config initConfig, releaseRepo string, commit bool) (*api.ReleaseBuildConfiguration, error) {
 	logrus.Print(`Generating CI Operator configuration ...`)
+	originConfig, err := loadOriginConfig(releaseRepo)
+	if err != nil {
+		return nil, err
+	}
+	if config.GoVersion == "" {
+		config.GoVersion = goVersionFromBuildRoot(originConfig)
+	}
+	if config.GoVersion == "" {
+		config.GoVersion = defaultGoVersion
+	}
+
+	generated := generateCIOperatorConfig(config, originConfig.PromotionConfiguration)
+	if buildRoot := buildRootFromOrigin(originConfig, config.GoVersion); buildRoot != nil {
+		generated.Configuration.BuildRootImage = &api.BuildRootImageConfiguration{ImageStreamTagReference: buildRoot}
+	}
+	if commit {
+		return &generated.Configuration, generated.CommitTo(path.Join(releaseRepo, ciopconfig.CiopConfigInRepoPath))
+	}
+	return &generated.Configuration, nil
+}
+
+func loadOriginConfig(releaseRepo string) (*api.ReleaseBuildConfiguration, error) {
 	info := api.Metadata{
 		Org:    "openshift",
 		Repo:   "origin",
 		Branch: "master",
 	}
 	originPath := path.Join(releaseRepo, ciopconfig.CiopConfigInRepoPath, info.RelativePath())
+	clusterProfiles, err := ciopconfig.LoadClusterProfiles(releaseRepo)
+	if err != nil {
+		return nil, err
+	}
 	var originConfig *api.ReleaseBuildConfiguration
-	if err := ciopconfig.OperateOnCIOperatorConfig(originPath, func(configuration *api.ReleaseBuildConfiguration, _ *ciopconfig.Info) error {
+	if err := ciopconfig.OperateOnCIOperatorConfig(originPath, clusterProfiles, func(configuration *api.ReleaseBuildConfiguration, _ *ciopconfig.Info) error {
 		originConfig = configuration
 		return nil
 	}); err != nil {
 		return nil, fmt.Errorf("failed to load configuration for openshift/origin: %w", err)
 	}
+	return originConfig, nil
+}
 
-	generated := generateCIOperatorConfig(config, originConfig.PromotionConfiguration)
-	if commit {
-		return &generated.Configuration, generated.CommitTo(path.Join(releaseRepo, ciopconfig.CiopConfigInRepoPath))
+// goVersionFromBuildRoot returns the Go version of the golang build root image
+// used by the configuration, or an empty string if it does not use one.
+func goVersionFromBuildRoot(configuration *api.ReleaseBuildConfiguration) string {
+	if configuration == nil || configuration.BuildRootImage == nil || configuration.BuildRootImage.ImageStreamTagReference == nil {
+		return ""
 	}
-	return &generated.Configuration, nil
+	if match := golangTagRegex.FindStringSubmatch(configuration.BuildRootImage.ImageStreamTagReference.Tag); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// buildRootFromOrigin returns the golang build root image used by openshift/origin
+// if it is for the given Go version, or nil otherwise. Tags for other Go versions
+// are not derived from it, as there is no guarantee that they exist.
+func buildRootFromOrigin(originConfig *api.ReleaseBuildConfiguration, goVersion string) *api.ImageStreamTagReference {
+	if version := goVersionFromBuildRoot(originConfig); version == "" || version != goVersion {
+		return nil
+	}
+	buildRoot := *originConfig.BuildRootImage.ImageStreamTagReference
+	return &buildRoot
 }
 
 func generateCIOperatorConfig(config initConfig, originConfig *api.PromotionConfiguration) ciopconfig.DataWithInfo {
@@ -749,30 +982,19 @@ func generateCIOperatorConfig(config initConfig, originConfig *api.PromotionConf
 		},
 	}
 
-	if config.OperatorBundle != nil {
+	if len(config.OperatorBundles) > 0 {
 		operatorConfig := api.OperatorStepConfiguration{}
 		generated.Configuration.Operator = &operatorConfig
-		if config.OperatorBundle.Name != "" {
-			operatorConfig.Bundles = []api.Bundle{
-				{
-					As: config.OperatorBundle.Name,
-				},
-			}
-		}
-		if config.OperatorBundle.BaseIndex != "" {
-			operatorConfig.Bundles[0].BaseIndex = config.OperatorBundle.BaseIndex
-		}
-		if config.OperatorBundle.ContextDir != "" {
-			operatorConfig.Bundles[0].ContextDir = config.OperatorBundle.ContextDir
-		}
-		if config.OperatorBundle.Channel != "" {
-			operatorConfig.Bundles[0].ContextDir = config.OperatorBundle.ContextDir
-		}
-		if config.OperatorBundle.DockerfilePath != "" {
-			operatorConfig.Bundles[0].DockerfilePath = config.OperatorBundle.DockerfilePath
+		for _, bundle := range config.OperatorBundles {
+			operatorConfig.Bundles = append(operatorConfig.Bundles, api.Bundle{
+				As:             bundle.Name,
+				BaseIndex:      bundle.BaseIndex,
+				ContextDir:     bundle.ContextDir,
+				DockerfilePath: bundle.DockerfilePath,
+			})
+			// substitutions apply to all bundles
+			operatorConfig.Substitutions = append(operatorConfig.Substitutions, bundle.Substitutions...)
 		}
-
-		operatorConfig.Substitutions = config.OperatorBundle.Substitutions
 	}
 
 	for _, test := range config.Tests {
@@ -816,6 +1038,45 @@ func generateCIOperatorConfig(config initConfig, originConfig *api.PromotionConf
 		generated.Configuration.Tests = append(generated.Configuration.Tests, t)
 	}
 
+	for _, test := range config.Periodics {
+		t := api.TestStepConfiguration{As: test.As}
+		if test.Cron != "" {
+			schedule := test.Cron
+			t.Cron = &schedule
+		}
+		if test.Interval != "" {
+			interval := test.Interval
+			t.Interval = &interval
+		}
+		workflow := test.Workflow
+		if workflow == "" {
+			workflow = clusterProfiles[test.Profile]
+		}
+		if test.Profile == "" && workflow == "" {
+			t.Commands = test.Command
+			t.ContainerTestConfiguration = &api.ContainerTestConfiguration{From: api.PipelineImageStreamTagReferenceSource}
+		} else {
+			t.MultiStageTestConfiguration = &api.MultiStageTestConfiguration{
+				ClusterProfile: test.Profile,
+				Test: []api.TestStep{
+					{
+						LiteralTestStep: &api.LiteralTestStep{
+							As:        test.As,
+							Commands:  test.Command,
+							From:      "src",
+							Resources: api.ResourceRequirements{Requests: map[string]string{"cpu": "100m"}},
+						},
+					},
+				},
+			}
+			if workflow != "" {
+				t.MultiStageTestConfiguration.Workflow = &workflow
+			}
+		}
+
+		generated.Configuration.Tests = append(generated.Configuration.Tests, t)
+	}
+
 	if config.ReleaseType != "" {
 		release := api.UnresolvedRelease{}
 		switch config.ReleaseType {
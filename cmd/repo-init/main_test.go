@@ -1,10 +1,14 @@
 package main
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/util/diff"
+	prowconfig "sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/plugins"
 
 	"github.com/openshift/ci-tools/pkg/api"
@@ -685,6 +689,145 @@ func TestGenerateCIOperatorConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "multiple operator bundles configured",
+			config: initConfig{
+				Org:                   "org",
+				Repo:                  "repo",
+				Branch:                "branch",
+				CanonicalGoRepository: "sometimes.com",
+				GoVersion:             "1",
+				OperatorBundles: []operatorBundle{
+					{
+						Name:           "stable-bundle",
+						DockerfilePath: "bundle.Dockerfile",
+						ContextDir:     "stable",
+						Substitutions:  []api.PullSpecSubstitution{{PullSpec: "quay.io/org/operator:stable", With: "operator"}},
+					},
+					{
+						Name:       "candidate-bundle",
+						BaseIndex:  "index",
+						ContextDir: "candidate",
+					},
+				},
+			},
+			originConfig: &api.PromotionConfiguration{
+				Targets: []api.PromotionTarget{{
+					Namespace: "promote",
+					Name:      "version",
+				}},
+			},
+			expected: ciopconfig.DataWithInfo{
+				Configuration: api.ReleaseBuildConfiguration{
+					Metadata: api.Metadata{
+						Org:    "org",
+						Repo:   "repo",
+						Branch: "branch",
+					},
+					InputConfiguration: api.InputConfiguration{
+						BuildRootImage: &api.BuildRootImageConfiguration{
+							ImageStreamTagReference: &api.ImageStreamTagReference{
+								Namespace: "openshift",
+								Name:      "release",
+								Tag:       "golang-1",
+							},
+						},
+					},
+					CanonicalGoRepository: strP("sometimes.com"),
+					Operator: &api.OperatorStepConfiguration{
+						Bundles: []api.Bundle{
+							{As: "stable-bundle", DockerfilePath: "bundle.Dockerfile", ContextDir: "stable"},
+							{As: "candidate-bundle", BaseIndex: "index", ContextDir: "candidate"},
+						},
+						Substitutions: []api.PullSpecSubstitution{{PullSpec: "quay.io/org/operator:stable", With: "operator"}},
+					},
+					Resources: map[string]api.ResourceRequirements{"*": {
+						Limits:   map[string]string{"memory": "4Gi"},
+						Requests: map[string]string{"memory": "200Mi", "cpu": "100m"},
+					}},
+					Tests: []api.TestStepConfiguration{},
+				},
+				Info: ciopconfig.Info{
+					Metadata: api.Metadata{
+						Org:    "org",
+						Repo:   "repo",
+						Branch: "branch",
+					},
+				},
+			},
+		},
+		{
+			name: "periodic tests configured",
+			config: initConfig{
+				Org:                   "org",
+				Repo:                  "repo",
+				Branch:                "branch",
+				CanonicalGoRepository: "sometimes.com",
+				GoVersion:             "1",
+				Periodics: []periodicTest{
+					{As: "nightly", Cron: "@daily", Command: "make nightly"},
+					{As: "e2e-nightly", Interval: "24h", Command: "make e2e", Profile: api.ClusterProfileAWS},
+				},
+			},
+			originConfig: &api.PromotionConfiguration{
+				Targets: []api.PromotionTarget{{
+					Namespace: "promote",
+					Name:      "version",
+				}},
+			},
+			expected: ciopconfig.DataWithInfo{
+				Configuration: api.ReleaseBuildConfiguration{
+					Metadata: api.Metadata{
+						Org:    "org",
+						Repo:   "repo",
+						Branch: "branch",
+					},
+					InputConfiguration: api.InputConfiguration{
+						BuildRootImage: &api.BuildRootImageConfiguration{
+							ImageStreamTagReference: &api.ImageStreamTagReference{
+								Namespace: "openshift",
+								Name:      "release",
+								Tag:       "golang-1",
+							},
+						},
+					},
+					CanonicalGoRepository: strP("sometimes.com"),
+					Resources: map[string]api.ResourceRequirements{"*": {
+						Limits:   map[string]string{"memory": "4Gi"},
+						Requests: map[string]string{"memory": "200Mi", "cpu": "100m"},
+					}},
+					Tests: []api.TestStepConfiguration{
+						{
+							As:                         "nightly",
+							Cron:                       strP("@daily"),
+							Commands:                   "make nightly",
+							ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"},
+						},
+						{
+							As:       "e2e-nightly",
+							Interval: strP("24h"),
+							MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+								ClusterProfile: api.ClusterProfileAWS,
+								Workflow:       strP("ipi-aws"),
+								Test: []api.TestStep{{LiteralTestStep: &api.LiteralTestStep{
+									As:        "e2e-nightly",
+									Commands:  "make e2e",
+									From:      "src",
+									Resources: api.ResourceRequirements{Requests: map[string]string{"cpu": "100m"}},
+								}}},
+							},
+						},
+					},
+				},
+				Info: ciopconfig.Info{
+					Metadata: api.Metadata{
+						Org:    "org",
+						Repo:   "repo",
+						Branch: "branch",
+					},
+				},
+			},
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -694,3 +837,223 @@ func TestGenerateCIOperatorConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadConfigFile(t *testing.T) {
+	expected := initConfig{
+		Org:       "org",
+		Repo:      "repo",
+		Branch:    "master",
+		Promotes:  true,
+		GoVersion: "1.22",
+		Tests:     []test{{As: "unit", From: api.PipelineImageStreamTagReference("src"), Command: "make test"}},
+	}
+	var testCases = []struct {
+		name        string
+		content     string
+		expectedErr bool
+	}{
+		{
+			name: "yaml",
+			content: `org: org
+repo: repo
+branch: master
+promotes: true
+go_version: "1.22"
+tests:
+- as: unit
+  from: src
+  command: make test
+`,
+		},
+		{
+			name:    "json",
+			content: `{"org":"org","repo":"repo","branch":"master","promotes":true,"go_version":"1.22","tests":[{"as":"unit","from":"src","command":"make test"}]}`,
+		},
+		{
+			name:        "invalid",
+			content:     `org: [`,
+			expectedErr: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(testCase.content), 0644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+			actual, err := loadConfigFile(path)
+			if testCase.expectedErr != (err != nil) {
+				t.Fatalf("expected error: %t, got: %v", testCase.expectedErr, err)
+			}
+			if testCase.expectedErr {
+				return
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("got incorrect config: %v", diff.ObjectReflectDiff(expected, actual))
+			}
+		})
+	}
+}
+
+func TestUnmarshalInitConfigOperatorBundles(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		raw      string
+		expected []operatorBundle
+	}{
+		{
+			name:     "no bundles",
+			raw:      `{"org":"org"}`,
+			expected: nil,
+		},
+		{
+			name:     "singular bundle is folded into the bundles",
+			raw:      `{"operator_bundle":{"name":"legacy"}}`,
+			expected: []operatorBundle{{Name: "legacy"}},
+		},
+		{
+			name:     "singular bundle goes before the other bundles",
+			raw:      `{"operator_bundle":{"name":"legacy"},"operator_bundles":[{"name":"first"},{"name":"second"}]}`,
+			expected: []operatorBundle{{Name: "legacy"}, {Name: "first"}, {Name: "second"}},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var config initConfig
+			if err := json.Unmarshal([]byte(testCase.raw), &config); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(config.OperatorBundles, testCase.expected) {
+				t.Errorf("got incorrect bundles: %v", diff.ObjectReflectDiff(testCase.expected, config.OperatorBundles))
+			}
+		})
+	}
+}
+
+func TestFindExistingProwConfig(t *testing.T) {
+	config := initConfig{Org: "org", Repo: "repo"}
+	var testCases = []struct {
+		name         string
+		queries      prowconfig.TideQueries
+		pluginConfig *plugins.Configuration
+		expected     []string
+	}{
+		{
+			name:         "nothing references the repo",
+			queries:      prowconfig.TideQueries{{Repos: []string{"org/other"}, Labels: []string{"lgtm"}}},
+			pluginConfig: &plugins.Configuration{Plugins: map[string]plugins.OrgPlugins{"org": {Plugins: []string{"foo"}}}},
+		},
+		{
+			name:         "tide query references the repo",
+			queries:      prowconfig.TideQueries{{Repos: []string{"org/repo"}, Labels: []string{"lgtm"}}},
+			pluginConfig: &plugins.Configuration{},
+			expected:     []string{"tide query: is:pr state:open archived:false label:\"lgtm\" repo:\"org/repo\""},
+		},
+		{
+			name:         "tide query references the repo with a different casing",
+			queries:      prowconfig.TideQueries{{Repos: []string{"Org/Repo"}, Labels: []string{"lgtm"}}},
+			pluginConfig: &plugins.Configuration{},
+			expected:     []string{"tide query: is:pr state:open archived:false label:\"lgtm\" repo:\"Org/Repo\""},
+		},
+		{
+			name:         "plugins reference the repo",
+			pluginConfig: &plugins.Configuration{Plugins: map[string]plugins.OrgPlugins{"org/Repo": {Plugins: []string{"foo", "bar"}}}},
+			expected:     []string{"plugins for org/Repo: foo, bar"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := findExistingProwConfig(config, testCase.queries, testCase.pluginConfig); !reflect.DeepEqual(actual, testCase.expected) {
+				t.Errorf("got incorrect existing configuration: %v", diff.ObjectReflectDiff(testCase.expected, actual))
+			}
+		})
+	}
+}
+
+func TestGoVersionFromBuildRoot(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		configuration *api.ReleaseBuildConfiguration
+		expected      string
+	}{
+		{
+			name:          "no configuration",
+			configuration: nil,
+		},
+		{
+			name:          "no build root",
+			configuration: &api.ReleaseBuildConfiguration{},
+		},
+		{
+			name: "golang build root",
+			configuration: &api.ReleaseBuildConfiguration{InputConfiguration: api.InputConfiguration{BuildRootImage: &api.BuildRootImageConfiguration{
+				ImageStreamTagReference: &api.ImageStreamTagReference{Namespace: "openshift", Name: "release", Tag: "golang-1.22"},
+			}}},
+			expected: "1.22",
+		},
+		{
+			name: "builder build root",
+			configuration: &api.ReleaseBuildConfiguration{InputConfiguration: api.InputConfiguration{BuildRootImage: &api.BuildRootImageConfiguration{
+				ImageStreamTagReference: &api.ImageStreamTagReference{Namespace: "ocp", Name: "builder", Tag: "rhel-9-golang-1.22-openshift-4.17"},
+			}}},
+			expected: "1.22",
+		},
+		{
+			name: "build root without golang",
+			configuration: &api.ReleaseBuildConfiguration{InputConfiguration: api.InputConfiguration{BuildRootImage: &api.BuildRootImageConfiguration{
+				ImageStreamTagReference: &api.ImageStreamTagReference{Namespace: "ci", Name: "nodejs", Tag: "18"},
+			}}},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := goVersionFromBuildRoot(testCase.configuration); actual != testCase.expected {
+				t.Errorf("expected Go version %q, got %q", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestBuildRootFromOrigin(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		originConfig *api.ReleaseBuildConfiguration
+		goVersion    string
+		expected     *api.ImageStreamTagReference
+	}{
+		{
+			name:         "no build root",
+			originConfig: &api.ReleaseBuildConfiguration{},
+			goVersion:    "1.22",
+		},
+		{
+			name: "build root without golang",
+			originConfig: &api.ReleaseBuildConfiguration{InputConfiguration: api.InputConfiguration{BuildRootImage: &api.BuildRootImageConfiguration{
+				ImageStreamTagReference: &api.ImageStreamTagReference{Namespace: "ci", Name: "nodejs", Tag: "18"},
+			}}},
+			goVersion: "1.22",
+		},
+		{
+			name: "same Go version as origin",
+			originConfig: &api.ReleaseBuildConfiguration{InputConfiguration: api.InputConfiguration{BuildRootImage: &api.BuildRootImageConfiguration{
+				ImageStreamTagReference: &api.ImageStreamTagReference{Namespace: "ocp", Name: "builder", Tag: "rhel-9-golang-1.22-openshift-4.17"},
+			}}},
+			goVersion: "1.22",
+			expected:  &api.ImageStreamTagReference{Namespace: "ocp", Name: "builder", Tag: "rhel-9-golang-1.22-openshift-4.17"},
+		},
+		{
+			name: "other Go version than origin",
+			originConfig: &api.ReleaseBuildConfiguration{InputConfiguration: api.InputConfiguration{BuildRootImage: &api.BuildRootImageConfiguration{
+				ImageStreamTagReference: &api.ImageStreamTagReference{Namespace: "ocp", Name: "builder", Tag: "rhel-9-golang-1.22-openshift-4.17"},
+			}}},
+			goVersion: "1.21",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := buildRootFromOrigin(testCase.originConfig, testCase.goVersion); !reflect.DeepEqual(actual, testCase.expected) {
+				t.Errorf("got incorrect build root: %v", diff.ObjectReflectDiff(testCase.expected, actual))
+			}
+		})
+	}
+}
@@ -20,6 +20,8 @@ type repoManager struct {
 	numRepos       int
 	availableRepos []*repo
 	inUseRepos     []*repo
+	// sync updates a repo to the latest changes, defaults to updateRepo
+	sync func(*repo) error
 }
 
 type repo struct {
@@ -80,7 +82,7 @@ func (rm *repoManager) retrieveAndLockAvailable(githubUsername string) (reposito
 			availableRepo.inUseBy = githubUsername
 			rm.inUseRepos = append(rm.inUseRepos, availableRepo)
 			// make sure we update the repo to the latest changes before giving it out.
-			err := updateRepo(availableRepo)
+			err := rm.update(availableRepo)
 			if err != nil {
 				return nil, fmt.Errorf("unable to lock and sync repo: %w", err)
 			}
@@ -94,6 +96,26 @@ func (rm *repoManager) retrieveAndLockAvailable(githubUsername string) (reposito
 	return repository, err
 }
 
+// inspectAvailable runs inspect against the path of an available repo, without assigning the repo to anyone or
+// syncing it: repos are synced whenever they are handed out, so inspect sees the state of the last sync. The
+// manager stays locked meanwhile, so the repo is neither handed out nor modified while it is inspected.
+func (rm *repoManager) inspectAvailable(inspect func(path string)) error {
+	rm.mux.Lock()
+	defer rm.mux.Unlock()
+	if len(rm.availableRepos) == 0 {
+		return fmt.Errorf("all repositories are currently in use")
+	}
+	inspect(rm.availableRepos[len(rm.availableRepos)-1].path)
+	return nil
+}
+
+func (rm *repoManager) update(r *repo) error {
+	if rm.sync != nil {
+		return rm.sync(r)
+	}
+	return updateRepo(r)
+}
+
 func (rm *repoManager) returnInUse(r *repo) {
 	rm.mux.Lock()
 	for i, cr := range rm.inUseRepos {
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/blang/semver"
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
+	"sigs.k8s.io/yaml"
 
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -39,9 +41,15 @@ type options struct {
 	pagerDutyOptions  pagerdutyutil.Options
 
 	slackTokenPath string
+	configPath     string
 	weekStart      bool
 
+	incidentSummary bool
+
 	enableBuild02UpgradeNotification bool
+	build02UpgradeReminderInterval   time.Duration
+
+	digestUpdateWindow time.Duration
 }
 
 func (o *options) Validate() error {
@@ -54,6 +62,14 @@ func (o *options) Validate() error {
 		return fmt.Errorf("--slack-token-path is required")
 	}
 
+	if o.digestUpdateWindow < 0 {
+		return fmt.Errorf("--digest-update-window must not be negative")
+	}
+
+	if o.build02UpgradeReminderInterval < 0 {
+		return fmt.Errorf("--build02-upgrade-reminder-interval must not be negative")
+	}
+
 	for _, group := range []flagutil.OptionGroup{&o.jiraOptions, &o.pagerDutyOptions, &o.kubernetesOptions} {
 		if err := group.Validate(false); err != nil {
 			return err
@@ -72,8 +88,12 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	}
 
 	fs.StringVar(&o.slackTokenPath, "slack-token-path", "", "Path to the file containing the Slack token to use.")
+	fs.StringVar(&o.configPath, "config", "", "Path to the config file describing the Slack channels, the on-call roles and the Jira project. Defaults to the DPTP values if not set.")
 	fs.BoolVar(&o.weekStart, "week-start", false, "If set to true run in 'Monday' mode: performing, additional, Monday only activities")
+	fs.BoolVar(&o.incidentSummary, "incident-summary", false, "If set to true include a summary of the open PagerDuty incidents of the configured services in the team digest")
 	fs.BoolVar(&o.enableBuild02UpgradeNotification, "enable-build02-upgrade-notification", false, "If set to true send notification when build02 needs an upgrade")
+	fs.DurationVar(&o.build02UpgradeReminderInterval, "build02-upgrade-reminder-interval", 7*24*time.Hour, "If the build02 upgrade to the same version was already announced within this interval, it is not announced again. Zero announces it on every run")
+	fs.DurationVar(&o.digestUpdateWindow, "digest-update-window", 0, "If the team digest was already posted within this window, it is updated in place instead of posting a new one, e.g. 26h. Zero always posts a new one")
 
 	if err := fs.Parse(args); err != nil {
 		logrus.WithError(err).Fatal("Could not parse args.")
@@ -81,6 +101,103 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	return o
 }
 
+// config describes the team that the sprint-automation runs for.
+// Every field that is not set is defaulted to the DPTP value.
+type config struct {
+	// TeamChannel is the private Slack channel the team digest is posted to
+	TeamChannel string `json:"teamChannel,omitempty"`
+	// BuildFarmsChannel is the public Slack channel build farm upgrade notifications are posted to
+	BuildFarmsChannel string `json:"buildFarmsChannel,omitempty"`
+	// JiraProject is the key of the Jira project the issues are queried from
+	JiraProject string `json:"jiraProject,omitempty"`
+	// OnCallRoles maps the rotating roles to the PagerDuty schedules that determine who is in them
+	OnCallRoles []onCallRole `json:"onCallRoles,omitempty"`
+	// IncidentServices are the IDs of the PagerDuty services whose open incidents are summarized in the team digest
+	IncidentServices []string `json:"incidentServices,omitempty"`
+	// IntakeRole is the role from OnCallRoles that new issues are assigned to in the intake digest.
+	// The intake digest is not posted when it is not set.
+	IntakeRole string `json:"intakeRole,omitempty"`
+	// TriageRole is the role from OnCallRoles that is notified of the handover doc at the start of the week.
+	// Nobody is notified when it is not set.
+	TriageRole string `json:"triageRole,omitempty"`
+}
+
+type onCallRole struct {
+	// Role is the name of the role as it is shown in Slack
+	Role string `json:"role"`
+	// Query is used to find the PagerDuty schedule for the role
+	Query string `json:"query"`
+	// UserGroup is the handle of the Slack user group whose only member is kept in sync with the role, if any
+	UserGroup string `json:"userGroup,omitempty"`
+}
+
+func defaultConfig() *config {
+	return &config{
+		TeamChannel:       dptpTeamChannel,
+		BuildFarmsChannel: dptpBuildFarmsChannel,
+		JiraProject:       jira.ProjectDPTP,
+		OnCallRoles: []onCallRole{
+			{Role: roleTriagePrimary, Query: primaryOnCallQuery, UserGroup: userGroupTriage},
+			{Role: roleHelpdesk, Query: helpdeskQuery, UserGroup: userGroupHelpdesk},
+			{Role: roleIntake, Query: intakeQuery},
+		},
+		IntakeRole: roleIntake,
+		TriageRole: roleTriagePrimary,
+	}
+}
+
+func loadConfig(path string) (*config, error) {
+	defaults := defaultConfig()
+	if path == "" {
+		return defaults, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	cfg := &config{}
+	if err := yaml.Unmarshal(raw, cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	if cfg.TeamChannel == "" {
+		cfg.TeamChannel = defaults.TeamChannel
+	}
+	if cfg.BuildFarmsChannel == "" {
+		cfg.BuildFarmsChannel = defaults.BuildFarmsChannel
+	}
+	if cfg.JiraProject == "" {
+		cfg.JiraProject = defaults.JiraProject
+	}
+	if len(cfg.OnCallRoles) == 0 {
+		cfg.OnCallRoles = defaults.OnCallRoles
+		// The DPTP intake and triage roles only exist when the DPTP roles are used
+		if cfg.IntakeRole == "" {
+			cfg.IntakeRole = defaults.IntakeRole
+		}
+		if cfg.TriageRole == "" {
+			cfg.TriageRole = defaults.TriageRole
+		}
+	}
+	var errs []error
+	roles := sets.New[string]()
+	for i, role := range cfg.OnCallRoles {
+		if role.Role == "" || role.Query == "" {
+			errs = append(errs, fmt.Errorf("onCallRoles[%d]: both role and query must be set", i))
+		}
+		roles.Insert(role.Role)
+	}
+	if cfg.IntakeRole != "" && !roles.Has(cfg.IntakeRole) {
+		errs = append(errs, fmt.Errorf("intakeRole: %q is not one of the onCallRoles", cfg.IntakeRole))
+	}
+	if cfg.TriageRole != "" && !roles.Has(cfg.TriageRole) {
+		errs = append(errs, fmt.Errorf("triageRole: %q is not one of the onCallRoles", cfg.TriageRole))
+	}
+	if err := kerrors.NewAggregate(errs); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
 func addSchemes() error {
 	if err := configv1.AddToScheme(scheme.Scheme); err != nil {
 		return fmt.Errorf("failed to add configv1 to scheme: %w", err)
@@ -98,6 +215,11 @@ func main() {
 	level, _ := logrus.ParseLevel(o.logLevel)
 	logrus.SetLevel(level)
 
+	cfg, err := loadConfig(o.configPath)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not load config.")
+	}
+
 	if err := secret.Add(o.slackTokenPath); err != nil {
 		logrus.WithError(err).Fatal("Error starting secrets agent.")
 	}
@@ -107,14 +229,13 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal("Could not initialize PagerDuty client.")
 	}
-	userIdsByRole, err := users(pagerDutyClient, slackClient)
+	userIdsByRole, err := users(cfg.OnCallRoles, pagerDutyClient, slackClient)
 	if err != nil {
-		msg := "Could not get rotating roles from PagerDuty."
+		// Only give up when no role could be resolved, the digest calls out the ones that couldn't
 		if len(userIdsByRole) == 0 {
-			logrus.WithError(err).Fatal(msg)
-		} else {
-			logrus.WithError(err).Error(msg)
+			logrus.WithError(err).Fatal("Could not get any rotating roles from PagerDuty.")
 		}
+		logrus.WithError(err).Error("Could not get some rotating roles from PagerDuty.")
 	}
 	prowJiraClient, err := o.jiraOptions.Client()
 	if err != nil {
@@ -122,23 +243,41 @@ func main() {
 	}
 	jiraClient := prowJiraClient.JiraClient()
 
-	if err := sendTeamDigest(userIdsByRole, jiraClient, slackClient); err != nil {
+	var incidentBlocks []slack.Block
+	if o.incidentSummary {
+		if len(cfg.IncidentServices) == 0 {
+			logrus.Warn("No incidentServices are configured, skipping the incident summary.")
+		} else if incidents, err := recentIncidents(pagerDutyClient, cfg.IncidentServices, time.Now()); err != nil {
+			logrus.WithError(err).Error("Could not get incidents from PagerDuty.")
+		} else {
+			incidentBlocks = getIncidentBlocks(incidents)
+		}
+	}
+	if err := sendTeamDigest(cfg, userIdsByRole, incidentBlocks, jiraClient, slackClient, o.digestUpdateWindow); err != nil {
 		logrus.WithError(err).Fatal("Could not post team digest to Slack.")
 	}
 
-	if err := ensureGroupMembership(slackClient, userIdsByRole); err != nil {
+	if err := ensureGroupMembership(slackClient, cfg.OnCallRoles, userIdsByRole); err != nil {
 		logrus.WithError(err).Fatal("Could not ensure Slack group membership.")
 	}
 
-	if err := assignAndSendIntakeDigest(slackClient, jiraClient, userIdsByRole[roleIntake]); err != nil {
-		logrus.WithError(err).Fatal("Could not post @dptp-intake digest to Slack.")
+	if cfg.IntakeRole == "" {
+		logrus.Info("No intakeRole is configured, not posting the intake digest.")
+	} else if intake, resolved := userIdsByRole[cfg.IntakeRole]; !resolved {
+		logrus.Warnf("Could not resolve the %s role, not posting the intake digest.", cfg.IntakeRole)
+	} else if err := assignAndSendIntakeDigest(slackClient, jiraClient, cfg.JiraProject, intake); err != nil {
+		logrus.WithError(err).Fatalf("Could not post %s digest to Slack.", cfg.IntakeRole)
 	}
 
 	if o.weekStart {
-		if err := sendNextWeeksRoleDigest(pagerDutyClient, slackClient); err != nil {
+		if err := sendNextWeeksRoleDigest(cfg.OnCallRoles, pagerDutyClient, slackClient); err != nil {
 			logrus.WithError(err).Fatal("Could not post next week's role digest to Slack.")
 		}
-		if err := notifyTriageOfHandover(slackClient, userIdsByRole[roleTriagePrimary].slackId); err != nil {
+		if cfg.TriageRole == "" {
+			logrus.Info("No triageRole is configured, not notifying anybody of the handover doc.")
+		} else if triage, resolved := userIdsByRole[cfg.TriageRole]; !resolved {
+			logrus.Warnf("Could not resolve the %s role, not notifying them of the handover doc.", cfg.TriageRole)
+		} else if err := notifyTriageOfHandover(slackClient, triage.slackId); err != nil {
 			logrus.WithError(err).Fatal("Could not notify triage engineer of handover doc via Slack.")
 		}
 	}
@@ -171,8 +310,7 @@ func main() {
 			logrus.WithError(err).Fatal("could not determine if build02 needs to upgraded")
 		}
 		if versionInfo != nil {
-			logrus.WithField("toVersion", versionInfo.version).Info("Posting @dptp-triage about upgrading build02 to Slack")
-			if err := sendTriageBuild02Upgrade(slackClient, versionInfo.version, versionInfo.stableDuration); err != nil {
+			if err := sendTriageBuild02Upgrade(slackClient, cfg.BuildFarmsChannel, versionInfo.version, versionInfo.stableDuration, o.build02UpgradeReminderInterval); err != nil {
 				logrus.WithError(err).Fatal("Could not post @dptp-triage about upgrading build02 to Slack.")
 			}
 		}
@@ -191,29 +329,40 @@ const (
 	roleIntake                        = "@dptp-intake"
 	jiraUnassignedAssigneeDisplayName = "<Unassigned>"
 	jiraUnassignedAssigneeAvatarUrl   = "https://issues.redhat.com/secure/useravatar?size=mm&avatarId=10283"
+	incidentStatusTriggered           = "triggered"
+	incidentStatusAcknowledged        = "acknowledged"
+	incidentStatusResolved            = "resolved"
 )
 
-func sendTeamDigest(userIdsByRole map[string]user, jiraClient *jiraapi.Client, slackClient *slack.Client) error {
-	blocks := getPagerDutyBlocks(userIdsByRole)
+func sendTeamDigest(cfg *config, userIdsByRole map[string]user, incidentBlocks []slack.Block, jiraClient *jiraapi.Client, slackClient *slack.Client, updateWindow time.Duration) error {
+	blocks := getPagerDutyBlocks(cfg.OnCallRoles, userIdsByRole)
+	blocks = append(blocks, incidentBlocks...)
 
-	if approvalBlocks, err := getIssuesNeedingApproval(jiraClient); err != nil {
+	if approvalBlocks, err := getIssuesNeedingApproval(jiraClient, cfg.JiraProject); err != nil {
 		return fmt.Errorf("could not get issues needing approval: %w", err)
 	} else {
 		blocks = append(blocks, approvalBlocks...)
 	}
 
-	return postBlocks(slackClient, blocks)
+	return postBlocks(slackClient, cfg.TeamChannel, blocks, updateWindow)
 }
 
-func getPagerDutyBlocks(userIdsByRole map[string]user) []slack.Block {
+// getPagerDutyBlocks lists who is in which role, roles that could not be resolved
+// are called out instead of failing the whole digest
+func getPagerDutyBlocks(roles []onCallRole, userIdsByRole map[string]user) []slack.Block {
 	var fields []*slack.TextBlockObject
-	for _, role := range []string{roleTriagePrimary, roleHelpdesk, roleIntake} {
+	var unresolved []string
+	for _, role := range roles {
+		if _, resolved := userIdsByRole[role.Role]; !resolved {
+			unresolved = append(unresolved, role.Role)
+			continue
+		}
 		fields = append(fields, &slack.TextBlockObject{
 			Type: slack.PlainTextType,
-			Text: role,
+			Text: role.Role,
 		}, &slack.TextBlockObject{
 			Type: slack.MarkdownType,
-			Text: fmt.Sprintf("<@%s>", userIdsByRole[role].slackId),
+			Text: fmt.Sprintf("<@%s>", userIdsByRole[role.Role].slackId),
 		})
 	}
 
@@ -225,10 +374,23 @@ func getPagerDutyBlocks(userIdsByRole map[string]user) []slack.Block {
 				Text: "Today's Rotating Positions",
 			},
 		},
-		&slack.SectionBlock{
+	}
+	if len(fields) > 0 {
+		blocks = append(blocks, &slack.SectionBlock{
 			Type:   slack.MBTSection,
 			Fields: fields,
-		},
+		})
+	}
+	if len(unresolved) > 0 {
+		blocks = append(blocks, &slack.SectionBlock{
+			Type: slack.MBTSection,
+			Text: &slack.TextBlockObject{
+				Type: slack.MarkdownType,
+				Text: fmt.Sprintf(":warning: Could not resolve: %s", strings.Join(unresolved, ", ")),
+			},
+		})
+	}
+	blocks = append(blocks,
 		&slack.SectionBlock{
 			Type: slack.MBTSection,
 			Text: &slack.TextBlockObject{
@@ -243,8 +405,86 @@ func getPagerDutyBlocks(userIdsByRole map[string]user) []slack.Block {
 				Text: "Team definitions for: <https://docs.google.com/document/d/1pvTfPovr1zGmt-CKTQEfJ2Y6UYanbGO8-3RvujE-rpw|ready>, <https://docs.google.com/document/d/1f2zJHg9evsrY2BArfmhuhavFbdghPD4jzW1pxRUL35o|done>.",
 			},
 		},
+	)
+
+	return blocks
+}
+
+// recentIncidents lists the incidents of the given services that were triggered
+// in the last 24h, including those that were resolved since
+func recentIncidents(client pagerDutyClient, serviceIDs []string, now time.Time) ([]pagerduty.Incident, error) {
+	var incidents []pagerduty.Incident
+	opts := pagerduty.ListIncidentsOptions{
+		Since:      now.Add(-24 * time.Hour).Format(time.RFC3339),
+		Until:      now.Format(time.RFC3339),
+		Statuses:   []string{incidentStatusTriggered, incidentStatusAcknowledged, incidentStatusResolved},
+		ServiceIDs: serviceIDs,
+	}
+	for {
+		response, err := client.ListIncidents(opts)
+		if err != nil {
+			return nil, fmt.Errorf("could not list incidents: %w", err)
+		}
+		incidents = append(incidents, response.Incidents...)
+		if !response.More || len(response.Incidents) == 0 {
+			return incidents, nil
+		}
+		opts.Offset += uint(len(response.Incidents))
+	}
+}
+
+func getIncidentBlocks(incidents []pagerduty.Incident) []slack.Block {
+	blocks := []slack.Block{
+		&slack.HeaderBlock{
+			Type: slack.MBTHeader,
+			Text: &slack.TextBlockObject{
+				Type: slack.PlainTextType,
+				Text: "Incidents in the Last 24h",
+			},
+		},
+	}
+	if len(incidents) == 0 {
+		return append(blocks, &slack.SectionBlock{
+			Type: slack.MBTSection,
+			Text: &slack.TextBlockObject{
+				Type: slack.PlainTextType,
+				Text: "No incidents were triggered, it was a quiet night.",
+			},
+		})
+	}
+
+	countsByService := map[string]map[string]int{}
+	for _, incident := range incidents {
+		service := incident.Service.Summary
+		if service == "" {
+			service = incident.Service.ID
+		}
+		if _, ok := countsByService[service]; !ok {
+			countsByService[service] = map[string]int{}
+		}
+		countsByService[service][incident.Status]++
 	}
 
+	var fields []*slack.TextBlockObject
+	for _, service := range sets.List(sets.KeySet(countsByService)) {
+		counts := countsByService[service]
+		fields = append(fields, &slack.TextBlockObject{
+			Type: slack.PlainTextType,
+			Text: service,
+		}, &slack.TextBlockObject{
+			Type: slack.MarkdownType,
+			Text: fmt.Sprintf("*%d* triggered, *%d* acknowledged, *%d* resolved", counts[incidentStatusTriggered], counts[incidentStatusAcknowledged], counts[incidentStatusResolved]),
+		})
+	}
+	// Slack rejects section blocks with more than ten fields
+	for len(fields) > 0 {
+		n := min(len(fields), 10)
+		blocks = append(blocks, &slack.SectionBlock{
+			Type:   slack.MBTSection,
+			Fields: fields[:n],
+		})
+		fields = fields[n:]
+	}
 	return blocks
 }
 
@@ -253,39 +493,23 @@ type user struct {
 	email   string
 }
 
-func users(client *pagerduty.Client, slackClient *slack.Client) (map[string]user, error) {
+func users(roles []onCallRole, client *pagerduty.Client, slackClient *slack.Client) (map[string]user, error) {
 	now := time.Now()
-	userIdsByRole, errors := usersOnCallAtTime(client, slackClient, now.Year(), now.Month(), now.Day())
+	userIdsByRole, errors := usersOnCallAtTime(roles, client, slackClient, now.Year(), now.Month(), now.Day())
 	return userIdsByRole, kerrors.NewAggregate(errors)
 }
 
-func usersOnCallAtTime(client *pagerduty.Client, slackClient *slack.Client, year int, month time.Month, day int) (map[string]user, []error) {
+func usersOnCallAtTime(roles []onCallRole, client *pagerduty.Client, slackClient *slack.Client, year int, month time.Month, day int) (map[string]user, []error) {
 	var errors []error
 	userIdsByRole := map[string]user{}
 
-	for _, item := range []struct {
-		role  string
-		query string
-	}{
-		{
-			role:  roleTriagePrimary,
-			query: primaryOnCallQuery,
-		},
-		{
-			role:  roleHelpdesk,
-			query: helpdeskQuery,
-		},
-		{
-			role:  roleIntake,
-			query: intakeQuery,
-		},
-	} {
+	for _, item := range roles {
 		// 7 am UTC is when our PD day begins, and US on-call ends at 10pm UTC. Query 8 am - 9 pm for safe results
 		dayStart := time.Date(year, month, day, 8, 0, 1, 0, time.UTC)
 		dayEnd := dayStart.Add(13 * time.Hour).Add(-2 * time.Second)
-		pagerDutyUser, err := userOnCallDuring(client, item.query, dayStart, dayEnd)
+		pagerDutyUser, err := userOnCallDuring(client, item.Query, dayStart, dayEnd)
 		if err != nil {
-			errors = append(errors, fmt.Errorf("could not get PagerDuty user for %s: %w", item.role, err))
+			errors = append(errors, fmt.Errorf("could not get PagerDuty user for %s: %w", item.Role, err))
 			continue
 		}
 		slackUser, err := slackClient.GetUserByEmail(pagerDutyUser.Email)
@@ -293,12 +517,22 @@ func usersOnCallAtTime(client *pagerduty.Client, slackClient *slack.Client, year
 			errors = append(errors, fmt.Errorf("could not get slack user for %s: %w", pagerDutyUser.Name, err))
 			continue
 		}
-		userIdsByRole[item.role] = user{slackId: slackUser.ID, email: pagerDutyUser.Email}
+		userIdsByRole[item.Role] = user{slackId: slackUser.ID, email: pagerDutyUser.Email}
 	}
 	return userIdsByRole, errors
 }
 
-func userOnCallDuring(client *pagerduty.Client, query string, since, until time.Time) (*pagerduty.User, error) {
+// pagerDutyClient is the subset of the PagerDuty client used to determine who is on-call
+// and which incidents are open
+type pagerDutyClient interface {
+	ListSchedules(o pagerduty.ListSchedulesOptions) (*pagerduty.ListSchedulesResponse, error)
+	ListOnCallUsers(id string, o pagerduty.ListOnCallUsersOptions) ([]pagerduty.User, error)
+	ListOverrides(id string, o pagerduty.ListOverridesOptions) (*pagerduty.ListOverridesResponse, error)
+	GetUser(id string, o pagerduty.GetUserOptions) (*pagerduty.User, error)
+	ListIncidents(o pagerduty.ListIncidentsOptions) (*pagerduty.ListIncidentsResponse, error)
+}
+
+func userOnCallDuring(client pagerDutyClient, query string, since, until time.Time) (*pagerduty.User, error) {
 	scheduleResponse, err := client.ListSchedules(pagerduty.ListSchedulesOptions{Query: query})
 	if err != nil {
 		return nil, fmt.Errorf("could not query PagerDuty for the %s on-call schedule: %w", query, err)
@@ -329,10 +563,10 @@ func userOnCallDuring(client *pagerduty.Client, query string, since, until time.
 	if err != nil {
 		return nil, fmt.Errorf("could not query PagerDuty for the '%s' overrides: %w", query, err)
 	}
-	if len(overrides.Overrides) != 1 {
-		return nil, fmt.Errorf("did not get exactly one override when querying PagerDuty for the '%s' overrides: %v", query, overrides)
+	override, err := overrideDuring(overrides.Overrides, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine the override for the '%s' on-call: %w", query, err)
 	}
-	override := overrides.Overrides[0]
 
 	user, err := client.GetUser(override.User.ID, pagerduty.GetUserOptions{})
 	if err != nil {
@@ -341,20 +575,71 @@ func userOnCallDuring(client *pagerduty.Client, query string, since, until time.
 	return user, nil
 }
 
-func sendNextWeeksRoleDigest(client *pagerduty.Client, slackClient *slack.Client) error {
+// overrideDuring picks the override that is responsible for the [since, until] interval: the one
+// covering its midpoint or, if none does, the one covering the longest part of it. When several
+// overrides cover the midpoint, e.g. a handoff and a short secondary override, the one covering the
+// longest part of the interval wins.
+func overrideDuring(overrides []pagerduty.Override, since, until time.Time) (*pagerduty.Override, error) {
+	if len(overrides) == 0 {
+		return nil, fmt.Errorf("did not get any overrides")
+	}
+	if len(overrides) == 1 {
+		return &overrides[0], nil
+	}
+
+	midpoint := since.Add(until.Sub(since) / 2)
+	var selected *pagerduty.Override
+	var selectedCoversMidpoint bool
+	var selectedCoverage time.Duration
+	for i := range overrides {
+		start, err := time.Parse(time.RFC3339, overrides[i].Start)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse start of override %s: %w", overrides[i].ID, err)
+		}
+		end, err := time.Parse(time.RFC3339, overrides[i].End)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse end of override %s: %w", overrides[i].ID, err)
+		}
+		coversMidpoint := !start.After(midpoint) && end.After(midpoint)
+		coverage := minTime(end, until).Sub(maxTime(start, since))
+		if coverage <= 0 {
+			continue
+		}
+		if selected == nil || (coversMidpoint && !selectedCoversMidpoint) || (coversMidpoint == selectedCoversMidpoint && coverage > selectedCoverage) {
+			selected, selectedCoversMidpoint, selectedCoverage = &overrides[i], coversMidpoint, coverage
+		}
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("none of the %d overrides covers the interval from %s to %s", len(overrides), since, until)
+	}
+	return selected, nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func sendNextWeeksRoleDigest(roles []onCallRole, client *pagerduty.Client, slackClient *slack.Client) error {
 	var errors []error
 	// Use one week from now at noon UTC to ensure that PD roles have begun
 	nextWeek := time.Now().Add(7 * 24 * time.Hour)
-	userIdsByRole, errs := usersOnCallAtTime(client, slackClient, nextWeek.Year(), nextWeek.Month(), nextWeek.Day())
+	userIdsByRole, errs := usersOnCallAtTime(roles, client, slackClient, nextWeek.Year(), nextWeek.Month(), nextWeek.Day())
 	if len(errs) > 0 {
-		errors = append(errors, errs...)
-		msg := "Could not get rotating roles from PagerDuty."
-		err := kerrors.NewAggregate(errors)
+		err := kerrors.NewAggregate(errs)
 		if len(userIdsByRole) == 0 {
-			logrus.WithError(err).Fatal(msg)
-		} else {
-			logrus.WithError(err).Error(msg)
+			return fmt.Errorf("could not get any of next week's rotating roles from PagerDuty: %w", err)
 		}
+		logrus.WithError(err).Error("Could not get some of next week's rotating roles from PagerDuty.")
 	}
 
 	// Invert to group all roles for each userId as a user can be in multiple roles
@@ -414,8 +699,8 @@ func sendNextWeeksRoleDigest(client *pagerduty.Client, slackClient *slack.Client
 	return kerrors.NewAggregate(errors)
 }
 
-func getIssuesNeedingApproval(jiraClient *jiraapi.Client) ([]slack.Block, error) {
-	issues, response, err := jiraClient.Issue.Search(fmt.Sprintf(`project=%s AND status=Review AND issuetype!=Sub-task`, jira.ProjectDPTP), nil)
+func getIssuesNeedingApproval(jiraClient *jiraapi.Client, project string) ([]slack.Block, error) {
+	issues, response, err := jiraClient.Issue.Search(fmt.Sprintf(`project=%s AND status=Review AND issuetype!=Sub-task`, project), nil)
 	if err := jirautil.HandleJiraError(response, err); err != nil {
 		return nil, fmt.Errorf("could not query for Jira issues: %w", err)
 	}
@@ -436,7 +721,7 @@ func getIssuesNeedingApproval(jiraClient *jiraapi.Client) ([]slack.Block, error)
 			Type: slack.MBTSection,
 			Text: &slack.TextBlockObject{
 				Type: slack.PlainTextType,
-				Text: "The following issues are ready for acceptance on the DPTP board:",
+				Text: fmt.Sprintf("The following issues are ready for acceptance on the %s board:", project),
 			},
 		},
 	}
@@ -512,12 +797,42 @@ func channelID(slackClient *slack.Client, channel, t string) (string, error) {
 	return channelID, nil
 }
 
-func postBlocks(slackClient *slack.Client, blocks []slack.Block) error {
-	channelID, err := channelID(slackClient, dptpTeamChannel, privateChannelType)
+// teamDigestText is the fallback text of the team digest, used to find the previous digest
+const teamDigestText = "Jira card digest."
+
+type digestClient interface {
+	GetConversationHistory(params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error)
+	PostMessage(channelID string, options ...slack.MsgOption) (string, string, error)
+	UpdateMessage(channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error)
+}
+
+func postBlocks(slackClient *slack.Client, channel string, blocks []slack.Block, updateWindow time.Duration) error {
+	channelID, err := channelID(slackClient, channel, privateChannelType)
 	if err != nil {
-		return fmt.Errorf("failed to get channel ID for %s: %w", dptpTeamChannel, err)
+		return fmt.Errorf("failed to get channel ID for %s: %w", channel, err)
 	}
-	responseChannel, responseTimestamp, err := slackClient.PostMessage(channelID, slack.MsgOptionText("Jira card digest.", false), slack.MsgOptionBlocks(blocks...))
+	return postOrUpdateDigest(slackClient, channelID, blocks, updateWindow, time.Now())
+}
+
+// postOrUpdateDigest updates the team digest posted within the update window in place.
+// A new digest is posted when the window is zero or no previous digest can be updated.
+func postOrUpdateDigest(client digestClient, channelID string, blocks []slack.Block, updateWindow time.Duration, now time.Time) error {
+	options := []slack.MsgOption{slack.MsgOptionText(teamDigestText, false), slack.MsgOptionBlocks(blocks...)}
+	if updateWindow > 0 {
+		timestamp, err := findMessage(client, channelID, teamDigestText, now.Add(-updateWindow))
+		if err != nil {
+			logrus.WithError(err).Warn("Could not find the previous team digest, posting a new one")
+		} else if timestamp != "" {
+			responseChannel, responseTimestamp, _, err := client.UpdateMessage(channelID, timestamp, options...)
+			if err == nil {
+				logrus.Infof("Updated team digest in channel %s at %s", responseChannel, responseTimestamp)
+				return nil
+			}
+			logrus.WithError(err).Warn("Could not update the previous team digest, posting a new one")
+		}
+	}
+
+	responseChannel, responseTimestamp, err := client.PostMessage(channelID, options...)
 	if err != nil {
 		return fmt.Errorf("failed to post to channel: %w", err)
 	}
@@ -526,9 +841,31 @@ func postBlocks(slackClient *slack.Client, blocks []slack.Block) error {
 	return nil
 }
 
-func assignAndSendIntakeDigest(slackClient *slack.Client, jiraClient *jiraapi.Client, user user) error {
+// findMessage returns the timestamp of the newest message with the given text posted to the channel
+// after since, or an empty string if there is none.
+func findMessage(client digestClient, channelID, text string, since time.Time) (string, error) {
+	params := &slack.GetConversationHistoryParameters{ChannelID: channelID, Oldest: strconv.FormatInt(since.Unix(), 10)}
+	for {
+		history, err := client.GetConversationHistory(params)
+		if err != nil {
+			return "", fmt.Errorf("could not get the history of channel %s: %w", channelID, err)
+		}
+		// messages are returned newest first
+		for _, message := range history.Messages {
+			if message.Text == text {
+				return message.Timestamp, nil
+			}
+		}
+		if !history.HasMore || history.ResponseMetaData.NextCursor == "" {
+			return "", nil
+		}
+		params.Cursor = history.ResponseMetaData.NextCursor
+	}
+}
+
+func assignAndSendIntakeDigest(slackClient *slack.Client, jiraClient *jiraapi.Client, project string, user user) error {
 	opts := jiraapi.SearchOptions{Fields: []string{"*navigable", "comment"}}
-	issues, response, err := jiraClient.Issue.Search(fmt.Sprintf(`project=%s AND (labels is EMPTY OR NOT (labels=ready OR labels=no-intake)) AND created >= -30d AND status = "To Do" AND issuetype != Sub-task AND assignee is EMPTY`, jira.ProjectDPTP), &opts)
+	issues, response, err := jiraClient.Issue.Search(fmt.Sprintf(`project=%s AND (labels is EMPTY OR NOT (labels=ready OR labels=no-intake)) AND created >= -30d AND status = "To Do" AND issuetype != Sub-task AND assignee is EMPTY`, project), &opts)
 	if err := jirautil.HandleJiraError(response, err); err != nil {
 		return fmt.Errorf("could not query for Jira issues: %w", err)
 	}
@@ -655,7 +992,7 @@ func upgradeBuild02(ctx context.Context, build01Client, build02Client ctrlruntim
 	return build01VI, nil
 }
 
-func sendTriageBuild02Upgrade(slackClient *slack.Client, version, stableDuration string) error {
+func sendTriageBuild02Upgrade(slackClient *slack.Client, channel, version, stableDuration string, reminderInterval time.Duration) error {
 	blocks := []slack.Block{
 		&slack.HeaderBlock{
 			Type: slack.MBTHeader,
@@ -676,11 +1013,34 @@ func sendTriageBuild02Upgrade(slackClient *slack.Client, version, stableDuration
 		},
 	}
 
-	channelID, err := channelID(slackClient, dptpBuildFarmsChannel, publicChannelType)
+	channelID, err := channelID(slackClient, channel, publicChannelType)
 	if err != nil {
-		return fmt.Errorf("failed for get channel ID for %s", dptpBuildFarmsChannel)
+		return fmt.Errorf("failed for get channel ID for %s", channel)
+	}
+	return postBuild02UpgradeReminder(slackClient, channelID, version, blocks, reminderInterval, time.Now())
+}
+
+// build02UpgradeText is the fallback text of the build02 upgrade reminder, used to find
+// a previous reminder for the same version
+const build02UpgradeText = "Upgrade build02 to %s."
+
+// postBuild02UpgradeReminder posts the reminder unless one for the same version was
+// already posted within the interval. A zero interval always posts the reminder.
+func postBuild02UpgradeReminder(client digestClient, channelID, version string, blocks []slack.Block, interval time.Duration, now time.Time) error {
+	text := fmt.Sprintf(build02UpgradeText, version)
+	logger := logrus.WithField("toVersion", version)
+	if interval > 0 {
+		timestamp, err := findMessage(client, channelID, text, now.Add(-interval))
+		if err != nil {
+			logger.WithError(err).Warn("Could not find the previous build02 upgrade reminder, posting a new one")
+		} else if timestamp != "" {
+			logger.WithField("timestamp", timestamp).Info("The build02 upgrade was already announced, not posting it again")
+			return nil
+		}
 	}
-	responseChannel, responseTimestamp, err := slackClient.PostMessage(channelID, slack.MsgOptionBlocks(blocks...))
+
+	logger.Info("Posting @dptp-triage about upgrading build02 to Slack")
+	responseChannel, responseTimestamp, err := client.PostMessage(channelID, slack.MsgOptionText(text, false), slack.MsgOptionBlocks(blocks...))
 	if err != nil {
 		return fmt.Errorf("failed to message @dptp-triage: %w", err)
 	}
@@ -722,7 +1082,7 @@ const (
 	userGroupHelpdesk = "dptp-helpdesk"
 )
 
-func ensureGroupMembership(client *slack.Client, userIdsByRole map[string]user) error {
+func ensureGroupMembership(client *slack.Client, roles []onCallRole, userIdsByRole map[string]user) error {
 	groups, err := client.GetUserGroups(slack.GetUserGroupsOptionIncludeUsers(true))
 	if err != nil {
 		return fmt.Errorf("could not query Slack for groups: %w", err)
@@ -731,16 +1091,21 @@ func ensureGroupMembership(client *slack.Client, userIdsByRole map[string]user)
 	for i := range groups {
 		groupsByHandle[groups[i].Handle] = groups[i]
 	}
-	for role, handle := range map[string]string{
-		roleTriagePrimary: userGroupTriage,
-		roleHelpdesk:      userGroupHelpdesk,
-	} {
+	for _, role := range roles {
+		handle := role.UserGroup
+		if handle == "" {
+			continue
+		}
 		group, found := groupsByHandle[handle]
 		if !found {
 			return fmt.Errorf("could not find user group %s", handle)
 		}
+		if _, resolved := userIdsByRole[role.Role]; !resolved {
+			logrus.Warnf("Could not resolve the %s role, not updating the members of user group %s.", role.Role, handle)
+			continue
+		}
 
-		if expected, actual := sets.New[string](userIdsByRole[role].slackId), sets.New[string](group.Users...); !expected.Equal(actual) {
+		if expected, actual := sets.New[string](userIdsByRole[role.Role].slackId), sets.New[string](group.Users...); !expected.Equal(actual) {
 			if _, err := client.UpdateUserGroupMembers(group.ID, strings.Join(sets.List(expected), ",")); err != nil {
 				return fmt.Errorf("failed to update group %s: %w", handle, err)
 			}
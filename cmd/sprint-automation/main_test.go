@@ -2,11 +2,17 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/PagerDuty/go-pagerduty"
 	"github.com/google/go-cmp/cmp"
+	"github.com/slack-go/slack"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -15,6 +21,7 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 
+	"github.com/openshift/ci-tools/pkg/jira"
 	"github.com/openshift/ci-tools/pkg/testhelper"
 )
 
@@ -297,3 +304,499 @@ func TestUpgradeBuild02(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		raw         string
+		expected    *config
+		expectedErr error
+	}{
+		{
+			name:     "no config, DPTP defaults are used",
+			expected: defaultConfig(),
+		},
+		{
+			name: "unset fields are defaulted",
+			raw: `teamChannel: team-other
+jiraProject: OTHER`,
+			expected: &config{
+				TeamChannel:       "team-other",
+				BuildFarmsChannel: dptpBuildFarmsChannel,
+				JiraProject:       "OTHER",
+				OnCallRoles:       defaultConfig().OnCallRoles,
+				IntakeRole:        roleIntake,
+				TriageRole:        roleTriagePrimary,
+			},
+		},
+		{
+			name: "full config",
+			raw: `teamChannel: team-other
+buildFarmsChannel: alerts-other
+jiraProject: OTHER
+onCallRoles:
+- role: "@other-triage"
+  query: Other Triage
+  userGroup: other-triage
+- role: "@other-intake"
+  query: Other Intake
+intakeRole: "@other-intake"
+triageRole: "@other-triage"
+incidentServices:
+- PABC123`,
+			expected: &config{
+				TeamChannel:       "team-other",
+				BuildFarmsChannel: "alerts-other",
+				JiraProject:       "OTHER",
+				OnCallRoles: []onCallRole{
+					{Role: "@other-triage", Query: "Other Triage", UserGroup: "other-triage"},
+					{Role: "@other-intake", Query: "Other Intake"},
+				},
+				IntakeRole:       "@other-intake",
+				TriageRole:       "@other-triage",
+				IncidentServices: []string{"PABC123"},
+			},
+		},
+		{
+			name: "custom roles do not get the DPTP intake and triage roles",
+			raw: `onCallRoles:
+- role: "@other-triage"
+  query: Other Triage`,
+			expected: &config{
+				TeamChannel:       dptpTeamChannel,
+				BuildFarmsChannel: dptpBuildFarmsChannel,
+				JiraProject:       jira.ProjectDPTP,
+				OnCallRoles:       []onCallRole{{Role: "@other-triage", Query: "Other Triage"}},
+			},
+		},
+		{
+			name: "intake and triage roles that are not configured are rejected",
+			raw: `onCallRoles:
+- role: "@other-triage"
+  query: Other Triage
+intakeRole: "@dptp-intake"
+triageRole: "@other-helpdesk"`,
+			expectedErr: fmt.Errorf(`invalid config: [intakeRole: "@dptp-intake" is not one of the onCallRoles, triageRole: "@other-helpdesk" is not one of the onCallRoles]`),
+		},
+		{
+			name: "role without a query is rejected",
+			raw: `onCallRoles:
+- role: "@other-triage"`,
+			expectedErr: fmt.Errorf("invalid config: onCallRoles[0]: both role and query must be set"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var path string
+			if tc.raw != "" {
+				path = filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(path, []byte(tc.raw), 0644); err != nil {
+					t.Fatalf("failed to write config: %v", err)
+				}
+			}
+			actual, actualErr := loadConfig(path)
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("config differs from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedErr, actualErr, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("error differs from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+type fakePagerDutyClient struct {
+	t         *testing.T
+	users     []pagerduty.User
+	overrides []pagerduty.Override
+	incidents [][]pagerduty.Incident
+}
+
+func (c *fakePagerDutyClient) ListSchedules(pagerduty.ListSchedulesOptions) (*pagerduty.ListSchedulesResponse, error) {
+	return &pagerduty.ListSchedulesResponse{Schedules: []pagerduty.Schedule{{APIObject: pagerduty.APIObject{ID: "schedule"}}}}, nil
+}
+
+func (c *fakePagerDutyClient) ListOnCallUsers(string, pagerduty.ListOnCallUsersOptions) ([]pagerduty.User, error) {
+	return c.users, nil
+}
+
+func (c *fakePagerDutyClient) ListOverrides(string, pagerduty.ListOverridesOptions) (*pagerduty.ListOverridesResponse, error) {
+	if len(c.users) == 1 {
+		c.t.Error("overrides must not be listed when there is a single on-call user")
+	}
+	return &pagerduty.ListOverridesResponse{Overrides: c.overrides}, nil
+}
+
+func (c *fakePagerDutyClient) GetUser(id string, _ pagerduty.GetUserOptions) (*pagerduty.User, error) {
+	for i := range c.users {
+		if c.users[i].ID == id {
+			return &c.users[i], nil
+		}
+	}
+	return nil, fmt.Errorf("user %s not found", id)
+}
+
+func (c *fakePagerDutyClient) ListIncidents(o pagerduty.ListIncidentsOptions) (*pagerduty.ListIncidentsResponse, error) {
+	if diff := cmp.Diff([]string{"triggered", "acknowledged", "resolved"}, o.Statuses); diff != "" {
+		c.t.Errorf("incidents are listed with unexpected statuses:\n%s", diff)
+	}
+	var offset uint
+	for i, page := range c.incidents {
+		if offset == o.Offset {
+			return &pagerduty.ListIncidentsResponse{APIListObject: pagerduty.APIListObject{More: i < len(c.incidents)-1}, Incidents: page}, nil
+		}
+		offset += uint(len(page))
+	}
+	return nil, fmt.Errorf("no incidents at offset %d", o.Offset)
+}
+
+func TestRecentIncidents(t *testing.T) {
+	incident := func(id string) pagerduty.Incident {
+		return pagerduty.Incident{Id: id}
+	}
+	testCases := []struct {
+		name        string
+		incidents   [][]pagerduty.Incident
+		expected    []pagerduty.Incident
+		expectedErr error
+	}{
+		{
+			name:      "single page",
+			incidents: [][]pagerduty.Incident{{incident("a"), incident("b")}},
+			expected:  []pagerduty.Incident{incident("a"), incident("b")},
+		},
+		{
+			name:      "multiple pages are followed",
+			incidents: [][]pagerduty.Incident{{incident("a"), incident("b")}, {incident("c")}},
+			expected:  []pagerduty.Incident{incident("a"), incident("b"), incident("c")},
+		},
+		{
+			name:        "error listing incidents",
+			expectedErr: errors.New("could not list incidents: no incidents at offset 0"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakePagerDutyClient{t: t, incidents: tc.incidents}
+			actual, actualErr := recentIncidents(client, []string{"service"}, time.Now())
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("incidents differ from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedErr, actualErr, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("error differs from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetIncidentBlocks(t *testing.T) {
+	header := &slack.HeaderBlock{
+		Type: slack.MBTHeader,
+		Text: &slack.TextBlockObject{Type: slack.PlainTextType, Text: "Incidents in the Last 24h"},
+	}
+	incident := func(service, status string) pagerduty.Incident {
+		return pagerduty.Incident{Service: pagerduty.APIObject{ID: service + "-id", Summary: service}, Status: status}
+	}
+	testCases := []struct {
+		name      string
+		incidents []pagerduty.Incident
+		expected  []slack.Block
+	}{
+		{
+			name: "no incidents",
+			expected: []slack.Block{header, &slack.SectionBlock{
+				Type: slack.MBTSection,
+				Text: &slack.TextBlockObject{Type: slack.PlainTextType, Text: "No incidents were triggered, it was a quiet night."},
+			}},
+		},
+		{
+			name: "incidents are grouped by service",
+			incidents: []pagerduty.Incident{
+				incident("ci-operator", "triggered"),
+				incident("boskos", "acknowledged"),
+				incident("ci-operator", "acknowledged"),
+				incident("ci-operator", "triggered"),
+				incident("boskos", "resolved"),
+			},
+			expected: []slack.Block{header, &slack.SectionBlock{
+				Type: slack.MBTSection,
+				Fields: []*slack.TextBlockObject{
+					{Type: slack.PlainTextType, Text: "boskos"},
+					{Type: slack.MarkdownType, Text: "*0* triggered, *1* acknowledged, *1* resolved"},
+					{Type: slack.PlainTextType, Text: "ci-operator"},
+					{Type: slack.MarkdownType, Text: "*2* triggered, *1* acknowledged, *0* resolved"},
+				},
+			}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, getIncidentBlocks(tc.incidents)); diff != "" {
+				t.Errorf("blocks differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetPagerDutyBlocks(t *testing.T) {
+	roles := []onCallRole{{Role: roleTriagePrimary}, {Role: roleHelpdesk}, {Role: roleIntake}}
+	testCases := []struct {
+		name               string
+		userIdsByRole      map[string]user
+		expectedFields     []*slack.TextBlockObject
+		expectedUnresolved string
+	}{
+		{
+			name:          "all roles resolved",
+			userIdsByRole: map[string]user{roleTriagePrimary: {slackId: "U1"}, roleHelpdesk: {slackId: "U2"}, roleIntake: {slackId: "U3"}},
+			expectedFields: []*slack.TextBlockObject{
+				{Type: slack.PlainTextType, Text: roleTriagePrimary}, {Type: slack.MarkdownType, Text: "<@U1>"},
+				{Type: slack.PlainTextType, Text: roleHelpdesk}, {Type: slack.MarkdownType, Text: "<@U2>"},
+				{Type: slack.PlainTextType, Text: roleIntake}, {Type: slack.MarkdownType, Text: "<@U3>"},
+			},
+		},
+		{
+			name:          "unresolved roles are called out",
+			userIdsByRole: map[string]user{roleHelpdesk: {slackId: "U2"}},
+			expectedFields: []*slack.TextBlockObject{
+				{Type: slack.PlainTextType, Text: roleHelpdesk}, {Type: slack.MarkdownType, Text: "<@U2>"},
+			},
+			expectedUnresolved: fmt.Sprintf(":warning: Could not resolve: %s, %s", roleTriagePrimary, roleIntake),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			blocks := getPagerDutyBlocks(roles, tc.userIdsByRole)
+			var fields []*slack.TextBlockObject
+			var unresolved string
+			for _, block := range blocks {
+				section, ok := block.(*slack.SectionBlock)
+				if !ok {
+					continue
+				}
+				if section.Fields != nil {
+					fields = section.Fields
+				} else if strings.HasPrefix(section.Text.Text, ":warning:") {
+					unresolved = section.Text.Text
+				}
+			}
+			if diff := cmp.Diff(tc.expectedFields, fields); diff != "" {
+				t.Errorf("fields differ from expected:\n%s", diff)
+			}
+			if unresolved != tc.expectedUnresolved {
+				t.Errorf("expected unresolved note %q, got %q", tc.expectedUnresolved, unresolved)
+			}
+		})
+	}
+}
+
+func TestUserOnCallDuring(t *testing.T) {
+	since := time.Date(2024, time.March, 4, 8, 0, 1, 0, time.UTC)
+	until := since.Add(13 * time.Hour).Add(-2 * time.Second)
+	at := func(hour int) string {
+		return time.Date(2024, time.March, 4, hour, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	}
+	alice := pagerduty.User{APIObject: pagerduty.APIObject{ID: "alice"}, Name: "Alice"}
+	bob := pagerduty.User{APIObject: pagerduty.APIObject{ID: "bob"}, Name: "Bob"}
+	carol := pagerduty.User{APIObject: pagerduty.APIObject{ID: "carol"}, Name: "Carol"}
+	override := func(user pagerduty.User, start, end int) pagerduty.Override {
+		return pagerduty.Override{ID: user.ID + "-override", Start: at(start), End: at(end), User: user.APIObject}
+	}
+
+	testCases := []struct {
+		name        string
+		users       []pagerduty.User
+		overrides   []pagerduty.Override
+		expected    *pagerduty.User
+		expectedErr error
+	}{
+		{
+			name:     "single user is returned without looking at overrides",
+			users:    []pagerduty.User{alice},
+			expected: &alice,
+		},
+		{
+			name:      "single override",
+			users:     []pagerduty.User{alice, bob},
+			overrides: []pagerduty.Override{override(bob, 7, 23)},
+			expected:  &bob,
+		},
+		{
+			name:      "handoff override and a short secondary override, the one covering the midpoint wins",
+			users:     []pagerduty.User{alice, bob, carol},
+			overrides: []pagerduty.Override{override(carol, 9, 11), override(bob, 7, 23)},
+			expected:  &bob,
+		},
+		{
+			name:      "two overlapping overrides covering the midpoint, the longer one wins",
+			users:     []pagerduty.User{alice, bob, carol},
+			overrides: []pagerduty.Override{override(carol, 14, 15), override(bob, 12, 20)},
+			expected:  &bob,
+		},
+		{
+			name:      "no override covers the midpoint, the longest covering one wins",
+			users:     []pagerduty.User{alice, bob, carol},
+			overrides: []pagerduty.Override{override(carol, 16, 18), override(bob, 8, 12)},
+			expected:  &bob,
+		},
+		{
+			name:        "multiple users but no overrides",
+			users:       []pagerduty.User{alice, bob},
+			expectedErr: fmt.Errorf("could not determine the override for the 'query' on-call: did not get any overrides"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakePagerDutyClient{t: t, users: tc.users, overrides: tc.overrides}
+			actual, actualErr := userOnCallDuring(client, "query", since, until)
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("user differs from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedErr, actualErr, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("error differs from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+type fakeDigestClient struct {
+	messages   []slack.Message
+	historyErr error
+	updateErr  error
+
+	posted  int
+	updated []string
+}
+
+func (f *fakeDigestClient) GetConversationHistory(*slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+	if f.historyErr != nil {
+		return nil, f.historyErr
+	}
+	return &slack.GetConversationHistoryResponse{Messages: f.messages}, nil
+}
+
+func (f *fakeDigestClient) PostMessage(channelID string, _ ...slack.MsgOption) (string, string, error) {
+	f.posted++
+	return channelID, "new", nil
+}
+
+func (f *fakeDigestClient) UpdateMessage(channelID, timestamp string, _ ...slack.MsgOption) (string, string, string, error) {
+	if f.updateErr != nil {
+		return "", "", "", f.updateErr
+	}
+	f.updated = append(f.updated, timestamp)
+	return channelID, timestamp, "", nil
+}
+
+func TestPostOrUpdateDigest(t *testing.T) {
+	digest := func(timestamp string) slack.Message {
+		return slack.Message{Msg: slack.Msg{Text: teamDigestText, Timestamp: timestamp}}
+	}
+	other := slack.Message{Msg: slack.Msg{Text: "something else", Timestamp: "3"}}
+
+	testCases := []struct {
+		name            string
+		client          *fakeDigestClient
+		updateWindow    time.Duration
+		expectedPosted  int
+		expectedUpdated []string
+	}{
+		{
+			name:           "zero window always posts a new digest",
+			client:         &fakeDigestClient{messages: []slack.Message{digest("2")}},
+			expectedPosted: 1,
+		},
+		{
+			name:            "newest previous digest is updated",
+			client:          &fakeDigestClient{messages: []slack.Message{other, digest("2"), digest("1")}},
+			updateWindow:    24 * time.Hour,
+			expectedUpdated: []string{"2"},
+		},
+		{
+			name:           "no previous digest posts a new one",
+			client:         &fakeDigestClient{messages: []slack.Message{other}},
+			updateWindow:   24 * time.Hour,
+			expectedPosted: 1,
+		},
+		{
+			name:           "failure to read the history posts a new digest",
+			client:         &fakeDigestClient{historyErr: errors.New("injected")},
+			updateWindow:   24 * time.Hour,
+			expectedPosted: 1,
+		},
+		{
+			name:           "failure to update posts a new digest",
+			client:         &fakeDigestClient{messages: []slack.Message{digest("2")}, updateErr: errors.New("injected")},
+			updateWindow:   24 * time.Hour,
+			expectedPosted: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := postOrUpdateDigest(tc.client, "channel", nil, tc.updateWindow, time.Now()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.client.posted != tc.expectedPosted {
+				t.Errorf("expected %d posted digests, got %d", tc.expectedPosted, tc.client.posted)
+			}
+			if diff := cmp.Diff(tc.expectedUpdated, tc.client.updated); diff != "" {
+				t.Errorf("updated digests differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestPostBuild02UpgradeReminder(t *testing.T) {
+	reminder := func(version string) slack.Message {
+		return slack.Message{Msg: slack.Msg{Text: fmt.Sprintf(build02UpgradeText, version), Timestamp: "2"}}
+	}
+	other := slack.Message{Msg: slack.Msg{Text: "something else", Timestamp: "3"}}
+
+	testCases := []struct {
+		name           string
+		client         *fakeDigestClient
+		interval       time.Duration
+		expectedPosted int
+	}{
+		{
+			name:           "no previous reminder posts one",
+			client:         &fakeDigestClient{messages: []slack.Message{other}},
+			interval:       24 * time.Hour,
+			expectedPosted: 1,
+		},
+		{
+			name:     "reminder for the same version is not posted again",
+			client:   &fakeDigestClient{messages: []slack.Message{other, reminder("4.16.3")}},
+			interval: 24 * time.Hour,
+		},
+		{
+			name:           "reminder for a different version posts one",
+			client:         &fakeDigestClient{messages: []slack.Message{reminder("4.16.2")}},
+			interval:       24 * time.Hour,
+			expectedPosted: 1,
+		},
+		{
+			name:           "zero interval always posts the reminder",
+			client:         &fakeDigestClient{messages: []slack.Message{reminder("4.16.3")}},
+			expectedPosted: 1,
+		},
+		{
+			name:           "failure to read the history posts the reminder",
+			client:         &fakeDigestClient{historyErr: errors.New("injected")},
+			interval:       24 * time.Hour,
+			expectedPosted: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := postBuild02UpgradeReminder(tc.client, "channel", "4.16.3", nil, tc.interval, time.Now()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.client.posted != tc.expectedPosted {
+				t.Errorf("expected %d posted reminders, got %d", tc.expectedPosted, tc.client.posted)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -15,8 +16,10 @@ import (
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/flagutil"
 	"sigs.k8s.io/prow/pkg/interrupts"
@@ -27,7 +30,23 @@ import (
 	"github.com/openshift/ci-tools/pkg/vaultclient"
 )
 
-const objectPrefix = "secret-collection-manager-managed"
+const (
+	objectPrefix = "secret-collection-manager-managed"
+	// readOnlySuffix is appended to the prefixed name of a secret collection to get the
+	// name of the policy and group that grant read-only access to it. Collection names
+	// can not contain an underscore, so it never clashes with the name of another collection.
+	readOnlySuffix = "_read-only"
+	// indexItemName is the name of the placeholder item that makes new secret collections show up in the Vault UI
+	indexItemName = "index"
+	// auditSuffix is appended to the kv store prefix to get the folder that holds the audit trails.
+	// It is outside of the paths the members of the secret collections have access to.
+	auditSuffix = "-audit"
+	// auditArchiveSuffix is appended to the kv store prefix to get the folder that holds the audit
+	// trails of deleted secret collections whose name got reused
+	auditArchiveSuffix = "-audit-archive"
+	// userNamesCacheTTL is how long the list of all usernames served by the users endpoint is cached
+	userNamesCacheTTL = time.Minute
+)
 
 type option struct {
 	// Folder under which to create policies
@@ -38,6 +57,10 @@ type option struct {
 	vaultRole     string
 
 	authBackendType string
+
+	maxCollectionsPerUser int
+	collectionLimitAdmins flagutil.Strings
+	admins                flagutil.Strings
 	flagutil.InstrumentationOptions
 }
 
@@ -49,6 +72,9 @@ func parseOptions() (*option, error) {
 	flag.StringVar(&o.vaultToken, "vault-token", "", "The privileged token to use when communicating with vault, must be able to CRUD policies")
 	flag.StringVar(&o.vaultRole, "vault-role", "", "The vault role to use, must be able to CRUD policies. Will be used for kubernetes service account auth.")
 	flag.StringVar(&o.authBackendType, "auth-backend-type", "oidc", "The backend type used for user authentication.")
+	flag.IntVar(&o.maxCollectionsPerUser, "max-collections-per-user", 0, "The maximum number of secret collections a user may be a member of to create a new one. Zero means no limit.")
+	flag.Var(&o.collectionLimitAdmins, "collection-limit-admin", "A user that is not subject to --max-collections-per-user. Can be passed multiple times.")
+	flag.Var(&o.admins, "admin", "A user that may access the read-only admin view of all secret collections. Can be passed multiple times.")
 	o.InstrumentationOptions.AddFlags(flag.CommandLine)
 	flag.Parse()
 
@@ -56,6 +82,9 @@ func parseOptions() (*option, error) {
 	if o.vaultToken == "" && o.vaultRole == "" {
 		errs = append(errs, errors.New("--vault-token or --vault-role is required"))
 	}
+	if o.maxCollectionsPerUser < 0 {
+		errs = append(errs, errors.New("--max-collections-per-user must not be negative"))
+	}
 	if err := o.InstrumentationOptions.Validate(false); err != nil {
 		errs = append(errs, err)
 	}
@@ -97,7 +126,7 @@ func main() {
 
 	metrics.ExposeMetrics(version.Name, config.PushGateway{}, o.MetricsPort)
 
-	manager, server := server(privilegedVaultClient, o.authBackendType, o.kvStorePrefix, o.listenAddr)
+	manager, server := server(privilegedVaultClient, o.authBackendType, o.kvStorePrefix, o.listenAddr, o.maxCollectionsPerUser, o.collectionLimitAdmins.StringSet(), o.admins.StringSet())
 	reconciledPolicies, err := manager.reconcilePolicies()
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to reconcile policies")
@@ -105,6 +134,13 @@ func main() {
 	if len(reconciledPolicies) > 0 {
 		logrus.WithField("reconciled_policies", reconciledPolicies).Info("Successfully reconciled policies")
 	}
+	repairedObjects, err := manager.reconcileOrphans()
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to reconcile orphaned groups and policies")
+	}
+	if len(repairedObjects) > 0 {
+		logrus.WithField("repaired_objects", repairedObjects).Info("Successfully reconciled orphaned groups and policies")
+	}
 	interrupts.TickLiteral(func() {
 		reconciledPolicies, err := manager.reconcilePolicies()
 		if err != nil {
@@ -113,18 +149,29 @@ func main() {
 		if len(reconciledPolicies) > 0 {
 			logrus.WithField("reconciled_policies", reconciledPolicies).Info("Successfully reconciled policies")
 		}
+		repairedObjects, err := manager.reconcileOrphans()
+		if err != nil {
+			logrus.WithError(err).Error("Failed to reconcile orphaned groups and policies")
+		}
+		if len(repairedObjects) > 0 {
+			logrus.WithField("repaired_objects", repairedObjects).Info("Successfully reconciled orphaned groups and policies")
+		}
 	}, time.Hour)
 	interrupts.ListenAndServe(server, 5*time.Second)
 	interrupts.WaitForGracefulShutdown()
 }
 
-func server(privilegedVaultClient *vaultclient.VaultClient, authBackendType, kvStorePrefix, listenAddr string) (*secretCollectionManager, *http.Server) {
+func server(privilegedVaultClient *vaultclient.VaultClient, authBackendType, kvStorePrefix, listenAddr string, maxCollectionsPerUser int, collectionLimitAdmins, admins sets.Set[string]) (*secretCollectionManager, *http.Server) {
 	manager := &secretCollectionManager{
 		privilegedVaultClient:   privilegedVaultClient,
 		kvStorePrefix:           kvStorePrefix,
 		kvMetadataPrefix:        vaultclient.InsertMetadataIntoPath(kvStorePrefix),
 		kvDataPrefix:            vaultclient.InsertDataIntoPath(kvStorePrefix),
 		authAccessorBackendType: authBackendType,
+		maxCollectionsPerUser:   maxCollectionsPerUser,
+		collectionLimitAdmins:   collectionLimitAdmins,
+		admins:                  admins,
+		usersRateLimiter:        newPerClientRateLimiter(rate.Every(10*time.Second), 5),
 	}
 
 	return manager, &http.Server{Addr: listenAddr, Handler: manager.mux()}
@@ -150,10 +197,23 @@ type secretCollectionManager struct {
 	kvDataPrefix          string
 	groupCache            idNameCache
 	userCache             idNameCache
+	userNamesCache        userNamesCache
+	// usersRateLimiter limits how often a single user may list all users while the list is not cached
+	usersRateLimiter *perClientRateLimiter
 
 	authAccessorBackendType   string
 	authAccessorBackendID     string
 	authAccessorBackendIDLock sync.RWMutex
+
+	// maxCollectionsPerUser limits the number of collections a user can be a member of
+	// and still create new ones, zero means no limit. collectionLimitAdmins are exempt.
+	maxCollectionsPerUser int
+	collectionLimitAdmins sets.Set[string]
+	// admins may list all secret collections
+	admins sets.Set[string]
+
+	// auditLocks holds a *sync.Mutex per secret collection that serializes changes to its audit trail
+	auditLocks sync.Map
 }
 
 // idNameCache allows to get the id or the name, using
@@ -190,6 +250,46 @@ func (c *idNameCache) set(name, id string) {
 	c.ids[id] = name
 }
 
+// userNamesCache holds the sorted list of all usernames. Resolving it requires
+// a request to Vault for every identity, so it is cached for a short time and
+// invalidated whenever users or collections are changed.
+type userNamesCache struct {
+	lock      sync.Mutex
+	userNames []string
+	expires   time.Time
+	// generation is increased on every invalidation, so a listing that was
+	// started before an invalidation doesn't end up in the cache
+	generation int
+}
+
+// get returns the cached usernames if they are still valid, and the
+// generation to pass to set after resolving them otherwise.
+func (c *userNamesCache) get(now time.Time) ([]string, int, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.userNames == nil || now.After(c.expires) {
+		return nil, c.generation, false
+	}
+	return c.userNames, c.generation, true
+}
+
+func (c *userNamesCache) set(userNames []string, generation int, now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.userNames = userNames
+	c.expires = now.Add(userNamesCacheTTL)
+}
+
+func (c *userNamesCache) invalidate() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.userNames = nil
+	c.generation++
+}
+
 func (m *secretCollectionManager) mux() *instrumentationWrapper {
 	router := newInstrumentedRouter()
 	// Do not redirect something like POST secretcollection/ where someone tried to
@@ -202,8 +302,12 @@ func (m *secretCollectionManager) mux() *instrumentationWrapper {
 	router.GET("/secretcollection", loggingWrapper(userWrapper(m.listSecretCollections)))
 	router.PUT("/secretcollection/:name", loggingWrapper(userWrapper(m.createSecretCollectionHandler)))
 	router.PUT("/secretcollection/:name/members", loggingWrapper(userWrapper(m.updateSecretCollectionMembersHandler)))
+	router.GET("/secretcollection/:name/audit", loggingWrapper(userWrapper(m.auditHandler)))
+	router.GET("/secretcollection/:name/items", loggingWrapper(userWrapper(m.itemsHandler)))
 	router.DELETE("/secretcollection/:name", loggingWrapper(userWrapper(m.deleteCollectionHandler)))
 	router.GET("/users", loggingWrapper(userWrapper(m.usersHandler)))
+	router.POST("/users/:name/collections", loggingWrapper(userWrapper(m.bulkUpdateUserCollectionsHandler)))
+	router.GET("/admin/secretcollections", loggingWrapper(userWrapper(m.adminListSecretCollections)))
 	return router
 }
 
@@ -226,19 +330,26 @@ func redirectHandler(target string) httprouter.Handle {
 	}
 }
 
-func (m *secretCollectionManager) isUserMemberInSecretCollection(l *logrus.Entry, user, collectionName string) (bool, error) {
+// isUserMemberInSecretCollection checks if the user is a member of the secret collection. Read-only members
+// are only considered if includeReadOnly is set, as they may only see the collection, not manage it.
+func (m *secretCollectionManager) isUserMemberInSecretCollection(l *logrus.Entry, user, collectionName string, includeReadOnly bool) (bool, error) {
 	collections, err := m.getCollectionsForUser(l, user)
 	if err != nil {
 		return false, fmt.Errorf("failed to get sceret collections for user %s: %w", user, err)
 	}
+	return isMemberOf(collections, user, collectionName, includeReadOnly), nil
+}
 
+func isMemberOf(collections []secretCollection, user, collectionName string, includeReadOnly bool) bool {
 	for _, collection := range collections {
-		if collection.Name == collectionName {
-			return true, nil
+		if collection.Name != collectionName {
+			continue
+		}
+		if slices.Contains(collection.Members, user) || (includeReadOnly && slices.Contains(collection.ReadOnlyMembers, user)) {
+			return true
 		}
 	}
-
-	return false, nil
+	return false
 }
 
 func (m *secretCollectionManager) deleteCollectionHandler(l *logrus.Entry, user string, w http.ResponseWriter, r *http.Request, params httprouter.Params) {
@@ -248,7 +359,7 @@ func (m *secretCollectionManager) deleteCollectionHandler(l *logrus.Entry, user
 		return
 	}
 
-	isMember, err := m.isUserMemberInSecretCollection(l, user, name)
+	isMember, err := m.isUserMemberInSecretCollection(l, user, name, false)
 	if err != nil {
 		l.WithError(err).Error("failed to check if user is member for secret collection")
 		http.Error(w, fmt.Sprintf("failed to check if user is allowed to delete secret collection. RequestID: %s", l.Data["UID"]), http.StatusInternalServerError)
@@ -259,15 +370,20 @@ func (m *secretCollectionManager) deleteCollectionHandler(l *logrus.Entry, user
 		return
 	}
 
-	if err := m.deleteCollection(name); err != nil {
+	if err := m.deleteCollection(user, name); err != nil {
 		l.WithError(err).Error("Failed to delete colection")
 		http.Error(w, fmt.Sprintf("failed to delete secret collection. RequestID: %s", l.Data["UID"]), 500)
 	}
 }
 
-func (m *secretCollectionManager) deleteCollection(name string) error {
+func (m *secretCollectionManager) deleteCollection(user, name string) error {
+	collection, err := m.getCollectionsFromGroupName(prefixedName(name))
+	if err != nil {
+		return fmt.Errorf("failed to get secret collection %s: %w", name, err)
+	}
+
 	// First delete the data, then the group to be sure that users retain access until all
-	// data is deleted.
+	// data is deleted. The audit trail is kept to be able to investigate the deletion.
 	path := m.kvStorePrefix + "/" + name
 	allItems, err := m.privilegedVaultClient.ListKVRecursively(path)
 	if err != nil {
@@ -279,9 +395,83 @@ func (m *secretCollectionManager) deleteCollection(name string) error {
 		}
 	}
 
+	if err := m.appendAuditEntry(auditEntry{
+		Timestamp:          time.Now(),
+		User:               user,
+		Collection:         name,
+		OldMembers:         collection.Members,
+		OldReadOnlyMembers: collection.ReadOnlyMembers,
+	}); err != nil {
+		return err
+	}
+
+	defer m.userNamesCache.invalidate()
+	if err := m.privilegedVaultClient.DeleteGroupByName(readOnlyPrefixedName(name)); err != nil && !vaultclient.IsNotFound(err) {
+		return fmt.Errorf("failed to delete group %s: %w", readOnlyPrefixedName(name), err)
+	}
 	return m.privilegedVaultClient.DeleteGroupByName(prefixedName(name))
 }
 
+func (m *secretCollectionManager) itemsHandler(l *logrus.Entry, user string, w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	name := params.ByName("name")
+	if name == "" {
+		http.Error(w, "name url parameter must not be empty", 400)
+		return
+	}
+
+	isMember, err := m.isUserMemberInSecretCollection(l, user, name, true)
+	if err != nil {
+		l.WithError(err).Error("failed to check if user is member for secret collection")
+		http.Error(w, fmt.Sprintf("failed to check if user is allowed to list the secret collection. RequestID: %s", l.Data["UID"]), http.StatusInternalServerError)
+		return
+	}
+	if !isMember {
+		http.Error(w, fmt.Sprintf("secret collection not found. RequestID: %s", l.Data["UID"]), 404)
+		return
+	}
+
+	items, err := m.collectionItems(name)
+	if err != nil {
+		l.WithError(err).Error("failed to list items")
+		http.Error(w, fmt.Sprintf("failed to list items of secret collection. RequestID: %s", l.Data["UID"]), 500)
+		return
+	}
+
+	if len(items) == 0 {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	serialized, err := json.Marshal(items)
+	if err != nil {
+		l.WithError(err).Error("failed to serialize")
+		http.Error(w, fmt.Sprintf("failed to serialize. RequestID: %s", l.Data["UID"]), 500)
+		return
+	}
+	if _, err := w.Write(serialized); err != nil {
+		l.WithError(err).Error("failed to write response")
+	}
+}
+
+// collectionItems returns the paths of all items in a secret collection relative to the collection,
+// without the ones the manager creates itself. It never reads the items, so no secret values are exposed.
+func (m *secretCollectionManager) collectionItems(name string) ([]string, error) {
+	path := m.kvStorePrefix + "/" + name
+	allItems, err := m.privilegedVaultClient.ListKVRecursively(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items below %s: %w", path, err)
+	}
+	var items []string
+	for _, item := range allItems {
+		relativePath := strings.TrimPrefix(item, path+"/")
+		if relativePath == indexItemName {
+			continue
+		}
+		items = append(items, relativePath)
+	}
+	sort.Strings(items)
+	return items, nil
+}
+
 func (m *secretCollectionManager) updateSecretCollectionMembersHandler(l *logrus.Entry, user string, w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	name := params.ByName("name")
 	if name == "" {
@@ -289,7 +479,7 @@ func (m *secretCollectionManager) updateSecretCollectionMembersHandler(l *logrus
 		return
 	}
 
-	isMember, err := m.isUserMemberInSecretCollection(l, user, name)
+	isMember, err := m.isUserMemberInSecretCollection(l, user, name, false)
 	if err != nil {
 		l.WithError(err).Error("failed to check if user is member for secret collection")
 		http.Error(w, fmt.Sprintf("failed to check if user is allowed to change secret collection. RequestID: %s", l.Data["UID"]), http.StatusInternalServerError)
@@ -303,16 +493,16 @@ func (m *secretCollectionManager) updateSecretCollectionMembersHandler(l *logrus
 	var body secretCollectionUpdateBody
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		l.WithError(err).Debug("failed to decode request body")
-		http.Error(w, fmt.Sprintf(`failed to decode request body: %v, expected format: {"members": ["all", "desired", "members"]}`, err), http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf(`failed to decode request body: %v, expected format: {"members": ["all", "desired", "members"], "readOnlyMembers": ["optional", "read-only", "members"]}`, err), http.StatusBadRequest)
 		return
 	}
 
-	if len(body.Members) == 0 {
-		http.Error(w, "There must be at least one member", http.StatusBadRequest)
+	if err := validateMembers(body.Members, body.ReadOnlyMembers); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if err := m.updateSecretCollectionMembers(l, name, body.Members); err != nil {
+	if err := m.updateSecretCollectionMembers(l, user, name, body.Members, body.ReadOnlyMembers); err != nil {
 		logrus.WithError(err).Error("failed to update secret collection members")
 		http.Error(w, fmt.Sprintf("error updating secret collection members. RequestID: %s", l.Data["UID"]), 500)
 		return
@@ -320,23 +510,312 @@ func (m *secretCollectionManager) updateSecretCollectionMembersHandler(l *logrus
 	w.WriteHeader(http.StatusOK)
 }
 
-func (m *secretCollectionManager) updateSecretCollectionMembers(_ *logrus.Entry, collectionName string, updatedMemberNames []string) error {
+// validateMembers checks that a secret collection keeps at least one member and that
+// nobody is both a member and a read-only member
+func validateMembers(members, readOnlyMembers []string) error {
+	if len(members) == 0 {
+		return errors.New("there must be at least one member")
+	}
 	var errs []error
-	var updatedMemberIDs []string
-	for _, memberName := range updatedMemberNames {
-		entity, err := m.userByAliasCached(memberName)
-		if err != nil {
-			errs = append(errs, fmt.Errorf("failed to find member %s: %w", memberName, err))
-			continue
+	for _, member := range readOnlyMembers {
+		if slices.Contains(members, member) {
+			errs = append(errs, fmt.Errorf("%s can not be both a member and a read-only member", member))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (m *secretCollectionManager) updateSecretCollectionMembers(_ *logrus.Entry, user, collectionName string, updatedMemberNames, updatedReadOnlyMemberNames []string) error {
+	collection, err := m.getCollectionsFromGroupName(prefixedName(collectionName))
+	if err != nil {
+		return fmt.Errorf("failed to get secret collection %s: %w", collectionName, err)
+	}
+
+	var errs []error
+	memberIDsFor := func(memberNames []string) []string {
+		var ids []string
+		for _, memberName := range memberNames {
+			entity, err := m.userByAliasCached(memberName)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to find member %s: %w", memberName, err))
+				continue
+			}
+			ids = append(ids, entity.ID)
 		}
-		updatedMemberIDs = append(updatedMemberIDs, entity.ID)
+		return ids
 	}
+	updatedMemberIDs := memberIDsFor(updatedMemberNames)
+	updatedReadOnlyMemberIDs := memberIDsFor(updatedReadOnlyMemberNames)
 	if err := utilerrors.NewAggregate(errs); err != nil {
 		return fmt.Errorf("failed to validate members: %w", err)
 	}
 
+	if err := m.updateGroupMembers(collectionName, updatedMemberIDs, updatedReadOnlyMemberNames, updatedReadOnlyMemberIDs); err != nil {
+		return err
+	}
+	m.userNamesCache.invalidate()
+
+	entry := auditEntry{
+		Timestamp:          time.Now(),
+		User:               user,
+		Collection:         collectionName,
+		OldMembers:         collection.Members,
+		NewMembers:         updatedMemberNames,
+		OldReadOnlyMembers: collection.ReadOnlyMembers,
+		NewReadOnlyMembers: collection.ReadOnlyMembers,
+	}
+	if updatedReadOnlyMemberNames != nil {
+		entry.NewReadOnlyMembers = updatedReadOnlyMemberNames
+	}
+	return m.appendAuditEntry(entry)
+}
+
+// bulkUpdateUserCollectionsHandler adds a user to or removes them from several secret collections at once.
+// Every collection is updated separately and only if the requesting user is a member of it.
+func (m *secretCollectionManager) bulkUpdateUserCollectionsHandler(l *logrus.Entry, user string, w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	name := params.ByName("name")
+	if name == "" {
+		http.Error(w, "name url parameter must not be empty", 400)
+		return
+	}
+
+	var body bulkMembershipUpdateBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		l.WithError(err).Debug("failed to decode request body")
+		http.Error(w, fmt.Sprintf(`failed to decode request body: %v, expected format: {"add": ["collections", "to", "add", "the", "user", "to"], "remove": ["collections", "to", "remove", "the", "user", "from"]}`, err), http.StatusBadRequest)
+		return
+	}
+	if len(body.Add) == 0 && len(body.Remove) == 0 {
+		http.Error(w, "There must be at least one collection to add the user to or remove them from", http.StatusBadRequest)
+		return
+	}
+	if both := sets.New[string](body.Add...).Intersection(sets.New[string](body.Remove...)); both.Len() > 0 {
+		http.Error(w, fmt.Sprintf("collections %v can not be both in add and remove", sets.List(both)), http.StatusBadRequest)
+		return
+	}
+
+	collections, err := m.getCollectionsForUser(l, user)
+	if err != nil {
+		l.WithError(err).Error("failed to get secret collections for user")
+		http.Error(w, fmt.Sprintf("failed to get secret collections. RequestID: %s", l.Data["UID"]), http.StatusInternalServerError)
+		return
+	}
+	collectionsByName := map[string]secretCollection{}
+	for _, collection := range collections {
+		if slices.Contains(collection.Members, user) {
+			collectionsByName[collection.Name] = collection
+		}
+	}
+
+	results := map[string]bulkMembershipUpdateResult{}
+	for _, update := range []struct {
+		collections []string
+		add         bool
+	}{{collections: body.Add, add: true}, {collections: body.Remove, add: false}} {
+		for _, collectionName := range update.collections {
+			collection, isMember := collectionsByName[collectionName]
+			if !isMember {
+				results[collectionName] = bulkMembershipUpdateResult{Error: "secret collection not found"}
+				continue
+			}
+			members, readOnlyMembers, err := membershipAfterBulkUpdate(collection, name, update.add)
+			if err != nil {
+				results[collectionName] = bulkMembershipUpdateResult{Error: err.Error()}
+				continue
+			}
+			if err := m.updateSecretCollectionMembers(l, user, collectionName, members, readOnlyMembers); err != nil {
+				l.WithError(err).WithField("collection", collectionName).Error("failed to update secret collection members")
+				results[collectionName] = bulkMembershipUpdateResult{Error: fmt.Sprintf("error updating secret collection members. RequestID: %s", l.Data["UID"])}
+				continue
+			}
+			results[collectionName] = bulkMembershipUpdateResult{Success: true}
+		}
+	}
+
+	serialized, err := json.Marshal(results)
+	if err != nil {
+		l.WithError(err).Error("failed to serialize")
+		http.Error(w, fmt.Sprintf("failed to serialize. RequestID: %s", l.Data["UID"]), 500)
+		return
+	}
+	if _, err := w.Write(serialized); err != nil {
+		l.WithError(err).Error("failed to write response")
+	}
+}
+
+// membershipAfterBulkUpdate returns the members and read-only members of the collection after adding
+// the user to or removing them from it. The read-only members are nil if they do not change. A user
+// that is added as a member is no longer a read-only member.
+func membershipAfterBulkUpdate(collection secretCollection, userName string, add bool) ([]string, []string, error) {
+	members := slices.DeleteFunc(slices.Clone(collection.Members), func(member string) bool { return member == userName })
+	if add {
+		members = append(members, userName)
+	} else if len(members) == 0 {
+		return nil, nil, errors.New("there must be at least one member")
+	}
+
+	var readOnlyMembers []string
+	if slices.Contains(collection.ReadOnlyMembers, userName) {
+		readOnlyMembers = slices.DeleteFunc(slices.Clone(collection.ReadOnlyMembers), func(member string) bool { return member == userName })
+	}
+	return members, readOnlyMembers, nil
+}
+
+func (m *secretCollectionManager) updateGroupMembers(collectionName string, updatedMemberIDs []string, updatedReadOnlyMemberNames, updatedReadOnlyMemberIDs []string) error {
 	// This is a tad unsafe in case someone else removed us from this group. Would be great to have preconditions :/
-	return m.privilegedVaultClient.UpdateGroupMembers(prefixedName(collectionName), updatedMemberIDs)
+	if err := m.privilegedVaultClient.UpdateGroupMembers(prefixedName(collectionName), updatedMemberIDs); err != nil {
+		return err
+	}
+
+	// Requests that do not mention the read-only members leave them untouched
+	if updatedReadOnlyMemberNames == nil {
+		return nil
+	}
+
+	// The read-only group is only created once the first read-only member gets added
+	if _, err := m.privilegedVaultClient.GetGroupByName(readOnlyPrefixedName(collectionName)); err != nil {
+		if !vaultclient.IsNotFound(err) {
+			return fmt.Errorf("failed to get group %s: %w", readOnlyPrefixedName(collectionName), err)
+		}
+		if len(updatedReadOnlyMemberIDs) == 0 {
+			return nil
+		}
+		return m.createGroupWithPolicy(collectionName, true, updatedReadOnlyMemberIDs)
+	}
+	return m.privilegedVaultClient.UpdateGroupMembers(readOnlyPrefixedName(collectionName), updatedReadOnlyMemberIDs)
+}
+
+func (m *secretCollectionManager) auditPath(collectionName string) string {
+	return m.kvStorePrefix + auditSuffix + "/" + collectionName
+}
+
+func (m *secretCollectionManager) auditLock(collectionName string) *sync.Mutex {
+	lock, _ := m.auditLocks.LoadOrStore(collectionName, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+// appendAuditEntry adds an entry to the audit trail of a secret collection. Entries are
+// keyed by their timestamp, existing ones are never changed.
+func (m *secretCollectionManager) appendAuditEntry(entry auditEntry) error {
+	lock := m.auditLock(entry.Collection)
+	lock.Lock()
+	defer lock.Unlock()
+
+	path := m.auditPath(entry.Collection)
+	data := map[string]string{}
+	current, err := m.privilegedVaultClient.GetKV(path)
+	if err != nil && !vaultclient.IsNotFound(err) {
+		return fmt.Errorf("failed to get audit trail of %s: %w", entry.Collection, err)
+	}
+	if current != nil {
+		for k, v := range current.Data {
+			data[k] = v
+		}
+	}
+	serialized, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to serialize audit entry: %w", err)
+	}
+	data[strconv.FormatInt(entry.Timestamp.UnixNano(), 10)] = string(serialized)
+	if err := m.privilegedVaultClient.UpsertKV(path, data); err != nil {
+		return fmt.Errorf("failed to write audit trail of %s: %w", entry.Collection, err)
+	}
+	return nil
+}
+
+// archiveAuditTrail moves the audit trail left behind by a deleted secret collection out of the
+// way, so that a new secret collection with the same name starts with an empty audit trail.
+func (m *secretCollectionManager) archiveAuditTrail(collectionName string) error {
+	lock := m.auditLock(collectionName)
+	lock.Lock()
+	defer lock.Unlock()
+
+	path := m.auditPath(collectionName)
+	current, err := m.privilegedVaultClient.GetKV(path)
+	if err != nil {
+		if vaultclient.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get audit trail of %s: %w", collectionName, err)
+	}
+	archivePath := m.kvStorePrefix + auditArchiveSuffix + "/" + collectionName + "/" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	if err := m.privilegedVaultClient.UpsertKV(archivePath, current.Data); err != nil {
+		return fmt.Errorf("failed to archive audit trail of %s: %w", collectionName, err)
+	}
+	if err := m.privilegedVaultClient.DestroyKVIrreversibly(path); err != nil {
+		return fmt.Errorf("failed to delete archived audit trail of %s: %w", collectionName, err)
+	}
+	return nil
+}
+
+// auditEntries returns the audit trail of a secret collection, sorted by time
+func (m *secretCollectionManager) auditEntries(collectionName string) ([]auditEntry, error) {
+	path := m.auditPath(collectionName)
+	current, err := m.privilegedVaultClient.GetKV(path)
+	if err != nil {
+		if vaultclient.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get audit trail of %s: %w", collectionName, err)
+	}
+
+	var entries []auditEntry
+	for key, value := range current.Data {
+		var entry auditEntry
+		if err := json.Unmarshal([]byte(value), &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal audit entry %s of %s: %w", key, collectionName, err)
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries, nil
+}
+
+func (m *secretCollectionManager) auditHandler(l *logrus.Entry, user string, w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	name := params.ByName("name")
+	if name == "" {
+		http.Error(w, "name url parameter must not be empty", 400)
+		return
+	}
+
+	// admins may see the audit trail of deleted secret collections, which have no members anymore
+	isMember := m.admins.Has(user)
+	if !isMember {
+		var err error
+		isMember, err = m.isUserMemberInSecretCollection(l, user, name, false)
+		if err != nil {
+			l.WithError(err).Error("failed to check if user is member for secret collection")
+			http.Error(w, fmt.Sprintf("failed to check if user is allowed to see the audit trail. RequestID: %s", l.Data["UID"]), http.StatusInternalServerError)
+			return
+		}
+	}
+	if !isMember {
+		http.Error(w, fmt.Sprintf("secret collection not found. RequestID: %s", l.Data["UID"]), 404)
+		return
+	}
+
+	entries, err := m.auditEntries(name)
+	if err != nil {
+		l.WithError(err).Error("failed to get audit entries")
+		http.Error(w, fmt.Sprintf("failed to get audit trail. RequestID: %s", l.Data["UID"]), 500)
+		return
+	}
+
+	if len(entries) == 0 {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	serialized, err := json.Marshal(entries)
+	if err != nil {
+		l.WithError(err).Error("failed to serialize")
+		http.Error(w, fmt.Sprintf("failed to serialize. RequestID: %s", l.Data["UID"]), 500)
+		return
+	}
+	if _, err := w.Write(serialized); err != nil {
+		l.WithError(err).Error("failed to write response")
+	}
 }
 
 var alphaNumericRegex = regexp.MustCompile("^[a-z0-9-]+$")
@@ -366,28 +845,80 @@ func (m *secretCollectionManager) createSecretCollectionHandler(l *logrus.Entry,
 	}
 
 	if err := m.createSecretCollection(l, user, name); err != nil {
+		if errors.Is(err, errCollectionLimitExceeded) {
+			http.Error(w, fmt.Sprintf("%v, delete collections you no longer need or ask an administrator to create it", err), http.StatusForbidden)
+			return
+		}
 		logrus.WithError(err).Error("failed to create secret collection")
 		http.Error(w, fmt.Sprintf("failed to create secret collection. RequestID: %s", l.Data["UID"]), 500)
 	}
 }
 
-func (m *secretCollectionManager) createSecretCollection(_ *logrus.Entry, userName, secretCollectionName string) error {
+var errCollectionLimitExceeded = errors.New("secret collection limit exceeded")
+
+func (m *secretCollectionManager) createSecretCollection(l *logrus.Entry, userName, secretCollectionName string) error {
+	if m.maxCollectionsPerUser > 0 && !m.collectionLimitAdmins.Has(userName) {
+		collections, err := m.getCollectionsForUser(l, userName)
+		if err != nil {
+			return fmt.Errorf("failed to get collections for user %s: %w", userName, err)
+		}
+		if owned := countCollectionsWithMember(collections, userName); owned >= m.maxCollectionsPerUser {
+			return fmt.Errorf("%w: user %s is a member of %d secret collections, the maximum is %d", errCollectionLimitExceeded, userName, owned, m.maxCollectionsPerUser)
+		}
+	}
+
 	user, err := m.userByAliasCached(userName)
 	if err != nil {
 		return fmt.Errorf("failed to get user %s: %w", userName, err)
 	}
-	policy, err := m.serializedPolicyFor(secretCollectionName)
+	if err := m.archiveAuditTrail(secretCollectionName); err != nil {
+		return err
+	}
+	if err := m.createGroupWithPolicy(secretCollectionName, false, []string{user.ID}); err != nil {
+		return err
+	}
+	m.userNamesCache.invalidate()
+
+	// Create an empty file so ppl see the secret collection in the vault UI.
+	indexFileLocation := strings.Replace(m.kvDataPrefix, "/data", "", 1) + "/" + secretCollectionName + "/" + indexItemName
+	if err := m.privilegedVaultClient.UpsertKV(indexFileLocation, map[string]string{".": "."}); err != nil {
+		return fmt.Errorf("failed to create %s: %w", indexFileLocation, err)
+	}
+
+	return nil
+}
+
+// countCollectionsWithMember counts the collections the user has full access to,
+// read-only access does not count towards the limit.
+func countCollectionsWithMember(collections []secretCollection, userName string) int {
+	var count int
+	for _, collection := range collections {
+		if slices.Contains(collection.Members, userName) {
+			count++
+		}
+	}
+	return count
+}
+
+// createGroupWithPolicy creates the policy and the group granting either full or read-only
+// access to a secret collection. The group and the policy share the same name.
+func (m *secretCollectionManager) createGroupWithPolicy(secretCollectionName string, readOnly bool, memberIDs []string) error {
+	name := prefixedName(secretCollectionName)
+	if readOnly {
+		name = readOnlyPrefixedName(secretCollectionName)
+	}
+	policy, err := m.serializedPolicyFor(secretCollectionName, readOnly)
 	if err != nil {
 		return fmt.Errorf("failed to construct policy for %s: %w", secretCollectionName, err)
 	}
-	if err := m.privilegedVaultClient.Sys().PutPolicy(prefixedName(secretCollectionName), policy); err != nil {
-		return fmt.Errorf("failed to create policy %s: %w", prefixedName(secretCollectionName), err)
+	if err := m.privilegedVaultClient.Sys().PutPolicy(name, policy); err != nil {
+		return fmt.Errorf("failed to create policy %s: %w", name, err)
 	}
 
 	group := vaultclient.Group{
-		Name:            prefixedName(secretCollectionName),
-		Policies:        []string{prefixedName(secretCollectionName)},
-		MemberEntityIDs: []string{user.ID},
+		Name:            name,
+		Policies:        []string{name},
+		MemberEntityIDs: memberIDs,
 		Metadata:        map[string]string{"created-by-secret-collection-manager": "true"},
 	}
 	serializedGroup, err := json.Marshal(group)
@@ -395,23 +926,22 @@ func (m *secretCollectionManager) createSecretCollection(_ *logrus.Entry, userNa
 		return fmt.Errorf("failed to marhsal group: %w", err)
 	}
 	if err := m.privilegedVaultClient.Put("identity/group", serializedGroup); err != nil {
-		return fmt.Errorf("failed to create group %s: %w", prefixedName(secretCollectionName), err)
-	}
-
-	// Create an empty file so ppl see the secret collection in the vault UI.
-	indexFileLocation := strings.Replace(m.kvDataPrefix, "/data", "", 1) + "/" + secretCollectionName + "/index"
-	if err := m.privilegedVaultClient.UpsertKV(indexFileLocation, map[string]string{".": "."}); err != nil {
-		return fmt.Errorf("failed to create %s: %w", indexFileLocation, err)
+		return fmt.Errorf("failed to create group %s: %w", name, err)
 	}
-
 	return nil
 }
 
-func (m *secretCollectionManager) serializedPolicyFor(name string) (string, error) {
+func (m *secretCollectionManager) serializedPolicyFor(name string, readOnly bool) (string, error) {
 	policy := managedVaultPolicy{Path: map[string]managedVaultPolicyCapabilityList{
 		m.kvMetadataPrefix + "/" + name + "/*": {Capabilities: []string{"list", "delete"}},
 		m.kvDataPrefix + "/" + name + "/*":     {Capabilities: []string{"create", "update", "read"}},
 	}}
+	if readOnly {
+		policy = managedVaultPolicy{Path: map[string]managedVaultPolicyCapabilityList{
+			m.kvMetadataPrefix + "/" + name + "/*": {Capabilities: []string{"list"}},
+			m.kvDataPrefix + "/" + name + "/*":     {Capabilities: []string{"read"}},
+		}}
+	}
 	serialized, err := json.Marshal(policy)
 	if err != nil {
 		return "", fmt.Errorf("failed to serialize policy: %w", err)
@@ -424,8 +954,18 @@ func prefixedName(name string) string {
 	return objectPrefix + "-" + name
 }
 
-func nameFromPrefixedName(name string) string {
-	return strings.TrimPrefix(name, objectPrefix+"-")
+func readOnlyPrefixedName(name string) string {
+	return prefixedName(name) + readOnlySuffix
+}
+
+// nameFromPrefixedName returns the name of the secret collection a policy or group belongs to
+// and whether it grants read-only access.
+func nameFromPrefixedName(name string) (string, bool) {
+	name = strings.TrimPrefix(name, objectPrefix+"-")
+	if strings.HasSuffix(name, readOnlySuffix) {
+		return strings.TrimSuffix(name, readOnlySuffix), true
+	}
+	return name, false
 }
 
 func (m *secretCollectionManager) listSecretCollections(l *logrus.Entry, user string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -460,6 +1000,71 @@ func (m *secretCollectionManager) listSecretCollections(l *logrus.Entry, user st
 	}
 }
 
+func (m *secretCollectionManager) adminListSecretCollections(l *logrus.Entry, user string, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	if !m.admins.Has(user) {
+		http.Error(w, fmt.Sprintf("only admins may list all secret collections. RequestID: %s", l.Data["UID"]), http.StatusForbidden)
+		return
+	}
+
+	collections, err := m.getAllCollections()
+	if err != nil {
+		l.WithError(err).Error("failed to get all collections")
+		http.Error(w, fmt.Sprintf("failed to get secret collections. RequestID: %s", l.Data["UID"]), 500)
+		return
+	}
+
+	serialized, err := json.Marshal(collections)
+	if err != nil {
+		l.WithError(err).Error("failed to serialize")
+		http.Error(w, fmt.Sprintf("failed to serialize. RequestID: %s", l.Data["UID"]), 500)
+		return
+	}
+	if len(collections) == 0 {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if _, err := w.Write(serialized); err != nil {
+		l.WithError(err).Error("failed to write response")
+	}
+}
+
+// getAllCollections returns all secret collections with their members and the number of their items, sorted by name
+func (m *secretCollectionManager) getAllCollections() ([]adminSecretCollection, error) {
+	groupNames, err := m.privilegedVaultClient.GetGroupNames()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+	// A collection may only have a read-only group left, so both have to be considered
+	groupNamesByCollection := map[string]string{}
+	for _, groupName := range groupNames {
+		if !strings.HasPrefix(groupName, objectPrefix) {
+			continue
+		}
+		collectionName, readOnly := nameFromPrefixedName(groupName)
+		if _, seen := groupNamesByCollection[collectionName]; !seen || !readOnly {
+			groupNamesByCollection[collectionName] = groupName
+		}
+	}
+
+	var collections []adminSecretCollection
+	var errs []error
+	for _, collectionName := range sets.List(sets.KeySet(groupNamesByCollection)) {
+		collection, err := m.getCollectionsFromGroupName(groupNamesByCollection[collectionName])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		items, err := m.collectionItems(collection.Name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		collections = append(collections, adminSecretCollection{secretCollection: *collection, ItemCount: len(items)})
+	}
+
+	return collections, utilerrors.NewAggregate(errs)
+}
+
 func (m *secretCollectionManager) getAuthBackendAccessorID() (string, error) {
 	var id string
 	m.authAccessorBackendIDLock.RLock()
@@ -501,6 +1106,7 @@ func (m *secretCollectionManager) createUser(userName string) (*vaultclient.Enti
 	if err != nil {
 		return nil, fmt.Errorf("failed to create identity for %s: %w", userName, err)
 	}
+	defer m.userNamesCache.invalidate()
 	if err := m.privilegedVaultClient.CreateIdentityAlias(userName, user.ID, authBackendAccessorId); err != nil {
 		return nil, fmt.Errorf("failed to create alias for user %s: %w", userName, err)
 	}
@@ -535,12 +1141,18 @@ func (m *secretCollectionManager) getCollectionsForUser(l *logrus.Entry, userNam
 
 	var collections []secretCollection
 	var errs []error
+	seen := map[string]bool{}
 	for _, groupName := range groupNames {
 		collection, err := m.getCollectionsFromGroupName(groupName)
 		if err != nil {
 			errs = append(errs, err)
 			continue
 		}
+		// A user may be in both the group and the read-only group of a collection
+		if seen[collection.Name] {
+			continue
+		}
+		seen[collection.Name] = true
 		collections = append(collections, *collection)
 	}
 
@@ -629,6 +1241,36 @@ func (m *secretCollectionManager) getCollectionsFromGroupName(groupName string)
 		collection.Path = strings.Join([]string{m.kvStorePrefix, collection.Name}, "/")
 	}
 
+	// Both the policy of the group and of the read-only group cover the same paths, so the
+	// members of the other group of the collection have to be looked up separately.
+	groups := map[string]*vaultclient.Group{groupName: group}
+	for _, name := range []string{prefixedName(collection.Name), readOnlyPrefixedName(collection.Name)} {
+		if _, ok := groups[name]; ok {
+			continue
+		}
+		other, err := m.privilegedVaultClient.GetGroupByName(name)
+		if err != nil {
+			if vaultclient.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get group %s: %w", name, err)
+		}
+		groups[name] = other
+	}
+
+	if collection.Members, err = m.memberNames(groups[prefixedName(collection.Name)]); err != nil {
+		return nil, err
+	}
+	if collection.ReadOnlyMembers, err = m.memberNames(groups[readOnlyPrefixedName(collection.Name)]); err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+func (m *secretCollectionManager) memberNames(group *vaultclient.Group) ([]string, error) {
+	if group == nil {
+		return nil, nil
+	}
 	var memberNames []string
 	for _, memberID := range group.MemberEntityIDs {
 		name, err := m.userAliasByIDCached(memberID)
@@ -637,9 +1279,8 @@ func (m *secretCollectionManager) getCollectionsFromGroupName(groupName string)
 		}
 		memberNames = append(memberNames, name)
 	}
-
-	collection.Members = memberNames
-	return &collection, nil
+	sort.Strings(memberNames)
+	return memberNames, nil
 }
 
 // collectionNameFromPolicyPath strips the metadata/data prefix and the /* suffix from a policy path
@@ -647,28 +1288,20 @@ func (m *secretCollectionManager) collectionNameFromPolicyPath(policyPath string
 	return strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(policyPath, m.kvMetadataPrefix+"/"), m.kvDataPrefix+"/"), "/*")
 }
 
-func (m *secretCollectionManager) usersHandler(l *logrus.Entry, _ string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	entities, err := m.privilegedVaultClient.ListIdentities()
+func (m *secretCollectionManager) usersHandler(l *logrus.Entry, user string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userNames, err := m.userNames(l, user)
+	if errors.Is(err, errTooManyUserListings) {
+		http.Error(w, "too many requests, please try again later", http.StatusTooManyRequests)
+		return
+	}
 	if err != nil {
 		l.WithError(err).Error("Failed to list identities")
 		http.Error(w, fmt.Sprintf("failed to list users. RequestID: %s", l.Data["UID"]), 500)
 		return
 	}
 
-	var userNames []string
-	for _, entity := range entities {
-		name, err := m.userAliasByIDCached(entity)
-		if err != nil && !errors.Is(err, notExactlyOneEntityForUserError{}) {
-			l.WithError(err).WithField("userID", entity).Error("Failed to resolve username for id")
-		}
-		if name != "" {
-			userNames = append(userNames, name)
-		}
-	}
-
 	var serialized []byte
 	if len(userNames) > 0 {
-		sort.Strings(userNames)
 		var err error
 		serialized, err = json.Marshal(userNames)
 		if err != nil {
@@ -682,6 +1315,39 @@ func (m *secretCollectionManager) usersHandler(l *logrus.Entry, _ string, w http
 	}
 }
 
+var errTooManyUserListings = errors.New("too many listings of all users")
+
+// userNames returns the sorted names of all users, served from the cache if possible.
+// Only listings that miss the cache count towards the rate limit of the requesting user,
+// as only they are expensive.
+func (m *secretCollectionManager) userNames(l *logrus.Entry, requester string) ([]string, error) {
+	userNames, generation, ok := m.userNamesCache.get(time.Now())
+	if ok {
+		return userNames, nil
+	}
+	if !m.usersRateLimiter.allow(requester) {
+		return nil, errTooManyUserListings
+	}
+
+	entities, err := m.privilegedVaultClient.ListIdentities()
+	if err != nil {
+		return nil, err
+	}
+	userNames = []string{}
+	for _, entity := range entities {
+		name, err := m.userAliasByIDCached(entity)
+		if err != nil && !errors.Is(err, notExactlyOneEntityForUserError{}) {
+			l.WithError(err).WithField("userID", entity).Error("Failed to resolve username for id")
+		}
+		if name != "" {
+			userNames = append(userNames, name)
+		}
+	}
+	sort.Strings(userNames)
+	m.userNamesCache.set(userNames, generation, time.Now())
+	return userNames, nil
+}
+
 func (m *secretCollectionManager) reconcilePolicies() (updatedPolicies []string, err error) {
 	policyNames, err := m.privilegedVaultClient.Sys().ListPolicies()
 	if err != nil {
@@ -699,9 +1365,10 @@ func (m *secretCollectionManager) reconcilePolicies() (updatedPolicies []string,
 			continue
 		}
 
-		expectedPolicy, err := m.serializedPolicyFor(nameFromPrefixedName(policyName))
+		collectionName, readOnly := nameFromPrefixedName(policyName)
+		expectedPolicy, err := m.serializedPolicyFor(collectionName, readOnly)
 		if err != nil {
-			errs = append(errs, fmt.Errorf("failed to construct expected policy for %s: %w", nameFromPrefixedName(policyName), err))
+			errs = append(errs, fmt.Errorf("failed to construct expected policy for %s: %w", collectionName, err))
 			continue
 		}
 
@@ -717,3 +1384,108 @@ func (m *secretCollectionManager) reconcilePolicies() (updatedPolicies []string,
 
 	return updatedPolicies, utilerrors.NewAggregate(errs)
 }
+
+// reconcileOrphans finds groups and policies whose counterpart is missing. Collections whose deletion got
+// interrupted after their data was destroyed are deleted completely, groups without a policy get their
+// policy recreated. Policies without a group for collections that still have data can not be repaired,
+// as the members are unknown, so they are only logged.
+func (m *secretCollectionManager) reconcileOrphans() (repaired []string, err error) {
+	policyNames, err := m.privilegedVaultClient.Sys().ListPolicies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+	groupNames, err := m.privilegedVaultClient.GetGroupNames()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+	policies, groups := sets.New[string](), sets.New[string]()
+	for _, name := range policyNames {
+		if strings.HasPrefix(name, objectPrefix) {
+			policies.Insert(name)
+		}
+	}
+	for _, name := range groupNames {
+		if strings.HasPrefix(name, objectPrefix) {
+			groups.Insert(name)
+		}
+	}
+
+	var errs []error
+	deletedCollections := map[string]bool{}
+	for _, name := range sets.List(policies.Union(groups)) {
+		hasPolicy, hasGroup := policies.Has(name), groups.Has(name)
+		collectionName, readOnly := nameFromPrefixedName(name)
+		l := logrus.WithFields(logrus.Fields{"name": name, "has_policy": hasPolicy, "has_group": hasGroup})
+
+		deleted, checked := deletedCollections[collectionName]
+		if !checked {
+			var err error
+			if deleted, err = m.isHalfDeleted(collectionName, groups.Has(prefixedName(collectionName))); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			deletedCollections[collectionName] = deleted
+		}
+
+		switch {
+		case deleted:
+			if hasGroup {
+				if err := m.privilegedVaultClient.DeleteGroupByName(name); err != nil {
+					errs = append(errs, fmt.Errorf("failed to delete group %s: %w", name, err))
+					continue
+				}
+			}
+			if hasPolicy {
+				if err := m.privilegedVaultClient.Sys().DeletePolicy(name); err != nil {
+					errs = append(errs, fmt.Errorf("failed to delete policy %s: %w", name, err))
+					continue
+				}
+			}
+			l.Info("Completed the deletion of a secret collection")
+			repaired = append(repaired, name)
+		case hasGroup && !hasPolicy:
+			policy, err := m.serializedPolicyFor(collectionName, readOnly)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to construct policy for %s: %w", collectionName, err))
+				continue
+			}
+			if err := m.privilegedVaultClient.Sys().PutPolicy(name, policy); err != nil {
+				errs = append(errs, fmt.Errorf("failed to create policy %s: %w", name, err))
+				continue
+			}
+			l.Info("Recreated missing policy of a group")
+			repaired = append(repaired, name)
+		case hasPolicy && !hasGroup:
+			l.Warn("Found a policy without a group for a secret collection that still has data")
+		}
+	}
+
+	return repaired, utilerrors.NewAggregate(errs)
+}
+
+// isHalfDeleted checks if all data of a secret collection was destroyed, but its groups or policies
+// are still around. This is the case if either its group is gone as well, or the last entry in its
+// audit trail is the deletion.
+func (m *secretCollectionManager) isHalfDeleted(collectionName string, hasGroup bool) (bool, error) {
+	path := m.kvStorePrefix + "/" + collectionName
+	items, err := m.privilegedVaultClient.ListKVRecursively(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to list items below %s: %w", path, err)
+	}
+	if len(items) > 0 {
+		return false, nil
+	}
+	if !hasGroup {
+		return true, nil
+	}
+
+	entries, err := m.auditEntries(collectionName)
+	if err != nil {
+		return false, err
+	}
+	if len(entries) == 0 {
+		return false, nil
+	}
+	last := entries[len(entries)-1]
+	return len(last.NewMembers) == 0 && len(last.NewReadOnlyMembers) == 0, nil
+}
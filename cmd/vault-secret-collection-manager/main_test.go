@@ -2,23 +2,30 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/vault/api"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/openshift/ci-tools/pkg/testhelper"
 	"github.com/openshift/ci-tools/pkg/vaultclient"
 )
 
-func TestSecretCollectionManager(t *testing.T) {
-	t.Parallel()
-	vaultAddr := testhelper.Vault(t)
+// startSecretCollectionManager starts a Vault with the users user-1 and user-2 and a
+// secret-collection-manager in front of it
+func startSecretCollectionManager(t *testing.T, maxCollectionsPerUser int, collectionLimitAdmins, admins sets.Set[string]) (vaultAddr string, client *vaultclient.VaultClient, managerListenAddr string, collectionManager *secretCollectionManager) {
+	vaultAddr = testhelper.Vault(t)
 
 	client, err := vaultclient.New("http://"+vaultAddr, testhelper.VaultTestingRootToken)
 	if err != nil {
@@ -35,8 +42,8 @@ func TestSecretCollectionManager(t *testing.T) {
 		}
 	}
 
-	managerListenAddr := "127.0.0.1:" + testhelper.GetFreePort(t)
-	collectionManager, server := server(client, "userpass", "secret/self-managed", managerListenAddr)
+	managerListenAddr = "127.0.0.1:" + testhelper.GetFreePort(t)
+	collectionManager, server := server(client, "userpass", "secret/self-managed", managerListenAddr, maxCollectionsPerUser, collectionLimitAdmins, admins)
 	go func() {
 		if err := server.ListenAndServe(); err != http.ErrServerClosed {
 			t.Errorf("failed to start secret-collection-manager: %v", err)
@@ -49,6 +56,12 @@ func TestSecretCollectionManager(t *testing.T) {
 			t.Errorf("failed to close server: %v", err)
 		}
 	})
+	return vaultAddr, client, managerListenAddr, collectionManager
+}
+
+func TestSecretCollectionManager(t *testing.T) {
+	t.Parallel()
+	vaultAddr, client, managerListenAddr, collectionManager := startSecretCollectionManager(t, 0, nil, sets.New[string]("user-2"))
 
 	type permCheckScenario struct {
 		user          string
@@ -126,6 +139,7 @@ func TestSecretCollectionManager(t *testing.T) {
 				{"user-2", "secret/self-managed/mine-alone", false},
 				{"user-1", "secret/self-managed/elsewhere", false},
 				{"user-2", "secret/self-managed/elsewhere", false},
+				{"user-1", "secret/self-managed-audit", false},
 			},
 		},
 		{
@@ -143,6 +157,34 @@ func TestSecretCollectionManager(t *testing.T) {
 			}},
 			expectedVaultPolicies: []string{"default", "secret-collection-manager-managed-mine-alone", "root"},
 		},
+		{
+			name:               "Listing items as user-1, the placeholder is not returned",
+			user:               "user-1",
+			request:            mustNewRequest(http.MethodGet, fmt.Sprintf("http://%s/secretcollection/mine-alone/items", managerListenAddr)),
+			expectedStatusCode: 200,
+			expectedVaultGroups: []vaultclient.Group{{
+				Name:            "secret-collection-manager-managed-mine-alone",
+				Policies:        []string{"secret-collection-manager-managed-mine-alone"},
+				MemberEntityIDs: []string{"entity-0"},
+				Metadata:        map[string]string{"created-by-secret-collection-manager": "true"},
+				ModifyIndex:     1,
+			}},
+			expectedVaultPolicies: []string{"default", "secret-collection-manager-managed-mine-alone", "root"},
+		},
+		{
+			name:               "Listing items as user-2, 404",
+			user:               "user-2",
+			request:            mustNewRequest(http.MethodGet, fmt.Sprintf("http://%s/secretcollection/mine-alone/items", managerListenAddr)),
+			expectedStatusCode: 404,
+			expectedVaultGroups: []vaultclient.Group{{
+				Name:            "secret-collection-manager-managed-mine-alone",
+				Policies:        []string{"secret-collection-manager-managed-mine-alone"},
+				MemberEntityIDs: []string{"entity-0"},
+				Metadata:        map[string]string{"created-by-secret-collection-manager": "true"},
+				ModifyIndex:     1,
+			}},
+			expectedVaultPolicies: []string{"default", "secret-collection-manager-managed-mine-alone", "root"},
+		},
 		{
 			name:               "Listing as user 2, no collections",
 			user:               "user-2",
@@ -157,6 +199,35 @@ func TestSecretCollectionManager(t *testing.T) {
 			}},
 			expectedVaultPolicies: []string{"default", "secret-collection-manager-managed-mine-alone", "root"},
 		},
+		{
+			name:               "Listing all collections as non-admin user-1, 403",
+			user:               "user-1",
+			request:            mustNewRequest(http.MethodGet, fmt.Sprintf("http://%s/admin/secretcollections", managerListenAddr)),
+			expectedStatusCode: 403,
+			expectedVaultGroups: []vaultclient.Group{{
+				Name:            "secret-collection-manager-managed-mine-alone",
+				Policies:        []string{"secret-collection-manager-managed-mine-alone"},
+				MemberEntityIDs: []string{"entity-0"},
+				Metadata:        map[string]string{"created-by-secret-collection-manager": "true"},
+				ModifyIndex:     1,
+			}},
+			expectedVaultPolicies: []string{"default", "secret-collection-manager-managed-mine-alone", "root"},
+		},
+		{
+			name:               "Listing all collections as admin user-2, collection of user-1 is returned",
+			user:               "user-2",
+			request:            mustNewRequest(http.MethodGet, fmt.Sprintf("http://%s/admin/secretcollections", managerListenAddr)),
+			expectedStatusCode: 200,
+			expectedBody:       `[{"name":"mine-alone","path":"secret/self-managed/mine-alone","members":["user-1"],"itemCount":0}]`,
+			expectedVaultGroups: []vaultclient.Group{{
+				Name:            "secret-collection-manager-managed-mine-alone",
+				Policies:        []string{"secret-collection-manager-managed-mine-alone"},
+				MemberEntityIDs: []string{"entity-0"},
+				Metadata:        map[string]string{"created-by-secret-collection-manager": "true"},
+				ModifyIndex:     1,
+			}},
+			expectedVaultPolicies: []string{"default", "secret-collection-manager-managed-mine-alone", "root"},
+		},
 		{
 			name:               "User is not a collection member, 404",
 			user:               "user-2",
@@ -201,6 +272,40 @@ func TestSecretCollectionManager(t *testing.T) {
 			}},
 			expectedVaultPolicies: []string{"default", "secret-collection-manager-managed-mine-alone", "root"},
 		},
+		{
+			name: "Bulk removal of a member, collections the user is not a member of are not found",
+			user: "user-1",
+			request: mustNewRequest(http.MethodPost, fmt.Sprintf("http://%s/users/user-2/collections", managerListenAddr),
+				[]byte(`{"remove":["mine-alone","not-mine"]}`)...,
+			),
+			expectedStatusCode: 200,
+			expectedBody:       `{"mine-alone":{"success":true},"not-mine":{"success":false,"error":"secret collection not found"}}`,
+			expectedVaultGroups: []vaultclient.Group{{
+				Name:            "secret-collection-manager-managed-mine-alone",
+				Policies:        []string{"secret-collection-manager-managed-mine-alone"},
+				MemberEntityIDs: []string{"entity-0"},
+				Metadata:        map[string]string{"created-by-secret-collection-manager": "true"},
+				ModifyIndex:     3,
+			}},
+			expectedVaultPolicies: []string{"default", "secret-collection-manager-managed-mine-alone", "root"},
+		},
+		{
+			name: "Bulk addition of a member",
+			user: "user-1",
+			request: mustNewRequest(http.MethodPost, fmt.Sprintf("http://%s/users/user-2/collections", managerListenAddr),
+				[]byte(`{"add":["mine-alone"]}`)...,
+			),
+			expectedStatusCode: 200,
+			expectedBody:       `{"mine-alone":{"success":true}}`,
+			expectedVaultGroups: []vaultclient.Group{{
+				Name:            "secret-collection-manager-managed-mine-alone",
+				Policies:        []string{"secret-collection-manager-managed-mine-alone"},
+				MemberEntityIDs: []string{"entity-0", "entity-1"},
+				Metadata:        map[string]string{"created-by-secret-collection-manager": "true"},
+				ModifyIndex:     4,
+			}},
+			expectedVaultPolicies: []string{"default", "secret-collection-manager-managed-mine-alone", "root"},
+		},
 		{
 			name:                  "New collection member successfully deletes it",
 			user:                  "user-2",
@@ -326,8 +431,41 @@ func TestSecretCollectionManager(t *testing.T) {
 		if err != nil {
 			t.Fatalf("failed to list recuresively: %v", err)
 		}
-		if len(results) > 0 {
-			t.Errorf("expected kv store to be empty, but found %v", results)
+		if len(results) != 0 {
+			t.Errorf("expected kv store to be empty, got %v", results)
+		}
+		entries, err := collectionManager.auditEntries("mine-alone")
+		if err != nil {
+			t.Fatalf("failed to get audit entries: %v", err)
+		}
+		for idx := range entries {
+			entries[idx].Timestamp = time.Time{}
+		}
+		expected := []auditEntry{
+			{User: "user-1", Collection: "mine-alone", OldMembers: []string{"user-1"}, NewMembers: []string{"user-1", "user-2"}},
+			{User: "user-2", Collection: "mine-alone", OldMembers: []string{"user-1", "user-2"}},
+		}
+		if diff := cmp.Diff(expected, entries); diff != "" {
+			t.Errorf("audit entries differ from expected: %s", diff)
+		}
+
+		// A new collection with the same name must not inherit the audit trail
+		if err := collectionManager.archiveAuditTrail("mine-alone"); err != nil {
+			t.Fatalf("failed to archive audit trail: %v", err)
+		}
+		entries, err = collectionManager.auditEntries("mine-alone")
+		if err != nil {
+			t.Fatalf("failed to get audit entries: %v", err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("expected audit trail to be empty after archiving it, got %v", entries)
+		}
+		archived, err := client.ListKVRecursively("secret/self-managed-audit-archive/mine-alone")
+		if err != nil {
+			t.Fatalf("failed to list archived audit trails: %v", err)
+		}
+		if len(archived) != 1 {
+			t.Errorf("expected exactly one archived audit trail, got %v", archived)
 		}
 	})
 
@@ -358,6 +496,11 @@ func TestSecretCollectionManager(t *testing.T) {
 			t.Fatalf("failed to create 'unrelated' policy: %v", err)
 		}
 
+		// The read-only policy of a collection must not be considered outdated
+		if err := collectionManager.updateSecretCollectionMembers(nil, "user-1", "second", []string{"user-1"}, []string{"user-2"}); err != nil {
+			t.Fatalf("failed to add read-only member to the second collection: %v", err)
+		}
+
 		changedCollections, err := collectionManager.reconcilePolicies()
 		if err != nil {
 			t.Fatalf("reconcilePolicies: %v", err)
@@ -368,6 +511,118 @@ func TestSecretCollectionManager(t *testing.T) {
 		}
 	})
 
+	t.Run("reconcileOrphans", func(t *testing.T) {
+		// The policy of the deleted collection is still around, while first and second are intact
+		repaired, err := collectionManager.reconcileOrphans()
+		if err != nil {
+			t.Fatalf("reconcileOrphans: %v", err)
+		}
+		if diff := cmp.Diff([]string{prefixedName("mine-alone")}, repaired); diff != "" {
+			t.Errorf("repaired objects differ from expected: %s", diff)
+		}
+
+		if err := client.Sys().DeletePolicy(prefixedName("first")); err != nil {
+			t.Fatalf("failed to delete the first policy: %v", err)
+		}
+		// Simulate a deletion of the second collection that got interrupted before the groups were deleted
+		if err := client.DestroyKVIrreversibly("secret/self-managed/second/index"); err != nil {
+			t.Fatalf("failed to delete the data of the second collection: %v", err)
+		}
+		if err := collectionManager.appendAuditEntry(auditEntry{Timestamp: time.Now(), User: "user-1", Collection: "second", OldMembers: []string{"user-1"}}); err != nil {
+			t.Fatalf("failed to append audit entry: %v", err)
+		}
+		repaired, err = collectionManager.reconcileOrphans()
+		if err != nil {
+			t.Fatalf("reconcileOrphans: %v", err)
+		}
+		if diff := cmp.Diff([]string{prefixedName("first"), prefixedName("second"), readOnlyPrefixedName("second")}, repaired); diff != "" {
+			t.Errorf("repaired objects differ from expected: %s", diff)
+		}
+	})
+
+}
+
+func TestCollectionLimit(t *testing.T) {
+	t.Parallel()
+	vaultAddr, _, managerListenAddr, _ := startSecretCollectionManager(t, 1, sets.New[string]("user-2"), nil)
+	// Vault only creates the entities of the users once they logged in
+	for _, user := range []string{"user-1", "user-2"} {
+		if _, err := vaultclient.NewFromUserPass("http://"+vaultAddr, user, "password"); err != nil {
+			t.Fatalf("failed to log in as %s: %v", user, err)
+		}
+	}
+
+	testCases := []struct {
+		name               string
+		user               string
+		request            *http.Request
+		expectedStatusCode int
+	}{
+		{
+			name:               "User 1 creates their first collection",
+			user:               "user-1",
+			request:            mustNewRequest(http.MethodPut, fmt.Sprintf("http://%s/secretcollection/first", managerListenAddr)),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "User 1 exceeds the limit, 403",
+			user:               "user-1",
+			request:            mustNewRequest(http.MethodPut, fmt.Sprintf("http://%s/secretcollection/second", managerListenAddr)),
+			expectedStatusCode: 403,
+		},
+		{
+			name:               "User 2 creates their first collection",
+			user:               "user-2",
+			request:            mustNewRequest(http.MethodPut, fmt.Sprintf("http://%s/secretcollection/third", managerListenAddr)),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "User 2 is exempt from the limit",
+			user:               "user-2",
+			request:            mustNewRequest(http.MethodPut, fmt.Sprintf("http://%s/secretcollection/fourth", managerListenAddr)),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "User 1 makes user 2 a read-only member of their collection",
+			user:               "user-1",
+			request:            mustNewRequest(http.MethodPut, fmt.Sprintf("http://%s/secretcollection/first/members", managerListenAddr), []byte(`{"members":["user-1"],"readOnlyMembers":["user-2"]}`)...),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "User 1 still can not create another collection",
+			user:               "user-1",
+			request:            mustNewRequest(http.MethodPut, fmt.Sprintf("http://%s/secretcollection/fifth", managerListenAddr)),
+			expectedStatusCode: 403,
+		},
+		{
+			name:               "User 1 deletes their collection",
+			user:               "user-1",
+			request:            mustNewRequest(http.MethodDelete, fmt.Sprintf("http://%s/secretcollection/first", managerListenAddr)),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "User 1 can create a collection again",
+			user:               "user-1",
+			request:            mustNewRequest(http.MethodPut, fmt.Sprintf("http://%s/secretcollection/second", managerListenAddr)),
+			expectedStatusCode: 200,
+		},
+	}
+
+	// These tests mutate state in vault, so they need to be executed serially
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.request.Header.Set("X-Forwarded-Email", fmt.Sprintf("%s@unchecked.com", tc.user))
+			response, err := http.DefaultClient.Do(tc.request)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer response.Body.Close()
+			if response.StatusCode != tc.expectedStatusCode {
+				body, _ := io.ReadAll(response.Body)
+				t.Fatalf("expected status code %d, got %d: %s", tc.expectedStatusCode, response.StatusCode, body)
+			}
+		})
+	}
 }
 
 func checkIs403(err error, action string, expectSuccess bool, t *testing.T) {
@@ -398,3 +653,280 @@ func mustNewRequest(method, url string, body ...byte) *http.Request {
 	}
 	return request
 }
+
+func TestCountCollectionsWithMember(t *testing.T) {
+	collections := []secretCollection{
+		{Name: "mine", Members: []string{"user-1"}},
+		{Name: "shared", Members: []string{"user-2", "user-1"}},
+		{Name: "read-only", Members: []string{"user-2"}, ReadOnlyMembers: []string{"user-1"}},
+		{Name: "theirs", Members: []string{"user-2"}},
+	}
+	testCases := []struct {
+		name     string
+		user     string
+		expected int
+	}{
+		{name: "read-only access does not count", user: "user-1", expected: 2},
+		{name: "all collections with full access count", user: "user-2", expected: 3},
+		{name: "not a member of anything", user: "user-3", expected: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := countCollectionsWithMember(collections, tc.user); actual != tc.expected {
+				t.Errorf("expected %d collections, got %d", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNameFromPrefixedName(t *testing.T) {
+	testCases := []struct {
+		name             string
+		prefixedName     string
+		expectedName     string
+		expectedReadOnly bool
+	}{
+		{name: "group of a collection", prefixedName: prefixedName("foo"), expectedName: "foo"},
+		{name: "read-only group of a collection", prefixedName: readOnlyPrefixedName("foo"), expectedName: "foo", expectedReadOnly: true},
+		{name: "collection whose name ends like the old suffix", prefixedName: prefixedName("foo-ro"), expectedName: "foo-ro"},
+		{name: "read-only group of a collection whose name ends like the old suffix", prefixedName: readOnlyPrefixedName("foo-ro"), expectedName: "foo-ro", expectedReadOnly: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			name, readOnly := nameFromPrefixedName(tc.prefixedName)
+			if name != tc.expectedName || readOnly != tc.expectedReadOnly {
+				t.Errorf("expected collection %q (read-only: %t), got %q (read-only: %t)", tc.expectedName, tc.expectedReadOnly, name, readOnly)
+			}
+		})
+	}
+}
+
+func TestIsMemberOf(t *testing.T) {
+	collections := []secretCollection{
+		{Name: "shared", Members: []string{"user-1"}, ReadOnlyMembers: []string{"user-2"}},
+		{Name: "other", Members: []string{"user-2"}},
+	}
+	testCases := []struct {
+		name            string
+		user            string
+		collection      string
+		includeReadOnly bool
+		expected        bool
+	}{
+		{name: "member may manage the collection", user: "user-1", collection: "shared", expected: true},
+		{name: "member may read the collection", user: "user-1", collection: "shared", includeReadOnly: true, expected: true},
+		{name: "read-only member may not manage the collection", user: "user-2", collection: "shared"},
+		{name: "read-only member may read the collection", user: "user-2", collection: "shared", includeReadOnly: true, expected: true},
+		{name: "membership of another collection does not count", user: "user-1", collection: "other", includeReadOnly: true},
+		{name: "unknown collection", user: "user-1", collection: "unknown", includeReadOnly: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := isMemberOf(collections, tc.user, tc.collection, tc.includeReadOnly); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestValidateMembers(t *testing.T) {
+	testCases := []struct {
+		name            string
+		members         []string
+		readOnlyMembers []string
+		expectedErr     error
+	}{
+		{name: "members only", members: []string{"user-1", "user-2"}},
+		{name: "distinct members and read-only members", members: []string{"user-1"}, readOnlyMembers: []string{"user-2"}},
+		{name: "no members", readOnlyMembers: []string{"user-2"}, expectedErr: errors.New("there must be at least one member")},
+		{
+			name:            "members that are also read-only members",
+			members:         []string{"user-1", "user-2", "user-3"},
+			readOnlyMembers: []string{"user-2", "user-3", "user-4"},
+			expectedErr:     errors.New("[user-2 can not be both a member and a read-only member, user-3 can not be both a member and a read-only member]"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expectedErr, validateMembers(tc.members, tc.readOnlyMembers), testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("error differs from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestSerializedPolicyFor(t *testing.T) {
+	m := &secretCollectionManager{
+		kvMetadataPrefix: vaultclient.InsertMetadataIntoPath("secret/self-managed"),
+		kvDataPrefix:     vaultclient.InsertDataIntoPath("secret/self-managed"),
+	}
+	testCases := []struct {
+		name     string
+		readOnly bool
+		expected managedVaultPolicy
+	}{
+		{
+			name: "members may manage the secrets",
+			expected: managedVaultPolicy{Path: map[string]managedVaultPolicyCapabilityList{
+				"secret/metadata/self-managed/foo/*": {Capabilities: []string{"list", "delete"}},
+				"secret/data/self-managed/foo/*":     {Capabilities: []string{"create", "update", "read"}},
+			}},
+		},
+		{
+			name:     "read-only members may only list and read the secrets",
+			readOnly: true,
+			expected: managedVaultPolicy{Path: map[string]managedVaultPolicyCapabilityList{
+				"secret/metadata/self-managed/foo/*": {Capabilities: []string{"list"}},
+				"secret/data/self-managed/foo/*":     {Capabilities: []string{"read"}},
+			}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			serialized, err := m.serializedPolicyFor("foo", tc.readOnly)
+			if err != nil {
+				t.Fatalf("failed to serialize policy: %v", err)
+			}
+			var actual managedVaultPolicy
+			if err := json.Unmarshal([]byte(serialized), &actual); err != nil {
+				t.Fatalf("failed to unmarshal policy: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("policy differs from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestUserNamesCache(t *testing.T) {
+	now := time.Now()
+	cache := &userNamesCache{}
+
+	if _, _, ok := cache.get(now); ok {
+		t.Fatal("expected empty cache to miss")
+	}
+
+	_, generation, _ := cache.get(now)
+	cache.set([]string{"user-1"}, generation, now)
+	if userNames, _, ok := cache.get(now.Add(userNamesCacheTTL / 2)); !ok || !reflect.DeepEqual(userNames, []string{"user-1"}) {
+		t.Errorf("expected cache hit with [user-1], got %v (hit: %t)", userNames, ok)
+	}
+	if _, _, ok := cache.get(now.Add(2 * userNamesCacheTTL)); ok {
+		t.Error("expected expired cache to miss")
+	}
+
+	cache.invalidate()
+	if _, _, ok := cache.get(now); ok {
+		t.Error("expected invalidated cache to miss")
+	}
+
+	_, generation, _ = cache.get(now)
+	cache.invalidate()
+	cache.set([]string{"user-1"}, generation, now)
+	if _, _, ok := cache.get(now); ok {
+		t.Error("expected listing started before invalidation not to be cached")
+	}
+}
+
+func TestPerClientRateLimiter(t *testing.T) {
+	limiter := newPerClientRateLimiter(rate.Every(time.Hour), 2)
+	for i := 0; i < 2; i++ {
+		if !limiter.allow("user-1") {
+			t.Fatalf("expected request %d of user-1 to be allowed", i)
+		}
+	}
+	if limiter.allow("user-1") {
+		t.Error("expected request of user-1 exceeding the burst to be rejected")
+	}
+	if !limiter.allow("user-2") {
+		t.Error("expected request of user-2 to be allowed")
+	}
+}
+
+func TestUserNamesRateLimit(t *testing.T) {
+	m := &secretCollectionManager{usersRateLimiter: newPerClientRateLimiter(rate.Every(time.Hour), 0)}
+	l := logrus.NewEntry(logrus.StandardLogger())
+
+	_, generation, _ := m.userNamesCache.get(time.Now())
+	m.userNamesCache.set([]string{"user-1", "user-2"}, generation, time.Now())
+	userNames, err := m.userNames(l, "user-1")
+	if err != nil {
+		t.Fatalf("expected cached listing not to be rate limited, got %v", err)
+	}
+	if diff := cmp.Diff([]string{"user-1", "user-2"}, userNames); diff != "" {
+		t.Errorf("user names differ from expected:\n%s", diff)
+	}
+
+	m.userNamesCache.invalidate()
+	if _, err := m.userNames(l, "user-1"); !errors.Is(err, errTooManyUserListings) {
+		t.Errorf("expected listing that misses the cache to be rate limited, got %v", err)
+	}
+}
+
+func TestMembershipAfterBulkUpdate(t *testing.T) {
+	testCases := []struct {
+		name                    string
+		collection              secretCollection
+		user                    string
+		add                     bool
+		expectedMembers         []string
+		expectedReadOnlyMembers []string
+		expectedErr             error
+	}{
+		{
+			name:            "user is added",
+			collection:      secretCollection{Members: []string{"user-1"}},
+			user:            "user-2",
+			add:             true,
+			expectedMembers: []string{"user-1", "user-2"},
+		},
+		{
+			name:            "adding a member is a no-op",
+			collection:      secretCollection{Members: []string{"user-1", "user-2"}},
+			user:            "user-2",
+			add:             true,
+			expectedMembers: []string{"user-1", "user-2"},
+		},
+		{
+			name:                    "added read-only member is no longer read-only",
+			collection:              secretCollection{Members: []string{"user-1"}, ReadOnlyMembers: []string{"user-2"}},
+			user:                    "user-2",
+			add:                     true,
+			expectedMembers:         []string{"user-1", "user-2"},
+			expectedReadOnlyMembers: []string{},
+		},
+		{
+			name:            "user is removed",
+			collection:      secretCollection{Members: []string{"user-1", "user-2"}},
+			user:            "user-2",
+			expectedMembers: []string{"user-1"},
+		},
+		{
+			name:                    "user is removed from the read-only members",
+			collection:              secretCollection{Members: []string{"user-1"}, ReadOnlyMembers: []string{"user-2", "user-3"}},
+			user:                    "user-2",
+			expectedMembers:         []string{"user-1"},
+			expectedReadOnlyMembers: []string{"user-3"},
+		},
+		{
+			name:        "last member can not be removed",
+			collection:  secretCollection{Members: []string{"user-1"}},
+			user:        "user-1",
+			expectedErr: errors.New("there must be at least one member"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			members, readOnlyMembers, err := membershipAfterBulkUpdate(tc.collection, tc.user, tc.add)
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("error differs from expected: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedMembers, members); diff != "" {
+				t.Errorf("members differ from expected: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedReadOnlyMembers, readOnlyMembers); diff != "" {
+				t.Errorf("read-only members differ from expected: %s", diff)
+			}
+		})
+	}
+}
@@ -3,12 +3,14 @@ package main
 import (
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/prometheus/client_golang/prometheus"
 	uuid "github.com/satori/go.uuid"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 )
 
 type statusCodeCapturingResponseWriter struct {
@@ -63,6 +65,30 @@ func logFor(r *http.Request, w http.ResponseWriter) (l *logrus.Entry, _ http.Res
 	}
 }
 
+// perClientRateLimiter limits the rate at which every single client may call an endpoint
+type perClientRateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	lock     sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func newPerClientRateLimiter(limit rate.Limit, burst int) *perClientRateLimiter {
+	return &perClientRateLimiter{limit: limit, burst: burst, limiters: map[string]*rate.Limiter{}}
+}
+
+func (p *perClientRateLimiter) allow(client string) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	limiter, ok := p.limiters[client]
+	if !ok {
+		limiter = rate.NewLimiter(p.limit, p.burst)
+		p.limiters[client] = limiter
+	}
+	return limiter.Allow()
+}
+
 type instrumentationWrapper struct {
 	*httprouter.Router
 	metrics *prometheus.HistogramVec
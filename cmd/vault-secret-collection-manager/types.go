@@ -1,5 +1,7 @@
 package main
 
+import "time"
+
 type managedVaultPolicy struct {
 	Path map[string]managedVaultPolicyCapabilityList `json:"path,omitempty"`
 }
@@ -12,8 +14,40 @@ type secretCollection struct {
 	Name    string   `json:"name"`
 	Path    string   `json:"path"`
 	Members []string `json:"members,omitempty"`
+	// ReadOnlyMembers can only read and list the secrets of the collection
+	ReadOnlyMembers []string `json:"readOnlyMembers,omitempty"`
+}
+
+// adminSecretCollection is a secret collection as shown in the admin view
+type adminSecretCollection struct {
+	secretCollection `json:",inline"`
+	ItemCount        int `json:"itemCount"`
 }
 
 type secretCollectionUpdateBody struct {
-	Members []string `json:"members,omitempty"`
+	Members         []string `json:"members,omitempty"`
+	ReadOnlyMembers []string `json:"readOnlyMembers,omitempty"`
+}
+
+// bulkMembershipUpdateBody lists the secret collections a user gets added to or removed from
+type bulkMembershipUpdateBody struct {
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+}
+
+// bulkMembershipUpdateResult is the outcome of a bulk membership update for a single secret collection
+type bulkMembershipUpdateResult struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// auditEntry records a change of the members of a secret collection
+type auditEntry struct {
+	Timestamp          time.Time `json:"timestamp"`
+	User               string    `json:"user"`
+	Collection         string    `json:"collection"`
+	OldMembers         []string  `json:"oldMembers,omitempty"`
+	NewMembers         []string  `json:"newMembers,omitempty"`
+	OldReadOnlyMembers []string  `json:"oldReadOnlyMembers,omitempty"`
+	NewReadOnlyMembers []string  `json:"newReadOnlyMembers,omitempty"`
 }
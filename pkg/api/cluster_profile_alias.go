@@ -0,0 +1,52 @@
+package api
+
+import (
+	"fmt"
+)
+
+// IsBuiltInClusterProfile determines whether the profile is one of ClusterProfiles()
+func IsBuiltInClusterProfile(p ClusterProfile) bool {
+	for _, builtIn := range ClusterProfiles() {
+		if builtIn == p {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateClusterProfileAlias checks that the alias neither shadows a built-in profile nor
+// one of the profiles defined in the config, and that its base is a built-in profile.
+func ValidateClusterProfileAlias(details ClusterProfileDetails, configured ClusterProfilesMap) error {
+	if IsBuiltInClusterProfile(details.Profile) {
+		return fmt.Errorf("cluster profile alias %q shadows the built-in profile of the same name", details.Profile)
+	}
+	if _, ok := configured[details.Profile]; ok {
+		return fmt.Errorf("cluster profile alias %q shadows the profile of the same name defined in the config", details.Profile)
+	}
+	if !IsBuiltInClusterProfile(details.BaseProfile) {
+		return fmt.Errorf("cluster profile alias %q: base profile %q is not a built-in profile", details.Profile, details.BaseProfile)
+	}
+	return nil
+}
+
+// IsAlias determines whether the profile is an alias of a built-in profile
+func (m ClusterProfilesMap) IsAlias(p ClusterProfile) bool {
+	return m[p].BaseProfile != ""
+}
+
+// Base returns the built-in profile the profile is an alias of, or the profile itself.
+// Aliases behave like their base profile, except for the secret and the additional environment.
+func (m ClusterProfilesMap) Base(p ClusterProfile) ClusterProfile {
+	if m.IsAlias(p) {
+		return m[p].BaseProfile
+	}
+	return p
+}
+
+// AliasEnv returns the additional environment configured for the profile if it is an alias
+func (m ClusterProfilesMap) AliasEnv(p ClusterProfile) map[string]string {
+	if m.IsAlias(p) {
+		return m[p].Env
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestClusterProfileAlias(t *testing.T) {
+	profiles := ClusterProfilesMap{
+		ClusterProfileAWS: {Profile: ClusterProfileAWS, Secret: "cluster-secrets-aws"},
+		"aws-restricted": {
+			Profile:     "aws-restricted",
+			BaseProfile: ClusterProfileAWS,
+			Secret:      "cluster-secrets-aws-restricted",
+			Env:         map[string]string{"RESTRICTED": "true"},
+		},
+	}
+
+	alias := ClusterProfile("aws-restricted")
+	if !profiles.IsAlias(alias) {
+		t.Errorf("expected %s to be an alias", alias)
+	}
+	for _, p := range []ClusterProfile{ClusterProfileAWS, ClusterProfileGCP} {
+		if profiles.IsAlias(p) {
+			t.Errorf("expected %s not to be an alias", p)
+		}
+		if base := profiles.Base(p); base != p {
+			t.Errorf("expected %s to be its own base, got %s", p, base)
+		}
+		if env := profiles.AliasEnv(p); env != nil {
+			t.Errorf("expected no alias env for %s, got %v", p, env)
+		}
+	}
+	if base := profiles.Base(alias); base != ClusterProfileAWS {
+		t.Errorf("expected the base of %s to be %s, got %s", alias, ClusterProfileAWS, base)
+	}
+	if diff := cmp.Diff(map[string]string{"RESTRICTED": "true"}, profiles.AliasEnv(alias)); diff != "" {
+		t.Errorf("alias env differs from expected: %s", diff)
+	}
+
+	var unknown ClusterProfilesMap
+	if unknown.IsAlias(alias) || unknown.Base(alias) != alias {
+		t.Errorf("expected %s not to be an alias without profiles", alias)
+	}
+}
+
+func TestValidateClusterProfileAlias(t *testing.T) {
+	configured := ClusterProfilesMap{"config-only": {Profile: "config-only"}}
+	testCases := []struct {
+		name     string
+		details  ClusterProfileDetails
+		expected error
+	}{
+		{
+			name:    "valid alias",
+			details: ClusterProfileDetails{Profile: "aws-restricted", BaseProfile: ClusterProfileAWS},
+		},
+		{
+			name:     "alias shadows a built-in profile",
+			details:  ClusterProfileDetails{Profile: ClusterProfileGCP, BaseProfile: ClusterProfileAWS},
+			expected: fmt.Errorf(`cluster profile alias "gcp" shadows the built-in profile of the same name`),
+		},
+		{
+			name:     "alias shadows a profile defined in the config",
+			details:  ClusterProfileDetails{Profile: "config-only", BaseProfile: ClusterProfileAWS},
+			expected: fmt.Errorf(`cluster profile alias "config-only" shadows the profile of the same name defined in the config`),
+		},
+		{
+			name:     "base is not built in",
+			details:  ClusterProfileDetails{Profile: "aws-restricted", BaseProfile: "aws-other-alias"},
+			expected: fmt.Errorf(`cluster profile alias "aws-restricted": base profile "aws-other-alias" is not a built-in profile`),
+		},
+		{
+			name:     "base is only defined in the config",
+			details:  ClusterProfileDetails{Profile: "aws-restricted", BaseProfile: "config-only"},
+			expected: fmt.Errorf(`cluster profile alias "aws-restricted": base profile "config-only" is not a built-in profile`),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, ValidateClusterProfileAlias(tc.details, configured), testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("error differs from expected: %s", diff)
+			}
+		})
+	}
+}
@@ -28,6 +28,9 @@ const (
 	CloudLabel               = "ci-operator.openshift.io/cloud"
 	CloudClusterProfileLabel = "ci-operator.openshift.io/cloud-cluster-profile"
 
+	// PinClusterLabel is the label on a Prow job whose value is the cluster the job must always be dispatched to
+	PinClusterLabel = "ci.openshift.io/pin-cluster"
+
 	NoBuildsLabel = "ci.openshift.io/no-builds"
 	NoBuildsValue = "true"
 
@@ -30,6 +30,10 @@ type JobSpec struct {
 	Metadata               Metadata
 	Target                 string
 	TargetAdditionalSuffix string
+
+	// PodLabels and PodAnnotations are added to every pod created for the job
+	PodLabels      map[string]string
+	PodAnnotations map[string]string
 }
 
 // Namespace returns the namespace of the job. Must not be evaluated
@@ -7,9 +7,9 @@ import (
 
 // LeasesForTest aggregates all the lease configurations in a test.
 // It is assumed that they have been validated and contain only valid and
-// unique values.
-func LeasesForTest(s *MultiStageTestConfigurationLiteral) (ret []StepLease) {
-	if p := s.ClusterProfile; p != "" {
+// unique values. Aliases among the profiles lease like their base profile.
+func LeasesForTest(s *MultiStageTestConfigurationLiteral, profiles ClusterProfilesMap) (ret []StepLease) {
+	if p := profiles.Base(s.ClusterProfile); p != "" {
 		ret = append(ret, StepLease{
 			ResourceType: p.LeaseType(),
 			Env:          DefaultLeaseEnv,
@@ -25,8 +25,8 @@ func LeasesForTest(s *MultiStageTestConfigurationLiteral) (ret []StepLease) {
 
 const maxAddressesRequired = 13
 
-func IPPoolLeaseForTest(s *MultiStageTestConfigurationLiteral, metadata Metadata) (ret StepLease) {
-	p := s.ClusterProfile
+func IPPoolLeaseForTest(s *MultiStageTestConfigurationLiteral, metadata Metadata, profiles ClusterProfilesMap) (ret StepLease) {
+	p := profiles.Base(s.ClusterProfile)
 	if p != "" {
 		if lt := p.IPPoolLeaseType(); lt != "" {
 			if !p.IPPoolLeaseShouldValidateBranch() || branchValidForIPPoolLease(metadata.Branch) {
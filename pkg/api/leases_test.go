@@ -24,6 +24,16 @@ func TestLeasesForTest(t *testing.T) {
 			Env:          DefaultLeaseEnv,
 			Count:        1,
 		}},
+	}, {
+		name: "alias of a cluster profile, lease of the base profile",
+		tests: MultiStageTestConfigurationLiteral{
+			ClusterProfile: "aws-restricted",
+		},
+		expected: []StepLease{{
+			ResourceType: "aws-quota-slice",
+			Env:          DefaultLeaseEnv,
+			Count:        1,
+		}},
 	}, {
 		name: "explicit configuration, lease",
 		tests: MultiStageTestConfigurationLiteral{
@@ -40,7 +50,7 @@ func TestLeasesForTest(t *testing.T) {
 		expected: []StepLease{{ResourceType: "aws-quota-slice"}},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
-			ret := LeasesForTest(&tc.tests)
+			ret := LeasesForTest(&tc.tests, ClusterProfilesMap{"aws-restricted": {Profile: "aws-restricted", BaseProfile: ClusterProfileAWS}})
 			if diff := cmp.Diff(tc.expected, ret); diff != "" {
 				t.Errorf("incorrect leases, diff: %s", diff)
 			}
@@ -79,6 +89,16 @@ func TestIPPoolLeaseForTest(t *testing.T) {
 				Count:        13,
 			},
 		},
+		{
+			name:     "alias of aws",
+			tests:    MultiStageTestConfigurationLiteral{ClusterProfile: "aws-restricted"},
+			metadata: Metadata{Branch: "master"},
+			expected: StepLease{
+				ResourceType: "aws-ip-pools",
+				Env:          DefaultIPPoolLeaseEnv,
+				Count:        13,
+			},
+		},
 		{
 			name:     "aws, but older release branch",
 			tests:    MultiStageTestConfigurationLiteral{ClusterProfile: ClusterProfileAWS},
@@ -87,7 +107,7 @@ func TestIPPoolLeaseForTest(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			ret := IPPoolLeaseForTest(&tc.tests, tc.metadata)
+			ret := IPPoolLeaseForTest(&tc.tests, tc.metadata, ClusterProfilesMap{"aws-restricted": {Profile: "aws-restricted", BaseProfile: ClusterProfileAWS}})
 			if diff := cmp.Diff(tc.expected, ret); diff != "" {
 				t.Errorf("incorrect lease returned, diff: %s", diff)
 			}
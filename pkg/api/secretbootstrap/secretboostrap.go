@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"slices"
 	"strings"
+	"text/template"
 
 	"github.com/getlantern/deepcopy"
 
 	corev1 "k8s.io/api/core/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/yaml"
 
 	"github.com/openshift/ci-tools/pkg/util/gzip"
@@ -21,9 +24,15 @@ type ItemContext struct {
 	Item                 string                 `json:"item,omitempty"`
 	Field                string                 `json:"field,omitempty"`
 	DockerConfigJSONData []DockerConfigJSONData `json:"dockerconfigJSON,omitempty"`
+	// DockerConfigJSONField is a field of the item that holds a complete .dockerconfigjson.
+	// It is validated and used as is, instead of constructing one from DockerConfigJSONData.
+	DockerConfigJSONField string `json:"dockerconfigJSON_field,omitempty"`
 	// If the secret should be base64 decoded before uploading to kube. Encoding
 	// it is useful to be able to store binary data.
 	Base64Decode bool `json:"base64_decode,omitempty"`
+	// AllFieldsExcept imports every field of the item except the listed ones, each
+	// into a key named after the field. The key of the entry itself is not used.
+	AllFieldsExcept []string `json:"all_fields_except,omitempty"`
 }
 
 type DockerConfigJSONData struct {
@@ -46,10 +55,49 @@ type SecretContext struct {
 	// A cluster to target. Mutually exclusive with 'ClusterGroups'
 	Cluster string `json:"cluster,omitempty"`
 	// A list of clusterGroups to target. Mutually exclusive with 'cluster'
-	ClusterGroups []string          `json:"cluster_groups,omitempty"`
-	Namespace     string            `json:"namespace"`
-	Name          string            `json:"name"`
-	Type          corev1.SecretType `json:"type,omitempty"`
+	ClusterGroups []string `json:"cluster_groups,omitempty"`
+	Namespace     string   `json:"namespace"`
+	// The name of the secret. It may reference the target cluster as {{.Cluster}}, use RenderName to expand it.
+	Name string            `json:"name"`
+	Type corev1.SecretType `json:"type,omitempty"`
+	// OnConflict determines how a key that is provided both by this config and by
+	// a user secret synced from Vault is resolved. Defaults to ConflictPolicyError.
+	OnConflict ConflictPolicy `json:"on_conflict,omitempty"`
+}
+
+// ConflictPolicy determines how collisions between config-provided and user-provided keys are resolved
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyError fails the sync of the colliding key
+	ConflictPolicyError ConflictPolicy = "error"
+	// ConflictPolicyConfigWins keeps the value provided by the ci-secret-bootstrap config
+	ConflictPolicyConfigWins ConflictPolicy = "config-wins"
+	// ConflictPolicyUserWins overrides the config-provided value with the user secret
+	ConflictPolicyUserWins ConflictPolicy = "user-wins"
+)
+
+var validConflictPolicies = []ConflictPolicy{"", ConflictPolicyError, ConflictPolicyConfigWins, ConflictPolicyUserWins}
+
+// nameTemplateContext holds the values a secret name template may reference
+type nameTemplateContext struct {
+	Cluster string
+}
+
+// RenderName expands the template in the name of the secret against the target cluster
+func (sc SecretContext) RenderName() (string, error) {
+	if !strings.Contains(sc.Name, "{{") {
+		return sc.Name, nil
+	}
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(sc.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse name template %q: %w", sc.Name, err)
+	}
+	var name strings.Builder
+	if err := tmpl.Execute(&name, nameTemplateContext{Cluster: sc.Cluster}); err != nil {
+		return "", fmt.Errorf("failed to render name template %q: %w", sc.Name, err)
+	}
+	return name.String(), nil
 }
 
 func (sc SecretContext) String() string {
@@ -130,6 +178,7 @@ func (s *SecretConfig) groupClusters() {
 				Namespace:     to.Namespace,
 				Name:          to.Name,
 				Type:          to.Type,
+				OnConflict:    to.OnConflict,
 			}
 			present := false
 			for _, context := range secrets {
@@ -160,31 +209,65 @@ func stripVaultPrefix(s *SecretConfig, pre string) {
 	}
 }
 
+// requiredKeysByType lists the keys the API server requires to be present in secrets of a given type
+var requiredKeysByType = map[corev1.SecretType][]string{
+	corev1.SecretTypeDockerConfigJson: {corev1.DockerConfigJsonKey},
+	corev1.SecretTypeTLS:              {corev1.TLSCertKey, corev1.TLSPrivateKeyKey},
+}
+
 func (c *Config) Validate() error {
 	var errs []error
 	for i, secretConfig := range c.Secrets {
-		var foundKey bool
-		for key := range secretConfig.From {
-			if key == corev1.DockerConfigJsonKey {
-				foundKey = true
+		var expandsAllFields bool
+		for _, key := range sets.List(sets.KeySet(secretConfig.From)) {
+			itemContext := secretConfig.From[key]
+			if len(itemContext.AllFieldsExcept) == 0 {
+				continue
+			}
+			expandsAllFields = true
+			if itemContext.Item == "" {
+				errs = append(errs, fmt.Errorf("key %s in secretConfig[%d] sets all_fields_except without an item", key, i))
 			}
+			if itemContext.Field != "" || itemContext.DockerConfigJSONField != "" || len(itemContext.DockerConfigJSONData) > 0 {
+				errs = append(errs, fmt.Errorf("key %s in secretConfig[%d] sets all_fields_except together with field, dockerconfigJSON or dockerconfigJSON_field, those are mutually exclusive", key, i))
+			}
+		}
+		// the keys of a secret importing all fields of an item are validated once they are expanded
+		if !expandsAllFields {
+			errs = append(errs, secretConfig.ValidateRequiredKeys(i)...)
 		}
-		k := -1
 		for j, secretContext := range secretConfig.To {
-			if err := validation.ValidateSecretInStep(secretContext.Namespace, secretContext.Name); err != nil {
+			name, err := secretContext.RenderName()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("secret[%d] in secretConfig[%d] has an invalid name: %w", j, i, err))
+				continue
+			}
+			if err := validation.ValidateSecretInStep(secretContext.Namespace, name); err != nil {
 				errs = append(errs, fmt.Errorf("secret[%d] in secretConfig[%d] cannot be used in a step: %w", j, i, err))
 			}
-			if secretContext.Type == corev1.SecretTypeDockerConfigJson {
-				k = j
+			if !slices.Contains(validConflictPolicies, secretContext.OnConflict) {
+				errs = append(errs, fmt.Errorf("secret[%d] in secretConfig[%d] has an invalid on_conflict policy %q, must be one of %s, %s or %s", j, i, secretContext.OnConflict, ConflictPolicyError, ConflictPolicyConfigWins, ConflictPolicyUserWins))
 			}
 		}
-		if !foundKey && k > -1 {
-			errs = append(errs, fmt.Errorf("secret[%d] in secretConfig[%d] with kubernetes.io/dockerconfigjson type have no key named .dockerconfigjson", k, i))
-		}
 	}
 	return utilerrors.NewAggregate(errs)
 }
 
+// ValidateRequiredKeys determines whether the secret config populates the keys required by the
+// types of its targets. The keys imported with all_fields_except are only known once they are
+// expanded using the secret store, so the secret config is expected to be expanded already.
+func (s SecretConfig) ValidateRequiredKeys(idx int) []error {
+	var errs []error
+	for j, secretContext := range s.To {
+		for _, key := range requiredKeysByType[secretContext.Type] {
+			if _, ok := s.From[key]; !ok {
+				errs = append(errs, fmt.Errorf("secret[%d] in secretConfig[%d] with %s type have no key named %s", j, idx, secretContext.Type, key))
+			}
+		}
+	}
+	return errs
+}
+
 func (c *Config) resolve() error {
 	var errs []error
 
@@ -212,6 +295,7 @@ func (c *Config) resolve() error {
 						Namespace:     to.Namespace,
 						Name:          to.Name,
 						Type:          to.Type,
+						OnConflict:    to.OnConflict,
 					})
 				}
 			}
@@ -410,6 +410,48 @@ func TestValidate(t *testing.T) {
 				}}}}},
 			expected: utilerrors.NewAggregate([]error{fmt.Errorf("secret[0] in secretConfig[0] with kubernetes.io/dockerconfigjson type have no key named .dockerconfigjson")}),
 		},
+		{
+			name: "kubernetes.io/tls type with the desired keys",
+			config: &Config{Secrets: []SecretConfig{{
+				From: map[string]ItemContext{
+					"tls.crt": {},
+					"tls.key": {},
+				},
+				To: []SecretContext{{
+					Cluster: "cl",
+					Type:    "kubernetes.io/tls",
+				}}}}},
+		},
+		{
+			name: "kubernetes.io/tls type without the desired keys",
+			config: &Config{Secrets: []SecretConfig{{
+				From: map[string]ItemContext{
+					"tls.crt": {},
+				},
+				To: []SecretContext{
+					{
+						Cluster: "cl",
+						Name:    "opaque",
+					},
+					{
+						Cluster: "cl",
+						Name:    "tls",
+						Type:    "kubernetes.io/tls",
+					},
+				}}}},
+			expected: utilerrors.NewAggregate([]error{fmt.Errorf("secret[1] in secretConfig[0] with kubernetes.io/tls type have no key named tls.key")}),
+		},
+		{
+			name: "kubernetes.io/tls type importing all fields of an item is validated once expanded",
+			config: &Config{Secrets: []SecretConfig{{
+				From: map[string]ItemContext{
+					"all": {Item: "item", AllFieldsExcept: []string{"ca.crt"}},
+				},
+				To: []SecretContext{{
+					Cluster: "cl",
+					Type:    "kubernetes.io/tls",
+				}}}}},
+		},
 		{
 			name: "long name",
 			config: &Config{Secrets: []SecretConfig{{
@@ -423,6 +465,86 @@ func TestValidate(t *testing.T) {
 				}}}}},
 			expected: utilerrors.NewAggregate([]error{fmt.Errorf("secret[0] in secretConfig[0] cannot be used in a step: volumeName test-credentials-very-very-very-very-very-very-very-very-very-long: [must be no more than 63 characters]")}),
 		},
+		{
+			name: "name templated with the cluster",
+			config: &Config{Secrets: []SecretConfig{{
+				From: map[string]ItemContext{
+					"some": {},
+				},
+				To: []SecretContext{{
+					Cluster:   "cl",
+					Namespace: "test-credentials",
+					Name:      "secret-{{.Cluster}}",
+				}}}}},
+		},
+		{
+			name: "name template with an unknown key",
+			config: &Config{Secrets: []SecretConfig{{
+				From: map[string]ItemContext{
+					"some": {},
+				},
+				To: []SecretContext{{
+					Cluster:   "cl",
+					Namespace: "test-credentials",
+					Name:      "secret-{{.Region}}",
+				}}}}},
+			expected: utilerrors.NewAggregate([]error{fmt.Errorf(`secret[0] in secretConfig[0] has an invalid name: failed to render name template "secret-{{.Region}}": template: name:1:9: executing "name" at <.Region>: can't evaluate field Region in type secretbootstrap.nameTemplateContext`)}),
+		},
+		{
+			name: "valid on_conflict policy",
+			config: &Config{Secrets: []SecretConfig{{
+				From: map[string]ItemContext{
+					"some": {},
+				},
+				To: []SecretContext{{
+					Cluster:    "cl",
+					Namespace:  "test-credentials",
+					Name:       "secret",
+					OnConflict: ConflictPolicyUserWins,
+				}}}}},
+		},
+		{
+			name: "invalid on_conflict policy",
+			config: &Config{Secrets: []SecretConfig{{
+				From: map[string]ItemContext{
+					"some": {},
+				},
+				To: []SecretContext{{
+					Cluster:    "cl",
+					Namespace:  "test-credentials",
+					Name:       "secret",
+					OnConflict: "whoever-wins",
+				}}}}},
+			expected: utilerrors.NewAggregate([]error{fmt.Errorf(`secret[0] in secretConfig[0] has an invalid on_conflict policy "whoever-wins", must be one of error, config-wins or user-wins`)}),
+		},
+		{
+			name: "all_fields_except",
+			config: &Config{Secrets: []SecretConfig{{
+				From: map[string]ItemContext{
+					"all": {Item: "item", AllFieldsExcept: []string{"unwanted"}},
+				},
+				To: []SecretContext{{
+					Cluster:   "cl",
+					Namespace: "test-credentials",
+					Name:      "secret",
+				}}}}},
+		},
+		{
+			name: "all_fields_except without an item and with a field",
+			config: &Config{Secrets: []SecretConfig{{
+				From: map[string]ItemContext{
+					"all": {Field: "field", AllFieldsExcept: []string{"unwanted"}},
+				},
+				To: []SecretContext{{
+					Cluster:   "cl",
+					Namespace: "test-credentials",
+					Name:      "secret",
+				}}}}},
+			expected: utilerrors.NewAggregate([]error{
+				fmt.Errorf("key all in secretConfig[0] sets all_fields_except without an item"),
+				fmt.Errorf("key all in secretConfig[0] sets all_fields_except together with field, dockerconfigJSON or dockerconfigJSON_field, those are mutually exclusive"),
+			}),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -433,3 +555,34 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderName(t *testing.T) {
+	testCases := []struct {
+		name          string
+		secretContext SecretContext
+		expected      string
+	}{
+		{
+			name:          "plain name is kept",
+			secretContext: SecretContext{Cluster: "build01", Name: "secret"},
+			expected:      "secret",
+		},
+		{
+			name:          "cluster is expanded",
+			secretContext: SecretContext{Cluster: "build01", Name: "secret-{{.Cluster}}"},
+			expected:      "secret-build01",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.secretContext.RenderName()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("rendered name differs from expected: %s", diff)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package secretgenerator
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/getlantern/deepcopy"
@@ -80,9 +81,21 @@ func (c Config) IsFieldGenerated(name, component string) bool {
 }
 
 type FieldGenerator struct {
-	Name    string `json:"name,omitempty"`
-	Cmd     string `json:"cmd,omitempty"`
-	Cluster string `json:"-"`
+	Name string `json:"name,omitempty"`
+	Cmd  string `json:"cmd,omitempty"`
+	// Inputs are names of other fields of the same item that must be generated
+	// before this one. The value of each is exposed to Cmd in the environment
+	// variable named by InputEnvName.
+	Inputs  []string `json:"inputs,omitempty"`
+	Cluster string   `json:"-"`
+}
+
+var invalidEnvNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// InputEnvName returns the name of the environment variable that exposes the
+// value of the given input field, e.g. FIELD_sa_token_txt for sa.token.txt.
+func InputEnvName(field string) string {
+	return "FIELD_" + invalidEnvNameChars.ReplaceAllString(field, "_")
 }
 
 type SecretItem struct {
@@ -92,6 +105,52 @@ type SecretItem struct {
 	Params   map[string][]string `json:"params,omitempty"`
 }
 
+// FieldsInGenerationOrder returns the fields of the item ordered so that each
+// field comes after all of its inputs, otherwise keeping the configured order.
+func (si SecretItem) FieldsInGenerationOrder() ([]FieldGenerator, error) {
+	byName := map[string]int{}
+	for i, field := range si.Fields {
+		byName[field.Name] = i
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make([]int, len(si.Fields))
+	var ordered []FieldGenerator
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		field := si.Fields[i]
+		path = append(path, field.Name)
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("item %s: fields form a cycle: %s", si.ItemName, strings.Join(path, " -> "))
+		}
+		state[i] = visiting
+		for _, input := range field.Inputs {
+			j, ok := byName[input]
+			if !ok {
+				return fmt.Errorf("item %s: field %s uses unknown field %s as input", si.ItemName, field.Name, input)
+			}
+			if err := visit(j, path); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		ordered = append(ordered, field)
+		return nil
+	}
+	for i := range si.Fields {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
 func (si SecretItem) generateItemsFromParams() ([]SecretItem, error) {
 	var errs []error
 	var processedBwItems []SecretItem
@@ -114,6 +173,9 @@ func (si SecretItem) generateItemsFromParams() ([]SecretItem, error) {
 				for i, field := range argItem.Fields {
 					argItem.Fields[i].Name = replaceParameter(paramName, param, field.Name)
 					argItem.Fields[i].Cmd = replaceParameter(paramName, param, field.Cmd)
+					for j, input := range field.Inputs {
+						argItem.Fields[i].Inputs[j] = replaceParameter(paramName, param, input)
+					}
 					if paramName == "cluster" {
 						argItem.Fields[i].Cluster = param
 					}
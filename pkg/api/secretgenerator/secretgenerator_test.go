@@ -1,11 +1,14 @@
 package secretgenerator
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"sort"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"github.com/openshift/ci-tools/pkg/testhelper"
 )
 
@@ -51,3 +54,89 @@ func TestLoadConfigFromPath(t *testing.T) {
 		})
 	}
 }
+
+func TestFieldsInGenerationOrder(t *testing.T) {
+	testCases := []struct {
+		name          string
+		item          SecretItem
+		expected      []string
+		expectedError error
+	}{
+		{
+			name: "no inputs keeps the configured order",
+			item: SecretItem{ItemName: "item", Fields: []FieldGenerator{
+				{Name: "b"}, {Name: "a"},
+			}},
+			expected: []string{"b", "a"},
+		},
+		{
+			name: "inputs are generated first",
+			item: SecretItem{ItemName: "item", Fields: []FieldGenerator{
+				{Name: "htpasswd", Inputs: []string{"password", "user"}},
+				{Name: "user"},
+				{Name: "password"},
+			}},
+			expected: []string{"password", "user", "htpasswd"},
+		},
+		{
+			name: "transitive inputs",
+			item: SecretItem{ItemName: "item", Fields: []FieldGenerator{
+				{Name: "c", Inputs: []string{"b"}},
+				{Name: "b", Inputs: []string{"a"}},
+				{Name: "a"},
+			}},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name: "unknown input",
+			item: SecretItem{ItemName: "item", Fields: []FieldGenerator{
+				{Name: "a", Inputs: []string{"missing"}},
+			}},
+			expectedError: errors.New("item item: field a uses unknown field missing as input"),
+		},
+		{
+			name: "self reference",
+			item: SecretItem{ItemName: "item", Fields: []FieldGenerator{
+				{Name: "a", Inputs: []string{"a"}},
+			}},
+			expectedError: errors.New("item item: fields form a cycle: a -> a"),
+		},
+		{
+			name: "cycle",
+			item: SecretItem{ItemName: "item", Fields: []FieldGenerator{
+				{Name: "a"},
+				{Name: "b", Inputs: []string{"a", "c"}},
+				{Name: "c", Inputs: []string{"d"}},
+				{Name: "d", Inputs: []string{"b"}},
+			}},
+			expectedError: errors.New("item item: fields form a cycle: b -> c -> d -> b"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fields, err := tc.item.FieldsInGenerationOrder()
+			if diff := cmp.Diff(tc.expectedError, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			var actual []string
+			for _, field := range fields {
+				actual = append(actual, field.Name)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected order: %s", diff)
+			}
+		})
+	}
+}
+
+func TestInputEnvName(t *testing.T) {
+	for field, expected := range map[string]string{
+		"password":                 "FIELD_password",
+		"sa.ci-operator.token.txt": "FIELD_sa_ci_operator_token_txt",
+		"already_valid_NAME_123":   "FIELD_already_valid_NAME_123",
+	} {
+		if actual := InputEnvName(field); actual != expected {
+			t.Errorf("%s: expected %s, got %s", field, expected, actual)
+		}
+	}
+}
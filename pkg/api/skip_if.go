@@ -0,0 +1,150 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// skipComparison is a single `NAME == value` or `NAME != value` term of a
+// `skip_if` expression.
+type skipComparison struct {
+	name   string
+	value  string
+	negate bool
+}
+
+func (c skipComparison) matches(env map[string]string) bool {
+	return (env[c.name] == c.value) != c.negate
+}
+
+var skipIfNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// parseSkipIf parses a `skip_if` expression into a disjunction of
+// conjunctions of comparisons. The grammar is intentionally minimal:
+// comparisons of an environment variable to a literal value with `==` or
+// `!=`, joined with `&&` and `||` with the usual precedence. Values may be
+// wrapped in single or double quotes, which is required to compare against
+// the empty string or against a value containing an operator.
+func parseSkipIf(expr string) ([][]skipComparison, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+	if quote := unterminatedQuote(expr); quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	var ret [][]skipComparison
+	for _, disjunct := range splitUnquoted(expr, "||") {
+		var conjunction []skipComparison
+		for _, term := range splitUnquoted(disjunct, "&&") {
+			comparison, err := parseSkipComparison(term)
+			if err != nil {
+				return nil, err
+			}
+			conjunction = append(conjunction, comparison)
+		}
+		ret = append(ret, conjunction)
+	}
+	return ret, nil
+}
+
+// unterminatedQuote returns the quote character that is opened in s but
+// never closed, or 0 if all quotes are closed.
+func unterminatedQuote(s string) byte {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote == 0 && (s[i] == '"' || s[i] == '\''):
+			quote = s[i]
+		case s[i] == quote:
+			quote = 0
+		}
+	}
+	return quote
+}
+
+// indexUnquoted returns the index of the first occurrence of sep in s that
+// is not within single or double quotes, or -1 if there is none.
+func indexUnquoted(s, sep string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case strings.HasPrefix(s[i:], sep):
+			return i
+		}
+	}
+	return -1
+}
+
+// splitUnquoted splits s around the occurrences of sep that are not within
+// single or double quotes.
+func splitUnquoted(s, sep string) []string {
+	var ret []string
+	for i := indexUnquoted(s, sep); i != -1; i = indexUnquoted(s, sep) {
+		ret = append(ret, s[:i])
+		s = s[i+len(sep):]
+	}
+	return append(ret, s)
+}
+
+func parseSkipComparison(term string) (skipComparison, error) {
+	var ret skipComparison
+	op := "=="
+	i := indexUnquoted(term, op)
+	if j := indexUnquoted(term, "!="); j != -1 && (i == -1 || j < i) {
+		op, i = "!=", j
+		ret.negate = true
+	}
+	if i == -1 {
+		return ret, fmt.Errorf("%q: expected a comparison using == or !=", strings.TrimSpace(term))
+	}
+	ret.name = strings.TrimSpace(term[:i])
+	if !skipIfNameRe.MatchString(ret.name) {
+		return ret, fmt.Errorf("%q: %q is not a valid environment variable name", strings.TrimSpace(term), ret.name)
+	}
+	value := strings.TrimSpace(term[i+len(op):])
+	if indexUnquoted(value, "==") != -1 || indexUnquoted(value, "!=") != -1 {
+		return ret, fmt.Errorf("%q: only one comparison is allowed per term", strings.TrimSpace(term))
+	}
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	} else if value == "" {
+		return ret, fmt.Errorf("%q: missing value, use \"\" to compare against an empty value", strings.TrimSpace(term))
+	}
+	ret.value = value
+	return ret, nil
+}
+
+// ValidateSkipIf determines whether a `skip_if` expression is well-formed.
+func ValidateSkipIf(expr string) error {
+	_, err := parseSkipIf(expr)
+	return err
+}
+
+// EvaluateSkipIf evaluates a `skip_if` expression against the environment of
+// a step. Variables not present in the environment compare as empty.
+func EvaluateSkipIf(expr string, env map[string]string) (bool, error) {
+	disjunction, err := parseSkipIf(expr)
+	if err != nil {
+		return false, err
+	}
+	for _, conjunction := range disjunction {
+		matched := true
+		for _, comparison := range conjunction {
+			if !comparison.matches(env) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEvaluateSkipIf(t *testing.T) {
+	env := map[string]string{"FIPS_ENABLED": "true", "CLUSTER_TYPE": "aws", "EMPTY": "", "QUOTED": "c && d != e"}
+	for _, tc := range []struct {
+		name        string
+		expr        string
+		expected    bool
+		expectedErr string
+	}{{
+		name:     "equality matches",
+		expr:     "FIPS_ENABLED == true",
+		expected: true,
+	}, {
+		name: "equality does not match",
+		expr: "CLUSTER_TYPE == gcp",
+	}, {
+		name:     "inequality matches",
+		expr:     "CLUSTER_TYPE != gcp",
+		expected: true,
+	}, {
+		name:     "quoted values",
+		expr:     `CLUSTER_TYPE == "aws" && EMPTY == ''`,
+		expected: true,
+	}, {
+		name:     "unset variables compare as empty",
+		expr:     `UNSET == ""`,
+		expected: true,
+	}, {
+		name: "conjunction requires all terms",
+		expr: "FIPS_ENABLED == true && CLUSTER_TYPE == gcp",
+	}, {
+		name:     "disjunction requires any conjunction",
+		expr:     "CLUSTER_TYPE == gcp || FIPS_ENABLED == true && CLUSTER_TYPE == aws",
+		expected: true,
+	}, {
+		name:     "operators within quoted values",
+		expr:     `CLUSTER_TYPE == "a||b" || QUOTED == 'c && d != e'`,
+		expected: true,
+	}, {
+		name: "quoted operators do not split the expression",
+		expr: `CLUSTER_TYPE == "aws||FIPS_ENABLED == true"`,
+	}, {
+		name:        "empty expression",
+		expr:        " ",
+		expectedErr: "expression is empty",
+	}, {
+		name:        "no operator",
+		expr:        "FIPS_ENABLED",
+		expectedErr: `"FIPS_ENABLED": expected a comparison using == or !=`,
+	}, {
+		name:        "invalid name",
+		expr:        "$FIPS == true",
+		expectedErr: `"$FIPS == true": "$FIPS" is not a valid environment variable name`,
+	}, {
+		name:        "missing value",
+		expr:        "FIPS_ENABLED ==",
+		expectedErr: `"FIPS_ENABLED ==": missing value, use "" to compare against an empty value`,
+	}, {
+		name:        "chained comparison",
+		expr:        "A == B == C",
+		expectedErr: `"A == B == C": only one comparison is allowed per term`,
+	}, {
+		name:        "unterminated quote",
+		expr:        `CLUSTER_TYPE == "aws || FIPS_ENABLED == true`,
+		expectedErr: `unterminated " quote`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := EvaluateSkipIf(tc.expr, env)
+			var actualErr string
+			if err != nil {
+				actualErr = err.Error()
+			}
+			if diff := cmp.Diff(tc.expectedErr, actualErr); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -791,6 +791,10 @@ type TestStepConfiguration struct {
 	// Optional indicates that the job's status context, that is generated from the corresponding test, should not be required for merge.
 	Optional bool `json:"optional,omitempty"`
 
+	// Informing indicates that the test is run and its results and JUnit are recorded as usual,
+	// but the job always reports success, so the test surfaces data without blocking merges.
+	Informing bool `json:"informing,omitempty"`
+
 	// Portable allows to port periodic tests to current and future release despite the demand to skip periodics
 	Portable bool `json:"portable,omitempty"`
 
@@ -872,7 +876,7 @@ type ClusterClaim struct {
 	// Labels is the labels to select the cluster pools
 	Labels map[string]string `json:"labels,omitempty"`
 	// Timeout is how long ci-operator will wait for the cluster to be ready.
-	// Defaults to 1h.
+	// Defaults to 1h, may be at most 3h.
 	Timeout *prowv1.Duration `json:"timeout,omitempty"`
 }
 
@@ -1028,6 +1032,13 @@ type LiteralTestStep struct {
 	// Dependencies lists images which must be available before the test runs
 	// and the environment variables which are used to expose their pull specs.
 	Dependencies []StepDependency `json:"dependencies,omitempty"`
+	// OutputImage is the name of an image that this step produces by tagging
+	// or pushing it into the `pipeline` image stream of the test namespace.
+	// When any step of a test sets it, the steps of the test are allowed to
+	// create image stream tags and push to the `pipeline` image stream.
+	// Later steps of the same test can consume it with a dependency on
+	// `pipeline:<output_image>`, which is resolved right before they run.
+	OutputImage string `json:"output_image,omitempty"`
 	// DnsConfig for step's Pod.
 	DNSConfig *StepDNSConfig `json:"dnsConfig,omitempty"`
 	// Leases lists resources that should be acquired for the test.
@@ -1037,11 +1048,29 @@ type LiteralTestStep struct {
 	// flag is set to true in MultiStageTestConfiguration. This option is
 	// applicable to `post` steps.
 	OptionalOnSuccess *bool `json:"optional_on_success,omitempty"`
+	// SkipOnFailure defines if this step should be skipped when any of
+	// the `pre` or `test` steps failed, e.g. because it reports on a
+	// successful run. This option is applicable to `post` steps and is
+	// mutually exclusive with OptionalOnSuccess.
+	SkipOnFailure *bool `json:"skip_on_failure,omitempty"`
 	// BestEffort defines if this step should cause the job to fail when the
 	// step fails. This only applies when AllowBestEffortPostSteps flag is set
 	// to true in MultiStageTestConfiguration. This option is applicable to
 	// `post` steps.
 	BestEffort *bool `json:"best_effort,omitempty"`
+	// SkipIf is an expression evaluated against the environment of the step
+	// before it runs. When it is true, the step is not run and is reported
+	// as skipped. Comparisons of a variable to a value use `==` or `!=` and
+	// may be combined with `&&` and `||`, e.g. `FIPS_ENABLED == true`.
+	SkipIf string `json:"skip_if,omitempty"`
+	// CollectArtifacts defines if the contents of $ARTIFACT_DIR are collected
+	// when the step finishes. It defaults to true, steps producing only
+	// throwaway output can disable it to avoid uploading that output.
+	CollectArtifacts *bool `json:"collect_artifacts,omitempty"`
+	// ArtifactDir is the directory, relative to the artifacts of the test,
+	// that the artifacts of the step are collected into. It defaults to the
+	// name of the step and cannot be the directory of another step.
+	ArtifactDir string `json:"artifact_dir,omitempty"`
 	// NoKubeconfig determines that no $KUBECONFIG will exist in $SHARED_DIR,
 	// so no local copy of it will be created for the step and if the step
 	// creates one, it will not be propagated.
@@ -2478,6 +2507,11 @@ type ProjectDirectoryImageBuildInputs struct {
 	// See https://docs.docker.com/engine/reference/builder/#/arg for more details.
 	BuildArgs []BuildArg `json:"build_args,omitempty"`
 
+	// BuildArgsFrom contains build arguments whose value is the pull spec of
+	// an image the job depends on, resolved when the build is created.
+	// Not supported for the build root.
+	BuildArgsFrom []BuildArgFrom `json:"build_args_from,omitempty"`
+
 	// Ref is an optional string linking to the extra_ref in "org.repo" format that this belongs to
 	Ref string `json:"ref,omitempty"`
 }
@@ -2490,6 +2524,16 @@ type BuildArg struct {
 	Value string `json:"value,omitempty"`
 }
 
+// BuildArgFrom is a build arg whose value is the pull spec of an image
+type BuildArgFrom struct {
+	// Name of the build arg.
+	Name string `json:"name"`
+
+	// Dependency is the image whose pull spec is passed in the build arg,
+	// in the `tag` or `stream:tag` form used by multi-stage step dependencies.
+	Dependency string `json:"dependency"`
+}
+
 // PullSpecSubstitution contains a name of a pullspec that needs to
 // be substituted with the name of a different pullspec. This is used
 // for generated operator bundle images.
@@ -2607,6 +2651,11 @@ type ClusterProfileDetails struct {
 	LeaseType   string                 `yaml:"lease_type,omitempty" json:"lease_type,omitempty"`
 	Secret      string                 `yaml:"secret,omitempty" json:"secret,omitempty"`
 	ConfigMap   string                 `yaml:"config_map,omitempty" json:"config_map,omitempty"`
+	// BaseProfile makes this profile an alias of a built-in profile, which it
+	// behaves like except for the secret and the additional environment.
+	BaseProfile ClusterProfile `yaml:"base_profile,omitempty" json:"base_profile,omitempty"`
+	// Env is additional environment exposed to the steps using an alias.
+	Env map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
 }
 
 type ClusterProfileOwners struct {
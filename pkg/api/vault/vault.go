@@ -7,6 +7,9 @@ import (
 )
 
 const (
+	// SecretSyncKeyPrefix is the prefix of the keys that configure the sync of a user secret
+	SecretSyncKeyPrefix = "secretsync/"
+
 	SecretSyncTargetNamepaceKey = "secretsync/target-namespace"
 	SecretSyncTargetNameKey     = "secretsync/target-name"
 	SecretSyncTargetClusterKey  = "secretsync/target-clusters"
@@ -23,6 +23,21 @@ func (in *BuildArg) DeepCopy() *BuildArg {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildArgFrom) DeepCopyInto(out *BuildArgFrom) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildArgFrom.
+func (in *BuildArgFrom) DeepCopy() *BuildArgFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildArgFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildRootImageConfiguration) DeepCopyInto(out *BuildRootImageConfiguration) {
 	*out = *in
@@ -230,6 +245,13 @@ func (in *ClusterProfileDetails) DeepCopyInto(out *ClusterProfileDetails) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProfileDetails.
@@ -667,11 +689,21 @@ func (in *LiteralTestStep) DeepCopyInto(out *LiteralTestStep) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.SkipOnFailure != nil {
+		in, out := &in.SkipOnFailure, &out.SkipOnFailure
+		*out = new(bool)
+		**out = **in
+	}
 	if in.BestEffort != nil {
 		in, out := &in.BestEffort, &out.BestEffort
 		*out = new(bool)
 		**out = **in
 	}
+	if in.CollectArtifacts != nil {
+		in, out := &in.CollectArtifacts, &out.CollectArtifacts
+		*out = new(bool)
+		**out = **in
+	}
 	if in.NoKubeconfig != nil {
 		in, out := &in.NoKubeconfig, &out.NoKubeconfig
 		*out = new(bool)
@@ -1283,6 +1315,11 @@ func (in *ProjectDirectoryImageBuildInputs) DeepCopyInto(out *ProjectDirectoryIm
 		*out = make([]BuildArg, len(*in))
 		copy(*out, *in)
 	}
+	if in.BuildArgsFrom != nil {
+		in, out := &in.BuildArgsFrom, &out.BuildArgsFrom
+		*out = make([]BuildArgFrom, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectDirectoryImageBuildInputs.
@@ -57,7 +57,7 @@ func (b *GeneratedReleaseGatingJobsBumper) GetFiles() ([]string, error) {
 }
 
 func (b *GeneratedReleaseGatingJobsBumper) Unmarshall(file string) (*cioperatorcfg.DataWithInfo, error) {
-	cfgDataByFilename, err := cioperatorcfg.LoadDataByFilename(file)
+	cfgDataByFilename, err := cioperatorcfg.LoadDataByFilename(file, nil)
 	if err != nil {
 		return nil, err
 	}
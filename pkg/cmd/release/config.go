@@ -70,7 +70,7 @@ func cmdConfigList(paths []string) error {
 
 func cmdConfigPrint(resolver registry.Resolver, paths []string) error {
 	for _, p := range paths {
-		if err := config.OperateOnCIOperatorConfigDir(p, func(
+		if err := config.OperateOnCIOperatorConfigDir(p, nil, func(
 			conf *api.ReleaseBuildConfiguration,
 			_ *config.Info,
 		) error {
@@ -122,7 +122,7 @@ type Rehearsals struct {
 	DisabledRehearsals []string `json:"disabled_rehearsals,omitempty"`
 }
 
-func readCiOperatorConfig(configFilePath string, info Info) (*cioperatorapi.ReleaseBuildConfiguration, error) {
+func readCiOperatorConfig(configFilePath string, info Info, clusterProfiles cioperatorapi.ClusterProfilesMap) (*cioperatorapi.ReleaseBuildConfiguration, error) {
 	data, err := gzip.ReadFileMaybeGZIP(configFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read ci-operator config (%w)", err)
@@ -133,7 +133,7 @@ func readCiOperatorConfig(configFilePath string, info Info) (*cioperatorapi.Rele
 		return nil, fmt.Errorf("failed to load ci-operator config (%w)", err)
 	}
 
-	if err := validation.IsValidConfiguration(&configSpec, info.Org, info.Repo); err != nil {
+	if err := validation.IsValidConfiguration(&configSpec, info.Org, info.Repo, clusterProfiles); err != nil {
 		return nil, fmt.Errorf("invalid ci-operator config: %w", err)
 	}
 
@@ -227,14 +227,15 @@ func isMountSpecialFile(path string) bool {
 }
 
 // OperateOnCIOperatorConfig runs the callback on the parsed data from
-// the CI Operator configuration file provided
-func OperateOnCIOperatorConfig(path string, callback ConfigIterFunc) error {
+// the CI Operator configuration file provided. The aliases among the
+// cluster profiles are accepted in addition to the built-in profiles.
+func OperateOnCIOperatorConfig(path string, clusterProfiles cioperatorapi.ClusterProfilesMap, callback ConfigIterFunc) error {
 	info, err := InfoFromPath(path)
 	if err != nil {
 		logrus.WithField("source-file", path).WithError(err).Error("Failed to resolve info from CI Operator configuration path")
 		return err
 	}
-	jobConfig, err := readCiOperatorConfig(path, *info)
+	jobConfig, err := readCiOperatorConfig(path, *info, clusterProfiles)
 	if err != nil {
 		logrus.WithField("source-file", path).WithError(err).Error("Failed to load CI Operator configuration")
 		return err
@@ -247,12 +248,13 @@ func OperateOnCIOperatorConfig(path string, callback ConfigIterFunc) error {
 }
 
 // OperateOnCIOperatorConfigDir runs the callback on all CI Operator
-// configuration files found while walking the directory provided
-func OperateOnCIOperatorConfigDir(configDir string, callback ConfigIterFunc) error {
-	return OperateOnCIOperatorConfigSubdir(configDir, "", callback)
+// configuration files found while walking the directory provided. The aliases
+// among the cluster profiles are accepted in addition to the built-in profiles.
+func OperateOnCIOperatorConfigDir(configDir string, clusterProfiles cioperatorapi.ClusterProfilesMap, callback ConfigIterFunc) error {
+	return OperateOnCIOperatorConfigSubdir(configDir, "", clusterProfiles, callback)
 }
 
-func OperateOnCIOperatorConfigSubdir(configDir, subDir string, callback ConfigIterFunc) error {
+func OperateOnCIOperatorConfigSubdir(configDir, subDir string, clusterProfiles cioperatorapi.ClusterProfilesMap, callback ConfigIterFunc) error {
 	type item struct {
 		config *cioperatorapi.ReleaseBuildConfiguration
 		info   *Info
@@ -287,13 +289,13 @@ func OperateOnCIOperatorConfigSubdir(configDir, subDir string, callback ConfigIt
 				errCh <- err
 				continue
 			}
-			config, err := readCiOperatorConfig(path, *info)
+			config, err := readCiOperatorConfig(path, *info, clusterProfiles)
 			if err != nil {
 				logrus.WithField("source-file", path).WithError(err).Error("Failed to load CI Operator configuration")
 				errCh <- err
 				continue
 			}
-			if err := validation.IsValidRuntimeConfiguration(config); err != nil {
+			if err := validation.IsValidRuntimeConfiguration(config, clusterProfiles); err != nil {
 				errCh <- fmt.Errorf("invalid ci-operator config: %w", err)
 				continue
 			}
@@ -374,9 +376,9 @@ func (all DataByFilename) add(handledConfig *cioperatorapi.ReleaseBuildConfigura
 	return nil
 }
 
-func LoadDataByFilename(path string) (DataByFilename, error) {
+func LoadDataByFilename(path string, clusterProfiles cioperatorapi.ClusterProfilesMap) (DataByFilename, error) {
 	config := DataByFilename{}
-	if err := OperateOnCIOperatorConfigDir(path, config.add); err != nil {
+	if err := OperateOnCIOperatorConfigDir(path, clusterProfiles, config.add); err != nil {
 		return nil, err
 	}
 
@@ -391,9 +393,9 @@ func (all ByFilename) add(handledConfig *cioperatorapi.ReleaseBuildConfiguration
 	return nil
 }
 
-func LoadByFilename(path string) (ByFilename, error) {
+func LoadByFilename(path string, clusterProfiles cioperatorapi.ClusterProfilesMap) (ByFilename, error) {
 	config := ByFilename{}
-	if err := OperateOnCIOperatorConfigDir(path, config.add); err != nil {
+	if err := OperateOnCIOperatorConfigDir(path, clusterProfiles, config.add); err != nil {
 		return nil, err
 	}
 
@@ -413,9 +415,9 @@ func (all ByOrgRepo) add(c *cioperatorapi.ReleaseBuildConfiguration, i *Info) er
 	return nil
 }
 
-func LoadByOrgRepo(path string) (ByOrgRepo, error) {
+func LoadByOrgRepo(path string, clusterProfiles cioperatorapi.ClusterProfilesMap) (ByOrgRepo, error) {
 	config := ByOrgRepo{}
-	if err := OperateOnCIOperatorConfigDir(path, config.add); err != nil {
+	if err := OperateOnCIOperatorConfigDir(path, clusterProfiles, config.add); err != nil {
 		return nil, err
 	}
 	return config, nil
@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"testing"
+	"testing/fstest"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -273,3 +274,67 @@ func TestProwgen_GetSlackReporterConfigForTest(t *testing.T) {
 		})
 	}
 }
+
+func TestOperateOnCIOperatorConfigDirClusterProfileAliases(t *testing.T) {
+	dir, err := testhelper.TmpDir(t, map[string]fstest.MapFile{
+		"foo/bar/foo-bar-master.yaml": {Data: []byte(`build_root:
+  image_stream_tag:
+    name: release
+    namespace: openshift
+    tag: golang-1.10
+resources:
+  '*':
+    requests:
+      cpu: 10m
+tests:
+- as: e2e
+  steps:
+    cluster_profile: custom-aws
+    test:
+    - as: e2e
+      commands: make e2e
+      from: src
+      resources:
+        requests:
+          cpu: 10m
+zz_generated_metadata:
+  branch: master
+  org: foo
+  repo: bar
+`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		name          string
+		profiles      api.ClusterProfilesMap
+		expectedError bool
+	}{
+		{
+			name:          "alias is not known without the cluster profiles config",
+			expectedError: true,
+		},
+		{
+			name: "alias from the cluster profiles config is accepted",
+			profiles: api.ClusterProfilesMap{
+				"custom-aws": {Profile: "custom-aws", BaseProfile: api.ClusterProfileAWS},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var loaded []string
+			err := OperateOnCIOperatorConfigDir(dir, tc.profiles, func(configuration *api.ReleaseBuildConfiguration, info *Info) error {
+				loaded = append(loaded, info.Basename())
+				return nil
+			})
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("expected error: %t, got: %v", tc.expectedError, err)
+			}
+			if !tc.expectedError && len(loaded) != 1 {
+				t.Errorf("expected the config to be loaded, got %v", loaded)
+			}
+		})
+	}
+}
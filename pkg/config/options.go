@@ -25,6 +25,10 @@ type Options struct {
 
 	LogLevel string
 
+	// ClusterProfiles holds the cluster profiles config, whose aliases are
+	// accepted in addition to the built-in profiles when loading configurations
+	ClusterProfiles cioperatorapi.ClusterProfilesMap
+
 	onlyProcessChanges bool
 	modifiedFiles      sets.Set[string]
 }
@@ -113,7 +117,7 @@ func (o *Options) matches(org, repo string) bool {
 // OperateOnCIOperatorConfigDir filters the full set of configurations
 // down to those that were selected by the user with --{org|repo}
 func (o *Options) OperateOnCIOperatorConfigDir(configDir string, callback func(*cioperatorapi.ReleaseBuildConfiguration, *Info) error) error {
-	return OperateOnCIOperatorConfigDir(configDir, func(configuration *cioperatorapi.ReleaseBuildConfiguration, info *Info) error {
+	return OperateOnCIOperatorConfigDir(configDir, o.ClusterProfiles, func(configuration *cioperatorapi.ReleaseBuildConfiguration, info *Info) error {
 		if !o.matches(info.Metadata.Org, info.Metadata.Repo) {
 			return nil
 		}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	"sigs.k8s.io/prow/pkg/plugins"
 	pjdwapi "sigs.k8s.io/prow/pkg/pod-utils/downwardapi"
 
+	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/load"
 	"github.com/openshift/ci-tools/pkg/registry"
 )
@@ -44,6 +46,8 @@ const (
 	StagingNamespace = "ci-stg"
 	// RegistryPath is the path to the multistage step registry
 	RegistryPath = "ci-operator/step-registry"
+	// ClusterProfilesConfigInRepoPath is the path to the cluster profiles config from release repo
+	ClusterProfilesConfigInRepoPath = RegistryPath + "/cluster-profiles/cluster-profiles-config.yaml"
 )
 
 // ConfigMapName returns the name of the ConfigMap to which config-updater would
@@ -127,15 +131,32 @@ func NewLocalJobSpec(path string) (*pjdwapi.JobSpec, error) {
 	return &pjdwapi.JobSpec{Type: pjapi.PresubmitJob, Refs: &refs}, nil
 }
 
+// LoadClusterProfiles loads the cluster profiles config from the working copy of the release repo,
+// which holds the aliases of the cluster profiles. No profiles are returned if there is no such config.
+func LoadClusterProfiles(releaseRepoPath string) (api.ClusterProfilesMap, error) {
+	path := filepath.Join(releaseRepoPath, ClusterProfilesConfigInRepoPath)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	profiles, err := load.ClusterProfilesConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the cluster profiles config from release repo: %w", err)
+	}
+	return profiles, nil
+}
+
 // GetAllConfigs loads all configuration from the working copy of the release repo (usually openshift/release).
 // When an error occurs during some config loading, the error will be propagated, however the returned struct field will
 // also have a nil value in the appropriate field.
 func GetAllConfigs(releaseRepoPath string) (*ReleaseRepoConfig, error) {
 	config := &ReleaseRepoConfig{}
 	var errs []error
-	var err error
+	clusterProfiles, err := LoadClusterProfiles(releaseRepoPath)
+	if err != nil {
+		errs = append(errs, err)
+	}
 	ciopConfigPath := filepath.Join(releaseRepoPath, CiopConfigInRepoPath)
-	config.CiOperator, err = LoadDataByFilename(ciopConfigPath)
+	config.CiOperator, err = LoadDataByFilename(ciopConfigPath, clusterProfiles)
 	if err != nil {
 		errs = append(errs, fmt.Errorf("failed to load ci-operator configuration from release repo: %w", err))
 	}
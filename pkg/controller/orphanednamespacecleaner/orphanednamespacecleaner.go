@@ -0,0 +1,129 @@
+package orphanednamespacecleaner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+
+	controllerutil "github.com/openshift/ci-tools/pkg/controller/util"
+	"github.com/openshift/ci-tools/pkg/steps"
+)
+
+const ControllerName = "orphanednamespacecleaner"
+
+// AddToManager adds a controller for every build cluster that deletes the namespaces ci-operator
+// created for a ProwJob once the ProwJob is gone or completed more than ttl ago. ProwJobs are
+// read from the app.ci manager.
+func AddToManager(
+	mgr manager.Manager,
+	buildClusterManagers map[string]manager.Manager,
+	prowJobNamespace string,
+	ttl time.Duration,
+	drainer *controllerutil.Drainer,
+) error {
+	log := logrus.WithField("controller", ControllerName)
+	for clusterName, clusterManager := range buildClusterManagers {
+		c, err := controller.New(ControllerName+"_"+clusterName, mgr, controller.Options{
+			Reconciler: drainer.Wrap(&reconciler{
+				log:              log.WithField("cluster", clusterName),
+				client:           clusterManager.GetClient(),
+				prowJobClient:    mgr.GetClient(),
+				prowJobNamespace: prowJobNamespace,
+				ttl:              ttl,
+				now:              time.Now,
+			}),
+			MaxConcurrentReconciles: 10,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to construct controller for cluster %s: %w", clusterName, err)
+		}
+
+		predicates := predicate.TypedFuncs[*corev1.Namespace]{
+			CreateFunc: func(e event.TypedCreateEvent[*corev1.Namespace]) bool {
+				return createdByCIOperator(e.Object.GetLabels())
+			},
+			DeleteFunc: func(event.TypedDeleteEvent[*corev1.Namespace]) bool { return false },
+			UpdateFunc: func(e event.TypedUpdateEvent[*corev1.Namespace]) bool {
+				return createdByCIOperator(e.ObjectNew.GetLabels())
+			},
+			GenericFunc: func(e event.TypedGenericEvent[*corev1.Namespace]) bool {
+				return createdByCIOperator(e.Object.GetLabels())
+			},
+		}
+		if err := c.Watch(source.Kind(clusterManager.GetCache(), &corev1.Namespace{}, &handler.TypedEnqueueRequestForObject[*corev1.Namespace]{}, predicates)); err != nil {
+			return fmt.Errorf("failed to watch namespaces in cluster %s: %w", clusterName, err)
+		}
+	}
+
+	log.Info("Successfully added reconciler to manager")
+	return nil
+}
+
+func createdByCIOperator(labels map[string]string) bool {
+	return labels[steps.CreatedByCILabel] == "true" && labels[steps.LabelJobID] != ""
+}
+
+type reconciler struct {
+	log              *logrus.Entry
+	client           ctrlruntimeclient.Client
+	prowJobClient    ctrlruntimeclient.Client
+	prowJobNamespace string
+	ttl              time.Duration
+	now              func() time.Time
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	var ns corev1.Namespace
+	if err := r.client.Get(ctx, req.NamespacedName, &ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("failed to get namespace %s: %w", req.Name, err)
+	}
+	if ns.DeletionTimestamp != nil || !createdByCIOperator(ns.Labels) {
+		return reconcile.Result{}, nil
+	}
+
+	jobID := ns.Labels[steps.LabelJobID]
+	log := r.log.WithFields(logrus.Fields{"namespace": ns.Name, "prowjob": jobID})
+
+	// Jobs that are gone are measured from the namespace creation, so we do not race
+	// with ci-operator creating the namespace right after the ProwJob
+	finished := ns.CreationTimestamp.Time
+	var pj prowv1.ProwJob
+	if err := r.prowJobClient.Get(ctx, types.NamespacedName{Namespace: r.prowJobNamespace, Name: jobID}, &pj); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return reconcile.Result{}, fmt.Errorf("failed to get prowjob %s: %w", jobID, err)
+		}
+	} else {
+		if !pj.Complete() {
+			return reconcile.Result{RequeueAfter: r.ttl}, nil
+		}
+		finished = pj.Status.CompletionTime.Time
+	}
+
+	if age := r.now().Sub(finished); age < r.ttl {
+		return reconcile.Result{RequeueAfter: r.ttl - age}, nil
+	}
+
+	log.Info("Deleting orphaned namespace")
+	if err := r.client.Delete(ctx, &ns); err != nil && !apierrors.IsNotFound(err) {
+		return reconcile.Result{}, fmt.Errorf("failed to delete namespace %s: %w", ns.Name, err)
+	}
+	return reconcile.Result{}, nil
+}
@@ -0,0 +1,122 @@
+package orphanednamespacecleaner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+
+	"github.com/openshift/ci-tools/pkg/steps"
+)
+
+func TestReconcile(t *testing.T) {
+	t.Parallel()
+
+	ttl := time.Hour
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	namespace := func(labels map[string]string, created time.Time) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:              "ci-op-1234",
+			Labels:            labels,
+			CreationTimestamp: metav1.Time{Time: created},
+		}}
+	}
+	ciOperatorLabels := map[string]string{steps.CreatedByCILabel: "true", steps.LabelJobID: "job-id"}
+	prowJob := func(completed *time.Time) *prowv1.ProwJob {
+		pj := &prowv1.ProwJob{ObjectMeta: metav1.ObjectMeta{Name: "job-id", Namespace: "ci"}}
+		if completed != nil {
+			pj.Status.CompletionTime = &metav1.Time{Time: *completed}
+		}
+		return pj
+	}
+	recently, longAgo := now.Add(-10*time.Minute), now.Add(-2*time.Hour)
+
+	testCases := []struct {
+		name       string
+		namespace  *corev1.Namespace
+		prowJob    *prowv1.ProwJob
+		expected   reconcile.Result
+		expectGone bool
+	}{
+		{
+			name:      "namespace not created by ci-operator is ignored",
+			namespace: namespace(nil, longAgo),
+		},
+		{
+			name:      "namespace of a running job is kept",
+			namespace: namespace(ciOperatorLabels, longAgo),
+			prowJob:   prowJob(nil),
+			expected:  reconcile.Result{RequeueAfter: ttl},
+		},
+		{
+			name:      "namespace of a recently completed job is kept",
+			namespace: namespace(ciOperatorLabels, longAgo),
+			prowJob:   prowJob(&recently),
+			expected:  reconcile.Result{RequeueAfter: 50 * time.Minute},
+		},
+		{
+			name:       "namespace of a job that completed before the ttl is deleted",
+			namespace:  namespace(ciOperatorLabels, longAgo),
+			prowJob:    prowJob(&longAgo),
+			expectGone: true,
+		},
+		{
+			name:      "recent namespace of a job that does not exist is kept",
+			namespace: namespace(ciOperatorLabels, recently),
+			expected:  reconcile.Result{RequeueAfter: 50 * time.Minute},
+		},
+		{
+			name:       "old namespace of a job that does not exist is deleted",
+			namespace:  namespace(ciOperatorLabels, longAgo),
+			expectGone: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			scheme := runtime.NewScheme()
+			if err := prowv1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add prowv1 to scheme: %v", err)
+			}
+			prowJobClient := fakectrlruntimeclient.NewClientBuilder().WithScheme(scheme)
+			if tc.prowJob != nil {
+				prowJobClient = prowJobClient.WithObjects(tc.prowJob)
+			}
+			r := &reconciler{
+				log:              logrus.NewEntry(logrus.StandardLogger()),
+				client:           fakectrlruntimeclient.NewClientBuilder().WithObjects(tc.namespace).Build(),
+				prowJobClient:    prowJobClient.Build(),
+				prowJobNamespace: "ci",
+				ttl:              ttl,
+				now:              func() time.Time { return now },
+			}
+
+			result, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "ci-op-1234"}})
+			if err != nil {
+				t.Fatalf("reconcile failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Errorf("reconcile result differs from expected: %s", diff)
+			}
+
+			var list corev1.NamespaceList
+			if err := r.client.List(context.Background(), &list); err != nil {
+				t.Fatalf("failed to list namespaces: %v", err)
+			}
+			if gone := len(list.Items) == 0; gone != tc.expectGone {
+				t.Errorf("expected namespace to be deleted: %t, was deleted: %t", tc.expectGone, gone)
+			}
+		})
+	}
+}
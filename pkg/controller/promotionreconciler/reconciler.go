@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -43,9 +45,28 @@ type Options struct {
 	// that contains our imageRegistry. This cluster is
 	// most likely not the one the normal manager talks to.
 	RegistryManager controllerruntime.Manager
+	// RegistryClusterName is the name of the cluster the RegistryManager
+	// talks to. It is needed to mirror to the secondary registries.
+	RegistryClusterName string
+	// SecondaryRegistryManagers talk to the clusters with secondary
+	// registries, keyed by cluster name. Tags that are current in the
+	// primary registry are mirrored into all of them.
+	SecondaryRegistryManagers map[string]controllerruntime.Manager
 
 	IgnoredImageStreams []*regexp.Regexp
 	Since               time.Duration
+	// SinceOverrides replace Since for the image streams they match,
+	// the first matching override is used
+	SinceOverrides []SinceOverride
+	// Drainer tracks the in-flight reconciles
+	Drainer *controllerutil.Drainer
+}
+
+// SinceOverride is the age up to which the tags of the image streams
+// matching ImageStreams are reconciled
+type SinceOverride struct {
+	ImageStreams *regexp.Regexp
+	Since        time.Duration
 }
 
 const ControllerName = "promotionreconciler"
@@ -87,9 +108,21 @@ func AddToManager(mgr controllerruntime.Manager, opts Options) error {
 		gitHubClient:        opts.GitHubClient,
 		enqueueJob:          prowJobEnqueuer,
 		since:               opts.Since,
+		sinceOverrides:      opts.SinceOverrides,
+	}
+	if len(opts.SecondaryRegistryManagers) > 0 {
+		registryDomain, err := cioperatorapi.RegistryDomainForClusterName(opts.RegistryClusterName)
+		if err != nil {
+			return fmt.Errorf("failed to get registry domain for cluster %s: %w", opts.RegistryClusterName, err)
+		}
+		r.registryDomain = registryDomain
+		r.secondaryRegistryClients = map[string]ctrlruntimeclient.Client{}
+		for cluster, secondaryMgr := range opts.SecondaryRegistryManagers {
+			r.secondaryRegistryClients[cluster] = imagestreamtagwrapper.MustNew(secondaryMgr.GetClient(), secondaryMgr.GetCache())
+		}
 	}
 	c, err := controller.New(ControllerName, opts.RegistryManager, controller.Options{
-		Reconciler: r,
+		Reconciler: opts.Drainer.Wrap(r),
 		// We currently have 50k ImageStreamTags in the OCP namespace and need to periodically reconcile all of them,
 		// so don't be stingy with the workers
 		MaxConcurrentReconciles: 100,
@@ -126,6 +159,18 @@ func ignored(r reconcile.Request, ignoredImageStreams []*regexp.Regexp) bool {
 	return false
 }
 
+// sinceFor returns the age up to which the imageStreamTag is reconciled. Like for the
+// ignored image streams, the overrides are matched against namespace/name:tag.
+func (r *reconciler) sinceFor(req reconcile.Request) time.Duration {
+	is := fmt.Sprintf("%s/%s", req.Namespace, req.Name)
+	for _, override := range r.sinceOverrides {
+		if override.ImageStreams.MatchString(is) {
+			return override.Since
+		}
+	}
+	return r.since
+}
+
 // ciOperatorConfigGetter is needed to for testing. In non-test scenarios it is implemented
 // by using an index on the agents.ConfigAgent
 type ciOperatorConfigGetter func(identifier string) ([]*cioperatorapi.ReleaseBuildConfiguration, error)
@@ -141,6 +186,10 @@ type reconciler struct {
 	gitHubClient        githubClient
 	enqueueJob          prowjobreconciler.Enqueuer
 	since               time.Duration
+	sinceOverrides      []SinceOverride
+	// registryDomain is the domain of the primary registry
+	registryDomain           string
+	secondaryRegistryClients map[string]ctrlruntimeclient.Client
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req controllerruntime.Request) (controllerruntime.Result, error) {
@@ -149,7 +198,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req controllerruntime.Reques
 	startTime := time.Now()
 	defer func() { log.WithField("duration", time.Since(startTime)).Trace("Finished reconciliation") }()
 
-	err := r.reconcile(ctx, req, log)
+	current, err := r.reconcile(ctx, req, log)
 	if err != nil {
 		log := log.WithError(err)
 		// Degrade terminal errors to debug, they most lilely just mean a given imageStreamTag wasn't built
@@ -161,51 +210,58 @@ func (r *reconciler) Reconcile(ctx context.Context, req controllerruntime.Reques
 		}
 	}
 
+	// Failures to mirror into a secondary registry are retried on their own and
+	// do not change the result for the primary registry
+	if current != nil && !r.mirrorToSecondaryRegistries(ctx, current, log) {
+		return controllerruntime.Result{Requeue: true}, controllerutil.SwallowIfTerminal(err)
+	}
+
 	return controllerruntime.Result{}, controllerutil.SwallowIfTerminal(err)
 }
 
-func (r *reconciler) reconcile(ctx context.Context, req controllerruntime.Request, log *logrus.Entry) error {
+// reconcile returns the imageStreamTag if it is current in the primary registry
+func (r *reconciler) reconcile(ctx context.Context, req controllerruntime.Request, log *logrus.Entry) (*imagev1.ImageStreamTag, error) {
 	ist := &imagev1.ImageStreamTag{}
 	if err := r.client.Get(ctx, req.NamespacedName, ist); err != nil {
 		// Object got deleted while it was in the workqueue
 		if apierrors.IsNotFound(err) {
-			return nil
+			return nil, nil
 		}
-		return fmt.Errorf("failed to get object: %w", err)
+		return nil, fmt.Errorf("failed to get object: %w", err)
 	}
 
-	if !ist.CreationTimestamp.After(time.Now().Add(-r.since)) {
+	if !ist.CreationTimestamp.After(time.Now().Add(-r.sinceFor(req))) {
 		log.WithField("creationTimestamp", ist.CreationTimestamp).Trace("Ignored old imageStreamTag")
-		return nil
+		return nil, nil
 	}
 
 	ciOPConfig, err := r.promotionConfig(ist)
 	if err != nil {
-		return fmt.Errorf("failed to get promotionConfig: %w", err)
+		return nil, fmt.Errorf("failed to get promotionConfig: %w", err)
 	}
 	if ciOPConfig == nil || !promotion.AllPromotionImageStreamTags(ciOPConfig).Has(req.String()) {
 		// We don't know how to build this
 		log.Trace("No promotionConfig found")
-		return nil
+		return nil, nil
 	}
 	log = log.WithField("org", ciOPConfig.Metadata.Org).WithField("repo", ciOPConfig.Metadata.Repo).WithField("branch", ciOPConfig.Metadata.Branch)
 
 	istCommit, err := commitForIST(ist, r.client)
 	if err != nil {
-		return controllerutil.TerminalError(fmt.Errorf("failed to get commit for imageStreamTag: %w", err))
+		return nil, controllerutil.TerminalError(fmt.Errorf("failed to get commit for imageStreamTag: %w", err))
 	}
 	log = log.WithField("istCommit", istCommit)
 
 	currentHEAD, found, err := r.currentHEADForBranch(ciOPConfig.Metadata, log)
 	if err != nil {
-		return fmt.Errorf("failed to get current git head for imageStreamTag: %w", err)
+		return nil, fmt.Errorf("failed to get current git head for imageStreamTag: %w", err)
 	}
 	if !found {
-		return controllerutil.TerminalError(fmt.Errorf("got 404 for %s/%s/%s from github, this likely means the repo or branch got deleted or we are not allowed to access it", ciOPConfig.Metadata.Org, ciOPConfig.Metadata.Repo, ciOPConfig.Metadata.Branch))
+		return nil, controllerutil.TerminalError(fmt.Errorf("got 404 for %s/%s/%s from github, this likely means the repo or branch got deleted or we are not allowed to access it", ciOPConfig.Metadata.Org, ciOPConfig.Metadata.Repo, ciOPConfig.Metadata.Branch))
 	}
 	// ImageStreamTag is current, nothing to do
 	if currentHEAD == istCommit {
-		return nil
+		return ist, nil
 	}
 	log = log.WithField("currentHEAD", currentHEAD)
 
@@ -216,6 +272,87 @@ func (r *reconciler) reconcile(ctx context.Context, req controllerruntime.Reques
 		Branch: ciOPConfig.Metadata.Branch,
 		Commit: currentHEAD,
 	})
+	return nil, nil
+}
+
+// mirrorToSecondaryRegistries ensures the imageStreamTag points to the same image in all
+// secondary registries. It returns false if mirroring into any of them failed.
+func (r *reconciler) mirrorToSecondaryRegistries(ctx context.Context, ist *imagev1.ImageStreamTag, log *logrus.Entry) bool {
+	var clusters []string
+	for cluster := range r.secondaryRegistryClients {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	succeeded := true
+	for _, cluster := range clusters {
+		if err := r.mirrorToSecondaryRegistry(ctx, cluster, r.secondaryRegistryClients[cluster], ist); err != nil {
+			log.WithError(err).WithField("secondary_registry_cluster", cluster).Error("Failed to mirror imageStreamTag to secondary registry")
+			succeeded = false
+		}
+	}
+	return succeeded
+}
+
+func (r *reconciler) mirrorToSecondaryRegistry(ctx context.Context, cluster string, client ctrlruntimeclient.Client, ist *imagev1.ImageStreamTag) error {
+	secondary := &imagev1.ImageStreamTag{}
+	if err := client.Get(ctx, types.NamespacedName{Namespace: ist.Namespace, Name: ist.Name}, secondary); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get imageStreamTag %s/%s: %w", ist.Namespace, ist.Name, err)
+		}
+	} else if secondary.Image.Name == ist.Image.Name {
+		return nil
+	}
+
+	if err := client.Get(ctx, types.NamespacedName{Name: ist.Namespace}, &corev1.Namespace{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to check if namespace %s exists: %w", ist.Namespace, err)
+		}
+		if err := client.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ist.Namespace}}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create namespace %s: %w", ist.Namespace, err)
+		}
+	}
+
+	imageStreamName, tag, found := strings.Cut(ist.Name, ":")
+	if !found {
+		return fmt.Errorf("imageStreamTag name %s is not in name:tag format", ist.Name)
+	}
+	imageStreamImport := &imagev1.ImageStreamImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ist.Namespace,
+			Name:      imageStreamName,
+		},
+		Spec: imagev1.ImageStreamImportSpec{
+			Import: true,
+			Images: []imagev1.ImageImportSpec{{
+				ImportPolicy: imagev1.TagImportPolicy{ImportMode: imagev1.ImportModePreserveOriginal},
+				From: corev1.ObjectReference{
+					Kind: "DockerImage",
+					Name: r.registryDomain + "/" + ist.Namespace + "/" + imageStreamName + "@" + ist.Image.Name,
+				},
+				To: &corev1.LocalObjectReference{Name: tag},
+				ReferencePolicy: imagev1.TagReferencePolicy{
+					Type: imagev1.LocalTagReferencePolicy,
+				},
+			}},
+		},
+	}
+
+	// ImageStreamImport is not an ordinary api but a virtual one that does the import synchronously
+	if err := client.Create(ctx, imageStreamImport); err != nil {
+		controllerutil.CountImportResult(ControllerName, cluster, ist.Namespace, imageStreamName, false)
+		return fmt.Errorf("failed to import image: %w", err)
+	}
+	if len(imageStreamImport.Status.Images) == 0 || imageStreamImport.Status.Images[0].Image == nil {
+		controllerutil.CountImportResult(ControllerName, cluster, ist.Namespace, imageStreamName, false)
+		var status metav1.Status
+		if len(imageStreamImport.Status.Images) > 0 {
+			status = imageStreamImport.Status.Images[0].Status
+		}
+		return fmt.Errorf("imageStreamImport did not succeed: reason: %s, message: %s", status.Reason, status.Message)
+	}
+
+	controllerutil.CountImportResult(ControllerName, cluster, ist.Namespace, imageStreamName, true)
 	return nil
 }
 
@@ -18,6 +18,7 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/yaml"
@@ -342,7 +343,7 @@ func TestReconcile(t *testing.T) {
 				since:        since,
 			}
 
-			err := r.reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{
+			_, err := r.reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{
 				Namespace: "namespace",
 				Name:      "name:tag",
 			}}, r.log)
@@ -354,6 +355,91 @@ func TestReconcile(t *testing.T) {
 	}
 }
 
+func TestMirrorToSecondaryRegistries(t *testing.T) {
+	t.Parallel()
+	ist := &imagev1.ImageStreamTag{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "name:tag"},
+		Image:      imagev1.Image{ObjectMeta: metav1.ObjectMeta{Name: "sha256:current"}},
+	}
+	importSucceeds := interceptor.Funcs{Create: func(ctx context.Context, client ctrlruntimeclient.WithWatch, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
+		if isi, ok := obj.(*imagev1.ImageStreamImport); ok {
+			isi.Status.Images = []imagev1.ImageImportStatus{{Image: &imagev1.Image{ObjectMeta: metav1.ObjectMeta{Name: "sha256:current"}}}}
+		}
+		return client.Create(ctx, obj, opts...)
+	}}
+	importFails := interceptor.Funcs{Create: func(ctx context.Context, client ctrlruntimeclient.WithWatch, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
+		if _, ok := obj.(*imagev1.ImageStreamImport); ok {
+			return errors.New("injected failure")
+		}
+		return client.Create(ctx, obj, opts...)
+	}}
+
+	testCases := []struct {
+		name              string
+		clients           map[string]ctrlruntimeclient.Client
+		expectedSucceeded bool
+		expectedImports   map[string]bool
+	}{
+		{
+			name: "current tag is not imported",
+			clients: map[string]ctrlruntimeclient.Client{
+				"secondary": fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(&imagev1.ImageStreamTag{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "name:tag"},
+					Image:      imagev1.Image{ObjectMeta: metav1.ObjectMeta{Name: "sha256:current"}},
+				}).WithInterceptorFuncs(importSucceeds).Build(),
+			},
+			expectedSucceeded: true,
+			expectedImports:   map[string]bool{"secondary": false},
+		},
+		{
+			name: "outdated and missing tags are imported",
+			clients: map[string]ctrlruntimeclient.Client{
+				"outdated": fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(&imagev1.ImageStreamTag{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "name:tag"},
+					Image:      imagev1.Image{ObjectMeta: metav1.ObjectMeta{Name: "sha256:old"}},
+				}).WithInterceptorFuncs(importSucceeds).Build(),
+				"missing": fakectrlruntimeclient.NewClientBuilder().WithInterceptorFuncs(importSucceeds).Build(),
+			},
+			expectedSucceeded: true,
+			expectedImports:   map[string]bool{"outdated": true, "missing": true},
+		},
+		{
+			name: "failure on one registry does not prevent the import into the other",
+			clients: map[string]ctrlruntimeclient.Client{
+				"broken":  fakectrlruntimeclient.NewClientBuilder().WithInterceptorFuncs(importFails).Build(),
+				"missing": fakectrlruntimeclient.NewClientBuilder().WithInterceptorFuncs(importSucceeds).Build(),
+			},
+			expectedImports: map[string]bool{"broken": false, "missing": true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r := &reconciler{
+				log:                      logrus.NewEntry(logrus.New()),
+				registryDomain:           "registry.ci.openshift.org",
+				secondaryRegistryClients: tc.clients,
+			}
+			if succeeded := r.mirrorToSecondaryRegistries(context.Background(), ist, r.log); succeeded != tc.expectedSucceeded {
+				t.Errorf("expected succeeded to be %t, was %t", tc.expectedSucceeded, succeeded)
+			}
+			for cluster, client := range tc.clients {
+				var isi imagev1.ImageStreamImport
+				err := client.Get(context.Background(), types.NamespacedName{Namespace: "namespace", Name: "name"}, &isi)
+				if imported := err == nil; imported != tc.expectedImports[cluster] {
+					t.Errorf("%s: expected import: %t, got import: %t", cluster, tc.expectedImports[cluster], imported)
+				}
+				if err == nil {
+					if from := isi.Spec.Images[0].From.Name; from != "registry.ci.openshift.org/namespace/name@sha256:current" {
+						t.Errorf("%s: unexpected import source %s", cluster, from)
+					}
+				}
+			}
+		})
+	}
+}
+
 func TestHandleCIOpConfigChange(t *testing.T) {
 	var queue []prowjobreconciler.OrgRepoBranchCommit
 	testCases := []struct {
@@ -541,3 +627,41 @@ func TestIgnored(t *testing.T) {
 		})
 	}
 }
+
+func TestSinceFor(t *testing.T) {
+	r := &reconciler{
+		since: 360 * time.Hour,
+		sinceOverrides: []SinceOverride{
+			{ImageStreams: regexp.MustCompile(`^ocp/4\.1[0-9]:.+`), Since: 720 * time.Hour},
+			{ImageStreams: regexp.MustCompile(`^ocp/.+`), Since: 24 * time.Hour},
+		},
+	}
+	testCases := []struct {
+		name     string
+		request  reconcile.Request
+		expected time.Duration
+	}{
+		{
+			name:     "no override matches, the global since is used",
+			request:  reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ci", Name: "tools:latest"}},
+			expected: 360 * time.Hour,
+		},
+		{
+			name:     "first matching override is used",
+			request:  reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ocp", Name: "4.15:cli"}},
+			expected: 720 * time.Hour,
+		},
+		{
+			name:     "later override matches",
+			request:  reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ocp", Name: "4.9:cli"}},
+			expected: 24 * time.Hour,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := r.sinceFor(tc.request); actual != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
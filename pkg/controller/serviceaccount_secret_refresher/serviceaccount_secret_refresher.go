@@ -18,6 +18,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	controllerutil "github.com/openshift/ci-tools/pkg/controller/util"
 )
 
 const (
@@ -26,7 +28,10 @@ const (
 	TTLAnnotationKey = "serviaccount-secret-rotator.openshift.io/delete-after"
 )
 
-func AddToManager(clusterName string, mgr manager.Manager, enabledNamespaces, ignoreServiceAccounts sets.Set[string], removeOldSecrets bool) error {
+// AddToManager adds the serviceaccount_secret_refresher to the given manager. Pull secrets
+// older than maxSecretAge are removed from their ServiceAccount so they get rotated and, if
+// removeOldSecrets is set, are deleted once they are older than twice the maxSecretAge.
+func AddToManager(clusterName string, mgr manager.Manager, enabledNamespaces, ignoreServiceAccounts sets.Set[string], removeOldSecrets bool, maxSecretAge time.Duration, drainer *controllerutil.Drainer) error {
 	r := &reconciler{
 		client: mgr.GetClient(),
 		filter: func(r reconcile.Request) bool {
@@ -35,9 +40,10 @@ func AddToManager(clusterName string, mgr manager.Manager, enabledNamespaces, ig
 		log:              logrus.WithField("controller", ControllerName).WithField("cluster", clusterName),
 		second:           time.Second,
 		removeOldSecrets: removeOldSecrets,
+		maxSecretAge:     maxSecretAge,
 	}
 	c, err := controller.New(fmt.Sprintf("%s_%s", ControllerName, clusterName), mgr, controller.Options{
-		Reconciler: r,
+		Reconciler: drainer.Wrap(r),
 		// When > 1, there will be IsConflict errors on updating the same ServiceAccount
 		MaxConcurrentReconciles: 20,
 	})
@@ -71,6 +77,7 @@ type reconciler struct {
 	// Allow speeding up time for tests
 	second           time.Duration
 	removeOldSecrets bool
+	maxSecretAge     time.Duration
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
@@ -88,8 +95,6 @@ func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	return *res, err
 }
 
-const thirtyDays = 30 * 24 * time.Hour
-
 func (r *reconciler) reconcile(ctx context.Context, l *logrus.Entry, req reconcile.Request) (*reconcile.Result, error) {
 	if !r.filter(req) {
 		return nil, nil
@@ -111,7 +116,7 @@ func (r *reconciler) reconcile(ctx context.Context, l *logrus.Entry, req reconci
 		if err != nil {
 			return nil, fmt.Errorf("failed to check image pull secret creationTimestamp: %w", err)
 		}
-		if deleteObjectIn := objectExpiredIn(l, secret, thirtyDays); deleteObjectIn != 0 {
+		if deleteObjectIn := objectExpiredIn(l, secret, r.maxSecretAge); deleteObjectIn != 0 {
 			l.WithField("secret", secret.Name).Infof("DeleteObjectIn: %s", deleteObjectIn.String())
 			imagePullSecretsToKeep = append(imagePullSecretsToKeep, pullSecretRef)
 			if requeueAfter == 0 || deleteObjectIn < requeueAfter {
@@ -134,7 +139,7 @@ func (r *reconciler) reconcile(ctx context.Context, l *logrus.Entry, req reconci
 			tokenSecretsToKeep = append(tokenSecretsToKeep, tokenSecretRef)
 			continue
 		}
-		if deleteObjectIn := objectExpiredIn(l, secret, thirtyDays); deleteObjectIn != 0 {
+		if deleteObjectIn := objectExpiredIn(l, secret, r.maxSecretAge); deleteObjectIn != 0 {
 			tokenSecretsToKeep = append(tokenSecretsToKeep, tokenSecretRef)
 			if requeueAfter == 0 || deleteObjectIn < requeueAfter {
 				requeueAfter = deleteObjectIn
@@ -178,14 +183,14 @@ func (r *reconciler) reconcile(ctx context.Context, l *logrus.Entry, req reconci
 		if secret.Annotations[corev1.ServiceAccountUIDKey] != string(sa.UID) {
 			continue
 		}
-		if deleteObjectIn := objectExpiredIn(l, &secret, 2*thirtyDays); deleteObjectIn != 0 {
+		if deleteObjectIn := objectExpiredIn(l, &secret, 2*r.maxSecretAge); deleteObjectIn != 0 {
 			if requeueAfter == 0 || deleteObjectIn < requeueAfter {
 				requeueAfter = deleteObjectIn
 			}
 			continue
 		}
 
-		l.WithField("name", secret.Name).WithField("age", time.Since(secret.CreationTimestamp.Time).String()).Info("Deleting secret that is older than 30 days")
+		l.WithField("name", secret.Name).WithField("age", time.Since(secret.CreationTimestamp.Time).String()).WithField("max_age", (2 * r.maxSecretAge).String()).Info("Deleting secret that exceeded its maximum age")
 		// ignore ErrNotExist as there could be a race condition where something else has already deleted the secret.
 		if err := r.client.Delete(ctx, &secret); err != nil && !apierrors.IsNotFound(err) {
 			return nil, fmt.Errorf("failed to delete secret %s/%s: %w", secret.Namespace, secret.Name, err)
@@ -30,6 +30,7 @@ func TestReconcile(t *testing.T) {
 		name                       string
 		objects                    []runtime.Object
 		removeOldSecrets           bool
+		maxSecretAge               time.Duration
 		filter                     func(reconcile.Request) bool
 		expectedRequeAfterHours    int
 		expectedNumImagePullSecret uint
@@ -138,6 +139,66 @@ func TestReconcile(t *testing.T) {
 			expectedPullSecretName:     "new-pull-secret",
 			expectedTokenSecretName:    "token-secret",
 		},
+		{
+			name: "pull secret just under the max age is kept",
+			objects: []runtime.Object{
+				sa.DeepCopy(),
+				secretForSA(sa, corev1.SecretTypeDockercfg, func(s *corev1.Secret) {
+					s.Name = "pull-secret"
+					s.CreationTimestamp = metav1.NewTime(time.Now().Add(-10*24*time.Hour + time.Hour))
+				}),
+				secretForSA(sa, corev1.SecretTypeServiceAccountToken, func(s *corev1.Secret) {
+					s.Name = "token-secret"
+					s.CreationTimestamp = metav1.Now()
+				}),
+			},
+			removeOldSecrets:           true,
+			maxSecretAge:               10 * 24 * time.Hour,
+			expectedNumImagePullSecret: 1,
+			expectedNumTokenSecret:     1,
+			expectedPullSecretName:     "pull-secret",
+			expectedTokenSecretName:    "token-secret",
+		},
+		{
+			name: "pull secret just over the max age is rotated but not yet deleted",
+			objects: []runtime.Object{
+				sa.DeepCopy(),
+				secretForSA(sa, corev1.SecretTypeDockercfg, func(s *corev1.Secret) {
+					s.Name = "pull-secret"
+					s.CreationTimestamp = metav1.NewTime(time.Now().Add(-20*24*time.Hour + time.Hour))
+				}),
+				secretForSA(sa, corev1.SecretTypeServiceAccountToken, func(s *corev1.Secret) {
+					s.Name = "token-secret"
+					s.CreationTimestamp = metav1.Now()
+				}),
+			},
+			removeOldSecrets:           true,
+			maxSecretAge:               10 * 24 * time.Hour,
+			expectedNumImagePullSecret: 2,
+			expectedNumTokenSecret:     1,
+			expectedPullSecretName:     "new-pull-secret",
+			expectedTokenSecretName:    "token-secret",
+		},
+		{
+			name: "pull secret just over twice the max age is rotated and deleted",
+			objects: []runtime.Object{
+				sa.DeepCopy(),
+				secretForSA(sa, corev1.SecretTypeDockercfg, func(s *corev1.Secret) {
+					s.Name = "pull-secret"
+					s.CreationTimestamp = metav1.NewTime(time.Now().Add(-20*24*time.Hour - time.Hour))
+				}),
+				secretForSA(sa, corev1.SecretTypeServiceAccountToken, func(s *corev1.Secret) {
+					s.Name = "token-secret"
+					s.CreationTimestamp = metav1.Now()
+				}),
+			},
+			removeOldSecrets:           true,
+			maxSecretAge:               10 * 24 * time.Hour,
+			expectedNumImagePullSecret: 1,
+			expectedNumTokenSecret:     1,
+			expectedPullSecretName:     "new-pull-secret",
+			expectedTokenSecretName:    "token-secret",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -147,6 +208,9 @@ func TestReconcile(t *testing.T) {
 			if tc.filter == nil {
 				tc.filter = func(_ reconcile.Request) bool { return true }
 			}
+			if tc.maxSecretAge == 0 {
+				tc.maxSecretAge = 30 * 24 * time.Hour
+			}
 			client := &serviceaccountSecretRecreatingClient{t: t, Client: fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(tc.objects...).Build()}
 
 			r := &reconciler{
@@ -155,6 +219,7 @@ func TestReconcile(t *testing.T) {
 				log:              logrus.WithField("test", tc.name),
 				second:           10 * time.Millisecond,
 				removeOldSecrets: tc.removeOldSecrets,
+				maxSecretAge:     tc.maxSecretAge,
 			}
 
 			ctx := context.Background()
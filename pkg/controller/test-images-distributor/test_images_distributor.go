@@ -2,10 +2,16 @@ package testimagesdistributor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
 
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -35,6 +41,17 @@ import (
 
 const ControllerName = "test_images_distributor"
 
+// workersFor returns the number of concurrent reconciles. Without a bound on the
+// in-flight imports, there is a single worker so imports are not flooded. Requests
+// for imagestreamtags of the same imagestream would conflict, so they are serialized
+// by the reconciler, which allows for as many workers as imports may be in flight.
+func workersFor(maxConcurrentSyncs int64) int {
+	if maxConcurrentSyncs > 0 {
+		return int(maxConcurrentSyncs)
+	}
+	return 1
+}
+
 func AddToManager(mgr manager.Manager,
 	registryClusterName string,
 	registryManager manager.Manager,
@@ -44,8 +61,12 @@ func AddToManager(mgr manager.Manager,
 	additionalImageStreamTags sets.Set[string],
 	additionalImageStreams sets.Set[string],
 	additionalImageStreamNamespaces sets.Set[string],
+	ignoreImageStreamTags []*regexp.Regexp,
 	forbiddenRegistries sets.Set[string],
 	ignoreClusterNames sets.Set[string],
+	maxConcurrentSyncs int64,
+	syncQPS float64,
+	drainer *controllerutil.Drainer,
 ) error {
 	log := logrus.WithField("controller", ControllerName)
 
@@ -56,13 +77,15 @@ func AddToManager(mgr manager.Manager,
 		buildClusterClients: map[string]ctrlruntimeclient.Client{},
 		forbiddenRegistries: forbiddenRegistries,
 	}
+	if maxConcurrentSyncs > 0 {
+		r.syncSemaphore = semaphore.NewWeighted(maxConcurrentSyncs)
+	}
+	if syncQPS > 0 {
+		r.syncLimiter = rate.NewLimiter(rate.Limit(syncQPS), 1)
+	}
 	c, err := controller.New(ControllerName, mgr, controller.Options{
-		Reconciler: r,
-		// We conflict on ImageStream level which means multiple request for imagestreamtags
-		// of the same imagestream will conflict so stay at one worker in order to reduce the
-		// number of errors we see. If we hit performance issues, we will probably need cluster
-		// and/or imagestream level locking.
-		MaxConcurrentReconciles: 1,
+		Reconciler:              drainer.Wrap(r),
+		MaxConcurrentReconciles: workersFor(maxConcurrentSyncs),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to construct controller: %w", err)
@@ -90,7 +113,7 @@ func AddToManager(mgr manager.Manager,
 		if err := c.Watch(
 			source.Kind(buildClusterManager.GetCache(),
 				&testimagestreamtagimportv1.TestImageStreamTagImport{},
-				testImageStreamTagImportHandlerForNamedCluster(buildClusterName)),
+				testImageStreamTagImportHandlerForNamedCluster(buildClusterName, ignoreImageStreamTags)),
 		); err != nil {
 			return fmt.Errorf("failed to watch testimagestreamtagimports in cluster %s: %w", buildClusterName, err)
 		}
@@ -100,7 +123,7 @@ func AddToManager(mgr manager.Manager,
 	if err := c.Watch(
 		source.Kind(mgr.GetCache(),
 			&testimagestreamtagimportv1.TestImageStreamTagImport{},
-			testImageStreamTagImportHandler(log, ignoreClusterNames)),
+			testImageStreamTagImportHandler(log, ignoreClusterNames, ignoreImageStreamTags)),
 	); err != nil {
 		return fmt.Errorf("failed to create watch for testimagestreamtagimports: %w", err)
 	}
@@ -114,7 +137,7 @@ func AddToManager(mgr manager.Manager,
 		appCIClient = imagestreamtagwrapper.MustNew(mgr.GetClient(), mgr.GetCache())
 	}
 
-	objectFilter, err := testInputImageStreamTagFilterFactory(log, configAgent, appCIClient, resolver, additionalImageStreamTags, additionalImageStreams, additionalImageStreamNamespaces, r.buildClusterClients)
+	objectFilter, err := testInputImageStreamTagFilterFactory(log, configAgent, appCIClient, resolver, additionalImageStreamTags, additionalImageStreams, additionalImageStreamNamespaces, ignoreImageStreamTags, r.buildClusterClients)
 	if err != nil {
 		return fmt.Errorf("failed to get filter for ImageStreamTags: %w", err)
 	}
@@ -130,7 +153,7 @@ func AddToManager(mgr manager.Manager,
 	if err != nil {
 		return fmt.Errorf("failed to subscribe to index changes for index %s: %w", indexName, err)
 	}
-	if err := c.Watch(sourceForConfigChangeChannel(buildClusters, appCIClient, configChangeChannel)); err != nil {
+	if err := c.Watch(sourceForConfigChangeChannel(buildClusters, appCIClient, configChangeChannel, ignoreImageStreamTags)); err != nil {
 		return fmt.Errorf("failed to subscribe for config change changes: %w", err)
 	}
 
@@ -138,7 +161,7 @@ func AddToManager(mgr manager.Manager,
 	return nil
 }
 
-func sourceForConfigChangeChannel(buildClusterNames sets.Set[string], registryClient ctrlruntimeclient.Client, changes <-chan agents.IndexDelta) source.Source {
+func sourceForConfigChangeChannel(buildClusterNames sets.Set[string], registryClient ctrlruntimeclient.Client, changes <-chan agents.IndexDelta, ignoreImageStreamTags []*regexp.Regexp) source.Source {
 	sourceChannel := make(chan event.TypedGenericEvent[*testimagestreamtagimportv1.TestImageStreamTagImport])
 	hndler := handler.TypedEnqueueRequestsFromMapFunc(func(ctx context.Context, tisti *testimagestreamtagimportv1.TestImageStreamTagImport) []reconcile.Request {
 		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: tisti.Namespace, Name: tisti.Name}}}
@@ -181,6 +204,10 @@ func sourceForConfigChangeChannel(buildClusterNames sets.Set[string], registryCl
 			}
 			for _, buildClusterName := range sets.List(buildClusterNames) {
 				for _, result := range result {
+					if isImageStreamTagIgnored(result, ignoreImageStreamTags) {
+						logrus.WithField("name", result.String()).Debug("Ignored imagestreamtag for config change")
+						continue
+					}
 					sourceChannel <- event.TypedGenericEvent[*testimagestreamtagimportv1.TestImageStreamTagImport]{Object: &testimagestreamtagimportv1.TestImageStreamTagImport{ObjectMeta: metav1.ObjectMeta{
 						Namespace: buildClusterName + clusterAndNamespaceDelimiter + result.Namespace,
 						Name:      result.Name,
@@ -193,8 +220,11 @@ func sourceForConfigChangeChannel(buildClusterNames sets.Set[string], registryCl
 	return channelSource
 }
 
-func testImageStreamTagImportHandlerForNamedCluster(clusterName string) handler.TypedEventHandler[*testimagestreamtagimportv1.TestImageStreamTagImport] {
+func testImageStreamTagImportHandlerForNamedCluster(clusterName string, ignoreImageStreamTags []*regexp.Regexp) handler.TypedEventHandler[*testimagestreamtagimportv1.TestImageStreamTagImport] {
 	return handler.TypedEnqueueRequestsFromMapFunc[*testimagestreamtagimportv1.TestImageStreamTagImport](func(ctx context.Context, testimagestreamtagimport *testimagestreamtagimportv1.TestImageStreamTagImport) []reconcile.Request {
+		if isImageStreamTagIgnored(types.NamespacedName{Namespace: testimagestreamtagimport.Spec.Namespace, Name: testimagestreamtagimport.Spec.Name}, ignoreImageStreamTags) {
+			return nil
+		}
 		return []reconcile.Request{{NamespacedName: types.NamespacedName{
 			Namespace: clusterName + clusterAndNamespaceDelimiter + testimagestreamtagimport.Spec.Namespace,
 			Name:      testimagestreamtagimport.Spec.Name,
@@ -202,7 +232,7 @@ func testImageStreamTagImportHandlerForNamedCluster(clusterName string) handler.
 	})
 }
 
-func testImageStreamTagImportHandler(l *logrus.Entry, ignoreClusterNames sets.Set[string]) handler.TypedEventHandler[*testimagestreamtagimportv1.TestImageStreamTagImport] {
+func testImageStreamTagImportHandler(l *logrus.Entry, ignoreClusterNames sets.Set[string], ignoreImageStreamTags []*regexp.Regexp) handler.TypedEventHandler[*testimagestreamtagimportv1.TestImageStreamTagImport] {
 	return handler.TypedEnqueueRequestsFromMapFunc[*testimagestreamtagimportv1.TestImageStreamTagImport](func(ctx context.Context, testimagestreamtagimport *testimagestreamtagimportv1.TestImageStreamTagImport) []reconcile.Request {
 		if testimagestreamtagimport.Spec.ClusterName == "" {
 			// This should never happen
@@ -212,6 +242,9 @@ func testImageStreamTagImportHandler(l *logrus.Entry, ignoreClusterNames sets.Se
 		if ignoreClusterNames.Has(testimagestreamtagimport.Spec.ClusterName) {
 			return nil
 		}
+		if isImageStreamTagIgnored(types.NamespacedName{Namespace: testimagestreamtagimport.Spec.Namespace, Name: testimagestreamtagimport.Spec.Name}, ignoreImageStreamTags) {
+			return nil
+		}
 		return []reconcile.Request{{NamespacedName: types.NamespacedName{
 			Namespace: testimagestreamtagimport.Spec.ClusterName + clusterAndNamespaceDelimiter + testimagestreamtagimport.Spec.Namespace,
 			Name:      testimagestreamtagimport.Spec.Name,
@@ -264,11 +297,83 @@ type reconciler struct {
 	registryClient      ctrlruntimeclient.Client
 	buildClusterClients map[string]ctrlruntimeclient.Client
 	forbiddenRegistries sets.Set[string]
+	// syncSemaphore bounds the number of in-flight imports, it is unbounded if nil
+	syncSemaphore *semaphore.Weighted
+	// syncLimiter bounds the rate of imports, it is unlimited if nil
+	syncLimiter *rate.Limiter
+	// imageStreams serializes the reconciliation of the tags of an imagestream on a cluster
+	imageStreams imageStreamLocks
+}
+
+// imageStreamLocks holds the imagestreams that are being reconciled, by cluster, namespace and name
+type imageStreamLocks struct {
+	lock   sync.Mutex
+	locked sets.Set[string]
+}
+
+// tryLock never blocks. It returns false if the imagestream is already locked,
+// otherwise the caller has to call the returned func once it is done.
+func (l *imageStreamLocks) tryLock(cluster string, imageStream types.NamespacedName) (func(), bool) {
+	key := cluster + "/" + imageStream.String()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.locked == nil {
+		l.locked = sets.New[string]()
+	}
+	if l.locked.Has(key) {
+		return nil, false
+	}
+	l.locked.Insert(key)
+	return func() {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		l.locked.Delete(key)
+	}, true
+}
+
+// syncRetryInterval is the time after which an import that was deferred because
+// too many imports were in flight or its imagestream was busy is retried
+const syncRetryInterval = time.Second
+
+// syncDeferredError is returned when an import was deferred by the limits on imports
+type syncDeferredError struct {
+	after time.Duration
+}
+
+func (e *syncDeferredError) Error() string {
+	return fmt.Sprintf("import deferred for %s", e.after)
+}
+
+// acquireSync never blocks on the concurrency or the rate limit. If the import may
+// not happen right away, it returns a syncDeferredError so the request gets retried.
+// Otherwise the caller has to call the returned func once the import is done.
+func (r *reconciler) acquireSync() (func(), error) {
+	release := func() {}
+	if r.syncSemaphore != nil {
+		if !r.syncSemaphore.TryAcquire(1) {
+			return nil, &syncDeferredError{after: syncRetryInterval}
+		}
+		release = func() { r.syncSemaphore.Release(1) }
+	}
+	if r.syncLimiter != nil {
+		reservation := r.syncLimiter.Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			reservation.Cancel()
+			release()
+			return nil, &syncDeferredError{after: delay}
+		}
+	}
+	return release, nil
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
 	log := r.log.WithField("request", req.String())
 	err := r.reconcile(ctx, req, log)
+	var deferred *syncDeferredError
+	if errors.As(err, &deferred) {
+		log.WithField("after", deferred.after).Debug("Import deferred")
+		return reconcile.Result{RequeueAfter: deferred.after}, nil
+	}
 	if err != nil && !apierrors.IsConflict(err) {
 		log.WithError(err).Error("Reconciliation failed")
 	} else {
@@ -308,6 +413,11 @@ func (r *reconciler) reconcile(ctx context.Context, req reconcile.Request, log *
 	}
 	imageStreamName, imageTag := imageStreamNameAndTag[0], imageStreamNameAndTag[1]
 	isName := types.NamespacedName{Namespace: decoded.Namespace, Name: imageStreamName}
+	unlock, ok := r.imageStreams.tryLock(cluster, isName)
+	if !ok {
+		return &syncDeferredError{after: syncRetryInterval}
+	}
+	defer unlock()
 	sourceImageStream := &imagev1.ImageStream{}
 	if err := r.registryClient.Get(ctx, isName, sourceImageStream); err != nil {
 		return fmt.Errorf("failed to get imageStream %s from registry cluster: %w", isName.String(), err)
@@ -358,6 +468,11 @@ func (r *reconciler) reconcile(ctx context.Context, req reconcile.Request, log *
 		log.WithField("isCurrent", isCurrent).Debug("ImageStreamTag is skipped")
 		return nil
 	}
+	release, err := r.acquireSync()
+	if err != nil {
+		return err
+	}
+	defer release()
 	if err := controllerutil.EnsureImagePullSecret(ctx, decoded.Namespace, client, log); err != nil {
 		return fmt.Errorf("failed to ensure imagePullSecret on cluster %s: %w", cluster, err)
 	}
@@ -515,14 +630,20 @@ func testInputImageStreamTagFilterFactory(
 	additionalImageStreamTags,
 	additionalImageStreams,
 	additionalImageStreamNamespaces sets.Set[string],
+	ignoreImageStreamTags []*regexp.Regexp,
 	buildClusterClients map[string]ctrlruntimeclient.Client,
 ) (objectFilter, error) {
 	if err := ca.AddIndex(indexName, indexConfigsByTestInputImageStreamTag(resolver)); err != nil {
 		return nil, fmt.Errorf("failed to add %s index to configAgent: %w", indexName, err)
 	}
-	l = logrus.WithField("subcomponent", "test-input-image-stream-tag-filter")
+	l = l.WithField("subcomponent", "test-input-image-stream-tag-filter")
 	buildClusterClients["app.ci"] = client
 	return func(nn types.NamespacedName) bool {
+		// Ignored imagestreamtags are never distributed, not even when they are referenced
+		if isImageStreamTagIgnored(nn, ignoreImageStreamTags) {
+			l.WithField("name", nn.String()).Info("Not distributing ignored imagestreamtag")
+			return false
+		}
 		if additionalImageStreamTags.Has(nn.String()) {
 			return true
 		}
@@ -579,6 +700,16 @@ func testInputImageStreamTagFilterFactory(
 	}, nil
 }
 
+// isImageStreamTagIgnored determines whether the imagestreamtag in namespace/name:tag format matches any of the patterns
+func isImageStreamTagIgnored(nn types.NamespacedName, ignoreImageStreamTags []*regexp.Regexp) bool {
+	for _, re := range ignoreImageStreamTags {
+		if re.MatchString(nn.String()) {
+			return true
+		}
+	}
+	return false
+}
+
 func imageStreamNameFromImageStreamTagName(nn types.NamespacedName) (types.NamespacedName, error) {
 	colonSplit := strings.Split(nn.Name, ":")
 	if n := len(colonSplit); n != 2 {
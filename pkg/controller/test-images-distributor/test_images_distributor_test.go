@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"sync"
 	"testing"
@@ -12,6 +13,9 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
 
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -24,6 +28,7 @@ import (
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	imagev1 "github.com/openshift/api/image/v1"
@@ -378,6 +383,8 @@ func TestReconcile(t *testing.T) {
 		request             types.NamespacedName
 		registryClient      ctrlruntimeclient.Client
 		buildClusterClients map[string]ctrlruntimeclient.Client
+		syncSemaphore       *semaphore.Weighted
+		syncLimiter         *rate.Limiter
 		verify              func(ctrlruntimeclient.Client, map[string]ctrlruntimeclient.Client, error) error
 	}{
 		{
@@ -567,6 +574,40 @@ func TestReconcile(t *testing.T) {
 				return verifyEverythingCreated(bc["01"])
 			},
 		},
+		{
+			name: "Outdated imageStreamtag, import is deferred when too many imports are in flight",
+			request: types.NamespacedName{
+				Namespace: "01_" + referenceImageStreamTag.Namespace,
+				Name:      referenceImageStreamTag.Name,
+			},
+			registryClient: fakeclient.NewClientBuilder().WithRuntimeObjects(referenceImageStream.DeepCopy(), referenceImageStreamTag.DeepCopy()).Build(),
+			buildClusterClients: map[string]ctrlruntimeclient.Client{"01": bcc(fakeclient.NewClientBuilder().WithRuntimeObjects(
+				secret.DeepCopy(),
+				outdatedImageStreamTag(),
+				expectedNamespace.DeepCopy(),
+				expectedPullSecret.DeepCopy(),
+				expectedImageStream.DeepCopy(),
+			).Build())},
+			syncSemaphore: exhaustedSemaphore(),
+			verify:        verifyDeferred(syncRetryInterval),
+		},
+		{
+			name: "Outdated imageStreamtag, import is deferred when the rate limit is exceeded",
+			request: types.NamespacedName{
+				Namespace: "01_" + referenceImageStreamTag.Namespace,
+				Name:      referenceImageStreamTag.Name,
+			},
+			registryClient: fakeclient.NewClientBuilder().WithRuntimeObjects(referenceImageStream.DeepCopy(), referenceImageStreamTag.DeepCopy()).Build(),
+			buildClusterClients: map[string]ctrlruntimeclient.Client{"01": bcc(fakeclient.NewClientBuilder().WithRuntimeObjects(
+				secret.DeepCopy(),
+				outdatedImageStreamTag(),
+				expectedNamespace.DeepCopy(),
+				expectedPullSecret.DeepCopy(),
+				expectedImageStream.DeepCopy(),
+			).Build())},
+			syncLimiter: exhaustedLimiter(),
+			verify:      verifyDeferred(0),
+		},
 		{
 			name: "Outdated imageStreamtag, import is created, failure is returned",
 			request: types.NamespacedName{
@@ -608,6 +649,8 @@ func TestReconcile(t *testing.T) {
 					"registry.build01.ci.openshift.org",
 					"registry.build02.ci.openshift.org",
 				),
+				syncSemaphore: tc.syncSemaphore,
+				syncLimiter:   tc.syncLimiter,
 			}
 
 			request := reconcile.Request{NamespacedName: tc.request}
@@ -615,10 +658,145 @@ func TestReconcile(t *testing.T) {
 			if err := tc.verify(r.registryClient, r.buildClusterClients, err); err != nil {
 				t.Errorf("verification failed: %v", err)
 			}
+
+			// Deferred imports must be retried rather than dropped
+			var deferred *syncDeferredError
+			if errors.As(err, &deferred) {
+				result, err := r.Reconcile(context.Background(), request)
+				if err != nil {
+					t.Errorf("deferred import yielded an error: %v", err)
+				}
+				if result.RequeueAfter <= 0 {
+					t.Errorf("expected deferred import to be requeued, got %v", result)
+				}
+			}
 		})
 	}
 }
 
+func TestReconcileConcurrently(t *testing.T) {
+	imageStreamTag := func(name string) *imagev1.ImageStreamTag {
+		return &imagev1.ImageStreamTag{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+			Image: imagev1.Image{
+				ObjectMeta:           metav1.ObjectMeta{Name: "sha256:a273f5ac7f1ad8f7ffab45205ac36c8dff92d9107ef3ae429eeb135fa8057b8b"},
+				DockerImageReference: "registry.svc.ci.openshift.org/ocp/4.4@sha256:a273f5ac7f1ad8f7ffab45205ac36c8dff92d9107ef3ae429eeb135fa8057b8b",
+			},
+		}
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ci", Name: "registry-pull-credentials"},
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte("abc")},
+		Type:       corev1.SecretTypeDockerConfigJson,
+	}
+
+	// imports block until released, so reconciles overlap
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	buildClusterClient := func() ctrlruntimeclient.Client {
+		return bcc(&blockingImportClient{
+			Client:  fakeclient.NewClientBuilder().WithRuntimeObjects(secret.DeepCopy()).Build(),
+			started: started,
+			release: release,
+		})
+	}
+	r := &reconciler{
+		log:                 logrus.NewEntry(logrus.StandardLogger()),
+		registryClusterName: "app.ci",
+		registryClient: fakeclient.NewClientBuilder().WithRuntimeObjects(
+			&imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "4.2"}},
+			imageStreamTag("4.2:Question"),
+			imageStreamTag("4.2:Answer"),
+		).Build(),
+		buildClusterClients: map[string]ctrlruntimeclient.Client{
+			"01": buildClusterClient(),
+			"02": buildClusterClient(),
+			"03": buildClusterClient(),
+		},
+		syncSemaphore: semaphore.NewWeighted(2),
+	}
+	reconcile := func(cluster, tag string) error {
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: cluster + "_ns", Name: tag}}
+		return r.reconcile(context.Background(), request, logrus.NewEntry(logrus.StandardLogger()))
+	}
+	expectDeferred := func(reason string, err error) {
+		t.Helper()
+		var deferred *syncDeferredError
+		if !errors.As(err, &deferred) {
+			t.Errorf("expected the import to be deferred because %s, got error %v", reason, err)
+		}
+	}
+
+	inFlight := make(chan error, 2)
+	go func() { inFlight <- reconcile("01", "4.2:Question") }()
+	<-started
+	expectDeferred("the imagestream is being reconciled", reconcile("01", "4.2:Answer"))
+
+	go func() { inFlight <- reconcile("02", "4.2:Question") }()
+	<-started
+	expectDeferred("too many imports are in flight", reconcile("03", "4.2:Question"))
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-inFlight; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if err := reconcile("01", "4.2:Answer"); err != nil {
+		t.Errorf("unexpected error once the imagestream was released: %v", err)
+	}
+	if err := reconcile("03", "4.2:Question"); err != nil {
+		t.Errorf("unexpected error once the imports were done: %v", err)
+	}
+}
+
+// blockingImportClient blocks imports until release is closed, after signaling on started.
+// Like on a real cluster, imports are not persisted, so an imagestream can be imported again.
+type blockingImportClient struct {
+	ctrlruntimeclient.Client
+	started chan<- struct{}
+	release <-chan struct{}
+}
+
+func (client *blockingImportClient) Create(ctx context.Context, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
+	if _, match := obj.(*imagev1.ImageStreamImport); match {
+		client.started <- struct{}{}
+		<-client.release
+		return nil
+	}
+	return client.Client.Create(ctx, obj, opts...)
+}
+
+func exhaustedSemaphore() *semaphore.Weighted {
+	s := semaphore.NewWeighted(1)
+	s.TryAcquire(1)
+	return s
+}
+
+func exhaustedLimiter() *rate.Limiter {
+	l := rate.NewLimiter(rate.Every(time.Hour), 1)
+	l.Allow()
+	return l
+}
+
+// verifyDeferred verifies the import was deferred for at least the given duration and not created
+func verifyDeferred(atLeast time.Duration) func(ctrlruntimeclient.Client, map[string]ctrlruntimeclient.Client, error) error {
+	return func(_ ctrlruntimeclient.Client, bc map[string]ctrlruntimeclient.Client, err error) error {
+		var deferred *syncDeferredError
+		if !errors.As(err, &deferred) {
+			return fmt.Errorf("expected the import to be deferred, got error %v", err)
+		}
+		if deferred.after <= 0 || deferred.after < atLeast {
+			return fmt.Errorf("expected the import to be deferred for at least %s, was %s", atLeast, deferred.after)
+		}
+		name := types.NamespacedName{Namespace: "ns", Name: "4.2"}
+		if err := bc["01"].Get(context.Background(), name, &imagev1.ImageStreamImport{}); !apierrors.IsNotFound(err) {
+			return fmt.Errorf("expected no import to be created, got error %v", err)
+		}
+		return nil
+	}
+}
+
 func bcc(upstream ctrlruntimeclient.Client, opts ...func(*imageImportStatusSettingClient)) ctrlruntimeclient.Client {
 	c := &imageImportStatusSettingClient{
 		Client: upstream,
@@ -649,6 +827,34 @@ func (client *imageImportStatusSettingClient) Create(ctx context.Context, obj ct
 // indexConfigsByTestInputImageStreamTag must be an agents.IndexFn
 var _ agents.IndexFn = indexConfigsByTestInputImageStreamTag(nil)
 
+func TestWorkersFor(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name               string
+		maxConcurrentSyncs int64
+		expected           int
+	}{
+		{
+			name:     "unbounded imports keep a single worker",
+			expected: 1,
+		},
+		{
+			name:               "bounded imports get a worker each",
+			maxConcurrentSyncs: 5,
+			expected:           5,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if actual := workersFor(tc.maxConcurrentSyncs); actual != tc.expected {
+				t.Errorf("expected %d workers, got %d", tc.expected, actual)
+			}
+		})
+	}
+}
+
 func TestTestImageStramTagImportHandlerRoundTrips(t *testing.T) {
 	t.Parallel()
 	const cluster, namespace, name = "cluster", "namespace", "name"
@@ -662,7 +868,7 @@ func TestTestImageStramTagImportHandlerRoundTrips(t *testing.T) {
 	queue := &hijackingQueue{}
 
 	event := event.TypedCreateEvent[*testimagestreamtagimportv1.TestImageStreamTagImport]{Object: obj}
-	testImageStreamTagImportHandler(logrus.NewEntry(logrus.StandardLogger()), sets.New[string]()).Create(context.Background(), event, queue)
+	testImageStreamTagImportHandler(logrus.NewEntry(logrus.StandardLogger()), sets.New[string](), nil).Create(context.Background(), event, queue)
 
 	if n := len(queue.received); n != 1 {
 		t.Fatalf("expected exactly one reconcile request, got %d(%v)", n, queue.received)
@@ -683,6 +889,58 @@ func TestTestImageStramTagImportHandlerRoundTrips(t *testing.T) {
 	}
 }
 
+func TestTestImageStreamTagImportHandlersIgnoreImageStreamTags(t *testing.T) {
+	t.Parallel()
+	ignoreImageStreamTags := []*regexp.Regexp{regexp.MustCompile(".*:.*-source")}
+	testCases := []struct {
+		name     string
+		handler  handler.TypedEventHandler[*testimagestreamtagimportv1.TestImageStreamTagImport]
+		tag      string
+		expected int
+	}{
+		{
+			name:     "app.ci handler, tag is not ignored",
+			handler:  testImageStreamTagImportHandler(logrus.NewEntry(logrus.StandardLogger()), sets.New[string](), ignoreImageStreamTags),
+			tag:      "name:tag",
+			expected: 1,
+		},
+		{
+			name:    "app.ci handler, tag is ignored",
+			handler: testImageStreamTagImportHandler(logrus.NewEntry(logrus.StandardLogger()), sets.New[string](), ignoreImageStreamTags),
+			tag:     "name:tag-source",
+		},
+		{
+			name:     "named cluster handler, tag is not ignored",
+			handler:  testImageStreamTagImportHandlerForNamedCluster("cluster", ignoreImageStreamTags),
+			tag:      "name:tag",
+			expected: 1,
+		},
+		{
+			name:    "named cluster handler, tag is ignored",
+			handler: testImageStreamTagImportHandlerForNamedCluster("cluster", ignoreImageStreamTags),
+			tag:     "name:tag-source",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			obj := &testimagestreamtagimportv1.TestImageStreamTagImport{
+				Spec: testimagestreamtagimportv1.TestImageStreamTagImportSpec{
+					ClusterName: "cluster",
+					Namespace:   "namespace",
+					Name:        tc.tag,
+				},
+			}
+			queue := &hijackingQueue{}
+			tc.handler.Create(context.Background(), event.TypedCreateEvent[*testimagestreamtagimportv1.TestImageStreamTagImport]{Object: obj}, queue)
+			if n := len(queue.received); n != tc.expected {
+				t.Errorf("expected %d reconcile requests, got %d(%v)", tc.expected, n, queue.received)
+			}
+		})
+	}
+}
+
 func TestTestInputImageStreamTagFilterFactory(t *testing.T) {
 	t.Parallel()
 	const namespace, streamName, tagName = "namespace", "streamName", "streamTag"
@@ -694,7 +952,9 @@ func TestTestInputImageStreamTagFilterFactory(t *testing.T) {
 		additionalImageStreamTags       sets.Set[string]
 		additionalImageStreams          sets.Set[string]
 		additionalImageStreamNamespaces sets.Set[string]
+		ignoreImageStreamTags           []*regexp.Regexp
 		expectedResult                  bool
+		expectedLog                     string
 	}{
 		{
 			name:                      "imagestreamtag is explicitly allowed",
@@ -792,6 +1052,27 @@ func TestTestInputImageStreamTagFilterFactory(t *testing.T) {
 		{
 			name: "no reference, imagestreatag gets denied",
 		},
+		{
+			name: "imagestreamtag is referenced by config but ignored",
+			config: api.ReleaseBuildConfiguration{
+				RawSteps: []api.StepConfiguration{
+					{
+						InputImageTagStepConfiguration: &api.InputImageTagStepConfiguration{
+							InputImage: api.InputImage{
+								BaseImage: api.ImageStreamTagReference{Namespace: namespace, Name: streamName, Tag: tagName}},
+						},
+					},
+				},
+			},
+			ignoreImageStreamTags: []*regexp.Regexp{regexp.MustCompile(".*:stream.*")},
+			expectedLog:           "Not distributing ignored imagestreamtag",
+		},
+		{
+			name:                      "imagestreamtag is explicitly allowed and not ignored",
+			additionalImageStreamTags: sets.New[string](namespace + "/" + streamName + ":" + tagName),
+			ignoreImageStreamTags:     []*regexp.Regexp{regexp.MustCompile(".*:.*-source")},
+			expectedResult:            true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -803,14 +1084,16 @@ func TestTestInputImageStreamTagFilterFactory(t *testing.T) {
 				tc.buildClusterClients = map[string]ctrlruntimeclient.Client{}
 			}
 			configAgent := agents.NewFakeConfigAgent(map[string]map[string][]api.ReleaseBuildConfiguration{"": {"": []api.ReleaseBuildConfiguration{tc.config}}})
+			logger, hook := logrustest.NewNullLogger()
 			filter, err := testInputImageStreamTagFilterFactory(
-				logrus.NewEntry(logrus.New()),
+				logrus.NewEntry(logger),
 				configAgent,
 				tc.client,
 				noOpRegistryResolver{},
 				tc.additionalImageStreamTags,
 				tc.additionalImageStreams,
 				tc.additionalImageStreamNamespaces,
+				tc.ignoreImageStreamTags,
 				tc.buildClusterClients,
 			)
 			if err != nil {
@@ -819,6 +1102,13 @@ func TestTestInputImageStreamTagFilterFactory(t *testing.T) {
 			if result := filter(types.NamespacedName{Namespace: namespace, Name: streamName + ":" + tagName}); result != tc.expectedResult {
 				t.Errorf("expected result %t, got result %t", tc.expectedResult, result)
 			}
+			var log string
+			if entry := hook.LastEntry(); entry != nil {
+				log = entry.Message
+			}
+			if log != tc.expectedLog {
+				t.Errorf("expected log %q, got %q", tc.expectedLog, log)
+			}
 		})
 	}
 }
@@ -885,7 +1175,7 @@ func TestSourceForConfigChangeChannel(t *testing.T) {
 
 			changeChannel := make(chan agents.IndexDelta)
 
-			source := sourceForConfigChangeChannel(buildClusters, tc.client, changeChannel)
+			source := sourceForConfigChangeChannel(buildClusters, tc.client, changeChannel, nil)
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 			queue := &hijackingQueue{}
@@ -6,25 +6,37 @@ import (
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	testimagestreamtagimportv1 "github.com/openshift/ci-tools/pkg/api/testimagestreamtagimport/v1"
+	controllerutil "github.com/openshift/ci-tools/pkg/controller/util"
 )
 
 const ControllerName = "testimagestreamimportcleaner"
 
+// AddToManager adds a controller for every cluster that deletes old testimagestreamtagimports.
+// When namespaces is not empty, only imports in those namespaces are reconciled. Imports in
+// ignoredNamespaces are never reconciled.
 func AddToManager(
 	mgr manager.Manager,
 	allManagers map[string]manager.Manager,
+	namespaces sets.Set[string],
+	ignoredNamespaces sets.Set[string],
+	drainer *controllerutil.Drainer,
 ) error {
+	predicates := predicate.NewTypedPredicateFuncs(func(o *testimagestreamtagimportv1.TestImageStreamTagImport) bool {
+		return namespaceEnabled(o.Namespace, namespaces, ignoredNamespaces)
+	})
 	for clusterName, clusterManager := range allManagers {
 		c, err := controller.New(ControllerName+"_"+clusterName, mgr, controller.Options{
-			Reconciler:              &reconciler{client: clusterManager.GetClient(), now: time.Now},
+			Reconciler:              drainer.Wrap(&reconciler{client: clusterManager.GetClient(), now: time.Now}),
 			MaxConcurrentReconciles: 10,
 		})
 		if err != nil {
@@ -32,7 +44,8 @@ func AddToManager(
 		}
 		if err := c.Watch(source.Kind(clusterManager.GetCache(),
 			&testimagestreamtagimportv1.TestImageStreamTagImport{},
-			&handler.TypedEnqueueRequestForObject[*testimagestreamtagimportv1.TestImageStreamTagImport]{})); err != nil {
+			&handler.TypedEnqueueRequestForObject[*testimagestreamtagimportv1.TestImageStreamTagImport]{},
+			predicates)); err != nil {
 			return fmt.Errorf("failed to watch testimagestreamtagimports in cluster %s: %w", clusterName, err)
 		}
 	}
@@ -40,6 +53,13 @@ func AddToManager(
 	return nil
 }
 
+func namespaceEnabled(namespace string, namespaces, ignoredNamespaces sets.Set[string]) bool {
+	if ignoredNamespaces.Has(namespace) {
+		return false
+	}
+	return namespaces.Len() == 0 || namespaces.Has(namespace)
+}
+
 type reconciler struct {
 	client ctrlruntimeclient.Client
 	now    func() time.Time
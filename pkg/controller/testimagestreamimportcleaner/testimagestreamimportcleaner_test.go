@@ -9,6 +9,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -82,3 +83,51 @@ func TestReconcile(t *testing.T) {
 		})
 	}
 }
+
+func TestNamespaceEnabled(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name              string
+		namespace         string
+		namespaces        sets.Set[string]
+		ignoredNamespaces sets.Set[string]
+		expected          bool
+	}{
+		{
+			name:      "no allowlist and no denylist enables every namespace",
+			namespace: "ci-op-1234",
+			expected:  true,
+		},
+		{
+			name:       "namespace on the allowlist is enabled",
+			namespace:  "ci-op-1234",
+			namespaces: sets.New[string]("ci-op-1234"),
+			expected:   true,
+		},
+		{
+			name:       "namespace not on the allowlist is disabled",
+			namespace:  "ci-op-5678",
+			namespaces: sets.New[string]("ci-op-1234"),
+		},
+		{
+			name:              "namespace on the denylist is disabled",
+			namespace:         "ci",
+			ignoredNamespaces: sets.New[string]("ci"),
+		},
+		{
+			name:              "denylist wins over allowlist",
+			namespace:         "ci",
+			namespaces:        sets.New[string]("ci"),
+			ignoredNamespaces: sets.New[string]("ci"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := namespaceEnabled(tc.namespace, tc.namespaces, tc.ignoredNamespaces); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
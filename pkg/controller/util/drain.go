@@ -0,0 +1,56 @@
+package util
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Drainer tracks in-flight reconciles, so a process that is about to exit can
+// let them finish rather than aborting them halfway. Once Drain was called, no
+// new reconciles are started.
+type Drainer struct {
+	lock     sync.Mutex
+	draining bool
+	inFlight sync.WaitGroup
+}
+
+// Wrap returns a reconciler whose reconciles are tracked by the Drainer. After
+// Drain was called it drops all requests, they will be picked up again by the
+// initial listing of the process that replaces this one.
+func (d *Drainer) Wrap(upstream reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		d.lock.Lock()
+		if d.draining {
+			d.lock.Unlock()
+			return reconcile.Result{}, nil
+		}
+		d.inFlight.Add(1)
+		d.lock.Unlock()
+		defer d.inFlight.Done()
+
+		return upstream.Reconcile(ctx, req)
+	})
+}
+
+// Drain stops new reconciles from being started and waits up to gracePeriod
+// for the in-flight ones to finish. It returns whether they all finished.
+func (d *Drainer) Drain(gracePeriod time.Duration) bool {
+	d.lock.Lock()
+	d.draining = true
+	d.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.inFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(gracePeriod):
+		return false
+	}
+}
@@ -0,0 +1,46 @@
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestDrainer(t *testing.T) {
+	drainer := &Drainer{}
+	started, release := make(chan struct{}), make(chan struct{})
+	var calls int
+	reconciler := drainer.Wrap(reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		calls++
+		started <- struct{}{}
+		<-release
+		return reconcile.Result{}, nil
+	}))
+
+	finished := make(chan struct{})
+	go func() {
+		if _, err := reconciler.Reconcile(context.Background(), reconcile.Request{}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		close(finished)
+	}()
+	<-started
+
+	if drainer.Drain(10 * time.Millisecond) {
+		t.Error("expected drain to time out while a reconcile is in flight")
+	}
+	if _, err := reconciler.Reconcile(context.Background(), reconcile.Request{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected no reconcile to be started while draining, got %d calls", calls)
+	}
+
+	close(release)
+	<-finished
+	if !drainer.Drain(time.Second) {
+		t.Error("expected drain to succeed after the in-flight reconcile finished")
+	}
+}
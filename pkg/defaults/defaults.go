@@ -82,6 +82,8 @@ func FromConfig(
 	localRegistryDNS string,
 	integratedStreams map[string]*configresolver.IntegratedStream,
 	injectedTest bool,
+	prebuiltImagesNamespace string,
+	clusterProfiles api.ClusterProfilesMap,
 ) ([]api.Step, []api.Step, error) {
 	crclient, err := ctrlruntimeclient.NewWithWatch(clusterConfig, ctrlruntimeclient.Options{})
 	crclient = secretrecordingclient.Wrap(crclient, censor)
@@ -119,7 +121,7 @@ func FromConfig(
 	httpClient := retryablehttp.NewClient()
 	httpClient.Logger = nil
 
-	return fromConfig(ctx, config, graphConf, jobSpec, templates, paramFile, promote, client, buildClient, templateClient, podClient, leaseClient, hiveClient, httpClient.StandardClient(), requiredTargets, cloneAuthConfig, pullSecret, pushSecret, api.NewDeferredParameters(nil), censor, consoleHost, nodeName, targetAdditionalSuffix, nodeArchitectures, integratedStreams, injectedTest)
+	return fromConfig(ctx, config, graphConf, jobSpec, templates, paramFile, promote, client, buildClient, templateClient, podClient, leaseClient, hiveClient, httpClient.StandardClient(), requiredTargets, cloneAuthConfig, pullSecret, pushSecret, api.NewDeferredParameters(nil), censor, consoleHost, nodeName, targetAdditionalSuffix, nodeArchitectures, integratedStreams, injectedTest, prebuiltImagesNamespace, clusterProfiles)
 }
 
 func fromConfig(
@@ -148,6 +150,8 @@ func fromConfig(
 	nodeArchitectures []string,
 	integratedStreams map[string]*configresolver.IntegratedStream,
 	injectedTest bool,
+	prebuiltImagesNamespace string,
+	clusterProfiles api.ClusterProfilesMap,
 ) ([]api.Step, []api.Step, error) {
 	requiredNames := sets.New[string]()
 	for _, target := range requiredTargets {
@@ -174,7 +178,7 @@ func fromConfig(
 
 	for _, rawStep := range rawSteps {
 		if testStep := rawStep.TestStepConfiguration; testStep != nil {
-			steps, err := stepForTest(config, params, podClient, leaseClient, templateClient, client, hiveClient, jobSpec, inputImages, testStep, &imageConfigs, pullSecret, censor, nodeName, targetAdditionalSuffix)
+			steps, err := stepForTest(config, params, podClient, leaseClient, templateClient, client, hiveClient, jobSpec, inputImages, testStep, &imageConfigs, pullSecret, censor, nodeName, targetAdditionalSuffix, clusterProfiles)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -306,6 +310,11 @@ func fromConfig(
 				addProvidesForStep(releaseStep, params)
 			}
 		}
+		if prebuiltImagesNamespace != "" {
+			if to, ok := builtPipelineImage(rawStep); ok {
+				step = steps.PrebuiltImageStep(step, to, prebuiltImagesNamespace, client, jobSpec)
+			}
+		}
 		step, ok := checkForFullyQualifiedStep(step, params)
 		if ok {
 			logrus.Infof("Task %s is satisfied by environment variables and will be skipped", step.Name())
@@ -380,6 +389,21 @@ func fromConfig(
 	return append(overridableSteps, buildSteps...), promotionSteps, nil
 }
 
+// builtPipelineImage returns the pipeline image the step builds, if it builds one
+func builtPipelineImage(rawStep api.StepConfiguration) (api.PipelineImageStreamTagReference, bool) {
+	switch {
+	case rawStep.SourceStepConfiguration != nil:
+		return rawStep.SourceStepConfiguration.To, true
+	case rawStep.PipelineImageCacheStepConfiguration != nil:
+		return rawStep.PipelineImageCacheStepConfiguration.To, true
+	case rawStep.ProjectDirectoryImageBuildStepConfiguration != nil:
+		return rawStep.ProjectDirectoryImageBuildStepConfiguration.To, true
+	case rawStep.RPMImageInjectionStepConfiguration != nil:
+		return rawStep.RPMImageInjectionStepConfiguration.To, true
+	}
+	return "", false
+}
+
 func stepsForImageOverrides(ctx context.Context, overriddenImages map[string]string, consoleHost string, client ctrlruntimeclient.Client, second time.Duration) []api.StepConfiguration {
 	var overrideSteps []api.StepConfiguration
 	for tag, value := range overriddenImages {
@@ -442,15 +466,16 @@ func stepForTest(
 	censor *secrets.DynamicCensor,
 	nodeName string,
 	targetAdditionalSuffix string,
+	clusterProfiles api.ClusterProfilesMap,
 ) ([]api.Step, error) {
 	if test := c.MultiStageTestConfigurationLiteral; test != nil {
-		leases := api.LeasesForTest(test)
-		ipPoolLease := api.IPPoolLeaseForTest(test, config.Metadata)
+		leases := api.LeasesForTest(test, clusterProfiles)
+		ipPoolLease := api.IPPoolLeaseForTest(test, config.Metadata, clusterProfiles)
 		if len(leases) != 0 || ipPoolLease.ResourceType != "" {
 			params = api.NewDeferredParameters(params)
 		}
 		var ret []api.Step
-		step := multi_stage.MultiStageTestStep(*c, config, params, podClient, jobSpec, leases, nodeName, targetAdditionalSuffix, nil)
+		step := multi_stage.MultiStageTestStep(*c, config, params, podClient, jobSpec, leases, clusterProfiles, nodeName, targetAdditionalSuffix, nil)
 		if ipPoolLease.ResourceType != "" {
 			step = steps.IPPoolStep(leaseClient, podClient, ipPoolLease, step, params, jobSpec.Namespace)
 		}
@@ -479,7 +504,7 @@ func stepForTest(
 			return nil, fmt.Errorf("unable to create end to end test step: %w", err)
 		}
 		step = steps.LeaseStep(leaseClient, []api.StepLease{{
-			ResourceType: test.ClusterProfile.LeaseType(),
+			ResourceType: clusterProfiles.Base(test.ClusterProfile).LeaseType(),
 			Env:          api.DefaultLeaseEnv,
 			Count:        1,
 		}}, step, jobSpec.Namespace)
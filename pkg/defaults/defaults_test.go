@@ -1336,6 +1336,7 @@ func TestFromConfig(t *testing.T) {
 		params              map[string]string
 		overriddenImagesEnv map[string]string
 		injectedTest        bool
+		prebuiltImages      string
 		expectedSteps       []string
 		expectedPost        []string
 		expectedParams      map[string]string
@@ -1405,6 +1406,23 @@ func TestFromConfig(t *testing.T) {
 		expectedParams: map[string]string{
 			"LOCAL_IMAGE_TO": "public_docker_image_repository:to",
 		},
+	}, {
+		name: "image build from pre-built images",
+		config: api.ReleaseBuildConfiguration{
+			Images: []api.ProjectDirectoryImageBuildStepConfiguration{
+				{From: "from", To: "to"},
+			},
+		},
+		prebuiltImages: "prebuilt",
+		expectedSteps: []string{
+			"to",
+			"[output:stable:to]",
+			"[output-images]",
+			"[images]",
+		},
+		expectedParams: map[string]string{
+			"LOCAL_IMAGE_TO": "public_docker_image_repository:to",
+		},
 	}, {
 		name: "build root",
 		config: api.ReleaseBuildConfiguration{
@@ -1833,7 +1851,7 @@ func TestFromConfig(t *testing.T) {
 				params.Add(k, func() (string, error) { return v, nil })
 			}
 			graphConf := FromConfigStatic(&tc.config)
-			configSteps, post, err := fromConfig(context.Background(), &tc.config, &graphConf, &jobSpec, tc.templates, tc.paramFiles, tc.promote, client, buildClient, templateClient, podClient, leaseClient, hiveClient, httpClient, requiredTargets, cloneAuthConfig, pullSecret, pushSecret, params, &secrets.DynamicCensor{}, api.ServiceDomainAPPCI, "", "", nil, map[string]*configresolver.IntegratedStream{}, tc.injectedTest)
+			configSteps, post, err := fromConfig(context.Background(), &tc.config, &graphConf, &jobSpec, tc.templates, tc.paramFiles, tc.promote, client, buildClient, templateClient, podClient, leaseClient, hiveClient, httpClient, requiredTargets, cloneAuthConfig, pullSecret, pushSecret, params, &secrets.DynamicCensor{}, api.ServiceDomainAPPCI, "", "", nil, map[string]*configresolver.IntegratedStream{}, tc.injectedTest, tc.prebuiltImages, nil)
 			if diff := cmp.Diff(tc.expectedErr, err); diff != "" {
 				t.Errorf("unexpected error: %v", diff)
 			}
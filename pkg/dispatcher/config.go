@@ -23,6 +23,9 @@ type ClusterInfo struct {
 	Provider     string
 	Capacity     int
 	Capabilities []string
+	// BlockedCapabilities are capabilities for which no jobs will be
+	// dispatched to the cluster, while jobs without them still can be.
+	BlockedCapabilities []string
 }
 
 // ClusterMap maps a cluster name to its corresponding ClusterInfo.
@@ -49,6 +52,11 @@ type Config struct {
 	BuildFarm map[api.Cloud]map[api.Cluster]*BuildFarmConfig `json:"buildFarm,omitempty"`
 	// BuildFarmCloud maps sets of clusters to a cloud provider, like GCP
 	BuildFarmCloud map[api.Cloud][]string `json:"-"`
+	// ExcludePatterns is a list of regexes of the file paths that are excluded from the dispatch.
+	// The jobs in the matching files keep their current assignment and do not count towards the volume of the clusters.
+	ExcludePatterns []string `json:"excludePatterns,omitempty"`
+
+	ExcludePatternREs []*regexp.Regexp `json:"-"`
 }
 
 type BuildFarmConfig struct {
@@ -118,6 +126,13 @@ func DetermineCloud(jobBase prowconfig.JobBase) string {
 	return ""
 }
 
+// RequiredCapabilities returns the sorted capabilities a job requires from its cluster
+func RequiredCapabilities(jobBase prowconfig.JobBase) []string {
+	capabilities := extractRequiredCapabilities(jobBase.Labels)
+	sort.Strings(capabilities)
+	return capabilities
+}
+
 func extractRequiredCapabilities(labels map[string]string) []string {
 	var capabilities []string
 	for key, value := range labels {
@@ -142,11 +157,25 @@ func matchesAllCapabilities(clusterCapabilities, requiredCapabilities []string)
 	return true
 }
 
+func blocksAnyCapability(blockedCapabilities, requiredCapabilities []string) bool {
+	for _, blocked := range blockedCapabilities {
+		for _, required := range requiredCapabilities {
+			if blocked == required {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // DetermineClusterForJob return the cluster for a prow job and if it can be relocated to a cluster in build farm
 func (config *Config) DetermineClusterForJob(jobBase prowconfig.JobBase, path string, cm ClusterMap) (clusterName api.Cluster, mayBeRelocated bool, _ error) {
 	if jobBase.Agent != "kubernetes" && jobBase.Agent != "" {
 		return "", false, nil
 	}
+	if cluster := PinnedCluster(jobBase); cluster != "" {
+		return api.Cluster(cluster), false, nil
+	}
 	if strings.Contains(jobBase.Name, "vsphere") && !isApplyConfigJob(jobBase) {
 		return api.ClusterVSphere02, false, nil
 	}
@@ -161,7 +190,7 @@ func (config *Config) DetermineClusterForJob(jobBase prowconfig.JobBase, path st
 			matchingClustersByProvider := map[string][]string{}
 
 			for clusterName, clusterInfo := range cm {
-				if matchesAllCapabilities(clusterInfo.Capabilities, requiredCapabilities) {
+				if matchesAllCapabilities(clusterInfo.Capabilities, requiredCapabilities) && !blocksAnyCapability(clusterInfo.BlockedCapabilities, requiredCapabilities) {
 					matchingClusters = append(matchingClusters, clusterName)
 					provider := clusterInfo.Provider
 					matchingClustersByProvider[provider] = append(matchingClustersByProvider[provider], clusterName)
@@ -302,6 +331,28 @@ func (config *Config) MatchingPathRegEx(path string) bool {
 	return false
 }
 
+// MatchingExcludePattern returns true if the given path matches one of the config's exclude patterns
+func (config *Config) MatchingExcludePattern(path string) bool {
+	for _, re := range config.ExcludePatternREs {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildFarmClusterForFile returns the build farm cluster the given file is assigned to; empty string otherwise.
+func (config *Config) BuildFarmClusterForFile(filename string) api.Cluster {
+	for _, v := range config.BuildFarm {
+		for cluster, filenames := range v {
+			if filenames.Filenames.Has(filename) {
+				return cluster
+			}
+		}
+	}
+	return ""
+}
+
 // LoadConfig loads config from a file
 func LoadConfig(configPath string) (*Config, error) {
 	config := &Config{}
@@ -329,6 +380,15 @@ func LoadConfig(configPath string) (*Config, error) {
 		config.Groups[cluster] = group
 	}
 
+	for i, p := range config.ExcludePatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to compile regex config.ExcludePatterns[%d] from %q: %w", i, p, err))
+			continue
+		}
+		config.ExcludePatternREs = append(config.ExcludePatternREs, re)
+	}
+
 	for cloudProvider := range config.BuildFarm {
 		if config.BuildFarmCloud == nil {
 			config.BuildFarmCloud = map[api.Cloud][]string{}
@@ -231,6 +231,10 @@ func TestLoadConfig(t *testing.T) {
 			name:          "invalid regex",
 			expectedError: utilerrors.NewAggregate([]error{fmt.Errorf("[failed to compile regex config.Groups[default].Paths[0] from \"[\": error parsing regexp: missing closing ]: `[`, failed to compile regex config.Groups[default].Paths[1] from \"[0-9]++\": error parsing regexp: invalid nested repetition operator: `++`]")}),
 		},
+		{
+			name:          "invalid exclude pattern",
+			expectedError: utilerrors.NewAggregate([]error{fmt.Errorf("failed to compile regex config.ExcludePatterns[1] from \"[\": error parsing regexp: missing closing ]: `[`")}),
+		},
 		{
 			name:     "good config",
 			expected: &c,
@@ -459,6 +463,15 @@ func TestDetermineClusterForJob(t *testing.T) {
 			expected:               "build02",
 			expectedCanBeRelocated: false,
 		},
+		{
+			name:   "a job pinned to a cluster",
+			config: &configWithBuildFarmWithJobs,
+			jobBase: config.JobBase{Agent: "kubernetes", Name: "yalayala-vsphere",
+				Labels: map[string]string{api.PinClusterLabel: "build03", "devices.kubevirt.io/kvm": "1"},
+			},
+			expected:               "build03",
+			expectedCanBeRelocated: false,
+		},
 		{
 			name:   "a job with cluster label",
 			config: &configWithBuildFarmWithJobs,
@@ -550,6 +563,19 @@ func TestDetermineClusterForJob(t *testing.T) {
 			expectedCanBeRelocated: false,
 			expectedErr:            fmt.Errorf("job some-e2e-job can't be matched with any cluster using provided capabilities: arm64,vpn"),
 		},
+		{
+			name:   "cluster blocking a required capability is not matched",
+			config: &configWithBuildFarmWithJobsAndDetermineE2EByJob,
+			jobBase: config.JobBase{Agent: "kubernetes", Name: "some-e2e-job",
+				Labels: map[string]string{
+					"capability/gpu":                 "gpu",
+					"ci-operator.openshift.io/cloud": "gcp"},
+			},
+			cm: ClusterMap{"build03": ClusterInfo{Provider: "aws", Capacity: 100, Capabilities: []string{"gpu"}},
+				"build02": ClusterInfo{Provider: "gcp", Capacity: 100, Capabilities: []string{"gpu"}, BlockedCapabilities: []string{"gpu"}}},
+			expected:               "build03",
+			expectedCanBeRelocated: false,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -626,6 +652,40 @@ func TestMatchingPathRegEx(t *testing.T) {
 	}
 }
 
+func TestMatchingExcludePattern(t *testing.T) {
+	config := &Config{ExcludePatternREs: []*regexp.Regexp{regexp.MustCompile(".*-periodics.yaml$")}}
+	testCases := []struct {
+		name     string
+		config   *Config
+		path     string
+		expected bool
+	}{
+		{
+			name:     "matching: true",
+			config:   config,
+			path:     "./ci-operator/jobs/openshift/ci-tools/openshift-ci-tools-master-periodics.yaml",
+			expected: true,
+		},
+		{
+			name:   "matching: false",
+			config: config,
+			path:   "./ci-operator/jobs/openshift/ci-tools/openshift-ci-tools-master-presubmits.yaml",
+		},
+		{
+			name:   "no exclude patterns",
+			config: &c,
+			path:   "./ci-operator/jobs/openshift/ci-tools/openshift-ci-tools-master-periodics.yaml",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.config.MatchingExcludePattern(tc.path); tc.expected != actual {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
 func TestIsSSHBastionJob(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -7,19 +7,24 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	prowconfig "sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/api"
 )
 
-func loadClusterConfigFromBytes(data []byte) (ClusterMap, sets.Set[string], error) {
+func loadClusterConfigFromBytes(data []byte) (ClusterMap, sets.Set[string], sets.Set[string], error) {
 	var clusters map[string][]struct {
-		Name         string   `yaml:"name"`
-		Capacity     int      `yaml:"capacity"`
-		Capabilities []string `yaml:"capabilities"`
-		Blocked      bool     `yaml:"blocked"`
+		Name                string   `yaml:"name"`
+		Capacity            int      `yaml:"capacity"`
+		Capabilities        []string `yaml:"capabilities"`
+		BlockedCapabilities []string `yaml:"blockedCapabilities"`
+		Blocked             bool     `yaml:"blocked"`
+		Drained             bool     `yaml:"drained"`
 	}
 	if err := yaml.Unmarshal(data, &clusters); err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	blockedClusters := sets.New[string]()
+	drainedClusters := sets.New[string]()
 	clusterMap := make(ClusterMap)
 
 	for provider, clusterList := range clusters {
@@ -33,22 +38,27 @@ func loadClusterConfigFromBytes(data []byte) (ClusterMap, sets.Set[string], erro
 				blockedClusters.Insert(cluster.Name)
 				continue
 			}
+			if cluster.Drained {
+				drainedClusters.Insert(cluster.Name)
+			}
 			clusterMap[cluster.Name] = ClusterInfo{
-				Provider:     provider,
-				Capacity:     cluster.Capacity,
-				Capabilities: cluster.Capabilities,
+				Provider:            provider,
+				Capacity:            cluster.Capacity,
+				Capabilities:        cluster.Capabilities,
+				BlockedCapabilities: cluster.BlockedCapabilities,
 			}
 		}
 	}
 
-	return clusterMap, blockedClusters, nil
+	return clusterMap, blockedClusters, drainedClusters, nil
 }
 
-// LoadClusterConfig loads cluster configuration from a YAML file, returning a ClusterMap and a set of blocked clusters.
-func LoadClusterConfig(filePath string) (ClusterMap, sets.Set[string], error) {
+// LoadClusterConfig loads cluster configuration from a YAML file, returning a ClusterMap, a set of blocked clusters
+// and a set of drained clusters. Drained clusters keep the jobs already assigned to them, but get no new ones.
+func LoadClusterConfig(filePath string) (ClusterMap, sets.Set[string], sets.Set[string], error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	return loadClusterConfigFromBytes(data)
 
@@ -56,19 +66,25 @@ func LoadClusterConfig(filePath string) (ClusterMap, sets.Set[string], error) {
 
 func FindMostUsedCluster(jc *prowconfig.JobConfig) string {
 	clusters := make(map[string]int)
+	count := func(jobBase prowconfig.JobBase) {
+		// pinned jobs do not take part in the rebalancing
+		if PinnedCluster(jobBase) == "" {
+			clusters[jobBase.Cluster]++
+		}
+	}
 	for k := range jc.PresubmitsStatic {
 		for _, job := range jc.PresubmitsStatic[k] {
-			clusters[job.Cluster]++
+			count(job.JobBase)
 		}
 	}
 
 	for k := range jc.PostsubmitsStatic {
 		for _, job := range jc.PostsubmitsStatic[k] {
-			clusters[job.Cluster]++
+			count(job.JobBase)
 		}
 	}
 	for _, job := range jc.Periodics {
-		clusters[job.Cluster]++
+		count(job.JobBase)
 	}
 	cluster := ""
 	value := 0
@@ -81,6 +97,28 @@ func FindMostUsedCluster(jc *prowconfig.JobConfig) string {
 	return cluster
 }
 
+// PinnedCluster returns the cluster the job is pinned to via the PinClusterLabel, if any
+func PinnedCluster(jobBase prowconfig.JobBase) string {
+	return jobBase.Labels[api.PinClusterLabel]
+}
+
+// BlockedClustersForJob returns the clusters the job must not be dispatched to:
+// the clusters blocked entirely and the clusters blocking any of the
+// capabilities the job requires.
+func BlockedClustersForJob(jobBase prowconfig.JobBase, blocked sets.Set[string], cm ClusterMap) sets.Set[string] {
+	requiredCapabilities := extractRequiredCapabilities(jobBase.Labels)
+	if len(requiredCapabilities) == 0 {
+		return blocked
+	}
+	ret := blocked.Clone()
+	for name, info := range cm {
+		if blocksAnyCapability(info.BlockedCapabilities, requiredCapabilities) {
+			ret.Insert(name)
+		}
+	}
+	return ret
+}
+
 func DetermineTargetCluster(cluster, determinedCluster, defaultCluster string, canBeRelocated bool, blocked sets.Set[string]) string {
 	if cluster == "" {
 		cluster = determinedCluster
@@ -112,6 +150,9 @@ func HasCapacityOrCapabilitiesChanged(prev, next ClusterMap) bool {
 		if !reflect.DeepEqual(info1.Capabilities, info2.Capabilities) {
 			return true
 		}
+		if !reflect.DeepEqual(info1.BlockedCapabilities, info2.BlockedCapabilities) {
+			return true
+		}
 	}
 
 	return false
@@ -3,8 +3,12 @@ package dispatcher
 import (
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"k8s.io/apimachinery/pkg/util/sets"
 	prowconfig "sigs.k8s.io/prow/pkg/config"
+
+	"github.com/openshift/ci-tools/pkg/api"
 )
 
 const build01 = "build01"
@@ -77,6 +81,21 @@ func TestFindMostUsedCluster(t *testing.T) {
 			},
 			expected: build01,
 		},
+		{
+			name: "pinned jobs are not counted",
+			jobConfig: prowconfig.JobConfig{
+				PresubmitsStatic: map[string][]prowconfig.Presubmit{
+					"repo1": {
+						{JobBase: prowconfig.JobBase{Cluster: build01, Labels: map[string]string{api.PinClusterLabel: build01}}},
+						{JobBase: prowconfig.JobBase{Cluster: build01, Labels: map[string]string{api.PinClusterLabel: build01}}},
+					},
+				},
+				Periodics: []prowconfig.Periodic{
+					{JobBase: prowconfig.JobBase{Cluster: build02}},
+				},
+			},
+			expected: build02,
+		},
 	}
 
 	for _, tt := range tests {
@@ -180,12 +199,53 @@ func TestDetermineTargetCluster(t *testing.T) {
 	}
 }
 
+func TestBlockedClustersForJob(t *testing.T) {
+	cm := ClusterMap{
+		"build01": {Provider: "aws", Capabilities: []string{"gpu"}},
+		"build02": {Provider: "gcp", Capabilities: []string{"gpu", "vpn"}, BlockedCapabilities: []string{"gpu"}},
+		"build03": {Provider: "aws", Capabilities: []string{"vpn"}, BlockedCapabilities: []string{"vpn"}},
+	}
+	blocked := sets.New[string]("build04")
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		expected sets.Set[string]
+	}{
+		{
+			name:     "job without capabilities is only blocked from fully blocked clusters",
+			expected: sets.New[string]("build04"),
+		},
+		{
+			name:     "job with a capability is blocked from clusters blocking it",
+			labels:   map[string]string{"capability/gpu": "gpu"},
+			expected: sets.New[string]("build02", "build04"),
+		},
+		{
+			name:     "job with multiple capabilities is blocked from clusters blocking any of them",
+			labels:   map[string]string{"capability/gpu": "gpu", "capability/vpn": "vpn"},
+			expected: sets.New[string]("build02", "build03", "build04"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := BlockedClustersForJob(prowconfig.JobBase{Labels: tt.labels}, blocked, cm)
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Errorf("unexpected blocked clusters: %s", diff)
+			}
+			if !blocked.Equal(sets.New[string]("build04")) {
+				t.Errorf("input set was modified: %v", blocked.UnsortedList())
+			}
+		})
+	}
+}
+
 func TestLoadClusterConfigFromBytes(t *testing.T) {
 	tests := []struct {
 		name            string
 		yamlData        string
 		expectedCluster ClusterMap
 		expectedBlocked sets.Set[string]
+		expectedDrained sets.Set[string]
 	}{
 		{
 			name: "Valid config with AWS and GCP",
@@ -198,6 +258,7 @@ aws:
       - amd64
       - vpn
   - name: build03
+    drained: true
   - name: build09
     blocked: true
   - name: build99
@@ -207,6 +268,9 @@ gcp:
     capacity: 60
     capabilities:
       - vpn
+      - gpu
+    blockedCapabilities:
+      - gpu
 `,
 			expectedCluster: ClusterMap{
 				"build01": {
@@ -220,12 +284,14 @@ gcp:
 					Capabilities: nil,
 				},
 				"build02": {
-					Provider:     "gcp",
-					Capacity:     60,
-					Capabilities: []string{"vpn"},
+					Provider:            "gcp",
+					Capacity:            60,
+					Capabilities:        []string{"vpn", "gpu"},
+					BlockedCapabilities: []string{"gpu"},
 				},
 			},
 			expectedBlocked: sets.New[string]("build09", "build99"),
+			expectedDrained: sets.New[string]("build03"),
 		},
 		{
 			name: "Config with missing capacities and capabilities",
@@ -239,6 +305,7 @@ gcp:
       - vpn
   - name: build03
     blocked: true
+    drained: true #blocking wins
 `,
 			expectedCluster: ClusterMap{
 				"build01": {
@@ -253,6 +320,7 @@ gcp:
 				},
 			},
 			expectedBlocked: sets.New[string]("build03"),
+			expectedDrained: sets.New[string](),
 		},
 		{
 			name: "Empty config",
@@ -262,6 +330,7 @@ gcp: []
 `,
 			expectedCluster: ClusterMap{},
 			expectedBlocked: sets.New[string](),
+			expectedDrained: sets.New[string](),
 		},
 	}
 
@@ -269,7 +338,7 @@ gcp: []
 		t.Run(tt.name, func(t *testing.T) {
 			data := []byte(tt.yamlData)
 
-			clusterMap, blockedClusters, err := loadClusterConfigFromBytes(data)
+			clusterMap, blockedClusters, drainedClusters, err := loadClusterConfigFromBytes(data)
 			if err != nil {
 				t.Fatalf("Failed to load cluster config: %v", err)
 			}
@@ -292,11 +361,17 @@ gcp: []
 							t.Errorf("Expected capability %d for %s: %s, got: %s", i, clusterName, expectedInfo.Capabilities[i], capability)
 						}
 					}
+					if diff := cmp.Diff(expectedInfo.BlockedCapabilities, info.BlockedCapabilities); diff != "" {
+						t.Errorf("Unexpected blocked capabilities for %s: %s", clusterName, diff)
+					}
 				}
 			}
 			if !blockedClusters.Equal(tt.expectedBlocked) {
 				t.Errorf("Expected blocked clusters: %v, got: %v", tt.expectedBlocked.UnsortedList(), blockedClusters.UnsortedList())
 			}
+			if !drainedClusters.Equal(tt.expectedDrained) {
+				t.Errorf("Expected drained clusters: %v, got: %v", tt.expectedDrained.UnsortedList(), drainedClusters.UnsortedList())
+			}
 		})
 	}
 }
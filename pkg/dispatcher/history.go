@@ -0,0 +1,110 @@
+package dispatcher
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AssignmentChange is a change of the cluster a job is assigned to. From is empty for
+// jobs that were not assigned before and To is empty for jobs that are no longer assigned.
+type AssignmentChange struct {
+	Job  string `json:"job"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// HistoryEntry holds the assignment changes of a single dispatch
+type HistoryEntry struct {
+	Time    time.Time          `json:"time"`
+	Changes []AssignmentChange `json:"changes"`
+}
+
+// History keeps the most recent assignment changes, persisted in Gob format
+type History struct {
+	mu         sync.Mutex
+	entries    []HistoryEntry
+	path       string
+	maxEntries int
+}
+
+// NewHistory loads the history from path, if set, and keeps at most maxEntries entries
+func NewHistory(path string, maxEntries int) *History {
+	h := &History{path: path, maxEntries: maxEntries}
+	if path == "" {
+		return h
+	}
+	if err := ReadGob(path, &h.entries); err != nil {
+		logrus.Errorf("falling back to empty history, error reading Gob file: %v", err)
+		h.entries = nil
+	}
+	h.rotate()
+	return h
+}
+
+// DiffAssignments returns the changes between two job assignments, sorted by job name
+func DiffAssignments(previous, current map[string]string) []AssignmentChange {
+	var changes []AssignmentChange
+	for job, cluster := range current {
+		if previous[job] != cluster {
+			changes = append(changes, AssignmentChange{Job: job, From: previous[job], To: cluster})
+		}
+	}
+	for job, cluster := range previous {
+		if _, exists := current[job]; !exists {
+			changes = append(changes, AssignmentChange{Job: job, From: cluster})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Job < changes[j].Job })
+	return changes
+}
+
+// Record appends the changes between the previous and current assignments, dropping the
+// oldest entries beyond the limit. Nothing is recorded when the assignments are the same.
+func (h *History) Record(now time.Time, previous, current map[string]string) error {
+	changes := DiffAssignments(previous, current)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, HistoryEntry{Time: now, Changes: changes})
+	h.rotate()
+	if h.path == "" {
+		return nil
+	}
+	return WriteGob(h.path, h.entries)
+}
+
+func (h *History) rotate() {
+	if h.maxEntries > 0 && len(h.entries) > h.maxEntries {
+		h.entries = append([]HistoryEntry(nil), h.entries[len(h.entries)-h.maxEntries:]...)
+	}
+}
+
+// JobHistoryEntry is a change of the cluster of a single job
+type JobHistoryEntry struct {
+	Time time.Time `json:"time"`
+	From string    `json:"from,omitempty"`
+	To   string    `json:"to,omitempty"`
+}
+
+// ForJob returns the last n changes of the cluster of the job, the most recent first
+func (h *History) ForJob(job string, n int) []JobHistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var result []JobHistoryEntry
+	for i := len(h.entries) - 1; i >= 0 && len(result) < n; i-- {
+		for _, change := range h.entries[i].Changes {
+			if change.Job == job {
+				result = append(result, JobHistoryEntry{Time: h.entries[i].Time, From: change.From, To: change.To})
+				break
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+package dispatcher
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffAssignments(t *testing.T) {
+	testCases := []struct {
+		name     string
+		previous map[string]string
+		current  map[string]string
+		expected []AssignmentChange
+	}{
+		{
+			name:     "no changes",
+			previous: map[string]string{"job-a": "build01"},
+			current:  map[string]string{"job-a": "build01"},
+		},
+		{
+			name:     "moved, new and removed jobs",
+			previous: map[string]string{"job-a": "build01", "job-b": "build02", "job-c": "build01"},
+			current:  map[string]string{"job-a": "build02", "job-c": "build01", "job-d": "build03"},
+			expected: []AssignmentChange{
+				{Job: "job-a", From: "build01", To: "build02"},
+				{Job: "job-b", From: "build02"},
+				{Job: "job-d", To: "build03"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, DiffAssignments(tc.previous, tc.current)); diff != "" {
+				t.Errorf("changes differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	history := NewHistory(path, 2)
+	assignments := []map[string]string{
+		{"job-a": "build01", "job-b": "build01"},
+		{"job-a": "build02", "job-b": "build01"},
+		{"job-a": "build02", "job-b": "build01"},
+		{"job-a": "build03", "job-b": "build01"},
+		{"job-a": "build01", "job-b": "build02"},
+	}
+	previous := map[string]string{}
+	for i, current := range assignments {
+		if err := history.Record(start.Add(time.Duration(i)*time.Hour), previous, current); err != nil {
+			t.Fatalf("failed to record history: %v", err)
+		}
+		previous = current
+	}
+
+	// the unchanged dispatch is not recorded and only the last two entries are kept
+	expected := []JobHistoryEntry{
+		{Time: start.Add(4 * time.Hour), From: "build03", To: "build01"},
+		{Time: start.Add(3 * time.Hour), From: "build02", To: "build03"},
+	}
+	if diff := cmp.Diff(expected, history.ForJob("job-a", 10)); diff != "" {
+		t.Errorf("history of job-a differs from expected:\n%s", diff)
+	}
+	if diff := cmp.Diff(expected[:1], history.ForJob("job-a", 1)); diff != "" {
+		t.Errorf("last change of job-a differs from expected:\n%s", diff)
+	}
+
+	loaded := NewHistory(path, 1)
+	if diff := cmp.Diff(expected[:1], loaded.ForJob("job-a", 10)); diff != "" {
+		t.Errorf("history loaded from disk differs from expected:\n%s", diff)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"math"
 	"net/http"
 	"time"
 
@@ -71,7 +72,33 @@ type PrometheusAPI interface {
 
 // GetJobVolumesFromPrometheus gets job volumes from a Prometheus server for the given time
 func GetJobVolumesFromPrometheus(ctx context.Context, prometheusAPI PrometheusAPI, ts time.Time) (map[string]float64, error) {
-	result, warnings, err := prometheusAPI.Query(ctx, `sum(increase(prowjob_state_transitions{state="pending"}[7d])) by (job_name)`, ts)
+	return getValuesByJobFromPrometheus(ctx, prometheusAPI, `sum(increase(prowjob_state_transitions{state="pending"}[7d])) by (job_name)`, ts)
+}
+
+// GetJobDurationsFromPrometheus gets the average job durations in seconds from a Prometheus server for the given time
+func GetJobDurationsFromPrometheus(ctx context.Context, prometheusAPI PrometheusAPI, ts time.Time) (map[string]float64, error) {
+	return getValuesByJobFromPrometheus(ctx, prometheusAPI, `sum(increase(prow_job_runtime_seconds_sum[7d])) by (job_name) / sum(increase(prow_job_runtime_seconds_count[7d])) by (job_name)`, ts)
+}
+
+// WeightJobVolumesByDuration multiplies the volume of each job by its average duration.
+// A job without a known duration is weighted by 1, so its volume is its count.
+func WeightJobVolumesByDuration(jobVolumes, jobDurations map[string]float64) map[string]float64 {
+	weighted := make(map[string]float64, len(jobVolumes))
+	for job, volume := range jobVolumes {
+		if duration, ok := jobDurations[job]; ok && validDuration(duration) {
+			volume *= duration
+		}
+		weighted[job] = volume
+	}
+	return weighted
+}
+
+func validDuration(duration float64) bool {
+	return duration > 0 && !math.IsInf(duration, 1)
+}
+
+func getValuesByJobFromPrometheus(ctx context.Context, prometheusAPI PrometheusAPI, query string, ts time.Time) (map[string]float64, error) {
+	result, warnings, err := prometheusAPI.Query(ctx, query, ts)
 	if err != nil {
 		return nil, err
 	}
@@ -84,12 +111,12 @@ func GetJobVolumesFromPrometheus(ctx context.Context, prometheusAPI PrometheusAP
 		return nil, fmt.Errorf("returned result of type %T from Prometheus cannot be cast to vector", result)
 	}
 
-	jobVolumes := map[string]float64{}
+	values := map[string]float64{}
 	for _, v := range vector {
-		jobVolumes[string(v.Metric[model.LabelName("job_name")])] = float64(v.Value)
+		values[string(v.Metric[model.LabelName("job_name")])] = float64(v.Value)
 	}
 
-	return jobVolumes, nil
+	return values, nil
 }
 
 // NewPrometheusClient return a Prometheus client
@@ -3,6 +3,7 @@ package dispatcher
 import (
 	"context"
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -20,7 +21,10 @@ type prometheusAPIForTest struct {
 }
 
 var (
-	supportedQueries = sets.New[string](`sum(increase(prowjob_state_transitions{state="pending"}[7d])) by (job_name)`)
+	supportedQueries = sets.New[string](
+		`sum(increase(prowjob_state_transitions{state="pending"}[7d])) by (job_name)`,
+		`sum(increase(prow_job_runtime_seconds_sum[7d])) by (job_name) / sum(increase(prow_job_runtime_seconds_count[7d])) by (job_name)`,
+	)
 )
 
 func (prometheusAPI *prometheusAPIForTest) Query(ctx context.Context, query string, ts time.Time, opts ...prometheusapi.Option) (model.Value, prometheusapi.Warnings, error) {
@@ -90,3 +94,58 @@ func TestGetJobVolumesFromPrometheus(t *testing.T) {
 		})
 	}
 }
+
+func TestGetJobDurationsFromPrometheus(t *testing.T) {
+	now := time.Now().Unix()
+	queryFunc := func(ctx context.Context, query string, ts time.Time) (model.Value, prometheusapi.Warnings, error) {
+		return model.Vector([]*model.Sample{
+			{
+				Metric:    model.Metric(map[model.LabelName]model.LabelValue{model.LabelName("job_name"): model.LabelValue("pull-ci-some-test-job")}),
+				Value:     model.SampleValue(float64(3600)),
+				Timestamp: model.Time(now),
+			},
+		}), nil, nil
+	}
+	actual, err := GetJobDurationsFromPrometheus(context.Background(), &prometheusAPIForTest{queryFunc}, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]float64{"pull-ci-some-test-job": 3600}, actual); diff != "" {
+		t.Errorf("actual does not match expected, diff: %s", diff)
+	}
+}
+
+func TestWeightJobVolumesByDuration(t *testing.T) {
+	testCases := []struct {
+		name         string
+		jobVolumes   map[string]float64
+		jobDurations map[string]float64
+		expected     map[string]float64
+	}{
+		{
+			name:         "volumes are weighted by duration",
+			jobVolumes:   map[string]float64{"short": 10, "long": 2},
+			jobDurations: map[string]float64{"short": 60, "long": 7200},
+			expected:     map[string]float64{"short": 600, "long": 14400},
+		},
+		{
+			name:         "job without duration keeps its count",
+			jobVolumes:   map[string]float64{"known": 2, "unknown": 5},
+			jobDurations: map[string]float64{"known": 100, "not-in-volumes": 300},
+			expected:     map[string]float64{"known": 200, "unknown": 5},
+		},
+		{
+			name:         "invalid durations keep the counts",
+			jobVolumes:   map[string]float64{"zero": 3, "nan": 4, "inf": 1, "valid": 1},
+			jobDurations: map[string]float64{"zero": 0, "nan": math.NaN(), "inf": math.Inf(1), "valid": 50},
+			expected:     map[string]float64{"zero": 3, "nan": 4, "inf": 1, "valid": 50},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, WeightJobVolumesByDuration(tc.jobVolumes, tc.jobDurations)); diff != "" {
+				t.Errorf("actual does not match expected, diff: %s", diff)
+			}
+		})
+	}
+}
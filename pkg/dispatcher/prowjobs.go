@@ -8,9 +8,39 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
+// AssignmentReason describes how the cluster of a job was chosen
+type AssignmentReason string
+
+const (
+	// ReasonPinned means the job config determined the cluster, e.g. via the pin label or capabilities
+	ReasonPinned AssignmentReason = "pinned"
+	// ReasonMostUsedCluster means the cluster most used by the other jobs in the same file was chosen
+	ReasonMostUsedCluster AssignmentReason = "most-used-cluster"
+	// ReasonVolumeMin means the cluster with the least weighted volume was chosen
+	ReasonVolumeMin AssignmentReason = "volume-min"
+	// ReasonVolumeCap means the cluster chosen by volume would exceed its share of the volume with the file of
+	// the job and the least loaded cluster of the same cloud provider staying below its share was chosen instead
+	ReasonVolumeCap AssignmentReason = "volume-cap"
+	// ReasonDrained means the job kept its existing assignment on a drained cluster
+	ReasonDrained AssignmentReason = "drained"
+	// ReasonBlocked means the chosen cluster was blocked and the default cluster was used instead
+	ReasonBlocked AssignmentReason = "blocked"
+	// ReasonExcluded means the file of the job is excluded from the dispatch and the job kept its current assignment
+	ReasonExcluded AssignmentReason = "excluded"
+)
+
+// Explanation records why a job was assigned to its cluster during a dispatch
+type Explanation struct {
+	Cluster      string           `json:"cluster"`
+	Reason       AssignmentReason `json:"reason"`
+	Capabilities []string         `json:"capabilities,omitempty"`
+	Candidates   []string         `json:"candidates,omitempty"`
+}
+
 type Prowjobs struct {
 	mu              sync.Mutex
 	data            map[string]string
+	explanations    map[string]Explanation
 	jobsStoragePath string
 }
 
@@ -36,6 +66,24 @@ func (pjs *Prowjobs) Regenerate(prowjobs map[string]string) {
 	}
 }
 
+// SetExplanations replaces the explanations recorded during the last dispatch
+func (pjs *Prowjobs) SetExplanations(explanations map[string]Explanation) {
+	pjs.mu.Lock()
+	defer pjs.mu.Unlock()
+	pjs.explanations = make(map[string]Explanation, len(explanations))
+	for key, value := range explanations {
+		pjs.explanations[key] = value
+	}
+}
+
+// GetExplanation returns the explanation recorded for the job during the last dispatch
+func (pjs *Prowjobs) GetExplanation(pj string) (Explanation, bool) {
+	pjs.mu.Lock()
+	defer pjs.mu.Unlock()
+	explanation, exists := pjs.explanations[pj]
+	return explanation, exists
+}
+
 func (pjs *Prowjobs) GetDataCopy() map[string]string {
 	pjs.mu.Lock()
 	defer pjs.mu.Unlock()
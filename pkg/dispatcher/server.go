@@ -11,16 +11,21 @@ import (
 
 type Server struct {
 	pjs      *Prowjobs
+	history  *History
 	dispatch func(bool)
 }
 
-func NewServer(jobs *Prowjobs, dispatch func(bool)) *Server {
+func NewServer(jobs *Prowjobs, history *History, dispatch func(bool)) *Server {
 	return &Server{
 		pjs:      jobs,
+		history:  history,
 		dispatch: dispatch,
 	}
 }
 
+// defaultHistoryChanges is the number of changes the history endpoint returns unless told otherwise
+const defaultHistoryChanges = 10
+
 // SchedulingRequest represents the incoming request structure
 type SchedulingRequest struct {
 	Job string `json:"job"`
@@ -31,6 +36,22 @@ type SchedulingResponse struct {
 	Cluster string `json:"cluster"`
 }
 
+// ExplanationResponse represents the response structure of the explain endpoint
+type ExplanationResponse struct {
+	Job          string           `json:"job"`
+	Cluster      string           `json:"cluster"`
+	Reason       AssignmentReason `json:"reason,omitempty"`
+	Capabilities []string         `json:"capabilities,omitempty"`
+	Candidates   []string         `json:"candidates,omitempty"`
+	Message      string           `json:"message,omitempty"`
+}
+
+// HistoryResponse represents the response structure of the history endpoint
+type HistoryResponse struct {
+	Job     string            `json:"job"`
+	Changes []JobHistoryEntry `json:"changes"`
+}
+
 func removeRehearsePrefix(jobName string) string {
 	re := regexp.MustCompile(`^rehearse-\d+-`)
 
@@ -102,3 +123,80 @@ func (s *Server) EventHandler(w http.ResponseWriter, r *http.Request) {
 		s.dispatch(true)
 	}
 }
+
+// ExplainHandler handles the /explain route, reporting why a job was assigned to its cluster
+func (s *Server) ExplainHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.URL.Path != "/explain" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	job := removeRehearsePrefix(r.URL.Query().Get("job"))
+	if job == "" {
+		http.Error(w, "Missing job parameter", http.StatusBadRequest)
+		return
+	}
+
+	cluster := s.pjs.Get(job)
+	if cluster == "" {
+		http.Error(w, "Cluster not found", http.StatusNotFound)
+		return
+	}
+
+	response := ExplanationResponse{Job: job, Cluster: cluster}
+	// jobs assigned by a delta dispatch have no explanation until the next full dispatch
+	if explanation, ok := s.pjs.GetExplanation(job); ok && explanation.Cluster == cluster {
+		response.Reason = explanation.Reason
+		response.Capabilities = explanation.Capabilities
+		response.Candidates = explanation.Candidates
+	} else {
+		response.Message = "the job was not assigned during the last full dispatch"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logrus.WithError(err).WithField("response", response).Error("failed to encode response")
+	}
+}
+
+// HistoryHandler handles the /history route, reporting the last changes of the cluster of a job
+func (s *Server) HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.URL.Path != "/history" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	job := removeRehearsePrefix(r.URL.Query().Get("job"))
+	if job == "" {
+		http.Error(w, "Missing job parameter", http.StatusBadRequest)
+		return
+	}
+
+	n := defaultHistoryChanges
+	if raw := r.URL.Query().Get("n"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			http.Error(w, "Invalid n parameter", http.StatusBadRequest)
+			return
+		}
+		n = parsed
+	}
+
+	response := HistoryResponse{Job: job, Changes: s.history.ForJob(job, n)}
+	if response.Changes == nil {
+		response.Changes = []JobHistoryEntry{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logrus.WithError(err).WithField("response", response).Error("failed to encode response")
+	}
+}
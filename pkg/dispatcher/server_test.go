@@ -1,7 +1,13 @@
 package dispatcher
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestRemoveRehearsePrefix(t *testing.T) {
@@ -35,3 +41,150 @@ func TestRemoveRehearsePrefix(t *testing.T) {
 		}
 	}
 }
+
+func TestExplainHandler(t *testing.T) {
+	pjs := &Prowjobs{
+		data: map[string]string{
+			"dispatched-job": "build01",
+			"new-job":        "build02",
+		},
+		explanations: map[string]Explanation{
+			"dispatched-job": {Cluster: "build01", Reason: ReasonVolumeMin, Candidates: []string{"build01", "build02"}},
+		},
+	}
+	server := NewServer(pjs, nil, nil)
+
+	testCases := []struct {
+		name             string
+		url              string
+		expectedCode     int
+		expectedResponse ExplanationResponse
+	}{
+		{
+			name:         "job dispatched during the last full dispatch is explained",
+			url:          "/explain?job=rehearse-123-dispatched-job",
+			expectedCode: http.StatusOK,
+			expectedResponse: ExplanationResponse{
+				Job:        "dispatched-job",
+				Cluster:    "build01",
+				Reason:     ReasonVolumeMin,
+				Candidates: []string{"build01", "build02"},
+			},
+		},
+		{
+			name:         "job assigned after the last full dispatch has no explanation",
+			url:          "/explain?job=new-job",
+			expectedCode: http.StatusOK,
+			expectedResponse: ExplanationResponse{
+				Job:     "new-job",
+				Cluster: "build02",
+				Message: "the job was not assigned during the last full dispatch",
+			},
+		},
+		{
+			name:         "unknown job",
+			url:          "/explain?job=unknown",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "missing job parameter",
+			url:          "/explain",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			server.ExplainHandler(recorder, httptest.NewRequest(http.MethodGet, tc.url, nil))
+			if recorder.Code != tc.expectedCode {
+				t.Fatalf("expected status code %d, got %d", tc.expectedCode, recorder.Code)
+			}
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+			var actual ExplanationResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &actual); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedResponse, actual); diff != "" {
+				t.Errorf("response differs from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHistoryHandler(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := NewHistory("", 10)
+	if err := history.Record(start, map[string]string{}, map[string]string{"job": "build01"}); err != nil {
+		t.Fatalf("failed to record history: %v", err)
+	}
+	if err := history.Record(start.Add(time.Hour), map[string]string{"job": "build01"}, map[string]string{"job": "build02"}); err != nil {
+		t.Fatalf("failed to record history: %v", err)
+	}
+	server := NewServer(&Prowjobs{}, history, nil)
+
+	testCases := []struct {
+		name             string
+		url              string
+		expectedCode     int
+		expectedResponse HistoryResponse
+	}{
+		{
+			name:         "all changes of a job",
+			url:          "/history?job=rehearse-123-job",
+			expectedCode: http.StatusOK,
+			expectedResponse: HistoryResponse{
+				Job: "job",
+				Changes: []JobHistoryEntry{
+					{Time: start.Add(time.Hour), From: "build01", To: "build02"},
+					{Time: start, To: "build01"},
+				},
+			},
+		},
+		{
+			name:         "last change of a job",
+			url:          "/history?job=job&n=1",
+			expectedCode: http.StatusOK,
+			expectedResponse: HistoryResponse{
+				Job:     "job",
+				Changes: []JobHistoryEntry{{Time: start.Add(time.Hour), From: "build01", To: "build02"}},
+			},
+		},
+		{
+			name:             "job without changes",
+			url:              "/history?job=unknown",
+			expectedCode:     http.StatusOK,
+			expectedResponse: HistoryResponse{Job: "unknown", Changes: []JobHistoryEntry{}},
+		},
+		{
+			name:         "missing job parameter",
+			url:          "/history",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid n parameter",
+			url:          "/history?job=job&n=0",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			server.HistoryHandler(recorder, httptest.NewRequest(http.MethodGet, tc.url, nil))
+			if recorder.Code != tc.expectedCode {
+				t.Fatalf("expected status code %d, got %d", tc.expectedCode, recorder.Code)
+			}
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+			var actual HistoryResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &actual); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedResponse, actual); diff != "" {
+				t.Errorf("response differs from expected:\n%s", diff)
+			}
+		})
+	}
+}
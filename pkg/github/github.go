@@ -48,21 +48,28 @@ func FileGetterFactory(org, repo, branch string, opts ...Opt) FileGetter {
 		if o.BasicAuthUser != "" {
 			req.SetBasicAuth(o.BasicAuthUser, o.BasicAuthPassword)
 		}
-		resp, err := client.StandardClient().Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to GET %s: %w", url, err)
-		}
-		defer resp.Body.Close()
-		if resp.StatusCode == http.StatusNotFound {
-			return nil, nil
-		}
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response body when getting %s: %w", url, err)
-		}
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("got unexpected http status code %d when getting %s, response body: %s", resp.StatusCode, url, string(body))
-		}
-		return body, nil
+		return getFile(client, req)
+	}
+}
+
+// getFile executes the request and returns the response body. It returns
+// a nil error on 404.
+func getFile(client *retryablehttp.Client, req *http.Request) ([]byte, error) {
+	url := req.URL.String()
+	resp, err := client.StandardClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GET %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body when getting %s: %w", url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got unexpected http status code %d when getting %s, response body: %s", resp.StatusCode, url, string(body))
 	}
+	return body, nil
 }
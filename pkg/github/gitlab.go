@@ -0,0 +1,35 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// GitLabFileGetterFactory returns a FileGetter that downloads files for the provided org/repo/branch
+// through the repository files API of the GitLab instance at baseURL. Like FileGetterFactory, the
+// returned FileGetter returns a nil error on 404. When configured WithAuthentication, the token
+// is sent as a personal access token and the username is ignored.
+func GitLabFileGetterFactory(baseURL, org, repo, branch string, opts ...Opt) FileGetter {
+	o := Opts{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	client := retryablehttp.NewClient()
+	client.Logger = nil
+	project := url.PathEscape(org + "/" + repo)
+	return func(path string) ([]byte, error) {
+		fileURL := fmt.Sprintf("%s/api/v4/projects/%s/repository/files/%s/raw?ref=%s", strings.TrimSuffix(baseURL, "/"), project, url.PathEscape(path), url.QueryEscape(branch))
+		req, err := http.NewRequest(http.MethodGet, fileURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct request: %w", err)
+		}
+		if o.BasicAuthPassword != "" {
+			req.Header.Set("PRIVATE-TOKEN", o.BasicAuthPassword)
+		}
+		return getFile(client, req)
+	}
+}
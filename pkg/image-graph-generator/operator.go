@@ -118,7 +118,11 @@ func (o *Operator) callback(c *api.ReleaseBuildConfiguration, i *config.Info) er
 }
 
 func (o *Operator) OperateOnCIOperatorConfigs() error {
-	if err := config.OperateOnCIOperatorConfigDir(filepath.Join(o.releaseRepoPath, ReleaseCIOperatorConfigsPath), o.callback); err != nil {
+	clusterProfiles, err := config.LoadClusterProfiles(o.releaseRepoPath)
+	if err != nil {
+		return err
+	}
+	if err := config.OperateOnCIOperatorConfigDir(filepath.Join(o.releaseRepoPath, ReleaseCIOperatorConfigsPath), clusterProfiles, o.callback); err != nil {
 		return err
 	}
 	return nil
@@ -2,20 +2,32 @@ package labeledclient
 
 import (
 	"context"
+	"fmt"
 	"maps"
+	"strings"
 
+	appsv1 "k8s.io/api/apps/v1"
 	authapi "k8s.io/api/authorization/v1"
+	coreapi "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/watch"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
+	buildapi "github.com/openshift/api/build/v1"
+
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/steps"
 )
 
-// Wrap wraps the upstream client, adding labels to objects created or updated
+// Wrap wraps the upstream client, adding labels to objects created or updated.
+// Pods, the pod templates of deployments and builds additionally get the
+// job's PodLabels and PodAnnotations.
 func Wrap(upstream ctrlruntimeclient.Client, jobSpec *api.JobSpec) ctrlruntimeclient.Client {
 	return &client{
 		upstream: upstream,
@@ -48,7 +60,9 @@ func (c *client) List(ctx context.Context, list ctrlruntimeclient.ObjectList, op
 }
 
 func (c *client) Create(ctx context.Context, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
-	c.addLabels(obj)
+	if err := c.addLabels(obj); err != nil {
+		return err
+	}
 	return c.upstream.Create(ctx, obj, opts...)
 }
 
@@ -92,13 +106,104 @@ func (c *client) SubResource(subResource string) ctrlruntimeclient.SubResourceCl
 	return c.upstream.SubResource(subResource)
 }
 
-func (c *client) addLabels(obj ctrlruntimeclient.Object) {
+func (c *client) addLabels(obj ctrlruntimeclient.Object) error {
+	var podMeta *metav1.ObjectMeta
 	// SelfSubjectAccessReview & LocalSubjectAccessReview do not hold labels
-	switch obj.(type) {
+	switch o := obj.(type) {
 	case *authapi.SelfSubjectAccessReview, *authapi.LocalSubjectAccessReview:
-	default:
-		obj.SetLabels(steps.LabelsFor(c.jobSpec, maps.Clone(obj.GetLabels()), ""))
+		return nil
+	case *coreapi.Pod:
+		podMeta = &o.ObjectMeta
+	case *appsv1.Deployment:
+		podMeta = &o.Spec.Template.ObjectMeta
+	case *buildapi.Build:
+		// the build pods are created by the build controller, the best we can do is to label the builds
+		podMeta = &o.ObjectMeta
+	}
+	if podMeta != nil {
+		if err := c.addPodMetadata(podMeta); err != nil {
+			return fmt.Errorf("could not add pod labels and annotations to %T %s: %w", obj, obj.GetName(), err)
+		}
+	}
+	obj.SetLabels(steps.LabelsFor(c.jobSpec, maps.Clone(obj.GetLabels()), ""))
+	return nil
+}
+
+func (c *client) addPodMetadata(podMeta *metav1.ObjectMeta) error {
+	labels, labelErr := merge(podMeta.Labels, c.jobSpec.PodLabels, "label")
+	annotations, annotationErr := merge(podMeta.Annotations, c.jobSpec.PodAnnotations, "annotation")
+	if err := utilerrors.NewAggregate([]error{labelErr, annotationErr}); err != nil {
+		return err
+	}
+	podMeta.Labels, podMeta.Annotations = labels, annotations
+	return nil
+}
+
+// merge adds the extra values to the existing ones. Values that ci-operator
+// already set are never overridden, a different value is an error.
+func merge(existing, extra map[string]string, kind string) (map[string]string, error) {
+	if len(extra) == 0 {
+		return existing, nil
+	}
+	merged := maps.Clone(existing)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	var errs []error
+	for _, key := range sets.List(sets.KeySet(extra)) {
+		if value, set := merged[key]; set && value != extra[key] {
+			errs = append(errs, fmt.Errorf("%s %s=%s collides with the value %q set by ci-operator", kind, key, extra[key], value))
+			continue
+		}
+		merged[key] = extra[key]
+	}
+	return merged, utilerrors.NewAggregate(errs)
+}
+
+// reservedPrefixes are the prefixes of the labels and annotations ci-operator
+// and Prow use internally
+var reservedPrefixes = []string{"ci.openshift.io/", "ci-operator.openshift.io/", "prow.k8s.io/"}
+
+func isReserved(key string, reservedKeys sets.Set[string]) bool {
+	if reservedKeys.Has(key) {
+		return true
+	}
+	for _, prefix := range reservedPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePodMetadata determines whether the labels and annotations can be
+// added to the pods created for a job, that is whether they are well-formed
+// and do not override any ci-operator uses internally.
+func ValidatePodMetadata(labels, annotations map[string]string) error {
+	reservedLabels := sets.KeySet(steps.LabelsFor(&api.JobSpec{}, nil, "")).Insert(steps.CreatesLabel, steps.AppLabel)
+	var errs []error
+	for _, key := range sets.List(sets.KeySet(labels)) {
+		if isReserved(key, reservedLabels) {
+			errs = append(errs, fmt.Errorf("label %s is reserved for ci-operator", key))
+			continue
+		}
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, fmt.Errorf("label %s: invalid key: %s", key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(labels[key]) {
+			errs = append(errs, fmt.Errorf("label %s: invalid value: %s", key, msg))
+		}
+	}
+	for _, key := range sets.List(sets.KeySet(annotations)) {
+		if isReserved(key, sets.New[string]()) {
+			errs = append(errs, fmt.Errorf("annotation %s is reserved for ci-operator", key))
+			continue
+		}
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, fmt.Errorf("annotation %s: invalid key: %s", key, msg))
+		}
 	}
+	return utilerrors.NewAggregate(errs)
 }
 
 type clientWithWatch struct {
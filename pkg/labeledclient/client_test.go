@@ -2,10 +2,13 @@ package labeledclient
 
 import (
 	"context"
+	"errors"
+	"maps"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 
+	appsv1 "k8s.io/api/apps/v1"
 	coreapi "k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -15,6 +18,7 @@ import (
 
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/steps"
+	"github.com/openshift/ci-tools/pkg/testhelper"
 )
 
 func TestCreate(t *testing.T) {
@@ -126,3 +130,115 @@ func TestCreate(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateAddsPodMetadata(t *testing.T) {
+	jobSpec := &api.JobSpec{
+		PodLabels:      map[string]string{"cost-center": "1234"},
+		PodAnnotations: map[string]string{"team": "dptp"},
+	}
+	podMeta := func(obj ctrlruntimeclient.Object) meta.Object {
+		if deployment, ok := obj.(*appsv1.Deployment); ok {
+			return &deployment.Spec.Template
+		}
+		return obj
+	}
+	tests := []struct {
+		name                string
+		obj                 ctrlruntimeclient.Object
+		expectedLabels      map[string]string
+		expectedAnnotations map[string]string
+		expectedErr         error
+	}{
+		{
+			name:                "pod gets the labels and annotations",
+			obj:                 &coreapi.Pod{ObjectMeta: meta.ObjectMeta{Name: "pod"}},
+			expectedLabels:      map[string]string{"cost-center": "1234"},
+			expectedAnnotations: map[string]string{"team": "dptp"},
+		},
+		{
+			name:                "deployment pod template gets the labels and annotations",
+			obj:                 &appsv1.Deployment{ObjectMeta: meta.ObjectMeta{Name: "deployment"}},
+			expectedLabels:      map[string]string{"cost-center": "1234"},
+			expectedAnnotations: map[string]string{"team": "dptp"},
+		},
+		{
+			name:           "other objects are not changed",
+			obj:            &coreapi.Secret{ObjectMeta: meta.ObjectMeta{Name: "secret"}},
+			expectedLabels: map[string]string{},
+		},
+		{
+			name:                "same value is not a collision",
+			obj:                 &coreapi.Pod{ObjectMeta: meta.ObjectMeta{Name: "pod", Labels: map[string]string{"cost-center": "1234"}}},
+			expectedLabels:      map[string]string{"cost-center": "1234"},
+			expectedAnnotations: map[string]string{"team": "dptp"},
+		},
+		{
+			name:                "collision is an error",
+			obj:                 &coreapi.Pod{ObjectMeta: meta.ObjectMeta{Name: "pod", Annotations: map[string]string{"team": "other"}}},
+			expectedAnnotations: map[string]string{"team": "other"},
+			expectedErr:         errors.New(`could not add pod labels and annotations to *v1.Pod pod: annotation team=dptp collides with the value "other" set by ci-operator`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &client{
+				upstream: fakeclient.NewClientBuilder().Build(),
+				jobSpec:  jobSpec,
+			}
+			err := c.Create(context.TODO(), tt.obj)
+			if diff := cmp.Diff(tt.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("error differs from expected:\n%s", diff)
+			}
+			// ignore the labels that are added to all objects
+			labels := maps.Clone(podMeta(tt.obj).GetLabels())
+			maps.DeleteFunc(labels, func(key, _ string) bool {
+				_, common := steps.LabelsFor(jobSpec, nil, "")[key]
+				return common
+			})
+			if diff := cmp.Diff(tt.expectedLabels, labels); diff != "" {
+				t.Errorf("labels differ from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.expectedAnnotations, podMeta(tt.obj).GetAnnotations()); diff != "" {
+				t.Errorf("annotations differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidatePodMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		expectedErr error
+	}{
+		{
+			name:        "valid labels and annotations",
+			labels:      map[string]string{"cost-center": "1234", "example.com/team": "dptp"},
+			annotations: map[string]string{"example.com/owner": "someone with spaces"},
+		},
+		{
+			name:        "labels set by ci-operator are reserved",
+			labels:      map[string]string{steps.CreatedByCILabel: "false", "OPENSHIFT_CI": "false", steps.AppLabel: "app", "ci.openshift.io/anything": "value"},
+			expectedErr: errors.New("[label OPENSHIFT_CI is reserved for ci-operator, label app is reserved for ci-operator, label ci.openshift.io/anything is reserved for ci-operator, label created-by-ci is reserved for ci-operator]"),
+		},
+		{
+			name:        "annotations with an internal prefix are reserved",
+			annotations: map[string]string{"ci-operator.openshift.io/save-container-logs": "false"},
+			expectedErr: errors.New("annotation ci-operator.openshift.io/save-container-logs is reserved for ci-operator"),
+		},
+		{
+			name:        "invalid label",
+			labels:      map[string]string{"cost center": "12/34"},
+			expectedErr: errors.New("[label cost center: invalid key: name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]'), label cost center: invalid value: a valid label must be an empty string or consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyValue',  or 'my_value',  or '12345', regex used for validation is '(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?')]"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePodMetadata(tt.labels, tt.annotations)
+			if diff := cmp.Diff(tt.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("error differs from expected:\n%s", diff)
+			}
+		})
+	}
+}
@@ -50,6 +50,7 @@ type configAgent struct {
 	indexes          map[string]configIndex
 	indexSubscribers map[string][]chan IndexDelta
 	reloadConfig     func() error
+	clusterProfiles  func() api.ClusterProfilesMap
 }
 
 type configIndex map[string][]*api.ReleaseBuildConfiguration
@@ -93,6 +94,10 @@ type ConfigAgentOptions struct {
 
 	Org  string
 	Repo string
+
+	// ClusterProfiles returns the cluster profiles config, whose aliases are
+	// accepted in addition to the built-in profiles when loading configs
+	ClusterProfiles func() api.ClusterProfilesMap
 }
 
 type ConfigAgentOption func(*ConfigAgentOptions)
@@ -115,6 +120,12 @@ func WithRepo(repo string) ConfigAgentOption {
 	}
 }
 
+func WithClusterProfiles(profiles func() api.ClusterProfilesMap) ConfigAgentOption {
+	return func(o *ConfigAgentOptions) {
+		o.ClusterProfiles = profiles
+	}
+}
+
 // NewConfigAgent returns a ConfigAgent interface that automatically reloads when
 // configs are changed on disk.
 func NewConfigAgent(configPath string, errCh chan error, opts ...ConfigAgentOption) (ConfigAgent, error) {
@@ -125,7 +136,10 @@ func NewConfigAgent(configPath string, errCh chan error, opts ...ConfigAgentOpti
 	if opt.ErrorMetric == nil {
 		opt.ErrorMetric = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "config_agent_errors_total"}, []string{"error"})
 	}
-	a := &configAgent{configPath: configPath, lock: &sync.RWMutex{}, errorMetrics: opt.ErrorMetric, org: opt.Org, repo: opt.Repo}
+	if opt.ClusterProfiles == nil {
+		opt.ClusterProfiles = func() api.ClusterProfilesMap { return nil }
+	}
+	a := &configAgent{configPath: configPath, lock: &sync.RWMutex{}, errorMetrics: opt.ErrorMetric, org: opt.Org, repo: opt.Repo, clusterProfiles: opt.ClusterProfiles}
 	a.reloadConfig = a.loadFilenameToConfig
 	// Load config once so we fail early if that doesn't work and are ready as soon as we return
 	if err := a.reloadConfig(); err != nil {
@@ -244,7 +258,7 @@ func (a *configAgent) loadFilenameToConfig() error {
 		a.lock.Lock()
 		defer a.lock.Unlock()
 		startTime := time.Now()
-		configs, err := config.LoadByOrgRepo(filepath.Join(a.configPath, a.org, a.repo))
+		configs, err := config.LoadByOrgRepo(filepath.Join(a.configPath, a.org, a.repo), a.clusterProfiles())
 		if err != nil {
 			return time.Duration(0), fmt.Errorf("loading config failed: %w", err)
 		}
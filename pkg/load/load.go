@@ -264,7 +264,37 @@ func ClusterProfilesConfig(configPath string) (api.ClusterProfilesMap, error) {
 	// from code in ci-tools to the config file in openshift/release
 	profilesFromConfigMap := make(api.ClusterProfilesMap)
 	for _, p := range profilesFromConfig {
-		profilesFromConfigMap[p.Profile] = p
+		if p.BaseProfile == "" {
+			profilesFromConfigMap[p.Profile] = p
+		}
+	}
+	aliases := make(api.ClusterProfilesMap)
+	var errs []error
+	for _, p := range profilesFromConfig {
+		if p.BaseProfile == "" {
+			continue
+		}
+		if err := api.ValidateClusterProfileAlias(p, profilesFromConfigMap); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, duplicate := aliases[p.Profile]; duplicate {
+			errs = append(errs, fmt.Errorf("cluster profile alias %q is defined more than once", p.Profile))
+			continue
+		}
+		if p.Secret == "" {
+			p.Secret = api.GetDefaultClusterProfileSecretName(p.Profile)
+		}
+		if p.ClusterType == "" {
+			p.ClusterType = p.BaseProfile.ClusterType()
+		}
+		if p.LeaseType == "" {
+			p.LeaseType = p.BaseProfile.LeaseType()
+		}
+		aliases[p.Profile] = p
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid cluster profile aliases in %s: %w", configPath, utilerrors.NewAggregate(errs))
 	}
 
 	mergedMap := make(api.ClusterProfilesMap)
@@ -282,6 +312,9 @@ func ClusterProfilesConfig(configPath string) (api.ClusterProfilesMap, error) {
 			}
 		}
 	}
+	for name, alias := range aliases {
+		mergedMap[name] = alias
+	}
 
 	return mergedMap, nil
 }
@@ -1,17 +1,21 @@
 package load
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/ghodss/yaml"
+	"github.com/google/go-cmp/cmp"
 
 	"k8s.io/apimachinery/pkg/util/diff"
 
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/testhelper"
 )
 
 func TestRegistry(t *testing.T) {
@@ -318,10 +322,24 @@ func TestClusterProfilesConfig(t *testing.T) {
 		}
 	}
 
+	profilesWithAlias := make(api.ClusterProfilesMap)
+	for name, details := range existingProfiles {
+		profilesWithAlias[name] = details
+	}
+	profilesWithAlias["aws-restricted"] = api.ClusterProfileDetails{
+		Profile:     "aws-restricted",
+		BaseProfile: api.ClusterProfileAWS,
+		ClusterType: api.ClusterProfileAWS.ClusterType(),
+		LeaseType:   api.ClusterProfileAWS.LeaseType(),
+		Secret:      "cluster-secrets-aws-restricted",
+		Env:         map[string]string{"RESTRICTED": "true"},
+	}
+
 	var testCases = []struct {
-		name     string
-		expected api.ClusterProfilesMap
-		testYaml string
+		name        string
+		expected    api.ClusterProfilesMap
+		testYaml    string
+		expectedErr error
 	}{
 		{
 			name:     "emptyOwnersFile",
@@ -367,6 +385,29 @@ func TestClusterProfilesConfig(t *testing.T) {
     `,
 			expected: profilesWithSecrets,
 		},
+		{
+			name: "alias of a built-in profile",
+			testYaml: `
+        - profile: aws-restricted
+          base_profile: aws
+          env:
+            RESTRICTED: "true"
+    `,
+			expected: profilesWithAlias,
+		},
+		{
+			name: "invalid aliases",
+			testYaml: `
+        - profile: gcp
+          base_profile: aws
+        - profile: aws-restricted
+          base_profile: aws-other
+        - profile: aws-config-only
+          base_profile: aws
+        - profile: aws-config-only
+    `,
+			expectedErr: errors.New(`invalid cluster profile aliases in CONFIG: [cluster profile alias "gcp" shadows the built-in profile of the same name, cluster profile alias "aws-restricted": base profile "aws-other" is not a built-in profile, cluster profile alias "aws-config-only" shadows the profile of the same name defined in the config]`),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -387,7 +428,13 @@ func TestClusterProfilesConfig(t *testing.T) {
 				t.Fatalf("Failed to close tmp file: %v", err)
 			}
 
-			actual, _ := ClusterProfilesConfig(tmpFile.Name())
+			actual, err := ClusterProfilesConfig(tmpFile.Name())
+			if tc.expectedErr != nil {
+				tc.expectedErr = errors.New(strings.ReplaceAll(tc.expectedErr.Error(), "CONFIG", tmpFile.Name()))
+			}
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("error differs from expected: %s", diff)
+			}
 			if !reflect.DeepEqual(tc.expected, actual) {
 				t.Errorf("\nExpected: %v, \nActual: %v", tc.expected, actual)
 			}
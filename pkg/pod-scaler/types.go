@@ -326,14 +326,17 @@ func coalesceOnce(input []TimeRange) []TimeRange {
 	return input
 }
 
+// DefaultDataMaxAge is the default age after which data is pruned.
+const DefaultDataMaxAge = 90 * 24 * time.Hour
+
 // Prune ensures that no identifying set of labels contains more than twenty-five entries,
-// as well as removing any data that was added more than 90 days ago.
+// as well as removing any data that was added more than maxAge ago. Label sets are
+// dropped once none of their entries are left.
 // We know that an entry fingerprint can only exist for one fully-qualified label set,
 // but if the label set contains a multi-stage step, it will also be referenced in
 // the additional per-step index.
-func (q *CachedQuery) Prune() {
-	ninetyDaysAgo := time.Now().Add(-90 * 24 * time.Hour)
-	q.prune(ninetyDaysAgo)
+func (q *CachedQuery) Prune(maxAge time.Duration) {
+	q.prune(time.Now().Add(-maxAge))
 }
 
 func (q *CachedQuery) prune(pruneBefore time.Time) {
@@ -358,6 +361,9 @@ func (q *CachedQuery) prune(pruneBefore time.Time) {
 		for _, item := range toRemove {
 			delete(q.Data, item.Fingerprint)
 		}
+		if len(q.DataByMetaData[meta]) == 0 {
+			delete(q.DataByMetaData, meta)
+		}
 	}
 }
 
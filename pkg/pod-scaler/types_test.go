@@ -863,6 +863,45 @@ func TestCachedQuery_Prune_removeOldFingerprints(t *testing.T) {
 	}
 }
 
+func TestCachedQuery_Prune_dropsIdentifiersWithoutRecentData(t *testing.T) {
+	now := time.Now()
+	q := CachedQuery{
+		Data: map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{},
+		DataByMetaData: map[FullMetadata][]FingerprintTime{
+			{Step: "recent"}: {
+				fta(1, now.Add(-200*24*time.Hour)), // Should be pruned
+				fta(2, now.Add(-time.Hour)),
+			},
+			{Step: "old"}: {
+				fta(3, now.Add(-200*24*time.Hour)), // Should be pruned
+				fta(4, now.Add(-181*24*time.Hour)), // Should be pruned
+			},
+			{Step: "untimed"}: {
+				{Fingerprint: model.Fingerprint(5)}, // Should be pruned
+			},
+		},
+	}
+
+	for i := 1; i < 6; i++ {
+		q.Data[model.Fingerprint(i)] = circonusllhist.NewHistogramWithoutLookups(circonusllhist.New(circonusllhist.NoLookup()))
+	}
+
+	q.Prune(DefaultDataMaxAge)
+
+	expected := CachedQuery{
+		Data: map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{
+			model.Fingerprint(2): circonusllhist.NewHistogramWithoutLookups(circonusllhist.New(circonusllhist.NoLookup())),
+		},
+		DataByMetaData: map[FullMetadata][]FingerprintTime{
+			{Step: "recent"}: {fta(2, now.Add(-time.Hour))},
+		},
+	}
+
+	if diff := cmp.Diff(expected, q, dataComparer); diff != "" {
+		t.Errorf("got incorrect state after pruning: %v", diff)
+	}
+}
+
 // fta generates a FingerprintTime for the supplied int representation of a fingerprint, and the added time
 func fta(fingerprint int, added time.Time) FingerprintTime {
 	return FingerprintTime{
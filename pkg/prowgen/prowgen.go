@@ -199,7 +199,7 @@ func testContainsLease(test *cioperatorapi.TestStepConfiguration) bool {
 		return false
 	}
 
-	return len(api.LeasesForTest(test.MultiStageTestConfigurationLiteral)) > 0
+	return len(api.LeasesForTest(test.MultiStageTestConfigurationLiteral, nil)) > 0
 }
 
 type generatePresubmitOptions struct {
@@ -22,7 +22,10 @@ const (
 	cioperatorLatestImage = "ci-operator:latest"
 )
 
-func DeterminizeJobs(prowJobConfigDir string, config *dispatcher.Config, pjs map[string]string, blocked sets.Set[string], cm dispatcher.ClusterMap) error {
+// DeterminizeJobs defaults the jobs in prowJobConfigDir and assigns them to the cluster from pjs or, if pjs is
+// nil, from the config. Jobs are not assigned to blocked clusters and jobs that are not already running on a
+// drained cluster are not assigned to one either.
+func DeterminizeJobs(prowJobConfigDir string, config *dispatcher.Config, pjs map[string]string, blocked, drained sets.Set[string], cm dispatcher.ClusterMap) error {
 	ch := make(chan string)
 	errCh := make(chan error)
 	produce := func() error {
@@ -53,7 +56,7 @@ func DeterminizeJobs(prowJobConfigDir string, config *dispatcher.Config, pjs map
 				continue
 			}
 
-			if err := defaultJobConfig(jobConfig, path, config, pjs, blocked, cm); err != nil {
+			if err := defaultJobConfig(jobConfig, path, config, pjs, blocked, drained, cm); err != nil {
 				errCh <- fmt.Errorf("failed to default job config %q: %w", path, err)
 			}
 
@@ -76,11 +79,11 @@ func DeterminizeJobs(prowJobConfigDir string, config *dispatcher.Config, pjs map
 	return nil
 }
 
-func defaultJobConfig(jc *prowconfig.JobConfig, path string, config *dispatcher.Config, pjs map[string]string, blocked sets.Set[string], cm dispatcher.ClusterMap) error {
+func defaultJobConfig(jc *prowconfig.JobConfig, path string, config *dispatcher.Config, pjs map[string]string, blocked, drained sets.Set[string], cm dispatcher.ClusterMap) error {
 	mostUsedCluster := dispatcher.FindMostUsedCluster(jc)
 	for k := range jc.PresubmitsStatic {
 		for idx := range jc.PresubmitsStatic[k] {
-			cluster, err := determineCluster(jc.PresubmitsStatic[k][idx].JobBase, config, pjs, path, mostUsedCluster, blocked, cm)
+			cluster, err := determineCluster(jc.PresubmitsStatic[k][idx].JobBase, config, pjs, path, mostUsedCluster, blocked, drained, cm)
 			if err != nil {
 				return err
 			}
@@ -103,7 +106,7 @@ func defaultJobConfig(jc *prowconfig.JobConfig, path string, config *dispatcher.
 	}
 	for k := range jc.PostsubmitsStatic {
 		for idx := range jc.PostsubmitsStatic[k] {
-			cluster, err := determineCluster(jc.PostsubmitsStatic[k][idx].JobBase, config, pjs, path, mostUsedCluster, blocked, cm)
+			cluster, err := determineCluster(jc.PostsubmitsStatic[k][idx].JobBase, config, pjs, path, mostUsedCluster, blocked, drained, cm)
 			if err != nil {
 				return err
 			}
@@ -123,7 +126,7 @@ func defaultJobConfig(jc *prowconfig.JobConfig, path string, config *dispatcher.
 		}
 	}
 	for idx := range jc.Periodics {
-		cluster, err := determineCluster(jc.Periodics[idx].JobBase, config, pjs, path, mostUsedCluster, blocked, cm)
+		cluster, err := determineCluster(jc.Periodics[idx].JobBase, config, pjs, path, mostUsedCluster, blocked, drained, cm)
 		if err != nil {
 			return err
 		}
@@ -139,14 +142,18 @@ func isCIOperatorLatest(image string) bool {
 	return lastPart == cioperatorLatestImage
 }
 
-func determineCluster(jb prowconfig.JobBase, config *dispatcher.Config, pjs map[string]string, path string, mostUsedCluster string, blocked sets.Set[string], cm dispatcher.ClusterMap) (string, error) {
+func determineCluster(jb prowconfig.JobBase, config *dispatcher.Config, pjs map[string]string, path string, mostUsedCluster string, blocked, drained sets.Set[string], cm dispatcher.ClusterMap) (string, error) {
 	if pjs == nil {
+		// drained clusters keep the jobs assigned to them but get no new ones
+		if jb.Cluster != "" && drained.Has(jb.Cluster) {
+			return jb.Cluster, nil
+		}
 		c, canBeRelocated, err := config.DetermineClusterForJob(jb, path, cm)
 		if err != nil {
 			return "", err
 		}
-		if blocked.Has(string(c)) {
-			return dispatcher.DetermineTargetCluster(mostUsedCluster, string(c), string(config.Default), canBeRelocated, blocked), nil
+		if jobBlocked := dispatcher.BlockedClustersForJob(jb, blocked, cm).Union(drained); jobBlocked.Has(string(c)) {
+			return dispatcher.DetermineTargetCluster(mostUsedCluster, string(c), string(config.Default), canBeRelocated, jobBlocked), nil
 		}
 		return string(c), nil
 	}
@@ -23,7 +23,7 @@ func TestDefaultJobConfig(t *testing.T) {
 	}
 
 	config := &dispatcher.Config{Default: "api.ci"}
-	if err := defaultJobConfig(jc, "", config, nil, make(sets.Set[string]), dispatcher.ClusterMap{}); err != nil {
+	if err := defaultJobConfig(jc, "", config, nil, make(sets.Set[string]), nil, dispatcher.ClusterMap{}); err != nil {
 		t.Errorf("failed default job config: %v", err)
 	}
 
@@ -68,3 +68,41 @@ func TestIsCIOperatorLatest(t *testing.T) {
 		})
 	}
 }
+
+func TestDetermineClusterDrained(t *testing.T) {
+	testCases := []struct {
+		name     string
+		jobBase  config.JobBase
+		drained  sets.Set[string]
+		expected string
+	}{
+		{
+			name:     "job is assigned to the default cluster",
+			jobBase:  config.JobBase{Name: "job", Agent: "kubernetes"},
+			expected: "build01",
+		},
+		{
+			name:     "job already running on a drained cluster stays there",
+			jobBase:  config.JobBase{Name: "job", Agent: "kubernetes", Cluster: "build01"},
+			drained:  sets.New[string]("build01"),
+			expected: "build01",
+		},
+		{
+			name:     "job is not assigned to a drained cluster",
+			jobBase:  config.JobBase{Name: "job", Agent: "kubernetes", Cluster: "build03"},
+			drained:  sets.New[string]("build01"),
+			expected: "build02",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := determineCluster(tc.jobBase, &dispatcher.Config{Default: "build01"}, nil, "", "build02", sets.New[string](), tc.drained, dispatcher.ClusterMap{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected cluster %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 type ReadOnlyClient interface {
 	GetFieldOnItem(itemName, fieldName string) ([]byte, error)
+	GetFieldsOnItem(itemName string) (sets.Set[string], error)
 	GetInUseInformationForAllItems(optionalPrefix string) (map[string]SecretUsageComparer, error)
 	GetUserSecrets() (map[types.NamespacedName]map[string]string, error)
 	HasItem(itemname string) (bool, error)
@@ -0,0 +1,79 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/vaultclient"
+)
+
+// snapshotPrefix is the prefix the items of a snapshot are served under
+const snapshotPrefix = "snapshot"
+
+// NewSOPSClient returns a read-only client that serves the items of a
+// SOPS-encrypted snapshot of the secret store instead of talking to Vault.
+// The snapshot is a YAML mapping of item names to their fields and values:
+//
+//	dptp/my-item:
+//	  field: value
+//
+// The file is decrypted with the `sops` binary, which has to be in $PATH.
+func NewSOPSClient(path string, censor *DynamicCensor) (ReadOnlyClient, error) {
+	raw, err := exec.Command("sops", "--decrypt", path).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("failed to decrypt %s: %w: %s", path, err, string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+	return newSnapshotClient(raw, censor)
+}
+
+func newSnapshotClient(raw []byte, censor *DynamicCensor) (ReadOnlyClient, error) {
+	var items map[string]map[string]string
+	if err := yaml.Unmarshal(raw, &items); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+	snapshot := &snapshotVaultClient{items: map[string]map[string]string{}}
+	for name, fields := range items {
+		snapshot.items[snapshotPrefix+"/"+strings.Trim(name, "/")] = fields
+	}
+	return NewVaultClient(snapshot, snapshotPrefix, censor), nil
+}
+
+// snapshotVaultClient serves the items of a snapshot in place of Vault. The
+// creation time of the items is not known and reported as the zero time.
+type snapshotVaultClient struct {
+	items map[string]map[string]string
+}
+
+func (c *snapshotVaultClient) GetKV(path string) (*vaultclient.KVData, error) {
+	fields, ok := c.items[path]
+	if !ok {
+		return nil, &api.ResponseError{StatusCode: http.StatusNotFound, Errors: []string{fmt.Sprintf("no item at path %s in the snapshot", path)}}
+	}
+	return &vaultclient.KVData{Data: maps.Clone(fields)}, nil
+}
+
+func (c *snapshotVaultClient) ListKVRecursively(path string) ([]string, error) {
+	var paths []string
+	for item := range c.items {
+		if strings.HasPrefix(item, path+"/") {
+			paths = append(paths, item)
+		}
+	}
+	return paths, nil
+}
+
+func (c *snapshotVaultClient) UpsertKV(path string, _ map[string]string) error {
+	return fmt.Errorf("cannot write %s: the snapshot is read-only", path)
+}
@@ -0,0 +1,67 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift/ci-tools/pkg/api/vault"
+)
+
+func TestSnapshotClient(t *testing.T) {
+	raw := []byte(`dptp/item:
+  field: value
+user/synced:
+  secretsync/target-namespace: ns-1,ns-2
+  secretsync/target-name: synced
+  key: user-value
+`)
+	censor := NewDynamicCensor()
+	client, err := newSnapshotClient(raw, &censor)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	value, err := client.GetFieldOnItem("dptp/item", "field")
+	if err != nil {
+		t.Fatalf("failed to get field: %v", err)
+	}
+	if diff := cmp.Diff("value", string(value)); diff != "" {
+		t.Errorf("field value differs from expected:\n%s", diff)
+	}
+	if _, err := client.GetFieldOnItem("dptp/item", "missing"); err == nil {
+		t.Error("expected an error for a missing field")
+	}
+
+	for item, expected := range map[string]bool{"dptp/item": true, "dptp/missing": false} {
+		has, err := client.HasItem(item)
+		if err != nil {
+			t.Fatalf("failed to determine whether %s exists: %v", item, err)
+		}
+		if has != expected {
+			t.Errorf("expected HasItem(%s) to be %t, got %t", item, expected, has)
+		}
+	}
+
+	inUse, err := client.GetInUseInformationForAllItems("dptp")
+	if err != nil {
+		t.Fatalf("failed to get in-use information: %v", err)
+	}
+	if _, ok := inUse["dptp/item"]; !ok || len(inUse) != 1 {
+		t.Errorf("expected in-use information for dptp/item only, got %v", inUse)
+	}
+
+	userSecrets, err := client.GetUserSecrets()
+	if err != nil {
+		t.Fatalf("failed to get user secrets: %v", err)
+	}
+	expected := map[types.NamespacedName]map[string]string{
+		{Namespace: "ns-1", Name: "synced"}: {"key": "user-value", vault.VaultSourceKey: "snapshot/user/synced"},
+		{Namespace: "ns-2", Name: "synced"}: {"key": "user-value", vault.VaultSourceKey: "snapshot/user/synced"},
+	}
+	if diff := cmp.Diff(expected, userSecrets); diff != "" {
+		t.Errorf("user secrets differ from expected:\n%s", diff)
+	}
+}
@@ -40,6 +40,10 @@ func (d dryRunClient) GetFieldOnItem(_, _ string) ([]byte, error) {
 	return nil, nil
 }
 
+func (d dryRunClient) GetFieldsOnItem(_ string) (sets.Set[string], error) {
+	return nil, nil
+}
+
 func (d dryRunClient) GetInUseInformationForAllItems(_ string) (map[string]SecretUsageComparer, error) {
 	return nil, nil
 }
@@ -130,6 +134,14 @@ func (c *vaultClient) GetFieldOnItem(itemName, fieldName string) ([]byte, error)
 	return c.getSecretAtPath(itemName, fieldName)
 }
 
+func (c *vaultClient) GetFieldsOnItem(itemName string) (sets.Set[string], error) {
+	response, err := c.upstream.GetKV(c.pathFor(itemName))
+	if err != nil {
+		return nil, err
+	}
+	return sets.KeySet(response.Data), nil
+}
+
 func (c *vaultClient) GetInUseInformationForAllItems(optionalSubPath string) (map[string]SecretUsageComparer, error) {
 	prefix := c.prefix
 	if optionalSubPath != "" {
@@ -17,6 +17,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/prow/pkg/kube"
 
@@ -152,6 +153,10 @@ func (s *clusterClaimStep) acquireCluster(ctx context.Context, waitForClaim func
 	claimStart := time.Now()
 	into := &hivev1.ClusterClaim{}
 	if err := waitForClaim(s.hiveClient, claimNamespace, claimName, into, s.clusterClaim.Timeout.Duration); err != nil {
+		// the wait is also interrupted when the step is cancelled, which is no timeout of the claim
+		if wait.Interrupted(err) && ctx.Err() == nil {
+			return claim, results.ForReason("cluster_claim_timeout").WithError(err).Errorf("no cluster from pool %s/%s became available within the cluster_claim.timeout of %s", clusterPool.Namespace, clusterPool.Name, s.clusterClaim.Timeout.Duration)
+		}
 		return claim, fmt.Errorf("failed to wait for the created cluster claim to become ready: %w", err)
 	}
 	claim = into
@@ -2,6 +2,7 @@ package steps
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -102,6 +103,8 @@ func TestClusterClaimStepAcquireCluster(t *testing.T) {
 		expected      *hivev1.ClusterClaim
 		expectedError error
 		verifyFunc    func(client ctrlruntimeclient.Client) error
+		// cancelled runs the step with a cancelled context
+		cancelled bool
 	}{
 		{
 			name: "happy path",
@@ -269,7 +272,60 @@ func TestClusterClaimStepAcquireCluster(t *testing.T) {
 					},
 				},
 			},
-			expectedError: fmt.Errorf("failed to wait for the created cluster claim to become ready: %w", wait.ErrWaitTimeout),
+			expectedError: errors.New("no cluster from pool ci-cluster-pool/ci-ocp-4.7.0-amd64-aws-us-east-1 became available within the cluster_claim.timeout of 1s"),
+		},
+		{
+			name:      "cancellation is not reported as a timeout",
+			cancelled: true,
+			clusterClaim: &api.ClusterClaim{
+				Product:      api.ReleaseProductOCP,
+				Version:      "4.7.0",
+				Architecture: api.ReleaseArchitectureAMD64,
+				Cloud:        api.CloudAWS,
+				Owner:        "dpp",
+				Timeout:      &prowv1.Duration{Duration: time.Second},
+			},
+			hiveClient: bcc(fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(aClusterPool()).Build(), func(client *clusterClaimStatusSettingClient) {
+				client.namespace = "ci-ocp-4.7.0-amd64-aws-us-east-1-ccx23"
+				client.conditionStatus = corev1.ConditionFalse
+			}),
+			client: loggingclient.New(fakectrlruntimeclient.NewClientBuilder().Build()),
+			jobSpec: &api.JobSpec{
+				JobSpec: downwardapi.JobSpec{
+					ProwJobID: "c2a971b7-947b-11eb-9747-0a580a820213",
+					BuildID:   "1378330119495487488",
+					Job:       "pull-ci-openshift-console-master-images",
+				},
+			},
+			waitForClaim: func(client ctrlruntimeclient.WithWatch, ns, name string, claim *hivev1.ClusterClaim, timeout time.Duration) error {
+				return context.Canceled
+			},
+			expected: &hivev1.ClusterClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "c2a971b7-947b-11eb-9747-0a580a820213",
+					Namespace: "ci-cluster-pool",
+					Labels: map[string]string{
+						"prow.k8s.io/build-id": "1378330119495487488",
+						"prow.k8s.io/job":      "pull-ci-openshift-console-master-images",
+					},
+				},
+				Spec: hivev1.ClusterClaimSpec{
+					ClusterPoolName: "ci-ocp-4.7.0-amd64-aws-us-east-1",
+					Namespace:       "ci-ocp-4.7.0-amd64-aws-us-east-1-ccx23",
+					Lifetime: &metav1.Duration{
+						Duration: 4 * time.Hour,
+					},
+				},
+				Status: hivev1.ClusterClaimStatus{
+					Conditions: []hivev1.ClusterClaimCondition{
+						{
+							Type:   hivev1.ClusterRunningCondition,
+							Status: corev1.ConditionFalse,
+						},
+					},
+				},
+			},
+			expectedError: errors.New("failed to wait for the created cluster claim to become ready: context canceled"),
 		},
 	}
 
@@ -285,7 +341,13 @@ func TestClusterClaimStepAcquireCluster(t *testing.T) {
 			if tc.jobSpec != nil {
 				tc.jobSpec.SetNamespace("ci-op-test")
 			}
-			actual, actualError := s.acquireCluster(context.TODO(), tc.waitForClaim)
+			ctx := context.TODO()
+			if tc.cancelled {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithCancel(ctx)
+				cancel()
+			}
+			actual, actualError := s.acquireCluster(ctx, tc.waitForClaim)
 			if diff := cmp.Diff(tc.expected, actual, testhelper.RuntimeObjectIgnoreRvTypeMeta); diff != "" {
 				t.Errorf("%s: actual does not match expected, diff: %s", tc.name, diff)
 			}
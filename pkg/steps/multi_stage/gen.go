@@ -15,6 +15,7 @@ import (
 	"sigs.k8s.io/prow/pkg/entrypoint"
 
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/junit"
 	base_steps "github.com/openshift/ci-tools/pkg/steps"
 	"github.com/openshift/ci-tools/pkg/steps/utils"
 )
@@ -59,6 +60,16 @@ func defaultGeneratePodOptions() *generatePodOptions {
 	}
 }
 
+// recordSkippedStep reports a step that is not run as a skipped test case
+func (s *multiStageTestStep) recordSkippedStep(name, message string) {
+	s.subLock.Lock()
+	defer s.subLock.Unlock()
+	s.subTests = append(s.subTests, &junit.TestCase{
+		Name:        fmt.Sprintf("%s - %s container %s", s.Description(), name, containerName),
+		SkipMessage: &junit.SkipMessage{Message: message},
+	})
+}
+
 func (s *multiStageTestStep) generatePods(
 	steps []api.LiteralTestStep,
 	env []coreapi.EnvVar,
@@ -85,6 +96,23 @@ func (s *multiStageTestStep) generatePods(
 			logrus.Infof(fmt.Sprintf("Skipping optional step %s", name))
 			continue
 		}
+		if o := step.SkipOnFailure; o != nil && *o && s.flags&hasPrevErrs != 0 {
+			logrus.Infof("Skipping step %s as a previous step failed", name)
+			s.recordSkippedStep(name, "step skipped as a previous step failed")
+			continue
+		}
+		if step.SkipIf != "" {
+			skip, err := api.EvaluateSkipIf(step.SkipIf, s.stepEnvironment(step, env))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to evaluate skip_if for step %s: %w", name, err))
+				continue
+			}
+			if skip {
+				logrus.Infof("Skipping step %s as %q is true", name, step.SkipIf)
+				s.recordSkippedStep(name, fmt.Sprintf("skip_if condition %q is true", step.SkipIf))
+				continue
+			}
+		}
 		image := step.From
 		if link, ok := step.FromImageTag(); ok {
 			image = fmt.Sprintf("%s:%s", api.PipelineImageStream, link)
@@ -113,6 +141,9 @@ func (s *multiStageTestStep) generatePods(
 			return &i
 		}
 		artifactDir := fmt.Sprintf("%s/%s", s.name, step.As)
+		if step.ArtifactDir != "" {
+			artifactDir = fmt.Sprintf("%s/%s", s.name, step.ArtifactDir)
+		}
 		timeout := entrypoint.DefaultTimeout
 		if step.Timeout != nil {
 			timeout = step.Timeout.Duration
@@ -181,6 +212,9 @@ func (s *multiStageTestStep) generatePods(
 			}
 		}
 
+		if step.CollectArtifacts != nil && !*step.CollectArtifacts {
+			discardArtifacts(pod)
+		}
 		addSecretWrapper(pod, s.vpnConf, !needsKubeConfig, genPodOpts)
 		if s.vpnConf != nil {
 			s.addVPNClient(pod)
@@ -229,7 +263,7 @@ func (s *multiStageTestStep) generatePods(
 			addDshmVolume(shmSize, pod, container)
 		}
 		if s.profile != "" {
-			addProfile(s.profileSecretName(), s.profile, pod)
+			addProfile(s.profileSecretName(), s.profile, s.clusterProfiles, pod)
 		}
 		if step.Cli != "" {
 			dependency := api.StepDependency{Name: fmt.Sprintf("%s:cli", api.ReleaseStreamFor(step.Cli))}
@@ -263,6 +297,24 @@ func (s *multiStageTestStep) generatePods(
 	return ret, bestEffortSteps, utilerrors.NewAggregate(errs)
 }
 
+// discardArtifacts points $ARTIFACT_DIR of the step container to a scratch
+// volume, so nothing the step writes there is uploaded by the sidecar.
+func discardArtifacts(pod *coreapi.Pod) {
+	pod.Spec.Volumes = append(pod.Spec.Volumes, coreapi.Volume{
+		Name: discardedArtifactsVolumeName,
+		VolumeSource: coreapi.VolumeSource{
+			EmptyDir: &coreapi.EmptyDirVolumeSource{},
+		},
+	})
+	container := &pod.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, coreapi.VolumeMount{Name: discardedArtifactsVolumeName, MountPath: discardedArtifactsMountPath})
+	for i := range container.Env {
+		if container.Env[i].Name == artifactDirEnv {
+			container.Env[i].Value = discardedArtifactsMountPath
+		}
+	}
+}
+
 func isKubeconfigNeeded(step *api.LiteralTestStep, opts *generatePodOptions) bool {
 	needsKubeconfig := step.NoKubeconfig == nil || !*step.NoKubeconfig
 	return needsKubeconfig || opts.IsObserver
@@ -391,6 +443,19 @@ func (s *multiStageTestStep) generateParams(env []api.StepParameter) []coreapi.E
 	return ret
 }
 
+// stepEnvironment collects the literal values of the environment variables
+// that will be exposed to a step, for use in evaluating its `skip_if`.
+func (s *multiStageTestStep) stepEnvironment(step api.LiteralTestStep, env []coreapi.EnvVar) map[string]string {
+	ret := map[string]string{}
+	for _, e := range env {
+		ret[e.Name] = e.Value
+	}
+	for _, e := range s.generateParams(step.Environment) {
+		ret[e.Name] = e.Value
+	}
+	return ret
+}
+
 func (s *multiStageTestStep) envForDependencies(step api.LiteralTestStep) ([]coreapi.EnvVar, []error) {
 	var env []coreapi.EnvVar
 	var errs []error
@@ -399,6 +464,10 @@ func (s *multiStageTestStep) envForDependencies(step api.LiteralTestStep) ([]cor
 		claimRelease = s.clusterClaim.ClaimRelease(s.name)
 	}
 	for _, dependency := range step.Dependencies {
+		// images produced by previous steps are resolved by envForOutputImages when the step runs
+		if s.isOutputImage(dependency, claimRelease) {
+			continue
+		}
 		var ref string
 		// if a fully-qualified pull spec was provided, then just use that. It'll be up to the job to use that pull spec
 		// correctly as it could possibly point to an external registry that ci-operator will itself not have access to.
@@ -420,6 +489,30 @@ func (s *multiStageTestStep) envForDependencies(step api.LiteralTestStep) ([]cor
 	return env, errs
 }
 
+// envForOutputImages resolves the dependencies of the step on images produced
+// by previous steps, which can only be done once those steps ran.
+func (s *multiStageTestStep) envForOutputImages(step api.LiteralTestStep) ([]coreapi.EnvVar, error) {
+	var env []coreapi.EnvVar
+	var errs []error
+	var claimRelease *api.ClaimRelease
+	if s.clusterClaim != nil {
+		claimRelease = s.clusterClaim.ClaimRelease(s.name)
+	}
+	for _, dependency := range step.Dependencies {
+		if !s.isOutputImage(dependency, claimRelease) {
+			continue
+		}
+		imageStream, name, _ := s.config.DependencyParts(dependency, claimRelease)
+		ref, err := utils.ImageDigestFor(s.client, s.jobSpec.Namespace, imageStream, name)()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not determine image pull spec for image %s produced by a previous step on step %s: %w", dependency.Name, step.As, err))
+			continue
+		}
+		env = append(env, coreapi.EnvVar{Name: dependency.Env, Value: ref})
+	}
+	return env, utilerrors.NewAggregate(errs)
+}
+
 func getClusterClaimPodParams(secretVolumeMounts []coreapi.VolumeMount, testName string) ([]coreapi.EnvVar, []coreapi.VolumeMount, error) {
 	var retEnv []coreapi.EnvVar
 	var retMount []coreapi.VolumeMount
@@ -468,7 +561,7 @@ func addDshmVolume(shmSize *resource.Quantity, pod *coreapi.Pod, container *core
 	})
 }
 
-func addProfile(name string, profile api.ClusterProfile, pod *coreapi.Pod) {
+func addProfile(name string, profile api.ClusterProfile, aliases api.ClusterProfilesMap, pod *coreapi.Pod) {
 	pod.Spec.Volumes = append(pod.Spec.Volumes, coreapi.Volume{
 		Name: profileVolumeName,
 		VolumeSource: coreapi.VolumeSource{
@@ -487,11 +580,15 @@ func addProfile(name string, profile api.ClusterProfile, pod *coreapi.Pod) {
 		Value: profile.Name(),
 	}, {
 		Name:  "CLUSTER_TYPE",
-		Value: profile.ClusterType(),
+		Value: aliases.Base(profile).ClusterType(),
 	}, {
 		Name:  ClusterProfileMountEnv,
 		Value: ClusterProfileMountPath,
 	}}...)
+	aliasEnv := aliases.AliasEnv(profile)
+	for _, name := range sets.List(sets.KeySet(aliasEnv)) {
+		container.Env = append(container.Env, coreapi.EnvVar{Name: name, Value: aliasEnv[name]})
+	}
 }
 
 func addCliInjector(imagestream string, pod *coreapi.Pod) {
@@ -10,18 +10,31 @@ import (
 
 	coreapi "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/diff"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	prowdapi "sigs.k8s.io/prow/pkg/pod-utils/downwardapi"
 
+	imagev1 "github.com/openshift/api/image/v1"
+
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
 	"github.com/openshift/ci-tools/pkg/testhelper"
+	testhelper_kube "github.com/openshift/ci-tools/pkg/testhelper/kubernetes"
 )
 
+func init() {
+	if err := imagev1.AddToScheme(scheme.Scheme); err != nil {
+		panic(fmt.Sprintf("failed to register imagev1 scheme: %v", err))
+	}
+}
+
 func TestGeneratePods(t *testing.T) {
-	yes := true
+	yes, no := true, false
 	nodeArchitectureARM64 := api.NodeArchitectureARM64
 	nodeArchitectureAMD64 := api.NodeArchitectureAMD64
 	config := api.ReleaseBuildConfiguration{
@@ -48,6 +61,10 @@ func TestGeneratePods(t *testing.T) {
 					As: "step4", From: "src", Commands: "command4", NodeArchitecture: &nodeArchitectureARM64,
 				}, {
 					As: "step5", From: "src", Commands: "command5", NodeArchitecture: &nodeArchitectureAMD64,
+				}, {
+					As: "step6", From: "src", Commands: "command6", CollectArtifacts: &no,
+				}, {
+					As: "step7", From: "src", Commands: "command7", ArtifactDir: "custom",
 				}},
 			}},
 		},
@@ -83,7 +100,7 @@ func TestGeneratePods(t *testing.T) {
 		},
 	}
 	jobSpec.SetNamespace("namespace")
-	step := newMultiStageTestStep(config.Tests[0], &config, nil, nil, &jobSpec, nil, "node-name", "", nil)
+	step := newMultiStageTestStep(config.Tests[0], &config, nil, nil, &jobSpec, nil, nil, "node-name", "", nil)
 	step.test[0].Resources = api.ResourceRequirements{
 		Requests: api.ResourceList{api.ShmResource: "2G"},
 		Limits:   api.ResourceList{api.ShmResource: "2G"}}
@@ -161,7 +178,7 @@ func TestGenerateObservers(t *testing.T) {
 		},
 	}
 	jobSpec.SetNamespace("namespace")
-	step := newMultiStageTestStep(config.Tests[0], &config, nil, nil, &jobSpec, nil, "node-name", "", nil)
+	step := newMultiStageTestStep(config.Tests[0], &config, nil, nil, &jobSpec, nil, nil, "node-name", "", nil)
 	ret, err := step.generateObservers(observers, nil, nil, nil)
 	if err != nil {
 		t.Fatal(err)
@@ -235,7 +252,7 @@ func TestGeneratePodsEnvironment(t *testing.T) {
 					Test:        test,
 					Environment: tc.env,
 				},
-			}, &api.ReleaseBuildConfiguration{}, nil, nil, &jobSpec, nil, "node-name", "", nil)
+			}, &api.ReleaseBuildConfiguration{}, nil, nil, &jobSpec, nil, nil, "node-name", "", nil)
 			pods, _, err := step.(*multiStageTestStep).generatePods(test, nil, nil, nil, nil)
 			if err != nil {
 				t.Fatal(err)
@@ -303,7 +320,7 @@ func TestGeneratePodBestEffort(t *testing.T) {
 		},
 	}
 	jobSpec.SetNamespace("namespace")
-	step := newMultiStageTestStep(config.Tests[0], &config, nil, nil, &jobSpec, nil, "node-name", "", nil)
+	step := newMultiStageTestStep(config.Tests[0], &config, nil, nil, &jobSpec, nil, nil, "node-name", "", nil)
 	_, bestEffortSteps, err := step.generatePods(config.Tests[0].MultiStageTestConfigurationLiteral.Post, nil, nil, nil, nil)
 	if err != nil {
 		t.Fatal(err)
@@ -487,3 +504,195 @@ func TestSetSecurityContexts(t *testing.T) {
 		})
 	}
 }
+
+func TestGeneratePodSkipIf(t *testing.T) {
+	config := api.ReleaseBuildConfiguration{
+		Tests: []api.TestStepConfiguration{{
+			As: "test",
+			MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+				Environment: api.TestEnvironment{"FIPS_ENABLED": "true"},
+				Test: []api.LiteralTestStep{{
+					As:          "step0",
+					From:        "src",
+					Commands:    "command0",
+					Environment: []api.StepParameter{{Name: "FIPS_ENABLED"}},
+					SkipIf:      "FIPS_ENABLED == true",
+				}, {
+					As:          "step1",
+					From:        "src",
+					Commands:    "command1",
+					Environment: []api.StepParameter{{Name: "FIPS_ENABLED"}},
+					SkipIf:      "FIPS_ENABLED != true",
+				}, {
+					As:       "step2",
+					From:     "src",
+					Commands: "command2",
+					SkipIf:   "CLUSTER_TYPE == aws",
+				}},
+			},
+		}},
+	}
+	jobSpec := api.JobSpec{
+		JobSpec: prowdapi.JobSpec{
+			Job:       "job",
+			BuildID:   "build id",
+			ProwJobID: "prow job id",
+			Refs: &prowapi.Refs{
+				Org:     "org",
+				Repo:    "repo",
+				BaseRef: "base ref",
+				BaseSHA: "base sha",
+			},
+			Type: "postsubmit",
+			DecorationConfig: &prowapi.DecorationConfig{
+				Timeout:     &prowapi.Duration{Duration: time.Minute},
+				GracePeriod: &prowapi.Duration{Duration: time.Second},
+				UtilityImages: &prowapi.UtilityImages{
+					Sidecar:    "sidecar",
+					Entrypoint: "entrypoint",
+				},
+			},
+		},
+	}
+	jobSpec.SetNamespace("namespace")
+	step := newMultiStageTestStep(config.Tests[0], &config, nil, nil, &jobSpec, nil, nil, "node-name", "", nil)
+	env := []coreapi.EnvVar{{Name: "CLUSTER_TYPE", Value: "aws"}}
+	pods, _, err := step.generatePods(config.Tests[0].MultiStageTestConfigurationLiteral.Test, env, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	if diff := cmp.Diff([]string{"test-step1"}, names); diff != "" {
+		t.Errorf("unexpected pods: %s", diff)
+	}
+	var skipped []string
+	for _, test := range step.SubTests() {
+		if test.SkipMessage != nil {
+			skipped = append(skipped, test.Name)
+		}
+	}
+	expected := []string{
+		"Run multi-stage test test - test-step0 container test",
+		"Run multi-stage test test - test-step2 container test",
+	}
+	if diff := cmp.Diff(expected, skipped); diff != "" {
+		t.Errorf("unexpected skipped tests: %s", diff)
+	}
+}
+
+func TestAddProfile(t *testing.T) {
+	aliases := api.ClusterProfilesMap{
+		"aws-restricted": {
+			Profile:     "aws-restricted",
+			BaseProfile: api.ClusterProfileAWS,
+			Env:         map[string]string{"RESTRICTED": "true", "REGION": "us-east-2"},
+		},
+	}
+	for _, tc := range []struct {
+		name     string
+		profile  api.ClusterProfile
+		expected []coreapi.EnvVar
+	}{{
+		name:    "built-in profile",
+		profile: api.ClusterProfileGCP,
+		expected: []coreapi.EnvVar{
+			{Name: "CLUSTER_PROFILE_NAME", Value: "gcp"},
+			{Name: "CLUSTER_TYPE", Value: "gcp"},
+			{Name: ClusterProfileMountEnv, Value: ClusterProfileMountPath},
+		},
+	}, {
+		name:    "alias exposes the type of its base and its env",
+		profile: "aws-restricted",
+		expected: []coreapi.EnvVar{
+			{Name: "CLUSTER_PROFILE_NAME", Value: "aws-restricted"},
+			{Name: "CLUSTER_TYPE", Value: "aws"},
+			{Name: ClusterProfileMountEnv, Value: ClusterProfileMountPath},
+			{Name: "REGION", Value: "us-east-2"},
+			{Name: "RESTRICTED", Value: "true"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &coreapi.Pod{Spec: coreapi.PodSpec{Containers: []coreapi.Container{{Name: "test"}}}}
+			addProfile("secret", tc.profile, aliases, pod)
+			if diff := cmp.Diff(tc.expected, pod.Spec.Containers[0].Env); diff != "" {
+				t.Errorf("env differs from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func pipelineImageStream(namespace string, tags ...string) *imagev1.ImageStream {
+	is := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: api.PipelineImageStream, Namespace: namespace},
+		Status:     imagev1.ImageStreamStatus{DockerImageRepository: "registry.ci/" + namespace + "/pipeline"},
+	}
+	for _, tag := range tags {
+		is.Status.Tags = append(is.Status.Tags, imagev1.NamedTagEventList{
+			Tag:   tag,
+			Items: []imagev1.TagEvent{{Image: "sha256:" + tag}},
+		})
+	}
+	return is
+}
+
+func TestEnvForOutputImages(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		outputImages sets.Set[string]
+		dependencies []api.StepDependency
+		expected     []coreapi.EnvVar
+		expectedErr  error
+	}{{
+		name:         "no dependencies",
+		outputImages: sets.New[string]("produced"),
+	}, {
+		name:         "dependency on an image produced by a previous step is resolved",
+		outputImages: sets.New[string]("produced"),
+		dependencies: []api.StepDependency{{Name: "pipeline:produced", Env: "PRODUCED"}},
+		expected:     []coreapi.EnvVar{{Name: "PRODUCED", Value: "registry.ci/ns/pipeline@sha256:produced"}},
+	}, {
+		name:         "other dependencies are left to the pod generation",
+		outputImages: sets.New[string]("produced"),
+		dependencies: []api.StepDependency{
+			{Name: "pipeline:src", Env: "SRC"},
+			{Name: "pipeline:produced", Env: "PRODUCED"},
+			{Name: "pipeline:bin", PullSpec: "quay.io/org/bin:latest", Env: "BIN"},
+		},
+		expected: []coreapi.EnvVar{{Name: "PRODUCED", Value: "registry.ci/ns/pipeline@sha256:produced"}},
+	}, {
+		name:         "image that was not produced is an error",
+		outputImages: sets.New[string]("produced", "missing"),
+		dependencies: []api.StepDependency{{Name: "pipeline:missing", Env: "MISSING"}},
+		expectedErr:  fmt.Errorf(`could not determine image pull spec for image pipeline:missing produced by a previous step on step step: image stream "pipeline" has no tag "missing" in spec or status`),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			jobSpec := api.JobSpec{}
+			jobSpec.SetNamespace("ns")
+			client := &testhelper_kube.FakePodClient{
+				FakePodExecutor: &testhelper_kube.FakePodExecutor{
+					LoggingClient: loggingclient.New(
+						fakectrlruntimeclient.NewClientBuilder().
+							WithObjects(pipelineImageStream("ns", "src", "produced")).
+							Build()),
+				},
+			}
+			s := &multiStageTestStep{
+				name:         "test",
+				config:       &api.ReleaseBuildConfiguration{},
+				client:       client,
+				jobSpec:      &jobSpec,
+				outputImages: tc.outputImages,
+			}
+			env, err := s.envForOutputImages(api.LiteralTestStep{As: "step", Dependencies: tc.dependencies})
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("error differs from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, env); diff != "" {
+				t.Errorf("env differs from expected:\n%s", diff)
+			}
+		})
+	}
+}
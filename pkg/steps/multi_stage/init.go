@@ -133,6 +133,19 @@ func (s *multiStageTestStep) setupRBAC(ctx context.Context) error {
 			Verbs:     []string{"get"},
 		}},
 	}
+	if s.outputImages.Len() > 0 {
+		// steps producing images tag or push them into the pipeline image stream
+		role.Rules = append(role.Rules, rbacapi.PolicyRule{
+			APIGroups: []string{"", "image.openshift.io"},
+			Resources: []string{"imagestreamtags"},
+			Verbs:     []string{"create", "update"},
+		}, rbacapi.PolicyRule{
+			APIGroups:     []string{"", "image.openshift.io"},
+			Resources:     []string{"imagestreams/layers"},
+			ResourceNames: []string{api.PipelineImageStream},
+			Verbs:         []string{"update"},
+		})
+	}
 	subj := []rbacapi.Subject{{Kind: "ServiceAccount", Name: s.name}}
 	bindings := []rbacapi.RoleBinding{
 		{
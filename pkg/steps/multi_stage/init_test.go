@@ -1,9 +1,21 @@
 package multi_stage
 
 import (
+	"context"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	rbacapi "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
 	"github.com/openshift/ci-tools/pkg/testhelper"
+	testhelper_kube "github.com/openshift/ci-tools/pkg/testhelper/kubernetes"
 )
 
 func TestParseNamespaceUID(t *testing.T) {
@@ -37,3 +49,66 @@ func TestParseNamespaceUID(t *testing.T) {
 		})
 	}
 }
+
+func TestSetupRBACForOutputImages(t *testing.T) {
+	rules := []rbacapi.PolicyRule{{
+		APIGroups: []string{"rbac.authorization.k8s.io"},
+		Resources: []string{"rolebindings", "roles"},
+		Verbs:     []string{"create", "list"},
+	}, {
+		APIGroups:     []string{""},
+		Resources:     []string{"secrets"},
+		ResourceNames: []string{"test"},
+		Verbs:         []string{"get", "update"},
+	}, {
+		APIGroups: []string{"", "image.openshift.io"},
+		Resources: []string{"imagestreams/layers"},
+		Verbs:     []string{"get"},
+	}}
+	for _, tc := range []struct {
+		name     string
+		steps    []api.LiteralTestStep
+		expected []rbacapi.PolicyRule
+	}{{
+		name:     "no step produces an image",
+		steps:    []api.LiteralTestStep{{As: "test0"}},
+		expected: rules,
+	}, {
+		name:  "a step produces an image",
+		steps: []api.LiteralTestStep{{As: "test0", OutputImage: "produced"}, {As: "test1"}},
+		expected: append(rules, rbacapi.PolicyRule{
+			APIGroups: []string{"", "image.openshift.io"},
+			Resources: []string{"imagestreamtags"},
+			Verbs:     []string{"create", "update"},
+		}, rbacapi.PolicyRule{
+			APIGroups:     []string{"", "image.openshift.io"},
+			Resources:     []string{"imagestreams/layers"},
+			ResourceNames: []string{"pipeline"},
+			Verbs:         []string{"update"},
+		}),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			// an existing service account skips waiting for its pull secrets
+			sa := &coreapi.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-namespace"}}
+			client := &testhelper_kube.FakePodClient{FakePodExecutor: &testhelper_kube.FakePodExecutor{
+				LoggingClient: loggingclient.New(fakectrlruntimeclient.NewClientBuilder().WithObjects(sa).Build()),
+			}}
+			jobSpec := api.JobSpec{}
+			jobSpec.SetNamespace("test-namespace")
+			step := MultiStageTestStep(api.TestStepConfiguration{
+				As:                                 "test",
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{Test: tc.steps},
+			}, &api.ReleaseBuildConfiguration{}, nil, client, &jobSpec, nil, nil, "node-name", "", nil)
+			if err := step.(*multiStageTestStep).setupRBAC(context.Background()); err != nil {
+				t.Fatalf("failed to set up RBAC: %v", err)
+			}
+			role := &rbacapi.Role{}
+			if err := client.Get(context.Background(), types.NamespacedName{Namespace: "test-namespace", Name: "test"}, role); err != nil {
+				t.Fatalf("failed to get role: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, role.Rules); diff != "" {
+				t.Errorf("rules of the role differ from expected: %s", diff)
+			}
+		})
+	}
+}
@@ -74,6 +74,10 @@ const (
 	// CommandScriptMountPath is where we mount the command script
 	CommandScriptMountPath = "/var/run/configmaps/ci.openshift.io/multi-stage"
 	homeVolumeName         = "home"
+	// discardedArtifactsVolumeName is the scratch volume $ARTIFACT_DIR points to when the artifacts of a step are not collected
+	discardedArtifactsVolumeName = "discarded-artifacts"
+	discardedArtifactsMountPath  = "/tmp/discarded-artifacts"
+	artifactDirEnv               = "ARTIFACT_DIR"
 	// vpnConfPath is the path of the configuration file in the cluster profile.
 	vpnConfPath = "vpn.yaml"
 )
@@ -90,17 +94,21 @@ type multiStageTestStep struct {
 	profile          api.ClusterProfile
 	config           *api.ReleaseBuildConfiguration
 	// params exposes getters for variables created by other steps
-	params           api.Parameters
-	env              api.TestEnvironment
-	client           kubernetes.PodClient
-	jobSpec          *api.JobSpec
-	observers        []api.Observer
-	pre, test, post  []api.LiteralTestStep
-	subLock          *sync.Mutex
-	subTests         []*junit.TestCase
-	subSteps         []api.CIOperatorStepDetailInfo
-	flags            stepFlag
-	leases           []api.StepLease
+	params          api.Parameters
+	env             api.TestEnvironment
+	client          kubernetes.PodClient
+	jobSpec         *api.JobSpec
+	observers       []api.Observer
+	pre, test, post []api.LiteralTestStep
+	// outputImages are the names of the images produced by the steps
+	outputImages sets.Set[string]
+	subLock      *sync.Mutex
+	subTests     []*junit.TestCase
+	subSteps     []api.CIOperatorStepDetailInfo
+	flags        stepFlag
+	leases       []api.StepLease
+	// clusterProfiles holds the aliases the profile may be one of
+	clusterProfiles  api.ClusterProfilesMap
 	clusterClaim     *api.ClusterClaim
 	vpnConf          *vpnConf
 	cancelObservers  func(context.CancelFunc)
@@ -114,11 +122,12 @@ func MultiStageTestStep(
 	client kubernetes.PodClient,
 	jobSpec *api.JobSpec,
 	leases []api.StepLease,
+	clusterProfiles api.ClusterProfilesMap,
 	nodeName string,
 	targetAdditionalSuffix string,
 	cancelObservers func(context.CancelFunc),
 ) api.Step {
-	return newMultiStageTestStep(testConfig, config, params, client, jobSpec, leases, nodeName, targetAdditionalSuffix, cancelObservers)
+	return newMultiStageTestStep(testConfig, config, params, client, jobSpec, leases, clusterProfiles, nodeName, targetAdditionalSuffix, cancelObservers)
 }
 
 func newMultiStageTestStep(
@@ -128,6 +137,7 @@ func newMultiStageTestStep(
 	client kubernetes.PodClient,
 	jobSpec *api.JobSpec,
 	leases []api.StepLease,
+	clusterProfiles api.ClusterProfilesMap,
 	nodeName string,
 	targetAdditionalSuffix string,
 	cancelObservers func(context.CancelFunc),
@@ -140,6 +150,14 @@ func newMultiStageTestStep(
 	if p := ms.AllowBestEffortPostSteps; p != nil && *p {
 		flags |= allowBestEffortPostSteps
 	}
+	outputImages := sets.New[string]()
+	for _, steps := range [][]api.LiteralTestStep{ms.Pre, ms.Test, ms.Post} {
+		for _, step := range steps {
+			if step.OutputImage != "" {
+				outputImages.Insert(step.OutputImage)
+			}
+		}
+	}
 	return &multiStageTestStep{
 		name:             testConfig.As,
 		additionalSuffix: targetAdditionalSuffix,
@@ -154,8 +172,10 @@ func newMultiStageTestStep(
 		pre:              ms.Pre,
 		test:             ms.Test,
 		post:             ms.Post,
+		outputImages:     outputImages,
 		flags:            flags,
 		leases:           leases,
+		clusterProfiles:  clusterProfiles,
 		clusterClaim:     testConfig.ClusterClaim,
 		subLock:          &sync.Mutex{},
 		cancelObservers:  cancelObservers,
@@ -279,6 +299,10 @@ func (s *multiStageTestStep) Requires() (ret []api.StepLink) {
 			if dependency.PullSpec != "" {
 				continue
 			}
+			// images produced by steps of this test do not exist before the test runs
+			if s.isOutputImage(dependency, claimRelease) {
+				continue
+			}
 
 			// we validate that the link will exist at config load time
 			// so we can safely ignore the case where !ok
@@ -310,6 +334,16 @@ func (s *multiStageTestStep) Requires() (ret []api.StepLink) {
 	return
 }
 
+// isOutputImage determines whether the dependency references an image that
+// is produced by a step of this test
+func (s *multiStageTestStep) isOutputImage(dependency api.StepDependency, claimRelease *api.ClaimRelease) bool {
+	if dependency.PullSpec != "" {
+		return false
+	}
+	stream, name, explicit := s.config.DependencyParts(dependency, claimRelease)
+	return explicit && stream == api.PipelineImageStream && s.outputImages.Has(name)
+}
+
 func (s *multiStageTestStep) Creates() []api.StepLink { return nil }
 func (s *multiStageTestStep) Provides() api.ParameterMap {
 	return nil
@@ -65,6 +65,16 @@ func TestRequires(t *testing.T) {
 			api.InternalImageLink(
 				api.PipelineImageStreamTagReferenceSource),
 		},
+	}, {
+		name: "step needs image produced by a previous step, should not have InternalImageLink",
+		steps: api.MultiStageTestConfigurationLiteral{
+			Pre:  []api.LiteralTestStep{{From: "pipeline:src", OutputImage: "produced"}},
+			Test: []api.LiteralTestStep{{From: "pipeline:src", Dependencies: []api.StepDependency{{Name: "pipeline:produced", Env: "PRODUCED"}}}},
+		},
+		req: []api.StepLink{
+			api.InternalImageLink(api.PipelineImageStreamTagReferenceSource),
+			api.InternalImageLink(api.PipelineImageStreamTagReferenceSource),
+		},
 	}, {
 		name: "step needs pipeline image explicitly, should have InternalImageLink",
 		steps: api.MultiStageTestConfigurationLiteral{
@@ -80,7 +90,7 @@ func TestRequires(t *testing.T) {
 				As:                                 "some-e2e",
 				ClusterClaim:                       tc.clusterClaim,
 				MultiStageTestConfigurationLiteral: &tc.steps,
-			}, &tc.config, api.NewDeferredParameters(nil), nil, nil, nil, "node-name", "", nil)
+			}, &tc.config, api.NewDeferredParameters(nil), nil, nil, nil, nil, "node-name", "", nil)
 			ret := step.Requires()
 			if len(ret) == len(tc.req) {
 				matches := true
@@ -44,7 +44,7 @@ func (s *multiStageTestStep) runSteps(
 			s.flags |= hasPrevErrs
 		}
 	}()
-	if err := s.runPods(ctx, pods, bestEffortSteps); err != nil {
+	if err := s.runPods(ctx, pods, steps, bestEffortSteps); err != nil {
 		errs = append(errs, err)
 	}
 	select {
@@ -79,10 +79,20 @@ func (s *multiStageTestStep) runSteps(
 	return err
 }
 
-func (s *multiStageTestStep) runPods(ctx context.Context, pods []coreapi.Pod, bestEffortSteps sets.Set[string]) error {
+func (s *multiStageTestStep) runPods(ctx context.Context, pods []coreapi.Pod, steps []api.LiteralTestStep, bestEffortSteps sets.Set[string]) error {
+	stepsByPod := map[string]api.LiteralTestStep{}
+	for _, step := range steps {
+		stepsByPod[fmt.Sprintf("%s-%s", s.name, step.As)] = step
+	}
 	var errs []error
 	for _, pod := range pods {
-		err := s.runPod(ctx, &pod, base_steps.NewTestCaseNotifier(util.NopNotifier), util.WaitForPodFlag(0))
+		var err error
+		if env, envErr := s.envForOutputImages(stepsByPod[pod.Name]); envErr != nil {
+			err = envErr
+		} else {
+			pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, env...)
+			err = s.runPod(ctx, &pod, base_steps.NewTestCaseNotifier(util.NopNotifier), util.WaitForPodFlag(0))
+		}
 		if err == nil {
 			continue
 		}
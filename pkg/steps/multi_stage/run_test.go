@@ -125,7 +125,7 @@ func TestRun(t *testing.T) {
 					Observers:          tc.observers,
 					AllowSkipOnSuccess: &yes,
 				},
-			}, &api.ReleaseBuildConfiguration{}, nil, client, &jobSpec, nil, "node-name", "", func(cf context.CancelFunc) {})
+			}, &api.ReleaseBuildConfiguration{}, nil, client, &jobSpec, nil, nil, "node-name", "", func(cf context.CancelFunc) {})
 
 			// An Observer pod failure doesn't make the test fail
 			failures := tc.failures.Delete(observerPodNames.UnsortedList()...)
@@ -261,7 +261,7 @@ func TestJUnit(t *testing.T) {
 					Test: []api.LiteralTestStep{{As: "test0"}, {As: "test1"}},
 					Post: []api.LiteralTestStep{{As: "post0"}, {As: "post1"}},
 				},
-			}, &api.ReleaseBuildConfiguration{}, nil, client, &jobSpec, nil, "node-name", "", nil)
+			}, &api.ReleaseBuildConfiguration{}, nil, client, &jobSpec, nil, nil, "node-name", "", nil)
 			if err := step.Run(context.Background()); tc.failures == nil && err != nil {
 				t.Error(err)
 				return
@@ -277,6 +277,185 @@ func TestJUnit(t *testing.T) {
 	}
 }
 
+func TestSkipOnFailure(t *testing.T) {
+	yes := true
+	for _, tc := range []struct {
+		name     string
+		failures sets.Set[string]
+		expected []string
+		skipped  sets.Set[string]
+	}{{
+		name: "no step fails, post step runs",
+		expected: []string{
+			"test-pre0",
+			"test-test0",
+			"test-post0", "test-post1",
+		},
+	}, {
+		name:     "failure in a pre step, post step is skipped",
+		failures: sets.New[string]("test-pre0"),
+		expected: []string{
+			"test-pre0",
+			"test-post0",
+		},
+		skipped: sets.New[string]("Run multi-stage test test - test-post1 container test"),
+	}, {
+		name:     "failure in a test step, post step is skipped",
+		failures: sets.New[string]("test-test0"),
+		expected: []string{
+			"test-pre0",
+			"test-test0",
+			"test-post0",
+		},
+		skipped: sets.New[string]("Run multi-stage test test - test-post1 container test"),
+	}, {
+		name:     "failure in a post step, later post step still runs",
+		failures: sets.New[string]("test-post0"),
+		expected: []string{
+			"test-pre0",
+			"test-test0",
+			"test-post0", "test-post1",
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			sa := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-namespace", Labels: map[string]string{"ci.openshift.io/multi-stage-test": "test"}}}
+
+			crclient := &testhelper_kube.FakePodExecutor{
+				LoggingClient: loggingclient.New(
+					fakectrlruntimeclient.NewClientBuilder().
+						WithIndex(&v1.Pod{}, "metadata.name", fakePodNameIndexer).
+						WithObjects(sa).
+						Build()),
+				Failures: tc.failures,
+			}
+			jobSpec := api.JobSpec{
+				JobSpec: prowdapi.JobSpec{
+					Job:       "job",
+					BuildID:   "build_id",
+					ProwJobID: "prow_job_id",
+					Type:      prowapi.PeriodicJob,
+					DecorationConfig: &prowapi.DecorationConfig{
+						Timeout:     &prowapi.Duration{Duration: time.Minute},
+						GracePeriod: &prowapi.Duration{Duration: time.Second},
+						UtilityImages: &prowapi.UtilityImages{
+							Sidecar:    "sidecar",
+							Entrypoint: "entrypoint",
+						},
+					},
+				},
+			}
+			jobSpec.SetNamespace("test-namespace")
+			client := &testhelper_kube.FakePodClient{FakePodExecutor: crclient}
+			step := MultiStageTestStep(api.TestStepConfiguration{
+				As: "test",
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					Pre:  []api.LiteralTestStep{{As: "pre0"}},
+					Test: []api.LiteralTestStep{{As: "test0"}},
+					Post: []api.LiteralTestStep{{As: "post0"}, {As: "post1", SkipOnFailure: &yes}},
+				},
+			}, &api.ReleaseBuildConfiguration{}, nil, client, &jobSpec, nil, nil, "node-name", "", nil)
+			if err := step.Run(context.Background()); (err != nil) != (tc.failures != nil) {
+				t.Errorf("expected error: %t, got error: %v", tc.failures != nil, err)
+			}
+			var names []string
+			for _, pod := range crclient.CreatedPods {
+				names = append(names, pod.Name)
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("did not execute correct pods: %s", diff)
+			}
+			skipped := sets.New[string]()
+			for _, testCase := range step.(steps.SubtestReporter).SubTests() {
+				if testCase.SkipMessage != nil {
+					skipped.Insert(testCase.Name)
+				}
+			}
+			if diff := cmp.Diff(sets.List(tc.skipped), sets.List(skipped)); diff != "" {
+				t.Errorf("skipped test cases differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestRunOutputImageDependencies(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		objects     []ctrlruntimeclient.Object
+		expectErr   bool
+		expected    []string
+		expectedEnv []v1.EnvVar
+	}{{
+		name:     "image produced by a pre step is passed to the test step",
+		objects:  []ctrlruntimeclient.Object{pipelineImageStream("test-namespace", "produced")},
+		expected: []string{"test-pre0", "test-test0"},
+		expectedEnv: []v1.EnvVar{
+			{Name: "PRODUCED", Value: "registry.ci/test-namespace/pipeline@sha256:produced"},
+		},
+	}, {
+		name:      "image that was not produced fails the test step without running it",
+		expectErr: true,
+		expected:  []string{"test-pre0"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			sa := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-namespace", Labels: map[string]string{"ci.openshift.io/multi-stage-test": "test"}}}
+			crclient := &testhelper_kube.FakePodExecutor{
+				LoggingClient: loggingclient.New(
+					fakectrlruntimeclient.NewClientBuilder().
+						WithIndex(&v1.Pod{}, "metadata.name", fakePodNameIndexer).
+						WithObjects(append(tc.objects, sa)...).
+						Build()),
+			}
+			jobSpec := api.JobSpec{
+				JobSpec: prowdapi.JobSpec{
+					Job:       "job",
+					BuildID:   "build_id",
+					ProwJobID: "prow_job_id",
+					Type:      prowapi.PeriodicJob,
+					DecorationConfig: &prowapi.DecorationConfig{
+						Timeout:     &prowapi.Duration{Duration: time.Minute},
+						GracePeriod: &prowapi.Duration{Duration: time.Second},
+						UtilityImages: &prowapi.UtilityImages{
+							Sidecar:    "sidecar",
+							Entrypoint: "entrypoint",
+						},
+					},
+				},
+			}
+			jobSpec.SetNamespace("test-namespace")
+			client := &testhelper_kube.FakePodClient{FakePodExecutor: crclient}
+			step := MultiStageTestStep(api.TestStepConfiguration{
+				As: "test",
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					Pre:  []api.LiteralTestStep{{As: "pre0", OutputImage: "produced"}},
+					Test: []api.LiteralTestStep{{As: "test0", Dependencies: []api.StepDependency{{Name: "pipeline:produced", Env: "PRODUCED"}}}},
+				},
+			}, &api.ReleaseBuildConfiguration{}, nil, client, &jobSpec, nil, nil, "node-name", "", nil)
+			if err := step.Run(context.Background()); (err != nil) != tc.expectErr {
+				t.Errorf("expected error: %t, got error: %v", tc.expectErr, err)
+			}
+			var names []string
+			var env []v1.EnvVar
+			for _, pod := range crclient.CreatedPods {
+				names = append(names, pod.Name)
+				if pod.Name != "test-test0" {
+					continue
+				}
+				for _, e := range pod.Spec.Containers[0].Env {
+					if e.Name == "PRODUCED" {
+						env = append(env, e)
+					}
+				}
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("did not execute correct pods: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedEnv, env); diff != "" {
+				t.Errorf("env of the test step differs from expected: %s", diff)
+			}
+		})
+	}
+}
+
 func fakePodNameIndexer(object ctrlruntimeclient.Object) []string {
 	p, ok := object.(*v1.Pod)
 	if !ok {
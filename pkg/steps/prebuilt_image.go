@@ -0,0 +1,130 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/results"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+	"github.com/openshift/ci-tools/pkg/steps/utils"
+)
+
+// prebuiltImageStep stands in for a step that builds a pipeline image: instead of
+// building it, the image already built into the pipeline image stream of another
+// namespace is tagged into the pipeline.
+type prebuiltImageStep struct {
+	step      api.Step
+	to        api.PipelineImageStreamTagReference
+	namespace string
+	client    loggingclient.LoggingClient
+	jobSpec   *api.JobSpec
+
+	imageName string
+}
+
+// PrebuiltImageStep substitutes the given step, which builds the pipeline image to, with
+// one that tags the same image from the pipeline image stream in namespace.
+func PrebuiltImageStep(step api.Step, to api.PipelineImageStreamTagReference, namespace string, client loggingclient.LoggingClient, jobSpec *api.JobSpec) api.Step {
+	return &prebuiltImageStep{
+		step:      step,
+		to:        to,
+		namespace: namespace,
+		client:    client,
+		jobSpec:   jobSpec,
+	}
+}
+
+var _ api.Step = &prebuiltImageStep{}
+
+func (s *prebuiltImageStep) Inputs() (api.InputDefinition, error) {
+	if len(s.imageName) > 0 {
+		return api.InputDefinition{s.imageName}, nil
+	}
+	from := imagev1.ImageStreamTag{}
+	name := fmt.Sprintf("%s:%s", api.PipelineImageStream, s.to)
+	if err := s.client.Get(context.TODO(), ctrlruntimeclient.ObjectKey{Namespace: s.namespace, Name: name}, &from); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, fmt.Errorf("image %s is required but was not found in the pre-built images namespace %s", s.to, s.namespace)
+		}
+		return nil, fmt.Errorf("could not resolve pre-built image %s/%s: %w", s.namespace, name, err)
+	}
+	logrus.Debugf("Resolved pre-built image %s/%s to %s.", s.namespace, name, from.Image.Name)
+	s.imageName = from.Image.Name
+	return api.InputDefinition{s.imageName}, nil
+}
+
+func (*prebuiltImageStep) Validate() error { return nil }
+
+func (s *prebuiltImageStep) Run(ctx context.Context) error {
+	return results.ForReason("tagging_prebuilt_image").ForError(s.run(ctx))
+}
+
+func (s *prebuiltImageStep) run(ctx context.Context) error {
+	logrus.Infof("Tagging pre-built image %s/%s:%s into %s:%s.", s.namespace, api.PipelineImageStream, s.to, api.PipelineImageStream, s.to)
+	if _, err := s.Inputs(); err != nil {
+		return err
+	}
+	ist := &imagev1.ImageStreamTag{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s:%s", api.PipelineImageStream, s.to),
+			Namespace: s.jobSpec.Namespace(),
+		},
+		Tag: &imagev1.TagReference{
+			ReferencePolicy: imagev1.TagReferencePolicy{
+				Type: imagev1.LocalTagReferencePolicy,
+			},
+			From: &coreapi.ObjectReference{
+				Kind:      "ImageStreamImage",
+				Name:      fmt.Sprintf("%s@%s", api.PipelineImageStream, s.imageName),
+				Namespace: s.namespace,
+			},
+			ImportPolicy: imagev1.TagImportPolicy{
+				ImportMode: imagev1.ImportModePreserveOriginal,
+			},
+		},
+	}
+	if err := s.client.Create(ctx, ist); err != nil && !kerrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create imagestreamtag for pre-built image: %w", err)
+	}
+	if err := waitForTagInSpec(ctx, s.client, s.jobSpec.Namespace(), api.PipelineImageStream, string(s.to), 3*time.Minute); err != nil {
+		return fmt.Errorf("failed to wait for the tag %s to show in the spec of imagestream %s/%s", s.to, s.jobSpec.Namespace(), api.PipelineImageStream)
+	}
+	if err := utils.WaitForImportingISTag(ctx, s.client, s.jobSpec.Namespace(), api.PipelineImageStream, nil, sets.New[string](string(s.to)), utils.DefaultImageImportTimeout); err != nil {
+		return fmt.Errorf("failed to wait for importing imagestreamtags on %s/%s:%s: %w", s.jobSpec.Namespace(), api.PipelineImageStream, s.to, err)
+	}
+	return nil
+}
+
+func (s *prebuiltImageStep) Name() string { return s.step.Name() }
+
+func (s *prebuiltImageStep) Description() string {
+	return fmt.Sprintf("Tag the image %s pre-built in namespace %s into the pipeline instead of building it", s.to, s.namespace)
+}
+
+func (s *prebuiltImageStep) Requires() []api.StepLink {
+	return nil
+}
+
+func (s *prebuiltImageStep) Creates() []api.StepLink {
+	return s.step.Creates()
+}
+
+func (s *prebuiltImageStep) Provides() api.ParameterMap {
+	return s.step.Provides()
+}
+
+func (s *prebuiltImageStep) Objects() []ctrlruntimeclient.Object {
+	return s.client.Objects()
+}
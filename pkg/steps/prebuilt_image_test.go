@@ -0,0 +1,135 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestPrebuiltImageStep(t *testing.T) {
+	client := loggingclient.New(fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(
+		&imagev1.ImageStream{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "target-namespace",
+				Name:      api.PipelineImageStream,
+			},
+			Spec: imagev1.ImageStreamSpec{
+				LookupPolicy: imagev1.ImageLookupPolicy{Local: true},
+				Tags: []imagev1.TagReference{
+					{Name: "TO"},
+				},
+			},
+			Status: imagev1.ImageStreamStatus{
+				PublicDockerImageRepository: "some-reg/target-namespace/pipeline",
+				Tags: []imagev1.NamedTagEventList{
+					{
+						Tag: "TO",
+						Items: []imagev1.TagEvent{
+							{
+								Image: "sha256:47e2f82dbede8ff990e6e240f82d78830e7558f7b30df7bd8c0693992018b1e3",
+							},
+						},
+					},
+				},
+			},
+		},
+		&imagev1.ImageStreamTag{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pipeline:TO",
+				Namespace: "prebuilt-namespace",
+			},
+			Image: imagev1.Image{ObjectMeta: metav1.ObjectMeta{Name: "sha256:ddc0de"}},
+		}).Build())
+
+	jobspec := &api.JobSpec{}
+	jobspec.SetNamespace("target-namespace")
+	build := InputEnvironmentStep("TO", nil, []api.StepLink{api.InternalImageLink("TO")})
+	step := PrebuiltImageStep(build, "TO", "prebuilt-namespace", client, jobspec)
+
+	specification := stepExpectation{
+		name:     "TO",
+		requires: nil,
+		creates:  []api.StepLink{api.InternalImageLink("TO")},
+		provides: providesExpectation{
+			params: nil,
+		},
+		inputs: inputsExpectation{
+			values: api.InputDefinition{"sha256:ddc0de"},
+			err:    false,
+		},
+	}
+
+	execSpecification := executionExpectation{
+		prerun: doneExpectation{
+			value: false,
+			err:   false,
+		},
+		runError: false,
+		postrun: doneExpectation{
+			value: true,
+			err:   false,
+		},
+	}
+
+	examineStep(t, step, specification)
+	executeStep(t, step, execSpecification)
+
+	expectedImageStreamTag := &imagev1.ImageStreamTag{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "pipeline:TO",
+			Namespace:       jobspec.Namespace(),
+			ResourceVersion: "1",
+		},
+		Tag: &imagev1.TagReference{
+			From: &corev1.ObjectReference{
+				Kind:      "ImageStreamImage",
+				Name:      "pipeline@sha256:ddc0de",
+				Namespace: "prebuilt-namespace",
+			},
+			ImportPolicy: imagev1.TagImportPolicy{
+				ImportMode: imagev1.ImportModePreserveOriginal,
+			},
+			ReferencePolicy: imagev1.TagReferencePolicy{
+				Type: imagev1.LocalTagReferencePolicy,
+			},
+		},
+	}
+
+	targetImageStreamTag := &imagev1.ImageStreamTag{}
+	if err := client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: jobspec.Namespace(), Name: "pipeline:TO"}, targetImageStreamTag); err != nil {
+		t.Errorf("Failed to get ImageStreamTag 'pipeline:TO' after step execution: %v", err)
+	}
+
+	if !equality.Semantic.DeepEqual(expectedImageStreamTag, targetImageStreamTag) {
+		t.Errorf("Different ImageStreamTag 'pipeline:TO' after step execution:\n%s", diff.ObjectReflectDiff(expectedImageStreamTag, targetImageStreamTag))
+	}
+}
+
+func TestPrebuiltImageStepMissingImage(t *testing.T) {
+	client := loggingclient.New(fakectrlruntimeclient.NewClientBuilder().Build())
+	jobspec := &api.JobSpec{}
+	jobspec.SetNamespace("target-namespace")
+	build := InputEnvironmentStep("TO", nil, []api.StepLink{api.InternalImageLink("TO")})
+	step := PrebuiltImageStep(build, "TO", "prebuilt-namespace", client, jobspec)
+
+	_, err := step.Inputs()
+	expected := fmt.Errorf("image TO is required but was not found in the pre-built images namespace prebuilt-namespace")
+	if diff := cmp.Diff(expected, err, testhelper.EquateErrorMessage); diff != "" {
+		t.Errorf("unexpected error (-want, +got): %s", diff)
+	}
+}
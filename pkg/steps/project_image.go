@@ -53,6 +53,12 @@ func (s *projectDirectoryImageBuildStep) run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	buildArgs, err := resolveBuildArgs(s.config.BuildArgs, s.config.BuildArgsFrom, s.releaseBuildConfig, func(stream, name string) (string, error) {
+		return utils.ImageDigestFor(s.client, s.jobSpec.Namespace, stream, name)()
+	})
+	if err != nil {
+		return err
+	}
 	build := buildFromSource(
 		s.jobSpec, s.config.From, s.config.To,
 		buildapi.BuildSource{
@@ -64,7 +70,7 @@ func (s *projectDirectoryImageBuildStep) run(ctx context.Context) error {
 		s.config.DockerfilePath,
 		s.resources,
 		s.pullSecret,
-		s.config.BuildArgs,
+		buildArgs,
 		s.config.Ref,
 	)
 
@@ -76,6 +82,24 @@ func (s *projectDirectoryImageBuildStep) run(ctx context.Context) error {
 	return handleBuilds(ctx, s.client, s.podClient, *build, newImageBuildOptions(s.architectures.UnsortedList()))
 }
 
+// resolveBuildArgs appends the build args sourced from images to the literal ones,
+// resolving the pull spec of every image with the given function
+func resolveBuildArgs(buildArgs []api.BuildArg, buildArgsFrom []api.BuildArgFrom, config *api.ReleaseBuildConfiguration, pullSpecFor func(stream, name string) (string, error)) ([]api.BuildArg, error) {
+	if len(buildArgsFrom) == 0 {
+		return buildArgs, nil
+	}
+	resolved := append([]api.BuildArg{}, buildArgs...)
+	for _, arg := range buildArgsFrom {
+		stream, name, _ := config.DependencyParts(api.StepDependency{Name: arg.Dependency}, nil)
+		pullSpec, err := pullSpecFor(stream, name)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve image %s for build arg %s: %w", arg.Dependency, arg.Name, err)
+		}
+		resolved = append(resolved, api.BuildArg{Name: arg.Name, Value: pullSpec})
+	}
+	return resolved, nil
+}
+
 type workingDir func(tag string) (string, error)
 type isBundleImage func(tag string) bool
 
@@ -170,6 +194,12 @@ func (s *projectDirectoryImageBuildStep) Requires() []api.StepLink {
 	for name := range s.config.Inputs {
 		links = append(links, api.InternalImageLink(api.PipelineImageStreamTagReference(name), api.StepLinkWithUnsatisfiableErrorMessage(fmt.Sprintf("%q is neither an imported nor a built image", name))))
 	}
+	for _, arg := range s.config.BuildArgsFrom {
+		stream, name, _ := s.releaseBuildConfig.DependencyParts(api.StepDependency{Name: arg.Dependency}, nil)
+		if link := api.LinkForImage(stream, name); link != nil {
+			links = append(links, link)
+		}
+	}
 	return links
 }
 
@@ -2,6 +2,7 @@ package steps
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -11,6 +12,7 @@ import (
 	buildapi "github.com/openshift/api/build/v1"
 
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/testhelper"
 )
 
 func TestImagesFor(t *testing.T) {
@@ -266,3 +268,59 @@ func TestImagesFor(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveBuildArgs(t *testing.T) {
+	config := &api.ReleaseBuildConfiguration{
+		Images: []api.ProjectDirectoryImageBuildStepConfiguration{{To: "base"}},
+	}
+	pullSpecFor := func(stream, name string) (string, error) {
+		if name == "missing" {
+			return "", errors.New("no such tag")
+		}
+		return fmt.Sprintf("registry.ci/ns/%s:%s", stream, name), nil
+	}
+	var testCases = []struct {
+		name          string
+		buildArgs     []api.BuildArg
+		buildArgsFrom []api.BuildArgFrom
+		expected      []api.BuildArg
+		expectedErr   error
+	}{
+		{
+			name:      "only literal build args",
+			buildArgs: []api.BuildArg{{Name: "TAGS", Value: "fips"}},
+			expected:  []api.BuildArg{{Name: "TAGS", Value: "fips"}},
+		},
+		{
+			name:      "build args sourced from images are appended",
+			buildArgs: []api.BuildArg{{Name: "TAGS", Value: "fips"}},
+			buildArgsFrom: []api.BuildArgFrom{
+				{Name: "BASE_IMAGE", Dependency: "base"},
+				{Name: "CLI_IMAGE", Dependency: "cli"},
+				{Name: "TESTS_IMAGE", Dependency: "stable-initial:tests"},
+			},
+			expected: []api.BuildArg{
+				{Name: "TAGS", Value: "fips"},
+				{Name: "BASE_IMAGE", Value: "registry.ci/ns/pipeline:base"},
+				{Name: "CLI_IMAGE", Value: "registry.ci/ns/stable:cli"},
+				{Name: "TESTS_IMAGE", Value: "registry.ci/ns/stable-initial:tests"},
+			},
+		},
+		{
+			name:          "image that cannot be resolved",
+			buildArgsFrom: []api.BuildArgFrom{{Name: "BASE_IMAGE", Dependency: "missing"}},
+			expectedErr:   errors.New("could not resolve image missing for build arg BASE_IMAGE: no such tag"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := resolveBuildArgs(tc.buildArgs, tc.buildArgsFrom, config, pullSpecFor)
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected build args: %s", diff)
+			}
+		})
+	}
+}
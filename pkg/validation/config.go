@@ -18,6 +18,9 @@ import (
 type Validator struct {
 	validClusterProfiles    api.ClusterProfilesMap
 	validClusterClaimOwners api.ClusterClaimOwnersMap
+	// clusterProfileAliases are accepted in addition to the built-in profiles
+	// when no valid profiles are known
+	clusterProfileAliases api.ClusterProfilesMap
 	// hasTrapCache avoids redundant regexp searches on step commands.
 	hasTrapCache map[string]bool
 }
@@ -108,24 +111,29 @@ func (c *configContext) addPipelineImage(name api.PipelineImageStreamTagReferenc
 }
 
 // IsValidRuntimeConfiguration validates all the configuration's values without knowledge of config
-// repo structure
-func IsValidRuntimeConfiguration(config *api.ReleaseBuildConfiguration) error {
+// repo structure. The aliases among the cluster profiles are accepted in addition to the built-in profiles.
+func IsValidRuntimeConfiguration(config *api.ReleaseBuildConfiguration, clusterProfileAliases api.ClusterProfilesMap) error {
 	v := newSingleUseValidator()
+	v.clusterProfileAliases = clusterProfileAliases
 	return v.validateConfiguration(NewConfigContext(), config, "", "", false, false)
 }
 
 // IsValidResolvedConfiguration behaves as ValidateAtRuntime and also validates that all
-// test steps are fully resolved.
-func IsValidResolvedConfiguration(config *api.ReleaseBuildConfiguration, mergedConfig bool) error {
+// test steps are fully resolved. The aliases among the cluster profiles are accepted
+// in addition to the built-in profiles.
+func IsValidResolvedConfiguration(config *api.ReleaseBuildConfiguration, mergedConfig bool, clusterProfileAliases api.ClusterProfilesMap) error {
 	config.Default()
 	v := newSingleUseValidator()
+	v.clusterProfileAliases = clusterProfileAliases
 	return v.validateConfiguration(NewConfigContext(), config, "", "", true, mergedConfig)
 }
 
-// IsValidConfiguration validates all the configuration's values.
-func IsValidConfiguration(config *api.ReleaseBuildConfiguration, org, repo string) error {
+// IsValidConfiguration validates all the configuration's values. The aliases among
+// the cluster profiles are accepted in addition to the built-in profiles.
+func IsValidConfiguration(config *api.ReleaseBuildConfiguration, org, repo string, clusterProfileAliases api.ClusterProfilesMap) error {
 	config.Default()
 	v := newSingleUseValidator()
+	v.clusterProfileAliases = clusterProfileAliases
 	return v.validateConfiguration(NewConfigContext(), config, org, repo, false, false)
 }
 
@@ -244,6 +252,12 @@ func validateBuildRootImageConfiguration(ctx *configContext, input *api.BuildRoo
 		ret = append(ret, ctx.errorf("you have to specify one of project_image, image_stream_tag or from_repository"))
 	} else if input.ImageStreamTagReference != nil {
 		ret = append(ret, validateBuildRootImageStreamTag(ctx.AddField("image_stream_tag"), *input.ImageStreamTagReference)...)
+	} else if input.ProjectImageBuild != nil {
+		ctxProjectImage := ctx.AddField("project_image")
+		ret = append(ret, validateBuildArgs(ctxProjectImage, *input.ProjectImageBuild)...)
+		if len(input.ProjectImageBuild.BuildArgsFrom) > 0 {
+			ret = append(ret, ctxProjectImage.AddField("build_args_from").errorf("build args sourced from images are not supported for the build root"))
+		}
 	}
 	if err := ctx.addPipelineImage(api.PipelineImageStreamTagReferenceRoot, ref); err != nil {
 		ret = append(ret, err)
@@ -293,7 +307,44 @@ func ValidateImages(ctx *configContext, images []api.ProjectDirectoryImageBuildS
 				validationErrors = append(validationErrors, ctxN.errorf("invalid architecture: %s. Use one of %s", arch, strings.Join(archList, ", ")))
 			}
 		}
+		validationErrors = append(validationErrors, validateBuildArgs(ctxN, image.ProjectDirectoryImageBuildInputs)...)
+		for i, arg := range image.BuildArgsFrom {
+			if arg.Dependency == string(image.To) || arg.Dependency == fmt.Sprintf("%s:%s", api.PipelineImageStream, image.To) {
+				validationErrors = append(validationErrors, ctxN.AddField("build_args_from").addIndex(i).errorf("image %s cannot pass its own pull spec in a build arg", image.To))
+			}
+		}
+	}
+	return validationErrors
+}
+
+var buildArgNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 
+// validateBuildArgs validates build_args_from. Literal build_args predate it and
+// are not validated on their own, they are only checked for conflicting names.
+func validateBuildArgs(ctx *configContext, inputs api.ProjectDirectoryImageBuildInputs) []error {
+	var validationErrors []error
+	literal := sets.New[string]()
+	for _, arg := range inputs.BuildArgs {
+		literal.Insert(arg.Name)
+	}
+	names := sets.New[string]()
+	for i, arg := range inputs.BuildArgsFrom {
+		ctxN := ctx.AddField("build_args_from").addIndex(i)
+		if arg.Name == "" {
+			validationErrors = append(validationErrors, ctxN.AddField("name").errorf("value required but not provided"))
+		} else if !buildArgNameRegex.MatchString(arg.Name) {
+			validationErrors = append(validationErrors, ctxN.AddField("name").errorf("%q is not a valid build arg name, it must match %s", arg.Name, buildArgNameRegex.String()))
+		} else if literal.Has(arg.Name) {
+			validationErrors = append(validationErrors, ctxN.AddField("name").errorf("build arg %q is also set in build_args", arg.Name))
+		} else if names.Has(arg.Name) {
+			validationErrors = append(validationErrors, ctxN.AddField("name").errorf("duplicate build arg %q", arg.Name))
+		}
+		names.Insert(arg.Name)
+		if arg.Dependency == "" {
+			validationErrors = append(validationErrors, ctxN.AddField("dependency").errorf("value required but not provided"))
+		} else if strings.Count(arg.Dependency, ":") > 1 {
+			validationErrors = append(validationErrors, ctxN.AddField("dependency").errorf("must take the `tag` or `stream:tag` form, not %q", arg.Dependency))
+		}
 	}
 	return validationErrors
 }
@@ -90,6 +90,26 @@ func TestValidateBuildRoot(t *testing.T) {
 			ref:           "org.repo",
 			expectedValid: true,
 		},
+		{
+			name: "project_image with build args is valid",
+			buildRootImageConfig: &api.BuildRootImageConfiguration{
+				ProjectImageBuild: &api.ProjectDirectoryImageBuildInputs{
+					DockerfilePath: "Dockerfile.test",
+					BuildArgs:      []api.BuildArg{{Name: "GO_VERSION", Value: "1.22"}},
+				},
+			},
+			expectedValid: true,
+		},
+		{
+			name: "project_image with build args sourced from images causes error",
+			buildRootImageConfig: &api.BuildRootImageConfiguration{
+				ProjectImageBuild: &api.ProjectDirectoryImageBuildInputs{
+					DockerfilePath: "Dockerfile.test",
+					BuildArgsFrom:  []api.BuildArgFrom{{Name: "BASE_IMAGE", Dependency: "stable:cli"}},
+				},
+			},
+			expectedValid: false,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			if err := validateBuildRootImageConfiguration(NewConfigContext().AddField("build_root"), tc.buildRootImageConfig, tc.hasImages, tc.ref); (err != nil) && tc.expectedValid {
@@ -491,6 +511,50 @@ func TestValidateImages(t *testing.T) {
 				errors.New("images[0]: invalid architecture: foo. Use one of amd64, arm64, ppc64le, s390x"),
 			},
 		},
+		{
+			name: "valid build args",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{{
+				ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+					BuildArgs:     []api.BuildArg{{Name: "TAGS", Value: "fips"}},
+					BuildArgsFrom: []api.BuildArgFrom{{Name: "BASE_IMAGE", Dependency: "stable:cli"}},
+				},
+				To: "amsterdam",
+			}},
+		},
+		{
+			name: "pre-existing build args are not validated on their own",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{{
+				ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+					BuildArgs: []api.BuildArg{{Name: "1TAGS"}, {Value: "fips"}, {Name: "TAGS"}, {Name: "TAGS"}},
+				},
+				To: "amsterdam",
+			}},
+		},
+		{
+			name: "invalid build args sourced from images",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{{
+				ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+					BuildArgs: []api.BuildArg{{Name: "GO_VERSION"}},
+					BuildArgsFrom: []api.BuildArgFrom{
+						{Name: "GO_VERSION", Dependency: "stable:cli"},
+						{Name: "BASE_IMAGE"},
+						{Name: "OTHER_IMAGE", Dependency: "a:b:c"},
+						{Name: "SELF", Dependency: "pipeline:amsterdam"},
+						{Name: "1IMAGE", Dependency: "stable:cli"},
+						{Name: "BASE_IMAGE", Dependency: "stable:tests"},
+					},
+				},
+				To: "amsterdam",
+			}},
+			output: []error{
+				errors.New("images[0].build_args_from[0].name: build arg \"GO_VERSION\" is also set in build_args"),
+				errors.New("images[0].build_args_from[1].dependency: value required but not provided"),
+				errors.New("images[0].build_args_from[2].dependency: must take the `tag` or `stream:tag` form, not \"a:b:c\""),
+				errors.New("images[0].build_args_from[4].name: \"1IMAGE\" is not a valid build arg name, it must match ^[a-zA-Z_][a-zA-Z0-9_]*$"),
+				errors.New("images[0].build_args_from[5].name: duplicate build arg \"BASE_IMAGE\""),
+				errors.New("images[0].build_args_from[3]: image amsterdam cannot pass its own pull spec in a build arg"),
+			},
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -775,7 +839,7 @@ func TestReleaseBuildConfiguration_validateImages(t *testing.T) {
 		expected: errors.New(`invalid configuration: it is not permissible to directly set: ‘build_roots’ directly in the config`),
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
-			err := IsValidResolvedConfiguration(&tc.config, tc.mergedConfig)
+			err := IsValidResolvedConfiguration(&tc.config, tc.mergedConfig, nil)
 			testhelper.Diff(t, "error", err, tc.expected, testhelper.EquateErrorMessage)
 		})
 	}
@@ -890,6 +954,32 @@ func TestReleaseBuildConfiguration_validateTestStepDependencies(t *testing.T) {
 				errors.New(`tests[1].literal_steps.post[0].dependencies[0]: cannot determine source for dependency "pipeline:rpms" - this dependency requires built RPMs, which are not configured`),
 			},
 		},
+		{
+			name: "dependencies on images produced by steps",
+			config: api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{To: "image"}},
+				Tests: []api.TestStepConfiguration{
+					{MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+						Pre:  []api.TestStep{{LiteralTestStep: &api.LiteralTestStep{OutputImage: "produced"}}},
+						Test: []api.TestStep{{LiteralTestStep: &api.LiteralTestStep{Dependencies: []api.StepDependency{{Name: "pipeline:produced"}}}}},
+					}},
+					{MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+						Pre: []api.LiteralTestStep{
+							{Dependencies: []api.StepDependency{{Name: "pipeline:produced-later"}}},
+							{OutputImage: "produced-later", Dependencies: []api.StepDependency{{Name: "pipeline:produced-later"}}},
+						},
+						Test: []api.LiteralTestStep{{OutputImage: "image"}, {OutputImage: "produced-later"}},
+						Post: []api.LiteralTestStep{{Dependencies: []api.StepDependency{{Name: "pipeline:produced-later"}}}},
+					}},
+				},
+			},
+			expected: []error{
+				errors.New(`tests[1].literal_steps.pre[0].dependencies[0]: cannot determine source for dependency "pipeline:produced-later" - the step producing this image does not run before this step`),
+				errors.New(`tests[1].literal_steps.pre[1].dependencies[0]: cannot determine source for dependency "pipeline:produced-later" - the step producing this image does not run before this step`),
+				errors.New(`tests[1].literal_steps.test[0].output_image: "image" clashes with an image that is already in the pipeline image stream`),
+				errors.New(`tests[1].literal_steps.test[1].output_image: "produced-later" is already produced by another step`),
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -1308,7 +1398,7 @@ func TestPipelineImages(t *testing.T) {
 		},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
-			err := IsValidConfiguration(&tc.conf, "org", "repo")
+			err := IsValidConfiguration(&tc.conf, "org", "repo", nil)
 			testhelper.Diff(t, "error", err, tc.expected, testhelper.EquateErrorMessage)
 		})
 	}
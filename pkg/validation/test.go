@@ -44,6 +44,10 @@ const (
 	maxTestNameLength      = 61
 )
 
+// maxClusterClaimTimeout bounds how long a test may wait for a claimed cluster,
+// as the claim is only held for a limited lifetime once it is fulfilled
+const maxClusterClaimTimeout = 3 * time.Hour
+
 func (v *Validator) commandHasTrap(cmd string) bool {
 	if v.hasTrapCache == nil {
 		return trapPattern.MatchString(cmd)
@@ -168,6 +172,9 @@ func (v *Validator) validateTestStepConfiguration(
 		if test.Postsubmit && test.Optional {
 			validationErrors = append(validationErrors, fmt.Errorf("%s: `optional` and `postsubmit` are mututally exclusive", fieldRootN))
 		}
+		if test.Informing && test.Optional {
+			validationErrors = append(validationErrors, fmt.Errorf("%s: `informing` and `optional` are mutually exclusive", fieldRootN))
+		}
 
 		if test.Cron != nil && test.Interval != nil {
 			validationErrors = append(validationErrors, fmt.Errorf("%s: `interval` and `cron` cannot both be set", fieldRootN))
@@ -214,6 +221,10 @@ func (v *Validator) validateTestStepConfiguration(
 		if test.Timeout != nil && test.Timeout.Duration > maxJobTimeout {
 			validationErrors = append(validationErrors, fmt.Errorf("%s: job timeout is limited to %s", fieldRootN, maxJobTimeout))
 		}
+		if test.Timeout != nil {
+			validationErrors = append(validationErrors, validateStepTimeouts(fieldRootN, test)...)
+		}
+		validationErrors = append(validationErrors, validateArtifactDirs(fieldRootN, test)...)
 
 		// Validate Secret/Secrets
 		if test.Secret != nil && test.Secrets != nil {
@@ -274,7 +285,35 @@ func validateTestStepDependencies(config *api.ReleaseBuildConfiguration) []error
 		}
 	}
 
-	dependencyErrors := func(step api.LiteralTestStep, testIdx int, stageField, stepField string, stepIdx int, claimRelease *api.ClaimRelease) []error {
+	// outputImages tracks the images produced by the steps of a test, in the order the steps run
+	type outputImages struct {
+		all, produced sets.Set[string]
+	}
+	pipelineImages := sets.New[string](
+		string(api.PipelineImageStreamTagReferenceRoot),
+		string(api.PipelineImageStreamTagReferenceSource),
+		string(api.PipelineImageStreamTagReferenceBinaries),
+		string(api.PipelineImageStreamTagReferenceTestBinaries),
+		string(api.PipelineImageStreamTagReferenceRPMs),
+		string(api.PipelineImageStreamTagReferenceBundleSource),
+	)
+	outputImageErrors := func(step api.LiteralTestStep, testIdx int, stageField, stepField string, stepIdx int, outputs *outputImages) []error {
+		if step.OutputImage == "" {
+			return nil
+		}
+		var errs []error
+		fieldRoot := fmt.Sprintf("tests[%d].%s.%s[%d].output_image", testIdx, stageField, stepField, stepIdx)
+		if pipelineImages.Has(step.OutputImage) || api.IsIndexImage(step.OutputImage) || config.IsBaseImage(step.OutputImage) || config.BuildsImage(step.OutputImage) || config.IsBundleImage(step.OutputImage) {
+			errs = append(errs, fmt.Errorf("%s: %q clashes with an image that is already in the pipeline image stream", fieldRoot, step.OutputImage))
+		}
+		if outputs.produced.Has(step.OutputImage) {
+			errs = append(errs, fmt.Errorf("%s: %q is already produced by another step", fieldRoot, step.OutputImage))
+		}
+		outputs.produced.Insert(step.OutputImage)
+		return errs
+	}
+
+	dependencyErrors := func(step api.LiteralTestStep, testIdx int, stageField, stepField string, stepIdx int, claimRelease *api.ClaimRelease, outputs *outputImages) []error {
 		var errs []error
 		for dependencyIdx, dependency := range step.Dependencies {
 			validationError := func(message string) error {
@@ -284,6 +323,12 @@ func validateTestStepDependencies(config *api.ReleaseBuildConfiguration) []error
 			if link := api.LinkForImage(stream, name); link == nil {
 				errs = append(errs, validationError("ensure the correct ImageStream name was provided"))
 			}
+			if explicit && stream == api.PipelineImageStream && outputs.all.Has(name) {
+				if !outputs.produced.Has(name) {
+					errs = append(errs, validationError("the step producing this image does not run before this step"))
+				}
+				continue
+			}
 			if explicit {
 				// the user has asked us for something specific, and we can
 				// do some best-effort analysis of that input to see if it's
@@ -370,19 +415,21 @@ func validateTestStepDependencies(config *api.ReleaseBuildConfiguration) []error
 		}
 		return errs
 	}
-	processSteps := func(steps []api.TestStep, testIdx int, stageField, stepField string, claimRelease *api.ClaimRelease) []error {
+	processSteps := func(steps []api.TestStep, testIdx int, stageField, stepField string, claimRelease *api.ClaimRelease, outputs *outputImages) []error {
 		var errs []error
 		for stepIdx, test := range steps {
 			if test.LiteralTestStep != nil {
-				errs = append(errs, dependencyErrors(*test.LiteralTestStep, testIdx, stageField, stepField, stepIdx, claimRelease)...)
+				errs = append(errs, dependencyErrors(*test.LiteralTestStep, testIdx, stageField, stepField, stepIdx, claimRelease, outputs)...)
+				errs = append(errs, outputImageErrors(*test.LiteralTestStep, testIdx, stageField, stepField, stepIdx, outputs)...)
 			}
 		}
 		return errs
 	}
-	processLiteralSteps := func(steps []api.LiteralTestStep, testIdx int, stageField, stepField string, claimRelease *api.ClaimRelease) []error {
+	processLiteralSteps := func(steps []api.LiteralTestStep, testIdx int, stageField, stepField string, claimRelease *api.ClaimRelease, outputs *outputImages) []error {
 		var errs []error
 		for stepIdx, test := range steps {
-			errs = append(errs, dependencyErrors(test, testIdx, stageField, stepField, stepIdx, claimRelease)...)
+			errs = append(errs, dependencyErrors(test, testIdx, stageField, stepField, stepIdx, claimRelease, outputs)...)
+			errs = append(errs, outputImageErrors(test, testIdx, stageField, stepField, stepIdx, outputs)...)
 		}
 		return errs
 	}
@@ -393,6 +440,12 @@ func validateTestStepDependencies(config *api.ReleaseBuildConfiguration) []error
 			claimRelease = test.ClusterClaim.ClaimRelease(test.As)
 		}
 		if test.MultiStageTestConfiguration != nil {
+			outputs := &outputImages{all: sets.New[string](), produced: sets.New[string]()}
+			for _, step := range append(append(append([]api.TestStep{}, test.MultiStageTestConfiguration.Pre...), test.MultiStageTestConfiguration.Test...), test.MultiStageTestConfiguration.Post...) {
+				if step.LiteralTestStep != nil && step.OutputImage != "" {
+					outputs.all.Insert(step.OutputImage)
+				}
+			}
 			for _, item := range []struct {
 				field string
 				list  []api.TestStep
@@ -401,10 +454,16 @@ func validateTestStepDependencies(config *api.ReleaseBuildConfiguration) []error
 				{field: "test", list: test.MultiStageTestConfiguration.Test},
 				{field: "post", list: test.MultiStageTestConfiguration.Post},
 			} {
-				errs = append(errs, processSteps(item.list, testIdx, "steps", item.field, claimRelease)...)
+				errs = append(errs, processSteps(item.list, testIdx, "steps", item.field, claimRelease, outputs)...)
 			}
 		}
 		if test.MultiStageTestConfigurationLiteral != nil {
+			outputs := &outputImages{all: sets.New[string](), produced: sets.New[string]()}
+			for _, step := range append(append(append([]api.LiteralTestStep{}, test.MultiStageTestConfigurationLiteral.Pre...), test.MultiStageTestConfigurationLiteral.Test...), test.MultiStageTestConfigurationLiteral.Post...) {
+				if step.OutputImage != "" {
+					outputs.all.Insert(step.OutputImage)
+				}
+			}
 			for _, item := range []struct {
 				field string
 				list  []api.LiteralTestStep
@@ -413,7 +472,7 @@ func validateTestStepDependencies(config *api.ReleaseBuildConfiguration) []error
 				{field: "test", list: test.MultiStageTestConfigurationLiteral.Test},
 				{field: "post", list: test.MultiStageTestConfigurationLiteral.Post},
 			} {
-				errs = append(errs, processLiteralSteps(item.list, testIdx, "literal_steps", item.field, claimRelease)...)
+				errs = append(errs, processLiteralSteps(item.list, testIdx, "literal_steps", item.field, claimRelease, outputs)...)
 			}
 		}
 	}
@@ -428,12 +487,8 @@ func (v *Validator) validateClusterProfile(fieldRoot string, p api.ClusterProfil
 			}
 			return nil
 		}
-	} else {
-		for _, x := range api.ClusterProfiles() {
-			if x == p {
-				return nil
-			}
-		}
+	} else if api.IsBuiltInClusterProfile(p) || v.clusterProfileAliases.IsAlias(p) {
+		return nil
 	}
 	return []error{fmt.Errorf("%s: invalid cluster profile %q", fieldRoot, p)}
 }
@@ -518,6 +573,9 @@ func (v *Validator) validateTestConfigurationType(
 		if claim.Cloud == "" {
 			validationErrors = append(validationErrors, fmt.Errorf("%s.cluster_claim.cloud cannot be empty when cluster_claim is not nil", fieldRoot))
 		}
+		if claim.Timeout != nil && (claim.Timeout.Duration <= 0 || claim.Timeout.Duration > maxClusterClaimTimeout) {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.cluster_claim.timeout must be positive and at most %s, got %s", fieldRoot, maxClusterClaimTimeout, claim.Timeout.Duration))
+		}
 		if claim.Owner == "" {
 			validationErrors = append(validationErrors, fmt.Errorf("%s.cluster_claim.owner cannot be empty when cluster_claim is not nil", fieldRoot))
 		} else if details, ok := v.validClusterClaimOwners[claim.Owner]; ok {
@@ -643,6 +701,81 @@ func (v *Validator) validateTestSteps(context *context, stage testStage, steps [
 	return
 }
 
+// validateStepTimeouts ensures that no literal step in a multi-stage test
+// requests a timeout longer than the timeout of the test itself.
+func validateStepTimeouts(fieldRoot string, test api.TestStepConfiguration) (ret []error) {
+	stageNames := []string{"pre", "test", "post"}
+	check := func(stage string, i int, step *api.LiteralTestStep) {
+		if step == nil || step.Timeout == nil || step.Timeout.Duration <= test.Timeout.Duration {
+			return
+		}
+		ret = append(ret, fmt.Errorf("%s.steps.%s[%d]: step timeout %s exceeds the test timeout %s", fieldRoot, stage, i, step.Timeout.Duration, test.Timeout.Duration))
+	}
+	if c := test.MultiStageTestConfigurationLiteral; c != nil {
+		for stage, steps := range [][]api.LiteralTestStep{c.Pre, c.Test, c.Post} {
+			for i := range steps {
+				check(stageNames[stage], i, &steps[i])
+			}
+		}
+	}
+	if c := test.MultiStageTestConfiguration; c != nil {
+		for stage, steps := range [][]api.TestStep{c.Pre, c.Test, c.Post} {
+			for i := range steps {
+				check(stageNames[stage], i, steps[i].LiteralTestStep)
+			}
+		}
+	}
+	return ret
+}
+
+// validateArtifactDirs ensures that the artifacts of a literal step in a
+// multi-stage test, including its build log, are not collected into the same
+// directory as those of another step.
+func validateArtifactDirs(fieldRoot string, test api.TestStepConfiguration) (ret []error) {
+	stageNames := []string{"pre", "test", "post"}
+	type located struct {
+		field string
+		step  *api.LiteralTestStep
+	}
+	var steps []located
+	if c := test.MultiStageTestConfigurationLiteral; c != nil {
+		for stage, stageSteps := range [][]api.LiteralTestStep{c.Pre, c.Test, c.Post} {
+			for i := range stageSteps {
+				steps = append(steps, located{field: fmt.Sprintf("%s.steps.%s[%d]", fieldRoot, stageNames[stage], i), step: &stageSteps[i]})
+			}
+		}
+	}
+	if c := test.MultiStageTestConfiguration; c != nil {
+		for stage, stageSteps := range [][]api.TestStep{c.Pre, c.Test, c.Post} {
+			for i := range stageSteps {
+				if stageSteps[i].LiteralTestStep != nil {
+					steps = append(steps, located{field: fmt.Sprintf("%s.steps.%s[%d]", fieldRoot, stageNames[stage], i), step: stageSteps[i].LiteralTestStep})
+				}
+			}
+		}
+	}
+	names := sets.New[string]()
+	for _, s := range steps {
+		names.Insert(s.step.As)
+	}
+	dirs := map[string]string{}
+	for _, s := range steps {
+		dir := s.step.ArtifactDir
+		if dir == "" {
+			continue
+		}
+		if dir != s.step.As && names.Has(dir) {
+			ret = append(ret, fmt.Errorf("%s.artifact_dir: %q is the name of another step", s.field, dir))
+		}
+		if other, ok := dirs[dir]; ok {
+			ret = append(ret, fmt.Errorf("%s.artifact_dir: %q is also used by %s", s.field, dir, other))
+		} else {
+			dirs[dir] = s.field
+		}
+	}
+	return ret
+}
+
 func validateTestStep(context *context, step api.TestStep) (ret []error) {
 	if (step.LiteralTestStep != nil && step.Reference != nil) ||
 		(step.LiteralTestStep != nil && step.Chain != nil) ||
@@ -699,6 +832,20 @@ func (v *Validator) validateLiteralTestStep(context *context, stage testStage, s
 	if step.BestEffort != nil && *step.BestEffort && step.Timeout == nil {
 		ret = append(ret, fmt.Errorf("test %s contains best_effort without timeout", step.As))
 	}
+	if step.SkipIf != "" {
+		if err := api.ValidateSkipIf(step.SkipIf); err != nil {
+			ret = append(ret, context.addField("skip_if").errorf("invalid expression: %v", err))
+		}
+	}
+
+	if step.ArtifactDir != "" {
+		if step.CollectArtifacts != nil && !*step.CollectArtifacts {
+			ret = append(ret, context.errorf("`artifact_dir` cannot be set when `collect_artifacts` is false"))
+		}
+		if dir := step.ArtifactDir; filepath.IsAbs(dir) || filepath.Clean(dir) != dir || dir == ".." || strings.HasPrefix(dir, "../") {
+			ret = append(ret, context.addField("artifact_dir").errorf("must be a clean relative path that does not leave the artifacts of the test, got %q", dir))
+		}
+	}
 
 	ret = append(ret, validateResourceRequirements(string(context.field)+".resources", step.Resources)...)
 	ret = append(ret, validateCredentials(string(context.field), step.Credentials)...)
@@ -708,6 +855,9 @@ func (v *Validator) validateLiteralTestStep(context *context, stage testStage, s
 		}
 	}
 	ret = append(ret, validateDependencies(string(context.field), step.Dependencies)...)
+	if strings.ContainsAny(step.OutputImage, ":/@") {
+		ret = append(ret, context.addField("output_image").errorf("must be a tag name, got %q", step.OutputImage))
+	}
 	ret = append(ret, validateLeases(context.addField("leases"), step.Leases)...)
 	if step.NodeArchitecture != nil {
 		err := validateNodeArchitecture(string(context.field), *step.NodeArchitecture)
@@ -718,6 +868,13 @@ func (v *Validator) validateLiteralTestStep(context *context, stage testStage, s
 		if step.OptionalOnSuccess != nil {
 			ret = append(ret, context.errorf("`optional_on_success` is only allowed for Post steps"))
 		}
+		if step.SkipOnFailure != nil {
+			ret = append(ret, context.errorf("`skip_on_failure` is only allowed for Post steps"))
+		}
+	case testStagePost:
+		if o, s := step.OptionalOnSuccess, step.SkipOnFailure; o != nil && *o && s != nil && *s {
+			ret = append(ret, context.errorf("`optional_on_success` and `skip_on_failure` are mutually exclusive"))
+		}
 	}
 	return ret
 }
@@ -688,6 +688,19 @@ func TestValidateTests(t *testing.T) {
 			},
 			expectedError: errors.New("tests[0]: `optional` and `postsubmit` are mututally exclusive"),
 		},
+		{
+			id: "informing job is mutually exclusive with optional",
+			tests: []api.TestStepConfiguration{
+				{
+					As:                         "unit",
+					Commands:                   "commands",
+					ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "ignored"},
+					Optional:                   true,
+					Informing:                  true,
+				},
+			},
+			expectedError: errors.New("tests[0]: `informing` and `optional` are mutually exclusive"),
+		},
 		{
 			id: "test name too long",
 			tests: []api.TestStepConfiguration{
@@ -751,6 +764,144 @@ func TestValidateTests(t *testing.T) {
 				},
 			},
 		},
+		{
+			id: "step timeout within test timeout",
+			tests: []api.TestStepConfiguration{
+				{
+					As:      "e2e",
+					Timeout: &prowv1.Duration{Duration: 2 * time.Hour},
+					MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+						Test: []api.LiteralTestStep{{
+							As:        "step",
+							From:      "cli",
+							Commands:  "commands",
+							Timeout:   &prowv1.Duration{Duration: time.Hour},
+							Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+						}},
+					},
+				},
+			},
+		},
+		{
+			id: "step timeout exceeds test timeout",
+			tests: []api.TestStepConfiguration{
+				{
+					As:      "e2e",
+					Timeout: &prowv1.Duration{Duration: time.Hour},
+					MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+						Post: []api.LiteralTestStep{{
+							As:        "step",
+							From:      "cli",
+							Commands:  "commands",
+							Timeout:   &prowv1.Duration{Duration: 2 * time.Hour},
+							Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+						}},
+					},
+				},
+			},
+			expectedError: errors.New("tests[0].steps.post[0]: step timeout 2h0m0s exceeds the test timeout 1h0m0s"),
+		},
+		{
+			id: "unresolved literal step timeout exceeds test timeout",
+			tests: []api.TestStepConfiguration{
+				{
+					As:      "e2e",
+					Timeout: &prowv1.Duration{Duration: time.Hour},
+					MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+						Test: []api.TestStep{{
+							LiteralTestStep: &api.LiteralTestStep{
+								As:        "step",
+								From:      "cli",
+								Commands:  "commands",
+								Timeout:   &prowv1.Duration{Duration: 2 * time.Hour},
+								Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+							},
+						}},
+					},
+				},
+			},
+			expectedError: errors.New("tests[0].steps.test[0]: step timeout 2h0m0s exceeds the test timeout 1h0m0s"),
+		},
+		{
+			id: "artifact directories of steps are distinct",
+			tests: []api.TestStepConfiguration{
+				{
+					As: "e2e",
+					MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+						Test: []api.LiteralTestStep{{
+							As:          "step",
+							From:        "cli",
+							Commands:    "commands",
+							ArtifactDir: "step",
+							Resources:   api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+						}, {
+							As:          "other-step",
+							From:        "cli",
+							Commands:    "commands",
+							ArtifactDir: "custom",
+							Resources:   api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+						}, {
+							As:        "third-step",
+							From:      "cli",
+							Commands:  "commands",
+							Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+						}},
+					},
+				},
+			},
+		},
+		{
+			id: "artifact directory is used by two steps",
+			tests: []api.TestStepConfiguration{
+				{
+					As: "e2e",
+					MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+						Pre: []api.LiteralTestStep{{
+							As:          "step",
+							From:        "cli",
+							Commands:    "commands",
+							ArtifactDir: "custom",
+							Resources:   api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+						}},
+						Post: []api.LiteralTestStep{{
+							As:          "other-step",
+							From:        "cli",
+							Commands:    "commands",
+							ArtifactDir: "custom",
+							Resources:   api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+						}},
+					},
+				},
+			},
+			expectedError: errors.New(`tests[0].steps.post[0].artifact_dir: "custom" is also used by tests[0].steps.pre[0]`),
+		},
+		{
+			id: "artifact directory is the name of another step",
+			tests: []api.TestStepConfiguration{
+				{
+					As: "e2e",
+					MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+						Test: []api.TestStep{{
+							LiteralTestStep: &api.LiteralTestStep{
+								As:          "step",
+								From:        "cli",
+								Commands:    "commands",
+								ArtifactDir: "other",
+								Resources:   api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+							},
+						}, {
+							LiteralTestStep: &api.LiteralTestStep{
+								As:        "other",
+								From:      "cli",
+								Commands:  "commands",
+								Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+							},
+						}},
+					},
+				},
+			},
+			expectedError: errors.New(`tests[0].steps.test[0].artifact_dir: "other" is the name of another step`),
+		},
 	} {
 		t.Run(tc.id, func(t *testing.T) {
 			v := newSingleUseValidator()
@@ -782,7 +933,7 @@ func TestValidateTestSteps(t *testing.T) {
 	// string pointers in golang are annoying
 	myReference := "my-reference"
 	asReference := "as"
-	yes := true
+	yes, no := true, false
 	defaultDuration := &prowv1.Duration{Duration: 1 * time.Minute}
 	for _, tc := range []struct {
 		name         string
@@ -1046,6 +1197,60 @@ func TestValidateTestSteps(t *testing.T) {
 		errs: []error{
 			errors.New("test[0]: `optional_on_success` is only allowed for Post steps"),
 		},
+	}, {
+		name: "Test step with skip_on_failure",
+
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:            "as",
+				From:          "from",
+				Commands:      "commands",
+				Resources:     resources,
+				SkipOnFailure: &yes},
+		}},
+		errs: []error{
+			errors.New("test[0]: `skip_on_failure` is only allowed for Post steps"),
+		},
+	}, {
+		name: "Test step with a custom artifact directory",
+
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:          "as",
+				From:        "from",
+				Commands:    "commands",
+				Resources:   resources,
+				ArtifactDir: "custom/dir"},
+		}},
+	}, {
+		name: "Test step with an artifact directory outside of the artifacts of the test",
+
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:          "as",
+				From:        "from",
+				Commands:    "commands",
+				Resources:   resources,
+				ArtifactDir: "../other-test"},
+		}},
+		errs: []error{
+			errors.New(`test[0].artifact_dir: must be a clean relative path that does not leave the artifacts of the test, got "../other-test"`),
+		},
+	}, {
+		name: "Test step with an artifact directory and without collecting artifacts",
+
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:               "as",
+				From:             "from",
+				Commands:         "commands",
+				Resources:        resources,
+				CollectArtifacts: &no,
+				ArtifactDir:      "custom"},
+		}},
+		errs: []error{
+			errors.New("test[0]: `artifact_dir` cannot be set when `collect_artifacts` is false"),
+		},
 	}, {
 		name: "Multiple errors",
 		steps: []api.TestStep{{
@@ -1122,6 +1327,29 @@ func TestValidateTestSteps(t *testing.T) {
 		errs: []error{
 			errors.New("test best-effort contains best_effort without timeout"),
 		},
+	}, {
+		name: "valid skip_if",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				SkipIf:    "FIPS_ENABLED == true"},
+		}},
+	}, {
+		name: "invalid skip_if",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				SkipIf:    "FIPS_ENABLED"},
+		}},
+		errs: []error{
+			errors.New(`test[0].skip_if: invalid expression: "FIPS_ENABLED": expected a comparison using == or !=`),
+		},
 	}, {
 		name: "cluster claim release",
 		steps: []api.TestStep{{
@@ -1173,6 +1401,32 @@ func TestValidatePostSteps(t *testing.T) {
 				Resources:         resources,
 				OptionalOnSuccess: &yes},
 		}},
+	}, {
+		name: "Valid Post step skipped on failure",
+
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:            "as",
+				From:          "from",
+				Commands:      "commands",
+				Resources:     resources,
+				SkipOnFailure: &yes},
+		}},
+	}, {
+		name: "Post step that is optional on success and skipped on failure",
+
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:                "as",
+				From:              "from",
+				Commands:          "commands",
+				Resources:         resources,
+				OptionalOnSuccess: &yes,
+				SkipOnFailure:     &yes},
+		}},
+		errs: []error{
+			errors.New("test[0]: `optional_on_success` and `skip_on_failure` are mutually exclusive"),
+		},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			context := newContext("test", nil, tc.releases, make(testInputImages))
@@ -1601,6 +1855,32 @@ func TestValidateTestConfigurationType(t *testing.T) {
 				fmt.Errorf("test.cluster_claim.cloud cannot be empty when cluster_claim is not nil"),
 				fmt.Errorf("test.cluster_claim.owner cannot be empty when cluster_claim is not nil")},
 		},
+		{
+			name: "claim timeout above the maximum",
+			test: api.TestStepConfiguration{
+				ClusterClaim: &api.ClusterClaim{
+					Product:      api.ReleaseProductOCP,
+					Version:      "4.6.0",
+					Architecture: api.ReleaseArchitectureAMD64,
+					Cloud:        api.CloudAWS,
+					Owner:        "dpp",
+					Timeout:      &prowv1.Duration{Duration: 4 * time.Hour},
+				},
+				MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+					Test: []api.TestStep{
+						{
+							LiteralTestStep: &api.LiteralTestStep{
+								As:        "e2e-aws-test",
+								Commands:  "oc get node",
+								From:      "cli",
+								Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+							},
+						},
+					},
+				},
+			},
+			expected: []error{errors.New("test.cluster_claim.timeout must be positive and at most 3h0m0s, got 4h0m0s")},
+		},
 		{
 			name: "valid cluster",
 			test: api.TestStepConfiguration{
@@ -1959,3 +2239,38 @@ func TestVerifyClusterClaimOwnership(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateClusterProfileAliases(t *testing.T) {
+	aliases := api.ClusterProfilesMap{"aws-restricted": {Profile: "aws-restricted", BaseProfile: api.ClusterProfileAWS}}
+	for _, tc := range []struct {
+		name     string
+		profile  api.ClusterProfile
+		aliases  api.ClusterProfilesMap
+		expected []error
+	}{{
+		name:    "built-in profile",
+		profile: api.ClusterProfileAWS,
+		aliases: aliases,
+	}, {
+		name:    "known alias",
+		profile: "aws-restricted",
+		aliases: aliases,
+	}, {
+		name:     "alias without known aliases",
+		profile:  "aws-restricted",
+		expected: []error{errors.New(`tests[0]: invalid cluster profile "aws-restricted"`)},
+	}, {
+		name:     "unknown profile",
+		profile:  "aws-unknown",
+		aliases:  aliases,
+		expected: []error{errors.New(`tests[0]: invalid cluster profile "aws-unknown"`)},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			v := newSingleUseValidator()
+			v.clusterProfileAliases = tc.aliases
+			if diff := cmp.Diff(tc.expected, v.validateClusterProfile("tests[0]", tc.profile, nil), testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("errors differ from expected: %s", diff)
+			}
+		})
+	}
+}
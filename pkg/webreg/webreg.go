@@ -1316,6 +1316,7 @@ func referenceHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *ht
 				Resources:         refs[name].Resources,
 				OptionalOnSuccess: refs[name].OptionalOnSuccess,
 				BestEffort:        refs[name].BestEffort,
+				SkipIf:            refs[name].SkipIf,
 				Cli:               refs[name].Cli,
 			},
 			Documentation: docs[name],
@@ -53,6 +53,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              name: ' '\n" +
 	"              # Value of the build arg.\n" +
 	"              value: ' '\n" +
+	"        # BuildArgsFrom contains build arguments whose value is the pull spec of\n" +
+	"        # an image the job depends on, resolved when the build is created.\n" +
+	"        # Not supported for the build root.\n" +
+	"        build_args_from:\n" +
+	"            - # Dependency is the image whose pull spec is passed in the build arg,\n" +
+	"              # in the `tag` or `stream:tag` form used by multi-stage step dependencies.\n" +
+	"              dependency: ' '\n" +
+	"              # Name of the build arg.\n" +
+	"              name: ' '\n" +
 	"        # ContextDir is the directory in the project\n" +
 	"        # from which this build should be run.\n" +
 	"        context_dir: ' '\n" +
@@ -111,6 +120,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  name: ' '\n" +
 	"                  # Value of the build arg.\n" +
 	"                  value: ' '\n" +
+	"            # BuildArgsFrom contains build arguments whose value is the pull spec of\n" +
+	"            # an image the job depends on, resolved when the build is created.\n" +
+	"            # Not supported for the build root.\n" +
+	"            build_args_from:\n" +
+	"                - # Dependency is the image whose pull spec is passed in the build arg,\n" +
+	"                  # in the `tag` or `stream:tag` form used by multi-stage step dependencies.\n" +
+	"                  dependency: ' '\n" +
+	"                  # Name of the build arg.\n" +
+	"                  name: ' '\n" +
 	"            # ContextDir is the directory in the project\n" +
 	"            # from which this build should be run.\n" +
 	"            context_dir: ' '\n" +
@@ -175,6 +193,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"          name: ' '\n" +
 	"          # Value of the build arg.\n" +
 	"          value: ' '\n" +
+	"      # BuildArgsFrom contains build arguments whose value is the pull spec of\n" +
+	"      # an image the job depends on, resolved when the build is created.\n" +
+	"      # Not supported for the build root.\n" +
+	"      build_args_from:\n" +
+	"        - # Dependency is the image whose pull spec is passed in the build arg,\n" +
+	"          # in the `tag` or `stream:tag` form used by multi-stage step dependencies.\n" +
+	"          dependency: ' '\n" +
+	"          # Name of the build arg.\n" +
+	"          name: ' '\n" +
 	"      # ContextDir is the directory in the project\n" +
 	"      # from which this build should be run.\n" +
 	"      context_dir: ' '\n" +
@@ -355,6 +382,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              name: ' '\n" +
 	"              # Value of the build arg.\n" +
 	"              value: ' '\n" +
+	"        # BuildArgsFrom contains build arguments whose value is the pull spec of\n" +
+	"        # an image the job depends on, resolved when the build is created.\n" +
+	"        # Not supported for the build root.\n" +
+	"        build_args_from:\n" +
+	"            - # Dependency is the image whose pull spec is passed in the build arg,\n" +
+	"              # in the `tag` or `stream:tag` form used by multi-stage step dependencies.\n" +
+	"              dependency: ' '\n" +
+	"              # Name of the build arg.\n" +
+	"              name: ' '\n" +
 	"        # ContextDir is the directory in the project\n" +
 	"        # from which this build should be run.\n" +
 	"        context_dir: ' '\n" +
@@ -397,6 +433,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              name: ' '\n" +
 	"              # Value of the build arg.\n" +
 	"              value: ' '\n" +
+	"        # BuildArgsFrom contains build arguments whose value is the pull spec of\n" +
+	"        # an image the job depends on, resolved when the build is created.\n" +
+	"        # Not supported for the build root.\n" +
+	"        build_args_from:\n" +
+	"            - # Dependency is the image whose pull spec is passed in the build arg,\n" +
+	"              # in the `tag` or `stream:tag` form used by multi-stage step dependencies.\n" +
+	"              dependency: ' '\n" +
+	"              # Name of the build arg.\n" +
+	"              name: ' '\n" +
 	"        # ContextDir is the directory in the project\n" +
 	"        # from which this build should be run.\n" +
 	"        context_dir: ' '\n" +
@@ -539,7 +584,7 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            # Defaults to ocp.\n" +
 	"            product: ' '\n" +
 	"            # Timeout is how long ci-operator will wait for the cluster to be ready.\n" +
-	"            # Defaults to 1h.\n" +
+	"            # Defaults to 1h, may be at most 3h.\n" +
 	"            timeout: 0s\n" +
 	"            # Version is the version of the product\n" +
 	"            version: ' '\n" +
@@ -564,6 +609,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # of pull request workflows. Setting this field will\n" +
 	"        # create a periodic job instead of a presubmit\n" +
 	"        cron: \"\"\n" +
+	"        # Informing indicates that the test is run and its results and JUnit are recorded as usual,\n" +
+	"        # but the job always reports success, so the test surfaces data without blocking merges.\n" +
+	"        informing: true\n" +
 	"        # Interval is how frequently the test should be run based\n" +
 	"        # on the last time the test ran. Setting this field will\n" +
 	"        # create a periodic job instead of a presubmit\n" +
@@ -647,7 +695,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            # Post is the array of test steps run after the tests finish and teardown/deprovision resources.\n" +
 	"            # Post steps always run, even if previous steps fail.\n" +
 	"            post:\n" +
-	"                - # As is the name of the LiteralTestStep.\n" +
+	"                - # ArtifactDir is the directory, relative to the artifacts of the test,\n" +
+	"                  # that the artifacts of the step are collected into. It defaults to the\n" +
+	"                  # name of the step and cannot be the directory of another step.\n" +
+	"                  artifact_dir: ' '\n" +
+	"                  # As is the name of the LiteralTestStep.\n" +
 	"                  as: ' '\n" +
 	"                  # BestEffort defines if this step should cause the job to fail when the\n" +
 	"                  # step fails. This only applies when AllowBestEffortPostSteps flag is set\n" +
@@ -657,6 +709,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # Cli is the (optional) name of the release from which the `oc` binary\n" +
 	"                  # will be injected into this step.\n" +
 	"                  cli: ' '\n" +
+	"                  # CollectArtifacts defines if the contents of $ARTIFACT_DIR are collected\n" +
+	"                  # when the step finishes. It defaults to true, steps producing only\n" +
+	"                  # throwaway output can disable it to avoid uploading that output.\n" +
+	"                  collect_artifacts: false\n" +
 	"                  # Commands is the command(s) that will be run inside the image.\n" +
 	"                  commands: ' '\n" +
 	"                  # Credentials defines the credentials we'll mount into this step.\n" +
@@ -723,6 +779,13 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"                  # applicable to `post` steps.\n" +
 	"                  optional_on_success: false\n" +
+	"                  # OutputImage is the name of an image that this step produces by tagging\n" +
+	"                  # or pushing it into the `pipeline` image stream of the test namespace.\n" +
+	"                  # When any step of a test sets it, the steps of the test are allowed to\n" +
+	"                  # create image stream tags and push to the `pipeline` image stream.\n" +
+	"                  # Later steps of the same test can consume it with a dependency on\n" +
+	"                  # `pipeline:<output_image>`, which is resolved right before they run.\n" +
+	"                  output_image: ' '\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
 	"                    # Limits are resource limits applied to an individual step in the job.\n" +
@@ -736,11 +799,25 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"                  # in the test container instead of being executed directly via bash\n" +
 	"                  run_as_script: false\n" +
+	"                  # SkipIf is an expression evaluated against the environment of the step\n" +
+	"                  # before it runs. When it is true, the step is not run and is reported\n" +
+	"                  # as skipped. Comparisons of a variable to a value use `==` or `!=` and\n" +
+	"                  # may be combined with `&&` and `||`, e.g. `FIPS_ENABLED == true`.\n" +
+	"                  skip_if: ' '\n" +
+	"                  # SkipOnFailure defines if this step should be skipped when any of\n" +
+	"                  # the `pre` or `test` steps failed, e.g. because it reports on a\n" +
+	"                  # successful run. This option is applicable to `post` steps and is\n" +
+	"                  # mutually exclusive with OptionalOnSuccess.\n" +
+	"                  skip_on_failure: false\n" +
 	"                  # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"                  timeout: 0s\n" +
 	"            # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"            pre:\n" +
-	"                - # As is the name of the LiteralTestStep.\n" +
+	"                - # ArtifactDir is the directory, relative to the artifacts of the test,\n" +
+	"                  # that the artifacts of the step are collected into. It defaults to the\n" +
+	"                  # name of the step and cannot be the directory of another step.\n" +
+	"                  artifact_dir: ' '\n" +
+	"                  # As is the name of the LiteralTestStep.\n" +
 	"                  as: ' '\n" +
 	"                  # BestEffort defines if this step should cause the job to fail when the\n" +
 	"                  # step fails. This only applies when AllowBestEffortPostSteps flag is set\n" +
@@ -750,6 +827,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # Cli is the (optional) name of the release from which the `oc` binary\n" +
 	"                  # will be injected into this step.\n" +
 	"                  cli: ' '\n" +
+	"                  # CollectArtifacts defines if the contents of $ARTIFACT_DIR are collected\n" +
+	"                  # when the step finishes. It defaults to true, steps producing only\n" +
+	"                  # throwaway output can disable it to avoid uploading that output.\n" +
+	"                  collect_artifacts: false\n" +
 	"                  # Commands is the command(s) that will be run inside the image.\n" +
 	"                  commands: ' '\n" +
 	"                  # Credentials defines the credentials we'll mount into this step.\n" +
@@ -816,6 +897,13 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"                  # applicable to `post` steps.\n" +
 	"                  optional_on_success: false\n" +
+	"                  # OutputImage is the name of an image that this step produces by tagging\n" +
+	"                  # or pushing it into the `pipeline` image stream of the test namespace.\n" +
+	"                  # When any step of a test sets it, the steps of the test are allowed to\n" +
+	"                  # create image stream tags and push to the `pipeline` image stream.\n" +
+	"                  # Later steps of the same test can consume it with a dependency on\n" +
+	"                  # `pipeline:<output_image>`, which is resolved right before they run.\n" +
+	"                  output_image: ' '\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
 	"                    # Limits are resource limits applied to an individual step in the job.\n" +
@@ -829,11 +917,25 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"                  # in the test container instead of being executed directly via bash\n" +
 	"                  run_as_script: false\n" +
+	"                  # SkipIf is an expression evaluated against the environment of the step\n" +
+	"                  # before it runs. When it is true, the step is not run and is reported\n" +
+	"                  # as skipped. Comparisons of a variable to a value use `==` or `!=` and\n" +
+	"                  # may be combined with `&&` and `||`, e.g. `FIPS_ENABLED == true`.\n" +
+	"                  skip_if: ' '\n" +
+	"                  # SkipOnFailure defines if this step should be skipped when any of\n" +
+	"                  # the `pre` or `test` steps failed, e.g. because it reports on a\n" +
+	"                  # successful run. This option is applicable to `post` steps and is\n" +
+	"                  # mutually exclusive with OptionalOnSuccess.\n" +
+	"                  skip_on_failure: false\n" +
 	"                  # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"                  timeout: 0s\n" +
 	"            # Test is the array of test steps that define the actual test.\n" +
 	"            test:\n" +
-	"                - # As is the name of the LiteralTestStep.\n" +
+	"                - # ArtifactDir is the directory, relative to the artifacts of the test,\n" +
+	"                  # that the artifacts of the step are collected into. It defaults to the\n" +
+	"                  # name of the step and cannot be the directory of another step.\n" +
+	"                  artifact_dir: ' '\n" +
+	"                  # As is the name of the LiteralTestStep.\n" +
 	"                  as: ' '\n" +
 	"                  # BestEffort defines if this step should cause the job to fail when the\n" +
 	"                  # step fails. This only applies when AllowBestEffortPostSteps flag is set\n" +
@@ -843,6 +945,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # Cli is the (optional) name of the release from which the `oc` binary\n" +
 	"                  # will be injected into this step.\n" +
 	"                  cli: ' '\n" +
+	"                  # CollectArtifacts defines if the contents of $ARTIFACT_DIR are collected\n" +
+	"                  # when the step finishes. It defaults to true, steps producing only\n" +
+	"                  # throwaway output can disable it to avoid uploading that output.\n" +
+	"                  collect_artifacts: false\n" +
 	"                  # Commands is the command(s) that will be run inside the image.\n" +
 	"                  commands: ' '\n" +
 	"                  # Credentials defines the credentials we'll mount into this step.\n" +
@@ -909,6 +1015,13 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"                  # applicable to `post` steps.\n" +
 	"                  optional_on_success: false\n" +
+	"                  # OutputImage is the name of an image that this step produces by tagging\n" +
+	"                  # or pushing it into the `pipeline` image stream of the test namespace.\n" +
+	"                  # When any step of a test sets it, the steps of the test are allowed to\n" +
+	"                  # create image stream tags and push to the `pipeline` image stream.\n" +
+	"                  # Later steps of the same test can consume it with a dependency on\n" +
+	"                  # `pipeline:<output_image>`, which is resolved right before they run.\n" +
+	"                  output_image: ' '\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
 	"                    # Limits are resource limits applied to an individual step in the job.\n" +
@@ -922,6 +1035,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"                  # in the test container instead of being executed directly via bash\n" +
 	"                  run_as_script: false\n" +
+	"                  # SkipIf is an expression evaluated against the environment of the step\n" +
+	"                  # before it runs. When it is true, the step is not run and is reported\n" +
+	"                  # as skipped. Comparisons of a variable to a value use `==` or `!=` and\n" +
+	"                  # may be combined with `&&` and `||`, e.g. `FIPS_ENABLED == true`.\n" +
+	"                  skip_if: ' '\n" +
+	"                  # SkipOnFailure defines if this step should be skipped when any of\n" +
+	"                  # the `pre` or `test` steps failed, e.g. because it reports on a\n" +
+	"                  # successful run. This option is applicable to `post` steps and is\n" +
+	"                  # mutually exclusive with OptionalOnSuccess.\n" +
+	"                  skip_on_failure: false\n" +
 	"                  # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"                  timeout: 0s\n" +
 	"            # Override job timeout\n" +
@@ -1050,13 +1173,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            # execution if previous Pre and Test steps passed.\n" +
 	"            post:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
-	"                - as: ' '\n" +
+	"                - artifact_dir: ' '\n" +
+	"                  as: ' '\n" +
 	"                  best_effort: false\n" +
 	"                  # Chain is the name of a step chain reference.\n" +
 	"                  chain: \"\"\n" +
 	"                  # Cli is the (optional) name of the release from which the `oc` binary\n" +
 	"                  # will be injected into this step.\n" +
 	"                  cli: ' '\n" +
+	"                  collect_artifacts: false\n" +
 	"                  commands: ' '\n" +
 	"                  credentials:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
@@ -1099,6 +1224,7 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - \"\"\n" +
 	"                  optional_on_success: false\n" +
+	"                  output_image: ' '\n" +
 	"                  # Reference is the name of a step reference.\n" +
 	"                  ref: \"\"\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
@@ -1111,17 +1237,21 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
 	"                  run_as_script: false\n" +
+	"                  skip_if: ' '\n" +
+	"                  skip_on_failure: false\n" +
 	"                  timeout: 0s\n" +
 	"            # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"            pre:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
-	"                - as: ' '\n" +
+	"                - artifact_dir: ' '\n" +
+	"                  as: ' '\n" +
 	"                  best_effort: false\n" +
 	"                  # Chain is the name of a step chain reference.\n" +
 	"                  chain: \"\"\n" +
 	"                  # Cli is the (optional) name of the release from which the `oc` binary\n" +
 	"                  # will be injected into this step.\n" +
 	"                  cli: ' '\n" +
+	"                  collect_artifacts: false\n" +
 	"                  commands: ' '\n" +
 	"                  credentials:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
@@ -1164,6 +1294,7 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - \"\"\n" +
 	"                  optional_on_success: false\n" +
+	"                  output_image: ' '\n" +
 	"                  # Reference is the name of a step reference.\n" +
 	"                  ref: \"\"\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
@@ -1176,17 +1307,21 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
 	"                  run_as_script: false\n" +
+	"                  skip_if: ' '\n" +
+	"                  skip_on_failure: false\n" +
 	"                  timeout: 0s\n" +
 	"            # Test is the array of test steps that define the actual test.\n" +
 	"            test:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
-	"                - as: ' '\n" +
+	"                - artifact_dir: ' '\n" +
+	"                  as: ' '\n" +
 	"                  best_effort: false\n" +
 	"                  # Chain is the name of a step chain reference.\n" +
 	"                  chain: \"\"\n" +
 	"                  # Cli is the (optional) name of the release from which the `oc` binary\n" +
 	"                  # will be injected into this step.\n" +
 	"                  cli: ' '\n" +
+	"                  collect_artifacts: false\n" +
 	"                  commands: ' '\n" +
 	"                  credentials:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
@@ -1229,6 +1364,7 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - \"\"\n" +
 	"                  optional_on_success: false\n" +
+	"                  output_image: ' '\n" +
 	"                  # Reference is the name of a step reference.\n" +
 	"                  ref: \"\"\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
@@ -1241,6 +1377,8 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
 	"                  run_as_script: false\n" +
+	"                  skip_if: ' '\n" +
+	"                  skip_on_failure: false\n" +
 	"                  timeout: 0s\n" +
 	"            # Workflow is the name of the workflow to be used for this configuration. For fields defined in both\n" +
 	"            # the config and the workflow, the fields from the config will override what is set in Workflow.\n" +
@@ -1385,7 +1523,7 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # Defaults to ocp.\n" +
 	"        product: ' '\n" +
 	"        # Timeout is how long ci-operator will wait for the cluster to be ready.\n" +
-	"        # Defaults to 1h.\n" +
+	"        # Defaults to 1h, may be at most 3h.\n" +
 	"        timeout: 0s\n" +
 	"        # Version is the version of the product\n" +
 	"        version: ' '\n" +
@@ -1410,6 +1548,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"      # of pull request workflows. Setting this field will\n" +
 	"      # create a periodic job instead of a presubmit\n" +
 	"      cron: \"\"\n" +
+	"      # Informing indicates that the test is run and its results and JUnit are recorded as usual,\n" +
+	"      # but the job always reports success, so the test surfaces data without blocking merges.\n" +
+	"      informing: true\n" +
 	"      # Interval is how frequently the test should be run based\n" +
 	"      # on the last time the test ran. Setting this field will\n" +
 	"      # create a periodic job instead of a presubmit\n" +
@@ -1493,7 +1634,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # Post is the array of test steps run after the tests finish and teardown/deprovision resources.\n" +
 	"        # Post steps always run, even if previous steps fail.\n" +
 	"        post:\n" +
-	"            - # As is the name of the LiteralTestStep.\n" +
+	"            - # ArtifactDir is the directory, relative to the artifacts of the test,\n" +
+	"              # that the artifacts of the step are collected into. It defaults to the\n" +
+	"              # name of the step and cannot be the directory of another step.\n" +
+	"              artifact_dir: ' '\n" +
+	"              # As is the name of the LiteralTestStep.\n" +
 	"              as: ' '\n" +
 	"              # BestEffort defines if this step should cause the job to fail when the\n" +
 	"              # step fails. This only applies when AllowBestEffortPostSteps flag is set\n" +
@@ -1503,6 +1648,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # Cli is the (optional) name of the release from which the `oc` binary\n" +
 	"              # will be injected into this step.\n" +
 	"              cli: ' '\n" +
+	"              # CollectArtifacts defines if the contents of $ARTIFACT_DIR are collected\n" +
+	"              # when the step finishes. It defaults to true, steps producing only\n" +
+	"              # throwaway output can disable it to avoid uploading that output.\n" +
+	"              collect_artifacts: false\n" +
 	"              # Commands is the command(s) that will be run inside the image.\n" +
 	"              commands: ' '\n" +
 	"              # Credentials defines the credentials we'll mount into this step.\n" +
@@ -1569,6 +1718,13 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"              # applicable to `post` steps.\n" +
 	"              optional_on_success: false\n" +
+	"              # OutputImage is the name of an image that this step produces by tagging\n" +
+	"              # or pushing it into the `pipeline` image stream of the test namespace.\n" +
+	"              # When any step of a test sets it, the steps of the test are allowed to\n" +
+	"              # create image stream tags and push to the `pipeline` image stream.\n" +
+	"              # Later steps of the same test can consume it with a dependency on\n" +
+	"              # `pipeline:<output_image>`, which is resolved right before they run.\n" +
+	"              output_image: ' '\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
 	"                # Limits are resource limits applied to an individual step in the job.\n" +
@@ -1582,11 +1738,25 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"              # in the test container instead of being executed directly via bash\n" +
 	"              run_as_script: false\n" +
+	"              # SkipIf is an expression evaluated against the environment of the step\n" +
+	"              # before it runs. When it is true, the step is not run and is reported\n" +
+	"              # as skipped. Comparisons of a variable to a value use `==` or `!=` and\n" +
+	"              # may be combined with `&&` and `||`, e.g. `FIPS_ENABLED == true`.\n" +
+	"              skip_if: ' '\n" +
+	"              # SkipOnFailure defines if this step should be skipped when any of\n" +
+	"              # the `pre` or `test` steps failed, e.g. because it reports on a\n" +
+	"              # successful run. This option is applicable to `post` steps and is\n" +
+	"              # mutually exclusive with OptionalOnSuccess.\n" +
+	"              skip_on_failure: false\n" +
 	"              # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"              timeout: 0s\n" +
 	"        # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"        pre:\n" +
-	"            - # As is the name of the LiteralTestStep.\n" +
+	"            - # ArtifactDir is the directory, relative to the artifacts of the test,\n" +
+	"              # that the artifacts of the step are collected into. It defaults to the\n" +
+	"              # name of the step and cannot be the directory of another step.\n" +
+	"              artifact_dir: ' '\n" +
+	"              # As is the name of the LiteralTestStep.\n" +
 	"              as: ' '\n" +
 	"              # BestEffort defines if this step should cause the job to fail when the\n" +
 	"              # step fails. This only applies when AllowBestEffortPostSteps flag is set\n" +
@@ -1596,6 +1766,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # Cli is the (optional) name of the release from which the `oc` binary\n" +
 	"              # will be injected into this step.\n" +
 	"              cli: ' '\n" +
+	"              # CollectArtifacts defines if the contents of $ARTIFACT_DIR are collected\n" +
+	"              # when the step finishes. It defaults to true, steps producing only\n" +
+	"              # throwaway output can disable it to avoid uploading that output.\n" +
+	"              collect_artifacts: false\n" +
 	"              # Commands is the command(s) that will be run inside the image.\n" +
 	"              commands: ' '\n" +
 	"              # Credentials defines the credentials we'll mount into this step.\n" +
@@ -1662,6 +1836,13 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"              # applicable to `post` steps.\n" +
 	"              optional_on_success: false\n" +
+	"              # OutputImage is the name of an image that this step produces by tagging\n" +
+	"              # or pushing it into the `pipeline` image stream of the test namespace.\n" +
+	"              # When any step of a test sets it, the steps of the test are allowed to\n" +
+	"              # create image stream tags and push to the `pipeline` image stream.\n" +
+	"              # Later steps of the same test can consume it with a dependency on\n" +
+	"              # `pipeline:<output_image>`, which is resolved right before they run.\n" +
+	"              output_image: ' '\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
 	"                # Limits are resource limits applied to an individual step in the job.\n" +
@@ -1675,11 +1856,25 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"              # in the test container instead of being executed directly via bash\n" +
 	"              run_as_script: false\n" +
+	"              # SkipIf is an expression evaluated against the environment of the step\n" +
+	"              # before it runs. When it is true, the step is not run and is reported\n" +
+	"              # as skipped. Comparisons of a variable to a value use `==` or `!=` and\n" +
+	"              # may be combined with `&&` and `||`, e.g. `FIPS_ENABLED == true`.\n" +
+	"              skip_if: ' '\n" +
+	"              # SkipOnFailure defines if this step should be skipped when any of\n" +
+	"              # the `pre` or `test` steps failed, e.g. because it reports on a\n" +
+	"              # successful run. This option is applicable to `post` steps and is\n" +
+	"              # mutually exclusive with OptionalOnSuccess.\n" +
+	"              skip_on_failure: false\n" +
 	"              # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"              timeout: 0s\n" +
 	"        # Test is the array of test steps that define the actual test.\n" +
 	"        test:\n" +
-	"            - # As is the name of the LiteralTestStep.\n" +
+	"            - # ArtifactDir is the directory, relative to the artifacts of the test,\n" +
+	"              # that the artifacts of the step are collected into. It defaults to the\n" +
+	"              # name of the step and cannot be the directory of another step.\n" +
+	"              artifact_dir: ' '\n" +
+	"              # As is the name of the LiteralTestStep.\n" +
 	"              as: ' '\n" +
 	"              # BestEffort defines if this step should cause the job to fail when the\n" +
 	"              # step fails. This only applies when AllowBestEffortPostSteps flag is set\n" +
@@ -1689,6 +1884,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # Cli is the (optional) name of the release from which the `oc` binary\n" +
 	"              # will be injected into this step.\n" +
 	"              cli: ' '\n" +
+	"              # CollectArtifacts defines if the contents of $ARTIFACT_DIR are collected\n" +
+	"              # when the step finishes. It defaults to true, steps producing only\n" +
+	"              # throwaway output can disable it to avoid uploading that output.\n" +
+	"              collect_artifacts: false\n" +
 	"              # Commands is the command(s) that will be run inside the image.\n" +
 	"              commands: ' '\n" +
 	"              # Credentials defines the credentials we'll mount into this step.\n" +
@@ -1755,6 +1954,13 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"              # applicable to `post` steps.\n" +
 	"              optional_on_success: false\n" +
+	"              # OutputImage is the name of an image that this step produces by tagging\n" +
+	"              # or pushing it into the `pipeline` image stream of the test namespace.\n" +
+	"              # When any step of a test sets it, the steps of the test are allowed to\n" +
+	"              # create image stream tags and push to the `pipeline` image stream.\n" +
+	"              # Later steps of the same test can consume it with a dependency on\n" +
+	"              # `pipeline:<output_image>`, which is resolved right before they run.\n" +
+	"              output_image: ' '\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
 	"                # Limits are resource limits applied to an individual step in the job.\n" +
@@ -1768,6 +1974,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"              # in the test container instead of being executed directly via bash\n" +
 	"              run_as_script: false\n" +
+	"              # SkipIf is an expression evaluated against the environment of the step\n" +
+	"              # before it runs. When it is true, the step is not run and is reported\n" +
+	"              # as skipped. Comparisons of a variable to a value use `==` or `!=` and\n" +
+	"              # may be combined with `&&` and `||`, e.g. `FIPS_ENABLED == true`.\n" +
+	"              skip_if: ' '\n" +
+	"              # SkipOnFailure defines if this step should be skipped when any of\n" +
+	"              # the `pre` or `test` steps failed, e.g. because it reports on a\n" +
+	"              # successful run. This option is applicable to `post` steps and is\n" +
+	"              # mutually exclusive with OptionalOnSuccess.\n" +
+	"              skip_on_failure: false\n" +
 	"              # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"              timeout: 0s\n" +
 	"        # Override job timeout\n" +
@@ -1896,13 +2112,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # execution if previous Pre and Test steps passed.\n" +
 	"        post:\n" +
 	"            # LiteralTestStep is a full test step definition.\n" +
-	"            - as: ' '\n" +
+	"            - artifact_dir: ' '\n" +
+	"              as: ' '\n" +
 	"              best_effort: false\n" +
 	"              # Chain is the name of a step chain reference.\n" +
 	"              chain: \"\"\n" +
 	"              # Cli is the (optional) name of the release from which the `oc` binary\n" +
 	"              # will be injected into this step.\n" +
 	"              cli: ' '\n" +
+	"              collect_artifacts: false\n" +
 	"              commands: ' '\n" +
 	"              credentials:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -1945,6 +2163,7 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - \"\"\n" +
 	"              optional_on_success: false\n" +
+	"              output_image: ' '\n" +
 	"              # Reference is the name of a step reference.\n" +
 	"              ref: \"\"\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
@@ -1957,17 +2176,21 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
 	"              run_as_script: false\n" +
+	"              skip_if: ' '\n" +
+	"              skip_on_failure: false\n" +
 	"              timeout: 0s\n" +
 	"        # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"        pre:\n" +
 	"            # LiteralTestStep is a full test step definition.\n" +
-	"            - as: ' '\n" +
+	"            - artifact_dir: ' '\n" +
+	"              as: ' '\n" +
 	"              best_effort: false\n" +
 	"              # Chain is the name of a step chain reference.\n" +
 	"              chain: \"\"\n" +
 	"              # Cli is the (optional) name of the release from which the `oc` binary\n" +
 	"              # will be injected into this step.\n" +
 	"              cli: ' '\n" +
+	"              collect_artifacts: false\n" +
 	"              commands: ' '\n" +
 	"              credentials:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -2010,6 +2233,7 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - \"\"\n" +
 	"              optional_on_success: false\n" +
+	"              output_image: ' '\n" +
 	"              # Reference is the name of a step reference.\n" +
 	"              ref: \"\"\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
@@ -2022,17 +2246,21 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
 	"              run_as_script: false\n" +
+	"              skip_if: ' '\n" +
+	"              skip_on_failure: false\n" +
 	"              timeout: 0s\n" +
 	"        # Test is the array of test steps that define the actual test.\n" +
 	"        test:\n" +
 	"            # LiteralTestStep is a full test step definition.\n" +
-	"            - as: ' '\n" +
+	"            - artifact_dir: ' '\n" +
+	"              as: ' '\n" +
 	"              best_effort: false\n" +
 	"              # Chain is the name of a step chain reference.\n" +
 	"              chain: \"\"\n" +
 	"              # Cli is the (optional) name of the release from which the `oc` binary\n" +
 	"              # will be injected into this step.\n" +
 	"              cli: ' '\n" +
+	"              collect_artifacts: false\n" +
 	"              commands: ' '\n" +
 	"              credentials:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -2075,6 +2303,7 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - \"\"\n" +
 	"              optional_on_success: false\n" +
+	"              output_image: ' '\n" +
 	"              # Reference is the name of a step reference.\n" +
 	"              ref: \"\"\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
@@ -2087,6 +2316,8 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
 	"              run_as_script: false\n" +
+	"              skip_if: ' '\n" +
+	"              skip_on_failure: false\n" +
 	"              timeout: 0s\n" +
 	"        # Workflow is the name of the workflow to be used for this configuration. For fields defined in both\n" +
 	"        # the config and the workflow, the fields from the config will override what is set in Workflow.\n" +